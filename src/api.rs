@@ -8,6 +8,23 @@
 use crate::config::Config;
 use anyhow::{bail, Result};
 
+/// A non-success API response. Carries the status and body so callers can
+/// react to specific statuses (e.g. a 400 carrying validation errors)
+/// without parsing the message.
+#[derive(Debug)]
+pub struct ApiError {
+    pub status: reqwest::StatusCode,
+    pub body: String,
+}
+
+impl std::fmt::Display for ApiError {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        write!(f, "API error (HTTP {}): {}", self.status, self.body)
+    }
+}
+
+impl std::error::Error for ApiError {}
+
 /// Perform a GET request to a Datadog API endpoint.
 pub async fn get(cfg: &Config, path: &str, query: &[(&str, String)]) -> Result<serde_json::Value> {
     let url = format!("{}{}", cfg.api_base_url(), path);
@@ -63,6 +80,21 @@ pub async fn delete(cfg: &Config, path: &str) -> Result<serde_json::Value> {
     send(req).await
 }
 
+/// Perform a DELETE request with a JSON body, for endpoints that identify
+/// what to remove in the body rather than the path.
+pub async fn delete_with_body(
+    cfg: &Config,
+    path: &str,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    let url = format!("{}{}", cfg.api_base_url(), path);
+    let client = reqwest::Client::new();
+    let mut req = client.delete(&url);
+    req = apply_auth(req, cfg)?;
+    req = req.json(body);
+    send(req).await
+}
+
 fn apply_auth(req: reqwest::RequestBuilder, cfg: &Config) -> Result<reqwest::RequestBuilder> {
     if let Some(token) = &cfg.access_token {
         Ok(req.header("Authorization", format!("Bearer {token}")))
@@ -89,7 +121,7 @@ async fn send(req: reqwest::RequestBuilder) -> Result<serde_json::Value> {
         .await
         .map_err(|e| anyhow::anyhow!("failed to read response body: {e}"))?;
     if !status.is_success() {
-        bail!("API error (HTTP {status}): {body}");
+        return Err(ApiError { status, body }.into());
     }
     if body.is_empty() {
         return Ok(serde_json::json!({}));
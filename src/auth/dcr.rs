@@ -14,7 +14,6 @@ pub const DCR_CLIENT_NAME: &str = "datadog-api-claude-plugin";
 pub const DCR_REDIRECT_PORTS: &[u16] = &[8000, 8080, 8888, 9000];
 
 #[cfg(not(target_arch = "wasm32"))]
-#[allow(dead_code)]
 pub fn get_redirect_uris() -> Vec<String> {
     DCR_REDIRECT_PORTS
         .iter()
@@ -26,6 +25,7 @@ pub fn get_redirect_uris() -> Vec<String> {
 /// DCR + token exchange client.
 pub struct DcrClient {
     site: String,
+    base_url: String,
     http: reqwest::Client,
 }
 
@@ -47,7 +47,7 @@ struct RegistrationResponse {
 
 #[cfg(not(target_arch = "wasm32"))]
 #[derive(Deserialize)]
-struct TokenResponse {
+pub(super) struct TokenResponse {
     access_token: String,
     token_type: String,
     expires_in: i64,
@@ -57,11 +57,30 @@ struct TokenResponse {
     scope: String,
 }
 
+#[cfg(not(target_arch = "wasm32"))]
+impl TokenResponse {
+    pub(super) fn into_token_set(self, client_id: &str) -> TokenSet {
+        TokenSet {
+            access_token: self.access_token,
+            refresh_token: self.refresh_token,
+            token_type: self.token_type,
+            expires_in: self.expires_in,
+            issued_at: Utc::now().timestamp(),
+            scope: self.scope,
+            client_id: client_id.to_string(),
+        }
+    }
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 impl DcrClient {
     pub fn new(site: &str) -> Self {
+        // PUP_MOCK_SERVER redirects OAuth calls too, like Config::api_base_url.
+        let base_url =
+            std::env::var("PUP_MOCK_SERVER").unwrap_or_else(|_| format!("https://api.{site}"));
         Self {
             site: site.to_string(),
+            base_url,
             http: reqwest::Client::builder()
                 .timeout(std::time::Duration::from_secs(30))
                 .build()
@@ -75,15 +94,39 @@ impl DcrClient {
         redirect_uri: &str,
         _scopes: &[&str],
     ) -> Result<ClientCredentials> {
-        let url = format!("https://api.{}/api/v2/oauth2/register", self.site);
+        self.register_with(
+            vec![redirect_uri.to_string()],
+            &["authorization_code", "refresh_token"],
+        )
+        .await
+    }
+
+    /// Registers a client that may also use the device authorization grant
+    /// (RFC 8628). The loopback redirect URIs are kept so the same client
+    /// still works for a later browser login.
+    pub async fn register_device(&self) -> Result<ClientCredentials> {
+        self.register_with(
+            get_redirect_uris(),
+            &[
+                "authorization_code",
+                super::device::DEVICE_CODE_GRANT,
+                "refresh_token",
+            ],
+        )
+        .await
+    }
+
+    async fn register_with(
+        &self,
+        redirect_uris: Vec<String>,
+        grant_types: &[&str],
+    ) -> Result<ClientCredentials> {
+        let url = format!("{}/api/v2/oauth2/register", self.base_url);
 
         let body = RegistrationRequest {
             client_name: DCR_CLIENT_NAME.to_string(),
-            redirect_uris: vec![redirect_uri.to_string()],
-            grant_types: vec![
-                "authorization_code".to_string(),
-                "refresh_token".to_string(),
-            ],
+            redirect_uris,
+            grant_types: grant_types.iter().map(|g| g.to_string()).collect(),
         };
 
         let resp = self
@@ -147,7 +190,7 @@ impl DcrClient {
     }
 
     async fn request_tokens(&self, params: &[(&str, &str)], client_id: &str) -> Result<TokenSet> {
-        let url = format!("https://api.{}/oauth2/v1/token", self.site);
+        let url = format!("{}/oauth2/v1/token", self.base_url);
 
         // Filter out empty params
         let form_params: Vec<(&str, &str)> = params
@@ -175,15 +218,7 @@ impl DcrClient {
             .await
             .context("failed to parse token response")?;
 
-        Ok(TokenSet {
-            access_token: token_resp.access_token,
-            refresh_token: token_resp.refresh_token,
-            token_type: token_resp.token_type,
-            expires_in: token_resp.expires_in,
-            issued_at: Utc::now().timestamp(),
-            scope: token_resp.scope,
-            client_id: client_id.to_string(),
-        })
+        Ok(token_resp.into_token_set(client_id))
     }
 
     /// Build the authorization URL for the browser.
@@ -0,0 +1,273 @@
+#[cfg(not(target_arch = "wasm32"))]
+use anyhow::{bail, Context, Result};
+#[cfg(not(target_arch = "wasm32"))]
+use serde::Deserialize;
+#[cfg(not(target_arch = "wasm32"))]
+use std::time::Duration;
+
+#[cfg(not(target_arch = "wasm32"))]
+use super::dcr::TokenResponse;
+#[cfg(not(target_arch = "wasm32"))]
+use super::types::TokenSet;
+
+/// Grant type for the device authorization flow (RFC 8628).
+pub const DEVICE_CODE_GRANT: &str = "urn:ietf:params:oauth:grant-type:device_code";
+
+/// Poll interval used when the server does not send one (RFC 8628 §3.2).
+#[cfg(not(target_arch = "wasm32"))]
+const DEFAULT_POLL_INTERVAL_SECS: u64 = 5;
+
+/// Seconds added to the poll interval on each `slow_down` response.
+#[cfg(not(target_arch = "wasm32"))]
+const SLOW_DOWN_SECS: u64 = 5;
+
+#[cfg(not(target_arch = "wasm32"))]
+/// Device authorization response: the code the user types and where to type it.
+#[derive(Debug, Clone, Deserialize)]
+pub struct DeviceAuthorization {
+    pub device_code: String,
+    pub user_code: String,
+    pub verification_uri: String,
+    #[serde(default)]
+    pub verification_uri_complete: Option<String>,
+    pub expires_in: u64,
+    #[serde(default)]
+    pub interval: Option<u64>,
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+#[derive(Deserialize)]
+struct ErrorResponse {
+    error: String,
+    #[serde(default)]
+    error_description: Option<String>,
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+/// Device authorization flow client, for logins on machines without a browser.
+pub struct DeviceClient {
+    base_url: String,
+    http: reqwest::Client,
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+impl DeviceClient {
+    pub fn new(site: &str) -> Result<Self> {
+        // PUP_MOCK_SERVER redirects OAuth calls too, like DcrClient::new.
+        let base_url =
+            std::env::var("PUP_MOCK_SERVER").unwrap_or_else(|_| format!("https://api.{site}"));
+        Self::with_base_url(&base_url)
+    }
+
+    pub fn with_base_url(base_url: &str) -> Result<Self> {
+        Ok(Self {
+            base_url: base_url.trim_end_matches('/').to_string(),
+            http: reqwest::Client::builder()
+                .timeout(Duration::from_secs(30))
+                .build()
+                .context("failed to build HTTP client")?,
+        })
+    }
+
+    /// Requests a device code and user code for `client_id`.
+    pub async fn authorize(&self, client_id: &str, scopes: &[&str]) -> Result<DeviceAuthorization> {
+        let url = format!("{}/oauth2/v1/device/authorize", self.base_url);
+        let scope = scopes.join(" ");
+        let resp = self
+            .http
+            .post(&url)
+            .form(&[("client_id", client_id), ("scope", scope.as_str())])
+            .send()
+            .await
+            .context("device authorization request failed")?;
+
+        if !resp.status().is_success() {
+            let status = resp.status();
+            let body = resp.text().await.unwrap_or_default();
+            bail!("device authorization failed (HTTP {status}): {body}");
+        }
+
+        resp.json()
+            .await
+            .context("failed to parse device authorization response")
+    }
+
+    /// Polls the token endpoint until the user approves the request, the
+    /// device code expires, or `timeout` elapses, whichever comes first.
+    pub async fn poll_for_token(
+        &self,
+        auth: &DeviceAuthorization,
+        client_id: &str,
+        timeout: Duration,
+    ) -> Result<TokenSet> {
+        let url = format!("{}/oauth2/v1/token", self.base_url);
+        let timeout = timeout.min(Duration::from_secs(auth.expires_in));
+        let deadline = tokio::time::Instant::now() + timeout;
+        let mut interval = Duration::from_secs(auth.interval.unwrap_or(DEFAULT_POLL_INTERVAL_SECS));
+        let params = [
+            ("grant_type", DEVICE_CODE_GRANT),
+            ("device_code", auth.device_code.as_str()),
+            ("client_id", client_id),
+        ];
+
+        loop {
+            let resp = self
+                .http
+                .post(&url)
+                .form(&params)
+                .send()
+                .await
+                .context("token request failed")?;
+
+            if resp.status().is_success() {
+                let token_resp: TokenResponse = resp
+                    .json()
+                    .await
+                    .context("failed to parse token response")?;
+                return Ok(token_resp.into_token_set(client_id));
+            }
+
+            let status = resp.status();
+            let body = resp.text().await.unwrap_or_default();
+            let err: ErrorResponse = match serde_json::from_str(&body) {
+                Ok(err) => err,
+                Err(_) => bail!("token exchange failed (HTTP {status}): {body}"),
+            };
+            match err.error.as_str() {
+                "authorization_pending" => {}
+                "slow_down" => interval += Duration::from_secs(SLOW_DOWN_SECS),
+                "access_denied" => bail!("device login was denied"),
+                "expired_token" => {
+                    bail!("device code expired; run 'pup auth login --device' again")
+                }
+                other => {
+                    let desc = err.error_description.unwrap_or_default();
+                    bail!("OAuth error: {other}: {desc}");
+                }
+            }
+
+            if tokio::time::Instant::now() + interval >= deadline {
+                bail!("device login timed out after {timeout:?}");
+            }
+            tokio::time::sleep(interval).await;
+        }
+    }
+}
+
+#[cfg(all(test, not(target_arch = "wasm32")))]
+mod tests {
+    use super::*;
+
+    fn device_auth(expires_in: u64) -> DeviceAuthorization {
+        DeviceAuthorization {
+            device_code: "dev-123".into(),
+            user_code: "ABCD-EFGH".into(),
+            verification_uri: "https://app.datadoghq.com/device".into(),
+            verification_uri_complete: None,
+            expires_in,
+            interval: Some(0),
+        }
+    }
+
+    async fn mock_token_error(
+        server: &mut mockito::Server,
+        error: &str,
+        hits: usize,
+    ) -> mockito::Mock {
+        server
+            .mock("POST", "/oauth2/v1/token")
+            .with_status(400)
+            .with_header("content-type", "application/json")
+            .with_body(format!(r#"{{"error": "{error}"}}"#))
+            .expect(hits)
+            .create_async()
+            .await
+    }
+
+    #[tokio::test]
+    async fn test_authorize_parses_codes() {
+        let mut server = mockito::Server::new_async().await;
+        let _mock = server
+            .mock("POST", "/oauth2/v1/device/authorize")
+            .match_body(mockito::Matcher::UrlEncoded(
+                "client_id".into(),
+                "client-1".into(),
+            ))
+            .with_status(200)
+            .with_header("content-type", "application/json")
+            .with_body(
+                r#"{"device_code": "dev-123", "user_code": "ABCD-EFGH",
+                    "verification_uri": "https://app.datadoghq.com/device",
+                    "expires_in": 600, "interval": 5}"#,
+            )
+            .create_async()
+            .await;
+
+        let client = DeviceClient::with_base_url(&server.url()).unwrap();
+        let auth = client
+            .authorize("client-1", &["metrics_read"])
+            .await
+            .unwrap();
+        assert_eq!(auth.user_code, "ABCD-EFGH");
+        assert_eq!(auth.interval, Some(5));
+        assert!(auth.verification_uri_complete.is_none());
+    }
+
+    #[tokio::test]
+    async fn test_poll_pending_then_success() {
+        let mut server = mockito::Server::new_async().await;
+        let pending = mock_token_error(&mut server, "authorization_pending", 2).await;
+        let success = server
+            .mock("POST", "/oauth2/v1/token")
+            .match_body(mockito::Matcher::UrlEncoded(
+                "grant_type".into(),
+                DEVICE_CODE_GRANT.into(),
+            ))
+            .with_status(200)
+            .with_header("content-type", "application/json")
+            .with_body(
+                r#"{"access_token": "at", "token_type": "Bearer", "expires_in": 3600,
+                    "refresh_token": "rt", "scope": "metrics_read"}"#,
+            )
+            .expect(1)
+            .create_async()
+            .await;
+
+        let client = DeviceClient::with_base_url(&server.url()).unwrap();
+        let tokens = client
+            .poll_for_token(&device_auth(60), "client-1", Duration::from_secs(30))
+            .await
+            .unwrap();
+        assert_eq!(tokens.access_token, "at");
+        assert_eq!(tokens.refresh_token, "rt");
+        assert_eq!(tokens.client_id, "client-1");
+        pending.assert_async().await;
+        success.assert_async().await;
+    }
+
+    #[tokio::test]
+    async fn test_poll_denied() {
+        let mut server = mockito::Server::new_async().await;
+        let _mock = mock_token_error(&mut server, "access_denied", 1).await;
+
+        let client = DeviceClient::with_base_url(&server.url()).unwrap();
+        let err = client
+            .poll_for_token(&device_auth(60), "client-1", Duration::from_secs(30))
+            .await
+            .unwrap_err();
+        assert!(err.to_string().contains("denied"), "{err}");
+    }
+
+    #[tokio::test]
+    async fn test_poll_times_out() {
+        let mut server = mockito::Server::new_async().await;
+        let _mock = mock_token_error(&mut server, "authorization_pending", 1).await;
+
+        let client = DeviceClient::with_base_url(&server.url()).unwrap();
+        let err = client
+            .poll_for_token(&device_auth(0), "client-1", Duration::from_secs(30))
+            .await
+            .unwrap_err();
+        assert!(err.to_string().contains("timed out"), "{err}");
+    }
+}
@@ -1,5 +1,6 @@
 pub mod callback;
 pub mod dcr;
+pub mod device;
 pub mod pkce;
 pub mod storage;
 pub mod types;
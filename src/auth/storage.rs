@@ -380,6 +380,13 @@ pub fn get_storage() -> Result<&'static Mutex<Option<Box<dyn Storage>>>> {
     Ok(&STORAGE)
 }
 
+/// Swaps the process-wide backend so tests never touch the real keychain.
+/// `None` restores auto-detection on the next `get_storage` call.
+#[cfg(test)]
+pub fn replace_storage(backend: Option<Box<dyn Storage>>) {
+    *STORAGE.lock().unwrap_or_else(|p| p.into_inner()) = backend;
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 fn detect_backend() -> Box<dyn Storage> {
     // Check DD_TOKEN_STORAGE env var
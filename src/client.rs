@@ -14,6 +14,7 @@ use crate::config::Config;
 #[cfg(not(target_arch = "wasm32"))]
 struct BearerAuthMiddleware {
     token: String,
+    site: String,
 }
 
 #[cfg(not(target_arch = "wasm32"))]
@@ -25,12 +26,124 @@ impl Middleware for BearerAuthMiddleware {
         extensions: &mut Extensions,
         next: Next<'_>,
     ) -> reqwest_middleware::Result<reqwest::Response> {
+        let token = current_token(&self.token);
+        // Streaming bodies can't be cloned; those requests just aren't retried.
+        let retry = req.try_clone();
         req.headers_mut().insert(
             reqwest::header::AUTHORIZATION,
-            format!("Bearer {}", self.token).parse().unwrap(),
+            format!("Bearer {token}").parse().unwrap(),
         );
-        next.run(req, extensions).await
+        let resp = next.clone().run(req, extensions).await?;
+        if resp.status() != reqwest::StatusCode::UNAUTHORIZED {
+            return Ok(resp);
+        }
+        let Some(mut retry) = retry else {
+            return Ok(resp);
+        };
+        let Some(new_token) = refresh_rejected_token(&self.site, &token).await else {
+            return Ok(resp);
+        };
+        retry.headers_mut().insert(
+            reqwest::header::AUTHORIZATION,
+            format!("Bearer {new_token}").parse().unwrap(),
+        );
+        next.run(retry, extensions).await
+    }
+}
+
+// ---------------------------------------------------------------------------
+// OAuth token refresh on 401
+// ---------------------------------------------------------------------------
+
+/// Access tokens replaced during this run, as (rejected, replacement) pairs.
+static REFRESHED_TOKENS: std::sync::Mutex<Vec<(String, String)>> =
+    std::sync::Mutex::new(Vec::new());
+
+/// Returns the newest token issued in place of `token`, or `token` itself if
+/// it was never refreshed. Requests started after a refresh use this so they
+/// don't each run into the same 401.
+pub fn current_token(token: &str) -> String {
+    let refreshed = REFRESHED_TOKENS.lock().unwrap_or_else(|p| p.into_inner());
+    let mut current = token;
+    while let Some((_, next)) = refreshed.iter().find(|(old, _)| old == current) {
+        current = next;
+    }
+    current.to_string()
+}
+
+/// Serializes refreshes so parallel requests hitting the same 401 trigger a
+/// single token exchange.
+#[cfg(not(target_arch = "wasm32"))]
+static REFRESH_LOCK: tokio::sync::Mutex<()> = tokio::sync::Mutex::const_new(());
+
+/// Called after the API rejected `token` with a 401. Refreshes the stored
+/// OAuth token for `site`, persists the new one and returns it so the caller
+/// can retry once. Returns None when there is nothing to refresh: the token
+/// didn't come from `pup auth login` (e.g. DD_ACCESS_TOKEN), no refresh
+/// token is stored, or the refresh itself failed.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn refresh_rejected_token(site: &str, token: &str) -> Option<String> {
+    let _guard = REFRESH_LOCK.lock().await;
+    let current = current_token(token);
+    if current != token {
+        // Another request refreshed it while we waited for the lock.
+        return Some(current);
+    }
+    match refresh_stored_token(site, token).await {
+        Ok(Some(new_token)) => {
+            REFRESHED_TOKENS
+                .lock()
+                .unwrap_or_else(|p| p.into_inner())
+                .push((token.to_string(), new_token.clone()));
+            crate::formatter::print_status("Access token was rejected; refreshed it and retrying.");
+            Some(new_token)
+        }
+        Ok(None) => None,
+        Err(e) => {
+            crate::formatter::print_status(&format!("Warning: access token refresh failed: {e:#}"));
+            None
+        }
+    }
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn refresh_rejected_token(_site: &str, _token: &str) -> Option<String> {
+    None
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn refresh_stored_token(site: &str, token: &str) -> anyhow::Result<Option<String>> {
+    use crate::auth::{dcr::DcrClient, storage};
+
+    // Never hold the storage lock across the token exchange.
+    let (tokens, creds) = {
+        let store = storage::get_storage()?;
+        let guard = store.lock().unwrap_or_else(|p| p.into_inner());
+        let Some(store) = guard.as_ref() else {
+            return Ok(None);
+        };
+        let Some(tokens) = store.load_tokens(site)? else {
+            return Ok(None);
+        };
+        if tokens.access_token != token || tokens.refresh_token.is_empty() {
+            return Ok(None);
+        }
+        let Some(creds) = store.load_client_credentials(site)? else {
+            return Ok(None);
+        };
+        (tokens, creds)
+    };
+
+    let new_tokens = DcrClient::new(site)
+        .refresh_token(&tokens.refresh_token, &creds)
+        .await?;
+
+    let store = storage::get_storage()?;
+    let guard = store.lock().unwrap_or_else(|p| p.into_inner());
+    if let Some(store) = guard.as_ref() {
+        store.save_tokens(site, &new_tokens)?;
     }
+    Ok(Some(new_tokens.access_token))
 }
 
 // ---------------------------------------------------------------------------
@@ -82,6 +195,7 @@ pub fn make_bearer_client(cfg: &Config) -> Option<ClientWithMiddleware> {
     let client = ClientBuilder::new(reqwest_client)
         .with(BearerAuthMiddleware {
             token: token.clone(),
+            site: cfg.site.clone(),
         })
         .build();
     Some(client)
@@ -468,35 +582,106 @@ static OAUTH_EXCLUDED_ENDPOINTS: &[EndpointRequirement] = &[
 ];
 
 // ---------------------------------------------------------------------------
-// Raw HTTP helpers (native only)
+// Concurrency governor
 // ---------------------------------------------------------------------------
 
-/// Makes an authenticated GET request directly via reqwest.
-/// Used for endpoints not covered by the typed DD API client.
-pub async fn raw_get(cfg: &Config, path: &str) -> anyhow::Result<serde_json::Value> {
-    let url = format!("{}{}", cfg.api_base_url(), path);
-    let client = reqwest::Client::new();
-    let mut req = client.get(&url);
+/// Default for `--max-concurrency`.
+pub const DEFAULT_MAX_CONCURRENCY: usize = 5;
+
+/// Request slots shared by every code path that fans out API calls, so the
+/// total in flight stays under `--max-concurrency` whichever feature started
+/// them. Created on first use with `DEFAULT_MAX_CONCURRENCY` slots.
+#[cfg(not(target_arch = "wasm32"))]
+static REQUEST_SLOTS: std::sync::Mutex<Option<std::sync::Arc<tokio::sync::Semaphore>>> =
+    std::sync::Mutex::new(None);
+
+/// Sets how many concurrent requests may be in flight at once (at least 1).
+/// Permits already handed out keep counting against the old limit.
+#[cfg(not(target_arch = "wasm32"))]
+pub fn set_max_concurrency(max: usize) {
+    let slots = tokio::sync::Semaphore::new(max.max(1));
+    *REQUEST_SLOTS.lock().unwrap() = Some(std::sync::Arc::new(slots));
+}
 
+/// Waits for a free request slot. Concurrent code paths hold the permit while
+/// their request runs; sequential commands don't need one. Never acquire a
+/// second slot while holding one, or a full governor deadlocks.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn acquire_request_slot() -> tokio::sync::OwnedSemaphorePermit {
+    let slots = REQUEST_SLOTS
+        .lock()
+        .unwrap()
+        .get_or_insert_with(|| {
+            std::sync::Arc::new(tokio::sync::Semaphore::new(DEFAULT_MAX_CONCURRENCY))
+        })
+        .clone();
+    slots
+        .acquire_owned()
+        .await
+        .expect("request slots are never closed")
+}
+
+// ---------------------------------------------------------------------------
+// Raw HTTP helpers (native only)
+// ---------------------------------------------------------------------------
+
+/// Adds the configured credentials to the request produced by `build` and
+/// sends it. With a bearer token, a 401 triggers one refresh of the stored
+/// OAuth token and a single retry; `build` is called again for the retry.
+async fn send_authenticated(
+    cfg: &Config,
+    build: impl Fn() -> reqwest::RequestBuilder,
+) -> anyhow::Result<reqwest::Response> {
     if let Some(token) = &cfg.access_token {
-        req = req.header("Authorization", format!("Bearer {token}"));
+        let token = current_token(token);
+        let resp = build()
+            .header("Authorization", format!("Bearer {token}"))
+            .send()
+            .await?;
+        if resp.status() == reqwest::StatusCode::UNAUTHORIZED {
+            if let Some(new_token) = refresh_rejected_token(&cfg.site, &token).await {
+                return Ok(build()
+                    .header("Authorization", format!("Bearer {new_token}"))
+                    .send()
+                    .await?);
+            }
+        }
+        Ok(resp)
     } else if let (Some(api_key), Some(app_key)) = (&cfg.api_key, &cfg.app_key) {
-        req = req
+        Ok(build()
             .header("DD-API-KEY", api_key.as_str())
-            .header("DD-APPLICATION-KEY", app_key.as_str());
+            .header("DD-APPLICATION-KEY", app_key.as_str())
+            .send()
+            .await?)
     } else {
         anyhow::bail!("no authentication configured");
     }
+}
 
-    let resp = req.header("Accept", "application/json").send().await?;
+/// Makes an authenticated GET request directly via reqwest.
+/// Used for endpoints not covered by the typed DD API client.
+pub async fn raw_get(cfg: &Config, path: &str) -> anyhow::Result<serde_json::Value> {
+    let url = format!("{}{}", cfg.api_base_url(), path);
+    let client = reqwest::Client::new();
+    let resp = send_authenticated(cfg, || {
+        client.get(&url).header("Accept", "application/json")
+    })
+    .await?;
     if !resp.status().is_success() {
         let status = resp.status();
         let body = resp.text().await.unwrap_or_default();
-        anyhow::bail!("API error (HTTP {status}): {body}");
+        return Err(crate::api::ApiError { status, body }.into());
     }
     Ok(resp.json().await?)
 }
 
+/// Like `raw_get`, but keeps the response text next to the decoded value so
+/// `-o json` can print it unchanged (see `formatter::output_raw_json`).
+pub async fn raw_get_json(cfg: &Config, path: &str) -> anyhow::Result<crate::formatter::RawJson> {
+    let resp = raw_request(cfg, "GET", path, None).await?;
+    crate::formatter::RawJson::parse(resp.text().await?)
+}
+
 /// Makes an authenticated POST request directly via reqwest.
 /// Used for endpoints not covered by the typed DD API client.
 pub async fn raw_post(
@@ -506,30 +691,71 @@ pub async fn raw_post(
 ) -> anyhow::Result<serde_json::Value> {
     let url = format!("{}{}", cfg.api_base_url(), path);
     let client = reqwest::Client::new();
-    let mut req = client.post(&url);
+    let resp = send_authenticated(cfg, || {
+        client
+            .post(&url)
+            .header("Content-Type", "application/json")
+            .header("Accept", "application/json")
+            .json(&body)
+    })
+    .await?;
+    if !resp.status().is_success() {
+        let status = resp.status();
+        let body = resp.text().await.unwrap_or_default();
+        return Err(crate::api::ApiError { status, body }.into());
+    }
+    Ok(resp.json().await?)
+}
 
-    if let Some(token) = &cfg.access_token {
-        req = req.header("Authorization", format!("Bearer {token}"));
-    } else if let (Some(api_key), Some(app_key)) = (&cfg.api_key, &cfg.app_key) {
-        req = req
-            .header("DD-API-KEY", api_key.as_str())
-            .header("DD-APPLICATION-KEY", app_key.as_str());
-    } else {
-        anyhow::bail!("no authentication configured");
+/// Makes an authenticated DELETE request directly via reqwest.
+/// Returns an empty object when the endpoint responds with no body (e.g. 204).
+pub async fn raw_delete(cfg: &Config, path: &str) -> anyhow::Result<serde_json::Value> {
+    let url = format!("{}{}", cfg.api_base_url(), path);
+    let client = reqwest::Client::new();
+    let resp = send_authenticated(cfg, || {
+        client.delete(&url).header("Accept", "application/json")
+    })
+    .await?;
+    if !resp.status().is_success() {
+        let status = resp.status();
+        let body = resp.text().await.unwrap_or_default();
+        return Err(crate::api::ApiError { status, body }.into());
+    }
+    let body = resp.text().await?;
+    if body.is_empty() {
+        return Ok(serde_json::json!({}));
     }
+    Ok(serde_json::from_str(&body)?)
+}
 
-    let resp = req
-        .header("Content-Type", "application/json")
-        .header("Accept", "application/json")
-        .json(&body)
-        .send()
-        .await?;
+/// Makes an authenticated request with any HTTP method and returns the
+/// response after its status has been checked, leaving the body unread.
+/// Used by `pup raw`, which decides how to consume the body.
+pub async fn raw_request(
+    cfg: &Config,
+    method: &str,
+    path: &str,
+    body: Option<&serde_json::Value>,
+) -> anyhow::Result<reqwest::Response> {
+    let method = reqwest::Method::from_bytes(method.to_uppercase().as_bytes())
+        .map_err(|_| anyhow::anyhow!("invalid HTTP method: {method}"))?;
+    let url = format!("{}{}", cfg.api_base_url(), path);
+    let client = reqwest::Client::new();
+    let resp = send_authenticated(cfg, || {
+        let mut req = client.request(method.clone(), &url);
+        if let Some(body) = body {
+            req = req.header("Content-Type", "application/json").json(body);
+        }
+        // Prefer JSON, but let endpoints that only produce text, CSV or YAML answer.
+        req.header("Accept", "application/json, */*;q=0.5")
+    })
+    .await?;
     if !resp.status().is_success() {
         let status = resp.status();
         let body = resp.text().await.unwrap_or_default();
-        anyhow::bail!("API error (HTTP {status}): {body}");
+        return Err(crate::api::ApiError { status, body }.into());
     }
-    Ok(resp.json().await?)
+    Ok(resp)
 }
 
 #[cfg(test)]
@@ -547,6 +773,11 @@ mod tests {
             output_format: crate::config::OutputFormat::Json,
             auto_approve: false,
             agent_mode: false,
+            envelope: None,
+            table: Default::default(),
+            template: None,
+            summary: false,
+            profile: None,
         }
     }
 
@@ -738,4 +969,59 @@ mod tests {
             "/api/v2/error_tracking/issues/search"
         ));
     }
+
+    #[tokio::test]
+    async fn test_request_slots_bound_in_flight_requests() {
+        use std::sync::atomic::{AtomicUsize, Ordering};
+        use std::sync::Arc;
+        use tokio::io::{AsyncReadExt, AsyncWriteExt};
+
+        let _guard = ENV_LOCK.lock().unwrap_or_else(|p| p.into_inner());
+        // Counting server: tracks how many requests are being served at once.
+        let listener = tokio::net::TcpListener::bind("127.0.0.1:0").await.unwrap();
+        let addr = listener.local_addr().unwrap();
+        let in_flight = Arc::new(AtomicUsize::new(0));
+        let peak = Arc::new(AtomicUsize::new(0));
+        let (server_in_flight, server_peak) = (in_flight.clone(), peak.clone());
+        tokio::spawn(async move {
+            loop {
+                let (mut sock, _) = listener.accept().await.unwrap();
+                let (in_flight, peak) = (server_in_flight.clone(), server_peak.clone());
+                tokio::spawn(async move {
+                    let mut buf = [0u8; 4096];
+                    let _ = sock.read(&mut buf).await;
+                    let now = in_flight.fetch_add(1, Ordering::SeqCst) + 1;
+                    peak.fetch_max(now, Ordering::SeqCst);
+                    tokio::time::sleep(std::time::Duration::from_millis(50)).await;
+                    in_flight.fetch_sub(1, Ordering::SeqCst);
+                    let body = "{}";
+                    let resp = format!(
+                        "HTTP/1.1 200 OK\r\ncontent-type: application/json\r\ncontent-length: {}\r\nconnection: close\r\n\r\n{body}",
+                        body.len()
+                    );
+                    let _ = sock.write_all(resp.as_bytes()).await;
+                });
+            }
+        });
+
+        std::env::set_var("PUP_MOCK_SERVER", format!("http://{addr}"));
+        set_max_concurrency(2);
+        let cfg = test_cfg();
+        let mut tasks = tokio::task::JoinSet::new();
+        for _ in 0..8 {
+            let cfg = cfg.clone();
+            tasks.spawn(async move {
+                let _slot = acquire_request_slot().await;
+                raw_get(&cfg, "/api/v1/validate").await
+            });
+        }
+        while let Some(joined) = tasks.join_next().await {
+            joined.unwrap().unwrap();
+        }
+        set_max_concurrency(DEFAULT_MAX_CONCURRENCY);
+        std::env::remove_var("PUP_MOCK_SERVER");
+
+        let peak = peak.load(Ordering::SeqCst);
+        assert!((1..=2).contains(&peak), "peak in-flight requests: {peak}");
+    }
 }
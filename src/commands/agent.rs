@@ -5,27 +5,29 @@ pub fn schema(_compact: bool) -> Result<()> {
 }
 
 pub fn guide() -> Result<()> {
-    println!("Datadog Agent Management Guide");
-    println!("==============================");
-    println!();
-    println!("The Datadog Agent collects metrics, traces, and logs from your hosts");
-    println!("and sends them to Datadog for monitoring and analysis.");
-    println!();
-    println!("COMMON OPERATIONS:");
-    println!("  Install:    See https://docs.datadoghq.com/agent/");
-    println!("  Start:      sudo datadog-agent start");
-    println!("  Stop:       sudo datadog-agent stop");
-    println!("  Restart:    sudo datadog-agent restart");
-    println!("  Status:     datadog-agent status");
-    println!("  Config:     /etc/datadog-agent/datadog.yaml");
-    println!();
-    println!("FLEET MANAGEMENT:");
-    println!("  Use 'pup fleet' commands to manage agents at scale:");
-    println!("  pup fleet agents list       - List all fleet agents");
-    println!("  pup fleet deployments list  - List deployments");
-    println!("  pup fleet schedules list    - List schedules");
-    println!();
-    println!("DOCUMENTATION:");
-    println!("  https://docs.datadoghq.com/agent/");
-    Ok(())
+    crate::formatter::print_block(
+        "Datadog Agent Management Guide
+==============================
+
+The Datadog Agent collects metrics, traces, and logs from your hosts
+and sends them to Datadog for monitoring and analysis.
+
+COMMON OPERATIONS:
+  Install:    See https://docs.datadoghq.com/agent/
+  Start:      sudo datadog-agent start
+  Stop:       sudo datadog-agent stop
+  Restart:    sudo datadog-agent restart
+  Status:     datadog-agent status
+  Config:     /etc/datadog-agent/datadog.yaml
+
+FLEET MANAGEMENT:
+  Use 'pup fleet' commands to manage agents at scale:
+  pup fleet agents list       - List all fleet agents
+  pup fleet deployments list  - List deployments
+  pup fleet schedules list    - List schedules
+
+DOCUMENTATION:
+  https://docs.datadoghq.com/agent/
+",
+    )
 }
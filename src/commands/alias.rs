@@ -31,20 +31,21 @@ fn save_aliases(aliases: &BTreeMap<String, String>) -> Result<()> {
 pub fn list() -> Result<()> {
     let aliases = load_aliases()?;
     if aliases.is_empty() {
-        println!("No aliases configured.");
+        crate::formatter::print_block("No aliases configured.\n")?;
         return Ok(());
     }
-    for (name, command) in &aliases {
-        println!("{name} = {command}");
-    }
-    Ok(())
+    let lines: String = aliases
+        .iter()
+        .map(|(name, command)| format!("{name} = {command}\n"))
+        .collect();
+    crate::formatter::print_block(&lines)
 }
 
 pub fn set(name: String, command: String) -> Result<()> {
     let mut aliases = load_aliases()?;
     aliases.insert(name.clone(), command.clone());
     save_aliases(&aliases)?;
-    println!("Alias set: {name} = {command}");
+    crate::formatter::print_block(&format!("Alias set: {name} = {command}\n"))?;
     Ok(())
 }
 
@@ -56,7 +57,7 @@ pub fn delete(names: Vec<String>) -> Result<()> {
         }
     }
     save_aliases(&aliases)?;
-    println!("Deleted {} alias(es).", names.len());
+    crate::formatter::print_block(&format!("Deleted {} alias(es).\n", names.len()))?;
     Ok(())
 }
 
@@ -83,6 +84,6 @@ pub fn import(file: &str) -> Result<()> {
         aliases.insert(name, command);
     }
     save_aliases(&aliases)?;
-    println!("Imported {count} alias(es) from {file}.");
+    crate::formatter::print_block(&format!("Imported {count} alias(es) from {file}.\n"))?;
     Ok(())
 }
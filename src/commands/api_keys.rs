@@ -94,13 +94,13 @@ pub async fn delete(cfg: &Config, key_id: &str) -> Result<()> {
     api.delete_api_key(key_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete API key: {e:?}"))?;
-    println!("Successfully deleted API key {key_id}");
+    formatter::print_block(&format!("Successfully deleted API key {key_id}\n"))?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
 pub async fn delete(cfg: &Config, key_id: &str) -> Result<()> {
     crate::api::delete(cfg, &format!("/api/v2/api_keys/{key_id}")).await?;
-    println!("Successfully deleted API key {key_id}");
+    formatter::print_block(&format!("Successfully deleted API key {key_id}\n"))?;
     Ok(())
 }
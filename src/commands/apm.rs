@@ -10,8 +10,8 @@ pub async fn services_list(cfg: &Config, env: String, from: String, to: String)
     let from_ts = util::parse_time_to_unix(&from)?;
     let to_ts = util::parse_time_to_unix(&to)?;
     let path = format!("/api/v2/apm/services?start={from_ts}&end={to_ts}&filter[env]={env}");
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+    let raw = client::raw_get_json(cfg, &path).await?;
+    formatter::output_raw_json(cfg, &raw)
 }
 
 #[cfg(target_arch = "wasm32")]
@@ -32,8 +32,8 @@ pub async fn services_stats(cfg: &Config, env: String, from: String, to: String)
     let from_ts = util::parse_time_to_unix(&from)?;
     let to_ts = util::parse_time_to_unix(&to)?;
     let path = format!("/api/v2/apm/services/stats?start={from_ts}&end={to_ts}&filter[env]={env}");
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+    let raw = client::raw_get_json(cfg, &path).await?;
+    formatter::output_raw_json(cfg, &raw)
 }
 
 #[cfg(target_arch = "wasm32")]
@@ -50,30 +50,206 @@ pub async fn services_stats(cfg: &Config, env: String, from: String, to: String)
 }
 
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn entities_list(cfg: &Config, from: String, to: String) -> Result<()> {
+pub async fn entities_list(cfg: &Config, from: String, to: String, format: String) -> Result<()> {
+    let tree = is_tree_format(&format)?;
     let from_ts = util::parse_time_to_unix(&from)?;
     let to_ts = util::parse_time_to_unix(&to)?;
     let path = format!("/api/unstable/apm/entities?start={from_ts}&end={to_ts}");
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+    if tree {
+        let data = client::raw_get(cfg, &path).await?;
+        return formatter::print_block(&render_tree(&entity_tree(&data)));
+    }
+    let raw = client::raw_get_json(cfg, &path).await?;
+    formatter::output_raw_json(cfg, &raw)
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn entities_list(cfg: &Config, from: String, to: String) -> Result<()> {
+pub async fn entities_list(cfg: &Config, from: String, to: String, format: String) -> Result<()> {
+    let tree = is_tree_format(&format)?;
     let from_ts = util::parse_time_to_unix(&from)?;
     let to_ts = util::parse_time_to_unix(&to)?;
     let query = vec![("start", from_ts.to_string()), ("end", to_ts.to_string())];
     let data = crate::api::get(cfg, "/api/unstable/apm/entities", &query).await?;
+    if tree {
+        return formatter::print_block(&render_tree(&entity_tree(&data)));
+    }
     crate::formatter::output(cfg, &data)
 }
 
+/// Validate a flat-or-tree `--format` (`apm entities list`, `traces get`);
+/// true for the tree rendering.
+pub(crate) fn is_tree_format(format: &str) -> Result<bool> {
+    match format {
+        "flat" => Ok(false),
+        "tree" => Ok(true),
+        _ => anyhow::bail!("invalid --format value: {format:?}\nExpected: flat or tree"),
+    }
+}
+
+/// A labelled node of a text tree.
+#[derive(Debug, PartialEq)]
+pub(crate) struct TreeNode {
+    pub(crate) label: String,
+    pub(crate) children: Vec<TreeNode>,
+}
+
+impl Drop for TreeNode {
+    // The derived drop recurses once per level, which deep trees (long call
+    // chains) would overflow; flatten the children onto a heap stack instead.
+    fn drop(&mut self) {
+        let mut stack = std::mem::take(&mut self.children);
+        while let Some(mut node) = stack.pop() {
+            stack.append(&mut node.children);
+        }
+    }
+}
+
+/// An entity from the entities response, reduced to what the tree needs.
+struct Entity {
+    id: String,
+    name: String,
+    kind: String,
+    parent: Option<String>,
+}
+
+/// Reads an entity's id, name, type, and parent service. The endpoint is
+/// unstable, so the usual spellings of each field are accepted.
+fn decode_entity(entity: &serde_json::Value) -> Option<Entity> {
+    let attrs = entity.get("attributes").unwrap_or(entity);
+    let text = |v: Option<&serde_json::Value>| v.and_then(|v| v.as_str()).map(str::to_string);
+    let id = text(entity.get("id")).or_else(|| text(attrs.get("name")))?;
+    let name = text(attrs.get("name"))
+        .or_else(|| text(attrs.get("service")))
+        .unwrap_or_else(|| id.clone());
+    let kind = text(attrs.get("type"))
+        .or_else(|| text(attrs.get("kind")))
+        .or_else(|| text(entity.get("type")))
+        .unwrap_or_else(|| "unknown".into());
+    let parent = text(entity.pointer("/relationships/parent/data/id"))
+        .or_else(|| text(attrs.get("base_service")))
+        .or_else(|| text(attrs.get("parent_service")));
+    Some(Entity {
+        id,
+        name,
+        kind,
+        parent,
+    })
+}
+
+/// Groups entities by type, nesting each inferred dependency under the
+/// service it was inferred from when that service is in the response.
+/// Entities without a known parent stay flat under their type. Services come
+/// first, then the other types alphabetically; names sort within a level.
+fn entity_tree(data: &serde_json::Value) -> Vec<TreeNode> {
+    let rows = data
+        .get("data")
+        .unwrap_or(data)
+        .as_array()
+        .map(|a| a.as_slice())
+        .unwrap_or_default();
+    let entities: Vec<Entity> = rows.iter().filter_map(decode_entity).collect();
+
+    let parent_of = |e: &Entity| -> Option<usize> {
+        let parent = e.parent.as_deref()?;
+        entities
+            .iter()
+            .position(|p| p.kind == "service" && (p.id == parent || p.name == parent))
+            .filter(|&i| entities[i].id != e.id)
+    };
+    let mut children: Vec<Vec<usize>> = vec![Vec::new(); entities.len()];
+    let mut nested = vec![false; entities.len()];
+    for (i, e) in entities.iter().enumerate() {
+        if let Some(p) = parent_of(e) {
+            children[p].push(i);
+            nested[i] = true;
+        }
+    }
+
+    let by_name =
+        |ids: &mut Vec<usize>| ids.sort_by(|a, b| entities[*a].name.cmp(&entities[*b].name));
+    let mut groups: std::collections::BTreeMap<&str, Vec<usize>> =
+        std::collections::BTreeMap::new();
+    for (i, e) in entities.iter().enumerate() {
+        if !nested[i] {
+            groups.entry(e.kind.as_str()).or_default().push(i);
+        }
+    }
+    let mut kinds: Vec<&str> = groups.keys().copied().collect();
+    kinds.sort_by_key(|k| (*k != "service", *k));
+
+    kinds
+        .into_iter()
+        .map(|kind| {
+            let mut ids = groups.remove(kind).unwrap_or_default();
+            by_name(&mut ids);
+            let children = ids
+                .into_iter()
+                .map(|i| {
+                    let mut deps = children[i].clone();
+                    by_name(&mut deps);
+                    TreeNode {
+                        label: entities[i].name.clone(),
+                        children: deps
+                            .into_iter()
+                            .map(|d| TreeNode {
+                                label: format!("{} ({})", entities[d].name, entities[d].kind),
+                                children: Vec::new(),
+                            })
+                            .collect(),
+                    }
+                })
+                .collect();
+            TreeNode {
+                label: kind.to_string(),
+                children,
+            }
+        })
+        .collect()
+}
+
+/// Renders root nodes with box-drawing branches beneath each root. Walks
+/// with an explicit stack so deep trees (long call chains) can't overflow.
+pub(crate) fn render_tree(roots: &[TreeNode]) -> String {
+    if roots.is_empty() {
+        return "No entities found\n".to_string();
+    }
+    let mut out = String::new();
+    for root in roots {
+        out.push_str(&root.label);
+        out.push('\n');
+        // (node, prefix of its line, whether it is the last of its siblings)
+        let mut stack: Vec<(&TreeNode, String, bool)> = Vec::new();
+        push_children(&mut stack, &root.children, "");
+        while let Some((node, prefix, last)) = stack.pop() {
+            out.push_str(&prefix);
+            out.push_str(if last { "└── " } else { "├── " });
+            out.push_str(&node.label);
+            out.push('\n');
+            let next = format!("{prefix}{}", if last { "    " } else { "│   " });
+            push_children(&mut stack, &node.children, &next);
+        }
+    }
+    out
+}
+
+/// Queues `nodes` so they pop off `stack` first to last.
+fn push_children<'a>(
+    stack: &mut Vec<(&'a TreeNode, String, bool)>,
+    nodes: &'a [TreeNode],
+    prefix: &str,
+) {
+    for (i, node) in nodes.iter().enumerate().rev() {
+        stack.push((node, prefix.to_string(), i + 1 == nodes.len()));
+    }
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn dependencies_list(cfg: &Config, env: String, from: String, to: String) -> Result<()> {
     let from_ts = util::parse_time_to_unix(&from)?;
     let to_ts = util::parse_time_to_unix(&to)?;
     let path = format!("/api/v1/service_dependencies?start={from_ts}&end={to_ts}&env={env}");
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+    let raw = client::raw_get_json(cfg, &path).await?;
+    formatter::output_raw_json(cfg, &raw)
 }
 
 #[cfg(target_arch = "wasm32")]
@@ -101,8 +277,8 @@ pub async fn services_operations(
     let to_ts = util::parse_time_to_unix(&to)?;
     let path =
         format!("/api/v1/trace/operation_names/{service}?env={env}&start={from_ts}&end={to_ts}");
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+    let raw = client::raw_get_json(cfg, &path).await?;
+    formatter::output_raw_json(cfg, &raw)
 }
 
 #[cfg(target_arch = "wasm32")]
@@ -139,8 +315,8 @@ pub async fn services_resources(
     let path = format!(
         "/api/ui/apm/resources?service={service}&operation={operation}&env={env}&start={from_ts}&end={to_ts}"
     );
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+    let raw = client::raw_get_json(cfg, &path).await?;
+    formatter::output_raw_json(cfg, &raw)
 }
 
 #[cfg(target_arch = "wasm32")]
@@ -177,8 +353,8 @@ pub async fn flow_map(
     let to_ts = util::parse_time_to_unix(&to)?;
     let path =
         format!("/api/ui/apm/flow-map?query={query}&limit={limit}&start={from_ts}&end={to_ts}");
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+    let raw = client::raw_get_json(cfg, &path).await?;
+    formatter::output_raw_json(cfg, &raw)
 }
 
 #[cfg(target_arch = "wasm32")]
@@ -200,3 +376,51 @@ pub async fn flow_map(
     let data = crate::api::get(cfg, "/api/ui/apm/flow-map", &q).await?;
     crate::formatter::output(cfg, &data)
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_entity_tree_nests_inferred_dependencies() {
+        let data = serde_json::json!({"data": [
+            {"id": "svc-1", "type": "entity", "attributes": {"name": "web-store", "type": "service"}},
+            {"id": "db-1", "type": "entity", "attributes": {
+                "name": "postgres", "type": "datastore", "base_service": "web-store"
+            }},
+            {"id": "q-1", "type": "entity", "attributes": {"name": "orders", "type": "queue"}},
+            {"id": "svc-2", "type": "entity", "attributes": {"name": "checkout", "type": "service"}}
+        ]});
+        let expected = [
+            "service",
+            "├── checkout",
+            "└── web-store",
+            "    └── postgres (datastore)",
+            "queue",
+            "└── orders",
+        ];
+        assert_eq!(
+            render_tree(&entity_tree(&data)),
+            format!("{}\n", expected.join("\n"))
+        );
+    }
+
+    #[test]
+    fn test_entity_tree_unknown_parent_stays_flat() {
+        let data = serde_json::json!({"data": [
+            {"id": "db-1", "attributes": {"name": "redis", "type": "datastore", "base_service": "gone"}}
+        ]});
+        let tree = entity_tree(&data);
+        assert_eq!(tree.len(), 1);
+        assert_eq!(tree[0].label, "datastore");
+        assert_eq!(tree[0].children[0].label, "redis");
+        assert_eq!(render_tree(&[]), "No entities found\n");
+    }
+
+    #[test]
+    fn test_is_tree_format() {
+        assert!(!is_tree_format("flat").unwrap());
+        assert!(is_tree_format("tree").unwrap());
+        assert!(is_tree_format("graph").is_err());
+    }
+}
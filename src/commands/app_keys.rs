@@ -127,7 +127,7 @@ pub async fn unregister(cfg: &Config, key_id: &str) -> Result<()> {
     api.unregister_app_key(key_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to unregister app key: {e:?}"))?;
-    println!("Successfully unregistered app key {key_id}");
+    formatter::print_block(&format!("Successfully unregistered app key {key_id}\n"))?;
     Ok(())
 }
 
@@ -138,6 +138,6 @@ pub async fn unregister(cfg: &Config, key_id: &str) -> Result<()> {
         &format!("/api/v2/integration/action_connections/app-keys/{key_id}"),
     )
     .await?;
-    println!("Successfully unregistered app key {key_id}");
+    formatter::print_block(&format!("Successfully unregistered app key {key_id}\n"))?;
     Ok(())
 }
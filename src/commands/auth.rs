@@ -86,8 +86,59 @@ pub async fn login(cfg: &Config) -> Result<()> {
         .exchange_code(&result.code, &redirect_uri, &challenge.verifier, &creds)
         .await?;
 
+    save_tokens_and_report(site, &tokens)
+}
+
+/// Upper bound on how long `auth login --device` waits for approval.
+#[cfg(not(target_arch = "wasm32"))]
+const DEVICE_LOGIN_TIMEOUT_SECS: u64 = 900;
+
+/// Device authorization login (RFC 8628) for machines without a browser:
+/// prints a code and URL to open anywhere, then polls until approved.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn login_device(cfg: &Config) -> Result<()> {
+    use crate::auth::{dcr, device, types};
+
+    let site = &cfg.site;
+    crate::formatter::print_status(&format!("\n🔐 Starting device login for site: {site}\n"));
+
+    // The stored client may predate device support, so register one that
+    // allows the device grant and keep it for later refreshes.
+    crate::formatter::print_status("📝 Registering OAuth2 client...");
+    let creds = dcr::DcrClient::new(site).register_device().await?;
+    with_storage(|store| store.save_client_credentials(site, &creds))?;
+    crate::formatter::print_status(&format!("✓ Registered client: {}", creds.client_id));
+
+    let device_client = device::DeviceClient::new(site)?;
+    let scopes = types::default_scopes();
+    let auth = device_client.authorize(&creds.client_id, &scopes).await?;
+
+    crate::formatter::print_status("\n🔑 On any device with a browser, open:");
+    crate::formatter::print_status(&format!("   {}", auth.verification_uri));
+    crate::formatter::print_status(&format!("   and enter the code: {}", auth.user_code));
+    if let Some(complete) = &auth.verification_uri_complete {
+        crate::formatter::print_status(&format!("   (or open {complete} to skip typing the code)"));
+    }
+
+    crate::formatter::print_status(&format!(
+        "\n⏳ Waiting for authorization (code expires in {} minutes)...",
+        auth.expires_in.div_ceil(60)
+    ));
+    let tokens = device_client
+        .poll_for_token(
+            &auth,
+            &creds.client_id,
+            std::time::Duration::from_secs(DEVICE_LOGIN_TIMEOUT_SECS),
+        )
+        .await?;
+
+    save_tokens_and_report(site, &tokens)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+fn save_tokens_and_report(site: &str, tokens: &crate::auth::types::TokenSet) -> Result<()> {
     let location = with_storage(|store| {
-        store.save_tokens(site, &tokens)?;
+        store.save_tokens(site, tokens)?;
         Ok(store.storage_location())
     })?;
 
@@ -99,9 +150,9 @@ pub async fn login(cfg: &Config) -> Result<()> {
     } else {
         location
     };
-    eprintln!("\n✅ Login successful!");
-    eprintln!("   Access token expires: {expires_at}");
-    eprintln!("   Token stored in: {display_location}");
+    crate::formatter::print_status("\n✅ Login successful!");
+    crate::formatter::print_status(&format!("   Access token expires: {expires_at}"));
+    crate::formatter::print_status(&format!("   Token stored in: {display_location}"));
 
     Ok(())
 }
@@ -115,6 +166,11 @@ pub async fn login(_cfg: &Config) -> Result<()> {
     )
 }
 
+#[cfg(target_arch = "wasm32")]
+pub async fn login_device(cfg: &Config) -> Result<()> {
+    login(cfg).await
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn logout(cfg: &Config) -> Result<()> {
     let site = &cfg.site;
@@ -142,9 +198,9 @@ pub fn status(cfg: &Config) -> Result<()> {
     #[cfg(target_arch = "wasm32")]
     {
         if cfg.has_bearer_token() || cfg.has_api_keys() {
-            println!("✅ Authenticated for site: {site}");
+            crate::formatter::print_block(&format!("✅ Authenticated for site: {site}\n"))?;
         } else {
-            println!("❌ Not authenticated for site: {site}");
+            crate::formatter::print_block(&format!("❌ Not authenticated for site: {site}\n"))?;
         }
         return Ok(());
     }
@@ -184,7 +240,10 @@ pub fn status(cfg: &Config) -> Result<()> {
                     "status": status,
                     "token_type": tokens.token_type,
                 });
-                println!("{}", serde_json::to_string_pretty(&json).unwrap());
+                crate::formatter::print_block(&format!(
+                    "{}\n",
+                    serde_json::to_string_pretty(&json).unwrap()
+                ))?;
             }
             None => {
                 eprintln!("❌ Not authenticated for site: {site}");
@@ -193,7 +252,10 @@ pub fn status(cfg: &Config) -> Result<()> {
                     "site": site,
                     "status": "no token",
                 });
-                println!("{}", serde_json::to_string_pretty(&json).unwrap());
+                crate::formatter::print_block(&format!(
+                    "{}\n",
+                    serde_json::to_string_pretty(&json).unwrap()
+                ))?;
             }
         }
         Ok(())
@@ -202,7 +264,7 @@ pub fn status(cfg: &Config) -> Result<()> {
 
 pub fn token(cfg: &Config) -> Result<()> {
     if let Some(token) = &cfg.access_token {
-        println!("{token}");
+        crate::formatter::print_block(&format!("{token}\n"))?;
         return Ok(());
     }
 
@@ -217,7 +279,7 @@ pub fn token(cfg: &Config) -> Result<()> {
                 if tokens.is_expired() {
                     bail!("token is expired — run 'pup auth login' to refresh");
                 }
-                println!("{}", tokens.access_token);
+                crate::formatter::print_block(&format!("{}\n", tokens.access_token))?;
                 Ok(())
             }
             None => bail!("no token available — run 'pup auth login' or set DD_ACCESS_TOKEN"),
@@ -0,0 +1,159 @@
+use anyhow::{bail, Result};
+use serde::Deserialize;
+
+/// One step of a `pup batch` file: a command path plus its flags/arguments.
+///
+/// ```yaml
+/// steps:
+///   - name: prod-monitors
+///     command: monitors list
+///     args: ["--tags", "env:prod"]
+///   - command: monitors get
+///     args: ["12345"]
+/// ```
+#[derive(Debug, Deserialize, PartialEq)]
+pub struct BatchStep {
+    #[serde(default)]
+    pub name: Option<String>,
+    pub command: String,
+    #[serde(default)]
+    pub args: Vec<String>,
+}
+
+/// Commands that cannot run as a batch step: another batch, and local
+/// commands that print straight to the terminal or prompt interactively
+/// instead of producing a result.
+const UNBATCHABLE: &[&str] = &[
+    "agent",
+    "alias",
+    "auth",
+    "batch",
+    "completions",
+    "test",
+    "version",
+];
+
+#[derive(Deserialize)]
+struct BatchFile {
+    steps: Vec<BatchStep>,
+}
+
+impl BatchStep {
+    /// Command line for this step, as if typed after `pup`.
+    pub fn argv(&self) -> Vec<String> {
+        std::iter::once("pup".to_string())
+            .chain(self.command.split_whitespace().map(str::to_string))
+            .chain(self.args.iter().cloned())
+            .collect()
+    }
+}
+
+/// Reads batch steps from a YAML (or JSON) file.
+pub fn load_steps(path: &str) -> Result<Vec<BatchStep>> {
+    let contents = std::fs::read_to_string(path)
+        .map_err(|e| anyhow::anyhow!("failed to read batch file {path}: {e}"))?;
+    parse_steps(&contents).map_err(|e| anyhow::anyhow!("invalid batch file {path}: {e}"))
+}
+
+fn parse_steps(contents: &str) -> Result<Vec<BatchStep>> {
+    let file: BatchFile = serde_yaml::from_str(contents)?;
+    if file.steps.is_empty() {
+        bail!("no steps defined");
+    }
+    for (i, step) in file.steps.iter().enumerate() {
+        match step.command.split_whitespace().next() {
+            None => bail!("step {} has an empty command", i + 1),
+            Some("batch") => bail!("step {} runs another batch, which is not allowed", i + 1),
+            Some(cmd) if UNBATCHABLE.contains(&cmd) => {
+                bail!(
+                    "step {} runs `{cmd}`, which cannot be used in a batch",
+                    i + 1
+                )
+            }
+            Some(_) => {}
+        }
+    }
+    Ok(file.steps)
+}
+
+/// Builds the output entry for step `index` (0-based): the step's command
+/// and either its captured output as `result` or the failure as `error`.
+pub fn step_block(
+    index: usize,
+    step: &BatchStep,
+    outcome: std::result::Result<Vec<serde_json::Value>, String>,
+) -> serde_json::Value {
+    let mut block = serde_json::json!({
+        "step": index + 1,
+        "command": step.command.split_whitespace().collect::<Vec<_>>().join(" "),
+        "args": step.args,
+    });
+    if let Some(name) = &step.name {
+        block["name"] = serde_json::Value::String(name.clone());
+    }
+    match outcome {
+        Ok(values) => block["result"] = crate::formatter::captured_result(values),
+        Err(error) => block["error"] = serde_json::Value::String(error),
+    }
+    block
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parse_steps() {
+        let yaml = r#"
+steps:
+  - name: prod-monitors
+    command: monitors list
+    args:
+      - --tags=env:prod
+  - command: monitors get
+    args:
+      - "12345"
+"#;
+        let steps = parse_steps(yaml).unwrap();
+        assert_eq!(steps.len(), 2);
+        assert_eq!(steps[0].name.as_deref(), Some("prod-monitors"));
+        assert_eq!(
+            steps[0].argv(),
+            ["pup", "monitors", "list", "--tags=env:prod"]
+        );
+        assert_eq!(steps[1].argv(), ["pup", "monitors", "get", "12345"]);
+    }
+
+    #[test]
+    fn test_parse_steps_rejects_bad_files() {
+        assert!(parse_steps(r#"{"steps": []}"#).is_err());
+        assert!(parse_steps(r#"{"steps": [{"command": " "}]}"#).is_err());
+        let err = parse_steps(r#"{"steps": [{"command": "batch", "args": ["--file", "x"]}]}"#)
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("another batch"), "{err}");
+        let err = parse_steps(r#"{"steps": [{"command": "auth login"}]}"#)
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("`auth`"), "{err}");
+    }
+
+    #[test]
+    fn test_step_block() {
+        let step = BatchStep {
+            name: None,
+            command: "monitors  get".into(),
+            args: vec!["1".into()],
+        };
+        let ok = step_block(0, &step, Ok(vec![serde_json::json!({"id": 1})]));
+        assert_eq!(ok["step"], 1);
+        assert_eq!(ok["command"], "monitors get");
+        assert_eq!(ok["result"]["id"], 1);
+        assert!(ok.get("name").is_none());
+
+        let failed = step_block(1, &step, Err("boom".into()));
+        assert_eq!(failed["step"], 2);
+        assert_eq!(failed["error"], "boom");
+        assert!(failed.get("result").is_none());
+    }
+}
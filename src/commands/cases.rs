@@ -38,9 +38,18 @@ fn make_api(cfg: &Config) -> CaseManagementAPI {
 // ---------------------------------------------------------------------------
 
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn search(cfg: &Config, _query: Option<String>, page_size: i64) -> Result<()> {
+pub async fn search(
+    cfg: &Config,
+    query: Option<String>,
+    assignee: Option<String>,
+    page_size: i64,
+) -> Result<()> {
+    let filter = search_filter(cfg, query, assignee).await?;
     let api = make_api(cfg);
-    let params = SearchCasesOptionalParams::default().page_size(page_size);
+    let mut params = SearchCasesOptionalParams::default().page_size(page_size);
+    if let Some(filter) = filter {
+        params = params.filter(filter);
+    }
     let resp = api
         .search_cases(params)
         .await
@@ -49,12 +58,45 @@ pub async fn search(cfg: &Config, _query: Option<String>, page_size: i64) -> Res
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn search(cfg: &Config, _query: Option<String>, page_size: i64) -> Result<()> {
-    let q = vec![("page[size]", page_size.to_string())];
+pub async fn search(
+    cfg: &Config,
+    query: Option<String>,
+    assignee: Option<String>,
+    page_size: i64,
+) -> Result<()> {
+    let mut q = vec![("page[size]", page_size.to_string())];
+    if let Some(filter) = search_filter(cfg, query, assignee).await? {
+        q.push(("filter", filter));
+    }
     let data = crate::api::get(cfg, "/api/v2/cases", &q).await?;
     crate::formatter::output(cfg, &data)
 }
 
+/// Search filter from the free-text query plus `assignee:<user id>`, with
+/// `--assignee me` / `@handle` resolved to the user's id first.
+async fn search_filter(
+    cfg: &Config,
+    query: Option<String>,
+    assignee: Option<String>,
+) -> Result<Option<String>> {
+    let assignee_id = match assignee {
+        Some(user) => Some(crate::commands::users::resolve_user_id(cfg, &user).await?),
+        None => None,
+    };
+    Ok(case_filter(query.as_deref(), assignee_id.as_deref()))
+}
+
+fn case_filter(query: Option<&str>, assignee_id: Option<&str>) -> Option<String> {
+    let parts: Vec<String> = query
+        .map(str::trim)
+        .filter(|q| !q.is_empty())
+        .map(str::to_string)
+        .into_iter()
+        .chain(assignee_id.map(|id| format!("assignee:{id}")))
+        .collect();
+    (!parts.is_empty()).then(|| parts.join(" "))
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn get(cfg: &Config, case_id: &str) -> Result<()> {
     let api = make_api(cfg);
@@ -160,7 +202,7 @@ pub async fn projects_delete(cfg: &Config, project_id: &str) -> Result<()> {
     api.delete_project(project_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete project: {e:?}"))?;
-    println!("Project {project_id} deleted.");
+    formatter::print_block(&format!("Project {project_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -171,7 +213,7 @@ pub async fn projects_delete(cfg: &Config, project_id: &str) -> Result<()> {
         &format!("/api/v2/case-management/projects/{project_id}"),
     )
     .await?;
-    println!("Project {project_id} deleted.");
+    formatter::print_block(&format!("Project {project_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -379,7 +421,7 @@ pub async fn jira_create_issue(cfg: &Config, case_id: &str, file: &str) -> Resul
     api.create_case_jira_issue(case_id.to_string(), body)
         .await
         .map_err(|e| anyhow::anyhow!("failed to create Jira issue for case: {e:?}"))?;
-    println!("Jira issue created for case '{case_id}'.");
+    formatter::print_block(&format!("Jira issue created for case '{case_id}'.\n"))?;
     Ok(())
 }
 
@@ -387,7 +429,7 @@ pub async fn jira_create_issue(cfg: &Config, case_id: &str, file: &str) -> Resul
 pub async fn jira_create_issue(cfg: &Config, case_id: &str, file: &str) -> Result<()> {
     let body: serde_json::Value = crate::util::read_json_file(file)?;
     crate::api::post(cfg, &format!("/api/v2/cases/{case_id}/jira_issue"), &body).await?;
-    println!("Jira issue created for case '{case_id}'.");
+    formatter::print_block(&format!("Jira issue created for case '{case_id}'.\n"))?;
     Ok(())
 }
 
@@ -398,7 +440,7 @@ pub async fn jira_link(cfg: &Config, case_id: &str, file: &str) -> Result<()> {
     api.link_jira_issue_to_case(case_id.to_string(), body)
         .await
         .map_err(|e| anyhow::anyhow!("failed to link Jira issue to case: {e:?}"))?;
-    println!("Jira issue linked to case '{case_id}'.");
+    formatter::print_block(&format!("Jira issue linked to case '{case_id}'.\n"))?;
     Ok(())
 }
 
@@ -411,7 +453,7 @@ pub async fn jira_link(cfg: &Config, case_id: &str, file: &str) -> Result<()> {
         &body,
     )
     .await?;
-    println!("Jira issue linked to case '{case_id}'.");
+    formatter::print_block(&format!("Jira issue linked to case '{case_id}'.\n"))?;
     Ok(())
 }
 
@@ -421,14 +463,14 @@ pub async fn jira_unlink(cfg: &Config, case_id: &str) -> Result<()> {
     api.unlink_jira_issue(case_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to unlink Jira issue from case: {e:?}"))?;
-    println!("Jira issue unlinked from case '{case_id}'.");
+    formatter::print_block(&format!("Jira issue unlinked from case '{case_id}'.\n"))?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
 pub async fn jira_unlink(cfg: &Config, case_id: &str) -> Result<()> {
     crate::api::delete(cfg, &format!("/api/v2/cases/{case_id}/jira_issue")).await?;
-    println!("Jira issue unlinked from case '{case_id}'.");
+    formatter::print_block(&format!("Jira issue unlinked from case '{case_id}'.\n"))?;
     Ok(())
 }
 
@@ -443,7 +485,9 @@ pub async fn servicenow_create_ticket(cfg: &Config, case_id: &str, file: &str) -
     api.create_case_service_now_ticket(case_id.to_string(), body)
         .await
         .map_err(|e| anyhow::anyhow!("failed to create ServiceNow ticket for case: {e:?}"))?;
-    println!("ServiceNow ticket created for case '{case_id}'.");
+    formatter::print_block(&format!(
+        "ServiceNow ticket created for case '{case_id}'.\n"
+    ))?;
     Ok(())
 }
 
@@ -456,7 +500,9 @@ pub async fn servicenow_create_ticket(cfg: &Config, case_id: &str, file: &str) -
         &body,
     )
     .await?;
-    println!("ServiceNow ticket created for case '{case_id}'.");
+    formatter::print_block(&format!(
+        "ServiceNow ticket created for case '{case_id}'.\n"
+    ))?;
     Ok(())
 }
 
@@ -528,7 +574,7 @@ pub async fn projects_notification_rules_update(
     api.update_project_notification_rule(project_id.to_string(), rule_id.to_string(), body)
         .await
         .map_err(|e| anyhow::anyhow!("failed to update notification rule: {e:?}"))?;
-    println!("Notification rule '{rule_id}' updated.");
+    formatter::print_block(&format!("Notification rule '{rule_id}' updated.\n"))?;
     Ok(())
 }
 
@@ -546,7 +592,7 @@ pub async fn projects_notification_rules_update(
         &body,
     )
     .await?;
-    println!("Notification rule '{rule_id}' updated.");
+    formatter::print_block(&format!("Notification rule '{rule_id}' updated.\n"))?;
     Ok(())
 }
 
@@ -560,7 +606,7 @@ pub async fn projects_notification_rules_delete(
     api.delete_project_notification_rule(project_id.to_string(), rule_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete notification rule: {e:?}"))?;
-    println!("Notification rule '{rule_id}' deleted.");
+    formatter::print_block(&format!("Notification rule '{rule_id}' deleted.\n"))?;
     Ok(())
 }
 
@@ -575,7 +621,7 @@ pub async fn projects_notification_rules_delete(
         &format!("/api/v2/case-management/projects/{project_id}/notification_rules/{rule_id}"),
     )
     .await?;
-    println!("Notification rule '{rule_id}' deleted.");
+    formatter::print_block(&format!("Notification rule '{rule_id}' deleted.\n"))?;
     Ok(())
 }
 
@@ -664,3 +710,26 @@ pub async fn projects_update(cfg: &Config, project_id: &str, file: &str) -> Resu
     .await?;
     crate::formatter::output(cfg, &data)
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_case_filter() {
+        assert_eq!(case_filter(None, None), None);
+        assert_eq!(case_filter(Some("  "), None), None);
+        assert_eq!(
+            case_filter(Some("status:open"), None).as_deref(),
+            Some("status:open")
+        );
+        assert_eq!(
+            case_filter(Some("status:open"), Some("u-1")).as_deref(),
+            Some("status:open assignee:u-1")
+        );
+        assert_eq!(
+            case_filter(None, Some("u-1")).as_deref(),
+            Some("assignee:u-1")
+        );
+    }
+}
@@ -451,7 +451,9 @@ pub async fn dora_patch_deployment(cfg: &Config, deployment_id: &str, file: &str
     api.patch_dora_deployment(deployment_id.to_string(), body)
         .await
         .map_err(|e| anyhow::anyhow!("failed to patch DORA deployment: {e:?}"))?;
-    println!("DORA deployment '{deployment_id}' patched successfully.");
+    formatter::print_block(&format!(
+        "DORA deployment '{deployment_id}' patched successfully.\n"
+    ))?;
     Ok(())
 }
 
@@ -460,7 +462,9 @@ pub async fn dora_patch_deployment(cfg: &Config, deployment_id: &str, file: &str
     let body: serde_json::Value = crate::util::read_json_file(file)?;
     let path = format!("/api/v2/dora/deployments/{deployment_id}");
     crate::api::patch(cfg, &path, &body).await?;
-    println!("DORA deployment '{deployment_id}' patched successfully.");
+    formatter::print_block(&format!(
+        "DORA deployment '{deployment_id}' patched successfully.\n"
+    ))?;
     Ok(())
 }
 
@@ -176,7 +176,7 @@ pub async fn oci_tenancies_delete(cfg: &Config, tenancy_id: &str) -> Result<()>
     api.delete_tenancy_config(tenancy_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete OCI tenancy: {e:?}"))?;
-    println!("OCI tenancy '{tenancy_id}' deleted.");
+    formatter::print_block(&format!("OCI tenancy '{tenancy_id}' deleted.\n"))?;
     Ok(())
 }
 
@@ -187,7 +187,7 @@ pub async fn oci_tenancies_delete(cfg: &Config, tenancy_id: &str) -> Result<()>
         &format!("/api/v2/integration/oci/tenancy_configs/{tenancy_id}"),
     )
     .await?;
-    println!("OCI tenancy '{tenancy_id}' deleted.");
+    formatter::print_block(&format!("OCI tenancy '{tenancy_id}' deleted.\n"))?;
     Ok(())
 }
 
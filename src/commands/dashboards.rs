@@ -2,7 +2,9 @@ use anyhow::Result;
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::api_dashboards::{DashboardsAPI, ListDashboardsOptionalParams};
 #[cfg(not(target_arch = "wasm32"))]
-use datadog_api_client::datadogV1::model::Dashboard;
+use datadog_api_client::datadogV1::model::{
+    Dashboard, DashboardBulkActionData, DashboardResourceType, DashboardRestoreRequest,
+};
 
 #[cfg(not(target_arch = "wasm32"))]
 use crate::client;
@@ -10,23 +12,33 @@ use crate::config::Config;
 use crate::formatter;
 use crate::util;
 
+/// Lists dashboards; with `deleted`, lists recently deleted ones instead so
+/// they can be found for `dashboards restore`.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn list(cfg: &Config) -> Result<()> {
+pub async fn list(cfg: &Config, deleted: bool) -> Result<()> {
     let dd_cfg = client::make_dd_config(cfg);
     let api = match client::make_bearer_client(cfg) {
         Some(c) => DashboardsAPI::with_client_and_config(dd_cfg, c),
         None => DashboardsAPI::with_config(dd_cfg),
     };
+    let mut params = ListDashboardsOptionalParams::default();
+    if deleted {
+        params = params.filter_deleted(true);
+    }
     let resp = api
-        .list_dashboards(ListDashboardsOptionalParams::default())
+        .list_dashboards(params)
         .await
         .map_err(|e| anyhow::anyhow!("failed to list dashboards: {e:?}"))?;
     formatter::output(cfg, &resp)
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn list(cfg: &Config) -> Result<()> {
-    let data = crate::api::get(cfg, "/api/v1/dashboard", &[]).await?;
+pub async fn list(cfg: &Config, deleted: bool) -> Result<()> {
+    let mut query = vec![];
+    if deleted {
+        query.push(("filter[deleted]", "true".to_string()));
+    }
+    let data = crate::api::get(cfg, "/api/v1/dashboard", &query).await?;
     crate::formatter::output(cfg, &data)
 }
 
@@ -113,3 +125,438 @@ pub async fn delete(cfg: &Config, id: &str) -> Result<()> {
     let data = crate::api::delete(cfg, &format!("/api/v1/dashboard/{id}")).await?;
     crate::formatter::output(cfg, &data)
 }
+
+/// Restores deleted dashboards in one bulk request, then prints them.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restore(cfg: &Config, ids: &[String]) -> Result<()> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => DashboardsAPI::with_client_and_config(dd_cfg, c),
+        None => DashboardsAPI::with_config(dd_cfg),
+    };
+    let data = ids
+        .iter()
+        .map(|id| DashboardBulkActionData::new(id.clone(), DashboardResourceType::DASHBOARD))
+        .collect();
+    api.restore_dashboards(DashboardRestoreRequest::new(data))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to restore dashboards: {e:?}"))?;
+    formatter::print_status(&format!("Restored {} dashboard(s).", ids.len()));
+
+    let mut restored = Vec::with_capacity(ids.len());
+    for id in ids {
+        let dashboard = api
+            .get_dashboard(id.clone())
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to get restored dashboard {id}: {e:?}"))?;
+        restored.push(serde_json::to_value(dashboard)?);
+    }
+    output_restored(cfg, restored)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restore(cfg: &Config, ids: &[String]) -> Result<()> {
+    crate::api::patch(cfg, "/api/v1/dashboard", &restore_body(ids)).await?;
+    formatter::print_status(&format!("Restored {} dashboard(s).", ids.len()));
+
+    let mut restored = Vec::with_capacity(ids.len());
+    for id in ids {
+        restored.push(crate::api::get(cfg, &format!("/api/v1/dashboard/{id}"), &[]).await?);
+    }
+    output_restored(cfg, restored)
+}
+
+/// Request body for the bulk restore endpoint.
+#[cfg(target_arch = "wasm32")]
+fn restore_body(ids: &[String]) -> serde_json::Value {
+    let data: Vec<_> = ids
+        .iter()
+        .map(|id| serde_json::json!({"id": id, "type": "dashboard"}))
+        .collect();
+    serde_json::json!({ "data": data })
+}
+
+/// A single restored dashboard prints as itself; several print as a list.
+fn output_restored(cfg: &Config, mut restored: Vec<serde_json::Value>) -> Result<()> {
+    if restored.len() == 1 {
+        return formatter::output(cfg, &restored.remove(0));
+    }
+    formatter::output(cfg, &restored)
+}
+
+// ---- Export and import ----
+
+/// Server-assigned dashboard fields left out of exported definitions.
+const SERVER_FIELDS: &[&str] = &[
+    "id",
+    "author_handle",
+    "author_name",
+    "created_at",
+    "modified_at",
+    "url",
+];
+
+/// Writes a dashboard's definition to `file` (JSON, or YAML for a `.yaml`
+/// or `.yml` path), keeping widgets, layout, and template variables as
+/// returned by the API so `dashboards import` can recreate it unchanged.
+pub async fn export(cfg: &Config, id: &str, file: &str) -> Result<()> {
+    let dashboard = fetch_dashboard(cfg, id).await?;
+    let definition = portable_definition(dashboard);
+    let contents = if is_yaml(file) {
+        serde_yaml::to_string(&definition)?
+    } else {
+        serde_json::to_string_pretty(&definition)? + "\n"
+    };
+    std::fs::write(file, contents).map_err(|e| anyhow::anyhow!("failed to write {file:?}: {e}"))?;
+    formatter::print_status(&format!("Exported dashboard {id} to {file}."));
+    Ok(())
+}
+
+/// Creates a dashboard from an exported definition, or overwrites dashboard
+/// `update` with it. The definition is sent as-is rather than through the
+/// typed model, so widget fields the client does not know survive.
+pub async fn import(cfg: &Config, file: &str, update: Option<&str>) -> Result<()> {
+    let definition = portable_definition(read_definition(file)?);
+    if definition["title"].as_str().unwrap_or_default().is_empty() {
+        anyhow::bail!("{file}: dashboard definition has no title");
+    }
+    if definition["layout_type"]
+        .as_str()
+        .unwrap_or_default()
+        .is_empty()
+    {
+        anyhow::bail!("{file}: dashboard definition has no layout_type");
+    }
+    let data = match update {
+        Some(id) => put_dashboard(cfg, id, &definition).await?,
+        None => post_dashboard(cfg, &definition).await?,
+    };
+    formatter::output(cfg, &data)
+}
+
+/// Reads a dashboard definition object from a JSON or YAML file.
+fn read_definition(file: &str) -> Result<serde_json::Value> {
+    let definition: serde_json::Value = if is_yaml(file) {
+        let contents = std::fs::read_to_string(file)
+            .map_err(|e| anyhow::anyhow!("failed to read file {file:?}: {e}"))?;
+        serde_yaml::from_str(&contents)
+            .map_err(|e| anyhow::anyhow!("failed to parse YAML from {file:?}: {e}"))?
+    } else {
+        util::read_json_file(file)?
+    };
+    if !definition.is_object() {
+        anyhow::bail!("{file}: expected a dashboard definition object");
+    }
+    Ok(definition)
+}
+
+fn is_yaml(file: &str) -> bool {
+    file.ends_with(".yaml") || file.ends_with(".yml")
+}
+
+/// A dashboard as written to a definition file: server fields and nulls
+/// dropped at the top level, everything else (widgets with their layout,
+/// template variables and presets, notify list) kept verbatim.
+fn portable_definition(dashboard: serde_json::Value) -> serde_json::Value {
+    let serde_json::Value::Object(mut fields) = dashboard else {
+        return dashboard;
+    };
+    for field in SERVER_FIELDS {
+        fields.remove(*field);
+    }
+    fields.retain(|_, v| !v.is_null());
+    serde_json::Value::Object(fields)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn fetch_dashboard(cfg: &Config, id: &str) -> Result<serde_json::Value> {
+    client::raw_get(cfg, &format!("/api/v1/dashboard/{id}")).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn fetch_dashboard(cfg: &Config, id: &str) -> Result<serde_json::Value> {
+    crate::api::get(cfg, &format!("/api/v1/dashboard/{id}"), &[]).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn post_dashboard(cfg: &Config, body: &serde_json::Value) -> Result<serde_json::Value> {
+    client::raw_post(cfg, "/api/v1/dashboard", body.clone()).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn post_dashboard(cfg: &Config, body: &serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v1/dashboard", body).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn put_dashboard(
+    cfg: &Config,
+    id: &str,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    let path = format!("/api/v1/dashboard/{id}");
+    let resp = client::raw_request(cfg, "PUT", &path, Some(body)).await?;
+    Ok(resp.json().await?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn put_dashboard(
+    cfg: &Config,
+    id: &str,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    crate::api::put(cfg, &format!("/api/v1/dashboard/{id}"), body).await
+}
+
+// ---- Shared dashboards ----
+
+/// Flags describing a share for `dashboards shares create`.
+#[derive(Clone, Debug, Default)]
+pub struct ShareOptions {
+    /// "open" (anyone with the URL), "invite" (only invited emails, after
+    /// signing in), or "embed" (iframe on the allowed domains).
+    pub share_type: String,
+    /// How long the share stays valid, e.g. 30d; never expires if omitted.
+    pub expires_in: Option<String>,
+    /// Emails allowed to view an invite-only share.
+    pub invitees: Vec<String>,
+    /// Domains allowed to embed an embed share.
+    pub embeddable_domains: Vec<String>,
+    /// Default time frame, e.g. 1h or 1w.
+    pub live_span: Option<String>,
+    /// Let viewers change the time frame.
+    pub selectable_time: bool,
+}
+
+/// Lists shared dashboards, optionally only the shares of `dashboard_id`.
+pub async fn shares_list(cfg: &Config, dashboard_id: Option<&str>) -> Result<()> {
+    let data = shares_get_json(cfg, "/api/v1/dashboard/public").await?;
+    let mut shares = match data {
+        serde_json::Value::Array(shares) => shares,
+        other => other["shared_dashboards"]
+            .as_array()
+            .cloned()
+            .unwrap_or_default(),
+    };
+    if let Some(id) = dashboard_id {
+        shares.retain(|s| s["dashboard_id"].as_str() == Some(id));
+    }
+    formatter::output(cfg, &shares)
+}
+
+pub async fn shares_get(cfg: &Config, token: &str) -> Result<()> {
+    let data = shares_get_json(cfg, &format!("/api/v1/dashboard/public/{token}")).await?;
+    formatter::output(cfg, &data)
+}
+
+/// Shares `dashboard_id` and prints the new share, including its public URL.
+pub async fn shares_create(cfg: &Config, dashboard_id: &str, opts: &ShareOptions) -> Result<()> {
+    let dashboard = fetch_dashboard(cfg, dashboard_id).await?;
+    let body = share_body(dashboard_id, &dashboard, opts, chrono::Utc::now())?;
+    let data = post_dashboard_path(cfg, "/api/v1/dashboard/public", &body).await?;
+    if let Some(url) = data["public_url"].as_str() {
+        formatter::print_status(&format!("Shared dashboard {dashboard_id} at {url}"));
+    }
+    formatter::output(cfg, &data)
+}
+
+/// Revokes a share; its public URL stops working immediately.
+pub async fn shares_revoke(cfg: &Config, token: &str) -> Result<()> {
+    delete_dashboard_path(cfg, &format!("/api/v1/dashboard/public/{token}")).await?;
+    formatter::print_block(&format!("Shared dashboard {token} revoked.\n"))?;
+    Ok(())
+}
+
+/// Shared dashboard create body for `dashboard` (a `get` response), with
+/// expiry counted from `now`.
+fn share_body(
+    dashboard_id: &str,
+    dashboard: &serde_json::Value,
+    opts: &ShareOptions,
+    now: chrono::DateTime<chrono::Utc>,
+) -> Result<serde_json::Value> {
+    let dashboard_type = match dashboard["layout_type"].as_str() {
+        Some("ordered") => "custom_timeboard",
+        Some("free") => "custom_screenboard",
+        other => anyhow::bail!("dashboard {dashboard_id} has unsupported layout_type {other:?}"),
+    };
+    match opts.share_type.as_str() {
+        "open" | "embed" if !opts.invitees.is_empty() => {
+            anyhow::bail!("--invitee only applies to --share-type=invite")
+        }
+        "invite" if opts.invitees.is_empty() => {
+            anyhow::bail!("--share-type=invite needs at least one --invitee")
+        }
+        "embed" if opts.embeddable_domains.is_empty() => {
+            anyhow::bail!("--share-type=embed needs at least one --embed-domain")
+        }
+        "open" | "invite" if !opts.embeddable_domains.is_empty() => {
+            anyhow::bail!("--embed-domain only applies to --share-type=embed")
+        }
+        "open" | "invite" | "embed" => {}
+        other => anyhow::bail!("invalid share type {other:?}: expected open, invite, or embed"),
+    }
+
+    let mut body = serde_json::json!({
+        "dashboard_id": dashboard_id,
+        "dashboard_type": dashboard_type,
+        "share_type": opts.share_type,
+        "global_time_selectable_enabled": opts.selectable_time,
+    });
+    if let Some(live_span) = &opts.live_span {
+        body["global_time"] = serde_json::json!({ "live_span": live_span });
+    }
+    if let Some(expires_in) = &opts.expires_in {
+        let ms = util::parse_duration_millis(expires_in)?;
+        let expiration = now + chrono::Duration::milliseconds(ms);
+        body["expiration"] = expiration
+            .to_rfc3339_opts(chrono::SecondsFormat::Secs, true)
+            .into();
+    }
+    if !opts.invitees.is_empty() {
+        let invitees: Vec<_> = opts
+            .invitees
+            .iter()
+            .map(|email| serde_json::json!({ "email": email }))
+            .collect();
+        body["invitees"] = invitees.into();
+    }
+    if !opts.embeddable_domains.is_empty() {
+        body["embeddable_domains"] = serde_json::json!(opts.embeddable_domains);
+    }
+    Ok(body)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn shares_get_json(cfg: &Config, path: &str) -> Result<serde_json::Value> {
+    client::raw_get(cfg, path).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn shares_get_json(cfg: &Config, path: &str) -> Result<serde_json::Value> {
+    crate::api::get(cfg, path, &[]).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn post_dashboard_path(
+    cfg: &Config,
+    path: &str,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    client::raw_post(cfg, path, body.clone()).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn post_dashboard_path(
+    cfg: &Config,
+    path: &str,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    crate::api::post(cfg, path, body).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn delete_dashboard_path(cfg: &Config, path: &str) -> Result<serde_json::Value> {
+    client::raw_delete(cfg, path).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn delete_dashboard_path(cfg: &Config, path: &str) -> Result<serde_json::Value> {
+    crate::api::delete(cfg, path).await
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_portable_definition_keeps_layout_and_template_variables() {
+        let dashboard = serde_json::json!({
+            "id": "abc-123",
+            "title": "Checkout",
+            "layout_type": "ordered",
+            "author_handle": "a@example.com",
+            "created_at": "2024-01-01T00:00:00Z",
+            "url": "/dashboard/abc-123/checkout",
+            "description": null,
+            "template_variables": [{"name": "env", "prefix": "env", "default": "prod"}],
+            "widgets": [{
+                "id": 42,
+                "definition": {"type": "note", "content": "hi"},
+                "layout": {"x": 0, "y": 0, "width": 4, "height": 2}
+            }]
+        });
+        let def = portable_definition(dashboard);
+        assert_eq!(
+            def,
+            serde_json::json!({
+                "title": "Checkout",
+                "layout_type": "ordered",
+                "template_variables": [{"name": "env", "prefix": "env", "default": "prod"}],
+                "widgets": [{
+                    "id": 42,
+                    "definition": {"type": "note", "content": "hi"},
+                    "layout": {"x": 0, "y": 0, "width": 4, "height": 2}
+                }]
+            })
+        );
+    }
+
+    #[test]
+    fn test_is_yaml() {
+        assert!(is_yaml("dash.yaml"));
+        assert!(is_yaml("dash.yml"));
+        assert!(!is_yaml("dash.json"));
+    }
+
+    fn share(share_type: &str) -> ShareOptions {
+        ShareOptions {
+            share_type: share_type.into(),
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_share_body() {
+        let now = chrono::DateTime::parse_from_rfc3339("2024-01-01T00:00:00Z")
+            .unwrap()
+            .with_timezone(&chrono::Utc);
+        let dashboard = serde_json::json!({"layout_type": "free"});
+        let opts = ShareOptions {
+            invitees: vec!["a@example.com".into()],
+            expires_in: Some("7d".into()),
+            live_span: Some("1h".into()),
+            selectable_time: true,
+            ..share("invite")
+        };
+        let body = share_body("abc-123", &dashboard, &opts, now).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({
+                "dashboard_id": "abc-123",
+                "dashboard_type": "custom_screenboard",
+                "share_type": "invite",
+                "global_time_selectable_enabled": true,
+                "global_time": {"live_span": "1h"},
+                "expiration": "2024-01-08T00:00:00Z",
+                "invitees": [{"email": "a@example.com"}]
+            })
+        );
+    }
+
+    #[test]
+    fn test_share_body_rejects_mismatched_flags() {
+        let now = chrono::Utc::now();
+        let timeboard = serde_json::json!({"layout_type": "ordered"});
+        assert!(share_body("a", &timeboard, &share("open"), now).is_ok());
+        assert!(share_body("a", &timeboard, &share("invite"), now).is_err());
+        assert!(share_body("a", &timeboard, &share("embed"), now).is_err());
+        assert!(share_body("a", &timeboard, &share("public"), now).is_err());
+        let open_with_invitee = ShareOptions {
+            invitees: vec!["a@example.com".into()],
+            ..share("open")
+        };
+        assert!(share_body("a", &timeboard, &open_with_invitee, now).is_err());
+        assert!(share_body("a", &serde_json::json!({}), &share("open"), now).is_err());
+    }
+}
@@ -49,10 +49,123 @@ pub async fn get(cfg: &Config, id: &str) -> Result<()> {
     crate::formatter::output(cfg, &data)
 }
 
+/// Flags describing a downtime for `downtime create` without `--file`.
+#[derive(Clone, Debug, Default)]
+pub struct DowntimeSchedule {
+    pub scope: String,
+    /// Silence one monitor; all monitors matching `monitor_tags` otherwise.
+    pub monitor_id: Option<i64>,
+    pub monitor_tags: Vec<String>,
+    /// Start time (default now).
+    pub start: Option<String>,
+    /// Length of the downtime, or of each occurrence when `rrule` is set.
+    pub duration: Option<String>,
+    /// iCalendar RRULE, e.g. "FREQ=WEEKLY;BYDAY=SA", making the downtime recurring.
+    pub rrule: Option<String>,
+    pub message: Option<String>,
+}
+
+pub async fn create_from_file(cfg: &Config, file: &str) -> Result<()> {
+    create(cfg, crate::util::read_json_file(file)?).await
+}
+
+/// Creates a one-off or recurring downtime from flags.
+pub async fn create_scheduled(cfg: &Config, schedule: &DowntimeSchedule) -> Result<()> {
+    create(cfg, schedule_body(schedule, chrono::Utc::now())?).await
+}
+
+/// v2 downtime create body for `schedule`, with `now` as the default start.
+fn schedule_body(
+    schedule: &DowntimeSchedule,
+    now: chrono::DateTime<chrono::Utc>,
+) -> Result<serde_json::Value> {
+    if schedule.scope.trim().is_empty() {
+        anyhow::bail!("--scope is required");
+    }
+    let start = match &schedule.start {
+        Some(start) => {
+            let secs = crate::util::parse_time_to_unix(start)?;
+            chrono::DateTime::from_timestamp(secs, 0)
+                .ok_or_else(|| anyhow::anyhow!("start time out of range: {start:?}"))?
+        }
+        None => now,
+    };
+    let duration_ms = schedule
+        .duration
+        .as_deref()
+        .map(crate::util::parse_duration_millis)
+        .transpose()?;
+
+    let schedule_json = match &schedule.rrule {
+        Some(rrule) => {
+            let rrule = rrule.trim().trim_start_matches("RRULE:");
+            if !rrule.contains("FREQ=") {
+                anyhow::bail!("invalid --rrule {rrule:?}: expected e.g. FREQ=WEEKLY;BYDAY=SA");
+            }
+            let Some(ms) = duration_ms else {
+                anyhow::bail!("--duration is required with --rrule");
+            };
+            serde_json::json!({
+                "recurrences": [{
+                    "rrule": rrule,
+                    "duration": recurrence_duration(ms)?,
+                    "start": start.format("%Y-%m-%dT%H:%M").to_string(),
+                }],
+                "timezone": "UTC",
+            })
+        }
+        None => {
+            let mut one_off = serde_json::json!({});
+            if schedule.start.is_some() {
+                one_off["start"] = rfc3339(start).into();
+            }
+            if let Some(ms) = duration_ms {
+                one_off["end"] = rfc3339(start + chrono::Duration::milliseconds(ms)).into();
+            }
+            one_off
+        }
+    };
+
+    let monitor_identifier = match schedule.monitor_id {
+        Some(id) => serde_json::json!({"monitor_id": id}),
+        None if schedule.monitor_tags.is_empty() => serde_json::json!({"monitor_tags": ["*"]}),
+        None => serde_json::json!({"monitor_tags": schedule.monitor_tags}),
+    };
+    let mut attributes = serde_json::json!({
+        "scope": schedule.scope,
+        "monitor_identifier": monitor_identifier,
+        "schedule": schedule_json,
+    });
+    if let Some(message) = &schedule.message {
+        attributes["message"] = message.clone().into();
+    }
+    Ok(serde_json::json!({"data": {"type": "downtime", "attributes": attributes}}))
+}
+
+fn rfc3339(time: chrono::DateTime<chrono::Utc>) -> String {
+    time.to_rfc3339_opts(chrono::SecondsFormat::Secs, true)
+}
+
+/// Recurrence length in the largest whole unit the API accepts (w, d, h, m).
+fn recurrence_duration(ms: i64) -> Result<String> {
+    const MINUTE: i64 = 60_000;
+    if ms < MINUTE || ms % MINUTE != 0 {
+        anyhow::bail!("recurring downtime duration must be a whole number of minutes");
+    }
+    let minutes = ms / MINUTE;
+    let (value, unit) = [(7 * 24 * 60, "w"), (24 * 60, "d"), (60, "h"), (1, "m")]
+        .into_iter()
+        .find(|(size, _)| minutes % size == 0)
+        .map(|(size, unit)| (minutes / size, unit))
+        .unwrap_or((minutes, "m"));
+    Ok(format!("{value}{unit}"))
+}
+
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn create(cfg: &Config, file: &str) -> Result<()> {
+async fn create(cfg: &Config, body: serde_json::Value) -> Result<()> {
     let body: datadog_api_client::datadogV2::model::DowntimeCreateRequest =
-        crate::util::read_json_file(file)?;
+        serde_json::from_value(body)
+            .map_err(|e| anyhow::anyhow!("invalid downtime definition: {e}"))?;
     let dd_cfg = client::make_dd_config(cfg);
     let api = match client::make_bearer_client(cfg) {
         Some(c) => DowntimesAPI::with_client_and_config(dd_cfg, c),
@@ -66,8 +179,7 @@ pub async fn create(cfg: &Config, file: &str) -> Result<()> {
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn create(cfg: &Config, file: &str) -> Result<()> {
-    let body: serde_json::Value = crate::util::read_json_file(file)?;
+async fn create(cfg: &Config, body: serde_json::Value) -> Result<()> {
     let data = crate::api::post(cfg, "/api/v2/downtime", &body).await?;
     crate::formatter::output(cfg, &data)
 }
@@ -82,13 +194,108 @@ pub async fn cancel(cfg: &Config, id: &str) -> Result<()> {
     api.cancel_downtime(id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to cancel downtime: {e:?}"))?;
-    println!("Downtime {id} cancelled.");
+    formatter::print_block(&format!("Downtime {id} cancelled.\n"))?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
 pub async fn cancel(cfg: &Config, id: &str) -> Result<()> {
     crate::api::delete(cfg, &format!("/api/v2/downtime/{id}")).await?;
-    println!("Downtime {id} cancelled.");
+    formatter::print_block(&format!("Downtime {id} cancelled.\n"))?;
     Ok(())
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn at(rfc3339: &str) -> chrono::DateTime<chrono::Utc> {
+        chrono::DateTime::parse_from_rfc3339(rfc3339)
+            .unwrap()
+            .with_timezone(&chrono::Utc)
+    }
+
+    #[test]
+    fn test_schedule_body_one_off() {
+        let schedule = DowntimeSchedule {
+            scope: "env:prod".into(),
+            monitor_id: Some(7),
+            start: Some("2024-01-06T22:00:00Z".into()),
+            duration: Some("2h".into()),
+            message: Some("deploy".into()),
+            ..Default::default()
+        };
+        let body = schedule_body(&schedule, at("2024-01-01T00:00:00Z")).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({"data": {"type": "downtime", "attributes": {
+                "scope": "env:prod",
+                "monitor_identifier": {"monitor_id": 7},
+                "schedule": {"start": "2024-01-06T22:00:00Z", "end": "2024-01-07T00:00:00Z"},
+                "message": "deploy"
+            }}})
+        );
+
+        // No start means now; no duration means until cancelled.
+        let schedule = DowntimeSchedule {
+            scope: "*".into(),
+            monitor_tags: vec!["team:web".into()],
+            ..Default::default()
+        };
+        let body = schedule_body(&schedule, at("2024-01-01T00:00:00Z")).unwrap();
+        let attributes = &body["data"]["attributes"];
+        assert_eq!(attributes["schedule"], serde_json::json!({}));
+        assert_eq!(
+            attributes["monitor_identifier"],
+            serde_json::json!({"monitor_tags": ["team:web"]})
+        );
+    }
+
+    #[test]
+    fn test_schedule_body_recurring() {
+        let schedule = DowntimeSchedule {
+            scope: "env:staging".into(),
+            start: Some("2024-01-06T22:00:00Z".into()),
+            duration: Some("120m".into()),
+            rrule: Some("RRULE:FREQ=WEEKLY;BYDAY=SA".into()),
+            ..Default::default()
+        };
+        let body = schedule_body(&schedule, at("2024-01-01T00:00:00Z")).unwrap();
+        assert_eq!(
+            body["data"]["attributes"]["schedule"],
+            serde_json::json!({
+                "recurrences": [{
+                    "rrule": "FREQ=WEEKLY;BYDAY=SA",
+                    "duration": "2h",
+                    "start": "2024-01-06T22:00"
+                }],
+                "timezone": "UTC"
+            })
+        );
+        assert_eq!(
+            body["data"]["attributes"]["monitor_identifier"],
+            serde_json::json!({"monitor_tags": ["*"]})
+        );
+
+        let no_duration = DowntimeSchedule {
+            duration: None,
+            ..schedule.clone()
+        };
+        assert!(schedule_body(&no_duration, chrono::Utc::now()).is_err());
+        let bad_rrule = DowntimeSchedule {
+            rrule: Some("WEEKLY".into()),
+            ..schedule
+        };
+        assert!(schedule_body(&bad_rrule, chrono::Utc::now()).is_err());
+    }
+
+    #[test]
+    fn test_recurrence_duration() {
+        assert_eq!(recurrence_duration(90 * 60_000).unwrap(), "90m");
+        assert_eq!(recurrence_duration(3 * 3_600_000).unwrap(), "3h");
+        assert_eq!(recurrence_duration(2 * 86_400_000).unwrap(), "2d");
+        assert_eq!(recurrence_duration(14 * 86_400_000).unwrap(), "2w");
+        assert!(recurrence_duration(30_000).is_err());
+        assert!(recurrence_duration(90_000).is_err());
+    }
+}
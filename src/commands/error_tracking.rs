@@ -40,7 +40,9 @@ pub async fn issues_search(cfg: &Config, query: Option<String>, _limit: i32) ->
     let val = serde_json::to_value(&resp)?;
     if let Some(data) = val.get("data") {
         if data.as_array().is_some_and(|a| a.is_empty()) {
-            println!("No error tracking issues found matching the specified criteria.");
+            formatter::print_block(
+                "No error tracking issues found matching the specified criteria.\n",
+            )?;
             return Ok(());
         }
     }
@@ -68,7 +70,9 @@ pub async fn issues_search(cfg: &Config, query: Option<String>, _limit: i32) ->
     let data = crate::api::post(cfg, "/api/v2/error-tracking/issues/search", &body).await?;
     if let Some(arr) = data.get("data").and_then(|d| d.as_array()) {
         if arr.is_empty() {
-            println!("No error tracking issues found matching the specified criteria.");
+            formatter::print_block(
+                "No error tracking issues found matching the specified criteria.\n",
+            )?;
             return Ok(());
         }
     }
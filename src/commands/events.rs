@@ -57,6 +57,11 @@ pub async fn list(cfg: &Config, start: i64, end: i64, tags: Option<String>) -> R
     crate::formatter::output(cfg, &data)
 }
 
+/// Largest page the v2 events search endpoint returns.
+const SEARCH_PAGE_SIZE: i32 = 1000;
+
+/// Searches events newest first, following the `meta.page.after` cursor
+/// until `limit` events are collected or none are left.
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn search(
     cfg: &Config,
@@ -72,67 +77,120 @@ pub async fn search(
              This endpoint does not support bearer token auth."
         );
     }
+    search_pages(cfg, query, from, to, limit).await
+}
 
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = EventsV2API::with_config(dd_cfg);
+#[cfg(target_arch = "wasm32")]
+pub async fn search(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    limit: i32,
+) -> Result<()> {
+    search_pages(cfg, query, from, to, limit).await
+}
 
+async fn search_pages(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    limit: i32,
+) -> Result<()> {
+    if limit < 1 {
+        bail!("--limit must be at least 1");
+    }
     let from_ms = util::parse_time_to_unix_millis(&from)?;
     let to_ms = util::parse_time_to_unix_millis(&to)?;
 
-    let from_str = chrono::DateTime::from_timestamp_millis(from_ms)
-        .unwrap()
-        .to_rfc3339();
-    let to_str = chrono::DateTime::from_timestamp_millis(to_ms)
-        .unwrap()
-        .to_rfc3339();
-
-    let body = EventsListRequest::new()
-        .filter(
-            EventsQueryFilter::new()
-                .query(query)
-                .from(from_str)
-                .to(to_str),
-        )
-        .page(EventsRequestPage::new().limit(limit))
-        .sort(EventsSort::TIMESTAMP_DESCENDING);
+    let mut events: Vec<serde_json::Value> = Vec::new();
+    let mut cursor: Option<String> = None;
+    while (events.len() as i32) < limit {
+        let page_limit = (limit - events.len() as i32).min(SEARCH_PAGE_SIZE);
+        let body = search_request(query.clone(), from_ms, to_ms, page_limit, cursor);
+        let page = search_page(cfg, body).await?;
+        let items = page["data"].as_array().cloned().unwrap_or_default();
+        if items.is_empty() {
+            break;
+        }
+        events.extend(items);
+        cursor = page["meta"]["page"]["after"].as_str().map(String::from);
+        if cursor.is_none() {
+            break;
+        }
+    }
+    formatter::output(cfg, &serde_json::json!({ "data": events }))
+}
 
-    let params = SearchEventsOptionalParams::default().body(body);
+#[cfg(not(target_arch = "wasm32"))]
+async fn search_page(cfg: &Config, body: EventsListRequest) -> Result<serde_json::Value> {
+    let api = EventsV2API::with_config(client::make_dd_config(cfg));
     let resp = api
-        .search_events(params)
+        .search_events(SearchEventsOptionalParams::default().body(body))
         .await
         .map_err(|e| anyhow::anyhow!("failed to search events: {e:?}"))?;
-    formatter::output(cfg, &resp)
+    Ok(serde_json::to_value(&resp)?)
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn search(
-    cfg: &Config,
+async fn search_page(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v2/events/search", &body).await
+}
+
+/// `ms` in RFC3339, or the raw millisecond count if chrono can't represent it.
+fn rfc3339_millis(ms: i64) -> String {
+    chrono::DateTime::from_timestamp_millis(ms)
+        .map(|dt| dt.to_rfc3339())
+        .unwrap_or_else(|| ms.to_string())
+}
+
+/// Events search request for `query` between two Unix-millisecond times,
+/// newest first, continuing from `cursor` when given.
+#[cfg(not(target_arch = "wasm32"))]
+fn search_request(
     query: String,
-    from: String,
-    to: String,
+    from_ms: i64,
+    to_ms: i64,
     limit: i32,
-) -> Result<()> {
-    let from_ms = util::parse_time_to_unix_millis(&from)?;
-    let to_ms = util::parse_time_to_unix_millis(&to)?;
-
-    let from_str = chrono::DateTime::from_timestamp_millis(from_ms)
-        .unwrap()
-        .to_rfc3339();
-    let to_str = chrono::DateTime::from_timestamp_millis(to_ms)
-        .unwrap()
-        .to_rfc3339();
+    cursor: Option<String>,
+) -> EventsListRequest {
+    let mut page = EventsRequestPage::new().limit(limit);
+    if let Some(cursor) = cursor {
+        page = page.cursor(cursor);
+    }
+    EventsListRequest::new()
+        .filter(
+            EventsQueryFilter::new()
+                .query(query)
+                .from(rfc3339_millis(from_ms))
+                .to(rfc3339_millis(to_ms)),
+        )
+        .page(page)
+        .sort(EventsSort::TIMESTAMP_DESCENDING)
+}
 
-    let body = serde_json::json!({
+#[cfg(target_arch = "wasm32")]
+fn search_request(
+    query: String,
+    from_ms: i64,
+    to_ms: i64,
+    limit: i32,
+    cursor: Option<String>,
+) -> serde_json::Value {
+    let mut body = serde_json::json!({
         "filter": {
             "query": query,
-            "from": from_str,
-            "to": to_str
+            "from": rfc3339_millis(from_ms),
+            "to": rfc3339_millis(to_ms)
         },
         "page": { "limit": limit },
         "sort": "-timestamp"
     });
-    let data = crate::api::post(cfg, "/api/v2/events/search", &body).await?;
-    crate::formatter::output(cfg, &data)
+    if let Some(cursor) = cursor {
+        body["page"]["cursor"] = cursor.into();
+    }
+    body
 }
 
 #[cfg(not(target_arch = "wasm32"))]
@@ -155,3 +213,424 @@ pub async fn get(cfg: &Config, id: i64) -> Result<()> {
     let data = crate::api::get(cfg, &path, &[]).await?;
     crate::formatter::output(cfg, &data)
 }
+
+// ---- Post ----
+
+/// Flags for `events post`.
+#[derive(Clone, Debug, Default)]
+pub struct EventPost {
+    pub title: String,
+    pub text: Option<String>,
+    pub tags: Vec<String>,
+    /// "change" or "alert".
+    pub category: String,
+    /// Alert events only: error, warning, info, or success.
+    pub alert_type: Option<String>,
+    /// Recorded as a `source:<name>` tag, which the events explorer facets on.
+    pub source_type: Option<String>,
+    pub aggregation_key: Option<String>,
+    pub host: Option<String>,
+    /// Change events only: what changed, as `name` or `type:name`
+    /// (e.g. `feature_flag:checkout-v2`; type defaults to configuration).
+    pub changed_resource: Option<String>,
+    pub author: Option<String>,
+    pub prev_value: Option<String>,
+    pub new_value: Option<String>,
+    /// Change events only: services affected by the change.
+    pub impacted_services: Vec<String>,
+}
+
+/// Sends an event through the v2 events intake.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn post(cfg: &Config, event: &EventPost) -> Result<()> {
+    use datadog_api_client::datadogV2::model::EventCreateRequestPayload;
+
+    if !cfg.has_api_keys() {
+        bail!(
+            "events post requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+    let body: EventCreateRequestPayload = serde_json::from_value(post_body(event)?)
+        .map_err(|e| anyhow::anyhow!("invalid event: {e}"))?;
+    let api = EventsV2API::with_config(client::make_dd_config(cfg));
+    let resp = api
+        .create_event(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to post event: {e:?}"))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn post(cfg: &Config, event: &EventPost) -> Result<()> {
+    let data = crate::api::post(cfg, "/api/v2/events", &post_body(event)?).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// v2 events intake body for `event`. Category-specific details go in the
+/// nested `attributes` object the intake validates per category.
+fn post_body(event: &EventPost) -> Result<serde_json::Value> {
+    if event.title.trim().is_empty() {
+        bail!("--title is required");
+    }
+    let details = match event.category.as_str() {
+        "change" => change_attributes(event)?,
+        "alert" => alert_attributes(event)?,
+        other => bail!("invalid category {other:?}: expected change or alert"),
+    };
+
+    let mut tags = event.tags.clone();
+    if let Some(source) = &event.source_type {
+        tags.push(format!("source:{source}"));
+    }
+    let mut attributes = serde_json::json!({
+        "title": event.title,
+        "category": event.category,
+        "attributes": details,
+    });
+    if let Some(text) = &event.text {
+        attributes["message"] = text.clone().into();
+    }
+    if !tags.is_empty() {
+        attributes["tags"] = tags.into();
+    }
+    if let Some(key) = &event.aggregation_key {
+        attributes["aggregation_key"] = key.clone().into();
+    }
+    if let Some(host) = &event.host {
+        attributes["host"] = host.clone().into();
+    }
+    Ok(serde_json::json!({"data": {"type": "event", "attributes": attributes}}))
+}
+
+fn change_attributes(event: &EventPost) -> Result<serde_json::Value> {
+    if event.alert_type.is_some() {
+        bail!("--alert-type only applies to --category alert");
+    }
+    let Some(resource) = &event.changed_resource else {
+        bail!("--changed-resource is required for change events");
+    };
+    let (kind, name) = resource
+        .split_once(':')
+        .unwrap_or(("configuration", resource.as_str()));
+    let mut details = serde_json::json!({
+        "changed_resource": {"name": name, "type": kind},
+    });
+    if let Some(author) = &event.author {
+        details["author"] = serde_json::json!({"name": author, "type": "user"});
+    }
+    if let Some(prev) = &event.prev_value {
+        details["prev_value"] = change_value(prev);
+    }
+    if let Some(new) = &event.new_value {
+        details["new_value"] = change_value(new);
+    }
+    if !event.impacted_services.is_empty() {
+        let impacted: Vec<_> = event
+            .impacted_services
+            .iter()
+            .map(|name| serde_json::json!({"name": name, "type": "service"}))
+            .collect();
+        details["impacted_resources"] = impacted.into();
+    }
+    Ok(details)
+}
+
+fn alert_attributes(event: &EventPost) -> Result<serde_json::Value> {
+    let change_only = event.changed_resource.is_some()
+        || event.author.is_some()
+        || event.prev_value.is_some()
+        || event.new_value.is_some()
+        || !event.impacted_services.is_empty();
+    if change_only {
+        bail!("change flags (--changed-resource, --author, --prev-value, --new-value, --impacted-service) only apply to --category change");
+    }
+    let status = match event.alert_type.as_deref().unwrap_or("info") {
+        "error" => "error",
+        "warning" => "warn",
+        "info" | "success" => "ok",
+        other => bail!("invalid alert type {other:?}: expected error, warning, info, or success"),
+    };
+    Ok(serde_json::json!({ "status": status }))
+}
+
+/// Change values are objects in the intake; a JSON object argument is sent
+/// as-is and anything else is wrapped as `{"value": ...}`.
+fn change_value(raw: &str) -> serde_json::Value {
+    match serde_json::from_str::<serde_json::Value>(raw) {
+        Ok(value @ serde_json::Value::Object(_)) => value,
+        _ => serde_json::json!({ "value": raw }),
+    }
+}
+
+/// Events fetched per page of an `events stream` poll.
+#[cfg(not(target_arch = "wasm32"))]
+const STREAM_PAGE_LIMIT: i32 = 100;
+
+/// Position of a polled feed (`events stream`, `logs tail --follow`): the
+/// newest timestamp printed so far and the ids already printed at that
+/// timestamp. Each poll searches from that timestamp inclusively, so the ids
+/// are needed to avoid repeats. Entries without a readable timestamp can't be
+/// placed on the timeline and are printed once, by id.
+pub struct StreamCursor {
+    last_ms: i64,
+    seen_at_last: std::collections::HashSet<String>,
+    seen_untimed: std::collections::HashSet<String>,
+}
+
+impl StreamCursor {
+    pub fn starting_at(ms: i64) -> Self {
+        Self {
+            last_ms: ms,
+            seen_at_last: Default::default(),
+            seen_untimed: Default::default(),
+        }
+    }
+
+    /// Timestamp the next poll should search from.
+    pub fn last_millis(&self) -> i64 {
+        self.last_ms
+    }
+
+    /// Returns the events not printed yet, oldest first, and advances past them.
+    /// Events without a timestamp follow the timed ones.
+    pub fn take_new(&mut self, events: &[serde_json::Value]) -> Vec<serde_json::Value> {
+        let mut fresh: Vec<(i64, &serde_json::Value)> = events
+            .iter()
+            .filter_map(|e| Some((event_millis(e)?, e)))
+            .filter(|(ms, e)| {
+                *ms > self.last_ms
+                    || (*ms == self.last_ms && !self.seen_at_last.contains(event_id(e)))
+            })
+            .collect();
+        fresh.sort_by_key(|(ms, _)| *ms);
+
+        for (ms, e) in &fresh {
+            if *ms > self.last_ms {
+                self.last_ms = *ms;
+                self.seen_at_last.clear();
+            }
+            if *ms == self.last_ms {
+                self.seen_at_last.insert(event_id(e).to_string());
+            }
+        }
+        let untimed = events
+            .iter()
+            .filter(|e| event_millis(e).is_none())
+            .filter(|e| self.seen_untimed.insert(event_id(e).to_string()));
+        fresh
+            .into_iter()
+            .map(|(_, e)| e)
+            .chain(untimed)
+            .cloned()
+            .collect()
+    }
+}
+
+fn event_id(event: &serde_json::Value) -> &str {
+    event.get("id").and_then(|v| v.as_str()).unwrap_or_default()
+}
+
+fn event_millis(event: &serde_json::Value) -> Option<i64> {
+    let ts = event.pointer("/attributes/timestamp")?.as_str()?;
+    chrono::DateTime::parse_from_rfc3339(ts)
+        .ok()
+        .map(|dt| dt.timestamp_millis())
+}
+
+/// Runs one `events stream` poll: pages through the events from the cursor
+/// up to now and returns the ones that are new since the previous poll.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn stream_poll(
+    cfg: &Config,
+    query: &str,
+    cursor: &mut StreamCursor,
+) -> Result<Vec<serde_json::Value>> {
+    let api = EventsV2API::with_config(client::make_dd_config(cfg));
+    let to_ms = chrono::Utc::now().timestamp_millis();
+    let mut events = Vec::new();
+    let mut page_cursor = None;
+    loop {
+        let body = search_request(
+            query.to_string(),
+            cursor.last_ms,
+            to_ms,
+            STREAM_PAGE_LIMIT,
+            page_cursor,
+        );
+        let resp = api
+            .search_events(SearchEventsOptionalParams::default().body(body))
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to search events: {e:?}"))?;
+        let data = serde_json::to_value(&resp)?;
+        let page = data
+            .get("data")
+            .and_then(|d| d.as_array())
+            .cloned()
+            .unwrap_or_default();
+        if page.is_empty() {
+            break;
+        }
+        events.extend(page);
+        page_cursor = data
+            .pointer("/meta/page/after")
+            .and_then(|v| v.as_str())
+            .map(str::to_string);
+        if page_cursor.is_none() {
+            break;
+        }
+    }
+    Ok(cursor.take_new(&events))
+}
+
+/// Polls events search every `interval` and prints events as they arrive,
+/// until interrupted with Ctrl-C.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn stream(cfg: &Config, query: String, interval: &str) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "events stream requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+    let interval_ms = util::parse_duration_millis(interval)?;
+    if interval_ms < 1000 {
+        bail!("--interval must be at least 1s");
+    }
+    let interval = std::time::Duration::from_millis(interval_ms as u64);
+
+    let mut cursor = StreamCursor::starting_at(chrono::Utc::now().timestamp_millis());
+    formatter::print_status(&format!(
+        "Streaming events matching {query:?} every {}s (Ctrl-C to stop)...",
+        interval.as_secs()
+    ));
+    loop {
+        let events = stream_poll(cfg, &query, &mut cursor).await?;
+        if !events.is_empty() {
+            formatter::output(cfg, &events)?;
+        }
+        tokio::select! {
+            _ = tokio::signal::ctrl_c() => return Ok(()),
+            _ = tokio::time::sleep(interval) => {}
+        }
+    }
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn stream(_cfg: &Config, _query: String, _interval: &str) -> Result<()> {
+    bail!("events stream is not available in WASM builds.")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_rfc3339_millis_falls_back_to_raw_millis() {
+        assert_eq!(rfc3339_millis(0), "1970-01-01T00:00:00+00:00");
+        assert_eq!(rfc3339_millis(i64::MAX), i64::MAX.to_string());
+    }
+
+    fn event(id: &str, ts: &str) -> serde_json::Value {
+        serde_json::json!({"id": id, "type": "event", "attributes": {"timestamp": ts}})
+    }
+
+    #[test]
+    fn test_post_body_change_event() {
+        let event = EventPost {
+            title: "Deployed checkout v42".into(),
+            text: Some("Rolled out by CI".into()),
+            tags: vec!["env:prod".into()],
+            category: "change".into(),
+            source_type: Some("github".into()),
+            changed_resource: Some("feature_flag:checkout-v2".into()),
+            author: Some("ci-bot".into()),
+            new_value: Some(r#"{"enabled": true}"#.into()),
+            prev_value: Some("off".into()),
+            impacted_services: vec!["checkout".into()],
+            ..Default::default()
+        };
+        let body = post_body(&event).unwrap();
+        let attrs = &body["data"]["attributes"];
+        assert_eq!(body["data"]["type"], "event");
+        assert_eq!(attrs["category"], "change");
+        assert_eq!(attrs["message"], "Rolled out by CI");
+        assert_eq!(
+            attrs["tags"],
+            serde_json::json!(["env:prod", "source:github"])
+        );
+        assert_eq!(
+            attrs["attributes"],
+            serde_json::json!({
+                "changed_resource": {"name": "checkout-v2", "type": "feature_flag"},
+                "author": {"name": "ci-bot", "type": "user"},
+                "prev_value": {"value": "off"},
+                "new_value": {"enabled": true},
+                "impacted_resources": [{"name": "checkout", "type": "service"}]
+            })
+        );
+    }
+
+    #[test]
+    fn test_post_body_alert_event() {
+        let event = EventPost {
+            title: "Disk almost full".into(),
+            category: "alert".into(),
+            alert_type: Some("warning".into()),
+            ..Default::default()
+        };
+        let body = post_body(&event).unwrap();
+        assert_eq!(
+            body["data"]["attributes"]["attributes"],
+            serde_json::json!({"status": "warn"})
+        );
+
+        let missing_resource = EventPost {
+            category: "change".into(),
+            ..event.clone()
+        };
+        assert!(post_body(&missing_resource).is_err());
+        let bad_category = EventPost {
+            category: "deploy".into(),
+            ..event
+        };
+        assert!(post_body(&bad_category).is_err());
+    }
+
+    #[test]
+    fn test_stream_cursor_skips_seen_events() {
+        let start = chrono::DateTime::parse_from_rfc3339("2024-01-01T00:00:00Z")
+            .unwrap()
+            .timestamp_millis();
+        let mut cursor = StreamCursor::starting_at(start);
+
+        let first = cursor.take_new(&[
+            event("b", "2024-01-01T00:00:02Z"),
+            event("a", "2024-01-01T00:00:01Z"),
+            event("old", "2023-12-31T23:59:59Z"),
+        ]);
+        let ids: Vec<&str> = first.iter().map(event_id).collect();
+        assert_eq!(ids, ["a", "b"]);
+
+        // The next search starts at b's timestamp, so b comes back again.
+        let second = cursor.take_new(&[
+            event("c", "2024-01-01T00:00:02Z"),
+            event("b", "2024-01-01T00:00:02Z"),
+        ]);
+        let ids: Vec<&str> = second.iter().map(event_id).collect();
+        assert_eq!(ids, ["c"]);
+        assert!(cursor
+            .take_new(&[event("c", "2024-01-01T00:00:02Z")])
+            .is_empty());
+    }
+
+    #[test]
+    fn test_stream_cursor_keeps_untimed_events_once() {
+        let mut cursor = StreamCursor::starting_at(0);
+        let untimed = serde_json::json!({"id": "u", "attributes": {"timestamp": "soon"}});
+        let first = cursor.take_new(&[untimed.clone(), event("a", "2024-01-01T00:00:01Z")]);
+        let ids: Vec<&str> = first.iter().map(event_id).collect();
+        assert_eq!(ids, ["a", "u"]);
+        assert!(cursor.take_new(&[untimed]).is_empty());
+    }
+}
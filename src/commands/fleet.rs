@@ -206,7 +206,7 @@ pub async fn schedules_delete(cfg: &Config, schedule_id: &str) -> Result<()> {
     api.delete_fleet_schedule(schedule_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete schedule: {e:?}"))?;
-    println!("Schedule '{schedule_id}' deleted successfully.");
+    formatter::print_block(&format!("Schedule '{schedule_id}' deleted successfully.\n"))?;
     Ok(())
 }
 
@@ -214,7 +214,7 @@ pub async fn schedules_delete(cfg: &Config, schedule_id: &str) -> Result<()> {
 pub async fn schedules_delete(cfg: &Config, schedule_id: &str) -> Result<()> {
     let path = format!("/api/v2/fleet/schedules/{schedule_id}");
     crate::api::delete(cfg, &path).await?;
-    println!("Schedule '{schedule_id}' deleted successfully.");
+    formatter::print_block(&format!("Schedule '{schedule_id}' deleted successfully.\n"))?;
     Ok(())
 }
 
@@ -228,7 +228,7 @@ pub async fn deployments_cancel(cfg: &Config, deployment_id: &str) -> Result<()>
     api.cancel_fleet_deployment(deployment_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to cancel deployment: {e:?}"))?;
-    println!("Fleet deployment {deployment_id} cancelled.");
+    formatter::print_block(&format!("Fleet deployment {deployment_id} cancelled.\n"))?;
     Ok(())
 }
 
@@ -236,7 +236,7 @@ pub async fn deployments_cancel(cfg: &Config, deployment_id: &str) -> Result<()>
 pub async fn deployments_cancel(cfg: &Config, deployment_id: &str) -> Result<()> {
     let path = format!("/api/v2/fleet/deployments/{deployment_id}");
     crate::api::delete(cfg, &path).await?;
-    println!("Fleet deployment {deployment_id} cancelled.");
+    formatter::print_block(&format!("Fleet deployment {deployment_id} cancelled.\n"))?;
     Ok(())
 }
 
@@ -316,7 +316,7 @@ pub async fn schedules_trigger(cfg: &Config, schedule_id: &str) -> Result<()> {
     api.trigger_fleet_schedule(schedule_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to trigger schedule: {e:?}"))?;
-    println!("Schedule {schedule_id} triggered.");
+    formatter::print_block(&format!("Schedule {schedule_id} triggered.\n"))?;
     Ok(())
 }
 
@@ -325,6 +325,6 @@ pub async fn schedules_trigger(cfg: &Config, schedule_id: &str) -> Result<()> {
     let path = format!("/api/v2/fleet/schedules/{schedule_id}/trigger");
     let body = serde_json::json!({});
     crate::api::post(cfg, &path, &body).await?;
-    println!("Schedule {schedule_id} triggered.");
+    formatter::print_block(&format!("Schedule {schedule_id} triggered.\n"))?;
     Ok(())
 }
@@ -68,6 +68,180 @@ pub async fn get(cfg: &Config, incident_id: &str) -> Result<()> {
     crate::formatter::output(cfg, &data)
 }
 
+/// Prints an incident as postmortem-shaped markdown, optionally with emails
+/// and IP addresses redacted.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn postmortem(cfg: &Config, incident_id: &str, redact: bool) -> Result<()> {
+    let api = make_api(cfg);
+    let resp = api
+        .get_incident(
+            incident_id.to_string(),
+            GetIncidentOptionalParams::default(),
+        )
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get incident: {:?}", e))?;
+    let todos = match client::raw_get(cfg, &todos_path(incident_id)).await {
+        Ok(v) => v["data"].as_array().cloned().unwrap_or_default(),
+        Err(e) => {
+            formatter::print_status(&format!("warning: could not load incident tasks: {e}"));
+            Vec::new()
+        }
+    };
+    print_postmortem(&serde_json::to_value(&resp)?, &todos, redact)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn postmortem(cfg: &Config, incident_id: &str, redact: bool) -> Result<()> {
+    let incident = crate::api::get(cfg, &format!("/api/v2/incidents/{incident_id}"), &[]).await?;
+    let todos = match crate::api::get(cfg, &todos_path(incident_id), &[]).await {
+        Ok(v) => v["data"].as_array().cloned().unwrap_or_default(),
+        Err(e) => {
+            formatter::print_status(&format!("warning: could not load incident tasks: {e}"));
+            Vec::new()
+        }
+    };
+    print_postmortem(&incident, &todos, redact)
+}
+
+fn print_postmortem(
+    incident: &serde_json::Value,
+    todos: &[serde_json::Value],
+    redact: bool,
+) -> Result<()> {
+    let md = postmortem_markdown(incident, todos);
+    let md = if redact { util::redact_pii(&md) } else { md };
+    formatter::print_block(&md)
+}
+
+/// Renders an incident (the `get` response) and its todos as markdown
+/// following the Datadog postmortem template: summary, impact, root cause,
+/// timeline, and action items.
+pub fn postmortem_markdown(incident: &serde_json::Value, todos: &[serde_json::Value]) -> String {
+    let attrs = &incident["data"]["attributes"];
+    let text = |v: &serde_json::Value| v.as_str().unwrap_or_default().trim().to_string();
+    let field = |name: &str| text(&attrs["fields"][name]["value"]);
+    let or_none = |s: String, what: &str| {
+        if s.is_empty() {
+            format!("_No {what} recorded._")
+        } else {
+            s
+        }
+    };
+
+    let mut md = format!("# Postmortem: {}\n\n", text(&attrs["title"]));
+    md.push_str("| | |\n|---|---|\n");
+    if let Some(n) = attrs["public_id"].as_i64() {
+        md.push_str(&format!("| Incident | #{n} |\n"));
+    }
+    let severity = match field("severity") {
+        s if s.is_empty() => text(&attrs["severity"]),
+        s => s,
+    };
+    md.push_str(&format!("| Severity | {} |\n", or_dash(&severity)));
+    let state = match field("state") {
+        s if s.is_empty() => text(&attrs["state"]),
+        s => s,
+    };
+    md.push_str(&format!("| State | {} |\n", or_dash(&state)));
+
+    md.push_str(&format!(
+        "\n## Summary\n\n{}\n",
+        or_none(field("summary"), "summary")
+    ));
+
+    md.push_str("\n## Impact\n\n");
+    let impacted = attrs["customer_impacted"].as_bool().unwrap_or(false);
+    md.push_str(&format!(
+        "- Customer impact: {}\n",
+        if impacted { "yes" } else { "no" }
+    ));
+    let scope = text(&attrs["customer_impact_scope"]);
+    if !scope.is_empty() {
+        md.push_str(&format!("- Scope: {scope}\n"));
+    }
+    if let Some(secs) = attrs["customer_impact_duration"]
+        .as_i64()
+        .filter(|s| *s > 0)
+    {
+        md.push_str(&format!("- Duration: {}\n", format_duration_secs(secs)));
+    }
+
+    md.push_str(&format!(
+        "\n## Root Cause\n\n{}\n",
+        or_none(field("root_cause"), "root cause")
+    ));
+
+    md.push_str("\n## Timeline\n\n| Time (UTC) | Event |\n|---|---|\n");
+    let mut events: Vec<(String, &str)> = [
+        ("created", "Incident declared"),
+        ("detected", "Detected"),
+        ("customer_impact_start", "Customer impact began"),
+        ("customer_impact_end", "Customer impact ended"),
+        ("resolved", "Resolved"),
+    ]
+    .into_iter()
+    .filter_map(|(key, label)| attrs[key].as_str().map(|ts| (ts.to_string(), label)))
+    .collect();
+    events.sort();
+    for (ts, label) in events {
+        md.push_str(&format!("| {} | {label} |\n", display_time(&ts)));
+    }
+
+    md.push_str("\n## Action Items\n\n");
+    if todos.is_empty() {
+        md.push_str("_No action items recorded._\n");
+    }
+    for todo in todos {
+        let t = &todo["attributes"];
+        let done = !t["completed"].is_null();
+        md.push_str(&format!(
+            "- [{}] {}",
+            if done { "x" } else { " " },
+            text(&t["content"])
+        ));
+        let assignees: Vec<String> = t["assignees"]
+            .as_array()
+            .into_iter()
+            .flatten()
+            .filter_map(|a| a.as_str().or_else(|| a["handle"].as_str()))
+            .map(|a| format!("@{a}"))
+            .collect();
+        if !assignees.is_empty() {
+            md.push_str(&format!(" ({})", assignees.join(", ")));
+        }
+        if let Some(due) = t["due_date"].as_str() {
+            md.push_str(&format!(" — due {}", display_time(due)));
+        }
+        md.push('\n');
+    }
+    md
+}
+
+fn or_dash(s: &str) -> &str {
+    if s.is_empty() {
+        "-"
+    } else {
+        s
+    }
+}
+
+/// "2024-03-01T10:15:42.000+00:00" → "2024-03-01 10:15".
+fn display_time(ts: &str) -> String {
+    ts.get(..16)
+        .map(|t| t.replace('T', " "))
+        .unwrap_or_else(|| ts.to_string())
+}
+
+fn format_duration_secs(secs: i64) -> String {
+    let (h, m) = (secs / 3600, secs % 3600 / 60);
+    match (h, m) {
+        (0, 0) => format!("{secs}s"),
+        (0, m) => format!("{m}m"),
+        (h, 0) => format!("{h}h"),
+        (h, m) => format!("{h}h {m}m"),
+    }
+}
+
 // ---------------------------------------------------------------------------
 // Attachments
 // ---------------------------------------------------------------------------
@@ -123,7 +297,9 @@ pub async fn attachments_delete(
         let body = resp.text().await.unwrap_or_default();
         bail!("failed to delete incident attachment (HTTP {status}): {body}");
     }
-    println!("Incident attachment {attachment_id} deleted from incident {incident_id}.");
+    formatter::print_block(&format!(
+        "Incident attachment {attachment_id} deleted from incident {incident_id}.\n"
+    ))?;
     Ok(())
 }
 
@@ -135,10 +311,160 @@ pub async fn attachments_delete(
 ) -> Result<()> {
     let path = format!("/api/v2/incidents/{incident_id}/attachments/{attachment_id}");
     crate::api::delete(cfg, &path).await?;
-    println!("Incident attachment {attachment_id} deleted from incident {incident_id}.");
+    formatter::print_block(&format!(
+        "Incident attachment {attachment_id} deleted from incident {incident_id}.\n"
+    ))?;
     Ok(())
 }
 
+// ---------------------------------------------------------------------------
+// Todos (incident tasks)
+// ---------------------------------------------------------------------------
+
+fn todos_path(incident_id: &str) -> String {
+    format!("/api/v2/incidents/{incident_id}/relationships/todos")
+}
+
+/// Incident and todo ids end up in request paths, so only accept the
+/// characters that real ids (UUIDs) use.
+fn validate_todo_ids(incident_id: &str, todo_id: Option<&str>) -> Result<()> {
+    let valid = |id: &str| {
+        !id.is_empty()
+            && id
+                .chars()
+                .all(|c| c.is_ascii_alphanumeric() || c == '-' || c == '_')
+    };
+    if !valid(incident_id) {
+        bail!("invalid incident id {incident_id:?}");
+    }
+    if let Some(todo_id) = todo_id.filter(|id| !valid(id)) {
+        bail!("invalid incident todo id {todo_id:?}");
+    }
+    Ok(())
+}
+
+/// Todo assignees are Datadog handles: "@" followed by a user handle or
+/// email, e.g. @jane@example.com.
+fn validate_assignee(assignee: &str) -> Result<()> {
+    match assignee.strip_prefix('@') {
+        Some(handle) if !handle.is_empty() && !handle.contains(char::is_whitespace) => Ok(()),
+        _ => bail!("invalid assignee {assignee:?}: expected a handle such as @jane@example.com"),
+    }
+}
+
+fn todo_body(content: &str, assignees: &[String]) -> Result<serde_json::Value> {
+    if content.trim().is_empty() {
+        bail!("--content must not be empty");
+    }
+    for assignee in assignees {
+        validate_assignee(assignee)?;
+    }
+    Ok(serde_json::json!({
+        "data": {
+            "type": "incident_todos",
+            "attributes": {
+                "content": content,
+                "assignees": assignees,
+            }
+        }
+    }))
+}
+
+/// Update body marking `todo` (a `get` response) completed at `completed`.
+/// The API replaces content and assignees on update, so both are carried
+/// over from the current todo. None when it is already completed.
+fn complete_todo_body(todo: &serde_json::Value, completed: &str) -> Option<serde_json::Value> {
+    let attrs = &todo["data"]["attributes"];
+    if !attrs["completed"].is_null() {
+        return None;
+    }
+    Some(serde_json::json!({
+        "data": {
+            "type": "incident_todos",
+            "attributes": {
+                "content": attrs["content"],
+                "assignees": attrs.get("assignees").cloned().unwrap_or_else(|| serde_json::json!([])),
+                "completed": completed,
+            }
+        }
+    }))
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn todos_list(cfg: &Config, incident_id: &str) -> Result<()> {
+    validate_todo_ids(incident_id, None)?;
+    let data = client::raw_get(cfg, &todos_path(incident_id))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list incident todos: {e}"))?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn todos_list(cfg: &Config, incident_id: &str) -> Result<()> {
+    validate_todo_ids(incident_id, None)?;
+    let data = crate::api::get(cfg, &todos_path(incident_id), &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn todos_add(
+    cfg: &Config,
+    incident_id: &str,
+    content: &str,
+    assignees: &[String],
+) -> Result<()> {
+    validate_todo_ids(incident_id, None)?;
+    let body = todo_body(content, assignees)?;
+    let data = client::raw_post(cfg, &todos_path(incident_id), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to add incident todo: {e}"))?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn todos_add(
+    cfg: &Config,
+    incident_id: &str,
+    content: &str,
+    assignees: &[String],
+) -> Result<()> {
+    validate_todo_ids(incident_id, None)?;
+    let body = todo_body(content, assignees)?;
+    let data = crate::api::post(cfg, &todos_path(incident_id), &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn todos_complete(cfg: &Config, incident_id: &str, todo_id: &str) -> Result<()> {
+    validate_todo_ids(incident_id, Some(todo_id))?;
+    let path = format!("{}/{todo_id}", todos_path(incident_id));
+    let todo = client::raw_get(cfg, &path)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get incident todo: {e}"))?;
+    let Some(body) = complete_todo_body(&todo, &chrono::Utc::now().to_rfc3339()) else {
+        formatter::print_status(&format!("Incident todo {todo_id} is already completed."));
+        return formatter::output(cfg, &todo);
+    };
+    let resp = client::raw_request(cfg, "PATCH", &path, Some(&body))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to complete incident todo: {e}"))?;
+    let data: serde_json::Value = resp.json().await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn todos_complete(cfg: &Config, incident_id: &str, todo_id: &str) -> Result<()> {
+    validate_todo_ids(incident_id, Some(todo_id))?;
+    let path = format!("{}/{todo_id}", todos_path(incident_id));
+    let todo = crate::api::get(cfg, &path, &[]).await?;
+    let Some(body) = complete_todo_body(&todo, &chrono::Utc::now().to_rfc3339()) else {
+        formatter::print_status(&format!("Incident todo {todo_id} is already completed."));
+        return crate::formatter::output(cfg, &todo);
+    };
+    let data = crate::api::patch(cfg, &path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
 // ---------------------------------------------------------------------------
 // Global incident settings
 // ---------------------------------------------------------------------------
@@ -216,9 +542,83 @@ pub async fn handles_create(cfg: &Config, file: &str) -> Result<()> {
     crate::formatter::output(cfg, &data)
 }
 
+/// Handle ids end up in request paths, so only accept the characters that
+/// real ids (UUIDs) use.
+fn validate_handle_id(id: &str) -> Result<()> {
+    if id.is_empty()
+        || !id
+            .chars()
+            .all(|c| c.is_ascii_alphanumeric() || c == '-' || c == '_')
+    {
+        bail!("invalid incident handle id {id:?}");
+    }
+    Ok(())
+}
+
+/// Points an update body at handle `id`. A body that already names a
+/// different handle is rejected rather than silently updating that one.
+fn handle_update_body(id: &str, mut body: serde_json::Value) -> Result<serde_json::Value> {
+    let Some(data) = body.get_mut("data").and_then(|d| d.as_object_mut()) else {
+        bail!("invalid incident handle request: expected {{\"data\": {{...}}}}");
+    };
+    match data.get("id").and_then(|v| v.as_str()) {
+        Some(existing) if existing != id => {
+            bail!("--file updates handle {existing}, but handle {id} was given")
+        }
+        _ => {
+            data.insert("id".into(), serde_json::Value::String(id.to_string()));
+        }
+    }
+    Ok(body)
+}
+
+/// Path of the single handle `id`; callers validate the id first.
+fn handle_path(id: &str) -> String {
+    format!("/api/v2/incidents/config/handles/{id}")
+}
+
+/// Picks handle `id` out of a handles list response. The API only exposes
+/// the handle collection, so single-handle reads go through the list.
+fn find_handle(list: &serde_json::Value, id: &str) -> Result<serde_json::Value> {
+    list.get("data")
+        .and_then(|d| d.as_array())
+        .and_then(|handles| {
+            handles
+                .iter()
+                .find(|h| h.get("id").and_then(|v| v.as_str()) == Some(id))
+        })
+        .map(|handle| serde_json::json!({ "data": handle }))
+        .ok_or_else(|| anyhow::anyhow!("incident handle {id} not found"))
+}
+
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn handles_update(cfg: &Config, file: &str) -> Result<()> {
-    let body = util::read_json_file(file)?;
+async fn fetch_handle(cfg: &Config, handle_id: &str) -> Result<serde_json::Value> {
+    validate_handle_id(handle_id)?;
+    let resp = make_api(cfg)
+        .list_global_incident_handles(ListGlobalIncidentHandlesOptionalParams::default())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list incident handles: {:?}", e))?;
+    find_handle(&serde_json::to_value(resp)?, handle_id)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn fetch_handle(cfg: &Config, handle_id: &str) -> Result<serde_json::Value> {
+    validate_handle_id(handle_id)?;
+    let data = crate::api::get(cfg, "/api/v2/incidents/config/handles", &[]).await?;
+    find_handle(&data, handle_id)
+}
+
+pub async fn handles_get(cfg: &Config, handle_id: &str) -> Result<()> {
+    let handle = fetch_handle(cfg, handle_id).await?;
+    formatter::output(cfg, &handle)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn handles_update(cfg: &Config, handle_id: &str, file: &str) -> Result<()> {
+    validate_handle_id(handle_id)?;
+    let body = handle_update_body(handle_id, util::read_json_file(file)?)?;
+    let body = serde_json::from_value(body)
+        .map_err(|e| anyhow::anyhow!("invalid incident handle request: {e}"))?;
     let api = make_api(cfg);
     let resp = api
         .update_global_incident_handle(body, UpdateGlobalIncidentHandleOptionalParams::default())
@@ -228,26 +628,37 @@ pub async fn handles_update(cfg: &Config, file: &str) -> Result<()> {
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn handles_update(cfg: &Config, file: &str) -> Result<()> {
-    let body: serde_json::Value = util::read_json_file(file)?;
+pub async fn handles_update(cfg: &Config, handle_id: &str, file: &str) -> Result<()> {
+    validate_handle_id(handle_id)?;
+    let body = handle_update_body(handle_id, util::read_json_file(file)?)?;
     let data = crate::api::patch(cfg, "/api/v2/incidents/config/handles", &body).await?;
     crate::formatter::output(cfg, &data)
 }
 
+/// Deletes handle `handle_id` after checking it exists and confirming. The
+/// typed client only offers the id-less collection delete, which would drop
+/// every handle, so the request goes to the handle's own path.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn handles_delete(cfg: &Config, _handle_id: &str) -> Result<()> {
-    let api = make_api(cfg);
-    api.delete_global_incident_handle()
+pub async fn handles_delete(cfg: &Config, handle_id: &str) -> Result<()> {
+    fetch_handle(cfg, handle_id).await?;
+    if !util::confirm(cfg, &format!("Delete incident handle {handle_id}?"))? {
+        return Ok(());
+    }
+    client::raw_delete(cfg, &handle_path(handle_id))
         .await
-        .map_err(|e| anyhow::anyhow!("failed to delete incident handle: {:?}", e))?;
-    println!("Incident handle deleted.");
+        .map_err(|e| anyhow::anyhow!("failed to delete incident handle: {e}"))?;
+    formatter::print_block(&format!("Incident handle {handle_id} deleted.\n"))?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn handles_delete(cfg: &Config, _handle_id: &str) -> Result<()> {
-    crate::api::delete(cfg, "/api/v2/incidents/config/handles").await?;
-    println!("Incident handle deleted.");
+pub async fn handles_delete(cfg: &Config, handle_id: &str) -> Result<()> {
+    fetch_handle(cfg, handle_id).await?;
+    if !util::confirm(cfg, &format!("Delete incident handle {handle_id}?"))? {
+        return Ok(());
+    }
+    crate::api::delete(cfg, &handle_path(handle_id)).await?;
+    formatter::print_block(&format!("Incident handle {handle_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -340,7 +751,7 @@ pub async fn postmortem_templates_delete(cfg: &Config, template_id: &str) -> Res
     api.delete_incident_postmortem_template(template_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete postmortem template: {:?}", e))?;
-    println!("Postmortem template {template_id} deleted.");
+    formatter::print_block(&format!("Postmortem template {template_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -348,6 +759,193 @@ pub async fn postmortem_templates_delete(cfg: &Config, template_id: &str) -> Res
 pub async fn postmortem_templates_delete(cfg: &Config, template_id: &str) -> Result<()> {
     let path = format!("/api/v2/incidents/config/postmortem-templates/{template_id}");
     crate::api::delete(cfg, &path).await?;
-    println!("Postmortem template {template_id} deleted.");
+    formatter::print_block(&format!("Postmortem template {template_id} deleted.\n"))?;
     Ok(())
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_postmortem_markdown() {
+        let incident = serde_json::json!({"data": {
+            "id": "abc",
+            "type": "incidents",
+            "attributes": {
+                "title": "Checkout errors",
+                "public_id": 42,
+                "customer_impacted": true,
+                "customer_impact_scope": "EU checkout",
+                "customer_impact_duration": 3900,
+                "created": "2024-03-01T10:20:00.000000+00:00",
+                "detected": "2024-03-01T10:15:00.000000+00:00",
+                "customer_impact_start": "2024-03-01T10:05:00.000000+00:00",
+                "customer_impact_end": "2024-03-01T11:10:00.000000+00:00",
+                "resolved": "2024-03-01T11:30:00.000000+00:00",
+                "fields": {
+                    "severity": {"type": "dropdown", "value": "SEV-2"},
+                    "state": {"type": "dropdown", "value": "resolved"},
+                    "summary": {"type": "textbox", "value": "Payments timed out."},
+                    "root_cause": {"type": "textbox", "value": null}
+                }
+            }
+        }});
+        let todos = vec![
+            serde_json::json!({"attributes": {
+                "content": "Add retry budget",
+                "completed": null,
+                "assignees": ["alice@example.com"],
+                "due_date": "2024-03-08T00:00:00+00:00"
+            }}),
+            serde_json::json!({"attributes": {
+                "content": "Page payments on timeouts",
+                "completed": "2024-03-02T09:00:00+00:00",
+                "assignees": []
+            }}),
+        ];
+        assert_eq!(
+            postmortem_markdown(&incident, &todos),
+            "# Postmortem: Checkout errors\n\
+             \n\
+             | | |\n\
+             |---|---|\n\
+             | Incident | #42 |\n\
+             | Severity | SEV-2 |\n\
+             | State | resolved |\n\
+             \n\
+             ## Summary\n\
+             \n\
+             Payments timed out.\n\
+             \n\
+             ## Impact\n\
+             \n\
+             - Customer impact: yes\n\
+             - Scope: EU checkout\n\
+             - Duration: 1h 5m\n\
+             \n\
+             ## Root Cause\n\
+             \n\
+             _No root cause recorded._\n\
+             \n\
+             ## Timeline\n\
+             \n\
+             | Time (UTC) | Event |\n\
+             |---|---|\n\
+             | 2024-03-01 10:05 | Customer impact began |\n\
+             | 2024-03-01 10:15 | Detected |\n\
+             | 2024-03-01 10:20 | Incident declared |\n\
+             | 2024-03-01 11:10 | Customer impact ended |\n\
+             | 2024-03-01 11:30 | Resolved |\n\
+             \n\
+             ## Action Items\n\
+             \n\
+             - [ ] Add retry budget (@alice@example.com) — due 2024-03-08 00:00\n\
+             - [x] Page payments on timeouts\n"
+        );
+    }
+
+    #[test]
+    fn test_postmortem_markdown_minimal() {
+        let incident =
+            serde_json::json!({"data": {"attributes": {"title": "Blip", "severity": "UNKNOWN"}}});
+        let md = postmortem_markdown(&incident, &[]);
+        assert!(md.contains("| Severity | UNKNOWN |"));
+        assert!(md.contains("| State | - |"));
+        assert!(md.contains("_No summary recorded._"));
+        assert!(md.contains("- Customer impact: no"));
+        assert!(md.contains("_No action items recorded._"));
+    }
+
+    #[test]
+    fn test_validate_handle_id() {
+        assert!(validate_handle_id("3f2a9c1e-77b0-4d1e-9a55-0c1d2e3f4a5b").is_ok());
+        assert!(validate_handle_id("").is_err());
+        assert!(validate_handle_id("../settings").is_err());
+        assert!(validate_handle_id("a b").is_err());
+    }
+
+    #[test]
+    fn test_find_handle() {
+        let list = serde_json::json!({"data": [
+            {"id": "h-1", "attributes": {"name": "@sre"}},
+            {"id": "h-2", "attributes": {"name": "@dba"}}
+        ]});
+        let handle = find_handle(&list, "h-2").unwrap();
+        assert_eq!(handle["data"]["attributes"]["name"], "@dba");
+        let err = find_handle(&list, "h-3").unwrap_err().to_string();
+        assert!(err.contains("h-3 not found"), "{err}");
+        assert!(find_handle(&serde_json::json!({}), "h-1").is_err());
+    }
+
+    #[test]
+    fn test_handle_update_body_sets_id() {
+        let body = serde_json::json!({"data": {"type": "incidents_handles", "attributes": {"name": "@sre"}}});
+        let body = handle_update_body("h-1", body).unwrap();
+        assert_eq!(body["data"]["id"], "h-1");
+        assert_eq!(body["data"]["attributes"]["name"], "@sre");
+
+        let same = serde_json::json!({"data": {"id": "h-1"}});
+        assert!(handle_update_body("h-1", same).is_ok());
+    }
+
+    #[test]
+    fn test_handle_update_body_rejects_other_handle() {
+        let body = serde_json::json!({"data": {"id": "h-2"}});
+        let err = handle_update_body("h-1", body).unwrap_err().to_string();
+        assert!(err.contains("h-2") && err.contains("h-1"), "{err}");
+        assert!(handle_update_body("h-1", serde_json::json!([])).is_err());
+    }
+
+    #[test]
+    fn test_validate_assignee() {
+        assert!(validate_assignee("@jane@example.com").is_ok());
+        assert!(validate_assignee("@sre-oncall").is_ok());
+        assert!(validate_assignee("jane@example.com").is_err());
+        assert!(validate_assignee("@").is_err());
+        assert!(validate_assignee("@jane doe").is_err());
+    }
+
+    #[test]
+    fn test_validate_todo_ids() {
+        assert!(validate_todo_ids("00000000-aaaa-0000-0000-000000000000", Some("t-1")).is_ok());
+        assert!(validate_todo_ids("inc/../x", None).is_err());
+        assert!(validate_todo_ids("inc-1", Some("")).is_err());
+    }
+
+    #[test]
+    fn test_todo_body() {
+        let body = todo_body("Roll back deploy", &["@jane@example.com".into()]).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({"data": {"type": "incident_todos", "attributes": {
+                "content": "Roll back deploy",
+                "assignees": ["@jane@example.com"]
+            }}})
+        );
+        assert!(todo_body("  ", &[]).is_err());
+        assert!(todo_body("Roll back", &["jane".into()]).is_err());
+    }
+
+    #[test]
+    fn test_complete_todo_body() {
+        let todo = serde_json::json!({"data": {"id": "t-1", "type": "incident_todos", "attributes": {
+            "content": "Roll back deploy",
+            "assignees": ["@jane@example.com"],
+            "completed": null
+        }}});
+        let body = complete_todo_body(&todo, "2024-03-01T12:00:00+00:00").unwrap();
+        assert_eq!(
+            body["data"]["attributes"],
+            serde_json::json!({
+                "content": "Roll back deploy",
+                "assignees": ["@jane@example.com"],
+                "completed": "2024-03-01T12:00:00+00:00"
+            })
+        );
+
+        let mut done = todo.clone();
+        done["data"]["attributes"]["completed"] = "2024-03-01T11:00:00+00:00".into();
+        assert!(complete_todo_body(&done, "2024-03-01T12:00:00+00:00").is_none());
+    }
+}
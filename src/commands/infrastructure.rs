@@ -1,50 +1,112 @@
-use anyhow::Result;
+use anyhow::{bail, Result};
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::api_hosts::{HostsAPI, ListHostsOptionalParams};
+#[cfg(not(target_arch = "wasm32"))]
+use datadog_api_client::datadogV1::model::HostMuteSettings;
 
 #[cfg(not(target_arch = "wasm32"))]
 use crate::client;
 use crate::config::Config;
 use crate::formatter;
+use crate::util;
 
-#[cfg(not(target_arch = "wasm32"))]
+/// Largest page the v1 hosts endpoint returns per request.
+const HOSTS_PAGE_SIZE: i64 = 1000;
+
+/// Lists up to `count` hosts, paging through the v1 hosts endpoint. `from`
+/// limits the listing to hosts that reported since that time.
 pub async fn hosts_list(
     cfg: &Config,
     filter: Option<String>,
     sort: String,
     count: i64,
+    from: Option<String>,
 ) -> Result<()> {
+    let from = from.as_deref().map(util::parse_time_to_unix).transpose()?;
+    let mut hosts: Vec<serde_json::Value> = Vec::new();
+    let mut total_matching = 0;
+    while (hosts.len() as i64) < count {
+        let page_size = (count - hosts.len() as i64).min(HOSTS_PAGE_SIZE);
+        let page = hosts_page(
+            cfg,
+            filter.as_deref(),
+            &sort,
+            from,
+            hosts.len() as i64,
+            page_size,
+        )
+        .await?;
+        total_matching = page["total_matching"].as_i64().unwrap_or(0);
+        let batch = page["host_list"].as_array().cloned().unwrap_or_default();
+        let last = (batch.len() as i64) < page_size;
+        hosts.extend(batch);
+        if last || hosts.len() as i64 >= total_matching {
+            break;
+        }
+    }
+    let total_returned = hosts.len();
+    formatter::output(
+        cfg,
+        &serde_json::json!({
+            "host_list": hosts,
+            "total_matching": total_matching,
+            "total_returned": total_returned,
+        }),
+    )
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn hosts_page(
+    cfg: &Config,
+    filter: Option<&str>,
+    sort: &str,
+    from: Option<i64>,
+    start: i64,
+    count: i64,
+) -> Result<serde_json::Value> {
     let dd_cfg = client::make_dd_config(cfg);
     let api = match client::make_bearer_client(cfg) {
         Some(c) => HostsAPI::with_client_and_config(dd_cfg, c),
         None => HostsAPI::with_config(dd_cfg),
     };
     let mut params = ListHostsOptionalParams::default()
+        .start(start)
         .count(count)
-        .sort_field(sort);
+        .sort_field(sort.to_string());
     if let Some(f) = filter {
-        params = params.filter(f);
+        params = params.filter(f.to_string());
+    }
+    if let Some(from) = from {
+        params = params.from(from);
     }
     let resp = api
         .list_hosts(params)
         .await
         .map_err(|e| anyhow::anyhow!("failed to list hosts: {e:?}"))?;
-    formatter::output(cfg, &resp)
+    Ok(serde_json::to_value(&resp)?)
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn hosts_list(
+async fn hosts_page(
     cfg: &Config,
-    filter: Option<String>,
-    sort: String,
+    filter: Option<&str>,
+    sort: &str,
+    from: Option<i64>,
+    start: i64,
     count: i64,
-) -> Result<()> {
-    let mut query = vec![("count", count.to_string()), ("sort_field", sort)];
+) -> Result<serde_json::Value> {
+    let mut query = vec![
+        ("start", start.to_string()),
+        ("count", count.to_string()),
+        ("sort_field", sort.to_string()),
+    ];
     if let Some(f) = filter {
-        query.push(("filter", f));
+        query.push(("filter", f.to_string()));
     }
-    let data = crate::api::get(cfg, "/api/v1/hosts", &query).await?;
-    crate::formatter::output(cfg, &data)
+    if let Some(from) = from {
+        query.push(("from", from.to_string()));
+    }
+    crate::api::get(cfg, "/api/v1/hosts", &query).await
 }
 
 #[cfg(not(target_arch = "wasm32"))]
@@ -72,3 +134,110 @@ pub async fn hosts_get(cfg: &Config, hostname: &str) -> Result<()> {
     let data = crate::api::get(cfg, "/api/v1/hosts", &query).await?;
     crate::formatter::output(cfg, &data)
 }
+
+/// Mutes a host's monitor notifications, until `end` when given (a duration
+/// from now like `2h`, or an absolute time) and indefinitely otherwise.
+pub async fn hosts_mute(
+    cfg: &Config,
+    hostname: &str,
+    end: Option<&str>,
+    message: Option<String>,
+) -> Result<()> {
+    let body = mute_body(end, message, chrono::Utc::now().timestamp())?;
+    let data = post_host_action(cfg, hostname, "mute", body).await?;
+    formatter::output(cfg, &data)
+}
+
+pub async fn hosts_unmute(cfg: &Config, hostname: &str) -> Result<()> {
+    let data = post_host_action(cfg, hostname, "unmute", serde_json::json!({})).await?;
+    formatter::output(cfg, &data)
+}
+
+/// Host mute body, with `end` resolved to Unix seconds relative to `now`.
+fn mute_body(end: Option<&str>, message: Option<String>, now: i64) -> Result<serde_json::Value> {
+    let mut body = serde_json::json!({});
+    if let Some(end) = end {
+        body["end"] = mute_end(end, now)?.into();
+    }
+    if let Some(message) = message {
+        body["message"] = message.into();
+    }
+    Ok(body)
+}
+
+/// Resolves `--end`: a bare duration counts forward from `now`, anything else
+/// is parsed as a point in time. Either way it has to be in the future.
+fn mute_end(end: &str, now: i64) -> Result<i64> {
+    let secs = match util::parse_duration_millis(end) {
+        Ok(ms) => now + ms / 1000,
+        Err(_) => util::parse_time_to_unix(end)?,
+    };
+    if secs <= now {
+        bail!("--end must be in the future, got {end:?}");
+    }
+    Ok(secs)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn post_host_action(
+    cfg: &Config,
+    hostname: &str,
+    action: &str,
+    body: serde_json::Value,
+) -> Result<serde_json::Value> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => HostsAPI::with_client_and_config(dd_cfg, c),
+        None => HostsAPI::with_config(dd_cfg),
+    };
+    let resp = if action == "mute" {
+        let settings: HostMuteSettings = serde_json::from_value(body)?;
+        api.mute_host(hostname.to_string(), settings)
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to mute host {hostname}: {e:?}"))?
+    } else {
+        api.unmute_host(hostname.to_string())
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to unmute host {hostname}: {e:?}"))?
+    };
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn post_host_action(
+    cfg: &Config,
+    hostname: &str,
+    action: &str,
+    body: serde_json::Value,
+) -> Result<serde_json::Value> {
+    crate::api::post(cfg, &format!("/api/v1/host/{hostname}/{action}"), &body).await
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const NOW: i64 = 1_700_000_000;
+
+    #[test]
+    fn test_mute_end_duration_counts_forward() {
+        assert_eq!(mute_end("2h", NOW).unwrap(), NOW + 7200);
+    }
+
+    #[test]
+    fn test_mute_end_absolute_time() {
+        let end = mute_end("2099-01-01T00:00:00Z", NOW).unwrap();
+        assert_eq!(end, 4_070_908_800);
+    }
+
+    #[test]
+    fn test_mute_end_rejects_past() {
+        assert!(mute_end("2020-01-01T00:00:00Z", NOW).is_err());
+    }
+
+    #[test]
+    fn test_mute_body_without_end_mutes_indefinitely() {
+        let body = mute_body(None, Some("patching".into()), NOW).unwrap();
+        assert_eq!(body, serde_json::json!({"message": "patching"}));
+    }
+}
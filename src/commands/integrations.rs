@@ -98,7 +98,7 @@ pub async fn jira_accounts_delete(cfg: &Config, account_id: &str) -> Result<()>
     api.delete_jira_account(uuid)
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete Jira account: {e:?}"))?;
-    println!("Jira account {account_id} deleted.");
+    formatter::print_block(&format!("Jira account {account_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -110,10 +110,44 @@ pub async fn jira_accounts_delete(cfg: &Config, account_id: &str) -> Result<()>
         &format!("/api/v2/integration/jira/accounts/{account_id}"),
     )
     .await?;
-    println!("Jira account {account_id} deleted.");
+    formatter::print_block(&format!("Jira account {account_id} deleted.\n"))?;
     Ok(())
 }
 
+/// Checks that a Jira account request body has the `{"data": {"attributes": {...}}}` shape.
+fn validate_jira_account_body(body: &serde_json::Value) -> Result<()> {
+    if !body["data"]["attributes"].is_object() {
+        anyhow::bail!(
+            "invalid Jira account request: expected {{\"data\": {{\"type\": \"jira-account\", \"attributes\": {{...}}}}}}"
+        );
+    }
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn jira_accounts_create(cfg: &Config, file: &str) -> Result<()> {
+    let body: serde_json::Value = util::read_json_file(file)?;
+    validate_jira_account_body(&body)?;
+    let data = client::raw_post(cfg, "/api/v2/integration/jira/accounts", body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create Jira account: {e}"))?;
+    if let Some(id) = data["data"]["id"].as_str() {
+        formatter::print_status(&format!("Jira account {id} created."));
+    }
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn jira_accounts_create(cfg: &Config, file: &str) -> Result<()> {
+    let body: serde_json::Value = util::read_json_file(file)?;
+    validate_jira_account_body(&body)?;
+    let data = crate::api::post(cfg, "/api/v2/integration/jira/accounts", &body).await?;
+    if let Some(id) = data["data"]["id"].as_str() {
+        formatter::print_status(&format!("Jira account {id} created."));
+    }
+    crate::formatter::output(cfg, &data)
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn jira_templates_create(cfg: &Config, file: &str) -> Result<()> {
     let dd_cfg = client::make_dd_config(cfg);
@@ -176,7 +210,7 @@ pub async fn jira_templates_delete(cfg: &Config, template_id: &str) -> Result<()
     api.delete_jira_issue_template(uuid)
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete Jira template: {e:?}"))?;
-    println!("Jira template {template_id} deleted.");
+    formatter::print_block(&format!("Jira template {template_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -188,7 +222,7 @@ pub async fn jira_templates_delete(cfg: &Config, template_id: &str) -> Result<()
         &format!("/api/v2/integration/jira/issue_templates/{template_id}"),
     )
     .await?;
-    println!("Jira template {template_id} deleted.");
+    formatter::print_block(&format!("Jira template {template_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -331,7 +365,7 @@ pub async fn servicenow_templates_delete(cfg: &Config, template_id: &str) -> Res
     api.delete_service_now_template(uuid)
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete ServiceNow template: {e:?}"))?;
-    println!("ServiceNow template {template_id} deleted.");
+    formatter::print_block(&format!("ServiceNow template {template_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -343,7 +377,7 @@ pub async fn servicenow_templates_delete(cfg: &Config, template_id: &str) -> Res
         &format!("/api/v2/integration/servicenow/templates/{template_id}"),
     )
     .await?;
-    println!("ServiceNow template {template_id} deleted.");
+    formatter::print_block(&format!("ServiceNow template {template_id} deleted.\n"))?;
     Ok(())
 }
 
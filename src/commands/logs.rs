@@ -1,5 +1,13 @@
 use anyhow::{bail, Result};
 #[cfg(not(target_arch = "wasm32"))]
+use datadog_api_client::datadogV1::api_logs_indexes::LogsIndexesAPI;
+#[cfg(not(target_arch = "wasm32"))]
+use datadog_api_client::datadogV1::api_logs_pipelines::LogsPipelinesAPI;
+#[cfg(not(target_arch = "wasm32"))]
+use datadog_api_client::datadogV1::model::{
+    LogsIndex, LogsIndexUpdateRequest, LogsIndexesOrder, LogsPipeline, LogsPipelinesOrder,
+};
+#[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV2::api_logs::{ListLogsOptionalParams, LogsAPI};
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV2::api_logs_archives::LogsArchivesAPI;
@@ -9,14 +17,17 @@ use datadog_api_client::datadogV2::api_logs_custom_destinations::LogsCustomDesti
 use datadog_api_client::datadogV2::api_logs_metrics::LogsMetricsAPI;
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV2::model::{
-    LogsAggregateRequest, LogsAggregationFunction, LogsCompute, LogsListRequest,
-    LogsListRequestPage, LogsQueryFilter, LogsSort,
+    LogsAggregateRequest, LogsAggregationFunction, LogsCompute, LogsComputeType, LogsGroupBy,
+    LogsListRequest, LogsListRequestPage, LogsListResponse, LogsQueryFilter, LogsSort,
 };
 
 #[cfg(not(target_arch = "wasm32"))]
 use crate::client;
+#[cfg(not(target_arch = "wasm32"))]
+use crate::commands::events::StreamCursor;
 use crate::config::Config;
 use crate::formatter;
+use crate::plan::{Plan, RequestCount};
 use crate::util;
 
 #[cfg(not(target_arch = "wasm32"))]
@@ -26,7 +37,10 @@ pub async fn search(
     from: String,
     to: String,
     limit: i32,
+    chunk: Option<String>,
+    storage: Option<String>,
 ) -> Result<()> {
+    let tiers = storage_tiers(storage.as_deref())?;
     // Logs search API doesn't support OAuth/bearer - force API keys
     if !cfg.has_api_keys() {
         bail!(
@@ -35,29 +49,16 @@ pub async fn search(
         );
     }
 
-    let dd_cfg = client::make_dd_config(cfg);
-    // Force API key auth only - do NOT use bearer middleware
-    let api = LogsAPI::with_config(dd_cfg);
-
     let from_ms = util::parse_time_to_unix_millis(&from)?;
     let to_ms = util::parse_time_to_unix_millis(&to)?;
+    if tiers.len() > 1 {
+        return search_tiers(cfg, query, from_ms, to_ms, limit, chunk, tiers).await;
+    }
 
-    let body = LogsListRequest::new()
-        .filter(
-            LogsQueryFilter::new()
-                .query(query)
-                .from(from_ms.to_string())
-                .to(to_ms.to_string()),
-        )
-        .page(LogsListRequestPage::new().limit(limit))
-        .sort(LogsSort::TIMESTAMP_DESCENDING);
-
-    let params = ListLogsOptionalParams::default().body(body);
-
-    let resp = api
-        .list_logs(params)
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to search logs: {:?}", e))?;
+    let dd_cfg = client::make_dd_config(cfg);
+    // Force API key auth only - do NOT use bearer middleware
+    let api = LogsAPI::with_config(dd_cfg);
+    let resp = fetch_range(&api, &query, from_ms, to_ms, limit, chunk, tiers[0]).await?;
 
     let meta = if cfg.agent_mode {
         let count = resp.data.as_ref().map(|d| d.len());
@@ -78,345 +79,3490 @@ pub async fn search(
     } else {
         None
     };
-    formatter::format_and_print(&resp, &cfg.output_format, cfg.agent_mode, meta.as_ref())?;
+    formatter::output_with_meta(cfg, &resp, meta.as_ref())?;
     Ok(())
 }
 
-#[cfg(target_arch = "wasm32")]
-pub async fn search(
-    cfg: &Config,
-    query: String,
-    from: String,
-    to: String,
+/// Fetches up to `limit` logs from one storage tier (the API default when
+/// None), newest first: a single request, or each `chunk`-sized window in
+/// turn, paged until it is exhausted.
+#[cfg(not(target_arch = "wasm32"))]
+async fn fetch_range(
+    api: &LogsAPI,
+    query: &str,
+    from_ms: i64,
+    to_ms: i64,
     limit: i32,
-) -> Result<()> {
-    let from_ms = util::parse_time_to_unix_millis(&from)?;
-    let to_ms = util::parse_time_to_unix_millis(&to)?;
-    let body = serde_json::json!({
-        "filter": {
-            "query": query,
-            "from": from_ms.to_string(),
-            "to": to_ms.to_string()
-        },
-        "page": { "limit": limit },
-        "sort": "-timestamp"
-    });
-    let data = crate::api::post(cfg, "/api/v2/logs/events/search", &body).await?;
-    crate::formatter::output(cfg, &data)
+    chunk: Option<String>,
+    tier: Option<&str>,
+) -> Result<LogsListResponse> {
+    let Some(chunk) = chunk else {
+        return fetch_logs(api, query, from_ms, to_ms, limit, tier).await;
+    };
+    let windows = util::split_time_range(from_ms, to_ms, util::parse_duration_millis(&chunk)?)?;
+    let total = windows.len();
+    let mut merged = Vec::new();
+    // Newest window first so merged results stay in descending timestamp order
+    for (i, (start, end)) in windows.into_iter().rev().enumerate() {
+        let remaining = limit - merged.len() as i32;
+        if remaining <= 0 {
+            break;
+        }
+        let end = window_end(end, to_ms);
+        formatter::print_status(&format!(
+            "Fetching chunk {}/{total} ({} to {})...",
+            i + 1,
+            format_millis(start),
+            format_millis(end)
+        ));
+        let mut cursor = None;
+        let mut fetched = 0;
+        while fetched < remaining {
+            let resp = fetch_logs_page(
+                api,
+                logs_filter(query, start, end, tier),
+                (remaining - fetched).min(EXPORT_PAGE_SIZE),
+                LogsSort::TIMESTAMP_DESCENDING,
+                cursor,
+            )
+            .await?;
+            let page = resp.data.unwrap_or_default();
+            if page.is_empty() {
+                break;
+            }
+            fetched += page.len() as i32;
+            merged.extend(page);
+            cursor = resp.meta.and_then(|m| m.page).and_then(|p| p.after);
+            if cursor.is_none() {
+                break;
+            }
+        }
+    }
+    Ok(LogsListResponse::new().data(merged))
 }
 
-/// Alias for `search` with the same interface.
-pub async fn list(cfg: &Config, query: String, from: String, to: String, limit: i32) -> Result<()> {
-    search(cfg, query, from, to, limit).await
+/// Inclusive end to search a `split_time_range` window up to. The API's
+/// `to` is inclusive and adjacent windows share their boundary millisecond,
+/// so every window but the last stops just before the next one starts.
+fn window_end(end: i64, to_ms: i64) -> i64 {
+    if end < to_ms {
+        end - 1
+    } else {
+        end
+    }
 }
 
-/// Alias for `search` with the same interface.
-pub async fn query(
+/// `--storage=all-parallel`: runs the search against every tier at once and
+/// merges the results. A tier that fails (e.g. Flex not enabled for the
+/// org) is reported and skipped; the search fails only if every tier does.
+#[cfg(not(target_arch = "wasm32"))]
+async fn search_tiers(
     cfg: &Config,
     query: String,
-    from: String,
-    to: String,
+    from_ms: i64,
+    to_ms: i64,
     limit: i32,
+    chunk: Option<String>,
+    tiers: Vec<Option<&'static str>>,
 ) -> Result<()> {
-    search(cfg, query, from, to, limit).await
-}
+    let mut tasks = tokio::task::JoinSet::new();
+    for (i, tier) in tiers.iter().copied().enumerate() {
+        let cfg = cfg.clone();
+        let query = query.clone();
+        let chunk = chunk.clone();
+        tasks.spawn(async move {
+            let _slot = client::acquire_request_slot().await;
+            let api = LogsAPI::with_config(client::make_dd_config(&cfg));
+            let resp = fetch_range(&api, &query, from_ms, to_ms, limit, chunk, tier).await;
+            (i, resp.and_then(|r| log_values(&r)))
+        });
+    }
 
-#[cfg(not(target_arch = "wasm32"))]
-pub async fn aggregate(cfg: &Config, query: String, from: String, to: String) -> Result<()> {
-    if !cfg.has_api_keys() {
-        bail!(
-            "logs aggregate requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
-             This endpoint does not support bearer token auth."
-        );
+    let mut outcomes: Vec<Option<Result<Vec<serde_json::Value>>>> =
+        tiers.iter().map(|_| None).collect();
+    while let Some(joined) = tasks.join_next().await {
+        if let Ok((i, outcome)) = joined {
+            outcomes[i] = Some(outcome);
+        }
     }
 
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsAPI::with_config(dd_cfg);
+    let mut results = Vec::new();
+    let mut first_err = None;
+    for (tier, outcome) in tiers.iter().zip(outcomes) {
+        let tier = tier.unwrap_or_default();
+        match outcome.unwrap_or_else(|| Err(anyhow::anyhow!("task aborted"))) {
+            Ok(logs) => results.push((tier, logs)),
+            Err(e) => {
+                formatter::print_status(&format!("warning: {tier} search failed: {e}"));
+                first_err = first_err.or(Some(e));
+            }
+        }
+    }
+    if results.is_empty() {
+        if let Some(e) = first_err {
+            return Err(e);
+        }
+    }
+    let merged = merge_tier_results(results, limit);
+    formatter::output(cfg, &serde_json::json!({ "data": merged }))
+}
 
-    let from_ms = util::parse_time_to_unix_millis(&from)?;
-    let to_ms = util::parse_time_to_unix_millis(&to)?;
+/// Storage tiers `--storage` accepts.
+const LOG_STORAGE_TIERS: &[&str] = &["indexes", "online-archives", "flex"];
 
-    let body = LogsAggregateRequest::new()
-        .filter(
-            LogsQueryFilter::new()
-                .query(query)
-                .from(from_ms.to_string())
-                .to(to_ms.to_string()),
-        )
-        .compute(vec![LogsCompute::new(LogsAggregationFunction::COUNT)]);
+/// `--storage` value that searches every tier at once.
+const ALL_TIERS_PARALLEL: &str = "all-parallel";
 
-    let resp = api
-        .aggregate_logs(body)
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to aggregate logs: {:?}", e))?;
+/// Resolves `--storage` into the tiers to search: the API default (None)
+/// when omitted, one tier, or all of them for `all-parallel`.
+fn storage_tiers(storage: Option<&str>) -> Result<Vec<Option<&'static str>>> {
+    let Some(storage) = storage.map(str::trim) else {
+        return Ok(vec![None]);
+    };
+    if storage == ALL_TIERS_PARALLEL {
+        return Ok(LOG_STORAGE_TIERS.iter().map(|t| Some(*t)).collect());
+    }
+    match LOG_STORAGE_TIERS.iter().find(|t| **t == storage) {
+        Some(tier) => Ok(vec![Some(*tier)]),
+        None => bail!(
+            "invalid --storage {storage:?}: expected one of {}, {ALL_TIERS_PARALLEL}",
+            LOG_STORAGE_TIERS.join(", ")
+        ),
+    }
+}
 
-    formatter::output(cfg, &resp)?;
-    Ok(())
+#[cfg(not(target_arch = "wasm32"))]
+fn storage_tier(tier: &str) -> LogsStorageTier {
+    match tier {
+        "online-archives" => LogsStorageTier::ONLINE_ARCHIVES,
+        "flex" => LogsStorageTier::FLEX,
+        _ => LogsStorageTier::INDEXES,
+    }
 }
 
-#[cfg(target_arch = "wasm32")]
-pub async fn aggregate(cfg: &Config, query: String, from: String, to: String) -> Result<()> {
-    let from_ms = util::parse_time_to_unix_millis(&from)?;
-    let to_ms = util::parse_time_to_unix_millis(&to)?;
-    let body = serde_json::json!({
-        "filter": {
-            "query": query,
-            "from": from_ms.to_string(),
-            "to": to_ms.to_string()
-        },
-        "compute": [{ "type": "count" }]
+/// Merges per-tier search results into one list, newest first, keeping at
+/// most `limit` logs. Each log gets a `storage_tier` field naming the tier
+/// it came from.
+fn merge_tier_results(
+    results: Vec<(&str, Vec<serde_json::Value>)>,
+    limit: i32,
+) -> Vec<serde_json::Value> {
+    let mut merged: Vec<serde_json::Value> = results
+        .into_iter()
+        .flat_map(|(tier, logs)| {
+            logs.into_iter().map(move |mut log| {
+                if let Some(obj) = log.as_object_mut() {
+                    obj.insert("storage_tier".into(), tier.into());
+                }
+                log
+            })
+        })
+        .collect();
+    // RFC3339 timestamps in UTC sort chronologically as strings.
+    merged.sort_by(|a, b| {
+        let ts =
+            |log: &serde_json::Value| log["attributes"]["timestamp"].as_str().map(String::from);
+        ts(b).cmp(&ts(a))
     });
-    let data = crate::api::post(cfg, "/api/v2/logs/analytics/aggregate", &body).await?;
-    crate::formatter::output(cfg, &data)
+    merged.truncate(limit.max(0) as usize);
+    merged
 }
 
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn archives_list(cfg: &Config) -> Result<()> {
-    if !cfg.has_api_keys() {
-        bail!(
-            "logs archives list requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
-             This endpoint does not support bearer token auth."
-        );
-    }
-
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsArchivesAPI::with_config(dd_cfg);
-
-    let resp = api
-        .list_logs_archives()
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to list log archives: {:?}", e))?;
-
-    formatter::output(cfg, &resp)?;
-    Ok(())
+async fn fetch_logs(
+    api: &LogsAPI,
+    query: &str,
+    from_ms: i64,
+    to_ms: i64,
+    limit: i32,
+    tier: Option<&str>,
+) -> Result<LogsListResponse> {
+    fetch_logs_page(
+        api,
+        logs_filter(query, from_ms, to_ms, tier),
+        limit,
+        LogsSort::TIMESTAMP_DESCENDING,
+        None,
+    )
+    .await
 }
 
-#[cfg(target_arch = "wasm32")]
-pub async fn archives_list(cfg: &Config) -> Result<()> {
-    let data = crate::api::get(cfg, "/api/v2/logs/config/archives", &[]).await?;
-    crate::formatter::output(cfg, &data)
+/// Filter for the logs matching `query` in a time window, searched in
+/// `tier` when given rather than the API default.
+#[cfg(not(target_arch = "wasm32"))]
+fn logs_filter(query: &str, from_ms: i64, to_ms: i64, tier: Option<&str>) -> LogsQueryFilter {
+    let filter = LogsQueryFilter::new()
+        .query(query.to_string())
+        .from(from_ms.to_string())
+        .to(to_ms.to_string());
+    match tier {
+        Some(tier) => filter.storage_tier(storage_tier(tier)),
+        None => filter,
+    }
 }
 
+/// Fetches one page of logs, continuing from `cursor` (the previous page's
+/// `meta.page.after`) when given.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn archives_get(cfg: &Config, archive_id: &str) -> Result<()> {
-    if !cfg.has_api_keys() {
-        bail!(
-            "logs archives get requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
-             This endpoint does not support bearer token auth."
-        );
+async fn fetch_logs_page(
+    api: &LogsAPI,
+    filter: LogsQueryFilter,
+    limit: i32,
+    sort: LogsSort,
+    cursor: Option<String>,
+) -> Result<LogsListResponse> {
+    let mut page = LogsListRequestPage::new().limit(limit);
+    if let Some(cursor) = cursor {
+        page = page.cursor(cursor);
     }
+    let body = LogsListRequest::new().filter(filter).page(page).sort(sort);
 
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsArchivesAPI::with_config(dd_cfg);
+    let params = ListLogsOptionalParams::default().body(body);
 
-    let resp = api
-        .get_logs_archive(archive_id.to_string())
+    api.list_logs(params)
         .await
-        .map_err(|e| anyhow::anyhow!("failed to get log archive: {:?}", e))?;
+        .map_err(|e| anyhow::anyhow!("failed to search logs: {:?}", e))
+}
 
-    formatter::output(cfg, &resp)?;
-    Ok(())
+/// Logs requested per page by `--export`, the API maximum.
+const EXPORT_PAGE_SIZE: i32 = 1000;
+
+/// File formats a log export can be written in.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum ExportFormat {
+    /// One JSON log per line.
+    Ndjson,
+    /// The `EXPORT_CSV_COLUMNS` of each log, with a header row.
+    Csv,
 }
 
-#[cfg(target_arch = "wasm32")]
-pub async fn archives_get(cfg: &Config, archive_id: &str) -> Result<()> {
-    let path = format!("/api/v2/logs/config/archives/{archive_id}");
-    let data = crate::api::get(cfg, &path, &[]).await?;
-    crate::formatter::output(cfg, &data)
+impl ExportFormat {
+    /// Parses `--format`; without one, a `.csv` file gets CSV and anything
+    /// else NDJSON.
+    pub fn resolve(format: Option<&str>, path: &str) -> Result<Self> {
+        match format.map(|f| f.trim().to_lowercase()).as_deref() {
+            Some("ndjson") | Some("jsonl") => Ok(ExportFormat::Ndjson),
+            Some("csv") => Ok(ExportFormat::Csv),
+            Some(other) => {
+                bail!("invalid export format {other:?}: expected one of ndjson, csv")
+            }
+            None if path.to_lowercase().ends_with(".csv") => Ok(ExportFormat::Csv),
+            None => Ok(ExportFormat::Ndjson),
+        }
+    }
 }
 
+/// Columns of a CSV export, as (header, JSON pointer into a log). Log
+/// attributes beyond these stay available in NDJSON exports.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn archives_delete(cfg: &Config, archive_id: &str) -> Result<()> {
-    if !cfg.has_api_keys() {
-        bail!(
-            "logs archives delete requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
-             This endpoint does not support bearer token auth."
-        );
-    }
+const EXPORT_CSV_COLUMNS: &[(&str, &str)] = &[
+    ("timestamp", "/attributes/timestamp"),
+    ("status", "/attributes/status"),
+    ("service", "/attributes/service"),
+    ("host", "/attributes/host"),
+    ("message", "/attributes/message"),
+    ("tags", "/attributes/tags"),
+    ("id", "/id"),
+];
 
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsArchivesAPI::with_config(dd_cfg);
+#[cfg(not(target_arch = "wasm32"))]
+fn export_csv_header() -> String {
+    let headers: Vec<&str> = EXPORT_CSV_COLUMNS.iter().map(|(h, _)| *h).collect();
+    formatter::csv_line(&headers)
+}
 
-    api.delete_logs_archive(archive_id.to_string())
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to delete log archive: {:?}", e))?;
+#[cfg(not(target_arch = "wasm32"))]
+fn export_csv_row(log: &serde_json::Value) -> String {
+    let cells: Vec<String> = EXPORT_CSV_COLUMNS
+        .iter()
+        .map(|(_, pointer)| match log.pointer(pointer) {
+            Some(serde_json::Value::Array(items)) => items
+                .iter()
+                .map(|item| formatter::csv_value(Some(item)))
+                .collect::<Vec<_>>()
+                .join(" "),
+            value => formatter::csv_value(value),
+        })
+        .collect();
+    formatter::csv_line(&cells)
+}
 
-    println!("Log archive {archive_id} deleted.");
+/// Exports every log matching `query` to `path` (one log per line).
+/// Pages are written as they arrive, so memory use stays at one page however
+/// many logs match; progress goes to stderr.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn export(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    path: &str,
+    format: ExportFormat,
+) -> Result<()> {
+    // Check everything that can fail up front so a bad run leaves no file
+    let (from_ms, to_ms) = export_window(cfg, &from, &to)?;
+    let file =
+        std::fs::File::create(path).map_err(|e| anyhow::anyhow!("failed to create {path}: {e}"))?;
+    let mut out = std::io::BufWriter::new(file);
+    let total = write_export(cfg, &query, from_ms, to_ms, format, &mut out).await?;
+    formatter::print_status(&format!("Exported {total} logs to {path}."));
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn archives_delete(cfg: &Config, archive_id: &str) -> Result<()> {
-    let path = format!("/api/v2/logs/config/archives/{archive_id}");
-    crate::api::delete(cfg, &path).await?;
-    println!("Log archive {archive_id} deleted.");
-    Ok(())
+pub async fn export(
+    _cfg: &Config,
+    _query: String,
+    _from: String,
+    _to: String,
+    _path: &str,
+    _format: ExportFormat,
+) -> Result<()> {
+    bail!("log export is not available in WASM builds.")
 }
 
+/// Pages through the logs matching `query`, writing each page to `sink` in
+/// `format` before the next is requested. Returns the number of logs written.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn custom_destinations_list(cfg: &Config) -> Result<()> {
+pub async fn export_to<W: std::io::Write>(
+    cfg: &Config,
+    query: &str,
+    from: &str,
+    to: &str,
+    format: ExportFormat,
+    sink: &mut W,
+) -> Result<u64> {
+    let (from_ms, to_ms) = export_window(cfg, from, to)?;
+    write_export(cfg, query, from_ms, to_ms, format, sink).await
+}
+
+/// Checks an export can run, returning its `(from, to)` window in
+/// milliseconds.
+#[cfg(not(target_arch = "wasm32"))]
+fn export_window(cfg: &Config, from: &str, to: &str) -> Result<(i64, i64)> {
     if !cfg.has_api_keys() {
         bail!(
-            "logs custom-destinations list requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+            "logs search requires API+APP key authentication (DD_API_KEY + DD_APP_KEY).\n\
              This endpoint does not support bearer token auth."
         );
     }
-
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsCustomDestinationsAPI::with_config(dd_cfg);
-
-    let resp = api
-        .list_logs_custom_destinations()
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to list custom destinations: {:?}", e))?;
-
-    formatter::output(cfg, &resp)?;
-    Ok(())
+    Ok((
+        util::parse_time_to_unix_millis(from)?,
+        util::parse_time_to_unix_millis(to)?,
+    ))
 }
 
-#[cfg(target_arch = "wasm32")]
-pub async fn custom_destinations_list(cfg: &Config) -> Result<()> {
-    let data = crate::api::get(cfg, "/api/v2/logs/config/custom_destinations", &[]).await?;
-    crate::formatter::output(cfg, &data)
+#[cfg(not(target_arch = "wasm32"))]
+async fn write_export<W: std::io::Write>(
+    cfg: &Config,
+    query: &str,
+    from_ms: i64,
+    to_ms: i64,
+    format: ExportFormat,
+    sink: &mut W,
+) -> Result<u64> {
+    let api = LogsAPI::with_config(client::make_dd_config(cfg));
+    if format == ExportFormat::Csv {
+        sink.write_all(export_csv_header().as_bytes())?;
+    }
+    let mut total = 0u64;
+    let mut cursor = None;
+    for page in 1.. {
+        let resp = fetch_logs_page(
+            &api,
+            logs_filter(query, from_ms, to_ms, None),
+            EXPORT_PAGE_SIZE,
+            LogsSort::TIMESTAMP_DESCENDING,
+            cursor,
+        )
+        .await?;
+        for log in resp.data.unwrap_or_default() {
+            match format {
+                ExportFormat::Ndjson => {
+                    serde_json::to_writer(&mut *sink, &log)?;
+                    sink.write_all(b"\n")?;
+                }
+                ExportFormat::Csv => {
+                    sink.write_all(export_csv_row(&serde_json::to_value(&log)?).as_bytes())?;
+                }
+            }
+            total += 1;
+        }
+        sink.flush()?;
+        formatter::print_status(&format!("Exported {total} logs ({page} pages)..."));
+        cursor = resp.meta.and_then(|m| m.page).and_then(|p| p.after);
+        if cursor.is_none() {
+            break;
+        }
+    }
+    Ok(total)
 }
 
+/// Prints the newest `limit` logs matching `query` since `from`, oldest
+/// first. With `follow`, keeps polling every `interval` and prints logs as
+/// they arrive until interrupted with Ctrl-C.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn custom_destinations_get(cfg: &Config, destination_id: &str) -> Result<()> {
+pub async fn tail(
+    cfg: &Config,
+    query: String,
+    from: String,
+    limit: i32,
+    follow: bool,
+    interval: &str,
+) -> Result<()> {
     if !cfg.has_api_keys() {
         bail!(
-            "logs custom-destinations get requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+            "logs tail requires API+APP key authentication (DD_API_KEY + DD_APP_KEY).\n\
              This endpoint does not support bearer token auth."
         );
     }
+    let interval_ms = util::parse_duration_millis(interval)?;
+    if interval_ms < 1000 {
+        bail!("--interval must be at least 1s");
+    }
+    let interval = std::time::Duration::from_millis(interval_ms as u64);
+    let api = LogsAPI::with_config(client::make_dd_config(cfg));
 
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsCustomDestinationsAPI::with_config(dd_cfg);
-
-    let resp = api
-        .get_logs_custom_destination(destination_id.to_string())
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to get custom destination: {:?}", e))?;
+    let from_ms = util::parse_time_to_unix_millis(&from)?;
+    let now_ms = chrono::Utc::now().timestamp_millis();
+    let mut cursor = StreamCursor::starting_at(from_ms);
+    let latest = fetch_logs(&api, &query, from_ms, now_ms, limit, None).await?;
+    let logs = cursor.take_new(&log_values(&latest)?);
+    if !logs.is_empty() {
+        formatter::output(cfg, &logs)?;
+    }
+    if !follow {
+        return Ok(());
+    }
 
-    formatter::output(cfg, &resp)?;
-    Ok(())
+    formatter::print_status(&format!(
+        "Following logs matching {query:?} every {}s (Ctrl-C to stop)...",
+        interval.as_secs()
+    ));
+    loop {
+        tokio::select! {
+            _ = tokio::signal::ctrl_c() => return Ok(()),
+            _ = tokio::time::sleep(interval) => {}
+        }
+        let logs = tail_poll(cfg, &query, &mut cursor).await?;
+        if !logs.is_empty() {
+            formatter::output(cfg, &logs)?;
+        }
+    }
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn custom_destinations_get(cfg: &Config, destination_id: &str) -> Result<()> {
-    let path = format!("/api/v2/logs/config/custom_destinations/{destination_id}");
-    let data = crate::api::get(cfg, &path, &[]).await?;
-    crate::formatter::output(cfg, &data)
+pub async fn tail(
+    _cfg: &Config,
+    _query: String,
+    _from: String,
+    _limit: i32,
+    _follow: bool,
+    _interval: &str,
+) -> Result<()> {
+    bail!("logs tail is not available in WASM builds.")
 }
 
+/// Runs one `logs tail --follow` poll: pages through the logs from the
+/// cursor up to now, oldest first, and returns the ones not printed yet.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn metrics_list(cfg: &Config) -> Result<()> {
-    if !cfg.has_api_keys() {
-        bail!(
-            "logs metrics list requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
-             This endpoint does not support bearer token auth."
-        );
+pub async fn tail_poll(
+    cfg: &Config,
+    query: &str,
+    cursor: &mut StreamCursor,
+) -> Result<Vec<serde_json::Value>> {
+    let api = LogsAPI::with_config(client::make_dd_config(cfg));
+    let to_ms = chrono::Utc::now().timestamp_millis();
+    let mut logs = Vec::new();
+    let mut page_cursor = None;
+    loop {
+        let resp = fetch_logs_page(
+            &api,
+            logs_filter(query, cursor.last_millis(), to_ms, None),
+            EXPORT_PAGE_SIZE,
+            LogsSort::TIMESTAMP_ASCENDING,
+            page_cursor,
+        )
+        .await?;
+        logs.extend(log_values(&resp)?);
+        page_cursor = resp.meta.and_then(|m| m.page).and_then(|p| p.after);
+        if page_cursor.is_none() {
+            break;
+        }
     }
-
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsMetricsAPI::with_config(dd_cfg);
-
-    let resp = api
-        .list_logs_metrics()
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to list log-based metrics: {:?}", e))?;
-
-    formatter::output(cfg, &resp)?;
-    Ok(())
+    Ok(cursor.take_new(&logs))
 }
 
-#[cfg(target_arch = "wasm32")]
-pub async fn metrics_list(cfg: &Config) -> Result<()> {
-    let data = crate::api::get(cfg, "/api/v2/logs/config/metrics", &[]).await?;
-    crate::formatter::output(cfg, &data)
+#[cfg(not(target_arch = "wasm32"))]
+fn log_values(resp: &LogsListResponse) -> Result<Vec<serde_json::Value>> {
+    Ok(serde_json::to_value(&resp.data)?
+        .as_array()
+        .cloned()
+        .unwrap_or_default())
 }
 
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn metrics_get(cfg: &Config, metric_id: &str) -> Result<()> {
-    if !cfg.has_api_keys() {
-        bail!(
-            "logs metrics get requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
-             This endpoint does not support bearer token auth."
+fn format_millis(ms: i64) -> String {
+    chrono::DateTime::from_timestamp_millis(ms)
+        .map(|dt| dt.to_rfc3339())
+        .unwrap_or_else(|| ms.to_string())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn search(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    limit: i32,
+    chunk: Option<String>,
+    storage: Option<String>,
+) -> Result<()> {
+    let tiers = storage_tiers(storage.as_deref())?;
+    let from_ms = util::parse_time_to_unix_millis(&from)?;
+    let to_ms = util::parse_time_to_unix_millis(&to)?;
+    if let [tier] = tiers[..] {
+        if chunk.is_none() {
+            let data = fetch_logs(cfg, &query, from_ms, to_ms, limit, tier).await?;
+            return crate::formatter::output(cfg, &data);
+        }
+        let logs = fetch_range(cfg, &query, from_ms, to_ms, limit, chunk, tier).await?;
+        return crate::formatter::output(cfg, &serde_json::json!({ "data": logs }));
+    }
+
+    // WASM builds have no task runtime to fan out on; tiers run in turn.
+    let mut results = Vec::new();
+    let mut first_err = None;
+    for tier in tiers.into_iter().flatten() {
+        match fetch_range(
+            cfg,
+            &query,
+            from_ms,
+            to_ms,
+            limit,
+            chunk.clone(),
+            Some(tier),
+        )
+        .await
+        {
+            Ok(logs) => results.push((tier, logs)),
+            Err(e) => {
+                formatter::print_status(&format!("warning: {tier} search failed: {e}"));
+                first_err = first_err.or(Some(e));
+            }
+        }
+    }
+    if results.is_empty() {
+        if let Some(e) = first_err {
+            return Err(e);
+        }
+    }
+    let merged = merge_tier_results(results, limit);
+    crate::formatter::output(cfg, &serde_json::json!({ "data": merged }))
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn fetch_range(
+    cfg: &Config,
+    query: &str,
+    from_ms: i64,
+    to_ms: i64,
+    limit: i32,
+    chunk: Option<String>,
+    tier: Option<&str>,
+) -> Result<Vec<serde_json::Value>> {
+    let windows = match chunk {
+        Some(chunk) => {
+            util::split_time_range(from_ms, to_ms, util::parse_duration_millis(&chunk)?)?
+        }
+        None => vec![(from_ms, to_ms)],
+    };
+    let mut merged: Vec<serde_json::Value> = Vec::new();
+    for (start, end) in windows.into_iter().rev() {
+        let end = window_end(end, to_ms);
+        let mut cursor: Option<String> = None;
+        loop {
+            let remaining = limit - merged.len() as i32;
+            if remaining <= 0 {
+                return Ok(merged);
+            }
+            let mut body = serde_json::json!({
+                "filter": {
+                    "query": query,
+                    "from": start.to_string(),
+                    "to": end.to_string()
+                },
+                "page": { "limit": remaining.min(EXPORT_PAGE_SIZE) },
+                "sort": "-timestamp"
+            });
+            if let Some(tier) = tier {
+                body["filter"]["storage_tier"] = tier.into();
+            }
+            if let Some(cursor) = cursor {
+                body["page"]["cursor"] = cursor.into();
+            }
+            let data = crate::api::post(cfg, "/api/v2/logs/events/search", &body).await?;
+            let items = data["data"].as_array().cloned().unwrap_or_default();
+            if items.is_empty() {
+                break;
+            }
+            merged.extend(items);
+            cursor = data["meta"]["page"]["after"].as_str().map(String::from);
+            if cursor.is_none() {
+                break;
+            }
+        }
+    }
+    Ok(merged)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn fetch_logs(
+    cfg: &Config,
+    query: &str,
+    from_ms: i64,
+    to_ms: i64,
+    limit: i32,
+    tier: Option<&str>,
+) -> Result<serde_json::Value> {
+    let mut body = serde_json::json!({
+        "filter": {
+            "query": query,
+            "from": from_ms.to_string(),
+            "to": to_ms.to_string()
+        },
+        "page": { "limit": limit },
+        "sort": "-timestamp"
+    });
+    if let Some(tier) = tier {
+        body["filter"]["storage_tier"] = tier.into();
+    }
+    crate::api::post(cfg, "/api/v2/logs/events/search", &body).await
+}
+
+/// Alias for `search` with the same interface.
+pub async fn list(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    limit: i32,
+    storage: Option<String>,
+) -> Result<()> {
+    search(cfg, query, from, to, limit, None, storage).await
+}
+
+/// Alias for `search` with the same interface.
+pub async fn query(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    limit: i32,
+    storage: Option<String>,
+) -> Result<()> {
+    search(cfg, query, from, to, limit, None, storage).await
+}
+
+/// `--explain` plan for `logs search` (and its `list`/`query` aliases),
+/// following `search` (one page or `--chunk` windows) and `export`.
+pub fn search_plan(
+    query: &str,
+    from: &str,
+    to: &str,
+    limit: i32,
+    chunk: Option<&str>,
+    export: Option<&str>,
+    storage: Option<&str>,
+) -> Result<Plan> {
+    let tiers = storage_tiers(storage)?;
+    let from_ms = util::parse_time_to_unix_millis(from)?;
+    let to_ms = util::parse_time_to_unix_millis(to)?;
+    let mut plan = Plan::new("POST /api/v2/logs/events/search");
+    plan.set_window(from_ms, to_ms);
+    match (export, chunk) {
+        (Some(path), _) => {
+            plan.pagination = Some(format!(
+                "cursor pages of {EXPORT_PAGE_SIZE} logs until none are left, written to {path}"
+            ));
+            plan.requests = Some(RequestCount::Unbounded(format!(
+                "one per {EXPORT_PAGE_SIZE} matching logs"
+            )));
+        }
+        (None, Some(chunk)) => {
+            let windows =
+                util::split_time_range(from_ms, to_ms, util::parse_duration_millis(chunk)?)?;
+            plan.pagination = Some(format!(
+                "{} windows of {chunk}, newest first, each paged until exhausted, \
+                 stopping once {limit} logs are collected",
+                windows.len()
+            ));
+            // A window needs another page only after a full one
+            let extra_pages = (limit.max(1) as usize - 1) / EXPORT_PAGE_SIZE as usize;
+            plan.requests = Some(RequestCount::AtMost(windows.len() + extra_pages));
+        }
+        (None, None) => {
+            plan.pagination = Some(format!("none; one page of up to {limit} logs"));
+            plan.requests = Some(RequestCount::Exactly(1));
+        }
+    }
+    match tiers[..] {
+        [None] => {}
+        [Some(tier)] => plan.notes.push(format!("searches the {tier} storage tier")),
+        _ => {
+            plan.requests = match plan.requests {
+                Some(RequestCount::Exactly(n)) => Some(RequestCount::Exactly(n * tiers.len())),
+                Some(RequestCount::AtMost(n)) => Some(RequestCount::AtMost(n * tiers.len())),
+                other => other,
+            };
+            plan.notes.push(format!(
+                "searches {} concurrently, then merges newest first up to {limit} logs",
+                LOG_STORAGE_TIERS.join(", ")
+            ));
+        }
+    }
+    plan.notes
+        .push("needs API + application keys; bearer tokens are not accepted".into());
+    if query.trim().is_empty() {
+        plan.notes.push("empty --query matches all logs".into());
+    }
+    Ok(plan)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn aggregate(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    compute: Vec<String>,
+    percentiles: Option<String>,
+    field: Option<String>,
+    interval: Option<String>,
+    group_by: Vec<String>,
+    limit: i32,
+) -> Result<()> {
+    let computes = aggregate_computes(&compute, percentiles.as_deref(), field.as_deref())?;
+    let interval = aggregate_interval(interval.as_deref())?;
+    let group_by = aggregate_group_by(&group_by)?;
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs aggregate requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
         );
     }
 
     let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsMetricsAPI::with_config(dd_cfg);
+    let api = LogsAPI::with_config(dd_cfg);
+
+    let from_ms = util::parse_time_to_unix_millis(&from)?;
+    let to_ms = util::parse_time_to_unix_millis(&to)?;
+    let body = aggregate_request(
+        query,
+        from_ms,
+        to_ms,
+        computes,
+        interval.as_deref(),
+        &group_by,
+        limit,
+    )?;
 
     let resp = api
-        .get_logs_metric(metric_id.to_string())
+        .aggregate_logs(body)
         .await
-        .map_err(|e| anyhow::anyhow!("failed to get log-based metric: {:?}", e))?;
+        .map_err(|e| anyhow::anyhow!("failed to aggregate logs: {:?}", e))?;
 
     formatter::output(cfg, &resp)?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn metrics_get(cfg: &Config, metric_id: &str) -> Result<()> {
-    let path = format!("/api/v2/logs/config/metrics/{metric_id}");
-    let data = crate::api::get(cfg, &path, &[]).await?;
+pub async fn aggregate(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    compute: Vec<String>,
+    percentiles: Option<String>,
+    field: Option<String>,
+    interval: Option<String>,
+    group_by: Vec<String>,
+    limit: i32,
+) -> Result<()> {
+    let computes = aggregate_computes(&compute, percentiles.as_deref(), field.as_deref())?;
+    let interval = aggregate_interval(interval.as_deref())?;
+    let group_by = aggregate_group_by(&group_by)?;
+    let from_ms = util::parse_time_to_unix_millis(&from)?;
+    let to_ms = util::parse_time_to_unix_millis(&to)?;
+    let body = aggregate_request(
+        query,
+        from_ms,
+        to_ms,
+        computes,
+        interval.as_deref(),
+        &group_by,
+        limit,
+    )?;
+    let data = crate::api::post(cfg, "/api/v2/logs/analytics/aggregate", &body).await?;
     crate::formatter::output(cfg, &data)
 }
 
+/// Percentiles `--percentiles` accepts, with the aggregation each maps to;
+/// the logs API computes the 50th percentile as `median`.
+const LOG_PERCENTILES: &[(u32, &str)] = &[
+    (50, "median"),
+    (75, "pc75"),
+    (90, "pc90"),
+    (95, "pc95"),
+    (98, "pc98"),
+    (99, "pc99"),
+];
+
+/// The computes for `logs aggregate`: each `--compute` in order, then one per
+/// `--percentiles` value over `--field`. A plain count when neither is given.
+fn aggregate_computes(
+    compute: &[String],
+    percentiles: Option<&str>,
+    field: Option<&str>,
+) -> Result<Vec<(String, Option<String>)>> {
+    let mut computes = compute
+        .iter()
+        .map(|c| crate::commands::traces::parse_compute_raw(c))
+        .collect::<Result<Vec<_>>>()?;
+    match (percentiles, field.map(str::trim)) {
+        (Some(_), None | Some("")) => {
+            bail!("--percentiles requires --field (e.g. --field @duration)")
+        }
+        (None, Some(_)) => bail!("--field is only used with --percentiles"),
+        (Some(list), Some(field)) => {
+            for value in list.split(',').map(str::trim) {
+                let pct: u32 = value
+                    .parse()
+                    .map_err(|_| anyhow::anyhow!("invalid percentile value: {value:?}"))?;
+                let Some((_, func)) = LOG_PERCENTILES.iter().find(|(p, _)| *p == pct) else {
+                    bail!("unsupported percentile: {pct} (supported: 50, 75, 90, 95, 98, 99)");
+                };
+                computes.push((func.to_string(), Some(field.to_string())));
+            }
+        }
+        (None, None) => {}
+    }
+    if computes.is_empty() {
+        computes.push(("count".into(), None));
+    }
+    Ok(computes)
+}
+
+/// Validates `--interval` for `logs aggregate` and `traces aggregate`, the
+/// bucket size that turns every compute into a timeseries. Returned trimmed,
+/// as the API expects it.
+pub(crate) fn aggregate_interval(interval: Option<&str>) -> Result<Option<String>> {
+    let Some(interval) = interval.map(str::trim) else {
+        return Ok(None);
+    };
+    if util::parse_duration_millis(interval)? < 1000 {
+        bail!("--interval must be at least 1s");
+    }
+    Ok(Some(interval.to_string()))
+}
+
+/// Facets for `logs aggregate` and `traces aggregate`, one per `--group-by`
+/// in the order given, which is the order the API nests the groups in.
+pub(crate) fn aggregate_group_by(group_by: &[String]) -> Result<Vec<String>> {
+    let mut facets: Vec<String> = Vec::with_capacity(group_by.len());
+    for facet in group_by.iter().map(|f| f.trim()) {
+        if facet.is_empty() {
+            bail!("--group-by cannot be empty");
+        }
+        if facets.iter().any(|f| f == facet) {
+            bail!("--group-by {facet:?} given more than once");
+        }
+        facets.push(facet.to_string());
+    }
+    Ok(facets)
+}
+
+/// Aggregate request running `computes` over the logs matching `query` in a
+/// time window, split by each of `group_by` (top `limit` values per facet).
+/// With an `interval`, each compute is a timeseries bucketed by it instead of
+/// a single total.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn metrics_delete(cfg: &Config, metric_id: &str) -> Result<()> {
+fn aggregate_request(
+    query: String,
+    from_ms: i64,
+    to_ms: i64,
+    computes: Vec<(String, Option<String>)>,
+    interval: Option<&str>,
+    group_by: &[String],
+    limit: i32,
+) -> Result<LogsAggregateRequest> {
+    let computes = computes
+        .into_iter()
+        .map(|(func, metric)| {
+            let mut compute = LogsCompute::new(aggregation_function(&func)?);
+            if let Some(m) = metric {
+                compute = compute.metric(m);
+            }
+            if let Some(i) = interval {
+                compute = compute
+                    .type_(LogsComputeType::TIMESERIES)
+                    .interval(i.to_string());
+            }
+            Ok(compute)
+        })
+        .collect::<Result<Vec<_>>>()?;
+    let mut request = LogsAggregateRequest::new()
+        .filter(
+            LogsQueryFilter::new()
+                .query(query)
+                .from(from_ms.to_string())
+                .to(to_ms.to_string()),
+        )
+        .compute(computes);
+    if !group_by.is_empty() {
+        request = request.group_by(
+            group_by
+                .iter()
+                .map(|facet| LogsGroupBy::new(facet.clone()).limit(limit as i64))
+                .collect(),
+        );
+    }
+    Ok(request)
+}
+
+#[cfg(target_arch = "wasm32")]
+fn aggregate_request(
+    query: String,
+    from_ms: i64,
+    to_ms: i64,
+    computes: Vec<(String, Option<String>)>,
+    interval: Option<&str>,
+    group_by: &[String],
+    limit: i32,
+) -> Result<serde_json::Value> {
+    let computes: Vec<serde_json::Value> = computes
+        .into_iter()
+        .map(|(func, metric)| {
+            let mut compute = serde_json::json!({ "aggregation": func });
+            if let Some(m) = metric {
+                compute["metric"] = serde_json::Value::String(m);
+            }
+            if let Some(i) = interval {
+                compute["type"] = "timeseries".into();
+                compute["interval"] = i.into();
+            }
+            compute
+        })
+        .collect();
+    let mut body = serde_json::json!({
+        "filter": {
+            "query": query,
+            "from": from_ms.to_string(),
+            "to": to_ms.to_string()
+        },
+        "compute": computes
+    });
+    if !group_by.is_empty() {
+        body["group_by"] = group_by
+            .iter()
+            .map(|facet| serde_json::json!({ "facet": facet, "limit": limit }))
+            .collect();
+    }
+    Ok(body)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+fn aggregation_function(func: &str) -> Result<LogsAggregationFunction> {
+    Ok(match func {
+        "count" => LogsAggregationFunction::COUNT,
+        "avg" => LogsAggregationFunction::AVG,
+        "sum" => LogsAggregationFunction::SUM,
+        "min" => LogsAggregationFunction::MIN,
+        "max" => LogsAggregationFunction::MAX,
+        "median" => LogsAggregationFunction::MEDIAN,
+        "cardinality" => LogsAggregationFunction::CARDINALITY,
+        "pc75" => LogsAggregationFunction::PERCENTILE_75,
+        "pc90" => LogsAggregationFunction::PERCENTILE_90,
+        "pc95" => LogsAggregationFunction::PERCENTILE_95,
+        "pc98" => LogsAggregationFunction::PERCENTILE_98,
+        "pc99" => LogsAggregationFunction::PERCENTILE_99,
+        _ => bail!("unknown aggregation function: {func}"),
+    })
+}
+
+/// Aggregate request counting the logs matching `query` in a time window.
+#[cfg(not(target_arch = "wasm32"))]
+fn count_aggregate_request(query: String, from_ms: i64, to_ms: i64) -> LogsAggregateRequest {
+    LogsAggregateRequest::new()
+        .filter(
+            LogsQueryFilter::new()
+                .query(query)
+                .from(from_ms.to_string())
+                .to(to_ms.to_string()),
+        )
+        .compute(vec![LogsCompute::new(LogsAggregationFunction::COUNT)])
+}
+
+#[cfg(target_arch = "wasm32")]
+fn count_aggregate_request(query: String, from_ms: i64, to_ms: i64) -> serde_json::Value {
+    serde_json::json!({
+        "filter": {
+            "query": query,
+            "from": from_ms.to_string(),
+            "to": to_ms.to_string()
+        },
+        "compute": [{ "type": "count" }]
+    })
+}
+
+/// Counts the logs matching `query` from `window` ago until now.
+#[cfg(not(target_arch = "wasm32"))]
+async fn count_matching(cfg: &Config, query: &str, window: &str) -> Result<i64> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsAPI::with_config(dd_cfg);
+    let from_ms = util::parse_time_to_unix_millis(window)?;
+    let to_ms = util::parse_time_to_unix_millis("now")?;
+
+    let resp = api
+        .aggregate_logs(count_aggregate_request(query.to_string(), from_ms, to_ms))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to aggregate logs: {:?}", e))?;
+    Ok(util::aggregate_count(&serde_json::to_value(&resp)?))
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn count_matching(cfg: &Config, query: &str, window: &str) -> Result<i64> {
+    let from_ms = util::parse_time_to_unix_millis(window)?;
+    let to_ms = util::parse_time_to_unix_millis("now")?;
+    let body = count_aggregate_request(query.to_string(), from_ms, to_ms);
+    let data = crate::api::post(cfg, "/api/v2/logs/analytics/aggregate", &body).await?;
+    Ok(util::aggregate_count(&data))
+}
+
+/// The query a log-based metric counts; metrics without a filter match all logs.
+fn metric_filter_query(metric: &serde_json::Value) -> String {
+    metric
+        .pointer("/data/attributes/filter/query")
+        .and_then(|q| q.as_str())
+        .filter(|q| !q.is_empty())
+        .unwrap_or("*")
+        .to_string()
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn archives_list(cfg: &Config) -> Result<()> {
     if !cfg.has_api_keys() {
         bail!(
-            "logs metrics delete requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+            "logs archives list requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
              This endpoint does not support bearer token auth."
         );
     }
 
     let dd_cfg = client::make_dd_config(cfg);
-    let api = LogsMetricsAPI::with_config(dd_cfg);
+    let api = LogsArchivesAPI::with_config(dd_cfg);
 
-    api.delete_logs_metric(metric_id.to_string())
+    let resp = api
+        .list_logs_archives()
         .await
-        .map_err(|e| anyhow::anyhow!("failed to delete log-based metric: {:?}", e))?;
+        .map_err(|e| anyhow::anyhow!("failed to list log archives: {:?}", e))?;
 
-    println!("Log-based metric {metric_id} deleted.");
+    formatter::output(cfg, &resp)?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn metrics_delete(cfg: &Config, metric_id: &str) -> Result<()> {
-    let path = format!("/api/v2/logs/config/metrics/{metric_id}");
-    crate::api::delete(cfg, &path).await?;
-    println!("Log-based metric {metric_id} deleted.");
-    Ok(())
+pub async fn archives_list(cfg: &Config) -> Result<()> {
+    let data = crate::api::get(cfg, "/api/v2/logs/config/archives", &[]).await?;
+    crate::formatter::output(cfg, &data)
 }
 
-// ---------------------------------------------------------------------------
-// Restriction Queries (raw HTTP - not available in typed client)
-// ---------------------------------------------------------------------------
-
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn restriction_queries_list(cfg: &Config) -> Result<()> {
-    let data = client::raw_get(cfg, "/api/v2/logs/config/restriction_queries").await?;
-    formatter::output(cfg, &data)
+pub async fn archives_get(cfg: &Config, archive_id: &str) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs archives get requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsArchivesAPI::with_config(dd_cfg);
+
+    let resp = api
+        .get_logs_archive(archive_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log archive: {:?}", e))?;
+
+    formatter::output(cfg, &resp)?;
+    Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn restriction_queries_list(cfg: &Config) -> Result<()> {
-    let data = crate::api::get(cfg, "/api/v2/logs/config/restriction_queries", &[]).await?;
+pub async fn archives_get(cfg: &Config, archive_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/archives/{archive_id}");
+    let data = crate::api::get(cfg, &path, &[]).await?;
     crate::formatter::output(cfg, &data)
 }
 
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn restriction_queries_get(cfg: &Config, query_id: &str) -> Result<()> {
-    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
-    let data = client::raw_get(cfg, &path).await?;
-    formatter::output(cfg, &data)
+pub async fn archives_delete(cfg: &Config, archive_id: &str) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs archives delete requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsArchivesAPI::with_config(dd_cfg);
+
+    api.delete_logs_archive(archive_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete log archive: {:?}", e))?;
+
+    formatter::print_block(&format!("Log archive {archive_id} deleted.\n"))?;
+    Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn restriction_queries_get(cfg: &Config, query_id: &str) -> Result<()> {
-    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
-    let data = crate::api::get(cfg, &path, &[]).await?;
-    crate::formatter::output(cfg, &data)
+pub async fn archives_delete(cfg: &Config, archive_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/archives/{archive_id}");
+    crate::api::delete(cfg, &path).await?;
+    formatter::print_block(&format!("Log archive {archive_id} deleted.\n"))?;
+    Ok(())
+}
+
+/// Storage types accepted for a log archive's `destination.type`.
+pub const ARCHIVE_DESTINATION_TYPES: &[&str] = &["s3", "gcs", "azure"];
+
+/// Builds a log archive request body from an optional `--file` body (or the
+/// current archive, for flag-only updates) plus flag overrides. Creates
+/// require a name, a query and a destination; the destination's bucket and
+/// cloud integration come from `--file`.
+pub fn archive_body(
+    base: Option<serde_json::Value>,
+    name: Option<&str>,
+    query: Option<&str>,
+    dest_type: Option<&str>,
+    rehydration_tags: Option<&str>,
+    include_tags: Option<bool>,
+) -> Result<serde_json::Value> {
+    let mut body = base.unwrap_or_else(|| serde_json::json!({"data": {}}));
+    let Some(data) = body.get_mut("data").and_then(|d| d.as_object_mut()) else {
+        bail!("log archive body must be a JSON object with a \"data\" object");
+    };
+    // Archives read back from the API carry an id and a read-only state.
+    data.remove("id");
+    data.insert("type".into(), "archives".into());
+    let attrs = data
+        .entry("attributes")
+        .or_insert_with(|| serde_json::json!({}));
+    let Some(attrs) = attrs.as_object_mut() else {
+        bail!("log archive \"attributes\" must be an object");
+    };
+    attrs.remove("state");
+    if let Some(name) = name {
+        attrs.insert("name".into(), name.into());
+    }
+    if let Some(query) = query {
+        attrs.insert("query".into(), query.into());
+    }
+    if let Some(tags) = rehydration_tags {
+        let tags: Vec<&str> = tags
+            .split(',')
+            .map(str::trim)
+            .filter(|t| !t.is_empty())
+            .collect();
+        attrs.insert("rehydration_tags".into(), tags.into());
+    }
+    if let Some(include_tags) = include_tags {
+        attrs.insert("include_tags".into(), include_tags.into());
+    }
+    if let Some(t) = dest_type {
+        let dest = attrs
+            .entry("destination")
+            .or_insert_with(|| serde_json::json!({}));
+        match dest.as_object_mut() {
+            Some(dest) => dest.insert("type".into(), t.to_lowercase().into()),
+            None => bail!("log archive \"destination\" must be an object"),
+        };
+    }
+
+    for field in ["name", "query"] {
+        if !attrs.contains_key(field) {
+            bail!("log archive requires a {field} (--{field} or attributes.{field} in --file)");
+        }
+    }
+    let Some(dest) = attrs.get("destination") else {
+        bail!(
+            "log archive requires a destination with its bucket and integration; \
+             provide it with --file"
+        );
+    };
+    let t = dest["type"].as_str().unwrap_or_default();
+    if !ARCHIVE_DESTINATION_TYPES.contains(&t) {
+        bail!(
+            "invalid archive destination type {t:?} (expected one of: {})",
+            ARCHIVE_DESTINATION_TYPES.join(", ")
+        );
+    }
+    Ok(body)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn archives_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs archives create requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let body = serde_json::from_value(body)
+        .map_err(|e| anyhow::anyhow!("invalid log archive body: {e}"))?;
+    let api = LogsArchivesAPI::with_config(client::make_dd_config(cfg));
+    let resp = api
+        .create_logs_archive(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create log archive: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn archives_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    let data = crate::api::post(cfg, "/api/v2/logs/config/archives", &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Fetches an archive as JSON, the base for flag-only `archives update`s
+/// (the API replaces the whole archive).
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn archives_current(cfg: &Config, archive_id: &str) -> Result<serde_json::Value> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs archives update requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let api = LogsArchivesAPI::with_config(client::make_dd_config(cfg));
+    let resp = api
+        .get_logs_archive(archive_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log archive: {:?}", e))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn archives_current(cfg: &Config, archive_id: &str) -> Result<serde_json::Value> {
+    let path = format!("/api/v2/logs/config/archives/{archive_id}");
+    crate::api::get(cfg, &path, &[]).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn archives_update(
+    cfg: &Config,
+    archive_id: &str,
+    body: serde_json::Value,
+) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs archives update requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let body = serde_json::from_value(body)
+        .map_err(|e| anyhow::anyhow!("invalid log archive body: {e}"))?;
+    let api = LogsArchivesAPI::with_config(client::make_dd_config(cfg));
+    let resp = api
+        .update_logs_archive(archive_id.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update log archive: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn archives_update(
+    cfg: &Config,
+    archive_id: &str,
+    body: serde_json::Value,
+) -> Result<()> {
+    let path = format!("/api/v2/logs/config/archives/{archive_id}");
+    let data = crate::api::put(cfg, &path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+fn archive_order_ids(order: &serde_json::Value) -> Vec<String> {
+    order
+        .pointer("/data/attributes/archive_ids")
+        .and_then(|ids| ids.as_array())
+        .map(|ids| {
+            ids.iter()
+                .filter_map(|id| id.as_str().map(String::from))
+                .collect()
+        })
+        .unwrap_or_default()
+}
+
+fn archive_order_body(ids: Vec<String>) -> serde_json::Value {
+    serde_json::json!({
+        "data": {"type": "archive_order", "attributes": {"archive_ids": ids}}
+    })
+}
+
+/// Prints the archive order; with `requested` ids, first moves them to the
+/// front (logs are archived by the first archive whose query matches).
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn archives_order(cfg: &Config, requested: &[String]) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs archives order requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let api = LogsArchivesAPI::with_config(client::make_dd_config(cfg));
+    let current = api
+        .get_logs_archive_order()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log archive order: {:?}", e))?;
+    if requested.is_empty() {
+        return formatter::output(cfg, &current);
+    }
+    let current = archive_order_ids(&serde_json::to_value(&current)?);
+    let order = move_to_front(&current, requested, "log archive")?;
+    let resp = api
+        .update_logs_archive_order(serde_json::from_value(archive_order_body(order))?)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update log archive order: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn archives_order(cfg: &Config, requested: &[String]) -> Result<()> {
+    let path = "/api/v2/logs/config/archive-order";
+    let current = crate::api::get(cfg, path, &[]).await?;
+    if requested.is_empty() {
+        return crate::formatter::output(cfg, &current);
+    }
+    let order = move_to_front(&archive_order_ids(&current), requested, "log archive")?;
+    let data = crate::api::put(cfg, path, &archive_order_body(order)).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn custom_destinations_list(cfg: &Config) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs custom-destinations list requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsCustomDestinationsAPI::with_config(dd_cfg);
+
+    let resp = api
+        .list_logs_custom_destinations()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list custom destinations: {:?}", e))?;
+
+    formatter::output(cfg, &resp)?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn custom_destinations_list(cfg: &Config) -> Result<()> {
+    let data = crate::api::get(cfg, "/api/v2/logs/config/custom_destinations", &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn custom_destinations_get(cfg: &Config, destination_id: &str) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs custom-destinations get requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsCustomDestinationsAPI::with_config(dd_cfg);
+
+    let resp = api
+        .get_logs_custom_destination(destination_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get custom destination: {:?}", e))?;
+
+    formatter::output(cfg, &resp)?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn custom_destinations_get(cfg: &Config, destination_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/custom_destinations/{destination_id}");
+    let data = crate::api::get(cfg, &path, &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Forwarder types accepted for a custom destination's `forwarder_destination.type`.
+pub const CUSTOM_DESTINATION_TYPES: &[&str] = &["http", "splunk_hec", "elasticsearch"];
+
+/// Flag-built HTTP forwarder for a custom destination: the endpoint plus
+/// either basic auth or a custom header carrying a token.
+#[derive(Debug, Default)]
+pub struct HttpForwarder {
+    pub endpoint: String,
+    pub username: Option<String>,
+    pub password: Option<String>,
+    pub header_name: Option<String>,
+    pub header_value: Option<String>,
+}
+
+/// Sets `attributes.forwarder_destination` of a custom destination body (an
+/// optional `--file` body) to `http`, replacing any forwarder it had.
+pub fn with_http_forwarder(
+    base: Option<serde_json::Value>,
+    http: &HttpForwarder,
+) -> Result<serde_json::Value> {
+    if !http.endpoint.starts_with("https://") {
+        bail!("--endpoint must be an https:// URL");
+    }
+    let auth = match (
+        &http.username,
+        &http.password,
+        &http.header_name,
+        &http.header_value,
+    ) {
+        (Some(username), Some(password), None, None) => serde_json::json!({
+            "type": "basic",
+            "username": username,
+            "password": password,
+        }),
+        (None, None, Some(name), Some(value)) => serde_json::json!({
+            "type": "custom_header",
+            "header_name": name,
+            "header_value": value,
+        }),
+        _ => bail!(
+            "an HTTP destination needs --username and --password, or --header-name and --header-value"
+        ),
+    };
+
+    let mut body = base.unwrap_or_else(|| serde_json::json!({"data": {}}));
+    let Some(data) = body.get_mut("data").and_then(|d| d.as_object_mut()) else {
+        bail!("custom destination body must be a JSON object with a \"data\" object");
+    };
+    let attrs = data
+        .entry("attributes")
+        .or_insert_with(|| serde_json::json!({}));
+    let Some(attrs) = attrs.as_object_mut() else {
+        bail!("custom destination \"attributes\" must be an object");
+    };
+    attrs.insert(
+        "forwarder_destination".into(),
+        serde_json::json!({"type": "http", "endpoint": http.endpoint, "auth": auth}),
+    );
+    Ok(body)
+}
+
+/// Builds a custom destination request body from an optional `--file` body
+/// plus flag overrides. Pass `id` for updates; creates additionally require
+/// a name and a forwarder destination.
+pub fn custom_destination_body(
+    base: Option<serde_json::Value>,
+    id: Option<&str>,
+    name: Option<&str>,
+    query: Option<&str>,
+    dest_type: Option<&str>,
+    enabled: Option<bool>,
+) -> Result<serde_json::Value> {
+    let mut body = base.unwrap_or_else(|| serde_json::json!({"data": {}}));
+    let Some(data) = body.get_mut("data").and_then(|d| d.as_object_mut()) else {
+        bail!("custom destination body must be a JSON object with a \"data\" object");
+    };
+    data.insert("type".into(), "custom_destination".into());
+    if let Some(id) = id {
+        data.insert("id".into(), id.into());
+    }
+    let attrs = data
+        .entry("attributes")
+        .or_insert_with(|| serde_json::json!({}));
+    let Some(attrs) = attrs.as_object_mut() else {
+        bail!("custom destination \"attributes\" must be an object");
+    };
+    if let Some(name) = name {
+        attrs.insert("name".into(), name.into());
+    }
+    if let Some(query) = query {
+        attrs.insert("query".into(), query.into());
+    }
+    if let Some(enabled) = enabled {
+        attrs.insert("enabled".into(), enabled.into());
+    }
+    if let Some(t) = dest_type {
+        // The API calls Splunk "splunk_hec"; accept the short name too.
+        let t = if t == "splunk" { "splunk_hec" } else { t };
+        let dest = attrs
+            .entry("forwarder_destination")
+            .or_insert_with(|| serde_json::json!({}));
+        match dest.as_object_mut() {
+            Some(dest) => dest.insert("type".into(), t.into()),
+            None => bail!("custom destination \"forwarder_destination\" must be an object"),
+        };
+    }
+
+    if let Some(t) = attrs.get("forwarder_destination").map(|d| &d["type"]) {
+        let t = t.as_str().unwrap_or_default();
+        if !CUSTOM_DESTINATION_TYPES.contains(&t) {
+            bail!(
+                "invalid destination type {t:?} (expected one of: {})",
+                CUSTOM_DESTINATION_TYPES.join(", ")
+            );
+        }
+    }
+    if id.is_none() {
+        if !attrs.contains_key("name") {
+            bail!("custom destination requires a name (--name or attributes.name in --file)");
+        }
+        if !attrs.contains_key("forwarder_destination") {
+            bail!(
+                "custom destination requires a forwarder_destination with its endpoint and auth; \
+                 provide it with --endpoint (HTTP) or --file"
+            );
+        }
+    }
+    Ok(body)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn custom_destinations_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs custom-destinations create requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let body = serde_json::from_value(body)
+        .map_err(|e| anyhow::anyhow!("invalid custom destination body: {e}"))?;
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsCustomDestinationsAPI::with_config(dd_cfg);
+
+    let resp = api
+        .create_logs_custom_destination(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create custom destination: {:?}", e))?;
+
+    formatter::output(cfg, &resp)?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn custom_destinations_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    let data = crate::api::post(cfg, "/api/v2/logs/config/custom_destinations", &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn custom_destinations_update(
+    cfg: &Config,
+    destination_id: &str,
+    body: serde_json::Value,
+) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs custom-destinations update requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let body = serde_json::from_value(body)
+        .map_err(|e| anyhow::anyhow!("invalid custom destination body: {e}"))?;
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsCustomDestinationsAPI::with_config(dd_cfg);
+
+    let resp = api
+        .update_logs_custom_destination(destination_id.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update custom destination: {:?}", e))?;
+
+    formatter::output(cfg, &resp)?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn custom_destinations_update(
+    cfg: &Config,
+    destination_id: &str,
+    body: serde_json::Value,
+) -> Result<()> {
+    let path = format!("/api/v2/logs/config/custom_destinations/{destination_id}");
+    let data = crate::api::patch(cfg, &path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn custom_destinations_delete(cfg: &Config, destination_id: &str) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs custom-destinations delete requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsCustomDestinationsAPI::with_config(dd_cfg);
+
+    api.delete_logs_custom_destination(destination_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete custom destination: {:?}", e))?;
+
+    formatter::print_block(&format!("Custom destination {destination_id} deleted.\n"))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn custom_destinations_delete(cfg: &Config, destination_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/custom_destinations/{destination_id}");
+    crate::api::delete(cfg, &path).await?;
+    formatter::print_block(&format!("Custom destination {destination_id} deleted.\n"))?;
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn metrics_list(cfg: &Config) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs metrics list requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsMetricsAPI::with_config(dd_cfg);
+
+    let resp = api
+        .list_logs_metrics()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list log-based metrics: {:?}", e))?;
+
+    formatter::output(cfg, &resp)?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn metrics_list(cfg: &Config) -> Result<()> {
+    let data = crate::api::get(cfg, "/api/v2/logs/config/metrics", &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Prints a log-based metric. With `volume_window`, also counts the logs its
+/// query matched over that window, to judge volume before enabling it.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn metrics_get(cfg: &Config, metric_id: &str, volume_window: Option<&str>) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs metrics get requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsMetricsAPI::with_config(dd_cfg);
+
+    let resp = api
+        .get_logs_metric(metric_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log-based metric: {:?}", e))?;
+
+    let Some(window) = volume_window else {
+        return formatter::output(cfg, &resp);
+    };
+    let mut data = serde_json::to_value(&resp)?;
+    let query = metric_filter_query(&data);
+    let count = count_matching(cfg, &query, window).await?;
+    util::attach_volume(&mut data, &query, window, count);
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn metrics_get(cfg: &Config, metric_id: &str, volume_window: Option<&str>) -> Result<()> {
+    let path = format!("/api/v2/logs/config/metrics/{metric_id}");
+    let mut data = crate::api::get(cfg, &path, &[]).await?;
+    if let Some(window) = volume_window {
+        let query = metric_filter_query(&data);
+        let count = count_matching(cfg, &query, window).await?;
+        util::attach_volume(&mut data, &query, window, count);
+    }
+    crate::formatter::output(cfg, &data)
+}
+
+/// Aggregations a log-based metric can compute.
+pub const LOG_METRIC_COMPUTE_TYPES: &[&str] = &["count", "distribution"];
+
+/// Parses `--group-by` for log-based metrics: comma-separated attribute
+/// paths, each optionally renamed with `=tag_name`. Without a name, the tag
+/// is the path minus its leading `@`.
+pub fn parse_metric_group_by(spec: &str) -> Result<Vec<serde_json::Value>> {
+    spec.split(',')
+        .map(str::trim)
+        .filter(|s| !s.is_empty())
+        .map(|item| {
+            let (path, tag) = match item.split_once('=') {
+                Some((path, tag)) => (path.trim(), tag.trim()),
+                None => (item, item.trim_start_matches('@')),
+            };
+            if path.is_empty() || tag.is_empty() {
+                bail!("invalid --group-by entry {item:?}: expected PATH or PATH=TAG_NAME");
+            }
+            Ok(serde_json::json!({ "path": path, "tag_name": tag }))
+        })
+        .collect()
+}
+
+/// Flags for `logs metrics create` and `update`.
+#[derive(Debug, Default)]
+pub struct LogMetricFlags {
+    pub query: Option<String>,
+    pub compute: Option<String>,
+    pub path: Option<String>,
+    pub group_by: Option<String>,
+    pub include_percentiles: Option<bool>,
+}
+
+/// Builds a log-based metric request body from an optional `--file` body
+/// plus flags. Pass `name` (the metric id) for creates; updates can change
+/// only the query, group-by and percentiles, not what is computed.
+pub fn log_metric_body(
+    base: Option<serde_json::Value>,
+    name: Option<&str>,
+    flags: &LogMetricFlags,
+) -> Result<serde_json::Value> {
+    let mut body = base.unwrap_or_else(|| serde_json::json!({"data": {}}));
+    let Some(data) = body.get_mut("data").and_then(|d| d.as_object_mut()) else {
+        bail!("log-based metric body must be a JSON object with a \"data\" object");
+    };
+    data.insert("type".into(), "logs_metrics".into());
+    match name {
+        Some(name) => {
+            data.insert("id".into(), name.into());
+        }
+        None => {
+            data.remove("id");
+        }
+    }
+    let attrs = data
+        .entry("attributes")
+        .or_insert_with(|| serde_json::json!({}));
+    let Some(attrs) = attrs.as_object_mut() else {
+        bail!("log-based metric \"attributes\" must be an object");
+    };
+    if let Some(query) = &flags.query {
+        attrs.insert("filter".into(), serde_json::json!({ "query": query }));
+    }
+    if let Some(spec) = &flags.group_by {
+        attrs.insert("group_by".into(), parse_metric_group_by(spec)?.into());
+    }
+
+    if name.is_none() && (flags.compute.is_some() || flags.path.is_some()) {
+        bail!("--compute and --path can't be changed on an existing log-based metric");
+    }
+    let compute = attrs
+        .entry("compute")
+        .or_insert_with(|| serde_json::json!({}));
+    let Some(compute) = compute.as_object_mut() else {
+        bail!("log-based metric \"compute\" must be an object");
+    };
+    if let Some(t) = &flags.compute {
+        compute.insert("aggregation_type".into(), t.to_lowercase().into());
+    }
+    if let Some(path) = &flags.path {
+        compute.insert("path".into(), path.as_str().into());
+    }
+    if let Some(include) = flags.include_percentiles {
+        compute.insert("include_percentiles".into(), include.into());
+    }
+
+    if name.is_some() {
+        let t = compute
+            .entry("aggregation_type")
+            .or_insert_with(|| "count".into())
+            .as_str()
+            .unwrap_or_default()
+            .to_string();
+        if !LOG_METRIC_COMPUTE_TYPES.contains(&t.as_str()) {
+            bail!(
+                "invalid compute type {t:?} (expected one of: {})",
+                LOG_METRIC_COMPUTE_TYPES.join(", ")
+            );
+        }
+        if t == "distribution" && !compute.contains_key("path") {
+            bail!("a distribution metric needs --path, the measure it aggregates (e.g. @duration)");
+        }
+        if t == "count" && compute.contains_key("include_percentiles") {
+            bail!("--include-percentiles only applies to distribution metrics");
+        }
+    } else if compute.is_empty() {
+        attrs.remove("compute");
+    }
+    Ok(body)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn metrics_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs metrics create requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let body = serde_json::from_value(body)
+        .map_err(|e| anyhow::anyhow!("invalid log-based metric body: {e}"))?;
+    let api = LogsMetricsAPI::with_config(client::make_dd_config(cfg));
+    let resp = api
+        .create_logs_metric(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create log-based metric: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn metrics_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    let data = crate::api::post(cfg, "/api/v2/logs/config/metrics", &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn metrics_update(cfg: &Config, metric_id: &str, body: serde_json::Value) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs metrics update requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let body = serde_json::from_value(body)
+        .map_err(|e| anyhow::anyhow!("invalid log-based metric body: {e}"))?;
+    let api = LogsMetricsAPI::with_config(client::make_dd_config(cfg));
+    let resp = api
+        .update_logs_metric(metric_id.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update log-based metric: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn metrics_update(cfg: &Config, metric_id: &str, body: serde_json::Value) -> Result<()> {
+    let path = format!("/api/v2/logs/config/metrics/{metric_id}");
+    let data = crate::api::patch(cfg, &path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn metrics_delete(cfg: &Config, metric_id: &str) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs metrics delete requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = LogsMetricsAPI::with_config(dd_cfg);
+
+    api.delete_logs_metric(metric_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete log-based metric: {:?}", e))?;
+
+    formatter::print_block(&format!("Log-based metric {metric_id} deleted.\n"))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn metrics_delete(cfg: &Config, metric_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/metrics/{metric_id}");
+    crate::api::delete(cfg, &path).await?;
+    formatter::print_block(&format!("Log-based metric {metric_id} deleted.\n"))?;
+    Ok(())
+}
+
+// ---------------------------------------------------------------------------
+// Pattern analysis
+// ---------------------------------------------------------------------------
+
+/// Placeholder for the variable part of a log pattern.
+const PATTERN_WILDCARD: &str = "<*>";
+
+/// Tokens of a message considered when templating it; stack traces and
+/// payload dumps past them don't split otherwise identical logs.
+const PATTERN_MAX_TOKENS: usize = 40;
+
+/// Longest example message kept per pattern.
+const PATTERN_EXAMPLE_CHARS: usize = 200;
+
+/// Templates a log message: its first line split on whitespace, with every
+/// token carrying a digit (ids, counts, durations, IPs, timestamps) replaced
+/// by a wildcard. A `key=value` token keeps its key.
+fn message_template(message: &str) -> Vec<String> {
+    let variable = |token: &str| token.chars().any(|c| c.is_ascii_digit());
+    message
+        .lines()
+        .next()
+        .unwrap_or_default()
+        .split_whitespace()
+        .take(PATTERN_MAX_TOKENS)
+        .map(|token| match token.split_once('=') {
+            Some((key, value)) if !key.is_empty() && !variable(key) && variable(value) => {
+                format!("{key}={PATTERN_WILDCARD}")
+            }
+            _ if variable(token) => PATTERN_WILDCARD.to_string(),
+            _ => token.to_string(),
+        })
+        .collect()
+}
+
+/// Logs sharing a template, with how often each service and status shows up.
+struct LogPattern {
+    template: Vec<String>,
+    count: usize,
+    services: Vec<(String, usize)>,
+    statuses: Vec<(String, usize)>,
+    example: String,
+}
+
+impl LogPattern {
+    /// Folds `other` into this pattern, wildcarding the tokens they differ in.
+    fn absorb(&mut self, other: LogPattern) {
+        for (mine, theirs) in self.template.iter_mut().zip(&other.template) {
+            if mine != theirs {
+                *mine = PATTERN_WILDCARD.to_string();
+            }
+        }
+        self.count += other.count;
+        for (name, n) in other.services {
+            tally(&mut self.services, &name, n);
+        }
+        for (name, n) in other.statuses {
+            tally(&mut self.statuses, &name, n);
+        }
+    }
+
+    /// Whether `template` reads as this pattern with one more wildcard: same
+    /// length (at least three tokens) and at most one differing token, never
+    /// the first.
+    fn accepts(&self, template: &[String]) -> bool {
+        if self.template.len() != template.len() || template.len() < 3 {
+            return false;
+        }
+        let mut differing = self
+            .template
+            .iter()
+            .zip(template)
+            .enumerate()
+            .filter(|(_, (mine, theirs))| *mine != PATTERN_WILDCARD && mine != theirs)
+            .map(|(i, _)| i);
+        match (differing.next(), differing.next()) {
+            (None, _) => true,
+            (Some(i), None) => i > 0,
+            _ => false,
+        }
+    }
+}
+
+fn tally(counts: &mut Vec<(String, usize)>, name: &str, n: usize) {
+    match counts.iter_mut().find(|(c, _)| c == name) {
+        Some((_, count)) => *count += n,
+        None => counts.push((name.to_string(), n)),
+    }
+}
+
+/// Most frequent first, ties by name.
+fn ranked(counts: &[(String, usize)]) -> Vec<&(String, usize)> {
+    let mut ranked: Vec<_> = counts.iter().collect();
+    ranked.sort_by(|a, b| b.1.cmp(&a.1).then_with(|| a.0.cmp(&b.0)));
+    ranked
+}
+
+/// Clusters `logs` into message patterns, largest first, and reports the
+/// `top` of them with their share of the sample, busiest services, most
+/// common status and an example message.
+fn log_patterns(logs: &[serde_json::Value], top: usize) -> serde_json::Value {
+    // Exact templates first, so the merge pass below sees the biggest groups
+    // first and they decide which tokens stay literal.
+    let mut exact: Vec<LogPattern> = Vec::new();
+    let mut index: std::collections::HashMap<Vec<String>, usize> = std::collections::HashMap::new();
+    for log in logs {
+        let attrs = &log["attributes"];
+        let message = attrs["message"].as_str().unwrap_or_default();
+        let template = message_template(message);
+        let i = *index.entry(template.clone()).or_insert_with(|| {
+            exact.push(LogPattern {
+                template,
+                count: 0,
+                services: Vec::new(),
+                statuses: Vec::new(),
+                example: message
+                    .lines()
+                    .next()
+                    .unwrap_or_default()
+                    .chars()
+                    .take(PATTERN_EXAMPLE_CHARS)
+                    .collect(),
+            });
+            exact.len() - 1
+        });
+        let pattern = &mut exact[i];
+        pattern.count += 1;
+        tally(
+            &mut pattern.services,
+            attrs["service"].as_str().unwrap_or("-"),
+            1,
+        );
+        tally(
+            &mut pattern.statuses,
+            attrs["status"].as_str().unwrap_or("-"),
+            1,
+        );
+    }
+    exact.sort_by(|a, b| {
+        b.count
+            .cmp(&a.count)
+            .then_with(|| a.template.cmp(&b.template))
+    });
+
+    let mut patterns: Vec<LogPattern> = Vec::new();
+    for pattern in exact {
+        match patterns.iter_mut().find(|p| p.accepts(&pattern.template)) {
+            Some(existing) => existing.absorb(pattern),
+            None => patterns.push(pattern),
+        }
+    }
+    patterns.sort_by(|a, b| {
+        b.count
+            .cmp(&a.count)
+            .then_with(|| a.template.cmp(&b.template))
+    });
+
+    let total = logs.len();
+    let rows: Vec<serde_json::Value> = patterns
+        .iter()
+        .take(top)
+        .map(|p| {
+            let pattern = if p.template.is_empty() {
+                "(no message)".to_string()
+            } else {
+                p.template.join(" ")
+            };
+            let services: Vec<String> = ranked(&p.services)
+                .into_iter()
+                .take(3)
+                .map(|(name, n)| format!("{name} ({n})"))
+                .collect();
+            serde_json::json!({
+                "pattern": pattern,
+                "count": p.count,
+                "percent": (p.count as f64 * 1000.0 / total as f64).round() / 10.0,
+                "services": services.join(", "),
+                "status": ranked(&p.statuses).first().map(|(s, _)| s.as_str()),
+                "example": p.example,
+            })
+        })
+        .collect();
+    serde_json::json!({
+        "data": rows,
+        "meta": { "logs": total, "patterns": patterns.len() }
+    })
+}
+
+/// Samples up to `limit` logs matching `query`, newest first, and reports
+/// the message patterns they fall into.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn analyze(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    limit: i32,
+    top: usize,
+) -> Result<()> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs analyze requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+    let api = LogsAPI::with_config(client::make_dd_config(cfg));
+    let from_ms = util::parse_time_to_unix_millis(&from)?;
+    let to_ms = util::parse_time_to_unix_millis(&to)?;
+
+    let mut logs = Vec::new();
+    let mut cursor = None;
+    while (logs.len() as i32) < limit {
+        let resp = fetch_logs_page(
+            &api,
+            logs_filter(&query, from_ms, to_ms, None),
+            (limit - logs.len() as i32).min(EXPORT_PAGE_SIZE),
+            LogsSort::TIMESTAMP_DESCENDING,
+            cursor,
+        )
+        .await?;
+        logs.extend(log_values(&resp)?);
+        cursor = resp.meta.and_then(|m| m.page).and_then(|p| p.after);
+        if cursor.is_none() {
+            break;
+        }
+    }
+    formatter::print_status(&format!("Analyzed {} logs.", logs.len()));
+    formatter::output(cfg, &log_patterns(&logs, top))
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn analyze(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    limit: i32,
+    top: usize,
+) -> Result<()> {
+    let from_ms = util::parse_time_to_unix_millis(&from)?;
+    let to_ms = util::parse_time_to_unix_millis(&to)?;
+
+    let mut logs: Vec<serde_json::Value> = Vec::new();
+    let mut cursor: Option<String> = None;
+    while (logs.len() as i32) < limit {
+        let mut body = serde_json::json!({
+            "filter": {
+                "query": query,
+                "from": from_ms.to_string(),
+                "to": to_ms.to_string()
+            },
+            "page": { "limit": (limit - logs.len() as i32).min(EXPORT_PAGE_SIZE) },
+            "sort": "-timestamp"
+        });
+        if let Some(cursor) = cursor {
+            body["page"]["cursor"] = cursor.into();
+        }
+        let data = crate::api::post(cfg, "/api/v2/logs/events/search", &body).await?;
+        if let Some(items) = data["data"].as_array() {
+            logs.extend(items.iter().cloned());
+        }
+        cursor = data["meta"]["page"]["after"].as_str().map(String::from);
+        if cursor.is_none() {
+            break;
+        }
+    }
+    formatter::print_status(&format!("Analyzed {} logs.", logs.len()));
+    crate::formatter::output(cfg, &log_patterns(&logs, top))
+}
+
+// ---------------------------------------------------------------------------
+// Facets (raw HTTP - not available in typed client)
+// ---------------------------------------------------------------------------
+
+/// Lists the log facets (standard and custom) of the org, the fields usable
+/// in `--group-by` and facet queries.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn facets_list(cfg: &Config) -> Result<()> {
+    let data = client::raw_get(cfg, "/api/v1/logs/facets").await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn facets_list(cfg: &Config) -> Result<()> {
+    let data = crate::api::get(cfg, "/api/v1/logs/facets", &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+// ---------------------------------------------------------------------------
+// Archive rehydration (no public API)
+// ---------------------------------------------------------------------------
+
+/// Fails `logs rehydrations <command>`: Datadog has no public API for
+/// archive rehydration (historical views), so the subcommands point at the
+/// UI instead of pretending to start or track anything.
+pub fn rehydrations_unavailable(cfg: &Config, command: &str) -> Result<()> {
+    bail!(
+        "`logs rehydrations {command}` is not available: Datadog has no public API for \
+         archive rehydration. Start and monitor historical views in the Datadog UI at \
+         https://app.{}/logs/pipelines/historical-views",
+        cfg.site
+    )
+}
+
+// ---------------------------------------------------------------------------
+// Restriction Queries (raw HTTP - not available in typed client)
+// ---------------------------------------------------------------------------
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restriction_queries_list(cfg: &Config) -> Result<()> {
+    let data = client::raw_get(cfg, "/api/v2/logs/config/restriction_queries").await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restriction_queries_list(cfg: &Config) -> Result<()> {
+    let data = crate::api::get(cfg, "/api/v2/logs/config/restriction_queries", &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restriction_queries_get(cfg: &Config, query_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
+    let data = client::raw_get(cfg, &path).await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restriction_queries_get(cfg: &Config, query_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
+    let data = crate::api::get(cfg, &path, &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Body for creating or updating a restriction query.
+fn restriction_query_body(query: &str) -> Result<serde_json::Value> {
+    let query = query.trim();
+    if query.is_empty() {
+        bail!("--query cannot be empty");
+    }
+    Ok(serde_json::json!({
+        "data": {
+            "type": "logs_restriction_queries",
+            "attributes": { "restriction_query": query }
+        }
+    }))
+}
+
+/// Body naming the role to grant a restriction query to or revoke it from.
+fn restriction_role_body(role_id: &str) -> Result<serde_json::Value> {
+    let role_id = role_id.trim();
+    if role_id.is_empty() {
+        bail!("--role-id cannot be empty");
+    }
+    Ok(serde_json::json!({ "data": { "type": "roles", "id": role_id } }))
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restriction_queries_create(cfg: &Config, query: &str) -> Result<()> {
+    let body = restriction_query_body(query)?;
+    let data = client::raw_post(cfg, "/api/v2/logs/config/restriction_queries", body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create restriction query: {e}"))?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restriction_queries_create(cfg: &Config, query: &str) -> Result<()> {
+    let body = restriction_query_body(query)?;
+    let data = crate::api::post(cfg, "/api/v2/logs/config/restriction_queries", &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restriction_queries_update(cfg: &Config, query_id: &str, query: &str) -> Result<()> {
+    let body = restriction_query_body(query)?;
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
+    let resp = client::raw_request(cfg, "PATCH", &path, Some(&body))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update restriction query: {e}"))?;
+    let data: serde_json::Value = resp.json().await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restriction_queries_update(cfg: &Config, query_id: &str, query: &str) -> Result<()> {
+    let body = restriction_query_body(query)?;
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
+    let data = crate::api::patch(cfg, &path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restriction_queries_delete(cfg: &Config, query_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
+    client::raw_delete(cfg, &path)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete restriction query: {e}"))?;
+    formatter::print_block(&format!("Restriction query {query_id} deleted.\n"))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restriction_queries_delete(cfg: &Config, query_id: &str) -> Result<()> {
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}");
+    crate::api::delete(cfg, &path).await?;
+    formatter::print_block(&format!("Restriction query {query_id} deleted.\n"))?;
+    Ok(())
+}
+
+/// Grants (`POST`) or revokes (`DELETE`) a role's restriction query. Both
+/// endpoints answer with no content.
+#[cfg(not(target_arch = "wasm32"))]
+async fn restriction_queries_role(
+    cfg: &Config,
+    method: &str,
+    query_id: &str,
+    role_id: &str,
+) -> Result<()> {
+    let body = restriction_role_body(role_id)?;
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}/roles");
+    client::raw_request(cfg, method, &path, Some(&body)).await?;
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restriction_queries_grant_role(
+    cfg: &Config,
+    query_id: &str,
+    role_id: &str,
+) -> Result<()> {
+    restriction_queries_role(cfg, "POST", query_id, role_id)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to grant restriction query: {e}"))?;
+    formatter::print_block(&format!(
+        "Restriction query {query_id} granted to role {role_id}.\n"
+    ))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restriction_queries_grant_role(
+    cfg: &Config,
+    query_id: &str,
+    role_id: &str,
+) -> Result<()> {
+    let body = restriction_role_body(role_id)?;
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}/roles");
+    crate::api::post(cfg, &path, &body).await?;
+    formatter::print_block(&format!(
+        "Restriction query {query_id} granted to role {role_id}.\n"
+    ))?;
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn restriction_queries_revoke_role(
+    cfg: &Config,
+    query_id: &str,
+    role_id: &str,
+) -> Result<()> {
+    restriction_queries_role(cfg, "DELETE", query_id, role_id)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to revoke restriction query: {e}"))?;
+    formatter::print_block(&format!(
+        "Restriction query {query_id} revoked from role {role_id}.\n"
+    ))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn restriction_queries_revoke_role(
+    cfg: &Config,
+    query_id: &str,
+    role_id: &str,
+) -> Result<()> {
+    let body = restriction_role_body(role_id)?;
+    let path = format!("/api/v2/logs/config/restriction_queries/{query_id}/roles");
+    crate::api::delete_with_body(cfg, &path, &body).await?;
+    formatter::print_block(&format!(
+        "Restriction query {query_id} revoked from role {role_id}.\n"
+    ))?;
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+fn pipelines_api(cfg: &Config, action: &str) -> Result<LogsPipelinesAPI> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs pipelines {action} requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+    Ok(LogsPipelinesAPI::with_config(client::make_dd_config(cfg)))
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn pipelines_list(cfg: &Config) -> Result<()> {
+    let resp = pipelines_api(cfg, "list")?
+        .list_logs_pipelines()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list log pipelines: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn pipelines_list(cfg: &Config) -> Result<()> {
+    let data = crate::api::get(cfg, "/api/v1/logs/config/pipelines", &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn pipelines_get(cfg: &Config, pipeline_id: &str) -> Result<()> {
+    let resp = pipelines_api(cfg, "get")?
+        .get_logs_pipeline(pipeline_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log pipeline: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn pipelines_get(cfg: &Config, pipeline_id: &str) -> Result<()> {
+    let path = format!("/api/v1/logs/config/pipelines/{pipeline_id}");
+    let data = crate::api::get(cfg, &path, &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn pipelines_create(cfg: &Config, file: &str) -> Result<()> {
+    let body: LogsPipeline = util::read_json_file(file)?;
+    let resp = pipelines_api(cfg, "create")?
+        .create_logs_pipeline(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create log pipeline: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn pipelines_create(cfg: &Config, file: &str) -> Result<()> {
+    let body: serde_json::Value = util::read_json_file(file)?;
+    let data = crate::api::post(cfg, "/api/v1/logs/config/pipelines", &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Replaces the pipeline with the definition in `file`; processors left out
+/// of the file are removed.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn pipelines_update(cfg: &Config, pipeline_id: &str, file: &str) -> Result<()> {
+    let body: LogsPipeline = util::read_json_file(file)?;
+    let resp = pipelines_api(cfg, "update")?
+        .update_logs_pipeline(pipeline_id.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update log pipeline: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn pipelines_update(cfg: &Config, pipeline_id: &str, file: &str) -> Result<()> {
+    let body: serde_json::Value = util::read_json_file(file)?;
+    let path = format!("/api/v1/logs/config/pipelines/{pipeline_id}");
+    let data = crate::api::put(cfg, &path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn pipelines_delete(cfg: &Config, pipeline_id: &str) -> Result<()> {
+    pipelines_api(cfg, "delete")?
+        .delete_logs_pipeline(pipeline_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete log pipeline: {:?}", e))?;
+    formatter::print_block(&format!("Log pipeline {pipeline_id} deleted.\n"))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn pipelines_delete(cfg: &Config, pipeline_id: &str) -> Result<()> {
+    let path = format!("/api/v1/logs/config/pipelines/{pipeline_id}");
+    crate::api::delete(cfg, &path).await?;
+    formatter::print_block(&format!("Log pipeline {pipeline_id} deleted.\n"))?;
+    Ok(())
+}
+
+/// New order for `pipelines reorder`, `indexes order` and `archives order`:
+/// the `requested` ids first, in the order given, then the rest in their
+/// `current` order. The API only accepts a complete order, so unknown or
+/// repeated ids are rejected rather than sent. `kind` names the items in
+/// errors.
+pub fn move_to_front(current: &[String], requested: &[String], kind: &str) -> Result<Vec<String>> {
+    let mut seen = std::collections::HashSet::new();
+    for id in requested {
+        if !current.contains(id) {
+            bail!("unknown {kind} {id:?}");
+        }
+        if !seen.insert(id) {
+            bail!("{kind} {id:?} is listed more than once");
+        }
+    }
+    Ok(requested
+        .iter()
+        .chain(current.iter().filter(|id| !seen.contains(id)))
+        .cloned()
+        .collect())
+}
+
+/// Moves the `requested` pipelines to the front of the processing order and
+/// prints the resulting order.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn pipelines_reorder(cfg: &Config, requested: &[String]) -> Result<()> {
+    let api = pipelines_api(cfg, "reorder")?;
+    let current = api
+        .get_logs_pipeline_order()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log pipeline order: {:?}", e))?;
+    let order = move_to_front(&current.pipeline_ids, requested, "log pipeline")?;
+    let resp = api
+        .update_logs_pipeline_order(LogsPipelinesOrder::new(order))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update log pipeline order: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn pipelines_reorder(cfg: &Config, requested: &[String]) -> Result<()> {
+    let path = "/api/v1/logs/config/pipeline-order";
+    let current = crate::api::get(cfg, path, &[]).await?;
+    let current: Vec<String> = current["pipeline_ids"]
+        .as_array()
+        .map(|ids| {
+            ids.iter()
+                .filter_map(|id| id.as_str().map(String::from))
+                .collect()
+        })
+        .unwrap_or_default();
+    let order = move_to_front(&current, requested, "log pipeline")?;
+    let body = serde_json::json!({ "pipeline_ids": order });
+    let data = crate::api::put(cfg, path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Flag changes applied to a log index by `indexes create` and `indexes update`.
+#[derive(Debug, Default)]
+pub struct IndexChanges {
+    pub filter: Option<String>,
+    pub daily_limit: Option<i64>,
+    pub no_daily_limit: bool,
+    pub retention_days: Option<i64>,
+    /// Exclusion filter to add, replacing any with the same name.
+    pub exclusion: Option<ExclusionFilter>,
+    /// Names of exclusion filters to remove.
+    pub remove_exclusions: Vec<String>,
+}
+
+#[derive(Debug)]
+pub struct ExclusionFilter {
+    pub name: String,
+    pub query: String,
+    /// Fraction of matching logs excluded, 0.0 to 1.0.
+    pub sample_rate: f64,
+    pub enabled: bool,
+}
+
+/// Applies `changes` to an index definition (from `--file`, or the current
+/// index for updates) and returns the request body. Creates need a `name`;
+/// updates drop the read-only `name` and `is_rate_limited` fields, which the
+/// update endpoint rejects.
+pub fn index_body(
+    base: Option<serde_json::Value>,
+    name: Option<&str>,
+    changes: &IndexChanges,
+) -> Result<serde_json::Value> {
+    let mut body = base.unwrap_or_else(|| serde_json::json!({}));
+    let Some(index) = body.as_object_mut() else {
+        bail!("log index body must be a JSON object");
+    };
+    match name {
+        Some(name) => {
+            index.insert("name".into(), name.into());
+        }
+        None => {
+            index.remove("name");
+            index.remove("is_rate_limited");
+        }
+    }
+    if let Some(query) = &changes.filter {
+        index.insert("filter".into(), serde_json::json!({ "query": query }));
+    }
+    if let Some(limit) = changes.daily_limit {
+        if limit <= 0 {
+            bail!("--daily-limit must be a positive number of logs");
+        }
+        index.insert("daily_limit".into(), limit.into());
+        index.insert("disable_daily_limit".into(), false.into());
+    }
+    if changes.no_daily_limit {
+        index.remove("daily_limit");
+        index.insert("disable_daily_limit".into(), true.into());
+    }
+    if let Some(days) = changes.retention_days {
+        index.insert("num_retention_days".into(), days.into());
+    }
+
+    if changes.exclusion.is_some() || !changes.remove_exclusions.is_empty() {
+        let filters = index
+            .entry("exclusion_filters")
+            .or_insert_with(|| serde_json::json!([]));
+        let Some(filters) = filters.as_array_mut() else {
+            bail!("log index \"exclusion_filters\" must be an array");
+        };
+        let named = |f: &serde_json::Value, name: &str| f["name"].as_str() == Some(name);
+        for name in &changes.remove_exclusions {
+            if !filters.iter().any(|f| named(f, name)) {
+                bail!("log index has no exclusion filter named {name:?}");
+            }
+            filters.retain(|f| !named(f, name));
+        }
+        if let Some(exclusion) = &changes.exclusion {
+            if !(0.0..=1.0).contains(&exclusion.sample_rate) {
+                bail!("--exclusion-sample-rate must be between 0 and 1");
+            }
+            filters.retain(|f| !named(f, &exclusion.name));
+            filters.push(serde_json::json!({
+                "name": exclusion.name,
+                "is_enabled": exclusion.enabled,
+                "filter": {
+                    "query": exclusion.query,
+                    "sample_rate": exclusion.sample_rate,
+                },
+            }));
+        }
+    }
+
+    if name.is_some() && !index.contains_key("filter") {
+        bail!("log index requires a filter (--filter or filter.query in --file)");
+    }
+    Ok(body)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+fn indexes_api(cfg: &Config, action: &str) -> Result<LogsIndexesAPI> {
+    if !cfg.has_api_keys() {
+        bail!(
+            "logs indexes {action} requires API key authentication (DD_API_KEY + DD_APP_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+    Ok(LogsIndexesAPI::with_config(client::make_dd_config(cfg)))
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn indexes_list(cfg: &Config) -> Result<()> {
+    let resp = indexes_api(cfg, "list")?
+        .list_log_indexes()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list log indexes: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn indexes_list(cfg: &Config) -> Result<()> {
+    let data = crate::api::get(cfg, "/api/v1/logs/config/indexes", &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn indexes_get(cfg: &Config, name: &str) -> Result<()> {
+    let resp = indexes_api(cfg, "get")?
+        .get_logs_index(name.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log index: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn indexes_get(cfg: &Config, name: &str) -> Result<()> {
+    let path = format!("/api/v1/logs/config/indexes/{name}");
+    let data = crate::api::get(cfg, &path, &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn indexes_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    let body: LogsIndex =
+        serde_json::from_value(body).map_err(|e| anyhow::anyhow!("invalid log index body: {e}"))?;
+    let resp = indexes_api(cfg, "create")?
+        .create_logs_index(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create log index: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn indexes_create(cfg: &Config, body: serde_json::Value) -> Result<()> {
+    let data = crate::api::post(cfg, "/api/v1/logs/config/indexes", &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Updates an index: `base` replaces it when given (`--file`), otherwise the
+/// current definition is fetched, so flags only change what they name.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn indexes_update(
+    cfg: &Config,
+    name: &str,
+    base: Option<serde_json::Value>,
+    changes: &IndexChanges,
+) -> Result<()> {
+    let api = indexes_api(cfg, "update")?;
+    let base = match base {
+        Some(base) => base,
+        None => {
+            let current = api
+                .get_logs_index(name.to_string())
+                .await
+                .map_err(|e| anyhow::anyhow!("failed to get log index: {:?}", e))?;
+            serde_json::to_value(&current)?
+        }
+    };
+    let body: LogsIndexUpdateRequest =
+        serde_json::from_value(index_body(Some(base), None, changes)?)
+            .map_err(|e| anyhow::anyhow!("invalid log index body: {e}"))?;
+    let resp = api
+        .update_logs_index(name.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update log index: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn indexes_update(
+    cfg: &Config,
+    name: &str,
+    base: Option<serde_json::Value>,
+    changes: &IndexChanges,
+) -> Result<()> {
+    let path = format!("/api/v1/logs/config/indexes/{name}");
+    let base = match base {
+        Some(base) => base,
+        None => crate::api::get(cfg, &path, &[]).await?,
+    };
+    let body = index_body(Some(base), None, changes)?;
+    let data = crate::api::put(cfg, &path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn indexes_delete(cfg: &Config, name: &str) -> Result<()> {
+    indexes_api(cfg, "delete")?
+        .delete_logs_index(name.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete log index: {:?}", e))?;
+    formatter::print_block(&format!("Log index {name} deleted.\n"))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn indexes_delete(cfg: &Config, name: &str) -> Result<()> {
+    let path = format!("/api/v1/logs/config/indexes/{name}");
+    crate::api::delete(cfg, &path).await?;
+    formatter::print_block(&format!("Log index {name} deleted.\n"))?;
+    Ok(())
+}
+
+/// Prints the index order; with `requested` names, first moves them to the
+/// front (logs go to the first index whose filter matches).
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn indexes_order(cfg: &Config, requested: &[String]) -> Result<()> {
+    let api = indexes_api(cfg, "order")?;
+    let current = api
+        .get_logs_index_order()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get log index order: {:?}", e))?;
+    if requested.is_empty() {
+        return formatter::output(cfg, &current);
+    }
+    let order = move_to_front(&current.index_names, requested, "log index")?;
+    let resp = api
+        .update_logs_index_order(LogsIndexesOrder::new(order))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update log index order: {:?}", e))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn indexes_order(cfg: &Config, requested: &[String]) -> Result<()> {
+    let path = "/api/v1/logs/config/index-order";
+    let current = crate::api::get(cfg, path, &[]).await?;
+    if requested.is_empty() {
+        return crate::formatter::output(cfg, &current);
+    }
+    let names: Vec<String> = current["index_names"]
+        .as_array()
+        .map(|names| {
+            names
+                .iter()
+                .filter_map(|n| n.as_str().map(String::from))
+                .collect()
+        })
+        .unwrap_or_default();
+    let order = move_to_front(&names, requested, "log index")?;
+    let body = serde_json::json!({ "index_names": order });
+    let data = crate::api::put(cfg, path, &body).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Extracts the free-text terms of a logs query for `--highlight`.
+///
+/// Facets (`service:web`, `@http.status_code:500`), boolean operators, and
+/// negated terms are skipped; quoted phrases are kept whole and wildcards are
+/// trimmed from the ends of terms.
+pub fn highlight_terms(query: &str) -> Vec<String> {
+    let mut tokens = Vec::new();
+    let mut current = String::new();
+    let mut in_quotes = false;
+    for c in query.chars() {
+        match c {
+            '"' => {
+                in_quotes = !in_quotes;
+                current.push(c);
+            }
+            c if !in_quotes && (c.is_whitespace() || c == '(' || c == ')') => {
+                if !current.is_empty() {
+                    tokens.push(std::mem::take(&mut current));
+                }
+            }
+            _ => current.push(c),
+        }
+    }
+    if !current.is_empty() {
+        tokens.push(current);
+    }
+
+    let mut terms: Vec<String> = Vec::new();
+    for token in tokens {
+        if token.starts_with('-') || token.starts_with('!') {
+            continue;
+        }
+        let term = if token.len() >= 2 && token.starts_with('"') && token.ends_with('"') {
+            token[1..token.len() - 1].to_string()
+        } else if matches!(token.as_str(), "AND" | "OR" | "NOT") || token.contains(':') {
+            continue;
+        } else {
+            token.trim_matches('*').replace('"', "")
+        };
+        if !term.is_empty() && !terms.contains(&term) {
+            terms.push(term);
+        }
+    }
+    terms
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_index_body_create() {
+        let changes = IndexChanges {
+            filter: Some("env:prod".into()),
+            daily_limit: Some(1_000_000),
+            retention_days: Some(15),
+            ..Default::default()
+        };
+        let body = index_body(None, Some("main"), &changes).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({
+                "name": "main",
+                "filter": {"query": "env:prod"},
+                "daily_limit": 1_000_000,
+                "disable_daily_limit": false,
+                "num_retention_days": 15
+            })
+        );
+        assert!(index_body(None, Some("main"), &IndexChanges::default()).is_err());
+    }
+
+    #[test]
+    fn test_index_body_update_exclusions() {
+        let current = serde_json::json!({
+            "name": "main",
+            "is_rate_limited": false,
+            "filter": {"query": "*"},
+            "daily_limit": 500,
+            "exclusion_filters": [
+                {"name": "debug", "is_enabled": true, "filter": {"query": "status:debug", "sample_rate": 1.0}},
+                {"name": "health", "is_enabled": true, "filter": {"query": "@http.url:/health", "sample_rate": 1.0}}
+            ]
+        });
+        let changes = IndexChanges {
+            no_daily_limit: true,
+            exclusion: Some(ExclusionFilter {
+                name: "health".into(),
+                query: "@http.url:/healthz".into(),
+                sample_rate: 0.9,
+                enabled: true,
+            }),
+            remove_exclusions: vec!["debug".into()],
+            ..Default::default()
+        };
+        let body = index_body(Some(current.clone()), None, &changes).unwrap();
+        assert!(body.get("name").is_none());
+        assert!(body.get("is_rate_limited").is_none());
+        assert!(body.get("daily_limit").is_none());
+        assert_eq!(body["disable_daily_limit"], true);
+        assert_eq!(
+            body["exclusion_filters"],
+            serde_json::json!([{
+                "name": "health",
+                "is_enabled": true,
+                "filter": {"query": "@http.url:/healthz", "sample_rate": 0.9}
+            }])
+        );
+
+        let unknown = IndexChanges {
+            remove_exclusions: vec!["nope".into()],
+            ..Default::default()
+        };
+        assert!(index_body(Some(current), None, &unknown).is_err());
+    }
+
+    #[test]
+    fn test_archive_body() {
+        let file = serde_json::json!({"data": {"attributes": {
+            "destination": {"bucket": "logs", "integration": {"account_id": "1", "role_name": "r"}}
+        }}});
+        let body = archive_body(
+            Some(file),
+            Some("prod"),
+            Some("env:prod"),
+            Some("S3"),
+            Some("team:core, env:prod"),
+            Some(true),
+        )
+        .unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({"data": {"type": "archives", "attributes": {
+                "name": "prod",
+                "query": "env:prod",
+                "rehydration_tags": ["team:core", "env:prod"],
+                "include_tags": true,
+                "destination": {
+                    "type": "s3",
+                    "bucket": "logs",
+                    "integration": {"account_id": "1", "role_name": "r"}
+                }
+            }}})
+        );
+
+        assert!(archive_body(None, Some("a"), Some("*"), Some("s3"), None, None).is_ok());
+        assert!(archive_body(None, Some("a"), Some("*"), None, None, None).is_err());
+        assert!(archive_body(None, Some("a"), Some("*"), Some("ftp"), None, None).is_err());
+        assert!(archive_body(None, None, Some("*"), Some("s3"), None, None).is_err());
+    }
+
+    #[test]
+    fn test_archive_body_from_current() {
+        let current = serde_json::json!({"data": {"type": "archives", "id": "abc", "attributes": {
+            "name": "prod", "query": "*", "state": "WORKING",
+            "destination": {"type": "gcs", "bucket": "b"}
+        }}});
+        let body = archive_body(Some(current), None, Some("env:prod"), None, None, None).unwrap();
+        assert!(body["data"].get("id").is_none());
+        assert!(body["data"]["attributes"].get("state").is_none());
+        assert_eq!(body["data"]["attributes"]["query"], "env:prod");
+        assert_eq!(body["data"]["attributes"]["destination"]["type"], "gcs");
+    }
+
+    #[test]
+    fn test_parse_metric_group_by() {
+        assert_eq!(
+            parse_metric_group_by("@http.status_code, service=svc").unwrap(),
+            vec![
+                serde_json::json!({"path": "@http.status_code", "tag_name": "http.status_code"}),
+                serde_json::json!({"path": "service", "tag_name": "svc"}),
+            ]
+        );
+        assert!(parse_metric_group_by("=tag").is_err());
+    }
+
+    #[test]
+    fn test_log_metric_body_create() {
+        let flags = LogMetricFlags {
+            query: Some("service:api".into()),
+            compute: Some("distribution".into()),
+            path: Some("@duration".into()),
+            group_by: Some("env".into()),
+            include_percentiles: Some(true),
+        };
+        let body = log_metric_body(None, Some("api.latency"), &flags).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({"data": {
+                "type": "logs_metrics",
+                "id": "api.latency",
+                "attributes": {
+                    "filter": {"query": "service:api"},
+                    "group_by": [{"path": "env", "tag_name": "env"}],
+                    "compute": {
+                        "aggregation_type": "distribution",
+                        "path": "@duration",
+                        "include_percentiles": true
+                    }
+                }
+            }})
+        );
+
+        // Count is the default compute type.
+        let body = log_metric_body(None, Some("errors"), &LogMetricFlags::default()).unwrap();
+        assert_eq!(
+            body["data"]["attributes"]["compute"],
+            serde_json::json!({"aggregation_type": "count"})
+        );
+    }
+
+    #[test]
+    fn test_log_metric_body_validation() {
+        let distribution = LogMetricFlags {
+            compute: Some("distribution".into()),
+            ..Default::default()
+        };
+        assert!(log_metric_body(None, Some("m"), &distribution).is_err());
+        let gauge = LogMetricFlags {
+            compute: Some("gauge".into()),
+            ..Default::default()
+        };
+        assert!(log_metric_body(None, Some("m"), &gauge).is_err());
+        let count_percentiles = LogMetricFlags {
+            include_percentiles: Some(true),
+            ..Default::default()
+        };
+        assert!(log_metric_body(None, Some("m"), &count_percentiles).is_err());
+        // Updates can't change what is computed.
+        assert!(log_metric_body(None, None, &distribution).is_err());
+    }
+
+    #[test]
+    fn test_log_metric_body_update() {
+        let flags = LogMetricFlags {
+            query: Some("service:web".into()),
+            ..Default::default()
+        };
+        let body = log_metric_body(None, None, &flags).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({"data": {
+                "type": "logs_metrics",
+                "attributes": {"filter": {"query": "service:web"}}
+            }})
+        );
+    }
+
+    #[test]
+    fn test_move_to_front() {
+        let current: Vec<String> = ["a", "b", "c", "d"].map(String::from).to_vec();
+        let order = move_to_front(&current, &["c".into(), "a".into()], "log pipeline").unwrap();
+        assert_eq!(order, ["c", "a", "b", "d"]);
+        assert_eq!(
+            move_to_front(&current, &[], "log pipeline").unwrap(),
+            current
+        );
+        let err = move_to_front(&current, &["x".into()], "log index").unwrap_err();
+        assert_eq!(err.to_string(), "unknown log index \"x\"");
+        assert!(move_to_front(&current, &["a".into(), "a".into()], "log pipeline").is_err());
+    }
+
+    #[test]
+    fn test_export_format_resolve() {
+        assert_eq!(
+            ExportFormat::resolve(None, "out.ndjson").unwrap(),
+            ExportFormat::Ndjson
+        );
+        assert_eq!(
+            ExportFormat::resolve(None, "OUT.CSV").unwrap(),
+            ExportFormat::Csv
+        );
+        assert_eq!(
+            ExportFormat::resolve(Some("ndjson"), "out.csv").unwrap(),
+            ExportFormat::Ndjson
+        );
+        assert!(ExportFormat::resolve(Some("xml"), "out.xml").is_err());
+    }
+
+    #[test]
+    fn test_export_csv_row() {
+        assert_eq!(
+            export_csv_header(),
+            "timestamp,status,service,host,message,tags,id\n"
+        );
+        let log = serde_json::json!({
+            "id": "AQAA",
+            "type": "log",
+            "attributes": {
+                "timestamp": "2024-01-01T00:00:00Z",
+                "status": "error",
+                "service": "api",
+                "message": "timeout, retrying \"db\"",
+                "tags": ["env:prod", "team:core"]
+            }
+        });
+        assert_eq!(
+            export_csv_row(&log),
+            "2024-01-01T00:00:00Z,error,api,,\"timeout, retrying \"\"db\"\"\",env:prod team:core,AQAA\n"
+        );
+    }
+
+    #[test]
+    fn test_search_plan_chunked() {
+        let plan = search_plan(
+            "service:api",
+            "2024-01-01T00:00:00Z",
+            "2024-01-04T00:00:00Z",
+            500,
+            Some("1d"),
+            None,
+            None,
+        )
+        .unwrap();
+        assert_eq!(plan.endpoints, vec!["POST /api/v2/logs/events/search"]);
+        assert_eq!(plan.requests, Some(RequestCount::AtMost(3)));
+        assert_eq!(
+            plan.pagination.as_deref(),
+            Some(
+                "3 windows of 1d, newest first, each paged until exhausted, \
+                 stopping once 500 logs are collected"
+            )
+        );
+        assert_eq!(plan.from.as_deref(), Some("2024-01-01T00:00:00+00:00"));
+        assert_eq!(plan.to.as_deref(), Some("2024-01-04T00:00:00+00:00"));
+    }
+
+    #[test]
+    fn test_search_plan_storage_tiers() {
+        let plan = search_plan("*", "1h", "now", 100, None, None, Some("flex")).unwrap();
+        assert_eq!(plan.requests, Some(RequestCount::Exactly(1)));
+        assert!(plan
+            .notes
+            .iter()
+            .any(|n| n == "searches the flex storage tier"));
+
+        let plan = search_plan(
+            "*",
+            "3d",
+            "now",
+            100,
+            Some("1d"),
+            None,
+            Some("all-parallel"),
+        )
+        .unwrap();
+        assert_eq!(plan.requests, Some(RequestCount::AtMost(9)));
+        assert!(search_plan("*", "1h", "now", 100, None, None, Some("cold")).is_err());
+    }
+
+    #[test]
+    fn test_storage_tiers() {
+        assert_eq!(storage_tiers(None).unwrap(), vec![None]);
+        assert_eq!(
+            storage_tiers(Some("online-archives")).unwrap(),
+            vec![Some("online-archives")]
+        );
+        assert_eq!(
+            storage_tiers(Some("all-parallel")).unwrap(),
+            vec![Some("indexes"), Some("online-archives"), Some("flex")]
+        );
+        assert!(storage_tiers(Some("all")).is_err());
+    }
+
+    #[test]
+    fn test_merge_tier_results() {
+        let log =
+            |id: &str, ts: &str| serde_json::json!({"id": id, "attributes": {"timestamp": ts}});
+        let merged = merge_tier_results(
+            vec![
+                (
+                    "indexes",
+                    vec![
+                        log("a", "2024-01-01T00:03:00Z"),
+                        log("b", "2024-01-01T00:01:00Z"),
+                    ],
+                ),
+                ("flex", vec![log("c", "2024-01-01T00:02:00Z")]),
+                ("online-archives", vec![]),
+            ],
+            2,
+        );
+        let ids: Vec<&str> = merged.iter().map(|l| l["id"].as_str().unwrap()).collect();
+        assert_eq!(ids, ["a", "c"]);
+        assert_eq!(merged[0]["storage_tier"], "indexes");
+        assert_eq!(merged[1]["storage_tier"], "flex");
+    }
+
+    #[test]
+    fn test_search_plan_single_page_and_export() {
+        let single = search_plan("*", "1h", "now", 100, None, None, None).unwrap();
+        assert_eq!(single.requests, Some(RequestCount::Exactly(1)));
+
+        let export = search_plan("*", "7d", "now", 100, None, Some("out.ndjson"), None).unwrap();
+        assert!(matches!(export.requests, Some(RequestCount::Unbounded(_))));
+        assert!(export.pagination.unwrap().contains("out.ndjson"));
+
+        assert!(search_plan("*", "1h", "now", 100, Some("bogus"), None, None).is_err());
+    }
+
+    #[test]
+    fn test_aggregate_computes_percentiles() {
+        let computes = aggregate_computes(&[], Some("50, 90,95,99"), Some("@duration")).unwrap();
+        let d = Some("@duration".to_string());
+        assert_eq!(
+            computes,
+            vec![
+                ("median".to_string(), d.clone()),
+                ("pc90".to_string(), d.clone()),
+                ("pc95".to_string(), d.clone()),
+                ("pc99".to_string(), d),
+            ]
+        );
+
+        let computes =
+            aggregate_computes(&["count".into()], Some("99"), Some("@duration")).unwrap();
+        assert_eq!(computes[0], ("count".to_string(), None));
+        assert_eq!(computes[1].0, "pc99");
+
+        assert_eq!(
+            aggregate_computes(&[], None, None).unwrap(),
+            vec![("count".to_string(), None)]
+        );
+    }
+
+    #[test]
+    fn test_aggregate_computes_validation() {
+        assert!(aggregate_computes(&[], Some("90"), None).is_err());
+        assert!(aggregate_computes(&[], Some("90"), Some(" ")).is_err());
+        assert!(aggregate_computes(&[], None, Some("@duration")).is_err());
+        assert!(aggregate_computes(&[], Some("42"), Some("@duration")).is_err());
+        assert!(aggregate_computes(&[], Some("p99"), Some("@duration")).is_err());
+        assert!(aggregate_computes(&["bogus(@x)".into()], None, None).is_err());
+    }
+
+    #[test]
+    fn test_message_template() {
+        assert_eq!(
+            message_template("GET /api/users/42 took 130ms status=500 user=alice"),
+            vec!["GET", "<*>", "took", "<*>", "status=<*>", "user=alice"]
+        );
+        assert_eq!(
+            message_template("panic: boom\n  at main.rs:10"),
+            vec!["panic:", "boom"]
+        );
+        assert!(message_template("").is_empty());
+    }
+
+    #[test]
+    fn test_log_patterns_clusters_and_ranks() {
+        let log = |service: &str, status: &str, message: &str| {
+            serde_json::json!({"attributes": {
+                "service": service, "status": status, "message": message
+            }})
+        };
+        let logs = vec![
+            log("api", "error", "Timeout calling payments after 3000ms"),
+            log("api", "error", "Timeout calling payments after 5000ms"),
+            log("web", "error", "Timeout calling ledger after 3000ms"),
+            log("web", "info", "Connection refused"),
+            log("worker", "info", "Connection reset"),
+        ];
+        let report = log_patterns(&logs, 10);
+        assert_eq!(
+            report["meta"],
+            serde_json::json!({"logs": 5, "patterns": 3})
+        );
+        let first = &report["data"][0];
+        assert_eq!(first["pattern"], "Timeout calling <*> after <*>");
+        assert_eq!(first["count"], 3);
+        assert_eq!(first["percent"], 60.0);
+        assert_eq!(first["services"], "api (2), web (1)");
+        assert_eq!(first["status"], "error");
+        assert_eq!(first["example"], "Timeout calling payments after 3000ms");
+        // Two-token messages differing in a word stay separate patterns.
+        assert_eq!(report["data"][1]["count"], 1);
+        assert_eq!(report["data"][2]["count"], 1);
+
+        let top = log_patterns(&logs, 1);
+        assert_eq!(top["data"].as_array().unwrap().len(), 1);
+        assert_eq!(top["meta"]["patterns"], 3);
+    }
+
+    #[test]
+    fn test_log_patterns_first_token_stays_literal() {
+        let log = |message: &str| serde_json::json!({"attributes": {"message": message}});
+        let logs = vec![log("user login failed"), log("admin login failed")];
+        let report = log_patterns(&logs, 10);
+        assert_eq!(report["meta"]["patterns"], 2);
+        assert_eq!(report["data"][0]["status"], "-");
+        assert_eq!(
+            log_patterns(&[log("")], 10)["data"][0]["pattern"],
+            "(no message)"
+        );
+    }
+
+    #[test]
+    fn test_restriction_query_bodies() {
+        assert_eq!(
+            restriction_query_body(" env:prod ").unwrap(),
+            serde_json::json!({"data": {
+                "type": "logs_restriction_queries",
+                "attributes": {"restriction_query": "env:prod"}
+            }})
+        );
+        assert!(restriction_query_body("  ").is_err());
+        assert_eq!(
+            restriction_role_body("r-1").unwrap(),
+            serde_json::json!({"data": {"type": "roles", "id": "r-1"}})
+        );
+        assert!(restriction_role_body("").is_err());
+    }
+
+    #[test]
+    fn test_aggregate_group_by() {
+        assert_eq!(
+            aggregate_group_by(&["service".into(), " status ".into()]).unwrap(),
+            vec!["service", "status"]
+        );
+        assert!(aggregate_group_by(&[]).unwrap().is_empty());
+        assert!(aggregate_group_by(&[" ".into()]).is_err());
+        assert!(aggregate_group_by(&["service".into(), "service".into()]).is_err());
+    }
+
+    #[test]
+    fn test_aggregate_interval() {
+        assert_eq!(aggregate_interval(None).unwrap(), None);
+        assert_eq!(
+            aggregate_interval(Some(" 5m ")).unwrap(),
+            Some("5m".to_string())
+        );
+        assert_eq!(aggregate_interval(Some("1h")).unwrap(), Some("1h".into()));
+        assert!(aggregate_interval(Some("0s")).is_err());
+        assert!(aggregate_interval(Some("soon")).is_err());
+    }
+
+    #[test]
+    fn test_metric_volume_helpers() {
+        let metric = serde_json::json!({"data": {"id": "m1", "attributes": {
+            "filter": {"query": "service:web status:error"}
+        }}});
+        assert_eq!(metric_filter_query(&metric), "service:web status:error");
+        assert_eq!(metric_filter_query(&serde_json::json!({"data": {}})), "*");
+    }
+
+    #[test]
+    fn test_highlight_terms_skips_facets() {
+        assert_eq!(
+            highlight_terms("service:web status:error timeout"),
+            vec!["timeout"]
+        );
+        assert_eq!(
+            highlight_terms(r#"@http.status_code:500 env:"prod us" refused"#),
+            vec!["refused"]
+        );
+    }
+
+    #[test]
+    fn test_highlight_terms_phrases_and_operators() {
+        assert_eq!(
+            highlight_terms(r#"("connection reset" OR timeout*) AND -debug"#),
+            vec!["connection reset", "timeout"]
+        );
+    }
+
+    #[test]
+    fn test_highlight_terms_empty_and_wildcard() {
+        assert!(highlight_terms("*").is_empty());
+        assert!(highlight_terms("").is_empty());
+        assert_eq!(highlight_terms("oom oom"), vec!["oom"]);
+    }
+
+    #[test]
+    fn test_custom_destination_body_from_flags_and_file() {
+        let file = serde_json::json!({"data": {"attributes": {
+            "name": "old",
+            "forwarder_destination": {"type": "http", "endpoint": "https://example.com"}
+        }}});
+        let body = custom_destination_body(
+            Some(file),
+            None,
+            Some("forwarder"),
+            Some("service:api"),
+            Some("splunk"),
+            Some(false),
+        )
+        .unwrap();
+        let attrs = &body["data"]["attributes"];
+        assert_eq!(body["data"]["type"], "custom_destination");
+        assert_eq!(attrs["name"], "forwarder");
+        assert_eq!(attrs["query"], "service:api");
+        assert_eq!(attrs["enabled"], false);
+        assert_eq!(attrs["forwarder_destination"]["type"], "splunk_hec");
+        assert_eq!(
+            attrs["forwarder_destination"]["endpoint"],
+            "https://example.com"
+        );
+    }
+
+    #[test]
+    fn test_custom_destination_body_update_is_partial() {
+        let body =
+            custom_destination_body(None, Some("abc"), None, None, None, Some(true)).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!({"data": {
+                "type": "custom_destination",
+                "id": "abc",
+                "attributes": {"enabled": true}
+            }})
+        );
+    }
+
+    #[test]
+    fn test_with_http_forwarder() {
+        let http = HttpForwarder {
+            endpoint: "https://logs.example.com/ingest".into(),
+            header_name: Some("X-Token".into()),
+            header_value: Some("secret".into()),
+            ..Default::default()
+        };
+        let base = with_http_forwarder(None, &http).unwrap();
+        let body =
+            custom_destination_body(Some(base), None, Some("ingest"), None, None, None).unwrap();
+        assert_eq!(
+            body["data"]["attributes"]["forwarder_destination"],
+            serde_json::json!({
+                "type": "http",
+                "endpoint": "https://logs.example.com/ingest",
+                "auth": {"type": "custom_header", "header_name": "X-Token", "header_value": "secret"}
+            })
+        );
+
+        let basic = HttpForwarder {
+            endpoint: "https://logs.example.com".into(),
+            username: Some("u".into()),
+            password: Some("p".into()),
+            ..Default::default()
+        };
+        let body = with_http_forwarder(None, &basic).unwrap();
+        assert_eq!(
+            body["data"]["attributes"]["forwarder_destination"]["auth"]["type"],
+            "basic"
+        );
+
+        let no_auth = HttpForwarder {
+            endpoint: "https://logs.example.com".into(),
+            ..Default::default()
+        };
+        assert!(with_http_forwarder(None, &no_auth).is_err());
+        let plain = HttpForwarder {
+            endpoint: "http://logs.example.com".into(),
+            ..basic
+        };
+        assert!(with_http_forwarder(None, &plain).is_err());
+    }
+
+    #[test]
+    fn test_custom_destination_body_validation() {
+        let err = custom_destination_body(None, Some("abc"), None, None, Some("kafka"), None)
+            .unwrap_err();
+        assert!(err
+            .to_string()
+            .contains("invalid destination type \"kafka\""));
+        let err = custom_destination_body(None, None, None, None, None, None).unwrap_err();
+        assert!(err.to_string().contains("requires a name"));
+        let err = custom_destination_body(None, None, Some("x"), None, None, None).unwrap_err();
+        assert!(err.to_string().contains("forwarder_destination"));
+        let err =
+            custom_destination_body(Some(serde_json::json!([])), None, None, None, None, None)
+                .unwrap_err();
+        assert!(err.to_string().contains("\"data\" object"));
+    }
 }
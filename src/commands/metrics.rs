@@ -1,4 +1,4 @@
-use anyhow::Result;
+use anyhow::{bail, Result};
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::api_metrics::{
     ListActiveMetricsOptionalParams, MetricsAPI as MetricsV1API,
@@ -6,7 +6,9 @@ use datadog_api_client::datadogV1::api_metrics::{
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::model::MetricMetadata;
 #[cfg(not(target_arch = "wasm32"))]
-use datadog_api_client::datadogV2::api_metrics::MetricsAPI as MetricsV2API;
+use datadog_api_client::datadogV2::api_metrics::{
+    ListTagConfigurationsOptionalParams, MetricsAPI as MetricsV2API,
+};
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV2::model::MetricPayload;
 
@@ -16,15 +18,36 @@ use crate::config::Config;
 use crate::formatter;
 use crate::util;
 
+/// Longest lookback accepted for the active metrics listing. The endpoint only
+/// tracks recently reporting metrics, so wider windows are refused up front.
+pub const MAX_ACTIVE_SINCE_SECS: i64 = 30 * 24 * 60 * 60;
+
+/// Resolves `--active-since` (relative like `1d` or absolute) to the `from`
+/// timestamp, in Unix seconds, sent to the active metrics endpoint.
+pub fn active_since_from(active_since: &str) -> Result<i64> {
+    let from = util::parse_time_to_unix(active_since)?;
+    let now = chrono::Utc::now().timestamp();
+    if from > now {
+        bail!("--active-since must be in the past: {active_since}");
+    }
+    if now - from > MAX_ACTIVE_SINCE_SECS {
+        bail!(
+            "--active-since {active_since} looks back more than {} days; the active metrics endpoint only covers recent data",
+            MAX_ACTIVE_SINCE_SECS / 86400
+        );
+    }
+    Ok(from)
+}
+
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn list(cfg: &Config, filter: Option<String>, from: String) -> Result<()> {
+pub async fn list(cfg: &Config, filter: Option<String>, active_since: String) -> Result<()> {
     let dd_cfg = client::make_dd_config(cfg);
     let api = match client::make_bearer_client(cfg) {
         Some(c) => MetricsV1API::with_client_and_config(dd_cfg, c),
         None => MetricsV1API::with_config(dd_cfg),
     };
 
-    let from_ts = util::parse_time_to_unix(&from)?;
+    let from_ts = active_since_from(&active_since)?;
     let params = ListActiveMetricsOptionalParams::default();
 
     let resp = api
@@ -48,8 +71,8 @@ pub async fn list(cfg: &Config, filter: Option<String>, from: String) -> Result<
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn list(cfg: &Config, filter: Option<String>, from: String) -> Result<()> {
-    let from_ts = util::parse_time_to_unix(&from)?;
+pub async fn list(cfg: &Config, filter: Option<String>, active_since: String) -> Result<()> {
+    let from_ts = active_since_from(&active_since)?;
     let query_params = vec![("from", from_ts.to_string())];
     let data = crate::api::get(cfg, "/api/v2/metrics", &query_params).await?;
 
@@ -149,6 +172,119 @@ pub async fn query(cfg: &Config, query: String, from: String, to: String) -> Res
     crate::formatter::output(cfg, &data)
 }
 
+/// Reducers accepted by the scalar query endpoint.
+pub const SCALAR_AGGREGATORS: &[&str] = &[
+    "avg",
+    "min",
+    "max",
+    "sum",
+    "last",
+    "mean",
+    "area",
+    "l2norm",
+    "percentile",
+];
+
+/// Queries one reduced number per group through the v2 scalar endpoint and
+/// prints it as rows of group tags plus `value`.
+pub async fn scalar(
+    cfg: &Config,
+    query: String,
+    from: String,
+    to: String,
+    aggregator: String,
+) -> Result<()> {
+    let body = scalar_request(&query, &from, &to, &aggregator)?;
+    let resp = scalar_query(cfg, body).await?;
+    formatter::output(cfg, &scalar_rows(&resp))
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn scalar_query(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    use datadog_api_client::datadogV2::model::ScalarFormulaQueryRequest;
+
+    let body: ScalarFormulaQueryRequest = serde_json::from_value(body)?;
+    let resp = v2_api(cfg)
+        .query_scalar_data(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to query scalar data: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn scalar_query(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v2/query/scalar", &body).await
+}
+
+fn scalar_request(
+    query: &str,
+    from: &str,
+    to: &str,
+    aggregator: &str,
+) -> Result<serde_json::Value> {
+    if !SCALAR_AGGREGATORS.contains(&aggregator) {
+        bail!(
+            "invalid aggregator {aggregator:?}: expected one of {}",
+            SCALAR_AGGREGATORS.join(", ")
+        );
+    }
+    let from_ms = util::parse_time_to_unix_millis(from)?;
+    let to_ms = util::parse_time_to_unix_millis(to)?;
+    if from_ms >= to_ms {
+        bail!("--from must be before --to");
+    }
+    Ok(serde_json::json!({
+        "data": {
+            "type": "scalar_request",
+            "attributes": {
+                "from": from_ms,
+                "to": to_ms,
+                "queries": [{
+                    "data_source": "metrics",
+                    "name": "a",
+                    "query": query,
+                    "aggregator": aggregator,
+                }],
+                "formulas": [{"formula": "a"}],
+            }
+        }
+    }))
+}
+
+/// Flattens a scalar response's columns into one row per group: each group
+/// column becomes a key (multi-value tags joined with ","), then `value`.
+fn scalar_rows(resp: &serde_json::Value) -> Vec<serde_json::Value> {
+    let columns = resp["data"]["attributes"]["columns"]
+        .as_array()
+        .cloned()
+        .unwrap_or_default();
+    let (groups, numbers): (Vec<_>, Vec<_>) = columns.iter().partition(|c| c["type"] == "group");
+    let Some(values) = numbers.first().and_then(|c| c["values"].as_array()) else {
+        return Vec::new();
+    };
+    values
+        .iter()
+        .enumerate()
+        .map(|(i, value)| {
+            let mut row = serde_json::Map::new();
+            for group in &groups {
+                let name = group["name"].as_str().unwrap_or("group");
+                let tag = match &group["values"][i] {
+                    serde_json::Value::Array(parts) => parts
+                        .iter()
+                        .map(|p| p.as_str().map_or_else(|| p.to_string(), String::from))
+                        .collect::<Vec<_>>()
+                        .join(","),
+                    other => other.as_str().unwrap_or_default().to_string(),
+                };
+                row.insert(name.to_string(), serde_json::json!(tag));
+            }
+            row.insert("value".into(), value.clone());
+            serde_json::Value::Object(row)
+        })
+        .collect()
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn metadata_update(cfg: &Config, metric_name: &str, file: &str) -> Result<()> {
     let dd_cfg = client::make_dd_config(cfg);
@@ -172,29 +308,273 @@ pub async fn metadata_update(cfg: &Config, metric_name: &str, file: &str) -> Res
     crate::formatter::output(cfg, &data)
 }
 
-#[cfg(not(target_arch = "wasm32"))]
+/// Largest `POST /api/v2/series` payload the intake accepts. Chunks are
+/// sized on the uncompressed JSON, so they fit whether or not gzip helps.
+const MAX_SERIES_PAYLOAD_BYTES: usize = 512_000;
+
+/// Submits the series in `file` (`-` for stdin), split into as few intake
+/// requests as the payload limit allows.
 pub async fn submit(cfg: &Config, file: &str) -> Result<()> {
+    let series = submit_series(util::read_json_input(file)?)?;
+    submit_all(cfg, series).await
+}
+
+/// Metric type names accepted by flag-based `metrics submit`.
+pub const SUBMIT_METRIC_TYPES: &[&str] = &["gauge", "count", "rate", "distribution"];
+
+/// Flags for a single-metric `metrics submit`.
+pub struct SubmitPoint {
+    pub name: String,
+    /// One value, or several samples for a distribution.
+    pub values: Vec<f64>,
+    pub metric_type: String,
+    pub tags: Option<String>,
+    pub host: Option<String>,
+    /// Seconds covered by a count or rate value; 0 leaves it unset.
+    pub interval: i64,
+    pub timestamp: String,
+}
+
+/// Submits one point from flags. Distributions go to the v1 distribution
+/// points intake, since v2 series cannot carry raw samples.
+pub async fn submit_point(cfg: &Config, point: SubmitPoint) -> Result<()> {
+    if point.metric_type == "distribution" {
+        let body = distribution_payload(&point)?;
+        let data = submit_distribution(cfg, body).await?;
+        return formatter::output(cfg, &data);
+    }
+    submit_all(cfg, vec![point_series(&point)?]).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn submit_distribution(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    use datadog_api_client::datadogV1::api_metrics::SubmitDistributionPointsOptionalParams;
+    use datadog_api_client::datadogV1::model::DistributionPointsPayload;
+
     let dd_cfg = client::make_dd_config(cfg);
     let api = match client::make_bearer_client(cfg) {
-        Some(c) => MetricsV2API::with_client_and_config(dd_cfg, c),
-        None => MetricsV2API::with_config(dd_cfg),
+        Some(c) => MetricsV1API::with_client_and_config(dd_cfg, c),
+        None => MetricsV1API::with_config(dd_cfg),
     };
-    let body: MetricPayload = util::read_json_file(file)?;
+    let body: DistributionPointsPayload = serde_json::from_value(body)?;
     let resp = api
-        .submit_metrics(
-            body,
-            datadog_api_client::datadogV2::api_metrics::SubmitMetricsOptionalParams::default(),
-        )
+        .submit_distribution_points(body, SubmitDistributionPointsOptionalParams::default())
         .await
-        .map_err(|e| anyhow::anyhow!("failed to submit metrics: {e:?}"))?;
-    formatter::output(cfg, &resp)
+        .map_err(|e| anyhow::anyhow!("failed to submit distribution points: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn submit(cfg: &Config, file: &str) -> Result<()> {
-    let body: serde_json::Value = util::read_json_file(file)?;
-    let data = crate::api::post(cfg, "/api/v2/series", &body).await?;
-    crate::formatter::output(cfg, &data)
+async fn submit_distribution(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v1/distribution_points", &body).await
+}
+
+/// Validates `--type`/`--value` and resolves the point's timestamp.
+fn point_timestamp(point: &SubmitPoint) -> Result<i64> {
+    if !SUBMIT_METRIC_TYPES.contains(&point.metric_type.as_str()) {
+        bail!(
+            "invalid metric type {:?}: expected one of {}",
+            point.metric_type,
+            SUBMIT_METRIC_TYPES.join(", ")
+        );
+    }
+    if point.values.is_empty() {
+        bail!("--value is required");
+    }
+    if point.metric_type != "distribution" && point.values.len() > 1 {
+        bail!(
+            "{} metrics take a single --value; repeat --value only with --type=distribution",
+            point.metric_type
+        );
+    }
+    if let Some(v) = point.values.iter().find(|v| !v.is_finite()) {
+        bail!("invalid value {v}: must be a finite number");
+    }
+    util::parse_time_to_unix(&point.timestamp)
+}
+
+fn point_tags(point: &SubmitPoint) -> Vec<&str> {
+    point
+        .tags
+        .as_deref()
+        .unwrap_or_default()
+        .split(',')
+        .map(str::trim)
+        .filter(|t| !t.is_empty())
+        .collect()
+}
+
+/// A v2 series for a gauge, count, or rate point.
+fn point_series(point: &SubmitPoint) -> Result<serde_json::Value> {
+    let timestamp = point_timestamp(point)?;
+    let type_id = match point.metric_type.as_str() {
+        "count" => 1,
+        "rate" => 2,
+        _ => 3,
+    };
+    let mut series = serde_json::json!({
+        "metric": point.name,
+        "type": type_id,
+        "points": [{"timestamp": timestamp, "value": point.values[0]}],
+        "tags": point_tags(point),
+    });
+    if let Some(host) = &point.host {
+        series["resources"] = serde_json::json!([{"name": host, "type": "host"}]);
+    }
+    if point.interval > 0 {
+        series["interval"] = serde_json::json!(point.interval);
+    }
+    Ok(series)
+}
+
+/// A v1 distribution points payload holding every `--value` sample.
+fn distribution_payload(point: &SubmitPoint) -> Result<serde_json::Value> {
+    let timestamp = point_timestamp(point)?;
+    let mut series = serde_json::json!({
+        "metric": point.name,
+        "type": "distribution",
+        "points": [[timestamp, point.values]],
+        "tags": point_tags(point),
+    });
+    if let Some(host) = &point.host {
+        series["host"] = serde_json::json!(host);
+    }
+    Ok(serde_json::json!({ "series": [series] }))
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn submit_all(cfg: &Config, series: Vec<serde_json::Value>) -> Result<()> {
+    use datadog_api_client::datadogV2::api_metrics::SubmitMetricsOptionalParams;
+    use datadog_api_client::datadogV2::model::MetricContentEncoding;
+
+    let total = series.len();
+    let payloads = series_payloads(series, MAX_SERIES_PAYLOAD_BYTES)?;
+    let api = v2_api(cfg);
+    let mut errors = Vec::new();
+    let mut sent = 0;
+    for (i, payload) in payloads.iter().enumerate() {
+        let count = payload["series"].as_array().map_or(0, Vec::len);
+        let body: MetricPayload = serde_json::from_value(payload.clone())?;
+        let resp = api
+            .submit_metrics(
+                body,
+                SubmitMetricsOptionalParams::default()
+                    .content_encoding(MetricContentEncoding::GZIP),
+            )
+            .await
+            .map_err(|e| submit_error(i, payloads.len(), sent, e))?;
+        errors.extend(intake_errors(&serde_json::to_value(&resp)?));
+        sent += count;
+    }
+    formatter::output(cfg, &submit_summary(total, payloads.len(), errors))
+}
+
+/// The browser and WASI builds have no gzip encoder, so chunks go out
+/// uncompressed; they are sized for that anyway.
+#[cfg(target_arch = "wasm32")]
+async fn submit_all(cfg: &Config, series: Vec<serde_json::Value>) -> Result<()> {
+    let total = series.len();
+    let payloads = series_payloads(series, MAX_SERIES_PAYLOAD_BYTES)?;
+    let mut errors = Vec::new();
+    let mut sent = 0;
+    for (i, payload) in payloads.iter().enumerate() {
+        let count = payload["series"].as_array().map_or(0, Vec::len);
+        let resp = crate::api::post(cfg, "/api/v2/series", payload)
+            .await
+            .map_err(|e| submit_error(i, payloads.len(), sent, e))?;
+        errors.extend(intake_errors(&resp));
+        sent += count;
+    }
+    formatter::output(cfg, &submit_summary(total, payloads.len(), errors))
+}
+
+/// The series in a submit file: either a `{"series": [...]}` payload or a
+/// bare array of series.
+fn submit_series(input: serde_json::Value) -> Result<Vec<serde_json::Value>> {
+    let series = match input {
+        serde_json::Value::Array(series) => series,
+        serde_json::Value::Object(mut payload) => match payload.remove("series") {
+            Some(serde_json::Value::Array(series)) => series,
+            _ => bail!("expected a \"series\" array in the metrics file"),
+        },
+        _ => bail!("expected an array of series or a {{\"series\": [...]}} object"),
+    };
+    if series.is_empty() {
+        bail!("no series to submit");
+    }
+    if let Some(i) = series.iter().position(|s| !s.is_object()) {
+        bail!("series {} is not an object", i + 1);
+    }
+    Ok(series)
+}
+
+/// Packs series, in order, into `{"series": [...]}` payloads whose
+/// serialized size stays within `max_bytes`.
+fn series_payloads(
+    series: Vec<serde_json::Value>,
+    max_bytes: usize,
+) -> Result<Vec<serde_json::Value>> {
+    // `{"series":[` + `]}`
+    const ENVELOPE: usize = 13;
+
+    let mut payloads = Vec::new();
+    let mut chunk: Vec<serde_json::Value> = Vec::new();
+    let mut size = ENVELOPE;
+    for (i, s) in series.into_iter().enumerate() {
+        let len = serde_json::to_string(&s)?.len();
+        if ENVELOPE + len > max_bytes {
+            let name = s["metric"].as_str().unwrap_or("?");
+            bail!(
+                "series {} ({name}) is {len} bytes, over the {max_bytes}-byte intake limit; \
+                 split its points across several series",
+                i + 1
+            );
+        }
+        if !chunk.is_empty() && size + 1 + len > max_bytes {
+            payloads.push(serde_json::json!({ "series": std::mem::take(&mut chunk) }));
+            size = ENVELOPE;
+        }
+        if !chunk.is_empty() {
+            size += 1;
+        }
+        size += len;
+        chunk.push(s);
+    }
+    if !chunk.is_empty() {
+        payloads.push(serde_json::json!({ "series": chunk }));
+    }
+    Ok(payloads)
+}
+
+fn submit_error(
+    index: usize,
+    requests: usize,
+    sent: usize,
+    err: impl std::fmt::Debug,
+) -> anyhow::Error {
+    if requests == 1 {
+        return anyhow::anyhow!("failed to submit metrics: {err:?}");
+    }
+    anyhow::anyhow!(
+        "failed to submit metrics (request {} of {requests}, {sent} series already accepted): {err:?}",
+        index + 1
+    )
+}
+
+fn intake_errors(resp: &serde_json::Value) -> Vec<serde_json::Value> {
+    resp["errors"].as_array().cloned().unwrap_or_default()
+}
+
+fn submit_summary(
+    series: usize,
+    requests: usize,
+    errors: Vec<serde_json::Value>,
+) -> serde_json::Value {
+    serde_json::json!({
+        "series": series,
+        "requests": requests,
+        "errors": errors,
+    })
 }
 
 #[cfg(not(target_arch = "wasm32"))]
@@ -222,3 +602,1106 @@ pub async fn tags_list(cfg: &Config, metric_name: &str) -> Result<()> {
     let data = crate::api::get(cfg, &path, &[]).await?;
     crate::formatter::output(cfg, &data)
 }
+
+/// Metric types a tag configuration can be created for.
+const TAG_CONFIG_METRIC_TYPES: &[&str] = &["gauge", "count", "rate", "distribution"];
+
+/// Flags for `metrics tag-config create` and `update`.
+#[derive(Clone, Default)]
+pub struct TagConfigOptions {
+    /// Comma-separated tags to keep queryable.
+    pub tags: Option<String>,
+    /// Comma-separated aggregations, each `time:space` or a single space
+    /// aggregation (see `parse_aggregation`).
+    pub aggregations: Option<String>,
+    pub metric_type: Option<String>,
+    pub include_percentiles: Option<bool>,
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+fn v2_api(cfg: &Config) -> MetricsV2API {
+    let dd_cfg = client::make_dd_config(cfg);
+    match client::make_bearer_client(cfg) {
+        Some(c) => MetricsV2API::with_client_and_config(dd_cfg, c),
+        None => MetricsV2API::with_config(dd_cfg),
+    }
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn tag_config_get(cfg: &Config, metric_name: &str) -> Result<()> {
+    let resp = v2_api(cfg)
+        .list_tag_configuration_by_name(metric_name.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get tag configuration for {metric_name}: {e:?}"))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn tag_config_get(cfg: &Config, metric_name: &str) -> Result<()> {
+    let path = format!("/api/v2/metrics/{metric_name}/tags");
+    let data = crate::api::get(cfg, &path, &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Creates a tag configuration. Without `--metric-type` the type is taken
+/// from the metric's metadata, since the API requires it.
+pub async fn tag_config_create(
+    cfg: &Config,
+    metric_name: &str,
+    opts: TagConfigOptions,
+) -> Result<()> {
+    let data = create_tag_config(cfg, metric_name, opts).await?;
+    formatter::output(cfg, &data)
+}
+
+pub async fn tag_config_update(
+    cfg: &Config,
+    metric_name: &str,
+    opts: TagConfigOptions,
+) -> Result<()> {
+    ensure_update_fields(&opts)?;
+    let data = update_tag_config(cfg, metric_name, opts).await?;
+    formatter::output(cfg, &data)
+}
+
+pub async fn tag_config_delete(cfg: &Config, metric_name: &str) -> Result<()> {
+    delete_tag_config(cfg, metric_name).await?;
+    formatter::print_block(&format!("Tag configuration for {metric_name} deleted.\n"))?;
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn create_tag_config(
+    cfg: &Config,
+    metric_name: &str,
+    mut opts: TagConfigOptions,
+) -> Result<serde_json::Value> {
+    use datadog_api_client::datadogV2::model::MetricTagConfigurationCreateRequest;
+
+    if opts.metric_type.is_none() {
+        let dd_cfg = client::make_dd_config(cfg);
+        let v1 = match client::make_bearer_client(cfg) {
+            Some(c) => MetricsV1API::with_client_and_config(dd_cfg, c),
+            None => MetricsV1API::with_config(dd_cfg),
+        };
+        let metadata = v1
+            .get_metric_metadata(metric_name.to_string())
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to get metric metadata: {e:?}"))?;
+        opts.metric_type = metadata_type(&serde_json::to_value(&metadata)?, metric_name)?;
+    }
+    let body: MetricTagConfigurationCreateRequest =
+        serde_json::from_value(tag_config_body(metric_name, &opts)?)?;
+    let resp = v2_api(cfg)
+        .create_tag_configuration(metric_name.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create tag configuration: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn create_tag_config(
+    cfg: &Config,
+    metric_name: &str,
+    mut opts: TagConfigOptions,
+) -> Result<serde_json::Value> {
+    if opts.metric_type.is_none() {
+        let metadata = crate::api::get(cfg, &format!("/api/v1/metrics/{metric_name}"), &[]).await?;
+        opts.metric_type = metadata_type(&metadata, metric_name)?;
+    }
+    let body = tag_config_body(metric_name, &opts)?;
+    let path = format!("/api/v2/metrics/{metric_name}/tags");
+    crate::api::post(cfg, &path, &body).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn update_tag_config(
+    cfg: &Config,
+    metric_name: &str,
+    opts: TagConfigOptions,
+) -> Result<serde_json::Value> {
+    use datadog_api_client::datadogV2::model::MetricTagConfigurationUpdateRequest;
+
+    let body: MetricTagConfigurationUpdateRequest =
+        serde_json::from_value(tag_config_body(metric_name, &opts)?)?;
+    let resp = v2_api(cfg)
+        .update_tag_configuration(metric_name.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update tag configuration: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn update_tag_config(
+    cfg: &Config,
+    metric_name: &str,
+    opts: TagConfigOptions,
+) -> Result<serde_json::Value> {
+    let body = tag_config_body(metric_name, &opts)?;
+    let path = format!("/api/v2/metrics/{metric_name}/tags");
+    crate::api::patch(cfg, &path, &body).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn delete_tag_config(cfg: &Config, metric_name: &str) -> Result<()> {
+    v2_api(cfg)
+        .delete_tag_configuration(metric_name.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete tag configuration: {e:?}"))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn delete_tag_config(cfg: &Config, metric_name: &str) -> Result<()> {
+    crate::api::delete(cfg, &format!("/api/v2/metrics/{metric_name}/tags")).await?;
+    Ok(())
+}
+
+/// What a bulk `tag-config` run does to each metric matching `--filter`.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum TagConfigAction {
+    Create,
+    Update,
+    Delete,
+}
+
+impl TagConfigAction {
+    fn verb(self) -> &'static str {
+        match self {
+            TagConfigAction::Create => "create tag configurations for",
+            TagConfigAction::Update => "update tag configurations of",
+            TagConfigAction::Delete => "delete tag configurations of",
+        }
+    }
+
+    fn done(self) -> &'static str {
+        match self {
+            TagConfigAction::Create => "created",
+            TagConfigAction::Update => "updated",
+            TagConfigAction::Delete => "deleted",
+        }
+    }
+
+    /// Only unconfigured metrics can get a new configuration, and only
+    /// configured ones have one to update or delete.
+    fn wants_configured(self) -> bool {
+        self != TagConfigAction::Create
+    }
+}
+
+/// Per-metric outcome of a bulk `tag-config` run.
+#[derive(Debug, serde::Serialize)]
+struct TagConfigResult {
+    metric: String,
+    status: &'static str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+/// Applies `action` to every metric whose name matches `pattern` (`*`
+/// matches any run of characters), after listing the matches and asking
+/// for confirmation.
+pub async fn tag_config_matching(
+    cfg: &Config,
+    pattern: &str,
+    action: TagConfigAction,
+    opts: TagConfigOptions,
+) -> Result<()> {
+    // Surface flag errors before listing metrics or prompting.
+    match action {
+        TagConfigAction::Create => {
+            tag_config_body(pattern, &opts)?;
+        }
+        TagConfigAction::Update => {
+            ensure_update_fields(&opts)?;
+            tag_config_body(pattern, &opts)?;
+        }
+        TagConfigAction::Delete => {}
+    }
+
+    let names = metric_names(cfg, Some(action.wants_configured())).await?;
+    let matched: Vec<String> = names
+        .into_iter()
+        .filter(|name| name_matches(pattern, name))
+        .collect();
+    if matched.is_empty() {
+        let which = if action.wants_configured() {
+            "with a tag configuration"
+        } else {
+            "without a tag configuration"
+        };
+        formatter::print_status(&format!("No metrics {which} match {pattern:?}."));
+        return Ok(());
+    }
+
+    formatter::print_status(&format!("Matched {} metric(s):", matched.len()));
+    for name in &matched {
+        formatter::print_status(&format!("  {name}"));
+    }
+    if !util::confirm_bulk(cfg, action.verb(), "metrics", matched.len())? {
+        return Ok(());
+    }
+
+    let results = apply_all(cfg, &matched, action, &opts).await;
+    let failed = results.iter().filter(|r| r.error.is_some()).count();
+    formatter::output(cfg, &results)?;
+    if failed > 0 {
+        bail!(
+            "failed to {} {failed} of {} metrics",
+            action.verb(),
+            results.len()
+        );
+    }
+    Ok(())
+}
+
+/// Whether `name` matches `pattern`, where `*` stands for any run of
+/// characters and everything else must match exactly.
+fn name_matches(pattern: &str, name: &str) -> bool {
+    let parts: Vec<&str> = pattern.split('*').collect();
+    let (first, rest) = parts.split_first().expect("split yields at least one part");
+    let Some(mut remaining) = name.strip_prefix(first) else {
+        return false;
+    };
+    let Some((last, middle)) = rest.split_last() else {
+        return remaining.is_empty();
+    };
+    for part in middle {
+        match remaining.find(part) {
+            Some(at) => remaining = &remaining[at + part.len()..],
+            None => return false,
+        }
+    }
+    remaining.len() >= last.len() && remaining.ends_with(last)
+}
+
+/// Metrics requested per page of `GET /api/v2/metrics`, the API maximum.
+const METRICS_PAGE_SIZE: i32 = 10_000;
+
+/// Names in a `GET /api/v2/metrics` response, in response order.
+fn metric_names_in(data: &serde_json::Value) -> Vec<String> {
+    data["data"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter_map(|m| m["id"].as_str().map(String::from))
+        .collect()
+}
+
+/// Cursor for the page after this `GET /api/v2/metrics` response, if any.
+fn next_metrics_cursor(data: &serde_json::Value) -> Option<String> {
+    data.pointer("/meta/pagination/next_cursor")
+        .and_then(|v| v.as_str())
+        .filter(|c| !c.is_empty())
+        .map(String::from)
+}
+
+/// Metrics with (`Some(true)`) or without a tag configuration, or all of
+/// them, sorted. Follows the page cursor until every page is read.
+#[cfg(not(target_arch = "wasm32"))]
+async fn metric_names(cfg: &Config, configured: Option<bool>) -> Result<Vec<String>> {
+    let api = v2_api(cfg);
+    let mut names = Vec::new();
+    let mut cursor: Option<String> = None;
+    loop {
+        let mut params =
+            ListTagConfigurationsOptionalParams::default().page_size(METRICS_PAGE_SIZE);
+        if let Some(configured) = configured {
+            params = params.filter_configured(configured);
+        }
+        if let Some(cursor) = cursor {
+            params = params.page_cursor(cursor);
+        }
+        let resp = api
+            .list_tag_configurations(params)
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to list metrics: {e:?}"))?;
+        let data = serde_json::to_value(&resp)?;
+        let page = metric_names_in(&data);
+        if page.is_empty() {
+            break;
+        }
+        names.extend(page);
+        cursor = next_metrics_cursor(&data);
+        if cursor.is_none() {
+            break;
+        }
+    }
+    names.sort();
+    Ok(names)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn metric_names(cfg: &Config, configured: Option<bool>) -> Result<Vec<String>> {
+    let mut names = Vec::new();
+    let mut cursor: Option<String> = None;
+    loop {
+        let mut params: Vec<(&str, String)> = vec![("page[size]", METRICS_PAGE_SIZE.to_string())];
+        if let Some(configured) = configured {
+            params.push(("filter[configured]", configured.to_string()));
+        }
+        if let Some(cursor) = cursor {
+            params.push(("page[cursor]", cursor));
+        }
+        let data = crate::api::get(cfg, "/api/v2/metrics", &params).await?;
+        let page = metric_names_in(&data);
+        if page.is_empty() {
+            break;
+        }
+        names.extend(page);
+        cursor = next_metrics_cursor(&data);
+        if cursor.is_none() {
+            break;
+        }
+    }
+    names.sort();
+    Ok(names)
+}
+
+async fn apply_one(
+    cfg: &Config,
+    metric_name: &str,
+    action: TagConfigAction,
+    opts: TagConfigOptions,
+) -> Result<()> {
+    match action {
+        TagConfigAction::Create => create_tag_config(cfg, metric_name, opts).await.map(drop),
+        TagConfigAction::Update => update_tag_config(cfg, metric_name, opts).await.map(drop),
+        TagConfigAction::Delete => delete_tag_config(cfg, metric_name).await,
+    }
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn apply_all(
+    cfg: &Config,
+    matched: &[String],
+    action: TagConfigAction,
+    opts: &TagConfigOptions,
+) -> Vec<TagConfigResult> {
+    let mut tasks = tokio::task::JoinSet::new();
+    for (i, name) in matched.iter().enumerate() {
+        let cfg = cfg.clone();
+        let name = name.clone();
+        let opts = opts.clone();
+        tasks.spawn(async move {
+            let _slot = client::acquire_request_slot().await;
+            (i, apply_one(&cfg, &name, action, opts).await)
+        });
+    }
+
+    let mut outcomes: Vec<Option<Result<()>>> = matched.iter().map(|_| None).collect();
+    while let Some(joined) = tasks.join_next().await {
+        if let Ok((i, outcome)) = joined {
+            outcomes[i] = Some(outcome);
+        }
+    }
+    matched
+        .iter()
+        .zip(outcomes)
+        .map(|(name, outcome)| {
+            tag_config_result(
+                name,
+                action,
+                outcome.unwrap_or_else(|| Err(anyhow::anyhow!("task aborted"))),
+            )
+        })
+        .collect()
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn apply_all(
+    cfg: &Config,
+    matched: &[String],
+    action: TagConfigAction,
+    opts: &TagConfigOptions,
+) -> Vec<TagConfigResult> {
+    let mut results = Vec::new();
+    for name in matched {
+        let outcome = apply_one(cfg, name, action, opts.clone()).await;
+        results.push(tag_config_result(name, action, outcome));
+    }
+    results
+}
+
+fn tag_config_result(name: &str, action: TagConfigAction, outcome: Result<()>) -> TagConfigResult {
+    let (status, error) = match outcome {
+        Ok(()) => (action.done(), None),
+        Err(e) => ("failed", Some(e.to_string())),
+    };
+    TagConfigResult {
+        metric: name.to_string(),
+        status,
+        error,
+    }
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn metric_volumes(cfg: &Config, metric_name: &str) -> Result<serde_json::Value> {
+    let resp = v2_api(cfg)
+        .list_volumes_by_metric_name(metric_name.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get volumes for {metric_name}: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn metric_volumes(cfg: &Config, metric_name: &str) -> Result<serde_json::Value> {
+    let path = format!("/api/v2/metrics/{metric_name}/volumes");
+    crate::api::get(cfg, &path, &[]).await
+}
+
+/// Ingested and indexed volumes of one metric.
+pub async fn volumes_get(cfg: &Config, metric_name: &str) -> Result<()> {
+    let data = metric_volumes(cfg, metric_name).await?;
+    formatter::output(cfg, &data)
+}
+
+/// Volumes of every metric matching `pattern` (`*` wildcards), largest
+/// first. Metrics whose volumes cannot be read are reported and skipped.
+pub async fn volumes_list(cfg: &Config, pattern: &str, limit: usize) -> Result<()> {
+    let matched: Vec<String> = metric_names(cfg, None)
+        .await?
+        .into_iter()
+        .filter(|name| name_matches(pattern, name))
+        .collect();
+    if matched.is_empty() {
+        formatter::print_status(&format!("No metrics match {pattern:?}."));
+        return Ok(());
+    }
+
+    let mut rows = Vec::new();
+    let mut failed = 0;
+    for (name, outcome) in fetch_volumes(cfg, &matched).await {
+        match outcome {
+            Ok(resp) => rows.push(volume_row(&name, &resp)),
+            Err(e) => {
+                formatter::print_status(&format!("warning: {e}"));
+                failed += 1;
+            }
+        }
+    }
+    if rows.is_empty() {
+        bail!("failed to get volumes for all {failed} matching metrics");
+    }
+    sort_volume_rows(&mut rows);
+    rows.truncate(limit);
+    formatter::output(cfg, &rows)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn fetch_volumes(cfg: &Config, names: &[String]) -> Vec<(String, Result<serde_json::Value>)> {
+    let mut tasks = tokio::task::JoinSet::new();
+    for name in names {
+        let cfg = cfg.clone();
+        let name = name.clone();
+        tasks.spawn(async move {
+            let _slot = client::acquire_request_slot().await;
+            let outcome = metric_volumes(&cfg, &name).await;
+            (name, outcome)
+        });
+    }
+    let mut results = Vec::with_capacity(names.len());
+    while let Some(joined) = tasks.join_next().await {
+        if let Ok(result) = joined {
+            results.push(result);
+        }
+    }
+    results
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn fetch_volumes(cfg: &Config, names: &[String]) -> Vec<(String, Result<serde_json::Value>)> {
+    let mut results = Vec::with_capacity(names.len());
+    for name in names {
+        results.push((name.clone(), metric_volumes(cfg, name).await));
+    }
+    results
+}
+
+/// One report row. Distributions have a distinct volume instead of
+/// ingested/indexed ones, so each row carries all three.
+fn volume_row(metric_name: &str, resp: &serde_json::Value) -> serde_json::Value {
+    let attrs = &resp["data"]["attributes"];
+    serde_json::json!({
+        "metric": metric_name,
+        "ingested_volume": attrs["ingested_volume"],
+        "indexed_volume": attrs["indexed_volume"],
+        "distinct_volume": attrs["distinct_volume"],
+    })
+}
+
+/// Largest first by ingested volume (distinct volume for distributions),
+/// then by name.
+fn sort_volume_rows(rows: &mut [serde_json::Value]) {
+    let volume = |row: &serde_json::Value| {
+        row["ingested_volume"]
+            .as_i64()
+            .or_else(|| row["distinct_volume"].as_i64())
+            .unwrap_or(0)
+    };
+    rows.sort_by(|a, b| {
+        volume(b)
+            .cmp(&volume(a))
+            .then_with(|| a["metric"].as_str().cmp(&b["metric"].as_str()))
+    });
+}
+
+/// Dashboards, monitors, notebooks, and SLOs that reference `metric_name`,
+/// one row per asset.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn assets(cfg: &Config, metric_name: &str) -> Result<()> {
+    let resp = v2_api(cfg)
+        .list_metric_assets(metric_name.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list assets for {metric_name}: {e:?}"))?;
+    formatter::output(cfg, &asset_rows(&serde_json::to_value(&resp)?))
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn assets(cfg: &Config, metric_name: &str) -> Result<()> {
+    let path = format!("/api/v2/metrics/{metric_name}/assets");
+    let data = crate::api::get(cfg, &path, &[]).await?;
+    formatter::output(cfg, &asset_rows(&data))
+}
+
+/// Rows from the `included` assets of a related assets response. Assets
+/// only listed under `relationships` still get a row, without a title.
+fn asset_rows(resp: &serde_json::Value) -> Vec<serde_json::Value> {
+    let included: Vec<&serde_json::Value> =
+        resp["included"].as_array().into_iter().flatten().collect();
+    let mut rows = Vec::new();
+    let relationships = resp["data"]["relationships"].as_object();
+    for (kind, rel) in relationships.into_iter().flatten() {
+        for item in rel["data"].as_array().into_iter().flatten() {
+            let id = &item["id"];
+            let asset_type = item["type"].as_str().unwrap_or(kind);
+            let attrs = included
+                .iter()
+                .find(|inc| inc["id"] == *id && inc["type"] == asset_type)
+                .map(|inc| &inc["attributes"]);
+            rows.push(serde_json::json!({
+                "type": asset_type,
+                "id": id,
+                "title": attrs.map_or(&serde_json::Value::Null, |a| &a["title"]),
+                "url": attrs.map_or(&serde_json::Value::Null, |a| &a["url"]),
+            }));
+        }
+    }
+    rows
+}
+
+/// Flags for `metrics estimate`, describing the tag configuration to price.
+#[derive(Default)]
+pub struct EstimateOptions {
+    /// Comma-separated tags that would stay queryable.
+    pub tags: Option<String>,
+    /// Aggregations in `tag-config` form; only their number matters.
+    pub aggregations: Option<String>,
+    pub include_percentiles: bool,
+    pub hours_ago: Option<i32>,
+    pub timespan_hours: Option<i32>,
+}
+
+/// `filter[groups]`: the tags that would stay queryable.
+fn estimate_groups(opts: &EstimateOptions) -> Option<String> {
+    let tags: Vec<&str> = opts
+        .tags
+        .as_deref()?
+        .split(',')
+        .map(str::trim)
+        .filter(|t| !t.is_empty())
+        .collect();
+    Some(tags.join(","))
+}
+
+/// `filter[num_aggregations]`, after checking each aggregation is valid.
+fn estimate_num_aggregations(opts: &EstimateOptions) -> Result<Option<i32>> {
+    let Some(list) = &opts.aggregations else {
+        return Ok(None);
+    };
+    let aggregations = list
+        .split(',')
+        .map(parse_aggregation)
+        .collect::<Result<Vec<_>>>()?;
+    Ok(Some(aggregations.len() as i32))
+}
+
+/// Projects how many custom metric series `metric_name` would emit under
+/// the given tag configuration.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn estimate(cfg: &Config, metric_name: &str, opts: EstimateOptions) -> Result<()> {
+    use datadog_api_client::datadogV2::api_metrics::EstimateMetricsOutputSeriesOptionalParams;
+
+    let mut params = EstimateMetricsOutputSeriesOptionalParams::default();
+    if let Some(groups) = estimate_groups(&opts) {
+        params = params.filter_groups(groups);
+    }
+    if let Some(count) = estimate_num_aggregations(&opts)? {
+        params = params.filter_num_aggregations(count);
+    }
+    if opts.include_percentiles {
+        params = params.filter_pct(true);
+    }
+    if let Some(hours) = opts.hours_ago {
+        params = params.filter_hours_ago(hours);
+    }
+    if let Some(hours) = opts.timespan_hours {
+        params = params.filter_timespan_h(hours);
+    }
+    let resp = v2_api(cfg)
+        .estimate_metrics_output_series(metric_name.to_string(), params)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to estimate cardinality for {metric_name}: {e:?}"))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn estimate(cfg: &Config, metric_name: &str, opts: EstimateOptions) -> Result<()> {
+    let mut params: Vec<(&str, String)> = Vec::new();
+    if let Some(groups) = estimate_groups(&opts) {
+        params.push(("filter[groups]", groups));
+    }
+    if let Some(count) = estimate_num_aggregations(&opts)? {
+        params.push(("filter[num_aggregations]", count.to_string()));
+    }
+    if opts.include_percentiles {
+        params.push(("filter[pct]", "true".into()));
+    }
+    if let Some(hours) = opts.hours_ago {
+        params.push(("filter[hours_ago]", hours.to_string()));
+    }
+    if let Some(hours) = opts.timespan_hours {
+        params.push(("filter[timespan_h]", hours.to_string()));
+    }
+    let path = format!("/api/v2/metrics/{metric_name}/estimate");
+    let data = crate::api::get(cfg, &path, &params).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+fn ensure_update_fields(opts: &TagConfigOptions) -> Result<()> {
+    if opts.tags.is_none() && opts.aggregations.is_none() && opts.include_percentiles.is_none() {
+        bail!("nothing to update: pass --tags, --aggregations, or --include-percentiles");
+    }
+    Ok(())
+}
+
+/// The metric type recorded in v1 metric metadata.
+fn metadata_type(metadata: &serde_json::Value, metric_name: &str) -> Result<Option<String>> {
+    match metadata["type"].as_str().filter(|t| !t.is_empty()) {
+        Some(t) => Ok(Some(t.to_string())),
+        None => bail!("metric {metric_name} has no type in its metadata; pass --metric-type"),
+    }
+}
+
+/// Builds the `manage_tags` request body shared by create and update,
+/// including only the attributes that were given.
+fn tag_config_body(metric_name: &str, opts: &TagConfigOptions) -> Result<serde_json::Value> {
+    let mut attrs = serde_json::Map::new();
+    if let Some(tags) = &opts.tags {
+        let tags: Vec<&str> = tags
+            .split(',')
+            .map(str::trim)
+            .filter(|t| !t.is_empty())
+            .collect();
+        attrs.insert("tags".into(), serde_json::json!(tags));
+    }
+    if let Some(metric_type) = &opts.metric_type {
+        if !TAG_CONFIG_METRIC_TYPES.contains(&metric_type.as_str()) {
+            bail!(
+                "invalid metric type {metric_type:?}: expected one of {}",
+                TAG_CONFIG_METRIC_TYPES.join(", ")
+            );
+        }
+        attrs.insert("metric_type".into(), serde_json::json!(metric_type));
+    }
+    let distribution = opts.metric_type.as_deref() == Some("distribution");
+    if let Some(list) = &opts.aggregations {
+        if distribution {
+            bail!("--aggregations does not apply to distributions; use --include-percentiles");
+        }
+        let aggregations = list
+            .split(',')
+            .map(parse_aggregation)
+            .collect::<Result<Vec<_>>>()?;
+        attrs.insert("aggregations".into(), serde_json::json!(aggregations));
+    }
+    if let Some(include) = opts.include_percentiles {
+        if opts.metric_type.is_some() && !distribution {
+            bail!("--include-percentiles only applies to distribution metrics");
+        }
+        attrs.insert("include_percentiles".into(), serde_json::json!(include));
+    }
+    Ok(serde_json::json!({
+        "data": {
+            "id": metric_name,
+            "type": "manage_tags",
+            "attributes": attrs
+        }
+    }))
+}
+
+/// Parses one aggregation: `time:space` (e.g. `sum:max`), or just the space
+/// aggregation, paired with `sum` over time for `sum` and `avg` otherwise.
+fn parse_aggregation(input: &str) -> Result<serde_json::Value> {
+    let input = input.trim();
+    let (time, space) = match input.split_once(':') {
+        Some((time, space)) => (time.trim(), space.trim()),
+        None if input == "sum" => ("sum", "sum"),
+        None => ("avg", input),
+    };
+    if !["avg", "sum"].contains(&time) || !["avg", "max", "min", "sum"].contains(&space) {
+        bail!(
+            "invalid aggregation {input:?}: expected time:space with time avg|sum and \
+             space avg|max|min|sum (e.g. sum:max), or just the space aggregation"
+        );
+    }
+    Ok(serde_json::json!({"time": time, "space": space}))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_active_since_from() {
+        let now = chrono::Utc::now().timestamp();
+        let from = active_since_from("1d").unwrap();
+        assert!((now - 86400 - from).abs() <= 1, "from={from} now={now}");
+
+        let absolute = now - 7 * 86400;
+        assert_eq!(active_since_from(&absolute.to_string()).unwrap(), absolute);
+
+        assert!(active_since_from("90d").is_err());
+        assert!(active_since_from(&(now + 3600).to_string()).is_err());
+    }
+
+    #[test]
+    fn test_tag_config_body() {
+        let opts = TagConfigOptions {
+            tags: Some("env, service,,".into()),
+            aggregations: Some("avg,sum,sum:max".into()),
+            metric_type: Some("count".into()),
+            include_percentiles: None,
+        };
+        assert_eq!(
+            tag_config_body("app.requests", &opts).unwrap(),
+            serde_json::json!({"data": {
+                "id": "app.requests",
+                "type": "manage_tags",
+                "attributes": {
+                    "tags": ["env", "service"],
+                    "metric_type": "count",
+                    "aggregations": [
+                        {"time": "avg", "space": "avg"},
+                        {"time": "sum", "space": "sum"},
+                        {"time": "sum", "space": "max"}
+                    ]
+                }
+            }})
+        );
+
+        let update = TagConfigOptions {
+            tags: Some("env".into()),
+            ..Default::default()
+        };
+        let body = tag_config_body("app.requests", &update).unwrap();
+        assert_eq!(
+            body["data"]["attributes"],
+            serde_json::json!({"tags": ["env"]})
+        );
+    }
+
+    #[test]
+    fn test_tag_config_body_validation() {
+        let invalid = |opts: TagConfigOptions| tag_config_body("m", &opts).is_err();
+        assert!(invalid(TagConfigOptions {
+            metric_type: Some("histogram".into()),
+            ..Default::default()
+        }));
+        assert!(invalid(TagConfigOptions {
+            metric_type: Some("distribution".into()),
+            aggregations: Some("avg".into()),
+            ..Default::default()
+        }));
+        assert!(invalid(TagConfigOptions {
+            metric_type: Some("gauge".into()),
+            include_percentiles: Some(true),
+            ..Default::default()
+        }));
+        assert!(invalid(TagConfigOptions {
+            aggregations: Some("max:avg".into()),
+            ..Default::default()
+        }));
+        assert!(invalid(TagConfigOptions {
+            aggregations: Some("p99".into()),
+            ..Default::default()
+        }));
+    }
+
+    #[test]
+    fn test_ensure_update_fields() {
+        assert!(ensure_update_fields(&TagConfigOptions::default()).is_err());
+        assert!(ensure_update_fields(&TagConfigOptions {
+            include_percentiles: Some(false),
+            ..Default::default()
+        })
+        .is_ok());
+    }
+
+    #[test]
+    fn test_asset_rows() {
+        let resp = serde_json::json!({
+            "data": {"id": "app.requests", "type": "metrics", "relationships": {
+                "dashboards": {"data": [{"id": "abc-def", "type": "dashboards"}]},
+                "monitors": {"data": [{"id": "42", "type": "monitors"}]},
+                "notebooks": {"data": []},
+                "slos": {"data": [{"id": "slo1", "type": "slos"}]}
+            }},
+            "included": [
+                {"id": "abc-def", "type": "dashboards",
+                 "attributes": {"title": "API overview", "url": "/dashboard/abc-def"}},
+                {"id": "42", "type": "monitors",
+                 "attributes": {"title": "Request drop", "url": "/monitors/42"}}
+            ]
+        });
+        let rows = asset_rows(&resp);
+        assert_eq!(rows.len(), 3);
+        assert_eq!(
+            rows[0],
+            serde_json::json!({"type": "dashboards", "id": "abc-def",
+                "title": "API overview", "url": "/dashboard/abc-def"})
+        );
+        assert_eq!(rows[1]["title"], "Request drop");
+        assert_eq!(rows[2]["type"], "slos");
+        assert!(rows[2]["title"].is_null());
+        assert!(asset_rows(&serde_json::json!({"data": {}})).is_empty());
+    }
+
+    #[test]
+    fn test_volume_rows_sorted_by_volume() {
+        let mut rows = vec![
+            volume_row(
+                "app.small",
+                &serde_json::json!({"data": {"attributes": {"ingested_volume": 10, "indexed_volume": 5}}}),
+            ),
+            volume_row(
+                "app.latency",
+                &serde_json::json!({"data": {"type": "distinct_metric_volumes", "attributes": {"distinct_volume": 300}}}),
+            ),
+            volume_row(
+                "app.big",
+                &serde_json::json!({"data": {"attributes": {"ingested_volume": 900, "indexed_volume": 40}}}),
+            ),
+        ];
+        sort_volume_rows(&mut rows);
+        let order: Vec<&str> = rows.iter().map(|r| r["metric"].as_str().unwrap()).collect();
+        assert_eq!(order, vec!["app.big", "app.latency", "app.small"]);
+        assert_eq!(rows[0]["indexed_volume"], 40);
+        assert!(rows[0]["distinct_volume"].is_null());
+        assert!(rows[1]["ingested_volume"].is_null());
+    }
+
+    #[test]
+    fn test_estimate_filters() {
+        let opts = EstimateOptions {
+            tags: Some("env, service,".into()),
+            aggregations: Some("sum:max,avg".into()),
+            ..Default::default()
+        };
+        assert_eq!(estimate_groups(&opts).as_deref(), Some("env,service"));
+        assert_eq!(estimate_num_aggregations(&opts).unwrap(), Some(2));
+
+        let none = EstimateOptions::default();
+        assert_eq!(estimate_groups(&none), None);
+        assert_eq!(estimate_num_aggregations(&none).unwrap(), None);
+
+        let bad = EstimateOptions {
+            aggregations: Some("median".into()),
+            ..Default::default()
+        };
+        assert!(estimate_num_aggregations(&bad).is_err());
+    }
+
+    #[test]
+    fn test_scalar_request() {
+        let body = scalar_request("avg:app.latency{*} by {service}", "1h", "now", "max").unwrap();
+        let attrs = &body["data"]["attributes"];
+        assert_eq!(body["data"]["type"], "scalar_request");
+        assert_eq!(attrs["queries"][0]["aggregator"], "max");
+        assert_eq!(attrs["queries"][0]["data_source"], "metrics");
+        assert_eq!(attrs["formulas"][0]["formula"], "a");
+        assert_eq!(
+            attrs["to"].as_i64().unwrap() - attrs["from"].as_i64().unwrap(),
+            3_600_000
+        );
+        assert!(scalar_request("q", "1h", "now", "median").is_err());
+        assert!(scalar_request("q", "now", "1h", "avg").is_err());
+    }
+
+    #[test]
+    fn test_scalar_rows() {
+        let resp = serde_json::json!({"data": {"attributes": {"columns": [
+            {"name": "service", "type": "group", "values": [["web"], ["api"]]},
+            {"name": "env", "type": "group", "values": [["prod"], ["prod", "staging"]]},
+            {"name": "a", "type": "number", "values": [1.5, null]}
+        ]}}});
+        assert_eq!(
+            scalar_rows(&resp),
+            vec![
+                serde_json::json!({"service": "web", "env": "prod", "value": 1.5}),
+                serde_json::json!({"service": "api", "env": "prod,staging", "value": null}),
+            ]
+        );
+
+        let ungrouped = serde_json::json!({"data": {"attributes": {"columns": [
+            {"name": "a", "type": "number", "values": [42.0]}
+        ]}}});
+        assert_eq!(
+            scalar_rows(&ungrouped),
+            vec![serde_json::json!({"value": 42.0})]
+        );
+        assert!(scalar_rows(&serde_json::json!({})).is_empty());
+    }
+
+    fn point(metric_type: &str, values: &[f64]) -> SubmitPoint {
+        SubmitPoint {
+            name: "app.latency".into(),
+            values: values.to_vec(),
+            metric_type: metric_type.into(),
+            tags: Some("env:prod, team:web".into()),
+            host: Some("web-1".into()),
+            interval: 0,
+            timestamp: "2023-11-14T22:13:20Z".into(),
+        }
+    }
+
+    #[test]
+    fn test_point_series() {
+        let series = point_series(&point("gauge", &[99.5])).unwrap();
+        assert_eq!(series["type"], 3);
+        assert_eq!(series["points"][0]["timestamp"], 1700000000);
+        assert_eq!(series["points"][0]["value"], 99.5);
+        assert_eq!(series["tags"], serde_json::json!(["env:prod", "team:web"]));
+        assert_eq!(series["resources"][0]["name"], "web-1");
+        assert!(series.get("interval").is_none());
+
+        let mut rate = point("rate", &[3.0]);
+        rate.interval = 10;
+        let series = point_series(&rate).unwrap();
+        assert_eq!(series["type"], 2);
+        assert_eq!(series["interval"], 10);
+    }
+
+    #[test]
+    fn test_point_validation() {
+        assert!(point_series(&point("gauge", &[1.0, 2.0]))
+            .unwrap_err()
+            .to_string()
+            .contains("single --value"));
+        assert!(point_series(&point("histogram", &[1.0])).is_err());
+        assert!(point_series(&point("gauge", &[])).is_err());
+        assert!(point_series(&point("gauge", &[f64::NAN])).is_err());
+    }
+
+    #[test]
+    fn test_distribution_payload() {
+        let body = distribution_payload(&point("distribution", &[12.0, 48.5, 230.0])).unwrap();
+        let series = &body["series"][0];
+        assert_eq!(series["metric"], "app.latency");
+        assert_eq!(series["host"], "web-1");
+        assert_eq!(
+            series["points"],
+            serde_json::json!([[1700000000, [12.0, 48.5, 230.0]]])
+        );
+        assert_eq!(series["tags"], serde_json::json!(["env:prod", "team:web"]));
+    }
+
+    #[test]
+    fn test_submit_series_accepts_payload_or_array() {
+        let series = serde_json::json!({"metric": "app.jobs", "points": []});
+        assert_eq!(
+            submit_series(serde_json::json!({ "series": [series.clone()] })).unwrap(),
+            vec![series.clone()]
+        );
+        assert_eq!(
+            submit_series(serde_json::json!([series.clone()])).unwrap(),
+            vec![series]
+        );
+        assert!(submit_series(serde_json::json!([])).is_err());
+        assert!(submit_series(serde_json::json!({"data": []})).is_err());
+        assert!(submit_series(serde_json::json!([1])).is_err());
+    }
+
+    #[test]
+    fn test_series_payloads_chunks_under_limit() {
+        let series: Vec<serde_json::Value> = (0..10)
+            .map(|i| serde_json::json!({"metric": format!("app.m{i}"), "points": []}))
+            .collect();
+        let len = serde_json::to_string(&series[0]).unwrap().len();
+        // Room for three series plus their separating commas.
+        let max = 13 + 3 * len + 2;
+        let payloads = series_payloads(series.clone(), max).unwrap();
+        let sizes: Vec<usize> = payloads
+            .iter()
+            .map(|p| p["series"].as_array().unwrap().len())
+            .collect();
+        assert_eq!(sizes, vec![3, 3, 3, 1]);
+        for p in &payloads {
+            assert!(serde_json::to_string(p).unwrap().len() <= max);
+        }
+        assert_eq!(payloads[3]["series"][0]["metric"], "app.m9");
+
+        assert_eq!(
+            series_payloads(series.clone(), MAX_SERIES_PAYLOAD_BYTES)
+                .unwrap()
+                .len(),
+            1
+        );
+        let err = series_payloads(series, len).unwrap_err();
+        assert!(err.to_string().contains("app.m0"), "{err}");
+    }
+
+    #[test]
+    fn test_name_matches() {
+        assert!(name_matches("app.http.*", "app.http.requests"));
+        assert!(!name_matches("app.http.*", "app.grpc.requests"));
+        assert!(name_matches("*.errors", "web.errors"));
+        assert!(!name_matches("*.errors", "web.errors.count"));
+        assert!(name_matches("app.*.latency.*", "app.web.latency.p99"));
+        assert!(name_matches("a*a", "aa"));
+        assert!(!name_matches("a*a", "a"));
+        assert!(name_matches("exact.name", "exact.name"));
+        assert!(!name_matches("exact.name", "exact.name.more"));
+        assert!(name_matches("*", "anything"));
+    }
+
+    #[test]
+    fn test_metric_names_in() {
+        let data = serde_json::json!({"data": [
+            {"id": "b.metric", "type": "manage_tags"},
+            {"id": "a.metric", "type": "metrics"},
+            {"type": "metrics"}
+        ]});
+        assert_eq!(metric_names_in(&data), vec!["b.metric", "a.metric"]);
+        assert!(metric_names_in(&serde_json::json!({})).is_empty());
+    }
+
+    #[test]
+    fn test_next_metrics_cursor() {
+        let data = serde_json::json!({"meta": {"pagination": {"next_cursor": "abc"}}});
+        assert_eq!(next_metrics_cursor(&data).as_deref(), Some("abc"));
+        let last = serde_json::json!({"meta": {"pagination": {"next_cursor": null}}});
+        assert_eq!(next_metrics_cursor(&last), None);
+        assert_eq!(next_metrics_cursor(&serde_json::json!({})), None);
+    }
+
+    #[test]
+    fn test_metadata_type() {
+        let metadata = serde_json::json!({"type": "count", "unit": "request"});
+        assert_eq!(
+            metadata_type(&metadata, "m").unwrap().as_deref(),
+            Some("count")
+        );
+        assert!(metadata_type(&serde_json::json!({}), "m").is_err());
+    }
+}
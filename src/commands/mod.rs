@@ -5,6 +5,7 @@ pub mod apm;
 pub mod app_keys;
 pub mod audit_logs;
 pub mod auth;
+pub mod batch;
 pub mod cases;
 pub mod cicd;
 pub mod cloud;
@@ -31,10 +32,12 @@ pub mod obs_pipelines;
 pub mod on_call;
 pub mod organizations;
 pub mod product_analytics;
+pub mod raw;
 pub mod rum;
 pub mod scorecards;
 pub mod security;
 pub mod service_catalog;
+pub mod service_checks;
 pub mod slos;
 pub mod static_analysis;
 pub mod status_pages;
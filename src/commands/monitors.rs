@@ -44,7 +44,7 @@ pub async fn list(
         .map_err(|e| anyhow::anyhow!("failed to list monitors: {:?}", e))?;
 
     if monitors.is_empty() {
-        eprintln!("No monitors found matching the specified criteria.");
+        formatter::print_status("No monitors found matching the specified criteria.");
         return Ok(());
     }
 
@@ -55,7 +55,7 @@ pub async fn list(
         command: Some("monitors list".to_string()),
         next_action: None,
     };
-    formatter::format_and_print(&monitors, &cfg.output_format, cfg.agent_mode, Some(&meta))?;
+    formatter::output_with_meta(cfg, &monitors, Some(&meta))?;
     Ok(())
 }
 
@@ -98,7 +98,7 @@ pub async fn get(cfg: &Config, monitor_id: i64) -> Result<()> {
         command: Some("monitors get".to_string()),
         next_action: None,
     };
-    formatter::format_and_print(&resp, &cfg.output_format, cfg.agent_mode, Some(&meta))
+    formatter::output_with_meta(cfg, &resp, Some(&meta))
 }
 
 #[cfg(target_arch = "wasm32")]
@@ -130,6 +130,71 @@ pub async fn create(cfg: &Config, file: &str) -> Result<()> {
     crate::formatter::output(cfg, &data)
 }
 
+/// Validation outcome for one monitor definition file.
+#[derive(Debug, serde::Serialize)]
+struct ValidationResult {
+    file: String,
+    valid: bool,
+    errors: Vec<String>,
+}
+
+/// Checks monitor definitions with the ValidateMonitor endpoint without
+/// creating anything. Prints one result per file and fails if any
+/// definition is invalid, so CI jobs exit non-zero.
+pub async fn validate(cfg: &Config, files: &[String]) -> Result<()> {
+    let mut results = Vec::with_capacity(files.len());
+    for file in files {
+        let body: serde_json::Value = util::read_json_file(file)?;
+        let errors = match validate_definition(cfg, &body).await {
+            Ok(()) => Vec::new(),
+            Err(e) => validation_errors(&e).ok_or(e)?,
+        };
+        results.push(ValidationResult {
+            file: file.clone(),
+            valid: errors.is_empty(),
+            errors,
+        });
+    }
+    let invalid = results.iter().filter(|r| !r.valid).count();
+    formatter::output(cfg, &results)?;
+    if invalid > 0 {
+        anyhow::bail!(
+            "{invalid} of {} monitor definitions are invalid",
+            results.len()
+        );
+    }
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn validate_definition(cfg: &Config, body: &serde_json::Value) -> Result<()> {
+    client::raw_request(cfg, "POST", "/api/v1/monitor/validate", Some(body)).await?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn validate_definition(cfg: &Config, body: &serde_json::Value) -> Result<()> {
+    crate::api::post(cfg, "/api/v1/monitor/validate", body).await?;
+    Ok(())
+}
+
+/// The `errors` of a 400 response from the validate endpoint, which is how
+/// it reports an invalid definition. Other failures (auth, network) are
+/// not validation results and yield None.
+fn validation_errors(err: &anyhow::Error) -> Option<Vec<String>> {
+    let api_err = err.downcast_ref::<crate::api::ApiError>()?;
+    if api_err.status != reqwest::StatusCode::BAD_REQUEST {
+        return None;
+    }
+    let value: serde_json::Value = serde_json::from_str(&api_err.body).ok()?;
+    let errors: Vec<String> = value["errors"]
+        .as_array()?
+        .iter()
+        .map(|e| e.as_str().map_or_else(|| e.to_string(), String::from))
+        .collect();
+    (!errors.is_empty()).then_some(errors)
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn update(cfg: &Config, monitor_id: i64, file: &str) -> Result<()> {
     let body: datadog_api_client::datadogV1::model::MonitorUpdateRequest =
@@ -205,3 +270,1335 @@ pub async fn delete(cfg: &Config, monitor_id: i64) -> Result<()> {
     let data = crate::api::delete(cfg, &format!("/api/v1/monitor/{monitor_id}")).await?;
     crate::formatter::output(cfg, &data)
 }
+
+// ---- Bulk operations by search query ----
+
+const SEARCH_PAGE_SIZE: i64 = 100;
+
+#[derive(Clone, Debug, serde::Serialize)]
+struct MatchedMonitor {
+    id: i64,
+    name: String,
+}
+
+/// Downtime scope used when `--scope` is not given: every group.
+const MUTE_ALL_SCOPES: &str = "*";
+
+/// Active downtimes listed per request when looking for ones to cancel.
+const DOWNTIME_PAGE_SIZE: usize = 100;
+
+/// An active downtime created for a single monitor.
+#[derive(Clone, Debug, PartialEq)]
+struct MonitorDowntime {
+    id: String,
+    monitor_id: i64,
+    scope: String,
+}
+
+#[derive(Clone, Debug)]
+enum BulkAction {
+    Delete,
+    /// Mute with an optional duration in milliseconds (indefinite if None).
+    Mute {
+        duration_ms: Option<i64>,
+        scope: String,
+    },
+    /// Cancel these downtimes, listed once before the monitors are matched.
+    Unmute(std::sync::Arc<Vec<MonitorDowntime>>),
+    /// Set `key:value` tags (replacing any existing value for the key)
+    /// and/or resolve every alerting group.
+    Update {
+        set_tags: std::sync::Arc<Vec<String>>,
+        resolve: bool,
+    },
+}
+
+impl BulkAction {
+    fn verb(&self) -> &'static str {
+        match self {
+            BulkAction::Delete => "delete",
+            BulkAction::Mute { .. } => "mute",
+            BulkAction::Unmute(_) => "unmute",
+            BulkAction::Update { set_tags, resolve } => match (set_tags.is_empty(), resolve) {
+                (true, _) => "resolve",
+                (false, false) => "tag",
+                (false, true) => "update",
+            },
+        }
+    }
+
+    fn done(&self) -> &'static str {
+        match self {
+            BulkAction::Delete => "deleted",
+            BulkAction::Mute { .. } => "muted",
+            BulkAction::Unmute(_) => "unmuted",
+            BulkAction::Update { set_tags, resolve } => match (set_tags.is_empty(), resolve) {
+                (true, _) => "resolved",
+                (false, false) => "tagged",
+                (false, true) => "updated",
+            },
+        }
+    }
+}
+
+/// Per-monitor outcome of a bulk action.
+#[derive(Debug, serde::Serialize)]
+struct BulkResult {
+    id: i64,
+    name: String,
+    status: &'static str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+/// Deletes every monitor matching a monitor search query.
+pub async fn delete_matching(cfg: &Config, query: &str) -> Result<()> {
+    bulk_by_query(cfg, query, BulkAction::Delete).await
+}
+
+/// Mutes one monitor by creating a v2 downtime over `scope` (all groups if
+/// None), ending after `duration` if given.
+pub async fn mute(
+    cfg: &Config,
+    monitor_id: i64,
+    duration: Option<String>,
+    scope: Option<String>,
+) -> Result<()> {
+    let duration_ms = mute_duration(duration.as_deref())?;
+    let scope = scope.as_deref().unwrap_or(MUTE_ALL_SCOPES);
+    let data = create_downtime(cfg, downtime_body(monitor_id, duration_ms, scope)).await?;
+    formatter::output(cfg, &data)
+}
+
+/// Mutes every monitor matching a monitor search query by creating a
+/// downtime per monitor, ending after `duration` if given.
+pub async fn mute_matching(
+    cfg: &Config,
+    query: &str,
+    duration: Option<String>,
+    scope: Option<String>,
+) -> Result<()> {
+    let action = BulkAction::Mute {
+        duration_ms: mute_duration(duration.as_deref())?,
+        scope: scope.unwrap_or_else(|| MUTE_ALL_SCOPES.to_string()),
+    };
+    bulk_by_query(cfg, query, action).await
+}
+
+/// Unmutes one monitor by cancelling the active downtimes created for it,
+/// only those over `scope` if given. Downtimes that silence it through
+/// monitor tags are left alone, since they cover other monitors too.
+pub async fn unmute(cfg: &Config, monitor_id: i64, scope: Option<String>) -> Result<()> {
+    let downtimes = monitor_downtimes(cfg, scope.as_deref()).await?;
+    let cancelled = cancel_monitor_downtimes(cfg, &downtimes, monitor_id).await?;
+    if cancelled == 0 {
+        formatter::print_status(&format!(
+            "Monitor {monitor_id} has no active downtimes to cancel."
+        ));
+    } else {
+        formatter::print_block(&format!(
+            "Cancelled {cancelled} downtime(s) for monitor {monitor_id}.\n"
+        ))?;
+    }
+    Ok(())
+}
+
+/// Unmutes every monitor matching a monitor search query.
+pub async fn unmute_matching(cfg: &Config, query: &str, scope: Option<String>) -> Result<()> {
+    let downtimes = monitor_downtimes(cfg, scope.as_deref()).await?;
+    bulk_by_query(
+        cfg,
+        query,
+        BulkAction::Unmute(std::sync::Arc::new(downtimes)),
+    )
+    .await
+}
+
+/// Applies a batch of changes to every monitor matching a monitor search
+/// query: tag, resolve, or delete.
+pub async fn bulk(
+    cfg: &Config,
+    query: &str,
+    set_tags: Vec<String>,
+    resolve: bool,
+    delete: bool,
+) -> Result<()> {
+    if delete {
+        if !set_tags.is_empty() || resolve {
+            anyhow::bail!("--delete cannot be combined with --set-tag or --resolve");
+        }
+        return bulk_by_query(cfg, query, BulkAction::Delete).await;
+    }
+    if set_tags.is_empty() && !resolve {
+        anyhow::bail!("nothing to do: pass --set-tag, --resolve, or --delete");
+    }
+    if let Some(tag) = set_tags.iter().find(|t| t.trim().is_empty()) {
+        anyhow::bail!("invalid tag {tag:?}");
+    }
+    let action = BulkAction::Update {
+        set_tags: std::sync::Arc::new(set_tags),
+        resolve,
+    };
+    bulk_by_query(cfg, query, action).await
+}
+
+fn mute_duration(duration: Option<&str>) -> Result<Option<i64>> {
+    duration.map(util::parse_duration_millis).transpose()
+}
+
+async fn bulk_by_query(cfg: &Config, query: &str, action: BulkAction) -> Result<()> {
+    let matched = search_all(cfg, query).await?;
+    if matched.is_empty() {
+        formatter::print_status(&format!("No monitors match query {query:?}."));
+        return Ok(());
+    }
+
+    formatter::print_status(&format!("Matched {} monitor(s):", matched.len()));
+    for m in &matched {
+        formatter::print_status(&format!("  {}  {}", m.id, m.name));
+    }
+    if !util::confirm_bulk(cfg, action.verb(), "monitors", matched.len())? {
+        return Ok(());
+    }
+
+    let results = apply_all(cfg, &matched, &action).await;
+    let failed = results.iter().filter(|r| r.error.is_some()).count();
+    formatter::output(cfg, &results)?;
+    if failed > 0 {
+        anyhow::bail!(
+            "failed to {} {failed} of {} monitors",
+            action.verb(),
+            results.len()
+        );
+    }
+    Ok(())
+}
+
+/// Reads one page of monitor search results, returning the matches and the
+/// total page count.
+fn parse_search_page(value: &serde_json::Value) -> (Vec<MatchedMonitor>, i64) {
+    let matched = value["monitors"]
+        .as_array()
+        .map(|monitors| {
+            monitors
+                .iter()
+                .filter_map(|m| {
+                    Some(MatchedMonitor {
+                        id: m["id"].as_i64()?,
+                        name: m["name"].as_str().unwrap_or_default().to_string(),
+                    })
+                })
+                .collect()
+        })
+        .unwrap_or_default();
+    let page_count = value["metadata"]["page_count"].as_i64().unwrap_or(0);
+    (matched, page_count)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn search_all(cfg: &Config, query: &str) -> Result<Vec<MatchedMonitor>> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = if let Some(http_client) = client::make_bearer_client(cfg) {
+        MonitorsAPI::with_client_and_config(dd_cfg, http_client)
+    } else {
+        MonitorsAPI::with_config(dd_cfg)
+    };
+
+    let mut matched = Vec::new();
+    let mut page = 0;
+    loop {
+        let params = SearchMonitorsOptionalParams::default()
+            .query(query.to_string())
+            .page(page)
+            .per_page(SEARCH_PAGE_SIZE);
+        let resp = api
+            .search_monitors(params)
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to search monitors: {:?}", e))?;
+        let (monitors, page_count) = parse_search_page(&serde_json::to_value(&resp)?);
+        let done = monitors.is_empty();
+        matched.extend(monitors);
+        page += 1;
+        if done || page >= page_count {
+            return Ok(matched);
+        }
+    }
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn search_all(cfg: &Config, query: &str) -> Result<Vec<MatchedMonitor>> {
+    let mut matched = Vec::new();
+    let mut page = 0;
+    loop {
+        let params = vec![
+            ("query", query.to_string()),
+            ("page", page.to_string()),
+            ("per_page", SEARCH_PAGE_SIZE.to_string()),
+        ];
+        let data = crate::api::get(cfg, "/api/v1/monitor/search", &params).await?;
+        let (monitors, page_count) = parse_search_page(&data);
+        let done = monitors.is_empty();
+        matched.extend(monitors);
+        page += 1;
+        if done || page >= page_count {
+            return Ok(matched);
+        }
+    }
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn apply_all(
+    cfg: &Config,
+    matched: &[MatchedMonitor],
+    action: &BulkAction,
+) -> Vec<BulkResult> {
+    let mut tasks = tokio::task::JoinSet::new();
+    for (i, m) in matched.iter().enumerate() {
+        let cfg = cfg.clone();
+        let id = m.id;
+        let action = action.clone();
+        tasks.spawn(async move {
+            let _slot = client::acquire_request_slot().await;
+            (i, apply_one(&cfg, id, &action).await)
+        });
+    }
+
+    let mut outcomes: Vec<Option<Result<&'static str>>> = matched.iter().map(|_| None).collect();
+    while let Some(joined) = tasks.join_next().await {
+        if let Ok((i, outcome)) = joined {
+            outcomes[i] = Some(outcome);
+        }
+    }
+    matched
+        .iter()
+        .zip(outcomes)
+        .map(|(m, outcome)| {
+            bulk_result(
+                m,
+                outcome.unwrap_or_else(|| Err(anyhow::anyhow!("task aborted"))),
+            )
+        })
+        .collect()
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn apply_all(
+    cfg: &Config,
+    matched: &[MatchedMonitor],
+    action: &BulkAction,
+) -> Vec<BulkResult> {
+    let mut results = Vec::new();
+    for m in matched {
+        results.push(bulk_result(m, apply_one(cfg, m.id, action).await));
+    }
+    results
+}
+
+/// The row for one monitor: the status `apply_one` reported, or the error.
+fn bulk_result(m: &MatchedMonitor, outcome: Result<&'static str>) -> BulkResult {
+    match outcome {
+        Ok(status) => BulkResult {
+            id: m.id,
+            name: m.name.clone(),
+            status,
+            error: None,
+        },
+        Err(e) => BulkResult {
+            id: m.id,
+            name: m.name.clone(),
+            status: "failed",
+            error: Some(e.to_string()),
+        },
+    }
+}
+
+/// `existing` with each of `set` added, replacing tags with the same key
+/// (`owner:a` replaces `owner:b` and a bare `owner`).
+fn merge_tags(existing: &[String], set: &[String]) -> Vec<String> {
+    let key = |tag: &str| tag.split_once(':').map_or(tag, |(k, _)| k).to_string();
+    let set_keys: Vec<String> = set.iter().map(|t| key(t)).collect();
+    let mut tags: Vec<String> = existing
+        .iter()
+        .filter(|t| set.contains(t) || !set_keys.contains(&key(t)))
+        .cloned()
+        .collect();
+    for tag in set {
+        if !tags.contains(tag) {
+            tags.push(tag.clone());
+        }
+    }
+    tags
+}
+
+/// Sets `set_tags` on one monitor, skipping the update if it already has them.
+async fn set_monitor_tags(cfg: &Config, monitor_id: i64, set_tags: &[String]) -> Result<()> {
+    let monitor = fetch_monitor(cfg, monitor_id).await?;
+    let existing: Vec<String> = monitor["tags"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter_map(|t| t.as_str().map(String::from))
+        .collect();
+    let tags = merge_tags(&existing, set_tags);
+    if tags != existing {
+        update_definition(cfg, monitor_id, &serde_json::json!({ "tags": tags })).await?;
+    }
+    Ok(())
+}
+
+/// Body resolving every group of one monitor.
+fn resolve_body(monitor_id: i64) -> serde_json::Value {
+    serde_json::json!({"resolve": [{ monitor_id.to_string(): "ALL_GROUPS" }]})
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn resolve_monitor(cfg: &Config, monitor_id: i64) -> Result<()> {
+    client::raw_post(
+        cfg,
+        "/api/v1/monitor/bulk_resolve",
+        resolve_body(monitor_id),
+    )
+    .await?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn resolve_monitor(cfg: &Config, monitor_id: i64) -> Result<()> {
+    crate::api::post(
+        cfg,
+        "/api/v1/monitor/bulk_resolve",
+        &resolve_body(monitor_id),
+    )
+    .await?;
+    Ok(())
+}
+
+/// v2 downtime body muting one monitor over `scope`.
+fn downtime_body(monitor_id: i64, duration_ms: Option<i64>, scope: &str) -> serde_json::Value {
+    let mut attributes = serde_json::json!({
+        "scope": scope,
+        "monitor_identifier": {"monitor_id": monitor_id},
+    });
+    if let Some(ms) = duration_ms {
+        let end = chrono::Utc::now() + chrono::Duration::milliseconds(ms);
+        attributes["schedule"] = serde_json::json!({
+            "end": end.to_rfc3339_opts(chrono::SecondsFormat::Secs, true),
+        });
+    }
+    serde_json::json!({"data": {"type": "downtime", "attributes": attributes}})
+}
+
+/// Downtimes in a v2 downtime list page that target a single monitor, over
+/// `scope` if given.
+fn parse_monitor_downtimes(value: &serde_json::Value, scope: Option<&str>) -> Vec<MonitorDowntime> {
+    value["data"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter_map(|d| {
+            let attrs = &d["attributes"];
+            Some(MonitorDowntime {
+                id: d["id"].as_str()?.to_string(),
+                monitor_id: attrs["monitor_identifier"]["monitor_id"].as_i64()?,
+                scope: attrs["scope"].as_str().unwrap_or_default().to_string(),
+            })
+        })
+        .filter(|d| scope.is_none_or(|scope| d.scope == scope))
+        .collect()
+}
+
+/// Active downtimes targeting single monitors, over `scope` if given.
+async fn monitor_downtimes(cfg: &Config, scope: Option<&str>) -> Result<Vec<MonitorDowntime>> {
+    let mut downtimes = Vec::new();
+    let mut offset = 0;
+    loop {
+        let page = list_current_downtimes(cfg, offset).await?;
+        let count = page["data"].as_array().map_or(0, Vec::len);
+        downtimes.extend(parse_monitor_downtimes(&page, scope));
+        if count < DOWNTIME_PAGE_SIZE {
+            return Ok(downtimes);
+        }
+        offset += count;
+    }
+}
+
+/// Cancels the downtimes in `downtimes` that target `monitor_id`, returning
+/// how many there were.
+async fn cancel_monitor_downtimes(
+    cfg: &Config,
+    downtimes: &[MonitorDowntime],
+    monitor_id: i64,
+) -> Result<usize> {
+    let mut cancelled = 0;
+    for d in downtimes.iter().filter(|d| d.monitor_id == monitor_id) {
+        cancel_downtime(cfg, &d.id).await?;
+        cancelled += 1;
+    }
+    Ok(cancelled)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn list_current_downtimes(cfg: &Config, offset: usize) -> Result<serde_json::Value> {
+    let path = format!(
+        "/api/v2/downtime?current_only=true&page[limit]={DOWNTIME_PAGE_SIZE}&page[offset]={offset}"
+    );
+    client::raw_get(cfg, &path).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn list_current_downtimes(cfg: &Config, offset: usize) -> Result<serde_json::Value> {
+    let params = [
+        ("current_only", "true".to_string()),
+        ("page[limit]", DOWNTIME_PAGE_SIZE.to_string()),
+        ("page[offset]", offset.to_string()),
+    ];
+    crate::api::get(cfg, "/api/v2/downtime", &params).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn create_downtime(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    client::raw_post(cfg, "/api/v2/downtime", body).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn create_downtime(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v2/downtime", &body).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn cancel_downtime(cfg: &Config, id: &str) -> Result<()> {
+    client::raw_delete(cfg, &format!("/api/v2/downtime/{id}")).await?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn cancel_downtime(cfg: &Config, id: &str) -> Result<()> {
+    crate::api::delete(cfg, &format!("/api/v2/downtime/{id}")).await?;
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn apply_one(cfg: &Config, monitor_id: i64, action: &BulkAction) -> Result<&'static str> {
+    match action {
+        BulkAction::Delete => {
+            let dd_cfg = client::make_dd_config(cfg);
+            let api = if let Some(http_client) = client::make_bearer_client(cfg) {
+                MonitorsAPI::with_client_and_config(dd_cfg, http_client)
+            } else {
+                MonitorsAPI::with_config(dd_cfg)
+            };
+            api.delete_monitor(monitor_id, DeleteMonitorOptionalParams::default())
+                .await
+                .map_err(|e| anyhow::anyhow!("failed to delete monitor: {:?}", e))?;
+        }
+        BulkAction::Mute { duration_ms, scope } => {
+            create_downtime(cfg, downtime_body(monitor_id, *duration_ms, scope)).await?;
+        }
+        BulkAction::Unmute(downtimes) => {
+            if cancel_monitor_downtimes(cfg, downtimes, monitor_id).await? == 0 {
+                return Ok("not muted");
+            }
+        }
+        BulkAction::Update { set_tags, resolve } => {
+            if !set_tags.is_empty() {
+                set_monitor_tags(cfg, monitor_id, set_tags).await?;
+            }
+            if *resolve {
+                resolve_monitor(cfg, monitor_id).await?;
+            }
+        }
+    }
+    Ok(action.done())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn apply_one(cfg: &Config, monitor_id: i64, action: &BulkAction) -> Result<&'static str> {
+    match action {
+        BulkAction::Delete => {
+            crate::api::delete(cfg, &format!("/api/v1/monitor/{monitor_id}")).await?;
+        }
+        BulkAction::Mute { duration_ms, scope } => {
+            create_downtime(cfg, downtime_body(monitor_id, *duration_ms, scope)).await?;
+        }
+        BulkAction::Unmute(downtimes) => {
+            if cancel_monitor_downtimes(cfg, downtimes, monitor_id).await? == 0 {
+                return Ok("not muted");
+            }
+        }
+        BulkAction::Update { set_tags, resolve } => {
+            if !set_tags.is_empty() {
+                set_monitor_tags(cfg, monitor_id, set_tags).await?;
+            }
+            if *resolve {
+                resolve_monitor(cfg, monitor_id).await?;
+            }
+        }
+    }
+    Ok(action.done())
+}
+
+// ---- Export and import ----
+
+/// Tag that ties a monitor to its exported file, as `pup_managed:<key>`.
+/// Import looks monitors up by it to decide between create and update.
+pub const MANAGED_TAG_PREFIX: &str = "pup_managed:";
+
+/// Server-assigned monitor fields left out of exported definitions.
+const SERVER_FIELDS: &[&str] = &[
+    "id",
+    "created",
+    "created_at",
+    "creator",
+    "deleted",
+    "matching_downtimes",
+    "modified",
+    "multi",
+    "org_id",
+    "overall_state",
+    "overall_state_modified",
+    "state",
+];
+
+/// Writes every monitor matching `query` to `dir`, one `<key>.json` (or
+/// `.yaml`) file each, tagged with its managed key.
+pub async fn export(cfg: &Config, query: &str, dir: &str, format: &str) -> Result<()> {
+    if !["json", "yaml"].contains(&format) {
+        anyhow::bail!("invalid format {format:?}: expected json or yaml");
+    }
+    let matched = search_all(cfg, query).await?;
+    if matched.is_empty() {
+        formatter::print_status(&format!("No monitors match query {query:?}."));
+        return Ok(());
+    }
+    std::fs::create_dir_all(dir)
+        .map_err(|e| anyhow::anyhow!("failed to create directory {dir:?}: {e}"))?;
+
+    let mut written = Vec::with_capacity(matched.len());
+    for m in &matched {
+        let monitor = fetch_monitor(cfg, m.id).await?;
+        let key = managed_key(&monitor).unwrap_or_else(|| default_key(m.id, &m.name));
+        let definition = exported_definition(monitor, &key);
+        let path = std::path::Path::new(dir).join(format!("{key}.{format}"));
+        let contents = if format == "yaml" {
+            serde_yaml::to_string(&definition)?
+        } else {
+            serde_json::to_string_pretty(&definition)? + "\n"
+        };
+        std::fs::write(&path, contents)
+            .map_err(|e| anyhow::anyhow!("failed to write {}: {e}", path.display()))?;
+        written.push(serde_json::json!({
+            "id": m.id,
+            "name": m.name,
+            "file": path.display().to_string(),
+        }));
+    }
+    formatter::output(cfg, &written)
+}
+
+/// One monitor definition read from an import directory.
+#[derive(Debug)]
+struct ImportFile {
+    path: String,
+    key: String,
+    definition: serde_json::Value,
+}
+
+/// Per-file outcome of `monitors import`.
+#[derive(Debug, serde::Serialize)]
+struct ImportResult {
+    file: String,
+    key: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    id: Option<i64>,
+    status: &'static str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+/// Creates or updates one monitor per definition file in `dir`, matching
+/// existing monitors by their managed tag. Definitions that already match
+/// are left untouched, so re-running an import is a no-op.
+pub async fn import(cfg: &Config, dir: &str) -> Result<()> {
+    let files = read_import_dir(dir)?;
+    if files.is_empty() {
+        formatter::print_status(&format!(
+            "No monitor definitions (*.json, *.yaml) in {dir}."
+        ));
+        return Ok(());
+    }
+
+    // (file, id of the monitor carrying its key, whether that monitor
+    // already matches the file)
+    let mut plan = Vec::with_capacity(files.len());
+    for file in files {
+        let existing = managed_monitors(cfg, &file.key).await?;
+        if existing.len() > 1 {
+            let ids: Vec<String> = existing.iter().map(|m| m["id"].to_string()).collect();
+            anyhow::bail!(
+                "{}: {} monitors carry the tag {MANAGED_TAG_PREFIX}{} ({}); remove the duplicates first",
+                file.path,
+                existing.len(),
+                file.key,
+                ids.join(", ")
+            );
+        }
+        let (id, unchanged) = match existing.into_iter().next() {
+            Some(monitor) => (
+                monitor["id"].as_i64(),
+                exported_definition(monitor, &file.key) == file.definition,
+            ),
+            None => (None, false),
+        };
+        plan.push((file, id, unchanged));
+    }
+
+    let changes = plan.iter().filter(|(_, _, unchanged)| !unchanged).count();
+    if changes > 0 {
+        formatter::print_status(&format!("{changes} monitor definition(s) to apply:"));
+        for (file, id, _) in plan.iter().filter(|(_, _, unchanged)| !unchanged) {
+            match id {
+                Some(id) => formatter::print_status(&format!("  update {id}  {}", file.path)),
+                None => formatter::print_status(&format!("  create  {}", file.path)),
+            }
+        }
+        if !util::confirm_bulk(cfg, "apply", "monitor definitions", changes)? {
+            return Ok(());
+        }
+    }
+
+    let mut results = Vec::with_capacity(plan.len());
+    for (file, id, unchanged) in plan {
+        let outcome = match id {
+            Some(id) if unchanged => Ok(("unchanged", Some(id))),
+            Some(id) => update_definition(cfg, id, &file.definition)
+                .await
+                .map(|_| ("updated", Some(id))),
+            None => create_definition(cfg, &file.definition)
+                .await
+                .map(|created| ("created", created["id"].as_i64())),
+        };
+        let (status, id, error) = match outcome {
+            Ok((status, id)) => (status, id, None),
+            Err(e) => ("failed", id, Some(e.to_string())),
+        };
+        results.push(ImportResult {
+            file: file.path,
+            key: file.key,
+            id,
+            status,
+            error,
+        });
+    }
+
+    let failed = results.iter().filter(|r| r.error.is_some()).count();
+    formatter::output(cfg, &results)?;
+    if failed > 0 {
+        anyhow::bail!(
+            "failed to import {failed} of {} monitor definitions",
+            results.len()
+        );
+    }
+    Ok(())
+}
+
+/// Definition files in `dir`, sorted by name. A file without a managed tag
+/// gets one keyed on its file name.
+fn read_import_dir(dir: &str) -> Result<Vec<ImportFile>> {
+    let entries = std::fs::read_dir(dir)
+        .map_err(|e| anyhow::anyhow!("failed to read directory {dir:?}: {e}"))?;
+    let mut paths: Vec<std::path::PathBuf> = entries
+        .filter_map(|entry| entry.ok().map(|e| e.path()))
+        .filter(|path| {
+            matches!(
+                path.extension().and_then(|e| e.to_str()),
+                Some("json" | "yaml" | "yml")
+            )
+        })
+        .collect();
+    paths.sort();
+
+    let mut files: Vec<ImportFile> = Vec::with_capacity(paths.len());
+    for path in paths {
+        let display = path.display().to_string();
+        let contents = std::fs::read_to_string(&path)
+            .map_err(|e| anyhow::anyhow!("failed to read {display}: {e}"))?;
+        let definition: serde_json::Value = if path.extension().is_some_and(|e| e == "json") {
+            serde_json::from_str(&contents)
+                .map_err(|e| anyhow::anyhow!("failed to parse JSON from {display}: {e}"))?
+        } else {
+            serde_yaml::from_str(&contents)
+                .map_err(|e| anyhow::anyhow!("failed to parse YAML from {display}: {e}"))?
+        };
+        if !definition.is_object() {
+            anyhow::bail!("{display}: expected a monitor definition object");
+        }
+        let stem = path
+            .file_stem()
+            .and_then(|s| s.to_str())
+            .unwrap_or_default();
+        let key = managed_key(&definition).unwrap_or_else(|| slug(stem));
+        if let Some(other) = files.iter().find(|f| f.key == key) {
+            anyhow::bail!(
+                "{display} and {} both use the managed key {key:?}",
+                other.path
+            );
+        }
+        files.push(ImportFile {
+            definition: exported_definition(definition, &key),
+            path: display,
+            key,
+        });
+    }
+    Ok(files)
+}
+
+/// The key in a monitor's `pup_managed:<key>` tag, if it has one.
+fn managed_key(monitor: &serde_json::Value) -> Option<String> {
+    monitor["tags"]
+        .as_array()?
+        .iter()
+        .filter_map(|t| t.as_str()?.strip_prefix(MANAGED_TAG_PREFIX))
+        .find(|key| !key.is_empty())
+        .map(str::to_string)
+}
+
+/// Key for a monitor exported for the first time: its name as a slug,
+/// suffixed with its id so renamed or same-named monitors never collide.
+fn default_key(id: i64, name: &str) -> String {
+    let name = slug(name);
+    let name: String = name.chars().take(60).collect();
+    let name = name.trim_end_matches('-');
+    if name.is_empty() {
+        format!("monitor-{id}")
+    } else {
+        format!("{name}-{id}")
+    }
+}
+
+/// Lowercase letters, digits, and single dashes.
+fn slug(text: &str) -> String {
+    let mut out = String::with_capacity(text.len());
+    for c in text.chars() {
+        if c.is_ascii_alphanumeric() {
+            out.push(c.to_ascii_lowercase());
+        } else if !out.ends_with('-') && !out.is_empty() {
+            out.push('-');
+        }
+    }
+    out.trim_end_matches('-').to_string()
+}
+
+/// A monitor as written to (and compared against) a definition file:
+/// server fields and nulls dropped, and exactly one managed tag for `key`.
+fn exported_definition(monitor: serde_json::Value, key: &str) -> serde_json::Value {
+    let serde_json::Value::Object(mut fields) = monitor else {
+        return monitor;
+    };
+    for field in SERVER_FIELDS {
+        fields.remove(*field);
+    }
+    fields.retain(|_, v| !v.is_null());
+    let mut tags: Vec<serde_json::Value> = fields
+        .get("tags")
+        .and_then(|t| t.as_array())
+        .into_iter()
+        .flatten()
+        .filter(|t| {
+            !t.as_str()
+                .is_some_and(|t| t.starts_with(MANAGED_TAG_PREFIX))
+        })
+        .cloned()
+        .collect();
+    tags.push(serde_json::json!(format!("{MANAGED_TAG_PREFIX}{key}")));
+    fields.insert("tags".into(), serde_json::json!(tags));
+    serde_json::Value::Object(fields)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn fetch_monitor(cfg: &Config, monitor_id: i64) -> Result<serde_json::Value> {
+    client::raw_get(cfg, &format!("/api/v1/monitor/{monitor_id}")).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn fetch_monitor(cfg: &Config, monitor_id: i64) -> Result<serde_json::Value> {
+    crate::api::get(cfg, &format!("/api/v1/monitor/{monitor_id}"), &[]).await
+}
+
+/// Monitors tagged with the managed tag for `key`.
+#[cfg(not(target_arch = "wasm32"))]
+async fn managed_monitors(cfg: &Config, key: &str) -> Result<Vec<serde_json::Value>> {
+    let path = format!("/api/v1/monitor?monitor_tags={MANAGED_TAG_PREFIX}{key}");
+    let data = client::raw_get(cfg, &path).await?;
+    Ok(data.as_array().cloned().unwrap_or_default())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn managed_monitors(cfg: &Config, key: &str) -> Result<Vec<serde_json::Value>> {
+    let params = [("monitor_tags", format!("{MANAGED_TAG_PREFIX}{key}"))];
+    let data = crate::api::get(cfg, "/api/v1/monitor", &params).await?;
+    Ok(data.as_array().cloned().unwrap_or_default())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn create_definition(cfg: &Config, body: &serde_json::Value) -> Result<serde_json::Value> {
+    client::raw_post(cfg, "/api/v1/monitor", body.clone()).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn create_definition(cfg: &Config, body: &serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v1/monitor", body).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn update_definition(
+    cfg: &Config,
+    monitor_id: i64,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    let path = format!("/api/v1/monitor/{monitor_id}");
+    let resp = client::raw_request(cfg, "PUT", &path, Some(body)).await?;
+    Ok(resp.json().await?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn update_definition(
+    cfg: &Config,
+    monitor_id: i64,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    crate::api::put(cfg, &format!("/api/v1/monitor/{monitor_id}"), body).await
+}
+
+// ---- Related dashboards and SLOs ----
+
+/// Most dashboards whose definitions `get --related` fetches and scans.
+const RELATED_DASHBOARD_LIMIT: usize = 200;
+/// SLOs listed per request when scanning for references.
+const RELATED_SLO_LIMIT: usize = 1000;
+
+/// Dashboard definitions already fetched by `get --related`, keyed by the
+/// config's cache scope (site and profile) and dashboard id, so repeated
+/// lookups in one run (e.g. batch steps) don't fetch the same dashboard twice.
+#[cfg(not(target_arch = "wasm32"))]
+static DASHBOARD_CACHE: std::sync::Mutex<std::collections::BTreeMap<String, serde_json::Value>> =
+    std::sync::Mutex::new(std::collections::BTreeMap::new());
+
+/// Monitor details plus the dashboards and SLOs that reference the monitor.
+/// Only the first `RELATED_DASHBOARD_LIMIT` dashboards are scanned.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn get_with_related(cfg: &Config, monitor_id: i64) -> Result<()> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = if let Some(http_client) = client::make_bearer_client(cfg) {
+        MonitorsAPI::with_client_and_config(dd_cfg, http_client)
+    } else {
+        MonitorsAPI::with_config(dd_cfg)
+    };
+    let monitor = api
+        .get_monitor(monitor_id, GetMonitorOptionalParams::default())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get monitor: {:?}", e))?;
+
+    let slos = client::raw_get(cfg, &format!("/api/v1/slo?limit={RELATED_SLO_LIMIT}")).await?;
+    let list = client::raw_get(cfg, "/api/v1/dashboard").await?;
+    let ids = dashboard_ids(&list);
+    if ids.len() > RELATED_DASHBOARD_LIMIT {
+        formatter::print_status(&format!(
+            "Scanning the first {RELATED_DASHBOARD_LIMIT} of {} dashboards.",
+            ids.len()
+        ));
+    }
+    let dashboards = fetch_dashboards(cfg, &ids[..ids.len().min(RELATED_DASHBOARD_LIMIT)]).await;
+
+    let report = related_report(
+        serde_json::to_value(&monitor)?,
+        monitor_id,
+        &slos,
+        &dashboards,
+    );
+    formatter::output(cfg, &report)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn get_with_related(cfg: &Config, monitor_id: i64) -> Result<()> {
+    let monitor = crate::api::get(cfg, &format!("/api/v1/monitor/{monitor_id}"), &[]).await?;
+    let slos = crate::api::get(
+        cfg,
+        "/api/v1/slo",
+        &[("limit", RELATED_SLO_LIMIT.to_string())],
+    )
+    .await?;
+    let list = crate::api::get(cfg, "/api/v1/dashboard", &[]).await?;
+    let mut dashboards = Vec::new();
+    for id in dashboard_ids(&list).iter().take(RELATED_DASHBOARD_LIMIT) {
+        if let Ok(dashboard) = crate::api::get(cfg, &format!("/api/v1/dashboard/{id}"), &[]).await {
+            dashboards.push(dashboard);
+        }
+    }
+    crate::formatter::output(
+        cfg,
+        &related_report(monitor, monitor_id, &slos, &dashboards),
+    )
+}
+
+/// Fetches dashboard definitions concurrently (within `--max-concurrency`),
+/// serving repeats from `DASHBOARD_CACHE`. Dashboards that fail to load are
+/// skipped with a note on stderr rather than failing the lookup.
+#[cfg(not(target_arch = "wasm32"))]
+async fn fetch_dashboards(cfg: &Config, ids: &[String]) -> Vec<serde_json::Value> {
+    let scope = cfg.cache_scope();
+    let cache_key = |id: &str| format!("{scope}/{id}");
+    let mut dashboards = Vec::new();
+    let mut tasks = tokio::task::JoinSet::new();
+    {
+        let cache = DASHBOARD_CACHE.lock().unwrap_or_else(|e| e.into_inner());
+        for id in ids {
+            match cache.get(&cache_key(id)) {
+                Some(dashboard) => dashboards.push(dashboard.clone()),
+                None => {
+                    let cfg = cfg.clone();
+                    let id = id.clone();
+                    tasks.spawn(async move {
+                        let _slot = client::acquire_request_slot().await;
+                        let path = format!("/api/v1/dashboard/{id}");
+                        (id, client::raw_get(&cfg, &path).await)
+                    });
+                }
+            }
+        }
+    }
+
+    let mut failed = 0;
+    while let Some(joined) = tasks.join_next().await {
+        match joined {
+            Ok((id, Ok(dashboard))) => {
+                DASHBOARD_CACHE
+                    .lock()
+                    .unwrap_or_else(|e| e.into_inner())
+                    .insert(cache_key(&id), dashboard.clone());
+                dashboards.push(dashboard);
+            }
+            _ => failed += 1,
+        }
+    }
+    if failed > 0 {
+        formatter::print_status(&format!(
+            "Skipped {failed} dashboard(s) that failed to load."
+        ));
+    }
+    dashboards
+}
+
+/// Dashboard ids from a `GET /api/v1/dashboard` list response.
+fn dashboard_ids(list: &serde_json::Value) -> Vec<String> {
+    list["dashboards"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter_map(|d| d["id"].as_str().map(str::to_string))
+        .collect()
+}
+
+/// Whether a dashboard or SLO definition points at `monitor_id`: an
+/// `alert_id` / `monitor_id` field (widgets store it as a string) or a
+/// `monitor_ids` list containing it, at any depth.
+fn references_monitor(value: &serde_json::Value, monitor_id: i64) -> bool {
+    let is_id = |v: &serde_json::Value| match v {
+        serde_json::Value::Number(n) => n.as_i64() == Some(monitor_id),
+        serde_json::Value::String(s) => s.trim().parse() == Ok(monitor_id),
+        _ => false,
+    };
+    match value {
+        serde_json::Value::Object(map) => map.iter().any(|(key, v)| {
+            match key.as_str() {
+                "alert_id" | "monitor_id" if is_id(v) => return true,
+                "monitor_ids" if v.as_array().is_some_and(|ids| ids.iter().any(is_id)) => {
+                    return true
+                }
+                _ => {}
+            }
+            references_monitor(v, monitor_id)
+        }),
+        serde_json::Value::Array(items) => items.iter().any(|v| references_monitor(v, monitor_id)),
+        _ => false,
+    }
+}
+
+/// Builds `{monitor, related: {dashboards, slos}}`, listing each referencing
+/// dashboard as `{id, title, url}` and SLO as `{id, name, type}`, sorted by name.
+fn related_report(
+    monitor: serde_json::Value,
+    monitor_id: i64,
+    slos: &serde_json::Value,
+    dashboards: &[serde_json::Value],
+) -> serde_json::Value {
+    let mut related_dashboards: Vec<serde_json::Value> = dashboards
+        .iter()
+        .filter(|d| references_monitor(&d["widgets"], monitor_id))
+        .map(|d| serde_json::json!({"id": d["id"], "title": d["title"], "url": d["url"]}))
+        .collect();
+    related_dashboards.sort_by(|a, b| a["title"].as_str().cmp(&b["title"].as_str()));
+
+    let mut related_slos: Vec<serde_json::Value> = slos["data"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter(|s| references_monitor(s, monitor_id))
+        .map(|s| serde_json::json!({"id": s["id"], "name": s["name"], "type": s["type"]}))
+        .collect();
+    related_slos.sort_by(|a, b| a["name"].as_str().cmp(&b["name"].as_str()));
+
+    serde_json::json!({
+        "monitor": monitor,
+        "related": {
+            "dashboards": related_dashboards,
+            "slos": related_slos,
+        }
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parse_search_page() {
+        let value = serde_json::json!({
+            "monitors": [
+                {"id": 1, "name": "cpu high", "status": "Alert"},
+                {"id": 2, "name": "disk full"},
+                {"name": "no id"}
+            ],
+            "metadata": {"page": 0, "page_count": 3, "per_page": 100, "total_count": 250}
+        });
+        let (matched, page_count) = parse_search_page(&value);
+        assert_eq!(page_count, 3);
+        assert_eq!(matched.len(), 2);
+        assert_eq!(matched[0].id, 1);
+        assert_eq!(matched[1].name, "disk full");
+    }
+
+    #[test]
+    fn test_downtime_body() {
+        let body = downtime_body(42, None, "*");
+        assert_eq!(body["data"]["type"], "downtime");
+        assert_eq!(body["data"]["attributes"]["scope"], "*");
+        assert_eq!(
+            body["data"]["attributes"]["monitor_identifier"]["monitor_id"],
+            42
+        );
+        assert!(body["data"]["attributes"].get("schedule").is_none());
+
+        let body = downtime_body(42, Some(3_600_000), "env:prod");
+        assert_eq!(body["data"]["attributes"]["scope"], "env:prod");
+        assert!(body["data"]["attributes"]["schedule"]["end"]
+            .as_str()
+            .unwrap()
+            .ends_with('Z'));
+    }
+
+    #[test]
+    fn test_merge_tags() {
+        let existing = vec![
+            "team:web".to_string(),
+            "owner:old".to_string(),
+            "owner".to_string(),
+        ];
+        assert_eq!(
+            merge_tags(
+                &existing,
+                &["owner:team-x".to_string(), "tier:1".to_string()]
+            ),
+            vec!["team:web", "owner:team-x", "tier:1"]
+        );
+        let tagged = merge_tags(&existing, &["team:web".to_string()]);
+        assert_eq!(tagged, existing);
+    }
+
+    #[test]
+    fn test_resolve_body() {
+        assert_eq!(
+            resolve_body(42),
+            serde_json::json!({"resolve": [{"42": "ALL_GROUPS"}]})
+        );
+    }
+
+    #[test]
+    fn test_bulk_update_verbs() {
+        let update = |tags: &[&str], resolve| BulkAction::Update {
+            set_tags: std::sync::Arc::new(tags.iter().map(|t| t.to_string()).collect()),
+            resolve,
+        };
+        assert_eq!(update(&["owner:x"], false).verb(), "tag");
+        assert_eq!(update(&[], true).done(), "resolved");
+        assert_eq!(update(&["owner:x"], true).verb(), "update");
+    }
+
+    #[test]
+    fn test_managed_keys() {
+        assert_eq!(
+            default_key(42, "CPU high on {{host.name}}!"),
+            "cpu-high-on-host-name-42"
+        );
+        assert_eq!(default_key(7, "***"), "monitor-7");
+        assert_eq!(slug("  Disk / full  "), "disk-full");
+        let monitor = serde_json::json!({"tags": ["team:web", "pup_managed:cpu-high"]});
+        assert_eq!(managed_key(&monitor).as_deref(), Some("cpu-high"));
+        assert_eq!(
+            managed_key(&serde_json::json!({"tags": ["pup_managed:"]})),
+            None
+        );
+        assert_eq!(managed_key(&serde_json::json!({})), None);
+    }
+
+    #[test]
+    fn test_exported_definition() {
+        let monitor = serde_json::json!({
+            "id": 42,
+            "name": "CPU high",
+            "type": "metric alert",
+            "query": "avg(last_5m):avg:system.cpu.user{*} > 90",
+            "tags": ["team:web", "pup_managed:old-key"],
+            "priority": null,
+            "overall_state": "OK",
+            "creator": {"email": "a@example.com"},
+            "options": {"thresholds": {"critical": 90}}
+        });
+        let def = exported_definition(monitor, "cpu-high-42");
+        assert_eq!(
+            def,
+            serde_json::json!({
+                "name": "CPU high",
+                "type": "metric alert",
+                "query": "avg(last_5m):avg:system.cpu.user{*} > 90",
+                "tags": ["team:web", "pup_managed:cpu-high-42"],
+                "options": {"thresholds": {"critical": 90}}
+            })
+        );
+        // Normalizing twice changes nothing, so exported files compare equal.
+        assert_eq!(exported_definition(def.clone(), "cpu-high-42"), def);
+    }
+
+    #[test]
+    fn test_read_import_dir() {
+        let dir = std::env::temp_dir().join("pup_test_monitor_import_dir");
+        std::fs::remove_dir_all(&dir).ok();
+        std::fs::create_dir_all(&dir).unwrap();
+        std::fs::write(
+            dir.join("b-disk.json"),
+            r#"{"name": "Disk", "tags": ["pup_managed:disk-full"]}"#,
+        )
+        .unwrap();
+        std::fs::write(dir.join("A CPU.json"), r#"{"name": "CPU"}"#).unwrap();
+        std::fs::write(dir.join("notes.txt"), "ignored").unwrap();
+
+        let files = read_import_dir(dir.to_str().unwrap()).unwrap();
+        let keys: Vec<&str> = files.iter().map(|f| f.key.as_str()).collect();
+        assert_eq!(keys, vec!["a-cpu", "disk-full"]);
+        assert_eq!(
+            files[0].definition["tags"],
+            serde_json::json!(["pup_managed:a-cpu"])
+        );
+
+        std::fs::write(
+            dir.join("c-copy.json"),
+            r#"{"name": "Copy", "tags": ["pup_managed:disk-full"]}"#,
+        )
+        .unwrap();
+        let err = read_import_dir(dir.to_str().unwrap()).unwrap_err();
+        assert!(err.to_string().contains("disk-full"), "{err}");
+        std::fs::remove_dir_all(&dir).ok();
+    }
+
+    #[test]
+    fn test_validation_errors() {
+        let api_err = |status: u16, body: &str| -> anyhow::Error {
+            crate::api::ApiError {
+                status: reqwest::StatusCode::from_u16(status).unwrap(),
+                body: body.to_string(),
+            }
+            .into()
+        };
+        let body = r#"{"errors": ["The value provided for parameter 'query' is invalid", "Alert threshold (90.0) must be greater than warning threshold (95.0)"]}"#;
+        assert_eq!(
+            validation_errors(&api_err(400, body)).unwrap(),
+            vec![
+                "The value provided for parameter 'query' is invalid",
+                "Alert threshold (90.0) must be greater than warning threshold (95.0)",
+            ]
+        );
+        assert!(validation_errors(&api_err(403, r#"{"errors": ["Forbidden"]}"#)).is_none());
+        assert!(validation_errors(&api_err(400, "not json")).is_none());
+        // Classified by status, not by message wording
+        let reworded = anyhow::anyhow!("API error (HTTP 400 Bad Request): {body}");
+        assert!(validation_errors(&reworded).is_none());
+    }
+
+    #[test]
+    fn test_parse_monitor_downtimes() {
+        let page = serde_json::json!({"data": [
+            {"id": "dt-1", "attributes": {"scope": "*", "monitor_identifier": {"monitor_id": 7}}},
+            {"id": "dt-2", "attributes": {"scope": "env:prod", "monitor_identifier": {"monitor_id": 7}}},
+            {"id": "dt-3", "attributes": {"scope": "*", "monitor_identifier": {"monitor_tags": ["team:web"]}}}
+        ]});
+        let all = parse_monitor_downtimes(&page, None);
+        let ids: Vec<&str> = all.iter().map(|d| d.id.as_str()).collect();
+        assert_eq!(ids, vec!["dt-1", "dt-2"]);
+        assert_eq!(
+            parse_monitor_downtimes(&page, Some("env:prod")),
+            vec![MonitorDowntime {
+                id: "dt-2".into(),
+                monitor_id: 7,
+                scope: "env:prod".into(),
+            }]
+        );
+    }
+
+    #[test]
+    fn test_references_monitor() {
+        let widgets = serde_json::json!([
+            {"definition": {"type": "group", "widgets": [
+                {"definition": {"type": "alert_graph", "alert_id": "123", "viz_type": "timeseries"}}
+            ]}}
+        ]);
+        assert!(references_monitor(&widgets, 123));
+        assert!(!references_monitor(&widgets, 12));
+        let slo = serde_json::json!({"id": "abc", "type": "monitor", "monitor_ids": [7, 123]});
+        assert!(references_monitor(&slo, 123));
+        assert!(!references_monitor(
+            &serde_json::json!({"query": "id:123"}),
+            123
+        ));
+    }
+
+    #[test]
+    fn test_related_report() {
+        let slos = serde_json::json!({"data": [
+            {"id": "s1", "name": "Checkout uptime", "type": "monitor", "monitor_ids": [123]},
+            {"id": "s2", "name": "Latency", "type": "metric", "query": {"numerator": "sum:x{*}"}}
+        ]});
+        let dashboards = [
+            serde_json::json!({"id": "d1", "title": "Checkout", "url": "/dashboard/d1",
+                "widgets": [{"definition": {"type": "alert_value", "alert_id": "123"}}]}),
+            serde_json::json!({"id": "d2", "title": "Infra", "url": "/dashboard/d2",
+                "widgets": [{"definition": {"type": "timeseries"}}]}),
+        ];
+        let report = related_report(serde_json::json!({"id": 123}), 123, &slos, &dashboards);
+        assert_eq!(report["monitor"]["id"], 123);
+        assert_eq!(
+            report["related"]["dashboards"],
+            serde_json::json!([{"id": "d1", "title": "Checkout", "url": "/dashboard/d1"}])
+        );
+        assert_eq!(
+            report["related"]["slos"],
+            serde_json::json!([{"id": "s1", "name": "Checkout uptime", "type": "monitor"}])
+        );
+    }
+
+    #[test]
+    fn test_dashboard_ids() {
+        let list =
+            serde_json::json!({"dashboards": [{"id": "a"}, {"title": "no id"}, {"id": "b"}]});
+        assert_eq!(dashboard_ids(&list), ["a", "b"]);
+        assert!(dashboard_ids(&serde_json::json!({})).is_empty());
+    }
+}
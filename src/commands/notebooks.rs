@@ -60,14 +60,14 @@ pub async fn delete(cfg: &Config, notebook_id: i64) -> Result<()> {
     api.delete_notebook(notebook_id)
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete notebook: {e:?}"))?;
-    println!("Successfully deleted notebook {notebook_id}");
+    formatter::print_block(&format!("Successfully deleted notebook {notebook_id}\n"))?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
 pub async fn delete(cfg: &Config, notebook_id: i64) -> Result<()> {
     crate::api::delete(cfg, &format!("/api/v1/notebooks/{notebook_id}")).await?;
-    println!("Successfully deleted notebook {notebook_id}");
+    formatter::print_block(&format!("Successfully deleted notebook {notebook_id}\n"))?;
     Ok(())
 }
 
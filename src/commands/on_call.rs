@@ -66,14 +66,14 @@ pub async fn teams_delete(cfg: &Config, team_id: &str) -> Result<()> {
     api.delete_team(team_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete team: {e:?}"))?;
-    println!("Team '{team_id}' deleted successfully.");
+    formatter::print_block(&format!("Team '{team_id}' deleted successfully.\n"))?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
 pub async fn teams_delete(cfg: &Config, team_id: &str) -> Result<()> {
     crate::api::delete(cfg, &format!("/api/v2/teams/{team_id}")).await?;
-    println!("Team '{team_id}' deleted successfully.");
+    formatter::print_block(&format!("Team '{team_id}' deleted successfully.\n"))?;
     Ok(())
 }
 
@@ -288,7 +288,9 @@ pub async fn memberships_remove(cfg: &Config, team_id: &str, user_id: &str) -> R
     api.delete_team_membership(team_id.to_string(), user_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to remove membership: {e:?}"))?;
-    println!("Membership for user {user_id} removed from team {team_id}.");
+    formatter::print_block(&format!(
+        "Membership for user {user_id} removed from team {team_id}.\n"
+    ))?;
     Ok(())
 }
 
@@ -299,6 +301,8 @@ pub async fn memberships_remove(cfg: &Config, team_id: &str, user_id: &str) -> R
         &format!("/api/v2/teams/{team_id}/memberships/{user_id}"),
     )
     .await?;
-    println!("Membership for user {user_id} removed from team {team_id}.");
+    formatter::print_block(&format!(
+        "Membership for user {user_id} removed from team {team_id}.\n"
+    ))?;
     Ok(())
 }
@@ -0,0 +1,178 @@
+use anyhow::{bail, Result};
+
+#[cfg(not(target_arch = "wasm32"))]
+use crate::client;
+use crate::config::Config;
+use crate::formatter;
+
+/// Calls any API path with the configured auth. A JSON response is printed
+/// through the formatter (re-indented as received for `-o json`); YAML, CSV,
+/// plain text, and other bodies are printed exactly as received. With
+/// `stream` or `output_file` the body bytes are copied out unchanged instead.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn run(
+    cfg: &Config,
+    method: &str,
+    path: &str,
+    body: Option<serde_json::Value>,
+    stream: bool,
+    output_file: Option<&str>,
+) -> Result<()> {
+    if let Some(file) = output_file {
+        let mut out = std::fs::File::create(file)
+            .map_err(|e| anyhow::anyhow!("failed to create {file}: {e}"))?;
+        let written = stream_to(cfg, method, path, body.as_ref(), &mut out).await?;
+        formatter::print_status(&format!("Wrote {written} bytes to {file}."));
+        return Ok(());
+    }
+    if stream {
+        stream_to(cfg, method, path, body.as_ref(), &mut std::io::stdout()).await?;
+        return Ok(());
+    }
+
+    match fetch(cfg, method, path, body.as_ref()).await? {
+        RawBody::Json(raw) => formatter::output_raw_json(cfg, &raw),
+        RawBody::Text(text) => formatter::print_block(&text),
+    }
+}
+
+/// A `raw` response body, decoded according to its Content-Type.
+#[cfg(not(target_arch = "wasm32"))]
+pub enum RawBody {
+    Json(formatter::RawJson),
+    /// Any non-JSON body, kept verbatim.
+    Text(String),
+}
+
+/// Sends the request and reads the body, decoding it only when the response
+/// says it is JSON. Responses without a Content-Type are assumed to be JSON.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn fetch(
+    cfg: &Config,
+    method: &str,
+    path: &str,
+    body: Option<&serde_json::Value>,
+) -> Result<RawBody> {
+    let resp = client::raw_request(cfg, method, path, body).await?;
+    let content_type = resp
+        .headers()
+        .get(reqwest::header::CONTENT_TYPE)
+        .and_then(|v| v.to_str().ok())
+        .map(str::to_string);
+    let text = resp.text().await?;
+    if is_json_content_type(content_type.as_deref()) {
+        Ok(RawBody::Json(formatter::RawJson::parse(text)?))
+    } else {
+        Ok(RawBody::Text(text))
+    }
+}
+
+/// True for `application/json` and `+json` media types such as
+/// `application/vnd.api+json`, or when there is no Content-Type at all.
+#[cfg(not(target_arch = "wasm32"))]
+fn is_json_content_type(content_type: Option<&str>) -> bool {
+    let Some(content_type) = content_type else {
+        return true;
+    };
+    let media = content_type
+        .split(';')
+        .next()
+        .unwrap_or_default()
+        .trim()
+        .to_ascii_lowercase();
+    media == "application/json" || media.ends_with("+json")
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn run(
+    cfg: &Config,
+    method: &str,
+    path: &str,
+    body: Option<serde_json::Value>,
+    stream: bool,
+    output_file: Option<&str>,
+) -> Result<()> {
+    if stream || output_file.is_some() {
+        bail!("--stream and --output-file are not available in WASM builds.");
+    }
+    let body = body.unwrap_or_else(|| serde_json::json!({}));
+    let data = match method.to_uppercase().as_str() {
+        "GET" => crate::api::get(cfg, path, &[]).await?,
+        "POST" => crate::api::post(cfg, path, &body).await?,
+        "PUT" => crate::api::put(cfg, path, &body).await?,
+        "PATCH" => crate::api::patch(cfg, path, &body).await?,
+        "DELETE" => crate::api::delete(cfg, path).await?,
+        other => bail!("unsupported HTTP method: {other}"),
+    };
+    formatter::output(cfg, &data)
+}
+
+/// Sends the request and copies the response body to `sink` chunk by chunk,
+/// without buffering or decoding it. The status is checked before anything
+/// is written. Returns the number of bytes written.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn stream_to<W: std::io::Write>(
+    cfg: &Config,
+    method: &str,
+    path: &str,
+    body: Option<&serde_json::Value>,
+    sink: &mut W,
+) -> Result<u64> {
+    let mut resp = client::raw_request(cfg, method, path, body).await?;
+    let mut written = 0u64;
+    while let Some(chunk) = resp
+        .chunk()
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to read response body: {e}"))?
+    {
+        sink.write_all(&chunk)?;
+        written += chunk.len() as u64;
+    }
+    sink.flush()?;
+    Ok(written)
+}
+
+/// Normalizes a user-supplied API path: adds the leading slash and rejects
+/// full URLs, since the host always comes from the configured site.
+pub fn normalize_path(path: &str) -> Result<String> {
+    if path.contains("://") {
+        bail!("expected an API path like /api/v1/validate, not a full URL: {path}");
+    }
+    if path.starts_with('/') {
+        Ok(path.to_string())
+    } else {
+        Ok(format!("/{path}"))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_normalize_path() {
+        assert_eq!(
+            normalize_path("/api/v1/validate").unwrap(),
+            "/api/v1/validate"
+        );
+        assert_eq!(
+            normalize_path("api/v2/users?page[size]=5").unwrap(),
+            "/api/v2/users?page[size]=5"
+        );
+        assert!(normalize_path("https://api.datadoghq.com/api/v1/validate").is_err());
+    }
+
+    #[test]
+    #[cfg(not(target_arch = "wasm32"))]
+    fn test_is_json_content_type() {
+        assert!(is_json_content_type(Some("application/json")));
+        assert!(is_json_content_type(Some(
+            "application/json; charset=utf-8"
+        )));
+        assert!(is_json_content_type(Some("application/vnd.api+json")));
+        assert!(is_json_content_type(None));
+        assert!(!is_json_content_type(Some("text/plain")));
+        assert!(!is_json_content_type(Some("text/csv; charset=utf-8")));
+        assert!(!is_json_content_type(Some("application/yaml")));
+    }
+}
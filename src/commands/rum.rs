@@ -15,9 +15,10 @@ use datadog_api_client::datadogV2::api_rum_replay_playlists::{
 use datadog_api_client::datadogV2::api_rum_retention_filters::RumRetentionFiltersAPI;
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV2::model::{
-    RUMApplicationCreate, RUMApplicationCreateAttributes, RUMApplicationCreateRequest,
-    RUMApplicationCreateType, RUMApplicationUpdateRequest, RUMQueryFilter, RUMSearchEventsRequest,
-    RUMSort, RumMetricCreateRequest, RumMetricUpdateRequest, RumRetentionFilterCreateRequest,
+    RUMAggregateRequest, RUMAggregationFunction, RUMApplicationCreate,
+    RUMApplicationCreateAttributes, RUMApplicationCreateRequest, RUMApplicationCreateType,
+    RUMApplicationUpdateRequest, RUMCompute, RUMQueryFilter, RUMSearchEventsRequest, RUMSort,
+    RumMetricCreateRequest, RumMetricUpdateRequest, RumRetentionFilterCreateRequest,
     RumRetentionFilterUpdateRequest,
 };
 
@@ -50,7 +51,7 @@ pub async fn apps_list(cfg: &Config) -> Result<()> {
 }
 
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn apps_get(cfg: &Config, app_id: &str) -> Result<()> {
+pub async fn apps_get(cfg: &Config, app_id: &str, snippet: bool) -> Result<()> {
     if !cfg.has_api_keys() {
         bail!("RUM apps requires API key authentication (DD_API_KEY + DD_APP_KEY)");
     }
@@ -60,13 +61,22 @@ pub async fn apps_get(cfg: &Config, app_id: &str) -> Result<()> {
         .get_rum_application(app_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to get RUM app: {e:?}"))?;
+    if snippet {
+        let value = serde_json::to_value(&resp)?;
+        formatter::print_block(&format!("{}\n", snippet_from_response(&value, &cfg.site)?))?;
+        return Ok(());
+    }
     formatter::output(cfg, &resp)
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn apps_get(cfg: &Config, app_id: &str) -> Result<()> {
+pub async fn apps_get(cfg: &Config, app_id: &str, snippet: bool) -> Result<()> {
     let path = format!("/api/v2/rum/applications/{app_id}");
     let data = crate::api::get(cfg, &path, &[]).await?;
+    if snippet {
+        formatter::print_block(&format!("{}\n", snippet_from_response(&data, &cfg.site)?))?;
+        return Ok(());
+    }
     crate::formatter::output(cfg, &data)
 }
 
@@ -116,7 +126,7 @@ pub async fn apps_delete(cfg: &Config, app_id: &str) -> Result<()> {
     api.delete_rum_application(app_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete RUM app: {e:?}"))?;
-    println!("Successfully deleted RUM application {app_id}");
+    formatter::print_block(&format!("Successfully deleted RUM application {app_id}\n"))?;
     Ok(())
 }
 
@@ -124,7 +134,7 @@ pub async fn apps_delete(cfg: &Config, app_id: &str) -> Result<()> {
 pub async fn apps_delete(cfg: &Config, app_id: &str) -> Result<()> {
     let path = format!("/api/v2/rum/applications/{app_id}");
     crate::api::delete(cfg, &path).await?;
-    println!("Successfully deleted RUM application {app_id}");
+    formatter::print_block(&format!("Successfully deleted RUM application {app_id}\n"))?;
     Ok(())
 }
 
@@ -172,34 +182,120 @@ pub async fn events_list(cfg: &Config, from: String, to: String, limit: i32) ->
     crate::formatter::output(cfg, &data)
 }
 
+/// Search filter shared by RUM event searches and count aggregates.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn sessions_search(
+fn rum_filter(query: Option<String>, from: String, to: String) -> RUMQueryFilter {
+    let mut filter = RUMQueryFilter::new().from(from).to(to);
+    if let Some(q) = query {
+        filter = filter.query(q);
+    }
+    filter
+}
+
+#[cfg(target_arch = "wasm32")]
+fn rum_filter(query: Option<String>, from: String, to: String) -> serde_json::Value {
+    let mut filter = serde_json::json!({ "from": from, "to": to });
+    if let Some(q) = query {
+        filter["query"] = serde_json::Value::String(q);
+    }
+    filter
+}
+
+/// Aggregate request counting the RUM events matching `query` in a window.
+#[cfg(not(target_arch = "wasm32"))]
+fn count_aggregate_request(query: Option<String>, from: String, to: String) -> RUMAggregateRequest {
+    RUMAggregateRequest::new()
+        .filter(rum_filter(query, from, to))
+        .compute(vec![RUMCompute::new(RUMAggregationFunction::COUNT)])
+}
+
+#[cfg(target_arch = "wasm32")]
+fn count_aggregate_request(query: Option<String>, from: String, to: String) -> serde_json::Value {
+    serde_json::json!({
+        "filter": rum_filter(query, from, to),
+        "compute": [{ "aggregation": "count" }]
+    })
+}
+
+/// Counts the RUM events matching `query` between `from` and `to` (RFC3339).
+#[cfg(not(target_arch = "wasm32"))]
+async fn count_matching(
     cfg: &Config,
     query: Option<String>,
     from: String,
     to: String,
-    _limit: i32,
-) -> Result<()> {
+) -> Result<i64> {
     let dd_cfg = client::make_dd_config(cfg);
     let api = match client::make_bearer_client(cfg) {
         Some(c) => RUMAPI::with_client_and_config(dd_cfg, c),
         None => RUMAPI::with_config(dd_cfg),
     };
+    let resp = api
+        .aggregate_rum_events(count_aggregate_request(query, from, to))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to aggregate RUM events: {e:?}"))?;
+    Ok(util::aggregate_count(&serde_json::to_value(&resp)?))
+}
 
-    let from_str = chrono::DateTime::from_timestamp_millis(util::parse_time_to_unix_millis(&from)?)
-        .unwrap()
-        .to_rfc3339();
-    let to_str = chrono::DateTime::from_timestamp_millis(util::parse_time_to_unix_millis(&to)?)
+#[cfg(target_arch = "wasm32")]
+async fn count_matching(
+    cfg: &Config,
+    query: Option<String>,
+    from: String,
+    to: String,
+) -> Result<i64> {
+    let body = count_aggregate_request(query, from, to);
+    let data = crate::api::post(cfg, "/api/v2/rum/analytics/aggregate", &body).await?;
+    Ok(crate::util::aggregate_count(&data))
+}
+
+fn rfc3339(input: &str) -> Result<String> {
+    let ms = crate::util::parse_time_to_unix_millis(input)?;
+    Ok(chrono::DateTime::from_timestamp_millis(ms)
         .unwrap()
-        .to_rfc3339();
+        .to_rfc3339())
+}
 
-    let mut filter = RUMQueryFilter::new().from(from_str).to(to_str);
-    if let Some(q) = query {
-        filter = filter.query(q);
+/// Output of `rum sessions search --count-only`.
+fn count_result(query: Option<&str>, from: &str, to: &str, count: i64) -> serde_json::Value {
+    serde_json::json!({
+        "query": query.unwrap_or("*"),
+        "from": from,
+        "to": to,
+        "count": count,
+    })
+}
+
+/// Searches RUM sessions. With `count_only`, runs a count aggregate over the
+/// same filter instead of fetching the events.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn sessions_search(
+    cfg: &Config,
+    query: Option<String>,
+    from: String,
+    to: String,
+    _limit: i32,
+    count_only: bool,
+) -> Result<()> {
+    let from_str = rfc3339(&from)?;
+    let to_str = rfc3339(&to)?;
+
+    if count_only {
+        let count = count_matching(cfg, query.clone(), from_str.clone(), to_str.clone()).await?;
+        return formatter::output(
+            cfg,
+            &count_result(query.as_deref(), &from_str, &to_str, count),
+        );
     }
 
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => RUMAPI::with_client_and_config(dd_cfg, c),
+        None => RUMAPI::with_config(dd_cfg),
+    };
+
     let body = RUMSearchEventsRequest::new()
-        .filter(filter)
+        .filter(rum_filter(query, from_str, to_str))
         .sort(RUMSort::TIMESTAMP_DESCENDING);
 
     let resp = api
@@ -216,21 +312,21 @@ pub async fn sessions_search(
     from: String,
     to: String,
     _limit: i32,
+    count_only: bool,
 ) -> Result<()> {
-    let from_ms = crate::util::parse_time_to_unix_millis(&from)?;
-    let to_ms = crate::util::parse_time_to_unix_millis(&to)?;
-    let from_str = chrono::DateTime::from_timestamp_millis(from_ms)
-        .unwrap()
-        .to_rfc3339();
-    let to_str = chrono::DateTime::from_timestamp_millis(to_ms)
-        .unwrap()
-        .to_rfc3339();
-    let mut filter = serde_json::json!({ "from": from_str, "to": to_str });
-    if let Some(q) = query {
-        filter["query"] = serde_json::Value::String(q);
+    let from_str = rfc3339(&from)?;
+    let to_str = rfc3339(&to)?;
+
+    if count_only {
+        let count = count_matching(cfg, query.clone(), from_str.clone(), to_str.clone()).await?;
+        return crate::formatter::output(
+            cfg,
+            &count_result(query.as_deref(), &from_str, &to_str, count),
+        );
     }
+
     let body = serde_json::json!({
-        "filter": filter,
+        "filter": rum_filter(query, from_str, to_str),
         "sort": "-timestamp"
     });
     let data = crate::api::post(cfg, "/api/v2/rum/events/search", &body).await?;
@@ -282,8 +378,29 @@ pub async fn metrics_list(cfg: &Config) -> Result<()> {
     crate::formatter::output(cfg, &data)
 }
 
+/// The RUM search a custom metric counts: its event type plus its filter.
+fn metric_volume_query(metric: &serde_json::Value) -> String {
+    let attrs = metric.pointer("/data/attributes");
+    let event_type = attrs
+        .and_then(|a| a.get("event_type"))
+        .and_then(|t| t.as_str())
+        .filter(|t| !t.is_empty());
+    let filter = attrs
+        .and_then(|a| a.pointer("/filter/query"))
+        .and_then(|q| q.as_str())
+        .filter(|q| !q.is_empty() && *q != "*");
+    match (event_type, filter) {
+        (Some(t), Some(q)) => format!("@type:{t} ({q})"),
+        (Some(t), None) => format!("@type:{t}"),
+        (None, Some(q)) => q.to_string(),
+        (None, None) => "*".to_string(),
+    }
+}
+
+/// Prints a RUM custom metric. With `volume_window`, also counts the RUM
+/// events it currently matches over that window.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn metrics_get(cfg: &Config, metric_id: &str) -> Result<()> {
+pub async fn metrics_get(cfg: &Config, metric_id: &str, volume_window: Option<&str>) -> Result<()> {
     if !cfg.has_api_keys() {
         bail!("RUM metrics requires API key authentication (DD_API_KEY + DD_APP_KEY)");
     }
@@ -293,13 +410,27 @@ pub async fn metrics_get(cfg: &Config, metric_id: &str) -> Result<()> {
         .get_rum_metric(metric_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to get RUM metric: {e:?}"))?;
-    formatter::output(cfg, &resp)
+
+    let Some(window) = volume_window else {
+        return formatter::output(cfg, &resp);
+    };
+    let mut data = serde_json::to_value(&resp)?;
+    let query = metric_volume_query(&data);
+    let count = count_matching(cfg, Some(query.clone()), rfc3339(window)?, rfc3339("now")?).await?;
+    util::attach_volume(&mut data, &query, window, count);
+    formatter::output(cfg, &data)
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn metrics_get(cfg: &Config, metric_id: &str) -> Result<()> {
+pub async fn metrics_get(cfg: &Config, metric_id: &str, volume_window: Option<&str>) -> Result<()> {
     let path = format!("/api/v2/rum/metrics/{metric_id}");
-    let data = crate::api::get(cfg, &path, &[]).await?;
+    let mut data = crate::api::get(cfg, &path, &[]).await?;
+    if let Some(window) = volume_window {
+        let query = metric_volume_query(&data);
+        let count =
+            count_matching(cfg, Some(query.clone()), rfc3339(window)?, rfc3339("now")?).await?;
+        crate::util::attach_volume(&mut data, &query, window, count);
+    }
     crate::formatter::output(cfg, &data)
 }
 
@@ -358,7 +489,7 @@ pub async fn metrics_delete(cfg: &Config, metric_id: &str) -> Result<()> {
     api.delete_rum_metric(metric_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete RUM metric: {e:?}"))?;
-    println!("RUM metric {metric_id} deleted.");
+    formatter::print_block(&format!("RUM metric {metric_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -366,7 +497,7 @@ pub async fn metrics_delete(cfg: &Config, metric_id: &str) -> Result<()> {
 pub async fn metrics_delete(cfg: &Config, metric_id: &str) -> Result<()> {
     let path = format!("/api/v2/rum/metrics/{metric_id}");
     crate::api::delete(cfg, &path).await?;
-    println!("RUM metric {metric_id} deleted.");
+    formatter::print_block(&format!("RUM metric {metric_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -480,7 +611,7 @@ pub async fn retention_filters_delete(cfg: &Config, app_id: &str, filter_id: &st
     api.delete_retention_filter(app_id.to_string(), filter_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete RUM retention filter: {e:?}"))?;
-    println!("RUM retention filter {filter_id} deleted.");
+    formatter::print_block(&format!("RUM retention filter {filter_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -488,7 +619,7 @@ pub async fn retention_filters_delete(cfg: &Config, app_id: &str, filter_id: &st
 pub async fn retention_filters_delete(cfg: &Config, app_id: &str, filter_id: &str) -> Result<()> {
     let path = format!("/api/v2/rum/applications/{app_id}/retention_filters/{filter_id}");
     crate::api::delete(cfg, &path).await?;
-    println!("RUM retention filter {filter_id} deleted.");
+    formatter::print_block(&format!("RUM retention filter {filter_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -619,3 +750,225 @@ pub async fn heatmaps_query(cfg: &Config, view_name: &str) -> Result<()> {
     let data = crate::api::get(cfg, "/api/v2/rum/replay/heatmap/snapshots", &query).await?;
     crate::formatter::output(cfg, &data)
 }
+
+// ---- Install snippets ----
+
+/// Extracts the app type and ids from a get-application response and renders its snippet.
+fn snippet_from_response(resp: &serde_json::Value, site: &str) -> Result<String> {
+    let attrs = &resp["data"]["attributes"];
+    let field = |key: &str| -> Result<&str> {
+        attrs[key]
+            .as_str()
+            .ok_or_else(|| anyhow::anyhow!("RUM application response is missing {key}"))
+    };
+    install_snippet(
+        field("type")?,
+        field("application_id")?,
+        field("client_token")?,
+        site,
+        attrs["name"].as_str().unwrap_or("<SERVICE_NAME>"),
+    )
+}
+
+/// Maps a Datadog site to the site constant used by the mobile SDKs.
+fn mobile_sdk_site(site: &str) -> &'static str {
+    match site {
+        "us3.datadoghq.com" => "US3",
+        "us5.datadoghq.com" => "US5",
+        "datadoghq.eu" => "EU1",
+        "ap1.datadoghq.com" => "AP1",
+        "ddog-gov.com" => "US1_FED",
+        _ => "US1",
+    }
+}
+
+/// Renders the SDK initialization snippet for a RUM application, choosing the
+/// template by application type (browser, ios, android, react-native, flutter).
+pub fn install_snippet(
+    app_type: &str,
+    application_id: &str,
+    client_token: &str,
+    site: &str,
+    service: &str,
+) -> Result<String> {
+    let mobile_site = mobile_sdk_site(site);
+    let snippet = match app_type {
+        "browser" => format!(
+            r#"import {{ datadogRum }} from '@datadog/browser-rum';
+
+datadogRum.init({{
+    applicationId: '{application_id}',
+    clientToken: '{client_token}',
+    site: '{site}',
+    service: '{service}',
+    env: '<ENV_NAME>',
+    sessionSampleRate: 100,
+    sessionReplaySampleRate: 20,
+    defaultPrivacyLevel: 'mask-user-input',
+}});"#
+        ),
+        "ios" => format!(
+            r#"import DatadogCore
+import DatadogRUM
+
+Datadog.initialize(
+    with: Datadog.Configuration(
+        clientToken: "{client_token}",
+        env: "<ENV_NAME>",
+        site: .{site_lower}
+    ),
+    trackingConsent: .granted
+)
+RUM.enable(with: RUM.Configuration(applicationID: "{application_id}"))"#,
+            site_lower = mobile_site.to_lowercase(),
+        ),
+        "android" => format!(
+            r#"val configuration = Configuration.Builder(
+    clientToken = "{client_token}",
+    env = "<ENV_NAME>"
+).useSite(DatadogSite.{mobile_site}).build()
+Datadog.initialize(this, configuration, TrackingConsent.GRANTED)
+
+val rumConfiguration = RumConfiguration.Builder("{application_id}").build()
+Rum.enable(rumConfiguration)"#
+        ),
+        "react-native" => format!(
+            r#"import {{ DdSdkReactNative, DdSdkReactNativeConfiguration }} from '@datadog/mobile-react-native';
+
+const config = new DdSdkReactNativeConfiguration(
+    '{client_token}',
+    '<ENV_NAME>',
+    '{application_id}',
+    true, // track user interactions
+    true, // track XHR resources
+    true  // track errors
+);
+config.site = '{mobile_site}';
+
+DdSdkReactNative.initialize(config);"#
+        ),
+        "flutter" => format!(
+            r#"final configuration = DatadogConfiguration(
+  clientToken: '{client_token}',
+  env: '<ENV_NAME>',
+  site: DatadogSite.{site_lower},
+  rumConfiguration: DatadogRumConfiguration(
+    applicationId: '{application_id}',
+  ),
+);
+
+await DatadogSdk.runApp(configuration, TrackingConsent.granted, () async {{
+  runApp(const MyApp());
+}});"#,
+            // Dart enum values are camelCase (us1Fed)
+            site_lower = mobile_site.to_lowercase().replace("_fed", "Fed"),
+        ),
+        other => bail!(
+            "no install snippet for RUM application type {other:?} \
+             (supported: browser, ios, android, react-native, flutter)"
+        ),
+    };
+    Ok(snippet)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_browser_snippet_includes_ids() {
+        let snippet =
+            install_snippet("browser", "app-123", "pub-abc", "datadoghq.eu", "checkout").unwrap();
+        assert!(snippet.contains("@datadog/browser-rum"));
+        assert!(snippet.contains("applicationId: 'app-123'"));
+        assert!(snippet.contains("clientToken: 'pub-abc'"));
+        assert!(snippet.contains("site: 'datadoghq.eu'"));
+        assert!(snippet.contains("service: 'checkout'"));
+    }
+
+    #[test]
+    fn test_mobile_snippets_use_sdk_site() {
+        let android = install_snippet("android", "app-1", "pub-1", "datadoghq.eu", "x").unwrap();
+        assert!(android.contains("DatadogSite.EU1"));
+        assert!(android.contains(r#"RumConfiguration.Builder("app-1")"#));
+
+        let ios = install_snippet("ios", "app-1", "pub-1", "us5.datadoghq.com", "x").unwrap();
+        assert!(ios.contains("site: .us5"));
+        assert!(ios.contains(r#"applicationID: "app-1""#));
+
+        let flutter = install_snippet("flutter", "app-1", "pub-1", "ddog-gov.com", "x").unwrap();
+        assert!(flutter.contains("DatadogSite.us1Fed"));
+    }
+
+    #[test]
+    fn test_unknown_app_type() {
+        let err = install_snippet("roku", "a", "b", "datadoghq.com", "x")
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("roku"));
+    }
+
+    #[test]
+    fn test_snippet_from_response() {
+        let resp = serde_json::json!({
+            "data": {
+                "id": "app-123",
+                "type": "rum_application",
+                "attributes": {
+                    "application_id": "app-123",
+                    "client_token": "pub-abc",
+                    "name": "web",
+                    "type": "browser"
+                }
+            }
+        });
+        let snippet = snippet_from_response(&resp, "datadoghq.com").unwrap();
+        assert!(snippet.contains("applicationId: 'app-123'"));
+        assert!(snippet.contains("clientToken: 'pub-abc'"));
+
+        let missing = serde_json::json!({ "data": { "attributes": { "type": "browser" } } });
+        assert!(snippet_from_response(&missing, "datadoghq.com").is_err());
+    }
+
+    #[test]
+    fn test_metric_volume_query() {
+        let metric = |attrs: serde_json::Value| serde_json::json!({"data": {"attributes": attrs}});
+        assert_eq!(
+            metric_volume_query(&metric(serde_json::json!({
+                "event_type": "action",
+                "filter": {"query": "@action.type:click"}
+            }))),
+            "@type:action (@action.type:click)"
+        );
+        assert_eq!(
+            metric_volume_query(&metric(serde_json::json!({
+                "event_type": "session",
+                "filter": {"query": "*"}
+            }))),
+            "@type:session"
+        );
+        assert_eq!(
+            metric_volume_query(&metric(
+                serde_json::json!({"filter": {"query": "@view.url_path:/cart"}})
+            )),
+            "@view.url_path:/cart"
+        );
+        assert_eq!(metric_volume_query(&serde_json::json!({})), "*");
+    }
+
+    #[test]
+    fn test_count_result() {
+        let out = count_result(
+            None,
+            "2024-01-01T00:00:00+00:00",
+            "2024-01-01T01:00:00+00:00",
+            7,
+        );
+        assert_eq!(out["query"], "*");
+        assert_eq!(out["count"], 7);
+        assert_eq!(
+            count_result(Some("@type:view"), "a", "b", 0)["query"],
+            "@type:view"
+        );
+    }
+}
@@ -178,7 +178,7 @@ pub async fn rules_bulk_export(cfg: &Config, rule_ids: Vec<String>) -> Result<()
         .map_err(|e| anyhow::anyhow!("failed to bulk export security rules: {e:?}"))?;
     // resp is Vec<u8> (ZIP data), output as raw bytes to stdout
     let output = String::from_utf8_lossy(&resp);
-    println!("{output}");
+    formatter::print_block(&format!("{output}\n"))?;
     Ok(())
 }
 
@@ -194,7 +194,7 @@ pub async fn rules_bulk_export(cfg: &Config, rule_ids: Vec<String>) -> Result<()
     });
     let data =
         crate::api::post(cfg, "/api/v2/security_monitoring/rules/_bulk_export", &body).await?;
-    println!("{data}");
+    formatter::print_block(&format!("{data}\n"))?;
     Ok(())
 }
 
@@ -230,7 +230,9 @@ pub async fn content_packs_activate(cfg: &Config, pack_id: &str) -> Result<()> {
     api.activate_content_pack(pack_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to activate content pack: {e:?}"))?;
-    println!("Content pack '{pack_id}' activated successfully.");
+    formatter::print_block(&format!(
+        "Content pack '{pack_id}' activated successfully.\n"
+    ))?;
     Ok(())
 }
 
@@ -243,7 +245,9 @@ pub async fn content_packs_activate(cfg: &Config, pack_id: &str) -> Result<()> {
         &body,
     )
     .await?;
-    println!("Content pack '{pack_id}' activated successfully.");
+    formatter::print_block(&format!(
+        "Content pack '{pack_id}' activated successfully.\n"
+    ))?;
     Ok(())
 }
 
@@ -257,7 +261,9 @@ pub async fn content_packs_deactivate(cfg: &Config, pack_id: &str) -> Result<()>
     api.deactivate_content_pack(pack_id.to_string())
         .await
         .map_err(|e| anyhow::anyhow!("failed to deactivate content pack: {e:?}"))?;
-    println!("Content pack '{pack_id}' deactivated successfully.");
+    formatter::print_block(&format!(
+        "Content pack '{pack_id}' deactivated successfully.\n"
+    ))?;
     Ok(())
 }
 
@@ -270,7 +276,9 @@ pub async fn content_packs_deactivate(cfg: &Config, pack_id: &str) -> Result<()>
         &body,
     )
     .await?;
-    println!("Content pack '{pack_id}' deactivated successfully.");
+    formatter::print_block(&format!(
+        "Content pack '{pack_id}' deactivated successfully.\n"
+    ))?;
     Ok(())
 }
 
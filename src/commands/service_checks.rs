@@ -0,0 +1,131 @@
+use anyhow::{bail, Result};
+#[cfg(not(target_arch = "wasm32"))]
+use datadog_api_client::datadogV1::api_service_checks::ServiceChecksAPI;
+#[cfg(not(target_arch = "wasm32"))]
+use datadog_api_client::datadogV1::model::ServiceCheck;
+
+#[cfg(not(target_arch = "wasm32"))]
+use crate::client;
+use crate::config::Config;
+use crate::formatter;
+
+/// Status names accepted by `service-checks submit`, in the order of the
+/// numeric codes the intake expects (0 = ok ... 3 = unknown).
+pub const STATUSES: &[&str] = &["ok", "warning", "critical", "unknown"];
+
+/// Flags for a single `service-checks submit`.
+pub struct CheckRun {
+    pub check: String,
+    pub status: String,
+    pub host: String,
+    pub tags: Option<String>,
+    pub message: Option<String>,
+}
+
+/// Reports one check run through the v1 service check intake.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn submit(cfg: &Config, run: &CheckRun) -> Result<()> {
+    if cfg.api_key.is_none() {
+        bail!(
+            "service-checks submit requires an API key (DD_API_KEY).\n\
+             This endpoint does not support bearer token auth."
+        );
+    }
+    let body: Vec<ServiceCheck> = serde_json::from_value(submit_body(run)?)
+        .map_err(|e| anyhow::anyhow!("invalid service check: {e}"))?;
+    let api = ServiceChecksAPI::with_config(client::make_dd_config(cfg));
+    let resp = api
+        .submit_service_check(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to submit service check: {e:?}"))?;
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn submit(cfg: &Config, run: &CheckRun) -> Result<()> {
+    let data = crate::api::post(cfg, "/api/v1/check_run", &submit_body(run)?).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// Maps a `--status` name to the intake's numeric status code.
+fn status_code(status: &str) -> Result<usize> {
+    match STATUSES.iter().position(|s| s.eq_ignore_ascii_case(status)) {
+        Some(code) => Ok(code),
+        None => bail!(
+            "invalid status {status:?}: expected one of {}",
+            STATUSES.join(", ")
+        ),
+    }
+}
+
+/// The check run intake body: an array holding the one check run.
+fn submit_body(run: &CheckRun) -> Result<serde_json::Value> {
+    if run.check.trim().is_empty() {
+        bail!("--check is required");
+    }
+    if run.host.trim().is_empty() {
+        bail!("--host is required");
+    }
+    let tags: Vec<&str> = run
+        .tags
+        .as_deref()
+        .unwrap_or_default()
+        .split(',')
+        .map(str::trim)
+        .filter(|t| !t.is_empty())
+        .collect();
+    let mut check = serde_json::json!({
+        "check": run.check,
+        "host_name": run.host,
+        "status": status_code(&run.status)?,
+        "tags": tags,
+    });
+    if let Some(message) = &run.message {
+        check["message"] = message.clone().into();
+    }
+    Ok(serde_json::json!([check]))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn run(status: &str) -> CheckRun {
+        CheckRun {
+            check: "backup.nightly".into(),
+            status: status.into(),
+            host: "db-1".into(),
+            tags: Some("env:prod, team:data,".into()),
+            message: None,
+        }
+    }
+
+    #[test]
+    fn test_submit_body_maps_status_and_splits_tags() {
+        let body = submit_body(&run("Critical")).unwrap();
+        assert_eq!(
+            body,
+            serde_json::json!([{
+                "check": "backup.nightly",
+                "host_name": "db-1",
+                "status": 2,
+                "tags": ["env:prod", "team:data"],
+            }])
+        );
+    }
+
+    #[test]
+    fn test_submit_body_rejects_unknown_status() {
+        let err = submit_body(&run("down")).unwrap_err().to_string();
+        assert!(err.contains("ok, warning, critical, unknown"), "{err}");
+    }
+
+    #[test]
+    fn test_submit_body_keeps_message() {
+        let mut r = run("ok");
+        r.message = Some("backup finished".into());
+        let body = submit_body(&r).unwrap();
+        assert_eq!(body[0]["status"], 0);
+        assert_eq!(body[0]["message"], "backup finished");
+    }
+}
@@ -1,8 +1,8 @@
 use anyhow::Result;
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::api_service_level_objectives::{
-    DeleteSLOOptionalParams, GetSLOOptionalParams, ListSLOsOptionalParams,
-    ServiceLevelObjectivesAPI,
+    DeleteSLOOptionalParams, GetSLOHistoryOptionalParams, GetSLOOptionalParams,
+    ListSLOsOptionalParams, ServiceLevelObjectivesAPI,
 };
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::model::{ServiceLevelObjective, ServiceLevelObjectiveRequest};
@@ -33,8 +33,13 @@ pub async fn list(cfg: &Config) -> Result<()> {
     crate::formatter::output(cfg, &data)
 }
 
+/// Prints SLO `id`, or with `breakdown` its per-group SLI over that
+/// `(from_ts, to_ts)` window instead.
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn get(cfg: &Config, id: &str) -> Result<()> {
+pub async fn get(cfg: &Config, id: &str, breakdown: Option<(i64, i64)>) -> Result<()> {
+    if let Some((from_ts, to_ts)) = breakdown {
+        return output_breakdown(cfg, id, from_ts, to_ts).await;
+    }
     let dd_cfg = client::make_dd_config(cfg);
     let api = match client::make_bearer_client(cfg) {
         Some(c) => ServiceLevelObjectivesAPI::with_client_and_config(dd_cfg, c),
@@ -48,7 +53,10 @@ pub async fn get(cfg: &Config, id: &str) -> Result<()> {
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn get(cfg: &Config, id: &str) -> Result<()> {
+pub async fn get(cfg: &Config, id: &str, breakdown: Option<(i64, i64)>) -> Result<()> {
+    if let Some((from_ts, to_ts)) = breakdown {
+        return output_breakdown(cfg, id, from_ts, to_ts).await;
+    }
     let data = crate::api::get(cfg, &format!("/api/v1/slo/{id}"), &[]).await?;
     crate::formatter::output(cfg, &data)
 }
@@ -75,6 +83,118 @@ pub async fn create(cfg: &Config, file: &str) -> Result<()> {
     crate::formatter::output(cfg, &data)
 }
 
+/// SLO timeframes accepted by `create --from-monitor`.
+const SLO_TIMEFRAMES: &[&str] = &["7d", "30d", "90d"];
+
+/// Flags for `slos create --from-monitor`.
+pub struct FromMonitor {
+    pub monitor_id: i64,
+    pub target: f64,
+    pub timeframe: String,
+    pub name: Option<String>,
+    pub description: Option<String>,
+}
+
+/// Creates a monitor-based SLO tracking `monitor_id`, named after the monitor
+/// unless a name is given, and prints the new SLO's id on stderr.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn create_from_monitor(cfg: &Config, opts: FromMonitor) -> Result<()> {
+    use datadog_api_client::datadogV1::api_monitors::{GetMonitorOptionalParams, MonitorsAPI};
+
+    let timeframe = parse_timeframe(&opts.timeframe)?;
+    validate_target(opts.target)?;
+    let dd_cfg = client::make_dd_config(cfg);
+    let (monitors, api) = match client::make_bearer_client(cfg) {
+        Some(c) => (
+            MonitorsAPI::with_client_and_config(dd_cfg.clone(), c.clone()),
+            ServiceLevelObjectivesAPI::with_client_and_config(dd_cfg, c),
+        ),
+        None => (
+            MonitorsAPI::with_config(dd_cfg.clone()),
+            ServiceLevelObjectivesAPI::with_config(dd_cfg),
+        ),
+    };
+    let monitor = monitors
+        .get_monitor(opts.monitor_id, GetMonitorOptionalParams::default())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get monitor {}: {e:?}", opts.monitor_id))?;
+    let body = monitor_slo_body(&serde_json::to_value(&monitor)?, &opts, timeframe);
+    let body: ServiceLevelObjectiveRequest = serde_json::from_value(body)?;
+    let resp = api
+        .create_slo(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to create SLO: {e:?}"))?;
+    let resp = serde_json::to_value(&resp)?;
+    if let Some(id) = resp["data"][0]["id"].as_str() {
+        formatter::print_status(&format!("Created SLO {id}."));
+    }
+    formatter::output(cfg, &resp)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn create_from_monitor(cfg: &Config, opts: FromMonitor) -> Result<()> {
+    let timeframe = parse_timeframe(&opts.timeframe)?;
+    validate_target(opts.target)?;
+    let path = format!("/api/v1/monitor/{}", opts.monitor_id);
+    let monitor = crate::api::get(cfg, &path, &[]).await?;
+    let body = monitor_slo_body(&monitor, &opts, timeframe);
+    let resp = crate::api::post(cfg, "/api/v1/slo", &body).await?;
+    if let Some(id) = resp["data"][0]["id"].as_str() {
+        formatter::print_status(&format!("Created SLO {id}."));
+    }
+    crate::formatter::output(cfg, &resp)
+}
+
+/// Maps a CLI timeframe (`30d`, `30 days`) to one the SLO API accepts.
+pub fn parse_timeframe(input: &str) -> Result<&'static str> {
+    let normalized = input
+        .trim()
+        .to_lowercase()
+        .replace("days", "d")
+        .replace(' ', "");
+    SLO_TIMEFRAMES
+        .iter()
+        .find(|t| **t == normalized)
+        .copied()
+        .ok_or_else(|| {
+            anyhow::anyhow!(
+                "invalid SLO timeframe {input:?}: expected one of {}",
+                SLO_TIMEFRAMES.join(", ")
+            )
+        })
+}
+
+/// SLO targets are percentages strictly between 0 and 100.
+pub fn validate_target(target: f64) -> Result<()> {
+    if !(target > 0.0 && target < 100.0) {
+        anyhow::bail!("invalid SLO target {target}: expected a percentage between 0 and 100");
+    }
+    Ok(())
+}
+
+fn monitor_slo_body(
+    monitor: &serde_json::Value,
+    opts: &FromMonitor,
+    timeframe: &str,
+) -> serde_json::Value {
+    let name = match (&opts.name, monitor["name"].as_str()) {
+        (Some(name), _) => name.clone(),
+        (None, Some(monitor_name)) => monitor_name.to_string(),
+        (None, None) => format!("Monitor {} SLO", opts.monitor_id),
+    };
+    let mut body = serde_json::json!({
+        "type": "monitor",
+        "name": name,
+        "monitor_ids": [opts.monitor_id],
+        "thresholds": [{"timeframe": timeframe, "target": opts.target}],
+        "tags": monitor["tags"].as_array().cloned().unwrap_or_default(),
+    });
+    if let Some(desc) = &opts.description {
+        body["description"] = serde_json::Value::String(desc.clone());
+    }
+    body
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn update(cfg: &Config, id: &str, file: &str) -> Result<()> {
     let body: ServiceLevelObjective = util::read_json_file(file)?;
@@ -118,7 +238,16 @@ pub async fn delete(cfg: &Config, id: &str) -> Result<()> {
 }
 
 #[cfg(not(target_arch = "wasm32"))]
-pub async fn status(cfg: &Config, id: &str, from_ts: i64, to_ts: i64) -> Result<()> {
+pub async fn status(
+    cfg: &Config,
+    id: &str,
+    from_ts: i64,
+    to_ts: i64,
+    with_sli_breakdown: bool,
+) -> Result<()> {
+    if with_sli_breakdown {
+        return output_breakdown(cfg, id, from_ts, to_ts).await;
+    }
     use datadog_api_client::datadogV2::api_service_level_objectives::{
         GetSloStatusOptionalParams, ServiceLevelObjectivesAPI as SloV2API,
     };
@@ -141,7 +270,16 @@ pub async fn status(cfg: &Config, id: &str, from_ts: i64, to_ts: i64) -> Result<
 }
 
 #[cfg(target_arch = "wasm32")]
-pub async fn status(cfg: &Config, id: &str, from_ts: i64, to_ts: i64) -> Result<()> {
+pub async fn status(
+    cfg: &Config,
+    id: &str,
+    from_ts: i64,
+    to_ts: i64,
+    with_sli_breakdown: bool,
+) -> Result<()> {
+    if with_sli_breakdown {
+        return output_breakdown(cfg, id, from_ts, to_ts).await;
+    }
     let query = vec![
         ("from_ts", from_ts.to_string()),
         ("to_ts", to_ts.to_string()),
@@ -149,3 +287,664 @@ pub async fn status(cfg: &Config, id: &str, from_ts: i64, to_ts: i64) -> Result<
     let data = crate::api::get(cfg, &format!("/api/v2/slo/{id}/status"), &query).await?;
     crate::formatter::output(cfg, &data)
 }
+
+// ---- SLI breakdown ----
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn output_breakdown(cfg: &Config, id: &str, from_ts: i64, to_ts: i64) -> Result<()> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => ServiceLevelObjectivesAPI::with_client_and_config(dd_cfg, c),
+        None => ServiceLevelObjectivesAPI::with_config(dd_cfg),
+    };
+    let resp = api
+        .get_slo_history(
+            id.to_string(),
+            from_ts,
+            to_ts,
+            GetSLOHistoryOptionalParams::default(),
+        )
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get SLO history: {e:?}"))?;
+    let history = serde_json::to_value(&resp)?;
+    formatter::output(cfg, &sli_breakdown(&history))
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn output_breakdown(cfg: &Config, id: &str, from_ts: i64, to_ts: i64) -> Result<()> {
+    let query = vec![
+        ("from_ts", from_ts.to_string()),
+        ("to_ts", to_ts.to_string()),
+    ];
+    let history = crate::api::get(cfg, &format!("/api/v1/slo/{id}/history"), &query).await?;
+    crate::formatter::output(cfg, &sli_breakdown(&history))
+}
+
+/// Builds one row per SLO group from an SLO history response: the group,
+/// SLI, and error budget remaining. The history API reports event counts
+/// only for the SLO as a whole, so rows carry no per-group good/bad events.
+/// Rows are sorted worst-first: least budget remaining, then lowest SLI;
+/// groups without either value sort last.
+pub fn sli_breakdown(history: &serde_json::Value) -> serde_json::Value {
+    let data = history.get("data").unwrap_or(history);
+    let groups = data
+        .get("groups")
+        .and_then(|g| g.as_array())
+        .filter(|g| !g.is_empty())
+        .or_else(|| data.get("monitors").and_then(|m| m.as_array()));
+
+    let mut rows: Vec<serde_json::Value> = groups
+        .into_iter()
+        .flatten()
+        .map(|g| {
+            let group = g
+                .get("group")
+                .or_else(|| g.get("name"))
+                .and_then(|v| v.as_str())
+                .unwrap_or("");
+            serde_json::json!({
+                "group": group,
+                "sli": g.get("sli_value").and_then(|v| v.as_f64()),
+                "budget_remaining": budget_remaining(g.get("error_budget_remaining")),
+            })
+        })
+        .collect();
+
+    rows.sort_by(|a, b| {
+        let key = |row: &serde_json::Value| {
+            (
+                row["budget_remaining"].as_f64().unwrap_or(f64::INFINITY),
+                row["sli"].as_f64().unwrap_or(f64::INFINITY),
+            )
+        };
+        let (ka, kb) = (key(a), key(b));
+        ka.0.total_cmp(&kb.0).then(ka.1.total_cmp(&kb.1))
+    });
+    serde_json::Value::Array(rows)
+}
+
+/// The API keys budget remaining by timeframe (`{"7d": 42.0}`); a group
+/// only ever carries the SLO's own timeframes, so the first value is used.
+fn budget_remaining(v: Option<&serde_json::Value>) -> Option<f64> {
+    let v = v?;
+    match v {
+        serde_json::Value::Object(map) => map.values().find_map(|b| b.as_f64()),
+        other => other.as_f64(),
+    }
+}
+
+// ---- History report ----
+
+/// Prints SLI, error budget remaining, and burn rate over `[from_ts, to_ts]`
+/// for SLO `id`, or one row per SLO whose name matches `query`.
+pub async fn history(
+    cfg: &Config,
+    id: Option<&str>,
+    query: Option<&str>,
+    from_ts: i64,
+    to_ts: i64,
+) -> Result<()> {
+    if from_ts >= to_ts {
+        anyhow::bail!("--from must be before --to");
+    }
+    let ids = match (id, query) {
+        (Some(id), _) => vec![id.to_string()],
+        (None, Some(query)) => matching_slo_ids(cfg, query).await?,
+        (None, None) => anyhow::bail!("an SLO id or --query is required"),
+    };
+    if ids.is_empty() {
+        formatter::print_status(&format!(
+            "No SLOs match query {:?}.",
+            query.unwrap_or_default()
+        ));
+        return Ok(());
+    }
+    let mut rows = Vec::with_capacity(ids.len());
+    for id in &ids {
+        let history = slo_history(cfg, id, from_ts, to_ts).await?;
+        rows.push(history_row(id, &history));
+    }
+    if id.is_some() {
+        return formatter::output(cfg, &rows.remove(0));
+    }
+    formatter::output(cfg, &rows)
+}
+
+/// One report row from an SLO history response. Burn rate is the observed
+/// error rate over the allowed one: 1.0 spends the budget exactly over the
+/// SLO's timeframe, above 1.0 exhausts it early.
+pub fn history_row(id: &str, history: &serde_json::Value) -> serde_json::Value {
+    let data = history.get("data").unwrap_or(history);
+    let threshold = data["slo"]["thresholds"]
+        .as_array()
+        .and_then(|t| t.first())
+        .or_else(|| {
+            data["thresholds"]
+                .as_object()
+                .and_then(|t| t.values().next())
+        });
+    let timeframe = threshold.and_then(|t| t["timeframe"].as_str());
+    let target = threshold.and_then(|t| t["target"].as_f64());
+    let sli = data["overall"]["sli_value"].as_f64();
+    let budget = &data["overall"]["error_budget_remaining"];
+    let budget_remaining = timeframe
+        .and_then(|tf| budget[tf].as_f64())
+        .or_else(|| budget_remaining(Some(budget)));
+    let burn_rate = match (sli, target) {
+        (Some(sli), Some(target)) if target < 100.0 => Some((100.0 - sli) / (100.0 - target)),
+        _ => None,
+    };
+    serde_json::json!({
+        "id": id,
+        "name": data["slo"]["name"].as_str().unwrap_or_default(),
+        "timeframe": timeframe,
+        "target": target,
+        "sli": sli,
+        "budget_remaining": budget_remaining,
+        "burn_rate": burn_rate,
+    })
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn slo_history(
+    cfg: &Config,
+    id: &str,
+    from_ts: i64,
+    to_ts: i64,
+) -> Result<serde_json::Value> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => ServiceLevelObjectivesAPI::with_client_and_config(dd_cfg, c),
+        None => ServiceLevelObjectivesAPI::with_config(dd_cfg),
+    };
+    let resp = api
+        .get_slo_history(
+            id.to_string(),
+            from_ts,
+            to_ts,
+            GetSLOHistoryOptionalParams::default(),
+        )
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get SLO history for {id}: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn slo_history(
+    cfg: &Config,
+    id: &str,
+    from_ts: i64,
+    to_ts: i64,
+) -> Result<serde_json::Value> {
+    let query = vec![
+        ("from_ts", from_ts.to_string()),
+        ("to_ts", to_ts.to_string()),
+    ];
+    crate::api::get(cfg, &format!("/api/v1/slo/{id}/history"), &query).await
+}
+
+/// Ids of the SLOs whose name matches `query`.
+#[cfg(not(target_arch = "wasm32"))]
+async fn matching_slo_ids(cfg: &Config, query: &str) -> Result<Vec<String>> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => ServiceLevelObjectivesAPI::with_client_and_config(dd_cfg, c),
+        None => ServiceLevelObjectivesAPI::with_config(dd_cfg),
+    };
+    let resp = api
+        .list_slos(ListSLOsOptionalParams::default().query(query.to_string()))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list SLOs: {e:?}"))?;
+    Ok(slo_ids(&serde_json::to_value(&resp)?))
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn matching_slo_ids(cfg: &Config, query: &str) -> Result<Vec<String>> {
+    let params = vec![("query", query.to_string())];
+    let data = crate::api::get(cfg, "/api/v1/slo", &params).await?;
+    Ok(slo_ids(&data))
+}
+
+fn slo_ids(list: &serde_json::Value) -> Vec<String> {
+    list["data"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter_map(|slo| slo["id"].as_str().map(str::to_string))
+        .collect()
+}
+
+// ---- SLO Corrections ----
+
+/// Correction categories accepted by the API, keyed by their CLI spelling.
+const CORRECTION_CATEGORIES: &[(&str, &str)] = &[
+    ("scheduled_maintenance", "Scheduled Maintenance"),
+    ("outside_business_hours", "Outside Business Hours"),
+    ("deployment", "Deployment"),
+    ("other", "Other"),
+];
+
+/// Maps a CLI category (e.g. `scheduled_maintenance` or `"Scheduled Maintenance"`)
+/// to the value expected by the SLO corrections API.
+pub fn parse_correction_category(input: &str) -> Result<&'static str> {
+    let normalized = input.trim().to_lowercase().replace([' ', '-'], "_");
+    CORRECTION_CATEGORIES
+        .iter()
+        .find(|(key, _)| *key == normalized)
+        .map(|(_, api_value)| *api_value)
+        .ok_or_else(|| {
+            let valid: Vec<&str> = CORRECTION_CATEGORIES.iter().map(|(k, _)| *k).collect();
+            anyhow::anyhow!(
+                "invalid correction category {input:?}: expected one of {}",
+                valid.join(", ")
+            )
+        })
+}
+
+fn correction_body(
+    slo_id: &str,
+    start: i64,
+    end: i64,
+    category: &str,
+    description: Option<&str>,
+) -> serde_json::Value {
+    let mut attributes = serde_json::json!({
+        "slo_id": slo_id,
+        "start": start,
+        "end": end,
+        "category": category,
+        "timezone": "UTC",
+    });
+    if let Some(desc) = description {
+        attributes["description"] = serde_json::Value::String(desc.to_string());
+    }
+    serde_json::json!({
+        "data": {
+            "type": "correction",
+            "attributes": attributes,
+        }
+    })
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn corrections_list(cfg: &Config, slo_id: &str) -> Result<()> {
+    let data = client::raw_get(cfg, &format!("/api/v1/slo/{slo_id}/corrections")).await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn corrections_list(cfg: &Config, slo_id: &str) -> Result<()> {
+    let data = crate::api::get(cfg, &format!("/api/v1/slo/{slo_id}/corrections"), &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+/// A correction that repeats: each occurrence lasts `duration` seconds,
+/// starting at the times `rrule` (an iCalendar RRULE) yields.
+#[derive(Clone, Debug)]
+pub struct CorrectionRecurrence {
+    pub rrule: String,
+    pub duration: i64,
+}
+
+/// Fields changed by `slos corrections update`; `None` leaves a field as is.
+#[derive(Clone, Debug, Default)]
+pub struct CorrectionUpdate {
+    pub start: Option<i64>,
+    pub end: Option<i64>,
+    pub category: Option<String>,
+    pub description: Option<String>,
+    pub recurrence: Option<CorrectionRecurrence>,
+}
+
+/// Recurrence from the `--rrule` and `--duration` flags; clap makes sure
+/// they come together.
+pub fn parse_recurrence(
+    rrule: Option<String>,
+    duration: Option<String>,
+) -> Result<Option<CorrectionRecurrence>> {
+    let (Some(rrule), Some(duration)) = (rrule, duration) else {
+        return Ok(None);
+    };
+    let duration = crate::util::parse_duration_millis(&duration)? / 1000;
+    Ok(Some(CorrectionRecurrence { rrule, duration }))
+}
+
+/// Validates an RRULE and sets it, with the occurrence length, on a
+/// correction body's attributes. Recurring corrections have no end.
+fn set_recurrence(body: &mut serde_json::Value, recurrence: &CorrectionRecurrence) -> Result<()> {
+    let rrule = recurrence.rrule.trim().trim_start_matches("RRULE:");
+    if !rrule.contains("FREQ=") {
+        anyhow::bail!("invalid --rrule {rrule:?}: expected e.g. FREQ=WEEKLY;BYDAY=SA");
+    }
+    if recurrence.duration <= 0 {
+        anyhow::bail!("--duration must be positive");
+    }
+    let attributes = &mut body["data"]["attributes"];
+    if let Some(attrs) = attributes.as_object_mut() {
+        attrs.remove("end");
+    }
+    attributes["rrule"] = rrule.into();
+    attributes["duration"] = recurrence.duration.into();
+    Ok(())
+}
+
+/// PATCH body for `update`, carrying only the fields being changed.
+fn correction_update_body(update: &CorrectionUpdate) -> Result<serde_json::Value> {
+    let mut body = serde_json::json!({"data": {"type": "correction", "attributes": {}}});
+    let attrs = &mut body["data"]["attributes"];
+    if let Some(start) = update.start {
+        attrs["start"] = start.into();
+    }
+    if let Some(end) = update.end {
+        attrs["end"] = end.into();
+    }
+    if let (Some(start), Some(end)) = (update.start, update.end) {
+        if end <= start {
+            anyhow::bail!("--to must be after --from");
+        }
+    }
+    if let Some(category) = &update.category {
+        attrs["category"] = parse_correction_category(category)?.into();
+    }
+    if let Some(description) = &update.description {
+        attrs["description"] = description.clone().into();
+    }
+    if let Some(recurrence) = &update.recurrence {
+        set_recurrence(&mut body, recurrence)?;
+    }
+    if body["data"]["attributes"]
+        .as_object()
+        .is_some_and(|a| a.is_empty())
+    {
+        anyhow::bail!(
+            "nothing to update: pass --from, --to, --category, --description, or --rrule"
+        );
+    }
+    Ok(body)
+}
+
+/// Creates a correction over `[start, end]`, or a recurring one from
+/// `start` when `recurrence` is set (and `end` is then unused).
+pub async fn corrections_create(
+    cfg: &Config,
+    slo_id: &str,
+    start: i64,
+    end: Option<i64>,
+    category: &str,
+    description: Option<&str>,
+    recurrence: Option<&CorrectionRecurrence>,
+) -> Result<()> {
+    let category = parse_correction_category(category)?;
+    let body = match (recurrence, end) {
+        (Some(recurrence), _) => {
+            let mut body = correction_body(slo_id, start, start, category, description);
+            set_recurrence(&mut body, recurrence)?;
+            body
+        }
+        (None, Some(end)) if end <= start => anyhow::bail!("--to must be after --from"),
+        (None, Some(end)) => correction_body(slo_id, start, end, category, description),
+        (None, None) => anyhow::bail!("--to is required unless --rrule is set"),
+    };
+    let data = post_correction(cfg, body).await?;
+    formatter::output(cfg, &data)
+}
+
+pub async fn corrections_update(
+    cfg: &Config,
+    correction_id: &str,
+    update: &CorrectionUpdate,
+) -> Result<()> {
+    let body = correction_update_body(update)?;
+    let data = patch_correction(cfg, correction_id, body).await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn post_correction(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    client::raw_post(cfg, "/api/v1/slo/correction", body).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn post_correction(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v1/slo/correction", &body).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn patch_correction(
+    cfg: &Config,
+    correction_id: &str,
+    body: serde_json::Value,
+) -> Result<serde_json::Value> {
+    let path = format!("/api/v1/slo/correction/{correction_id}");
+    let resp = client::raw_request(cfg, "PATCH", &path, Some(&body)).await?;
+    Ok(resp.json().await?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn patch_correction(
+    cfg: &Config,
+    correction_id: &str,
+    body: serde_json::Value,
+) -> Result<serde_json::Value> {
+    crate::api::patch(
+        cfg,
+        &format!("/api/v1/slo/correction/{correction_id}"),
+        &body,
+    )
+    .await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn corrections_delete(cfg: &Config, correction_id: &str) -> Result<()> {
+    let data = client::raw_delete(cfg, &format!("/api/v1/slo/correction/{correction_id}")).await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn corrections_delete(cfg: &Config, correction_id: &str) -> Result<()> {
+    let data = crate::api::delete(cfg, &format!("/api/v1/slo/correction/{correction_id}")).await?;
+    crate::formatter::output(cfg, &data)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parse_correction_category_snake_case() {
+        assert_eq!(
+            parse_correction_category("scheduled_maintenance").unwrap(),
+            "Scheduled Maintenance"
+        );
+        assert_eq!(parse_correction_category("other").unwrap(), "Other");
+    }
+
+    #[test]
+    fn test_parse_correction_category_display_name() {
+        assert_eq!(
+            parse_correction_category("Outside Business Hours").unwrap(),
+            "Outside Business Hours"
+        );
+        assert_eq!(
+            parse_correction_category("Deployment").unwrap(),
+            "Deployment"
+        );
+    }
+
+    #[test]
+    fn test_correction_categories_match_api_values() {
+        let api_values: Vec<&str> = CORRECTION_CATEGORIES.iter().map(|(_, v)| *v).collect();
+        assert_eq!(
+            api_values,
+            [
+                "Scheduled Maintenance",
+                "Outside Business Hours",
+                "Deployment",
+                "Other"
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_correction_category_invalid() {
+        let err = parse_correction_category("holiday")
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("holiday"));
+        assert!(err.contains("scheduled_maintenance"));
+    }
+
+    #[test]
+    fn test_correction_body() {
+        let body = correction_body("slo-1", 100, 200, "Other", Some("db upgrade"));
+        let attrs = &body["data"]["attributes"];
+        assert_eq!(body["data"]["type"], "correction");
+        assert_eq!(attrs["slo_id"], "slo-1");
+        assert_eq!(attrs["start"], 100);
+        assert_eq!(attrs["end"], 200);
+        assert_eq!(attrs["category"], "Other");
+        assert_eq!(attrs["description"], "db upgrade");
+
+        let body = correction_body("slo-1", 100, 200, "Other", None);
+        assert!(body["data"]["attributes"].get("description").is_none());
+    }
+
+    #[test]
+    fn test_set_recurrence() {
+        let mut body = correction_body("slo-1", 100, 100, "Other", None);
+        let weekly = CorrectionRecurrence {
+            rrule: "RRULE:FREQ=WEEKLY;BYDAY=SA".into(),
+            duration: 3600,
+        };
+        set_recurrence(&mut body, &weekly).unwrap();
+        let attrs = &body["data"]["attributes"];
+        assert_eq!(attrs["rrule"], "FREQ=WEEKLY;BYDAY=SA");
+        assert_eq!(attrs["duration"], 3600);
+        assert!(attrs.get("end").is_none());
+
+        let bad = CorrectionRecurrence {
+            rrule: "weekly".into(),
+            duration: 3600,
+        };
+        assert!(set_recurrence(&mut body, &bad).is_err());
+    }
+
+    #[test]
+    fn test_correction_update_body() {
+        let update = CorrectionUpdate {
+            category: Some("deployment".into()),
+            description: Some("deploy freeze".into()),
+            ..Default::default()
+        };
+        let body = correction_update_body(&update).unwrap();
+        assert_eq!(
+            body["data"]["attributes"],
+            serde_json::json!({"category": "Deployment", "description": "deploy freeze"})
+        );
+        assert!(correction_update_body(&CorrectionUpdate::default()).is_err());
+        let backwards = CorrectionUpdate {
+            start: Some(200),
+            end: Some(100),
+            ..Default::default()
+        };
+        assert!(correction_update_body(&backwards).is_err());
+    }
+
+    #[test]
+    fn test_history_row() {
+        let history = serde_json::json!({"data": {
+            "slo": {"name": "Checkout availability", "thresholds": [{"timeframe": "30d", "target": 99.9}]},
+            "thresholds": {"30d": {"timeframe": "30d", "target": 99.9}},
+            "overall": {"sli_value": 99.8, "error_budget_remaining": {"30d": -100.0}}
+        }});
+        let row = history_row("abc", &history);
+        assert_eq!(row["name"], "Checkout availability");
+        assert_eq!(row["timeframe"], "30d");
+        assert_eq!(row["target"], 99.9);
+        assert_eq!(row["sli"], 99.8);
+        assert_eq!(row["budget_remaining"], -100.0);
+        let burn = row["burn_rate"].as_f64().unwrap();
+        assert!((burn - 2.0).abs() < 1e-9, "burn rate {burn}");
+
+        let empty = history_row("abc", &serde_json::json!({"data": {}}));
+        assert!(empty["sli"].is_null());
+        assert!(empty["burn_rate"].is_null());
+    }
+
+    #[test]
+    fn test_sli_breakdown_worst_first() {
+        let history = serde_json::json!({"data": {"groups": [
+            {"group": "env:staging", "sli_value": 99.95,
+             "error_budget_remaining": {"7d": 80.0}},
+            {"group": "env:prod", "sli_value": 99.2,
+             "error_budget_remaining": {"7d": -20.0}},
+            {"group": "env:dev"},
+            {"group": "env:qa", "sli_value": 99.8,
+             "error_budget_remaining": {"7d": 35.5}},
+        ]}});
+        let rows = sli_breakdown(&history);
+        let order: Vec<&str> = rows
+            .as_array()
+            .unwrap()
+            .iter()
+            .map(|r| r["group"].as_str().unwrap())
+            .collect();
+        assert_eq!(order, ["env:prod", "env:qa", "env:staging", "env:dev"]);
+        assert_eq!(rows[0]["budget_remaining"], -20.0);
+        assert_eq!(rows[0]["sli"], 99.2);
+        assert!(rows[3]["sli"].is_null());
+    }
+
+    #[test]
+    fn test_sli_breakdown_falls_back_to_monitors() {
+        let history = serde_json::json!({"data": {"groups": [], "monitors": [
+            {"name": "api latency", "sli_value": 98.0},
+            {"name": "api errors", "sli_value": 97.0},
+        ]}});
+        let rows = sli_breakdown(&history);
+        assert_eq!(rows[0]["group"], "api errors");
+        assert_eq!(rows[1]["group"], "api latency");
+        assert_eq!(sli_breakdown(&serde_json::json!({})), serde_json::json!([]));
+    }
+
+    fn from_monitor(name: Option<&str>) -> FromMonitor {
+        FromMonitor {
+            monitor_id: 123,
+            target: 99.9,
+            timeframe: "30d".into(),
+            name: name.map(str::to_string),
+            description: None,
+        }
+    }
+
+    #[test]
+    fn test_monitor_slo_body() {
+        let monitor =
+            serde_json::json!({"id": 123, "name": "Checkout errors", "tags": ["team:payments"]});
+        let body = monitor_slo_body(&monitor, &from_monitor(None), "30d");
+        assert_eq!(
+            body,
+            serde_json::json!({
+                "type": "monitor",
+                "name": "Checkout errors",
+                "monitor_ids": [123],
+                "thresholds": [{"timeframe": "30d", "target": 99.9}],
+                "tags": ["team:payments"]
+            })
+        );
+        let body = monitor_slo_body(&monitor, &from_monitor(Some("Checkout availability")), "7d");
+        assert_eq!(body["name"], "Checkout availability");
+        assert_eq!(body["thresholds"][0]["timeframe"], "7d");
+    }
+
+    #[test]
+    fn test_parse_timeframe_and_target() {
+        assert_eq!(parse_timeframe("30d").unwrap(), "30d");
+        assert_eq!(parse_timeframe("7 days").unwrap(), "7d");
+        assert!(parse_timeframe("1d").is_err());
+        assert!(validate_target(99.9).is_ok());
+        assert!(validate_target(0.0).is_err());
+        assert!(validate_target(100.0).is_err());
+        assert!(validate_target(f64::NAN).is_err());
+    }
+}
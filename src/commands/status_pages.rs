@@ -72,7 +72,7 @@ pub async fn pages_delete(cfg: &Config, page_id: &str) -> Result<()> {
     api.delete_status_page(uuid)
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete status page: {e:?}"))?;
-    println!("Status page {page_id} deleted.");
+    formatter::print_block(&format!("Status page {page_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -80,7 +80,7 @@ pub async fn pages_delete(cfg: &Config, page_id: &str) -> Result<()> {
 pub async fn pages_delete(cfg: &Config, page_id: &str) -> Result<()> {
     util::parse_uuid(page_id, "page")?;
     crate::api::delete(cfg, &format!("/api/v2/status_pages/{page_id}")).await?;
-    println!("Status page {page_id} deleted.");
+    formatter::print_block(&format!("Status page {page_id} deleted.\n"))?;
     Ok(())
 }
 
@@ -318,7 +318,9 @@ pub async fn components_delete(cfg: &Config, page_id: &str, component_id: &str)
     api.delete_component(page_uuid, component_uuid)
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete component: {e:?}"))?;
-    println!("Component {component_id} deleted from page {page_id}.");
+    formatter::print_block(&format!(
+        "Component {component_id} deleted from page {page_id}.\n"
+    ))?;
     Ok(())
 }
 
@@ -331,7 +333,9 @@ pub async fn components_delete(cfg: &Config, page_id: &str, component_id: &str)
         &format!("/api/v2/status_pages/{page_id}/components/{component_id}"),
     )
     .await?;
-    println!("Component {component_id} deleted from page {page_id}.");
+    formatter::print_block(&format!(
+        "Component {component_id} deleted from page {page_id}.\n"
+    ))?;
     Ok(())
 }
 
@@ -343,7 +347,9 @@ pub async fn degradations_delete(cfg: &Config, page_id: &str, degradation_id: &s
     api.delete_degradation(page_uuid, degradation_uuid)
         .await
         .map_err(|e| anyhow::anyhow!("failed to delete degradation: {e:?}"))?;
-    println!("Degradation {degradation_id} deleted from page {page_id}.");
+    formatter::print_block(&format!(
+        "Degradation {degradation_id} deleted from page {page_id}.\n"
+    ))?;
     Ok(())
 }
 
@@ -356,7 +362,9 @@ pub async fn degradations_delete(cfg: &Config, page_id: &str, degradation_id: &s
         &format!("/api/v2/status_pages/{page_id}/degradations/{degradation_id}"),
     )
     .await?;
-    println!("Degradation {degradation_id} deleted from page {page_id}.");
+    formatter::print_block(&format!(
+        "Degradation {degradation_id} deleted from page {page_id}.\n"
+    ))?;
     Ok(())
 }
 
@@ -635,9 +643,9 @@ pub async fn third_party_list(cfg: &Config, search: Option<&str>, active: bool)
 
     if cfg.output_format == crate::config::OutputFormat::Table {
         if providers.is_empty() {
-            println!("No results found");
+            formatter::print_block("No results found\n")?;
         } else {
-            println!("{}", format_third_party_table(&providers));
+            formatter::print_block(&format!("{}\n", format_third_party_table(&providers)))?;
         }
         return Ok(());
     }
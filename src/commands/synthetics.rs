@@ -1,9 +1,12 @@
 use anyhow::Result;
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::api_synthetics::{
+    GetAPITestLatestResultsOptionalParams, GetBrowserTestLatestResultsOptionalParams,
     ListTestsOptionalParams, SearchTestsOptionalParams, SyntheticsAPI,
 };
 #[cfg(not(target_arch = "wasm32"))]
+use datadog_api_client::datadogV1::model::SyntheticsUpdateTestPauseStatusPayload;
+#[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV2::api_synthetics::{
     SearchSuitesOptionalParams, SyntheticsAPI as SyntheticsV2API,
 };
@@ -111,6 +114,494 @@ pub async fn tests_search(
     crate::formatter::output(cfg, &data)
 }
 
+// ---- Create / update / delete ----
+
+/// Path segment for a test definition's type: API and browser tests are
+/// created and updated through separate endpoints.
+fn test_kind(definition: &serde_json::Value, file: &str) -> Result<&'static str> {
+    match definition["type"].as_str() {
+        Some("api") => Ok("api"),
+        Some("browser") => Ok("browser"),
+        Some(other) => {
+            anyhow::bail!("{file}: unsupported test type {other:?}: expected api or browser")
+        }
+        None => anyhow::bail!("{file}: test definition has no \"type\" (api or browser)"),
+    }
+}
+
+/// Creates an API or browser test from a JSON definition, picking the
+/// endpoint from its `type`.
+pub async fn tests_create(cfg: &Config, file: &str) -> Result<()> {
+    let definition: serde_json::Value = crate::util::read_json_file(file)?;
+    let kind = test_kind(&definition, file)?;
+    let data = post_json(
+        cfg,
+        &format!("/api/v1/synthetics/tests/{kind}"),
+        &definition,
+    )
+    .await?;
+    formatter::output(cfg, &data)
+}
+
+/// Replaces test `public_id` with a JSON definition of the same type.
+pub async fn tests_update(cfg: &Config, public_id: &str, file: &str) -> Result<()> {
+    let definition: serde_json::Value = crate::util::read_json_file(file)?;
+    let kind = test_kind(&definition, file)?;
+    let path = format!("/api/v1/synthetics/tests/{kind}/{public_id}");
+    let data = put_json(cfg, &path, &definition).await?;
+    formatter::output(cfg, &data)
+}
+
+/// Deletes tests in one request.
+pub async fn tests_delete(cfg: &Config, public_ids: &[String]) -> Result<()> {
+    let body = serde_json::json!({ "public_ids": public_ids });
+    let data = post_json(cfg, "/api/v1/synthetics/tests/delete", &body).await?;
+    formatter::output(cfg, &data)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn put_json(cfg: &Config, path: &str, body: &serde_json::Value) -> Result<serde_json::Value> {
+    let resp = client::raw_request(cfg, "PUT", path, Some(body)).await?;
+    Ok(resp.json().await?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn put_json(cfg: &Config, path: &str, body: &serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::put(cfg, path, body).await
+}
+
+// ---- Pause / resume ----
+
+/// Status `tests pause` and `tests resume` switch a test to.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum TestStatus {
+    Paused,
+    Live,
+}
+
+impl TestStatus {
+    fn as_str(self) -> &'static str {
+        match self {
+            TestStatus::Paused => "paused",
+            TestStatus::Live => "live",
+        }
+    }
+
+    fn verb(self) -> &'static str {
+        match self {
+            TestStatus::Paused => "pause",
+            TestStatus::Live => "resume",
+        }
+    }
+
+    fn done(self) -> &'static str {
+        match self {
+            TestStatus::Paused => "paused",
+            TestStatus::Live => "resumed",
+        }
+    }
+}
+
+#[derive(Clone, Debug)]
+struct MatchedTest {
+    public_id: String,
+    name: String,
+}
+
+/// Per-test outcome of a bulk pause or resume.
+#[derive(Debug, serde::Serialize)]
+struct StatusResult {
+    public_id: String,
+    name: String,
+    status: &'static str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+/// Tests in a `list tests` response that carry every one of `tags`.
+fn tests_with_tags(value: &serde_json::Value, tags: &[String]) -> Vec<MatchedTest> {
+    let Some(tests) = value["tests"].as_array() else {
+        return Vec::new();
+    };
+    tests
+        .iter()
+        .filter(|t| {
+            let have: Vec<&str> = t["tags"]
+                .as_array()
+                .map(|a| a.iter().filter_map(|v| v.as_str()).collect())
+                .unwrap_or_default();
+            tags.iter().all(|tag| have.contains(&tag.as_str()))
+        })
+        .filter_map(|t| {
+            Some(MatchedTest {
+                public_id: t["public_id"].as_str()?.to_string(),
+                name: t["name"].as_str().unwrap_or_default().to_string(),
+            })
+        })
+        .collect()
+}
+
+/// Pauses or resumes a single test.
+pub async fn set_status(cfg: &Config, public_id: &str, status: TestStatus) -> Result<()> {
+    update_status(cfg, public_id, status).await?;
+    formatter::print_block(&format!("Synthetic test {public_id} {}.\n", status.done()))?;
+    Ok(())
+}
+
+/// Pauses or resumes every test carrying all of `tags`, after listing the
+/// matches and asking for confirmation.
+pub async fn set_status_matching(cfg: &Config, tags: &[String], status: TestStatus) -> Result<()> {
+    let matched = tests_with_tags(&list_all(cfg).await?, tags);
+    if matched.is_empty() {
+        formatter::print_status(&format!(
+            "No synthetic tests have tags {}.",
+            tags.join(", ")
+        ));
+        return Ok(());
+    }
+
+    formatter::print_status(&format!("Matched {} synthetic test(s):", matched.len()));
+    for t in &matched {
+        formatter::print_status(&format!("  {}  {}", t.public_id, t.name));
+    }
+    if !crate::util::confirm_bulk(cfg, status.verb(), "synthetic tests", matched.len())? {
+        return Ok(());
+    }
+
+    let results = update_all(cfg, &matched, status).await;
+    let failed = results.iter().filter(|r| r.error.is_some()).count();
+    formatter::output(cfg, &results)?;
+    if failed > 0 {
+        anyhow::bail!(
+            "failed to {} {failed} of {} synthetic tests",
+            status.verb(),
+            results.len()
+        );
+    }
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn list_all(cfg: &Config) -> Result<serde_json::Value> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => SyntheticsAPI::with_client_and_config(dd_cfg, c),
+        None => SyntheticsAPI::with_config(dd_cfg),
+    };
+    let resp = api
+        .list_tests(ListTestsOptionalParams::default())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list tests: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn list_all(cfg: &Config) -> Result<serde_json::Value> {
+    crate::api::get(cfg, "/api/v1/synthetics/tests", &[]).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn update_status(cfg: &Config, public_id: &str, status: TestStatus) -> Result<()> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => SyntheticsAPI::with_client_and_config(dd_cfg, c),
+        None => SyntheticsAPI::with_config(dd_cfg),
+    };
+    let body: SyntheticsUpdateTestPauseStatusPayload =
+        serde_json::from_value(serde_json::json!({"new_status": status.as_str()}))?;
+    api.update_test_pause_status(public_id.to_string(), body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to {} test {public_id}: {e:?}", status.verb()))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn update_status(cfg: &Config, public_id: &str, status: TestStatus) -> Result<()> {
+    let path = format!("/api/v1/synthetics/tests/{public_id}/status");
+    let body = serde_json::json!({"new_status": status.as_str()});
+    crate::api::put(cfg, &path, &body).await?;
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn update_all(
+    cfg: &Config,
+    matched: &[MatchedTest],
+    status: TestStatus,
+) -> Vec<StatusResult> {
+    let mut tasks = tokio::task::JoinSet::new();
+    for (i, t) in matched.iter().enumerate() {
+        let cfg = cfg.clone();
+        let public_id = t.public_id.clone();
+        tasks.spawn(async move {
+            let _slot = client::acquire_request_slot().await;
+            (i, update_status(&cfg, &public_id, status).await)
+        });
+    }
+
+    let mut outcomes: Vec<Option<Result<()>>> = matched.iter().map(|_| None).collect();
+    while let Some(joined) = tasks.join_next().await {
+        if let Ok((i, outcome)) = joined {
+            outcomes[i] = Some(outcome);
+        }
+    }
+    matched
+        .iter()
+        .zip(outcomes)
+        .map(|(t, outcome)| {
+            status_result(
+                t,
+                status,
+                outcome.unwrap_or_else(|| Err(anyhow::anyhow!("task aborted"))),
+            )
+        })
+        .collect()
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn update_all(
+    cfg: &Config,
+    matched: &[MatchedTest],
+    status: TestStatus,
+) -> Vec<StatusResult> {
+    let mut results = Vec::new();
+    for t in matched {
+        let outcome = update_status(cfg, &t.public_id, status).await;
+        results.push(status_result(t, status, outcome));
+    }
+    results
+}
+
+fn status_result(t: &MatchedTest, status: TestStatus, outcome: Result<()>) -> StatusResult {
+    let (state, error) = match outcome {
+        Ok(()) => (status.done(), None),
+        Err(e) => ("failed", Some(e.to_string())),
+    };
+    StatusResult {
+        public_id: t.public_id.clone(),
+        name: t.name.clone(),
+        status: state,
+        error,
+    }
+}
+
+// ---- Run (CI trigger) ----
+
+/// Flags for `synthetics run`.
+#[derive(Clone, Debug, Default)]
+pub struct RunOptions {
+    pub test_ids: Vec<String>,
+    /// Also run every test carrying all of these tags.
+    pub tags: Vec<String>,
+    /// Poll the batch until every result is in, then exit non-zero if a
+    /// blocking test failed.
+    pub wait: bool,
+    /// Longest `wait` polls before giving up, e.g. 30m.
+    pub timeout: String,
+    /// Where to write a JUnit XML report of the finished batch.
+    pub junit_file: Option<String>,
+}
+
+/// Triggers synthetic tests as a CI batch. Without `wait`, prints the
+/// trigger response (batch id and result ids) and returns right away.
+pub async fn run(cfg: &Config, opts: &RunOptions) -> Result<()> {
+    let mut ids = opts.test_ids.clone();
+    if !opts.tags.is_empty() {
+        let matched = tests_with_tags(&list_all(cfg).await?, &opts.tags);
+        if matched.is_empty() {
+            anyhow::bail!("no synthetic tests have tags {}", opts.tags.join(", "));
+        }
+        for t in matched {
+            if !ids.contains(&t.public_id) {
+                ids.push(t.public_id);
+            }
+        }
+    }
+    if ids.is_empty() {
+        anyhow::bail!("--test-id or --tag is required");
+    }
+    let timeout_ms = crate::util::parse_duration_millis(&opts.timeout)?;
+
+    let tests: Vec<_> = ids
+        .iter()
+        .map(|id| serde_json::json!({ "public_id": id }))
+        .collect();
+    let body = serde_json::json!({ "tests": tests });
+    let triggered = post_json(cfg, "/api/v1/synthetics/tests/trigger/ci", &body).await?;
+    if !opts.wait {
+        return formatter::output(cfg, &triggered);
+    }
+    let Some(batch_id) = triggered["batch_id"].as_str() else {
+        anyhow::bail!("trigger response has no batch_id: {triggered}");
+    };
+    formatter::print_status(&format!(
+        "Triggered {} synthetic test(s) in batch {batch_id}; waiting for results...",
+        ids.len()
+    ));
+
+    let batch = wait_for_batch(cfg, batch_id, timeout_ms).await?;
+    let report = batch_report(batch_id, &batch);
+    if let Some(path) = &opts.junit_file {
+        std::fs::write(path, formatter::junit_report(&report)?)
+            .map_err(|e| anyhow::anyhow!("failed to write {path:?}: {e}"))?;
+    }
+    formatter::output(cfg, &report)?;
+    let results = report["results"].as_array().map_or(0, Vec::len);
+    let failed = blocking_failures(&report);
+    if failed > 0 {
+        anyhow::bail!("{failed} of {results} synthetic test results failed");
+    }
+    Ok(())
+}
+
+/// Batch status values that mean results are still coming in.
+const BATCH_PENDING: &[&str] = &["in_progress"];
+
+/// How often `run --wait` polls the batch.
+#[cfg(not(target_arch = "wasm32"))]
+const BATCH_POLL_INTERVAL: std::time::Duration = std::time::Duration::from_secs(5);
+
+/// Polls a CI batch until it leaves `in_progress`, returning the final batch.
+#[cfg(not(target_arch = "wasm32"))]
+async fn wait_for_batch(
+    cfg: &Config,
+    batch_id: &str,
+    timeout_ms: i64,
+) -> Result<serde_json::Value> {
+    let deadline = std::time::Instant::now() + std::time::Duration::from_millis(timeout_ms as u64);
+    let path = format!("/api/v1/synthetics/ci/batch/{batch_id}");
+    loop {
+        let batch = client::raw_get(cfg, &path).await?;
+        let status = batch["data"]["status"].as_str().unwrap_or_default();
+        if !BATCH_PENDING.contains(&status) {
+            return Ok(batch);
+        }
+        if std::time::Instant::now() >= deadline {
+            anyhow::bail!("timed out waiting for synthetics batch {batch_id}");
+        }
+        tokio::select! {
+            _ = tokio::signal::ctrl_c() => anyhow::bail!("interrupted waiting for synthetics batch {batch_id}"),
+            _ = tokio::time::sleep(BATCH_POLL_INTERVAL) => {}
+        }
+    }
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn wait_for_batch(
+    _cfg: &Config,
+    _batch_id: &str,
+    _timeout_ms: i64,
+) -> Result<serde_json::Value> {
+    anyhow::bail!("synthetics run --wait is not available in WASM builds.")
+}
+
+/// Reshapes a finished CI batch into the synthetics results shape
+/// `--output=junit` understands, one result per test run and location.
+/// Batch durations are in seconds; results carry milliseconds.
+fn batch_report(batch_id: &str, batch: &serde_json::Value) -> serde_json::Value {
+    let data = &batch["data"];
+    let results: Vec<_> = data["results"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .map(|r| {
+            let status = r["status"].as_str().unwrap_or("unknown");
+            let mut result = serde_json::json!({ "passed": status != "failed" });
+            if let Some(secs) = r["duration"].as_f64() {
+                result["duration"] = (secs * 1000.0).into();
+            }
+            serde_json::json!({
+                "result_id": r["result_id"],
+                "public_id": r["test_public_id"],
+                "test_name": r["test_name"],
+                "probe_dc": r["location"],
+                "status": status,
+                "execution_rule": r["execution_rule"],
+                "result": result,
+            })
+        })
+        .collect();
+    serde_json::json!({
+        "batch_id": batch_id,
+        "status": data["status"],
+        "results": results,
+    })
+}
+
+/// Failed results of tests whose execution rule lets them block a pipeline.
+fn blocking_failures(report: &serde_json::Value) -> usize {
+    report["results"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter(|r| r["status"] == "failed" && r["execution_rule"] != "non_blocking")
+        .count()
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn post_json(
+    cfg: &Config,
+    path: &str,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    client::raw_post(cfg, path, body.clone()).await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn post_json(
+    cfg: &Config,
+    path: &str,
+    body: &serde_json::Value,
+) -> Result<serde_json::Value> {
+    crate::api::post(cfg, path, body).await
+}
+
+/// Latest results of a synthetic test. Browser tests have their own results
+/// endpoint, so the test is looked up first to pick the right one.
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn results(cfg: &Config, public_id: &str) -> Result<()> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => SyntheticsAPI::with_client_and_config(dd_cfg, c),
+        None => SyntheticsAPI::with_config(dd_cfg),
+    };
+    let test = api
+        .get_test(public_id.to_string())
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get test: {e:?}"))?;
+    if serde_json::to_value(&test)?["type"] == "browser" {
+        let resp = api
+            .get_browser_test_latest_results(
+                public_id.to_string(),
+                GetBrowserTestLatestResultsOptionalParams::default(),
+            )
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to get test results: {e:?}"))?;
+        formatter::output(cfg, &resp)
+    } else {
+        let resp = api
+            .get_api_test_latest_results(
+                public_id.to_string(),
+                GetAPITestLatestResultsOptionalParams::default(),
+            )
+            .await
+            .map_err(|e| anyhow::anyhow!("failed to get test results: {e:?}"))?;
+        formatter::output(cfg, &resp)
+    }
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn results(cfg: &Config, public_id: &str) -> Result<()> {
+    let test = crate::api::get(cfg, &format!("/api/v1/synthetics/tests/{public_id}"), &[]).await?;
+    let path = if test["type"] == "browser" {
+        format!("/api/v1/synthetics/tests/browser/{public_id}/results")
+    } else {
+        format!("/api/v1/synthetics/tests/{public_id}/results")
+    };
+    let data = crate::api::get(cfg, &path, &[]).await?;
+    crate::formatter::output(cfg, &data)
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn locations_list(cfg: &Config) -> Result<()> {
     let dd_cfg = client::make_dd_config(cfg);
@@ -256,3 +747,77 @@ pub async fn suites_delete(cfg: &Config, suite_ids: Vec<String>) -> Result<()> {
     let data = crate::api::post(cfg, "/api/v2/synthetics/suites/delete", &body).await?;
     crate::formatter::output(cfg, &data)
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_tests_with_tags() {
+        let resp = serde_json::json!({"tests": [
+            {"public_id": "abc-123", "name": "Checkout", "tags": ["team:web", "env:prod"]},
+            {"public_id": "def-456", "name": "Login", "tags": ["team:web"]},
+            {"public_id": "ghi-789", "name": "API", "tags": ["team:api", "env:prod"]},
+            {"public_id": "jkl-000", "name": "Untagged"}
+        ]});
+        let ids = |tags: &[&str]| -> Vec<String> {
+            let tags: Vec<String> = tags.iter().map(|t| t.to_string()).collect();
+            tests_with_tags(&resp, &tags)
+                .into_iter()
+                .map(|t| t.public_id)
+                .collect()
+        };
+        assert_eq!(ids(&["team:web"]), vec!["abc-123", "def-456"]);
+        assert_eq!(ids(&["team:web", "env:prod"]), vec!["abc-123"]);
+        assert!(ids(&["team:mobile"]).is_empty());
+        assert!(tests_with_tags(&serde_json::json!({}), &[]).is_empty());
+    }
+
+    #[test]
+    fn test_batch_report() {
+        let batch = serde_json::json!({"data": {"status": "failed", "results": [
+            {"result_id": "1", "test_public_id": "abc-123", "test_name": "Checkout",
+             "location": "aws:eu-west-1", "status": "passed", "duration": 1.5,
+             "execution_rule": "blocking"},
+            {"result_id": "2", "test_public_id": "def-456", "test_name": "Login",
+             "location": "aws:eu-west-1", "status": "failed", "execution_rule": "blocking"},
+            {"result_id": "3", "test_public_id": "ghi-789", "test_name": "Search",
+             "location": "aws:eu-west-1", "status": "failed", "execution_rule": "non_blocking"}
+        ]}});
+        let report = batch_report("batch-1", &batch);
+        assert_eq!(report["batch_id"], "batch-1");
+        assert_eq!(report["status"], "failed");
+        let first = &report["results"][0];
+        assert_eq!(first["public_id"], "abc-123");
+        assert_eq!(first["probe_dc"], "aws:eu-west-1");
+        assert_eq!(first["result"]["passed"], true);
+        assert_eq!(first["result"]["duration"], 1500.0);
+        assert_eq!(report["results"][1]["result"]["passed"], false);
+        assert_eq!(blocking_failures(&report), 1);
+    }
+
+    #[test]
+    fn test_test_kind() {
+        let kind = |def: serde_json::Value| test_kind(&def, "t.json");
+        assert_eq!(kind(serde_json::json!({"type": "api"})).unwrap(), "api");
+        assert_eq!(
+            kind(serde_json::json!({"type": "browser"})).unwrap(),
+            "browser"
+        );
+        assert!(kind(serde_json::json!({"type": "mobile"})).is_err());
+        assert!(kind(serde_json::json!({"name": "x"})).is_err());
+    }
+
+    #[test]
+    fn test_status_result() {
+        let t = MatchedTest {
+            public_id: "abc-123".into(),
+            name: "Checkout".into(),
+        };
+        let ok = status_result(&t, TestStatus::Live, Ok(()));
+        assert_eq!((ok.status, ok.error), ("resumed", None));
+        let failed = status_result(&t, TestStatus::Paused, Err(anyhow::anyhow!("HTTP 404")));
+        assert_eq!(failed.status, "failed");
+        assert_eq!(failed.error.as_deref(), Some("HTTP 404"));
+    }
+}
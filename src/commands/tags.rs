@@ -1,4 +1,4 @@
-use anyhow::Result;
+use anyhow::{bail, Result};
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::api_tags::{
     CreateHostTagsOptionalParams, DeleteHostTagsOptionalParams, GetHostTagsOptionalParams,
@@ -117,13 +117,294 @@ pub async fn delete(cfg: &Config, hostname: &str) -> Result<()> {
     )
     .await
     .map_err(|e| anyhow::anyhow!("failed to delete tags: {e:?}"))?;
-    println!("Successfully deleted all tags from host {hostname}");
+    formatter::print_block(&format!(
+        "Successfully deleted all tags from host {hostname}\n"
+    ))?;
     Ok(())
 }
 
 #[cfg(target_arch = "wasm32")]
 pub async fn delete(cfg: &Config, hostname: &str) -> Result<()> {
     crate::api::delete(cfg, &format!("/api/v1/tags/hosts/{hostname}")).await?;
-    println!("Successfully deleted all tags from host {hostname}");
+    formatter::print_block(&format!(
+        "Successfully deleted all tags from host {hostname}\n"
+    ))?;
     Ok(())
 }
+
+// ---- Bulk apply ----
+
+/// Per-host outcome of `tags apply`.
+#[derive(Debug, serde::Serialize)]
+struct ApplyResult {
+    host: String,
+    status: &'static str,
+    /// The host's user tags after the change.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    tags: Option<Vec<String>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+/// Adds and removes user tags across `hosts` concurrently, reporting each
+/// host's outcome. Fails after printing the report if any host failed.
+pub async fn apply(
+    cfg: &Config,
+    hosts: &[String],
+    add: &[String],
+    remove: &[String],
+) -> Result<()> {
+    if hosts.is_empty() {
+        bail!("--hosts is required");
+    }
+    if add.is_empty() && remove.is_empty() {
+        bail!("--add or --remove is required");
+    }
+    let results = apply_all(cfg, hosts, add, remove).await;
+    let failed = results.iter().filter(|r| r.error.is_some()).count();
+    formatter::output(cfg, &results)?;
+    if failed > 0 {
+        bail!(
+            "failed to update tags on {failed} of {} hosts",
+            results.len()
+        );
+    }
+    Ok(())
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn apply_all(
+    cfg: &Config,
+    hosts: &[String],
+    add: &[String],
+    remove: &[String],
+) -> Vec<ApplyResult> {
+    let mut tasks = tokio::task::JoinSet::new();
+    for (i, host) in hosts.iter().enumerate() {
+        let cfg = cfg.clone();
+        let host = host.clone();
+        let add = add.to_vec();
+        let remove = remove.to_vec();
+        tasks.spawn(async move {
+            let _slot = client::acquire_request_slot().await;
+            (i, apply_host(&cfg, &host, &add, &remove).await)
+        });
+    }
+
+    let mut outcomes: Vec<Option<Result<Vec<String>>>> = hosts.iter().map(|_| None).collect();
+    while let Some(joined) = tasks.join_next().await {
+        if let Ok((i, outcome)) = joined {
+            outcomes[i] = Some(outcome);
+        }
+    }
+    hosts
+        .iter()
+        .zip(outcomes)
+        .map(|(host, outcome)| {
+            apply_result(
+                host,
+                outcome.unwrap_or_else(|| Err(anyhow::anyhow!("task aborted"))),
+            )
+        })
+        .collect()
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn apply_all(
+    cfg: &Config,
+    hosts: &[String],
+    add: &[String],
+    remove: &[String],
+) -> Vec<ApplyResult> {
+    let mut results = Vec::new();
+    for host in hosts {
+        let outcome = apply_host(cfg, host, add, remove).await;
+        results.push(apply_result(host, outcome));
+    }
+    results
+}
+
+fn apply_result(host: &str, outcome: Result<Vec<String>>) -> ApplyResult {
+    match outcome {
+        Ok(tags) => ApplyResult {
+            host: host.to_string(),
+            status: "updated",
+            tags: Some(tags),
+            error: None,
+        },
+        Err(e) => ApplyResult {
+            host: host.to_string(),
+            status: "failed",
+            tags: None,
+            error: Some(e.to_string()),
+        },
+    }
+}
+
+/// Updates one host. Pure additions are a single append; removals rewrite
+/// the host's user tags, so only tags pup (or the UI) set can be removed.
+async fn apply_host(
+    cfg: &Config,
+    host: &str,
+    add: &[String],
+    remove: &[String],
+) -> Result<Vec<String>> {
+    if remove.is_empty() {
+        return Ok(response_tags(&post_user_tags(cfg, host, add).await?));
+    }
+    let current = response_tags(&get_user_tags(cfg, host).await?);
+    let tags = merge_tags(&current, add, remove);
+    if tags.is_empty() {
+        delete_user_tags(cfg, host).await?;
+        return Ok(tags);
+    }
+    Ok(response_tags(&put_user_tags(cfg, host, &tags).await?))
+}
+
+/// `current` without the tags matched by `remove`, plus any of `add` not
+/// already present.
+fn merge_tags(current: &[String], add: &[String], remove: &[String]) -> Vec<String> {
+    let mut tags: Vec<String> = current
+        .iter()
+        .filter(|t| !remove.iter().any(|r| tag_matches(t, r)))
+        .cloned()
+        .collect();
+    for tag in add {
+        if !tags.contains(tag) {
+            tags.push(tag.clone());
+        }
+    }
+    tags
+}
+
+/// A `--remove` entry matches a tag exactly, or by key when it has no value,
+/// so `legacy` removes `legacy:true` and `legacy:false` alike.
+fn tag_matches(tag: &str, remove: &str) -> bool {
+    tag == remove || (!remove.contains(':') && tag.split(':').next() == Some(remove))
+}
+
+fn response_tags(value: &serde_json::Value) -> Vec<String> {
+    value["tags"]
+        .as_array()
+        .map(|a| {
+            a.iter()
+                .filter_map(|t| t.as_str().map(String::from))
+                .collect()
+        })
+        .unwrap_or_default()
+}
+
+/// Tag source `tags apply` reads and writes; other sources are read-only here.
+const USER_SOURCE: &str = "users";
+
+#[cfg(not(target_arch = "wasm32"))]
+fn tags_api(cfg: &Config) -> TagsAPI {
+    let dd_cfg = client::make_dd_config(cfg);
+    match client::make_bearer_client(cfg) {
+        Some(c) => TagsAPI::with_client_and_config(dd_cfg, c),
+        None => TagsAPI::with_config(dd_cfg),
+    }
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn get_user_tags(cfg: &Config, host: &str) -> Result<serde_json::Value> {
+    let resp = tags_api(cfg)
+        .get_host_tags(
+            host.to_string(),
+            GetHostTagsOptionalParams::default().source(USER_SOURCE.to_string()),
+        )
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to get tags: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn get_user_tags(cfg: &Config, host: &str) -> Result<serde_json::Value> {
+    let query = [("source", USER_SOURCE.to_string())];
+    crate::api::get(cfg, &format!("/api/v1/tags/hosts/{host}"), &query).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn post_user_tags(cfg: &Config, host: &str, tags: &[String]) -> Result<serde_json::Value> {
+    let resp = tags_api(cfg)
+        .create_host_tags(
+            host.to_string(),
+            HostTags::new().tags(tags.to_vec()),
+            CreateHostTagsOptionalParams::default().source(USER_SOURCE.to_string()),
+        )
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to add tags: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn post_user_tags(cfg: &Config, host: &str, tags: &[String]) -> Result<serde_json::Value> {
+    let path = format!("/api/v1/tags/hosts/{host}?source={USER_SOURCE}");
+    crate::api::post(cfg, &path, &serde_json::json!({ "tags": tags })).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn put_user_tags(cfg: &Config, host: &str, tags: &[String]) -> Result<serde_json::Value> {
+    let resp = tags_api(cfg)
+        .update_host_tags(
+            host.to_string(),
+            HostTags::new().tags(tags.to_vec()),
+            UpdateHostTagsOptionalParams::default().source(USER_SOURCE.to_string()),
+        )
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to update tags: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn put_user_tags(cfg: &Config, host: &str, tags: &[String]) -> Result<serde_json::Value> {
+    let path = format!("/api/v1/tags/hosts/{host}?source={USER_SOURCE}");
+    crate::api::put(cfg, &path, &serde_json::json!({ "tags": tags })).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn delete_user_tags(cfg: &Config, host: &str) -> Result<()> {
+    tags_api(cfg)
+        .delete_host_tags(
+            host.to_string(),
+            DeleteHostTagsOptionalParams::default().source(USER_SOURCE.to_string()),
+        )
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to delete tags: {e:?}"))?;
+    Ok(())
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn delete_user_tags(cfg: &Config, host: &str) -> Result<()> {
+    let path = format!("/api/v1/tags/hosts/{host}?source={USER_SOURCE}");
+    crate::api::delete(cfg, &path).await?;
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn strings(v: &[&str]) -> Vec<String> {
+        v.iter().map(|s| s.to_string()).collect()
+    }
+
+    #[test]
+    fn test_merge_tags_removes_then_adds() {
+        let current = strings(&["env:prod", "legacy:true", "team:web"]);
+        let merged = merge_tags(
+            &current,
+            &strings(&["team:core", "env:prod"]),
+            &strings(&["legacy:true", "team:web"]),
+        );
+        assert_eq!(merged, strings(&["env:prod", "team:core"]));
+    }
+
+    #[test]
+    fn test_tag_matches_bare_key_removes_every_value() {
+        assert!(tag_matches("legacy:true", "legacy"));
+        assert!(tag_matches("legacy", "legacy"));
+        assert!(!tag_matches("legacy:true", "legacy:false"));
+        assert!(!tag_matches("legacyapp:true", "legacy"));
+    }
+}
@@ -10,27 +10,25 @@ fn mask_key(key: &str) -> String {
 }
 
 pub fn run(cfg: &Config) -> Result<()> {
-    println!("Site: {}", cfg.site);
-    println!("API host: {}", cfg.api_host());
+    let mut out = format!("Site: {}\nAPI host: {}\n", cfg.site, cfg.api_host());
 
     if let Some(ref api_key) = cfg.api_key {
-        println!("API Key: {}", mask_key(api_key));
+        out.push_str(&format!("API Key: {}\n", mask_key(api_key)));
     } else {
-        println!("API Key: not set");
+        out.push_str("API Key: not set\n");
     }
 
     if let Some(ref app_key) = cfg.app_key {
-        println!("App Key: {}", mask_key(app_key));
+        out.push_str(&format!("App Key: {}\n", mask_key(app_key)));
     } else {
-        println!("App Key: not set");
+        out.push_str("App Key: not set\n");
     }
 
     if cfg.has_bearer_token() {
-        println!("Bearer Token: configured");
+        out.push_str("Bearer Token: configured\n");
     }
 
-    println!("Output: {}", cfg.output_format);
-    println!("Agent mode: {}", cfg.agent_mode);
-
-    Ok(())
+    out.push_str(&format!("Output: {}\n", cfg.output_format));
+    out.push_str(&format!("Agent mode: {}\n", cfg.agent_mode));
+    crate::formatter::print_block(&out)
 }
@@ -4,13 +4,13 @@ use datadog_api_client::datadogV2::api_spans::SpansAPI;
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV2::model::{
     SpansAggregateData, SpansAggregateRequest, SpansAggregateRequestAttributes,
-    SpansAggregateRequestType, SpansAggregationFunction, SpansCompute, SpansGroupBy,
-    SpansListRequest, SpansListRequestAttributes, SpansListRequestData, SpansListRequestPage,
-    SpansListRequestType, SpansQueryFilter, SpansSort,
+    SpansAggregateRequestType, SpansAggregationFunction, SpansCompute, SpansComputeType,
+    SpansGroupBy, SpansListRequest, SpansQueryFilter,
 };
 
 #[cfg(not(target_arch = "wasm32"))]
 use crate::client;
+use crate::commands::{apm, logs};
 use crate::config::Config;
 use crate::formatter;
 use crate::util;
@@ -26,8 +26,9 @@ fn validate_sort(sort: &str) -> Result<()> {
 }
 
 /// Parse a compute string like "count", "avg(@duration)", "percentile(@duration, 99)"
-/// into a (function_name, Option<metric>) pair as raw strings.
-fn parse_compute_raw(input: &str) -> Result<(String, Option<String>)> {
+/// or the shorthand "pc99(@duration)" into a (function_name, Option<metric>) pair
+/// as raw strings.
+pub(crate) fn parse_compute_raw(input: &str) -> Result<(String, Option<String>)> {
     let input = input.trim();
     if input.is_empty() {
         bail!("--compute is required");
@@ -67,7 +68,8 @@ fn parse_compute_raw(input: &str) -> Result<(String, Option<String>)> {
 
         let metric = rest.to_string();
         let agg_name = match func {
-            "avg" | "sum" | "min" | "max" | "median" | "cardinality" => func.to_string(),
+            "avg" | "sum" | "min" | "max" | "median" | "cardinality" | "pc75" | "pc90" | "pc95"
+            | "pc98" | "pc99" => func.to_string(),
             "count" => bail!("count does not accept a field argument; use just 'count'"),
             _ => bail!("unknown aggregation function: {func}"),
         };
@@ -102,7 +104,11 @@ fn parse_compute(input: &str) -> Result<(SpansAggregationFunction, Option<String
     Ok((agg, metric))
 }
 
-#[cfg(not(target_arch = "wasm32"))]
+/// Largest page the v2 spans search endpoint returns.
+const SEARCH_PAGE_SIZE: i32 = 1000;
+
+/// Searches spans, following the `meta.page.after` cursor until `limit`
+/// spans are collected or none are left.
 pub async fn search(
     cfg: &Config,
     query: String,
@@ -112,55 +118,22 @@ pub async fn search(
     sort: String,
 ) -> Result<()> {
     validate_sort(&sort)?;
-
-    let dd_cfg = client::make_dd_config(cfg);
-    let api = if let Some(bearer_client) = client::make_bearer_client(cfg) {
-        SpansAPI::with_client_and_config(dd_cfg, bearer_client)
-    } else {
-        SpansAPI::with_config(dd_cfg)
-    };
-
+    if limit < 1 {
+        bail!("--limit must be at least 1");
+    }
     let from_ms = util::parse_time_to_unix_millis(&from)?;
     let to_ms = util::parse_time_to_unix_millis(&to)?;
-
-    let page_limit = limit.min(1000);
-    let spans_sort = match sort.as_str() {
-        "timestamp" => SpansSort::TIMESTAMP_ASCENDING,
-        _ => SpansSort::TIMESTAMP_DESCENDING,
-    };
-
-    let body = SpansListRequest::new().data(
-        SpansListRequestData::new()
-            .type_(SpansListRequestType::SEARCH_REQUEST)
-            .attributes(
-                SpansListRequestAttributes::new()
-                    .filter(
-                        SpansQueryFilter::new()
-                            .query(query)
-                            .from(from_ms.to_string())
-                            .to(to_ms.to_string()),
-                    )
-                    .page(SpansListRequestPage::new().limit(page_limit))
-                    .sort(spans_sort),
-            ),
-    );
-
-    let resp = api
-        .list_spans(body)
-        .await
-        .map_err(|e| anyhow::anyhow!("failed to search spans: {:?}", e))?;
+    let (spans, truncated) = collect_spans(cfg, &query, from_ms, to_ms, &sort, limit).await?;
 
     let meta = if cfg.agent_mode {
-        let count = resp.data.as_ref().map(|d| d.len());
-        let truncated = count.is_some_and(|c| c as i32 >= page_limit);
         Some(formatter::Metadata {
-            count,
+            count: Some(spans.len()),
             truncated,
             command: Some("traces search".into()),
             next_action: if truncated {
                 Some(format!(
-                    "Results may be truncated at {page_limit}. Use --limit={} or narrow the --query",
-                    page_limit + 1
+                    "Results truncated at {limit}. Use --limit={} or narrow the --query",
+                    limit.saturating_mul(2)
                 ))
             } else {
                 None
@@ -169,24 +142,75 @@ pub async fn search(
     } else {
         None
     };
-    formatter::format_and_print(&resp, &cfg.output_format, cfg.agent_mode, meta.as_ref())?;
+    formatter::output_with_meta(cfg, &serde_json::json!({ "data": spans }), meta.as_ref())?;
     Ok(())
 }
 
-#[cfg(target_arch = "wasm32")]
-pub async fn search(
+/// Pages through the spans matching `query` until `limit` are collected.
+/// The flag is true when the cursor had more spans left.
+async fn collect_spans(
     cfg: &Config,
-    query: String,
-    from: String,
-    to: String,
+    query: &str,
+    from_ms: i64,
+    to_ms: i64,
+    sort: &str,
     limit: i32,
-    sort: String,
-) -> Result<()> {
-    validate_sort(&sort)?;
+) -> Result<(Vec<serde_json::Value>, bool)> {
+    let mut spans: Vec<serde_json::Value> = Vec::new();
+    let mut cursor: Option<String> = None;
+    while (spans.len() as i32) < limit {
+        let page_limit = (limit - spans.len() as i32).min(SEARCH_PAGE_SIZE);
+        let body = search_request(query, from_ms, to_ms, sort, page_limit, cursor);
+        let page = search_page(cfg, body).await?;
+        let items = page["data"].as_array().cloned().unwrap_or_default();
+        if items.is_empty() {
+            return Ok((spans, false));
+        }
+        spans.extend(items);
+        cursor = page["meta"]["page"]["after"].as_str().map(String::from);
+        if cursor.is_none() {
+            return Ok((spans, false));
+        }
+    }
+    Ok((spans, true))
+}
 
-    let from_ms = util::parse_time_to_unix_millis(&from)?;
-    let to_ms = util::parse_time_to_unix_millis(&to)?;
-    let body = serde_json::json!({
+#[cfg(not(target_arch = "wasm32"))]
+async fn search_page(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = if let Some(bearer_client) = client::make_bearer_client(cfg) {
+        SpansAPI::with_client_and_config(dd_cfg, bearer_client)
+    } else {
+        SpansAPI::with_config(dd_cfg)
+    };
+    let body: SpansListRequest = serde_json::from_value(body)?;
+    let resp = api
+        .list_spans(body)
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to search spans: {:?}", e))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn search_page(cfg: &Config, body: serde_json::Value) -> Result<serde_json::Value> {
+    crate::api::post(cfg, "/api/v2/spans/events/search", &body).await
+}
+
+/// Spans search request for `query` between two Unix-millisecond times,
+/// continuing from `cursor` when given.
+fn search_request(
+    query: &str,
+    from_ms: i64,
+    to_ms: i64,
+    sort: &str,
+    limit: i32,
+    cursor: Option<String>,
+) -> serde_json::Value {
+    let mut page = serde_json::json!({ "limit": limit });
+    if let Some(cursor) = cursor {
+        page["cursor"] = cursor.into();
+    }
+    serde_json::json!({
         "data": {
             "attributes": {
                 "filter": {
@@ -194,26 +218,193 @@ pub async fn search(
                     "from": from_ms.to_string(),
                     "to": to_ms.to_string()
                 },
-                "page": { "limit": limit },
+                "page": page,
                 "sort": sort
             },
             "type": "search_request"
         }
-    });
-    let data = crate::api::post(cfg, "/api/v2/spans/events/search", &body).await?;
-    crate::formatter::output(cfg, &data)
+    })
+}
+
+/// Fetches every span of a trace, up to `limit`. Prints the spans in start
+/// order, or with `format` "tree" as an indented call tree with durations.
+pub async fn get(
+    cfg: &Config,
+    trace_id: &str,
+    from: String,
+    to: String,
+    limit: i32,
+    format: String,
+) -> Result<()> {
+    let tree = apm::is_tree_format(&format)?;
+    let trace_id = trace_id.trim();
+    if trace_id.is_empty() || trace_id.contains(char::is_whitespace) {
+        bail!("invalid trace ID: {trace_id:?}");
+    }
+    if limit < 1 {
+        bail!("--limit must be at least 1");
+    }
+    let from_ms = util::parse_time_to_unix_millis(&from)?;
+    let to_ms = util::parse_time_to_unix_millis(&to)?;
+    let query = format!("trace_id:{trace_id}");
+    let (spans, truncated) = collect_spans(cfg, &query, from_ms, to_ms, "timestamp", limit).await?;
+    if spans.is_empty() {
+        bail!("no spans found for trace {trace_id} between --from and --to");
+    }
+    if truncated {
+        formatter::print_status(&format!(
+            "Trace {trace_id} has more than {limit} spans; showing the first {limit}. Raise --limit to see all."
+        ));
+    }
+    if tree {
+        return formatter::print_block(&apm::render_tree(&trace_tree(&spans)));
+    }
+    formatter::output(cfg, &serde_json::json!({ "data": spans }))
+}
+
+/// A span from the search response, reduced to what the trace tree needs.
+struct TraceSpan {
+    id: String,
+    parent: Option<String>,
+    start: Option<chrono::DateTime<chrono::FixedOffset>>,
+    label: String,
 }
 
+/// Reads a span's ids, start, and a `service operation resource (duration)`
+/// label. The duration is `custom.duration` in nanoseconds, or the gap
+/// between the start and end timestamps.
+fn decode_span(span: &serde_json::Value) -> Option<TraceSpan> {
+    let attrs = span.get("attributes").unwrap_or(span);
+    let text = |v: Option<&serde_json::Value>| v.and_then(|v| v.as_str()).map(str::to_string);
+    let time = |v: Option<&serde_json::Value>| {
+        v.and_then(|v| v.as_str())
+            .and_then(|t| chrono::DateTime::parse_from_rfc3339(t).ok())
+    };
+    let id = text(attrs.get("span_id")).or_else(|| text(span.get("id")))?;
+    let parent = text(attrs.get("parent_id")).filter(|p| p != "0" && !p.is_empty() && *p != id);
+    let start = time(attrs.get("start_timestamp"));
+    let duration_ns = attrs
+        .pointer("/custom/duration")
+        .and_then(|d| d.as_f64())
+        .map(|d| d as i64)
+        .or_else(|| {
+            let end = time(attrs.get("end_timestamp"))?;
+            (end - start?).num_nanoseconds()
+        });
+
+    let mut parts: Vec<String> = Vec::new();
+    parts.extend(text(attrs.get("service")));
+    parts.extend(
+        text(attrs.get("operation_name")).or_else(|| text(attrs.pointer("/custom/operation_name"))),
+    );
+    parts.extend(text(attrs.get("resource_name")));
+    if parts.is_empty() {
+        parts.push(id.clone());
+    }
+    let mut label = parts.join(" ");
+    if let Some(ns) = duration_ns {
+        label.push_str(&format!(" ({})", format_duration_ns(ns)));
+    }
+    Some(TraceSpan {
+        id,
+        parent,
+        start,
+        label,
+    })
+}
+
+/// Renders a span duration with a unit suited to its size.
+fn format_duration_ns(ns: i64) -> String {
+    if ns >= 1_000_000_000 {
+        format!("{:.2}s", ns as f64 / 1e9)
+    } else if ns >= 1_000_000 {
+        format!("{:.2}ms", ns as f64 / 1e6)
+    } else {
+        format!("{:.1}µs", ns as f64 / 1e3)
+    }
+}
+
+/// Arranges spans into parent/child trees, siblings in start order. Spans
+/// whose parent is not among `spans` (the root, or spans outside the window)
+/// become roots of their own.
+fn trace_tree(spans: &[serde_json::Value]) -> Vec<apm::TreeNode> {
+    let mut spans: Vec<TraceSpan> = spans.iter().filter_map(decode_span).collect();
+    spans.sort_by_key(|s| s.start);
+
+    let index: std::collections::HashMap<&str, usize> = spans
+        .iter()
+        .enumerate()
+        .map(|(i, s)| (s.id.as_str(), i))
+        .collect();
+    let mut children: Vec<Vec<usize>> = vec![Vec::new(); spans.len()];
+    let mut roots = Vec::new();
+    for (i, s) in spans.iter().enumerate() {
+        match s.parent.as_deref().and_then(|p| index.get(p)) {
+            Some(&p) => children[p].push(i),
+            None => roots.push(i),
+        }
+    }
+
+    // Walk depth-first with an explicit stack (traces can nest deeper than
+    // the call stack allows), recording each span's children in start order.
+    // Spans caught in a parent cycle are never reached from a root, so any
+    // span left unseen starts a tree of its own.
+    let mut seen = vec![false; spans.len()];
+    let mut kept: Vec<Vec<usize>> = vec![Vec::new(); spans.len()];
+    let mut order = Vec::with_capacity(spans.len());
+    let mut tops = Vec::new();
+    for start in roots.into_iter().chain(0..spans.len()) {
+        if seen[start] {
+            continue;
+        }
+        seen[start] = true;
+        tops.push(start);
+        let mut stack = vec![start];
+        while let Some(i) = stack.pop() {
+            order.push(i);
+            for &c in &children[i] {
+                if !seen[c] {
+                    seen[c] = true;
+                    kept[i].push(c);
+                    stack.push(c);
+                }
+            }
+        }
+    }
+
+    // Children always come after their parent in `order`, so building in
+    // reverse has every child ready before its parent needs it.
+    let mut built: Vec<Option<apm::TreeNode>> = (0..spans.len()).map(|_| None).collect();
+    for &i in order.iter().rev() {
+        let children = kept[i].iter().filter_map(|&c| built[c].take()).collect();
+        built[i] = Some(apm::TreeNode {
+            label: spans[i].label.clone(),
+            children,
+        });
+    }
+    tops.into_iter().filter_map(|i| built[i].take()).collect()
+}
+
+/// Runs each of `compute` (a plain count when empty) over the spans matching
+/// `query`, split by each of `group_by` (top `limit` values per facet). With
+/// an `interval`, every compute is a timeseries bucketed by it.
 #[cfg(not(target_arch = "wasm32"))]
 pub async fn aggregate(
     cfg: &Config,
     query: String,
     from: String,
     to: String,
-    compute: String,
-    group_by: Option<String>,
+    compute: Vec<String>,
+    interval: Option<String>,
+    group_by: Vec<String>,
+    limit: i32,
 ) -> Result<()> {
-    let (agg_fn, metric) = parse_compute(&compute)?;
+    let computes = aggregate_computes(&compute)?
+        .iter()
+        .map(|c| parse_compute(c))
+        .collect::<Result<Vec<_>>>()?;
+    let interval = logs::aggregate_interval(interval.as_deref())?;
+    let group_by = logs::aggregate_group_by(&group_by)?;
 
     let dd_cfg = client::make_dd_config(cfg);
     let api = if let Some(bearer_client) = client::make_bearer_client(cfg) {
@@ -225,13 +416,24 @@ pub async fn aggregate(
     let from_ms = util::parse_time_to_unix_millis(&from)?;
     let to_ms = util::parse_time_to_unix_millis(&to)?;
 
-    let mut spans_compute = SpansCompute::new(agg_fn);
-    if let Some(m) = metric {
-        spans_compute = spans_compute.metric(m);
-    }
+    let computes = computes
+        .into_iter()
+        .map(|(agg_fn, metric)| {
+            let mut spans_compute = SpansCompute::new(agg_fn);
+            if let Some(m) = metric {
+                spans_compute = spans_compute.metric(m);
+            }
+            if let Some(i) = &interval {
+                spans_compute = spans_compute
+                    .type_(SpansComputeType::TIMESERIES)
+                    .interval(i.clone());
+            }
+            spans_compute
+        })
+        .collect();
 
     let mut attrs = SpansAggregateRequestAttributes::new()
-        .compute(vec![spans_compute])
+        .compute(computes)
         .filter(
             SpansQueryFilter::new()
                 .query(query)
@@ -239,8 +441,13 @@ pub async fn aggregate(
                 .to(to_ms.to_string()),
         );
 
-    if let Some(facet) = group_by {
-        attrs = attrs.group_by(vec![SpansGroupBy::new(facet)]);
+    if !group_by.is_empty() {
+        attrs = attrs.group_by(
+            group_by
+                .into_iter()
+                .map(|facet| SpansGroupBy::new(facet).limit(limit as i64))
+                .collect(),
+        );
     }
 
     let body = SpansAggregateRequest::new().data(
@@ -264,7 +471,7 @@ pub async fn aggregate(
     } else {
         None
     };
-    formatter::format_and_print(&resp, &cfg.output_format, cfg.agent_mode, meta.as_ref())?;
+    formatter::output_with_meta(cfg, &resp, meta.as_ref())?;
     Ok(())
 }
 
@@ -274,18 +481,35 @@ pub async fn aggregate(
     query: String,
     from: String,
     to: String,
-    compute: String,
-    group_by: Option<String>,
+    compute: Vec<String>,
+    interval: Option<String>,
+    group_by: Vec<String>,
+    limit: i32,
 ) -> Result<()> {
-    let (func, metric) = parse_compute_raw(&compute)?;
+    let computes = aggregate_computes(&compute)?
+        .iter()
+        .map(|c| parse_compute_raw(c))
+        .collect::<Result<Vec<_>>>()?;
+    let interval = logs::aggregate_interval(interval.as_deref())?;
+    let group_by = logs::aggregate_group_by(&group_by)?;
 
     let from_ms = util::parse_time_to_unix_millis(&from)?;
     let to_ms = util::parse_time_to_unix_millis(&to)?;
 
-    let mut compute_obj = serde_json::json!({ "aggregation": func });
-    if let Some(m) = metric {
-        compute_obj["metric"] = serde_json::Value::String(m);
-    }
+    let computes: Vec<serde_json::Value> = computes
+        .into_iter()
+        .map(|(func, metric)| {
+            let mut compute_obj = serde_json::json!({ "aggregation": func });
+            if let Some(m) = metric {
+                compute_obj["metric"] = serde_json::Value::String(m);
+            }
+            if let Some(i) = &interval {
+                compute_obj["type"] = "timeseries".into();
+                compute_obj["interval"] = i.clone().into();
+            }
+            compute_obj
+        })
+        .collect();
 
     let mut body = serde_json::json!({
         "data": {
@@ -295,20 +519,39 @@ pub async fn aggregate(
                     "from": from_ms.to_string(),
                     "to": to_ms.to_string()
                 },
-                "compute": [compute_obj]
+                "compute": computes
             },
             "type": "aggregate_request"
         }
     });
 
-    if let Some(facet) = group_by {
-        body["data"]["attributes"]["group_by"] = serde_json::json!([{ "facet": facet }]);
+    if !group_by.is_empty() {
+        let groups: Vec<serde_json::Value> = group_by
+            .into_iter()
+            .map(|facet| serde_json::json!({ "facet": facet, "limit": limit }))
+            .collect();
+        body["data"]["attributes"]["group_by"] = groups.into();
     }
 
     let data = crate::api::post(cfg, "/api/v2/spans/analytics/aggregate", &body).await?;
     crate::formatter::output(cfg, &data)
 }
 
+/// The `--compute` values in order, or a plain count when none were given.
+fn aggregate_computes(compute: &[String]) -> Result<Vec<String>> {
+    if compute.is_empty() {
+        return Ok(vec!["count".into()]);
+    }
+    if let Some(dup) = compute
+        .iter()
+        .enumerate()
+        .find_map(|(i, c)| compute[..i].contains(c).then_some(c))
+    {
+        bail!("--compute {dup:?} given more than once");
+    }
+    Ok(compute.to_vec())
+}
+
 #[cfg(all(test, not(target_arch = "wasm32")))]
 mod tests {
     use super::*;
@@ -384,6 +627,14 @@ mod tests {
         assert_eq!(metric.unwrap(), "@duration");
     }
 
+    #[test]
+    fn test_parse_compute_percentile_shorthand() {
+        let (agg, metric) = parse_compute("pc95(@duration)").unwrap();
+        assert_eq!(agg, SpansAggregationFunction::PERCENTILE_95);
+        assert_eq!(metric.unwrap(), "@duration");
+        assert!(parse_compute("pc42(@duration)").is_err());
+    }
+
     #[test]
     fn test_parse_compute_empty() {
         assert!(parse_compute("").is_err());
@@ -415,6 +666,90 @@ mod tests {
         assert!(err.to_string().contains("does not accept a field"));
     }
 
+    #[test]
+    fn test_search_request_carries_cursor() {
+        let body = search_request("service:api", 1000, 2000, "-timestamp", 50, None);
+        assert_eq!(
+            body["data"]["attributes"]["page"],
+            serde_json::json!({"limit": 50})
+        );
+        let body = search_request("*", 1000, 2000, "timestamp", 10, Some("abc".into()));
+        assert_eq!(body["data"]["attributes"]["page"]["cursor"], "abc");
+        assert_eq!(body["data"]["attributes"]["filter"]["from"], "1000");
+        let _: SpansListRequest = serde_json::from_value(body).unwrap();
+    }
+
+    #[test]
+    fn test_aggregate_computes_defaults_to_count() {
+        assert_eq!(aggregate_computes(&[]).unwrap(), vec!["count".to_string()]);
+        let computes = vec!["pc99(@duration)".to_string(), "count".to_string()];
+        assert_eq!(aggregate_computes(&computes).unwrap(), computes);
+        assert!(aggregate_computes(&["count".into(), "count".into()]).is_err());
+    }
+
+    #[test]
+    fn test_trace_tree_nests_children_in_start_order() {
+        let span = |id: &str, parent: &str, service: &str, start: &str, ns: i64| {
+            serde_json::json!({"id": id, "type": "spans", "attributes": {
+                "span_id": id, "parent_id": parent, "service": service,
+                "resource_name": format!("{service}-op"),
+                "start_timestamp": start, "custom": {"duration": ns}
+            }})
+        };
+        let spans = vec![
+            span("3", "1", "db", "2024-01-01T00:00:00.300Z", 40_000_000),
+            span("1", "0", "web", "2024-01-01T00:00:00Z", 1_500_000_000),
+            span("2", "1", "cache", "2024-01-01T00:00:00.100Z", 800_000),
+            span("4", "3", "pg", "2024-01-01T00:00:00.310Z", 500),
+            span("9", "gone", "worker", "2024-01-01T00:00:01Z", 2_000_000),
+        ];
+        let expected = [
+            "web web-op (1.50s)",
+            "├── cache cache-op (800.0µs)",
+            "└── db db-op (40.00ms)",
+            "    └── pg pg-op (0.5µs)",
+            "worker worker-op (2.00ms)",
+        ];
+        assert_eq!(
+            apm::render_tree(&trace_tree(&spans)),
+            format!("{}\n", expected.join("\n"))
+        );
+    }
+
+    #[test]
+    fn test_trace_tree_handles_deep_chains() {
+        let depth = 100_000;
+        let spans: Vec<_> = (0..depth)
+            .map(|i| {
+                serde_json::json!({"attributes": {
+                    "span_id": i.to_string(), "parent_id": (i - 1).to_string(),
+                    "service": "svc", "start_timestamp": "2024-01-01T00:00:00Z"
+                }})
+            })
+            .collect();
+        let tree = trace_tree(&spans);
+        assert_eq!(tree.len(), 1);
+        let mut node = &tree[0];
+        let mut levels = 1;
+        while let Some(child) = node.children.first() {
+            node = child;
+            levels += 1;
+        }
+        assert_eq!(levels, depth);
+    }
+
+    #[test]
+    fn test_decode_span_duration_from_timestamps() {
+        let span = serde_json::json!({"attributes": {
+            "span_id": "7", "service": "api",
+            "start_timestamp": "2024-01-01T00:00:00Z",
+            "end_timestamp": "2024-01-01T00:00:00.250Z"
+        }});
+        let decoded = decode_span(&span).unwrap();
+        assert!(decoded.parent.is_none());
+        assert_eq!(decoded.label, "api (250.00ms)");
+    }
+
     #[test]
     fn test_validate_sort_valid() {
         assert!(validate_sort("timestamp").is_ok());
@@ -1,7 +1,8 @@
 use anyhow::Result;
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::api_usage_metering::{
-    GetHourlyUsageAttributionOptionalParams, GetUsageSummaryOptionalParams, UsageMeteringAPI,
+    GetHourlyUsageAttributionOptionalParams, GetUsageSummaryOptionalParams,
+    GetUsageTopAvgMetricsOptionalParams, UsageMeteringAPI,
 };
 #[cfg(not(target_arch = "wasm32"))]
 use datadog_api_client::datadogV1::model::HourlyUsageAttributionUsageType;
@@ -92,3 +93,238 @@ pub async fn hourly(cfg: &Config, start: String, end: Option<String>) -> Result<
     let data = crate::api::get(cfg, "/api/v1/usage/hourly-attribution", &query).await?;
     crate::formatter::output(cfg, &data)
 }
+
+/// Longest window `top-metrics` will query; the API ranks metrics per day,
+/// so each day in the window is one request.
+pub const MAX_TOP_METRICS_DAYS: i64 = 31;
+
+const DAY_MS: i64 = 24 * 3600 * 1000;
+
+/// Days covered by a `--from` window ending now, to the nearest whole day.
+/// The window is made of the complete days before today, see
+/// `window_day_starts`.
+fn window_days(start: &str) -> Result<i64> {
+    let from_ms = util::parse_time_to_unix_millis(start)?;
+    let now_ms = chrono::Utc::now().timestamp_millis();
+    let days = (now_ms - from_ms + DAY_MS / 2) / DAY_MS;
+    if days > MAX_TOP_METRICS_DAYS {
+        anyhow::bail!("--from may be at most {MAX_TOP_METRICS_DAYS} days ago");
+    }
+    Ok(days.max(1))
+}
+
+/// The UTC days (as timestamps) covered by the window, oldest first. The
+/// window ends with yesterday: today's usage is still partial and would drag
+/// every metric's average down.
+fn window_day_starts(days: i64) -> Vec<chrono::DateTime<chrono::Utc>> {
+    let today = chrono::Utc::now()
+        .date_naive()
+        .and_hms_opt(0, 0, 0)
+        .unwrap()
+        .and_utc();
+    (1..=days)
+        .rev()
+        .map(|i| today - chrono::Duration::days(i))
+        .collect()
+}
+
+/// Metrics requested per page of a day's top metrics, the API maximum.
+const TOP_METRICS_PAGE_LIMIT: i32 = 5000;
+
+/// Id of the next page of a day's top metrics, if there is one.
+fn next_record_id(page: &serde_json::Value) -> Option<String> {
+    page.pointer("/metadata/pagination/next_record_id")
+        .and_then(|v| v.as_str())
+        .filter(|id| !id.is_empty())
+        .map(String::from)
+}
+
+/// Appends one page's `usage` entries to a day's accumulated `usage`.
+fn extend_usage(day: &mut Vec<serde_json::Value>, page: &serde_json::Value) {
+    if let Some(usage) = page.get("usage").and_then(|u| u.as_array()) {
+        day.extend(usage.iter().cloned());
+    }
+}
+
+/// Merges per-day top-metric responses into one ranking: a metric's hourly
+/// volume is averaged over the days in the window (days it was absent count
+/// as zero), its peak is the highest hourly count seen. Sorted by average
+/// volume, largest first, and cut to `limit` rows. Each day must hold every
+/// metric reported that day, not just that day's top `limit`, or a metric
+/// that only misses one day's cut would be counted as zero for it.
+pub fn rank_top_metrics(days: &[serde_json::Value], limit: usize) -> serde_json::Value {
+    let mut totals: Vec<(String, String, f64, i64)> = Vec::new();
+    for day in days {
+        let usage = day.get("usage").and_then(|u| u.as_array());
+        for hour in usage.into_iter().flatten() {
+            let Some(name) = hour.get("metric_name").and_then(|n| n.as_str()) else {
+                continue;
+            };
+            let avg = hour
+                .get("avg_metric_hour")
+                .and_then(|v| v.as_f64())
+                .unwrap_or(0.0);
+            let max = hour
+                .get("max_metric_hour")
+                .and_then(|v| v.as_i64())
+                .unwrap_or(0);
+            let category = hour
+                .get("metric_category")
+                .and_then(|v| v.as_str())
+                .unwrap_or("");
+            match totals.iter_mut().find(|t| t.0 == name) {
+                Some(t) => {
+                    t.2 += avg;
+                    t.3 = t.3.max(max);
+                }
+                None => totals.push((name.to_string(), category.to_string(), avg, max)),
+            }
+        }
+    }
+
+    let day_count = days.len().max(1) as f64;
+    totals.sort_by(|a, b| b.2.total_cmp(&a.2).then_with(|| a.0.cmp(&b.0)));
+    totals.truncate(limit);
+    serde_json::Value::Array(
+        totals
+            .into_iter()
+            .map(|(metric, category, avg, max)| {
+                serde_json::json!({
+                    "metric": metric,
+                    "category": category,
+                    "avg_hourly_volume": (avg / day_count).round() as i64,
+                    "max_hourly_volume": max,
+                })
+            })
+            .collect(),
+    )
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+pub async fn top_metrics(cfg: &Config, start: String, limit: usize) -> Result<()> {
+    let days = window_days(&start)?;
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => UsageMeteringAPI::with_client_and_config(dd_cfg, c),
+        None => UsageMeteringAPI::with_config(dd_cfg),
+    };
+
+    let mut responses = Vec::new();
+    for day in window_day_starts(days) {
+        let mut usage = Vec::new();
+        let mut next = None;
+        loop {
+            let mut params = GetUsageTopAvgMetricsOptionalParams::default()
+                .day(day)
+                .limit(TOP_METRICS_PAGE_LIMIT);
+            if let Some(id) = next {
+                params = params.next_record_id(id);
+            }
+            let resp = api
+                .get_usage_top_avg_metrics(params)
+                .await
+                .map_err(|e| anyhow::anyhow!("failed to get top metrics: {e:?}"))?;
+            let page = serde_json::to_value(&resp)?;
+            extend_usage(&mut usage, &page);
+            next = next_record_id(&page);
+            if next.is_none() {
+                break;
+            }
+        }
+        responses.push(serde_json::json!({ "usage": usage }));
+    }
+    formatter::output(cfg, &rank_top_metrics(&responses, limit))
+}
+
+#[cfg(target_arch = "wasm32")]
+pub async fn top_metrics(cfg: &Config, start: String, limit: usize) -> Result<()> {
+    let days = window_days(&start)?;
+    let mut responses = Vec::new();
+    for day in window_day_starts(days) {
+        let mut usage = Vec::new();
+        let mut next = None;
+        loop {
+            let mut query = vec![
+                ("day", day.format("%Y-%m-%d").to_string()),
+                ("limit", TOP_METRICS_PAGE_LIMIT.to_string()),
+            ];
+            if let Some(id) = next {
+                query.push(("next_record_id", id));
+            }
+            let page = crate::api::get(cfg, "/api/v1/usage/top_avg_metrics", &query).await?;
+            extend_usage(&mut usage, &page);
+            next = next_record_id(&page);
+            if next.is_none() {
+                break;
+            }
+        }
+        responses.push(serde_json::json!({ "usage": usage }));
+    }
+    crate::formatter::output(cfg, &rank_top_metrics(&responses, limit))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_rank_top_metrics_sorts_by_volume() {
+        let day1 = serde_json::json!({"usage": [
+            {"metric_name": "app.requests", "metric_category": "standard",
+             "avg_metric_hour": 1000, "max_metric_hour": 1500},
+            {"metric_name": "app.latency", "metric_category": "custom",
+             "avg_metric_hour": 4000, "max_metric_hour": 4200},
+            {"metric_name": "app.errors", "metric_category": "standard",
+             "avg_metric_hour": 10, "max_metric_hour": 12},
+        ]});
+        let day2 = serde_json::json!({"usage": [
+            {"metric_name": "app.requests", "metric_category": "standard",
+             "avg_metric_hour": 9000, "max_metric_hour": 12000},
+            {"metric_name": "app.latency", "metric_category": "custom",
+             "avg_metric_hour": 4000, "max_metric_hour": 4100},
+        ]});
+
+        let rows = rank_top_metrics(&[day1, day2], 2);
+        assert_eq!(
+            rows,
+            serde_json::json!([
+                {"metric": "app.requests", "category": "standard",
+                 "avg_hourly_volume": 5000, "max_hourly_volume": 12000},
+                {"metric": "app.latency", "category": "custom",
+                 "avg_hourly_volume": 4000, "max_hourly_volume": 4200},
+            ])
+        );
+    }
+
+    #[test]
+    fn test_window_day_starts_ends_yesterday() {
+        let today = chrono::Utc::now().date_naive();
+        let days = window_day_starts(3);
+        assert_eq!(days.len(), 3);
+        assert_eq!(days[0].date_naive(), today - chrono::Duration::days(3));
+        assert_eq!(days[2].date_naive(), today - chrono::Duration::days(1));
+    }
+
+    #[test]
+    fn test_next_record_id() {
+        let page = serde_json::json!({"metadata": {"pagination": {"next_record_id": "r2"}}});
+        assert_eq!(next_record_id(&page).as_deref(), Some("r2"));
+        let last = serde_json::json!({"metadata": {"pagination": {"next_record_id": null}}});
+        assert_eq!(next_record_id(&last), None);
+    }
+
+    #[test]
+    fn test_rank_top_metrics_empty() {
+        assert_eq!(
+            rank_top_metrics(&[serde_json::json!({"usage": []})], 20),
+            serde_json::json!([])
+        );
+    }
+
+    #[test]
+    fn test_window_days() {
+        assert_eq!(window_days("7d").unwrap(), 7);
+        assert_eq!(window_days("1h").unwrap(), 1);
+        assert!(window_days("60d").is_err());
+    }
+}
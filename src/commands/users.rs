@@ -68,3 +68,141 @@ pub async fn roles_list(cfg: &Config) -> Result<()> {
     let data = crate::api::get(cfg, "/api/v2/roles", &[]).await?;
     crate::formatter::output(cfg, &data)
 }
+
+// ---- Resolving user references ----
+
+/// A user named on the command line: `me`, `@handle`, or a user id.
+#[derive(Debug, PartialEq)]
+pub enum UserRef {
+    Me,
+    Handle(String),
+    Id(String),
+}
+
+pub fn parse_user_ref(input: &str) -> Result<UserRef> {
+    let input = input.trim();
+    match input {
+        "" => anyhow::bail!("expected a user: 'me', '@handle', or a user id"),
+        "me" => Ok(UserRef::Me),
+        _ => match input.strip_prefix('@') {
+            Some("") => anyhow::bail!("expected a handle after '@'"),
+            Some(handle) => Ok(UserRef::Handle(handle.to_string())),
+            None => Ok(UserRef::Id(input.to_string())),
+        },
+    }
+}
+
+/// User ids already resolved in this process, keyed by the config's cache
+/// scope (site and profile) and the reference, so `me` costs one lookup per
+/// org per run.
+static RESOLVED_USERS: std::sync::Mutex<std::collections::BTreeMap<String, String>> =
+    std::sync::Mutex::new(std::collections::BTreeMap::new());
+
+/// Resolves `me` (the current user) or `@handle` (matched by handle or email)
+/// to a user id. Anything else is taken to be an id already.
+pub async fn resolve_user_id(cfg: &Config, input: &str) -> Result<String> {
+    let user = match parse_user_ref(input)? {
+        UserRef::Id(id) => return Ok(id),
+        user => user,
+    };
+    let key = format!("{}|{}", cfg.cache_scope(), input.trim());
+    if let Some(id) = RESOLVED_USERS
+        .lock()
+        .unwrap_or_else(|e| e.into_inner())
+        .get(&key)
+    {
+        return Ok(id.clone());
+    }
+
+    let id = match user {
+        UserRef::Handle(handle) => find_user_id(&users_matching(cfg, &handle).await?, &handle)
+            .ok_or_else(|| anyhow::anyhow!("no user found with handle {handle:?}"))?,
+        _ => current_user(cfg).await?["data"]["id"]
+            .as_str()
+            .map(str::to_string)
+            .ok_or_else(|| anyhow::anyhow!("current user response has no user id"))?,
+    };
+    RESOLVED_USERS
+        .lock()
+        .unwrap_or_else(|e| e.into_inner())
+        .insert(key, id.clone());
+    Ok(id)
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn current_user(cfg: &Config) -> Result<serde_json::Value> {
+    client::raw_get(cfg, "/api/v2/current_user").await
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn current_user(cfg: &Config) -> Result<serde_json::Value> {
+    crate::api::get(cfg, "/api/v2/current_user", &[]).await
+}
+
+#[cfg(not(target_arch = "wasm32"))]
+async fn users_matching(cfg: &Config, filter: &str) -> Result<serde_json::Value> {
+    let dd_cfg = client::make_dd_config(cfg);
+    let api = match client::make_bearer_client(cfg) {
+        Some(c) => UsersAPI::with_client_and_config(dd_cfg, c),
+        None => UsersAPI::with_config(dd_cfg),
+    };
+    let resp = api
+        .list_users(ListUsersOptionalParams::default().filter(filter.to_string()))
+        .await
+        .map_err(|e| anyhow::anyhow!("failed to list users: {e:?}"))?;
+    Ok(serde_json::to_value(&resp)?)
+}
+
+#[cfg(target_arch = "wasm32")]
+async fn users_matching(cfg: &Config, filter: &str) -> Result<serde_json::Value> {
+    crate::api::get(cfg, "/api/v2/users", &[("filter", filter.to_string())]).await
+}
+
+/// Id of the user in a users list whose handle or email is exactly `handle`
+/// (ignoring case); the list filter also returns partial matches.
+fn find_user_id(users: &serde_json::Value, handle: &str) -> Option<String> {
+    users["data"]
+        .as_array()?
+        .iter()
+        .find(|u| {
+            ["handle", "email"].iter().any(|field| {
+                u["attributes"][field]
+                    .as_str()
+                    .is_some_and(|v| v.eq_ignore_ascii_case(handle))
+            })
+        })
+        .and_then(|u| u["id"].as_str().map(str::to_string))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parse_user_ref() {
+        assert_eq!(parse_user_ref("me").unwrap(), UserRef::Me);
+        assert_eq!(
+            parse_user_ref("@jane@example.com").unwrap(),
+            UserRef::Handle("jane@example.com".into())
+        );
+        assert_eq!(
+            parse_user_ref(" 3ad549bf-eba0-11e9-a77a-0705486660d0 ").unwrap(),
+            UserRef::Id("3ad549bf-eba0-11e9-a77a-0705486660d0".into())
+        );
+        assert!(parse_user_ref("").is_err());
+        assert!(parse_user_ref("@").is_err());
+    }
+
+    #[test]
+    fn test_find_user_id() {
+        let users = serde_json::json!({"data": [
+            {"id": "u-1", "attributes": {"handle": "jane.doe@example.com", "email": "jane.doe@example.com"}},
+            {"id": "u-2", "attributes": {"handle": "jane@example.com", "email": "jane@example.com"}}
+        ]});
+        assert_eq!(
+            find_user_id(&users, "Jane@example.com").as_deref(),
+            Some("u-2")
+        );
+        assert_eq!(find_user_id(&users, "jane"), None);
+    }
+}
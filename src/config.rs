@@ -1,9 +1,12 @@
 use anyhow::{bail, Result};
 #[cfg(not(feature = "browser"))]
 use serde::Deserialize;
+#[cfg(not(feature = "browser"))]
+use std::collections::HashMap;
 use std::path::PathBuf;
 
 /// Runtime configuration with precedence: flag > env > file > default.
+#[derive(Clone)]
 pub struct Config {
     pub api_key: Option<String>,
     pub app_key: Option<String>,
@@ -12,6 +15,39 @@ pub struct Config {
     pub output_format: OutputFormat,
     pub auto_approve: bool,
     pub agent_mode: bool,
+    /// Request metadata for `--envelope` output; None keeps output bare.
+    pub envelope: Option<crate::formatter::RequestInfo>,
+    /// Table rendering options (`--wrap`, ...).
+    pub table: crate::formatter::TableOptions,
+    /// Template from `--template-file`; when set, output is rendered through it.
+    pub template: Option<crate::template::Template>,
+    /// Print a per-resource summary (`--summary`) instead of the full response.
+    pub summary: bool,
+    /// Name of the config file profile this config was built from (`--orgs`);
+    /// None for the top-level credentials. Scopes `cache_scope`.
+    pub profile: Option<String>,
+}
+
+/// Region aliases accepted anywhere a site is configured, with the canonical
+/// site domain each one stands for.
+pub const SITE_ALIASES: &[(&str, &str)] = &[
+    ("us1", "datadoghq.com"),
+    ("us3", "us3.datadoghq.com"),
+    ("us5", "us5.datadoghq.com"),
+    ("eu1", "datadoghq.eu"),
+    ("ap1", "ap1.datadoghq.com"),
+    ("gov", "ddog-gov.com"),
+];
+
+/// Maps a region alias like `eu1` to its site domain. Anything else,
+/// including full domains, is returned unchanged.
+pub fn resolve_site(site: &str) -> String {
+    let site = site.trim();
+    SITE_ALIASES
+        .iter()
+        .find(|(alias, _)| alias.eq_ignore_ascii_case(site))
+        .map(|(_, domain)| domain.to_string())
+        .unwrap_or_else(|| site.to_string())
 }
 
 #[derive(Clone, Debug, PartialEq)]
@@ -19,6 +55,9 @@ pub enum OutputFormat {
     Json,
     Table,
     Yaml,
+    Csv,
+    Junit,
+    Prometheus,
 }
 
 impl std::fmt::Display for OutputFormat {
@@ -27,6 +66,9 @@ impl std::fmt::Display for OutputFormat {
             OutputFormat::Json => write!(f, "json"),
             OutputFormat::Table => write!(f, "table"),
             OutputFormat::Yaml => write!(f, "yaml"),
+            OutputFormat::Csv => write!(f, "csv"),
+            OutputFormat::Junit => write!(f, "junit"),
+            OutputFormat::Prometheus => write!(f, "prometheus"),
         }
     }
 }
@@ -38,12 +80,29 @@ impl std::str::FromStr for OutputFormat {
             "json" => Ok(OutputFormat::Json),
             "table" => Ok(OutputFormat::Table),
             "yaml" => Ok(OutputFormat::Yaml),
-            _ => bail!("invalid output format: {s:?} (expected json, table, or yaml)"),
+            "csv" => Ok(OutputFormat::Csv),
+            "junit" => Ok(OutputFormat::Junit),
+            "prometheus" => Ok(OutputFormat::Prometheus),
+            _ => bail!(
+                "invalid output format: {s:?} (expected json, table, yaml, csv, junit, or prometheus)"
+            ),
+        }
+    }
+}
+
+impl OutputFormat {
+    /// Format used when neither `--output`, DD_OUTPUT, nor the config file
+    /// picks one: a table for people at a terminal, JSON for pipes and scripts.
+    pub fn default_for(stdout_is_terminal: bool) -> Self {
+        if stdout_is_terminal {
+            OutputFormat::Table
+        } else {
+            OutputFormat::Json
         }
     }
 }
 
-/// Config file structure (~/.config/pup/config.yaml)
+/// Config file structure (~/.config/pup/config.yaml, or the `--config` path)
 #[cfg(not(feature = "browser"))]
 #[derive(Deserialize, Default)]
 struct FileConfig {
@@ -53,17 +112,44 @@ struct FileConfig {
     site: Option<String>,
     output: Option<String>,
     auto_approve: Option<bool>,
+    #[serde(default)]
+    profiles: HashMap<String, Profile>,
+}
+
+/// Named credentials under `profiles:` in the config file, selected with `--orgs`.
+///
+/// ```yaml
+/// profiles:
+///   prod:
+///     api_key: ...
+///     app_key: ...
+///   eu:
+///     site: datadoghq.eu
+///     access_token: ...
+/// ```
+#[cfg(not(feature = "browser"))]
+#[derive(Deserialize, Default, Clone, Debug, PartialEq)]
+pub struct Profile {
+    pub api_key: Option<String>,
+    pub app_key: Option<String>,
+    pub access_token: Option<String>,
+    pub site: Option<String>,
 }
 
 impl Config {
     /// Load configuration with precedence: flag overrides > env > file > keychain > defaults.
-    /// Flag overrides are applied by the caller after this returns.
+    /// Flag overrides are applied by the caller after this returns, except
+    /// `--site` (`site_flag`), which decides which stored token is loaded.
     #[cfg(not(feature = "browser"))]
-    pub fn from_env() -> Result<Self> {
-        let file_cfg = load_config_file().unwrap_or_default();
+    pub fn from_env(site_flag: Option<&str>) -> Result<Self> {
+        let file_cfg = load_config_file()?.unwrap_or_default();
 
         let access_token = env_or("DD_ACCESS_TOKEN", file_cfg.access_token);
-        let site = env_or("DD_SITE", file_cfg.site).unwrap_or_else(|| "datadoghq.com".into());
+        let site = site_flag
+            .map(str::to_string)
+            .or_else(|| env_or("DD_SITE", file_cfg.site))
+            .map(|s| resolve_site(&s))
+            .unwrap_or_else(|| "datadoghq.com".into());
 
         // If no token from env/file, try loading from keychain/storage (where `pup auth login` saves)
         #[cfg(not(target_arch = "wasm32"))]
@@ -76,11 +162,19 @@ impl Config {
             site,
             output_format: env_or("DD_OUTPUT", file_cfg.output)
                 .and_then(|s| s.parse().ok())
-                .unwrap_or(OutputFormat::Json),
+                .unwrap_or_else(|| {
+                    use std::io::IsTerminal;
+                    OutputFormat::default_for(std::io::stdout().is_terminal())
+                }),
             auto_approve: env_bool("DD_AUTO_APPROVE")
                 || env_bool("DD_CLI_AUTO_APPROVE")
                 || file_cfg.auto_approve.unwrap_or(false),
             agent_mode: false, // set by caller from --agent flag or useragent detection
+            envelope: None,    // set by caller from --envelope flag
+            table: Default::default(),
+            template: None,
+            summary: false,
+            profile: None, // set by with_profile for --orgs runs
         };
 
         Ok(cfg)
@@ -99,10 +193,44 @@ impl Config {
             api_key,
             app_key,
             access_token,
-            site,
+            site: resolve_site(&site),
             output_format: OutputFormat::Json,
             auto_approve: false,
             agent_mode: false,
+            envelope: None,
+            table: Default::default(),
+            template: None,
+            summary: false,
+            profile: None,
+        }
+    }
+
+    /// Returns a copy of this config using the credentials and site of the
+    /// profile called `name`. Credentials are taken from the profile only, so
+    /// one org's keys never leak into a run against another.
+    #[cfg(not(feature = "browser"))]
+    pub fn with_profile(&self, name: &str, profile: &Profile) -> Config {
+        Config {
+            api_key: profile.api_key.clone(),
+            app_key: profile.app_key.clone(),
+            access_token: profile.access_token.clone(),
+            site: profile
+                .site
+                .as_deref()
+                .map(resolve_site)
+                .unwrap_or_else(|| self.site.clone()),
+            profile: Some(name.to_string()),
+            ..self.clone()
+        }
+    }
+
+    /// Key prefix for anything cached for this config. Every profile gets its
+    /// own scope, even on a shared site, so results fetched with one org's
+    /// credentials are never served to another.
+    pub fn cache_scope(&self) -> String {
+        match &self.profile {
+            None => self.api_base_url(),
+            Some(name) => format!("{}#{name}", self.api_base_url()),
         }
     }
 
@@ -187,11 +315,67 @@ pub fn config_dir() -> Option<PathBuf> {
     None
 }
 
+/// Config file chosen with `--config`; replaces the default location.
+#[cfg(not(feature = "browser"))]
+static CONFIG_PATH: std::sync::Mutex<Option<PathBuf>> = std::sync::Mutex::new(None);
+
+/// Points config loading at `path` instead of `config_dir()/config.yaml`.
+/// Pass `None` to go back to the default location.
+#[cfg(not(feature = "browser"))]
+pub fn set_config_path(path: Option<PathBuf>) {
+    *CONFIG_PATH.lock().unwrap() = path;
+}
+
+#[cfg(not(feature = "browser"))]
+fn config_path_override() -> Option<PathBuf> {
+    CONFIG_PATH.lock().unwrap().clone()
+}
+
+/// Path of the config file in effect: the `--config` override if one was
+/// given, otherwise `config.yaml` in `config_dir()`.
+#[cfg(not(feature = "browser"))]
+pub fn config_file_path() -> Option<PathBuf> {
+    config_path_override().or_else(|| config_dir().map(|d| d.join("config.yaml")))
+}
+
+/// Reads the config file. A missing or malformed file at the default
+/// location is ignored, but a file named with `--config` must exist and parse.
+#[cfg(not(feature = "browser"))]
+fn load_config_file() -> Result<Option<FileConfig>> {
+    if let Some(path) = config_path_override() {
+        let contents = std::fs::read_to_string(&path)
+            .map_err(|e| anyhow::anyhow!("failed to read config {}: {e}", path.display()))?;
+        let file_cfg = serde_yaml::from_str(&contents)
+            .map_err(|e| anyhow::anyhow!("failed to parse config {}: {e}", path.display()))?;
+        return Ok(Some(file_cfg));
+    }
+    let Some(path) = config_file_path() else {
+        return Ok(None);
+    };
+    let Ok(contents) = std::fs::read_to_string(path) else {
+        return Ok(None);
+    };
+    Ok(serde_yaml::from_str(&contents).ok())
+}
+
+/// Load the `profiles:` section of the config file. Returns an empty map if
+/// there is no config file; a file that fails to parse is an error.
 #[cfg(not(feature = "browser"))]
-fn load_config_file() -> Option<FileConfig> {
-    let path = config_dir()?.join("config.yaml");
-    let contents = std::fs::read_to_string(path).ok()?;
-    serde_yaml::from_str(&contents).ok()
+pub fn load_profiles() -> Result<HashMap<String, Profile>> {
+    let Some(path) = config_file_path() else {
+        return Ok(HashMap::new());
+    };
+    let Ok(contents) = std::fs::read_to_string(&path) else {
+        return Ok(HashMap::new());
+    };
+    parse_profiles(&contents)
+        .map_err(|e| anyhow::anyhow!("failed to parse profiles in {}: {e}", path.display()))
+}
+
+#[cfg(not(feature = "browser"))]
+fn parse_profiles(contents: &str) -> Result<HashMap<String, Profile>> {
+    let file_cfg: FileConfig = serde_yaml::from_str(contents)?;
+    Ok(file_cfg.profiles)
 }
 
 /// Try to load a valid (non-expired) access token from keychain/file storage.
@@ -241,6 +425,11 @@ mod tests {
             output_format: OutputFormat::Json,
             auto_approve: false,
             agent_mode: false,
+            envelope: None,
+            table: Default::default(),
+            template: None,
+            summary: false,
+            profile: None,
         }
     }
 
@@ -253,6 +442,15 @@ mod tests {
             OutputFormat::Table
         );
         assert_eq!("yaml".parse::<OutputFormat>().unwrap(), OutputFormat::Yaml);
+        assert_eq!("csv".parse::<OutputFormat>().unwrap(), OutputFormat::Csv);
+        assert_eq!(
+            "junit".parse::<OutputFormat>().unwrap(),
+            OutputFormat::Junit
+        );
+        assert_eq!(
+            "prometheus".parse::<OutputFormat>().unwrap(),
+            OutputFormat::Prometheus
+        );
         assert!("xml".parse::<OutputFormat>().is_err());
     }
 
@@ -261,6 +459,27 @@ mod tests {
         assert_eq!(OutputFormat::Json.to_string(), "json");
         assert_eq!(OutputFormat::Table.to_string(), "table");
         assert_eq!(OutputFormat::Yaml.to_string(), "yaml");
+        assert_eq!(OutputFormat::Csv.to_string(), "csv");
+        assert_eq!(OutputFormat::Junit.to_string(), "junit");
+        assert_eq!(OutputFormat::Prometheus.to_string(), "prometheus");
+    }
+
+    #[test]
+    fn test_output_format_default_for_terminal() {
+        assert_eq!(OutputFormat::default_for(true), OutputFormat::Table);
+        assert_eq!(OutputFormat::default_for(false), OutputFormat::Json);
+    }
+
+    #[test]
+    fn test_output_format_default_for_redirected_stdout() {
+        use std::io::IsTerminal;
+        let path = std::env::temp_dir().join("pup_test_output_redirect.txt");
+        let file = std::fs::File::create(&path).unwrap();
+        assert_eq!(
+            OutputFormat::default_for(file.is_terminal()),
+            OutputFormat::Json
+        );
+        let _ = std::fs::remove_file(&path);
     }
 
     #[test]
@@ -454,4 +673,141 @@ mod tests {
         );
         std::env::remove_var("__PUP_TEST_ENV_EMPTY__");
     }
+
+    #[test]
+    fn test_parse_profiles() {
+        let yaml = r#"
+site: datadoghq.com
+profiles:
+  prod:
+    api_key: prod-api
+    app_key: prod-app
+  eu:
+    site: datadoghq.eu
+    access_token: eu-token
+"#;
+        let profiles = parse_profiles(yaml).unwrap();
+        assert_eq!(profiles.len(), 2);
+        assert_eq!(profiles["prod"].api_key.as_deref(), Some("prod-api"));
+        assert_eq!(profiles["eu"].site.as_deref(), Some("datadoghq.eu"));
+    }
+
+    #[test]
+    fn test_parse_profiles_missing_section() {
+        assert!(parse_profiles("site: datadoghq.com\n").unwrap().is_empty());
+    }
+
+    #[test]
+    fn test_with_profile_replaces_credentials() {
+        let base = make_cfg(Some("base-api"), Some("base-app"), None);
+        let profile = Profile {
+            access_token: Some("eu-token".into()),
+            site: Some("datadoghq.eu".into()),
+            ..Default::default()
+        };
+        let cfg = base.with_profile("prod", &profile);
+        assert_eq!(cfg.access_token.as_deref(), Some("eu-token"));
+        assert!(
+            cfg.api_key.is_none(),
+            "base keys must not leak into the profile"
+        );
+        assert!(cfg.app_key.is_none());
+        assert_eq!(cfg.site, "datadoghq.eu");
+
+        let cfg = base.with_profile("prod", &Profile::default());
+        assert_eq!(cfg.site, "datadoghq.com");
+    }
+
+    #[test]
+    fn test_resolve_site_aliases() {
+        assert_eq!(resolve_site("eu1"), "datadoghq.eu");
+        assert_eq!(resolve_site("us1"), "datadoghq.com");
+        assert_eq!(resolve_site("us3"), "us3.datadoghq.com");
+        assert_eq!(resolve_site("us5"), "us5.datadoghq.com");
+        assert_eq!(resolve_site("ap1"), "ap1.datadoghq.com");
+        assert_eq!(resolve_site("gov"), "ddog-gov.com");
+        assert_eq!(resolve_site(" EU1 "), "datadoghq.eu");
+    }
+
+    #[test]
+    fn test_resolve_site_keeps_domains() {
+        for (_, domain) in SITE_ALIASES {
+            assert_eq!(resolve_site(domain), *domain);
+        }
+        assert_eq!(
+            resolve_site("navy.oncall.datadoghq.com"),
+            "navy.oncall.datadoghq.com"
+        );
+    }
+
+    #[test]
+    fn test_cache_scope_is_per_profile() {
+        let base = make_cfg(Some("k"), Some("a"), None);
+        let prod = base.with_profile("prod", &Profile::default());
+        let eu = base.with_profile("eu", &Profile::default());
+        assert_eq!(prod.site, eu.site);
+        assert_ne!(prod.cache_scope(), eu.cache_scope());
+        assert_ne!(prod.cache_scope(), base.cache_scope());
+        assert!(prod.cache_scope().starts_with(&base.api_base_url()));
+    }
+
+    #[test]
+    fn test_with_profile_resolves_site_alias() {
+        let base = make_cfg(None, None, Some("token"));
+        let profile = Profile {
+            site: Some("eu1".into()),
+            ..Default::default()
+        };
+        assert_eq!(base.with_profile("prod", &profile).site, "datadoghq.eu");
+    }
+
+    #[test]
+    fn test_from_env_reads_config_override() {
+        let _guard = ENV_LOCK.lock().unwrap_or_else(|p| p.into_inner());
+        for key in ["DD_API_KEY", "DD_APP_KEY", "DD_SITE", "DD_OUTPUT"] {
+            std::env::remove_var(key);
+        }
+        let path = std::env::temp_dir().join("pup_test_config_override.yaml");
+        std::fs::write(
+            &path,
+            "api_key: file-api\napp_key: file-app\nsite: eu1\noutput: yaml\nprofiles:\n  staging:\n    api_key: staging-api\n",
+        )
+        .unwrap();
+        set_config_path(Some(path.clone()));
+
+        let cfg = Config::from_env(None).unwrap();
+        assert_eq!(cfg.api_key.as_deref(), Some("file-api"));
+        assert_eq!(cfg.app_key.as_deref(), Some("file-app"));
+        assert_eq!(cfg.site, "datadoghq.eu");
+        assert_eq!(cfg.output_format, OutputFormat::Yaml);
+        assert_eq!(config_file_path(), Some(path.clone()));
+
+        let profiles = load_profiles().unwrap();
+        assert_eq!(profiles["staging"].api_key.as_deref(), Some("staging-api"));
+
+        // env and flags still win over the file
+        std::env::set_var("DD_API_KEY", "env-api");
+        let cfg = Config::from_env(Some("us3")).unwrap();
+        assert_eq!(cfg.api_key.as_deref(), Some("env-api"));
+        assert_eq!(cfg.site, "us3.datadoghq.com");
+
+        std::env::remove_var("DD_API_KEY");
+        set_config_path(None);
+        let _ = std::fs::remove_file(&path);
+    }
+
+    #[test]
+    fn test_from_env_missing_config_override_fails() {
+        let _guard = ENV_LOCK.lock().unwrap_or_else(|p| p.into_inner());
+        let path = std::env::temp_dir().join("pup_test_config_missing.yaml");
+        let _ = std::fs::remove_file(&path);
+        set_config_path(Some(path));
+        let result = Config::from_env(None);
+        set_config_path(None);
+        let err = result
+            .err()
+            .expect("missing --config file must fail")
+            .to_string();
+        assert!(err.contains("pup_test_config_missing.yaml"), "{err}");
+    }
 }
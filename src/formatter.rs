@@ -25,6 +25,103 @@ struct AgentEnvelope<'a, T: Serialize> {
     metadata: Option<&'a Metadata>,
 }
 
+/// Provenance recorded alongside a result when `--envelope` is set.
+#[derive(Serialize, Clone, Debug, Default)]
+pub struct RequestInfo {
+    /// Invoked command path, e.g. "logs search".
+    pub command: String,
+    /// Raw arguments as passed on the command line.
+    pub args: Vec<String>,
+    /// Resolved start of the time window (RFC3339), if the command takes --from.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub from: Option<String>,
+    /// Resolved end of the time window (RFC3339), if the command takes --to.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub to: Option<String>,
+    /// When the command was invoked (RFC3339).
+    pub timestamp: String,
+    /// Config profile the command ran against, when fanned out with `--orgs`.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub profile: Option<String>,
+}
+
+/// Arrays with at most this many items are shown inline in table cells;
+/// longer ones collapse to `[N items]`.
+pub const DEFAULT_INLINE_ARRAY_LIMIT: usize = 3;
+
+/// Rendering options for `--output=table`, set from global flags.
+#[derive(Clone, Debug)]
+pub struct TableOptions {
+    /// Wrap long values across lines instead of truncating them. Only takes
+    /// effect when stdout is a terminal or `width` is set; piped output
+    /// otherwise keeps truncation.
+    pub wrap: bool,
+    /// Whether ANSI color may be used (resolved from `--color`).
+    pub color: bool,
+    /// Terms to highlight in the message column; empty disables highlighting.
+    pub highlight: Vec<String>,
+    /// Render booleans as Y/N instead of ✓/✗ (`--ascii`).
+    pub ascii: bool,
+    /// Largest array shown inline (`--inline-array-limit`); 0 always
+    /// summarizes.
+    pub inline_array_limit: usize,
+    /// Show JSON:API relationships by the names of their `included`
+    /// resources instead of raw ids (`--resolve-relationships`).
+    pub resolve_relationships: bool,
+    /// Render `*_at` timestamps as relative times and durations as `2m15s`
+    /// in table and CSV output (`--humanize`).
+    pub humanize: bool,
+    /// Text shown for null or missing values in table and CSV cells
+    /// (`--empty-placeholder`); empty by default.
+    pub empty_placeholder: String,
+    /// Width to fit tables into (`--width`); None uses the terminal width,
+    /// or leaves piped output unfitted.
+    pub width: Option<usize>,
+}
+
+impl Default for TableOptions {
+    fn default() -> Self {
+        Self {
+            wrap: false,
+            color: false,
+            highlight: Vec::new(),
+            ascii: false,
+            inline_array_limit: DEFAULT_INLINE_ARRAY_LIMIT,
+            resolve_relationships: false,
+            humanize: false,
+            empty_placeholder: String::new(),
+            width: None,
+        }
+    }
+}
+
+/// Resolves `--color` (auto, always, never). `auto` colors only when stdout
+/// is a terminal and NO_COLOR is unset.
+pub fn color_enabled(mode: &str) -> Result<bool> {
+    use std::io::IsTerminal;
+    match mode.to_lowercase().as_str() {
+        "always" => Ok(true),
+        "never" => Ok(false),
+        "auto" => Ok(std::io::stdout().is_terminal() && std::env::var_os("NO_COLOR").is_none()),
+        _ => anyhow::bail!("invalid color mode: {mode:?} (expected auto, always, or never)"),
+    }
+}
+
+/// Envelope wrapper: { request, result }
+#[derive(Serialize)]
+struct RequestEnvelope<'a, T: Serialize> {
+    request: &'a RequestInfo,
+    result: &'a T,
+}
+
+/// Wrap data in a self-describing envelope with request metadata.
+pub fn envelope_value<T: Serialize>(data: &T, info: &RequestInfo) -> Result<serde_json::Value> {
+    Ok(serde_json::to_value(RequestEnvelope {
+        request: info,
+        result: data,
+    })?)
+}
+
 /// Recursively sort all JSON object keys alphabetically.
 fn sort_json_value(v: serde_json::Value) -> serde_json::Value {
     match v {
@@ -67,34 +164,326 @@ pub fn format_and_print<T: Serialize>(
             metadata: meta,
         };
         let json = go_html_escape(&serde_json::to_string_pretty(&envelope)?);
-        println!("{json}");
-        return Ok(());
+        return print_block(&format!("{json}\n"));
     }
 
     match format {
         OutputFormat::Json => print_json(data),
         OutputFormat::Yaml => print_yaml(data),
         OutputFormat::Table => print_table(data),
+        OutputFormat::Csv => print_csv(data),
+        OutputFormat::Junit => print_junit(data),
+        OutputFormat::Prometheus => print_prometheus(data),
+    }
+}
+
+/// Writes whole blocks of output under a lock so concurrent callers (parallel
+/// `--orgs` runs, progress messages) never interleave within a block.
+pub struct SyncWriter<W: std::io::Write> {
+    inner: std::sync::Mutex<W>,
+}
+
+impl<W: std::io::Write> SyncWriter<W> {
+    pub const fn new(inner: W) -> Self {
+        SyncWriter {
+            inner: std::sync::Mutex::new(inner),
+        }
+    }
+
+    /// Write `block` in full and flush before releasing the lock.
+    pub fn write_block(&self, block: &str) -> Result<()> {
+        let mut w = self.inner.lock().unwrap_or_else(|e| e.into_inner());
+        w.write_all(block.as_bytes())?;
+        w.flush()?;
+        Ok(())
+    }
+
+    pub fn into_inner(self) -> W {
+        self.inner.into_inner().unwrap_or_else(|e| e.into_inner())
+    }
+}
+
+/// Unit handles so the process-wide writers can be built in a `static`.
+struct Stdout;
+struct Stderr;
+
+impl std::io::Write for Stdout {
+    fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+        std::io::stdout().lock().write(buf)
+    }
+    fn write_all(&mut self, buf: &[u8]) -> std::io::Result<()> {
+        std::io::stdout().lock().write_all(buf)
+    }
+    fn flush(&mut self) -> std::io::Result<()> {
+        std::io::stdout().flush()
+    }
+}
+
+impl std::io::Write for Stderr {
+    fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+        std::io::stderr().lock().write(buf)
+    }
+    fn write_all(&mut self, buf: &[u8]) -> std::io::Result<()> {
+        std::io::stderr().lock().write_all(buf)
+    }
+    fn flush(&mut self) -> std::io::Result<()> {
+        std::io::stderr().flush()
+    }
+}
+
+static STDOUT: SyncWriter<Stdout> = SyncWriter::new(Stdout);
+static STDERR: SyncWriter<Stderr> = SyncWriter::new(Stderr);
+
+/// Print a block of command output to stdout atomically. While output is
+/// being captured the block is collected as a string value instead.
+pub fn print_block(block: &str) -> Result<()> {
+    let text = block.strip_suffix('\n').unwrap_or(block);
+    let captured = with_capture(|stack| match stack.last_mut() {
+        Some(values) => {
+            values.push(serde_json::Value::String(text.to_string()));
+            true
+        }
+        None => false,
+    });
+    if captured {
+        return Ok(());
+    }
+    STDOUT.write_block(block)
+}
+
+/// Print a status line to stderr atomically.
+pub fn print_status(msg: &str) {
+    // Status output is best effort; a closed stderr must not fail the command.
+    let _ = STDERR.write_block(&format!("{msg}\n"));
+}
+
+/// Print a prompt to stderr without a trailing newline.
+pub fn print_prompt(msg: &str) {
+    let _ = STDERR.write_block(msg);
+}
+
+/// Output being collected instead of printed, innermost capture last. Used by
+/// `--orgs` and `pup batch` to gather each run's result into one aggregated
+/// output; a batch run under `--orgs` nests one capture inside another.
+///
+/// The stack is per thread: output is only ever written by the thread driving
+/// the command (fan-out tasks just fetch), so a capture never collects output
+/// from an unrelated run, such as another test executing in parallel.
+type CaptureStack = Vec<Vec<serde_json::Value>>;
+
+thread_local! {
+    static CAPTURE: std::cell::RefCell<CaptureStack> = const { std::cell::RefCell::new(Vec::new()) };
+}
+
+fn with_capture<R>(f: impl FnOnce(&mut CaptureStack) -> R) -> R {
+    CAPTURE.with(|stack| f(&mut stack.borrow_mut()))
+}
+
+/// Start collecting output instead of printing it.
+pub fn begin_capture() {
+    with_capture(|stack| stack.push(Vec::new()));
+}
+
+/// Stop collecting output and return everything captured since the matching
+/// `begin_capture`.
+pub fn end_capture() -> Vec<serde_json::Value> {
+    with_capture(|stack| stack.pop()).unwrap_or_default()
+}
+
+/// Whether output is currently being captured. Interactive prompts cannot be
+/// answered while it is.
+pub fn capturing() -> bool {
+    with_capture(|stack| !stack.is_empty())
+}
+
+/// Collapses the values captured from one run into a single result: null
+/// when nothing was output, the value itself for one, an array otherwise.
+pub fn captured_result(mut values: Vec<serde_json::Value>) -> serde_json::Value {
+    match values.len() {
+        0 => serde_json::Value::Null,
+        1 => values.remove(0),
+        _ => serde_json::Value::Array(values),
     }
 }
 
+/// Build one `--orgs` result block: an envelope whose request names the
+/// profile, holding the captured output as `result`, or `error` if the run failed.
+pub fn org_block(
+    info: &RequestInfo,
+    outcome: std::result::Result<Vec<serde_json::Value>, String>,
+) -> Result<serde_json::Value> {
+    let request = serde_json::to_value(info)?;
+    Ok(match outcome {
+        Ok(values) => {
+            serde_json::json!({ "request": request, "result": captured_result(values) })
+        }
+        Err(error) => serde_json::json!({ "request": request, "error": error }),
+    })
+}
+
 /// Convenience: format and print using config settings (respects -o flag and agent mode).
 pub fn output<T: Serialize>(cfg: &crate::config::Config, data: &T) -> Result<()> {
-    format_and_print(data, &cfg.output_format, cfg.agent_mode, None)
+    output_with_meta(cfg, data, None)
+}
+
+/// Like `output`, with agent mode metadata. Applies the `--envelope` wrapper
+/// for JSON/YAML output; tables and agent mode are left untouched.
+pub fn output_with_meta<T: Serialize>(
+    cfg: &crate::config::Config,
+    data: &T,
+    meta: Option<&Metadata>,
+) -> Result<()> {
+    if cfg.summary {
+        let summary = summarize(&serde_json::to_value(data)?);
+        let cfg = crate::config::Config {
+            summary: false,
+            ..cfg.clone()
+        };
+        return output_with_meta(&cfg, &summary, None);
+    }
+    if capturing() {
+        let value = serde_json::to_value(data)?;
+        with_capture(|stack| {
+            if let Some(values) = stack.last_mut() {
+                values.push(value);
+            }
+        });
+        return Ok(());
+    }
+    if let Some(template) = &cfg.template {
+        return print_block(&template.render(&serde_json::to_value(data)?)?);
+    }
+    if let Some(info) = &cfg.envelope {
+        if !cfg.agent_mode && matches!(cfg.output_format, OutputFormat::Json | OutputFormat::Yaml) {
+            let wrapped = envelope_value(data, info)?;
+            return format_and_print(&wrapped, &cfg.output_format, false, None);
+        }
+    }
+    if cfg.table.humanize
+        && !cfg.agent_mode
+        && matches!(cfg.output_format, OutputFormat::Table | OutputFormat::Csv)
+    {
+        let humanized = humanize_value(serde_json::to_value(data)?, unix_now());
+        let mut cfg = cfg.clone();
+        cfg.table.humanize = false;
+        return output_with_meta(&cfg, &humanized, meta);
+    }
+    if !cfg.agent_mode && cfg.output_format == OutputFormat::Table {
+        return print_table_with(data, &cfg.table);
+    }
+    if !cfg.agent_mode
+        && cfg.output_format == OutputFormat::Csv
+        && !cfg.table.empty_placeholder.is_empty()
+    {
+        let value = serde_json::to_value(data)?;
+        return print_block(&render_csv_with(&value, &cfg.table.empty_placeholder));
+    }
+    format_and_print(data, &cfg.output_format, cfg.agent_mode, meta)
+}
+
+/// A JSON response body kept as received, alongside its decoded value.
+/// Printing the original text avoids the lossy round trip through `Value`,
+/// where integers wider than 64 bits become floats in scientific notation.
+pub struct RawJson {
+    pub text: String,
+    pub value: serde_json::Value,
+}
+
+impl RawJson {
+    /// Decodes `text`, keeping it for pass-through output. An empty body
+    /// (e.g. HTTP 204) is treated as `{}`.
+    pub fn parse(text: String) -> Result<Self> {
+        let text = if text.trim().is_empty() {
+            "{}".to_string()
+        } else {
+            text
+        };
+        let value = serde_json::from_str(&text)
+            .map_err(|e| anyhow::anyhow!("failed to parse JSON response: {e}"))?;
+        Ok(RawJson { text, value })
+    }
+}
+
+/// Prints a raw JSON response. Plain `-o json` output re-indents the
+/// original text, keeping number literals and key order exactly as sent;
+/// every other mode goes through `output` with the decoded value.
+pub fn output_raw_json(cfg: &crate::config::Config, raw: &RawJson) -> Result<()> {
+    let capturing = capturing();
+    let plain_json = cfg.output_format == OutputFormat::Json
+        && !cfg.agent_mode
+        && !cfg.summary
+        && cfg.template.is_none()
+        && cfg.envelope.is_none();
+    if plain_json && !capturing {
+        return print_block(&format!("{}\n", reindent_json(&raw.text)));
+    }
+    output(cfg, &raw.value)
+}
+
+/// Pretty-prints JSON text in the same layout as `serde_json::to_string_pretty`
+/// without decoding it, so strings and numbers are copied verbatim. `json`
+/// must already be valid JSON.
+pub fn reindent_json(json: &str) -> String {
+    fn newline(out: &mut String, depth: usize) {
+        out.push('\n');
+        out.push_str(&"  ".repeat(depth));
+    }
+
+    let mut out = String::with_capacity(json.len() * 2);
+    let mut depth = 0usize;
+    let mut chars = json.chars().peekable();
+    while let Some(c) = chars.next() {
+        match c {
+            '"' => {
+                out.push('"');
+                while let Some(s) = chars.next() {
+                    out.push(s);
+                    match s {
+                        '\\' => out.extend(chars.next()),
+                        '"' => break,
+                        _ => {}
+                    }
+                }
+            }
+            '{' | '[' => {
+                while chars.peek().is_some_and(|n| n.is_whitespace()) {
+                    chars.next();
+                }
+                out.push(c);
+                if matches!(chars.peek(), Some('}' | ']')) {
+                    out.extend(chars.next());
+                } else {
+                    depth += 1;
+                    newline(&mut out, depth);
+                }
+            }
+            '}' | ']' => {
+                depth = depth.saturating_sub(1);
+                newline(&mut out, depth);
+                out.push(c);
+            }
+            ',' => {
+                out.push(',');
+                newline(&mut out, depth);
+            }
+            ':' => out.push_str(": "),
+            c if c.is_whitespace() => {}
+            c => out.push(c),
+        }
+    }
+    out
 }
 
 pub fn print_json<T: Serialize>(data: &T) -> Result<()> {
     let sorted_data = sort_json_value(serde_json::to_value(data)?);
     let json = go_html_escape(&serde_json::to_string_pretty(&sorted_data)?);
-    println!("{json}");
-    Ok(())
+    print_block(&format!("{json}\n"))
 }
 
 fn print_yaml<T: Serialize>(data: &T) -> Result<()> {
     let sorted_data = sort_json_value(serde_json::to_value(data)?);
     let yaml = serde_yaml::to_string(&sorted_data)?;
-    print!("{yaml}");
-    Ok(())
+    print_block(&yaml)
 }
 
 /// Flatten up to two levels of nested objects into dot-notation keys.
@@ -124,30 +513,430 @@ fn flatten_row(value: &serde_json::Value) -> serde_json::Value {
     }
 }
 
+/// Replaces JSON:API relationship references with the display names of the
+/// matching `included` resources, so `relationships.commander` shows
+/// "Jane Doe" rather than `{2 fields}`. References with no included match,
+/// and responses without `included`, are left as they are.
+fn resolve_relationships(value: &serde_json::Value) -> serde_json::Value {
+    let Some(included) = value.get("included").and_then(|v| v.as_array()) else {
+        return value.clone();
+    };
+    let names: std::collections::HashMap<(&str, &str), &str> = included
+        .iter()
+        .filter_map(|res| {
+            let kind = res.get("type")?.as_str()?;
+            let id = res.get("id")?.as_str()?;
+            let attrs = res.get("attributes")?;
+            let name = ["name", "title", "handle", "email"]
+                .iter()
+                .find_map(|k| attrs.get(*k).and_then(|v| v.as_str()))?;
+            Some(((kind, id), name))
+        })
+        .collect();
+    let lookup = |reference: &serde_json::Value| -> Option<serde_json::Value> {
+        let kind = reference.get("type")?.as_str()?;
+        let id = reference.get("id")?.as_str()?;
+        names.get(&(kind, id)).map(|n| serde_json::json!(n))
+    };
+
+    let mut out = value.clone();
+    let rows: Vec<&mut serde_json::Value> = match out.get_mut("data") {
+        Some(serde_json::Value::Array(rows)) => rows.iter_mut().collect(),
+        Some(row @ serde_json::Value::Object(_)) => vec![row],
+        _ => vec![],
+    };
+    for row in rows {
+        let Some(rels) = row.get_mut("relationships").and_then(|r| r.as_object_mut()) else {
+            continue;
+        };
+        for rel in rels.values_mut() {
+            let resolved = match rel.get("data") {
+                Some(serde_json::Value::Array(refs)) if !refs.is_empty() => {
+                    let names: Option<Vec<_>> = refs.iter().map(&lookup).collect();
+                    names.map(serde_json::Value::Array)
+                }
+                Some(reference @ serde_json::Value::Object(_)) => lookup(reference),
+                _ => None,
+            };
+            if let Some(resolved) = resolved {
+                *rel = resolved;
+            }
+        }
+    }
+    out
+}
+
 fn print_table<T: Serialize>(data: &T) -> Result<()> {
+    print_table_with(data, &TableOptions::default())
+}
+
+fn print_table_with<T: Serialize>(data: &T, opts: &TableOptions) -> Result<()> {
     // Convert to serde_json::Value to inspect structure
-    let value = serde_json::to_value(data)?;
+    let mut value = serde_json::to_value(data)?;
+    if let Some((headers, rows)) = timeseries_table(&value) {
+        return print_grid(None, &headers, rows, opts);
+    }
+    if let Some((title, headers, rows)) = aggregate_table(&value) {
+        return print_grid(Some(&title), &headers, rows, opts);
+    }
+    if opts.resolve_relationships {
+        value = resolve_relationships(&value);
+    }
+    if let Some(resource) = single_resource(&value) {
+        return print_block(&render_key_values(resource, opts));
+    }
     let raw_rows = extract_rows(&value);
     let owned_rows: Vec<serde_json::Value> = raw_rows.iter().map(|r| flatten_row(r)).collect();
     let rows: Vec<&serde_json::Value> = owned_rows.iter().collect();
 
     if rows.is_empty() {
-        println!("No results found");
-        return Ok(());
+        return print_block("No results found\n");
     }
 
-    // Collect headers from all rows
-    let mut headers: Vec<String> = Vec::new();
-    let mut header_set = std::collections::HashSet::new();
-    for row in &rows {
+    let mut final_headers = table_columns(&rows);
+    let all_columns = final_headers.len();
+    let width = table_width(opts);
+
+    let wrap_width = match width {
+        Some(width) if opts.wrap => {
+            final_headers.truncate(columns_at_min_width(width));
+            Some(column_budget(width, final_headers.len()))
+        }
+        _ => None,
+    };
+
+    let mut grid: Vec<Vec<String>> = rows
+        .iter()
+        .map(|row| {
+            final_headers
+                .iter()
+                .map(|h| {
+                    if let serde_json::Value::Object(map) = row {
+                        format_table_cell(map.get(h.as_str()), wrap_width, opts)
+                    } else {
+                        opts.empty_placeholder.clone()
+                    }
+                })
+                .collect()
+        })
+        .collect();
+    if let (Some(width), false) = (width, opts.wrap) {
+        fit_to_width(&mut final_headers, &mut grid, width);
+    }
+
+    let mut table = comfy_table::Table::new();
+    table.set_header(&final_headers);
+    for cells in grid {
+        table.add_row(cells);
+    }
+
+    let mut rendered = table.to_string();
+    if opts.color && !opts.highlight.is_empty() {
+        if let Some(col) = final_headers.iter().position(|h| is_message_column(h)) {
+            rendered = highlight_column(&rendered, col, &opts.highlight);
+        }
+    }
+    print_block(&format!("{rendered}\n"))?;
+    // On stderr so the table itself stays clean for pipes.
+    let dropped = all_columns - final_headers.len();
+    if dropped > 0 {
+        print_status(&dropped_columns_note(dropped));
+    }
+    if let Some(footer) = truncation_footer(&value) {
+        print_status(&footer);
+    }
+    Ok(())
+}
+
+/// Note for columns left out because the table didn't fit the width.
+fn dropped_columns_note(dropped: usize) -> String {
+    let noun = if dropped == 1 { "column" } else { "columns" };
+    format!("+{dropped} more {noun} (use --output=json)")
+}
+
+/// The resource in a single-object response, either a bare object or a
+/// JSON:API `{"data": {...}}` document. Tables show it as key/value pairs.
+fn single_resource(
+    value: &serde_json::Value,
+) -> Option<&serde_json::Map<String, serde_json::Value>> {
+    let map = value.as_object()?;
+    match map.get("data") {
+        Some(data) => data.as_object(),
+        None => Some(map),
+    }
+}
+
+/// An optional section title and the (path, value) fields under it.
+type KeyValueSection<'a> = (Option<String>, Vec<(String, &'a serde_json::Value)>);
+
+/// Fields of a resource grouped for the key/value view. Scalars (and
+/// arrays) at the top come first, untitled; each nested object becomes a
+/// section named after its key, holding its leaves by dotted path.
+/// JSON:API `attributes` are hoisted so that their nested objects, such as
+/// `options` or `fields`, get sections of their own.
+fn key_value_sections(
+    resource: &serde_json::Map<String, serde_json::Value>,
+) -> Vec<KeyValueSection<'_>> {
+    let mut top = Vec::new();
+    let mut sections: Vec<KeyValueSection<'_>> = Vec::new();
+    let entries = resource.iter().flat_map(|(k, v)| match v {
+        serde_json::Value::Object(attrs) if k == "attributes" => attrs.iter().collect::<Vec<_>>(),
+        _ => vec![(k, v)],
+    });
+    for (key, value) in entries {
+        match value {
+            serde_json::Value::Object(map) if !map.is_empty() => {
+                let mut fields = Vec::new();
+                flatten_paths(value, "", &mut fields);
+                sections.push((Some(section_title(key)), fields));
+            }
+            _ => top.push((key.clone(), value)),
+        }
+    }
+    if !top.is_empty() {
+        sections.insert(0, (None, top));
+    }
+    sections
+}
+
+/// Leaf values under `value` keyed by their dot-separated path below
+/// `prefix`, in document order. Arrays and empty objects are leaves.
+fn flatten_paths<'a>(
+    value: &'a serde_json::Value,
+    prefix: &str,
+    out: &mut Vec<(String, &'a serde_json::Value)>,
+) {
+    match value {
+        serde_json::Value::Object(map) if !map.is_empty() => {
+            for (k, v) in map {
+                let path = if prefix.is_empty() {
+                    k.clone()
+                } else {
+                    format!("{prefix}.{k}")
+                };
+                flatten_paths(v, &path, out);
+            }
+        }
+        _ => out.push((prefix.to_string(), value)),
+    }
+}
+
+/// "customer_impact" → "Customer impact".
+fn section_title(key: &str) -> String {
+    let words = key.replace('_', " ");
+    let mut chars = words.chars();
+    match chars.next() {
+        Some(first) => first.to_uppercase().chain(chars).collect(),
+        None => words,
+    }
+}
+
+/// Renders a single resource as field/value tables, one per section, each
+/// nested section preceded by its title.
+fn render_key_values(
+    resource: &serde_json::Map<String, serde_json::Value>,
+    opts: &TableOptions,
+) -> String {
+    let sections = key_value_sections(resource);
+    if sections.is_empty() {
+        return "No results found\n".to_string();
+    }
+    let width = table_width(opts);
+    let wrap_width = width.filter(|_| opts.wrap).map(|w| column_budget(w, 2));
+    let mut out = String::new();
+    for (title, fields) in sections {
+        let mut headers = vec!["field".to_string(), "value".to_string()];
+        let mut grid: Vec<Vec<String>> = fields
+            .into_iter()
+            .map(|(key, value)| vec![key, format_table_cell(Some(value), wrap_width, opts)])
+            .collect();
+        if let (Some(width), false) = (width, opts.wrap) {
+            fit_to_width(&mut headers, &mut grid, width);
+        }
+        let mut table = comfy_table::Table::new();
+        table.set_header(&headers);
+        for row in grid {
+            table.add_row(row);
+        }
+        if let Some(title) = title {
+            if !out.is_empty() {
+                out.push('\n');
+            }
+            out.push_str(&format!("{title}\n"));
+        }
+        out.push_str(&format!("{table}\n"));
+    }
+    out
+}
+
+/// Pointers to the total result count in the pagination metadata of
+/// list responses.
+const TOTAL_COUNT_POINTERS: &[&str] = &[
+    "/meta/page/total_filtered_count",
+    "/meta/page/total_count",
+    "/meta/pagination/total_count",
+    "/meta/pagination/total",
+    "/meta/total_count",
+];
+
+/// Footer noting that a `data` list holds only part of the results, either
+/// because `meta` reports a larger total or because it points at a next
+/// page. None when the list looks complete.
+fn truncation_footer(value: &serde_json::Value) -> Option<String> {
+    let shown = value.get("data")?.as_array()?.len() as u64;
+    let total = TOTAL_COUNT_POINTERS
+        .iter()
+        .find_map(|p| value.pointer(p).and_then(|v| v.as_u64()));
+    let has_next = value
+        .pointer("/meta/page/after")
+        .or_else(|| value.pointer("/meta/pagination/next_offset"))
+        .or_else(|| value.pointer("/links/next"))
+        .is_some_and(|v| !v.is_null() && v.as_str() != Some(""));
+    match total {
+        Some(total) if total > shown => Some(format!(
+            "Showing {shown} of {total} (raise --limit or narrow the query to see more)"
+        )),
+        Some(_) => None,
+        None if has_next => Some(format!(
+            "Showing {shown}; more results are available (raise --limit or narrow the query to see more)"
+        )),
+        None => None,
+    }
+}
+
+/// Prints pre-rendered rows under `headers`, optionally preceded by a title
+/// line, filling empty cells with the configured placeholder.
+fn print_grid(
+    title: Option<&str>,
+    headers: &[String],
+    rows: Vec<Vec<String>>,
+    opts: &TableOptions,
+) -> Result<()> {
+    if rows.is_empty() {
+        return print_block("No results found\n");
+    }
+    let mut table = comfy_table::Table::new();
+    table.set_header(headers);
+    for row in rows {
+        table.add_row(row.into_iter().map(|cell| {
+            if cell.is_empty() {
+                opts.empty_placeholder.clone()
+            } else {
+                cell
+            }
+        }));
+    }
+    match title {
+        Some(title) => print_block(&format!("{title}\n{table}\n")),
+        None => print_block(&format!("{table}\n")),
+    }
+}
+
+const ANSI_HIGHLIGHT: &str = "\x1b[1;33m";
+const ANSI_RESET: &str = "\x1b[0m";
+
+fn is_message_column(header: &str) -> bool {
+    header == "message" || header.ends_with(".message")
+}
+
+/// Highlights `terms` inside one column of a rendered table. Works on the
+/// rendered text so the escape codes don't count towards comfy-table's column
+/// widths; column bounds come from the '+' corners of the top border. The
+/// header row is left alone.
+fn highlight_column(rendered: &str, col: usize, terms: &[String]) -> String {
+    let Some(border) = rendered.lines().next() else {
+        return rendered.to_string();
+    };
+    let bounds: Vec<usize> = border
+        .chars()
+        .enumerate()
+        .filter(|(_, c)| *c == '+')
+        .map(|(i, _)| i)
+        .collect();
+    if bounds.len() < col + 2 {
+        return rendered.to_string();
+    }
+    let (start, end) = (bounds[col] + 1, bounds[col + 1]);
+
+    let mut seen_header = false;
+    let lines: Vec<String> = rendered
+        .lines()
+        .map(|line| {
+            if !line.starts_with('|') {
+                return line.to_string();
+            }
+            if !seen_header {
+                seen_header = true;
+                return line.to_string();
+            }
+            let chars: Vec<char> = line.chars().collect();
+            if chars.len() < end {
+                return line.to_string();
+            }
+            let head: String = chars[..start].iter().collect();
+            let cell: String = chars[start..end].iter().collect();
+            let tail: String = chars[end..].iter().collect();
+            format!("{head}{}{tail}", highlight_text(&cell, terms))
+        })
+        .collect();
+    lines.join("\n")
+}
+
+/// Wraps case-insensitive (ASCII) occurrences of `terms` in `text` with ANSI
+/// bold yellow. Longer terms win when several match at the same position.
+fn highlight_text(text: &str, terms: &[String]) -> String {
+    let mut terms: Vec<String> = terms
+        .iter()
+        .filter(|t| !t.is_empty())
+        .map(|t| t.to_ascii_lowercase())
+        .collect();
+    terms.sort_by_key(|t| std::cmp::Reverse(t.len()));
+
+    let lower = text.to_ascii_lowercase();
+    let mut out = String::with_capacity(text.len());
+    let mut i = 0;
+    while i < text.len() {
+        if let Some(term) = terms.iter().find(|t| lower[i..].starts_with(t.as_str())) {
+            out.push_str(ANSI_HIGHLIGHT);
+            out.push_str(&text[i..i + term.len()]);
+            out.push_str(ANSI_RESET);
+            i += term.len();
+        } else {
+            let ch = text[i..].chars().next().unwrap_or_default();
+            out.push(ch);
+            i += ch.len_utf8();
+        }
+    }
+    out
+}
+
+/// Most columns a table shows; the rest are dropped to keep rows readable.
+const MAX_TABLE_COLUMNS: usize = 12;
+
+/// Column names across all rows in first-seen order. The order depends only
+/// on the input, so table and CSV layouts are identical from run to run.
+fn collect_columns(rows: &[&serde_json::Value]) -> Vec<String> {
+    let mut columns: Vec<String> = Vec::new();
+    let mut seen = std::collections::HashSet::new();
+    for row in rows {
         if let serde_json::Value::Object(map) = row {
             for key in map.keys() {
-                if header_set.insert(key.clone()) {
-                    headers.push(key.clone());
+                if seen.insert(key.as_str()) {
+                    columns.push(key.clone());
                 }
             }
         }
     }
+    columns
+}
+
+/// Table columns, chosen heuristically so wide resources stay readable:
+/// well-known fields (at the top level or under JSON:API `attributes`) are
+/// pinned first in a fixed order, then scalar columns in first-seen order,
+/// then columns holding arrays or objects if room remains, up to
+/// `MAX_TABLE_COLUMNS` in total.
+fn table_columns(rows: &[&serde_json::Value]) -> Vec<String> {
+    let columns = collect_columns(rows);
 
     // Prioritize common fields (including flattened log attribute fields)
     let priority = [
@@ -168,155 +957,2102 @@ fn print_table<T: Serialize>(data: &T) -> Result<()> {
         "attributes.status",
         "attributes.message",
     ];
-    let mut final_headers: Vec<String> = Vec::new();
-    for &p in &priority {
-        if header_set.contains(p) {
-            final_headers.push(p.to_string());
+    let mut final_columns: Vec<String> = Vec::new();
+    for p in priority {
+        let nested = format!("attributes.{p}");
+        for candidate in [p, nested.as_str()] {
+            if columns.iter().any(|c| c == candidate)
+                && !final_columns.iter().any(|c| c == candidate)
+            {
+                final_columns.push(candidate.to_string());
+            }
+        }
+    }
+    final_columns.truncate(MAX_TABLE_COLUMNS);
+
+    let (scalar, nested): (Vec<String>, Vec<String>) = columns
+        .into_iter()
+        .filter(|c| !final_columns.contains(c))
+        .partition(|c| is_scalar_column(rows, c));
+    let room = MAX_TABLE_COLUMNS - final_columns.len();
+    final_columns.extend(scalar.into_iter().chain(nested).take(room));
+    final_columns
+}
+
+/// True when no row holds an array or object under `column`.
+fn is_scalar_column(rows: &[&serde_json::Value], column: &str) -> bool {
+    rows.iter().all(|row| {
+        !matches!(
+            row.get(column),
+            Some(serde_json::Value::Array(_) | serde_json::Value::Object(_))
+        )
+    })
+}
+
+fn print_csv<T: Serialize>(data: &T) -> Result<()> {
+    let value = serde_json::to_value(data)?;
+    print_block(&render_csv(&value))
+}
+
+/// Render a JSON value as CSV. Timeseries responses become long-format
+/// `timestamp,series,value` rows; anything else is flattened like the table view.
+fn render_csv(value: &serde_json::Value) -> String {
+    render_csv_with(value, "")
+}
+
+/// Like `render_csv`, writing `empty` for null or missing cells.
+fn render_csv_with(value: &serde_json::Value, empty: &str) -> String {
+    if let Some(rows) = timeseries_long_rows(value) {
+        let mut out = String::from("timestamp,series,value\n");
+        for (ts, series, val) in rows {
+            out.push_str(&format!("{ts},{},{val}\n", csv_field(&series)));
+        }
+        return out;
+    }
+
+    let rows: Vec<serde_json::Value> = extract_rows(value).into_iter().map(flatten_row).collect();
+    let headers = collect_columns(&rows.iter().collect::<Vec<_>>());
+    if headers.is_empty() {
+        return String::new();
+    }
+
+    let mut out = headers
+        .iter()
+        .map(|h| csv_field(h))
+        .collect::<Vec<_>>()
+        .join(",");
+    out.push('\n');
+    for row in &rows {
+        let cells: Vec<String> = headers
+            .iter()
+            .map(|h| match row.get(h.as_str()) {
+                None | Some(serde_json::Value::Null) => csv_field(empty),
+                value => csv_field(&csv_value(value)),
+            })
+            .collect();
+        out.push_str(&cells.join(","));
+        out.push('\n');
+    }
+    out
+}
+
+/// One CSV record, quoted as needed and ending in a newline, for writers
+/// that stream rows instead of rendering a whole response.
+pub fn csv_line<S: AsRef<str>>(cells: &[S]) -> String {
+    let mut line = cells
+        .iter()
+        .map(|c| csv_field(c.as_ref()))
+        .collect::<Vec<_>>()
+        .join(",");
+    line.push('\n');
+    line
+}
+
+/// Plain-text cell for CSV: strings unquoted, nested values as compact JSON.
+pub fn csv_value(value: Option<&serde_json::Value>) -> String {
+    match value {
+        None | Some(serde_json::Value::Null) => String::new(),
+        Some(serde_json::Value::String(s)) => s.clone(),
+        Some(v) => v.to_string(),
+    }
+}
+
+/// Quote a CSV field if it contains a delimiter, quote, or newline (RFC 4180).
+fn csv_field(field: &str) -> String {
+    if field.contains([',', '"', '\n', '\r']) {
+        format!("\"{}\"", field.replace('"', "\"\""))
+    } else {
+        field.to_string()
+    }
+}
+
+/// Extracts `(timestamp_ms, series, value)` rows from a timeseries response,
+/// one per point per series. Returns None for data that isn't a timeseries.
+fn timeseries_long_rows(value: &serde_json::Value) -> Option<Vec<(String, String, String)>> {
+    let number = |v: Option<&serde_json::Value>| match v {
+        Some(serde_json::Value::Number(n)) => n.to_string(),
+        _ => String::new(),
+    };
+    let mut rows = Vec::new();
+    for (label, points) in timeseries_series(value)? {
+        for (ts, val) in points {
+            rows.push((ts, label.clone(), number(val)));
+        }
+    }
+    Some(rows)
+}
+
+/// A labeled series and its `(timestamp_ms, value)` points.
+type TimeSeries<'a> = (String, Vec<(String, Option<&'a serde_json::Value>)>);
+
+/// Splits a timeseries response into labeled series. Handles the v2
+/// `timeseries_response` shape (series labeled by group tags, sharing one
+/// `times` axis), the v1 metrics query shape (`series[]` with their own
+/// `pointlist`, labeled by tag set or scope), and logs/RUM aggregates with
+/// timeseries computes (one series per group and compute). Returns None for
+/// other data.
+fn timeseries_series(value: &serde_json::Value) -> Option<Vec<TimeSeries<'_>>> {
+    // Timestamps come back as floats in v1 pointlists; print them as integers.
+    let timestamp = |v: &serde_json::Value| match v.as_f64() {
+        Some(f) => format!("{}", f as i64),
+        None => String::new(),
+    };
+
+    let data = &value["data"];
+    if data["type"] == "timeseries_response" {
+        let attrs = &data["attributes"];
+        let times = attrs["times"].as_array()?;
+        let series = attrs["series"].as_array()?;
+        let values = attrs["values"].as_array()?;
+        let mut out = Vec::new();
+        for (i, s) in series.iter().enumerate() {
+            let tags: Vec<&str> = s["group_tags"]
+                .as_array()
+                .map(|t| t.iter().filter_map(|v| v.as_str()).collect())
+                .unwrap_or_default();
+            let label = if tags.is_empty() {
+                format!("query{}", s["query_index"].as_u64().unwrap_or(i as u64))
+            } else {
+                tags.join(",")
+            };
+            let points = values.get(i).and_then(|v| v.as_array());
+            let points = times
+                .iter()
+                .enumerate()
+                .map(|(j, ts)| (timestamp(ts), points.and_then(|p| p.get(j))))
+                .collect();
+            out.push((label, points));
+        }
+        return Some(out);
+    }
+
+    if let Some(buckets) = data["buckets"].as_array() {
+        // Labeled by the group's facet values, plus the compute name when a
+        // group has several; an ungrouped aggregate uses the compute name.
+        let mut out = Vec::new();
+        for bucket in buckets {
+            let computes = bucket["computes"].as_object()?;
+            let group: Vec<String> = bucket["by"]
+                .as_object()
+                .map(|by| {
+                    by.iter()
+                        .map(|(k, v)| format!("{k}:{}", format_cell(Some(v))))
+                        .collect()
+                })
+                .unwrap_or_default();
+            for (name, points) in computes {
+                let label = match (group.is_empty(), computes.len()) {
+                    (true, _) => name.clone(),
+                    (false, 1) => group.join(","),
+                    (false, _) => format!("{name} {}", group.join(",")),
+                };
+                let points = points
+                    .as_array()?
+                    .iter()
+                    .map(|p| {
+                        let ts = match &p["time"] {
+                            serde_json::Value::String(t) => t.clone(),
+                            t => timestamp(t),
+                        };
+                        (ts, p.get("value"))
+                    })
+                    .collect();
+                out.push((label, points));
+            }
+        }
+        return if out.is_empty() { None } else { Some(out) };
+    }
+
+    let series = value["series"].as_array()?;
+    if series.iter().any(|s| !s["pointlist"].is_array()) {
+        return None;
+    }
+    let mut out = Vec::new();
+    for s in series {
+        let tags: Vec<&str> = s["tag_set"]
+            .as_array()
+            .map(|t| t.iter().filter_map(|v| v.as_str()).collect())
+            .unwrap_or_default();
+        let label = if tags.is_empty() {
+            s["scope"].as_str().unwrap_or("*").to_string()
+        } else {
+            tags.join(",")
+        };
+        let points = s["pointlist"]
+            .as_array()
+            .into_iter()
+            .flatten()
+            .map(|point| {
+                let ts = point.get(0).map(timestamp).unwrap_or_default();
+                (ts, point.get(1))
+            })
+            .collect();
+        out.push((label, points));
+    }
+    Some(out)
+}
+
+/// Lays a timeseries out with one row per timestamp and one column per
+/// series. Series from separate queries can report different timestamps, so
+/// the rows cover the union of all of them in time order, with an empty cell
+/// wherever a series has no point.
+fn timeseries_table(value: &serde_json::Value) -> Option<(Vec<String>, Vec<Vec<String>>)> {
+    let series = timeseries_series(value)?;
+    let mut headers = vec!["timestamp".to_string()];
+    for (label, _) in &series {
+        // Two queries can produce the same label; keep their columns apart.
+        let mut header = label.clone();
+        let mut n = 2;
+        while headers.contains(&header) {
+            header = format!("{label} ({n})");
+            n += 1;
+        }
+        headers.push(header);
+    }
+
+    let mut rows: std::collections::BTreeMap<(i64, String), Vec<String>> =
+        std::collections::BTreeMap::new();
+    for (i, (_, points)) in series.iter().enumerate() {
+        for (ts, val) in points {
+            let key = (ts.parse().unwrap_or(i64::MAX), ts.clone());
+            let row = rows
+                .entry(key)
+                .or_insert_with(|| vec![String::new(); series.len()]);
+            if let Some(serde_json::Value::Number(n)) = val {
+                row[i] = format_number(n);
+            }
         }
     }
-    for h in &headers {
-        if final_headers.len() >= 12 {
-            break;
-        }
-        if !final_headers.contains(h) {
-            final_headers.push(h.clone());
+    let rows = rows
+        .into_iter()
+        .map(|((_, ts), cells)| std::iter::once(ts).chain(cells).collect())
+        .collect();
+    Some((headers, rows))
+}
+
+/// Lays out a logs/RUM aggregate grouped by a single facet as a group
+/// column plus one column per compute, largest value first, with a title
+/// such as `c0 by status`. Other shapes (no group-by, several facets,
+/// timeseries computes) fall back to the generic rendering.
+fn aggregate_table(value: &serde_json::Value) -> Option<(String, Vec<String>, Vec<Vec<String>>)> {
+    let buckets = value.pointer("/data/buckets")?.as_array()?;
+    let first = buckets.first()?;
+    let facets = first.get("by")?.as_object()?;
+    if facets.len() != 1 {
+        return None;
+    }
+    let facet = facets.keys().next()?.clone();
+    let computes: Vec<String> = first
+        .get("computes")?
+        .as_object()?
+        .keys()
+        .cloned()
+        .collect();
+    if computes.is_empty() {
+        return None;
+    }
+
+    let mut rows = Vec::with_capacity(buckets.len());
+    for bucket in buckets {
+        let group = bucket.get("by")?.get(&facet)?;
+        let values = computes
+            .iter()
+            .map(|c| match bucket.pointer(&format!("/computes/{c}")) {
+                Some(serde_json::Value::Number(n)) => Some(Some(n)),
+                None | Some(serde_json::Value::Null) => Some(None),
+                Some(_) => None,
+            })
+            .collect::<Option<Vec<_>>>()?;
+        rows.push((format_cell(Some(group)), values));
+    }
+    rows.sort_by(|(_, a), (_, b)| {
+        let key = |v: &[Option<&serde_json::Number>]| v[0].and_then(|n| n.as_f64());
+        key(b)
+            .partial_cmp(&key(a))
+            .unwrap_or(std::cmp::Ordering::Equal)
+    });
+
+    let title = format!("{} by {facet}", computes.join(", "));
+    let headers = std::iter::once(facet).chain(computes).collect();
+    let rows = rows
+        .into_iter()
+        .map(|(group, values)| {
+            std::iter::once(group)
+                .chain(
+                    values
+                        .into_iter()
+                        .map(|v| v.map(format_number).unwrap_or_default()),
+                )
+                .collect()
+        })
+        .collect();
+    Some((title, headers, rows))
+}
+
+fn print_junit<T: Serialize>(data: &T) -> Result<()> {
+    let value = serde_json::to_value(data)?;
+    print_block(&render_junit(&value)?)
+}
+
+/// The JUnit XML report `--output=junit` would print for `value`, for
+/// commands that also write the report to a file.
+pub fn junit_report(value: &serde_json::Value) -> Result<String> {
+    render_junit(value)
+}
+
+/// One `<testcase>` of a JUnit report.
+struct JunitCase {
+    suite: String,
+    name: String,
+    /// Duration in seconds, when the result reports one.
+    time: Option<f64>,
+    outcome: JunitOutcome,
+}
+
+enum JunitOutcome {
+    Passed,
+    Failed(Vec<String>),
+    Skipped,
+}
+
+/// Render synthetics results or CI test events as a JUnit XML report, one
+/// `<testsuite>` per suite in first-seen order. Other data is an error, since
+/// a report with no test cases would read as a passing run.
+fn render_junit(value: &serde_json::Value) -> Result<String> {
+    let Some(cases) = synthetics_junit_cases(value).or_else(|| ci_junit_cases(value)) else {
+        anyhow::bail!(
+            "--output=junit supports synthetics results and CI test events only; \
+             use json, table, yaml, or csv for this command"
+        );
+    };
+
+    let mut suites: Vec<(&str, Vec<&JunitCase>)> = Vec::new();
+    for case in &cases {
+        match suites.iter_mut().find(|(name, _)| *name == case.suite) {
+            Some((_, members)) => members.push(case),
+            None => suites.push((&case.suite, vec![case])),
+        }
+    }
+    let failed = |cases: &[&JunitCase]| {
+        cases
+            .iter()
+            .filter(|c| matches!(c.outcome, JunitOutcome::Failed(_)))
+            .count()
+    };
+    let skipped = |cases: &[&JunitCase]| {
+        cases
+            .iter()
+            .filter(|c| matches!(c.outcome, JunitOutcome::Skipped))
+            .count()
+    };
+
+    let all: Vec<&JunitCase> = cases.iter().collect();
+    let mut out = String::from("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n");
+    out.push_str(&format!(
+        "<testsuites tests=\"{}\" failures=\"{}\" skipped=\"{}\">\n",
+        all.len(),
+        failed(&all),
+        skipped(&all)
+    ));
+    for (suite, members) in &suites {
+        let time = members
+            .iter()
+            .filter_map(|c| c.time)
+            .fold(0.0, |a, b| a + b);
+        out.push_str(&format!(
+            "  <testsuite name=\"{}\" tests=\"{}\" failures=\"{}\" skipped=\"{}\" time=\"{time:.3}\">\n",
+            xml_escape(suite),
+            members.len(),
+            failed(members),
+            skipped(members)
+        ));
+        for case in members {
+            out.push_str(&format!(
+                "    <testcase name=\"{}\" classname=\"{}\"",
+                xml_escape(&case.name),
+                xml_escape(suite)
+            ));
+            if let Some(time) = case.time {
+                out.push_str(&format!(" time=\"{time:.3}\""));
+            }
+            match &case.outcome {
+                JunitOutcome::Passed => out.push_str("/>\n"),
+                JunitOutcome::Skipped => out.push_str(">\n      <skipped/>\n    </testcase>\n"),
+                JunitOutcome::Failed(messages) => {
+                    out.push_str(">\n");
+                    for message in messages {
+                        out.push_str(&format!(
+                            "      <failure message=\"{}\"/>\n",
+                            xml_escape(message)
+                        ));
+                    }
+                    out.push_str("    </testcase>\n");
+                }
+            }
+        }
+        out.push_str("  </testsuite>\n");
+    }
+    out.push_str("</testsuites>\n");
+    Ok(out)
+}
+
+/// Test cases from a synthetics results response (`{results: [{result_id,
+/// probe_dc, result: {passed, ...}}]}`), one per run and location, grouped
+/// by `test_name` when results span several tests (`synthetics run`). Browser
+/// results carry no `passed` flag, so the run's status decides. Failed runs
+/// report the failure message and each failed assertion.
+fn synthetics_junit_cases(value: &serde_json::Value) -> Option<Vec<JunitCase>> {
+    let results = value.get("results")?.as_array()?;
+    if !results.iter().all(|r| r.get("result_id").is_some()) {
+        return None;
+    }
+    let cases = results
+        .iter()
+        .map(|r| {
+            let result = &r["result"];
+            let id = r["result_id"]
+                .as_str()
+                .map(str::to_string)
+                .unwrap_or_else(|| r["result_id"].to_string());
+            let name = match r["probe_dc"].as_str() {
+                Some(location) => format!("{location} ({id})"),
+                None => id,
+            };
+            let passed = match result["passed"].as_bool() {
+                Some(passed) => passed,
+                None => r["status"].as_i64() == Some(0),
+            };
+            let outcome = if passed {
+                JunitOutcome::Passed
+            } else {
+                JunitOutcome::Failed(synthetics_failures(result))
+            };
+            JunitCase {
+                suite: r["test_name"].as_str().unwrap_or("synthetics").to_string(),
+                name,
+                time: result["timings"]["total"]
+                    .as_f64()
+                    .or_else(|| result["duration"].as_f64())
+                    .map(|ms| ms / 1000.0),
+                outcome,
+            }
+        })
+        .collect();
+    Some(cases)
+}
+
+fn synthetics_failures(result: &serde_json::Value) -> Vec<String> {
+    let mut messages = Vec::new();
+    let failure = &result["failure"];
+    match (failure["code"].as_str(), failure["message"].as_str()) {
+        (Some(code), Some(message)) => messages.push(format!("{code}: {message}")),
+        (code, message) => messages.extend(code.or(message).map(str::to_string)),
+    }
+    if let Some(message) = result["errorMessage"].as_str() {
+        messages.push(message.to_string());
+    }
+    for assertion in result["assertionResults"].as_array().into_iter().flatten() {
+        if assertion["valid"].as_bool() != Some(false) {
+            continue;
+        }
+        let field = |key: &str| match &assertion[key] {
+            serde_json::Value::Null => None,
+            serde_json::Value::String(s) => Some(s.clone()),
+            v => Some(v.to_string()),
+        };
+        let mut message = String::from("assertion failed");
+        if let Some(kind) = field("type") {
+            message.push_str(&format!(": {kind}"));
+        }
+        if let Some(operator) = field("operator") {
+            message.push_str(&format!(" {operator}"));
+        }
+        if let Some(expected) = field("target").or_else(|| field("expected")) {
+            message.push_str(&format!(" {expected}"));
+        }
+        if let Some(actual) = field("actual") {
+            message.push_str(&format!(", got {actual}"));
+        }
+        messages.push(message);
+    }
+    if messages.is_empty() {
+        messages.push("test failed".to_string());
+    }
+    messages
+}
+
+/// Test cases from CI Visibility test events (`{data: [{attributes:
+/// {attributes: {test: {name, suite, status}, duration}}}]}`). Attributes may
+/// be nested or dotted (`test.name`); `duration` is in nanoseconds.
+fn ci_junit_cases(value: &serde_json::Value) -> Option<Vec<JunitCase>> {
+    let events = value.get("data")?.as_array()?;
+    let field = |attrs: &serde_json::Value, group: &str, key: &str| -> Option<String> {
+        attrs[group][key]
+            .as_str()
+            .or_else(|| attrs[format!("{group}.{key}").as_str()].as_str())
+            .map(str::to_string)
+    };
+    events
+        .iter()
+        .map(|event| {
+            let attrs = &event["attributes"]["attributes"];
+            let name = field(attrs, "test", "name")?;
+            let outcome = match field(attrs, "test", "status").as_deref() {
+                Some("fail") => {
+                    let message = field(attrs, "error", "message")
+                        .or_else(|| field(attrs, "error", "type"))
+                        .unwrap_or_else(|| "test failed".to_string());
+                    JunitOutcome::Failed(vec![message])
+                }
+                Some("skip") => JunitOutcome::Skipped,
+                _ => JunitOutcome::Passed,
+            };
+            Some(JunitCase {
+                suite: field(attrs, "test", "suite").unwrap_or_else(|| "ci".to_string()),
+                name,
+                time: attrs["duration"].as_f64().map(|ns| ns / 1e9),
+                outcome,
+            })
+        })
+        .collect()
+}
+
+/// Escape text for an XML attribute value.
+fn xml_escape(s: &str) -> String {
+    let mut out = String::with_capacity(s.len());
+    for c in s.chars() {
+        match c {
+            '&' => out.push_str("&amp;"),
+            '<' => out.push_str("&lt;"),
+            '>' => out.push_str("&gt;"),
+            '"' => out.push_str("&quot;"),
+            '\'' => out.push_str("&apos;"),
+            '\n' => out.push_str("&#10;"),
+            c if c.is_control() && c != '\t' => {}
+            c => out.push(c),
+        }
+    }
+    out
+}
+
+fn print_prometheus<T: Serialize>(data: &T) -> Result<()> {
+    let value = serde_json::to_value(data)?;
+    print_block(&render_prometheus(&value)?)
+}
+
+/// Render metrics query series in the Prometheus text exposition format:
+/// one `name{labels} value timestamp_ms` line per point, series grouped
+/// under a `# TYPE` line per metric. Tags become labels and null points
+/// are dropped. Other data is an error.
+fn render_prometheus(value: &serde_json::Value) -> Result<String> {
+    let Some(series) = value["series"]
+        .as_array()
+        .filter(|series| series.iter().all(|s| s["pointlist"].is_array()))
+    else {
+        anyhow::bail!(
+            "--output=prometheus supports metrics query results only; \
+             use json, table, yaml, or csv for this command"
+        );
+    };
+
+    let mut families: Vec<(String, Vec<String>)> = Vec::new();
+    for s in series {
+        let name = prometheus_name(s["metric"].as_str().unwrap_or("datadog_metric"));
+        let labels = prometheus_labels(&s["tag_set"]);
+        let lines: Vec<String> = s["pointlist"]
+            .as_array()
+            .into_iter()
+            .flatten()
+            .filter_map(|point| {
+                let ts = point.get(0)?.as_f64()?;
+                let v = point.get(1)?.as_f64()?;
+                Some(format!(
+                    "{name}{labels} {} {}",
+                    prometheus_value(v),
+                    ts as i64
+                ))
+            })
+            .collect();
+        match families.iter_mut().find(|(n, _)| *n == name) {
+            Some((_, existing)) => existing.extend(lines),
+            None => families.push((name, lines)),
+        }
+    }
+
+    let mut out = String::new();
+    for (name, lines) in families {
+        out.push_str(&format!("# TYPE {name} untyped\n"));
+        for line in lines {
+            out.push_str(&line);
+            out.push('\n');
+        }
+    }
+    Ok(out)
+}
+
+/// Datadog names like `system.cpu.user` as valid Prometheus metric names.
+fn prometheus_name(metric: &str) -> String {
+    let mut name: String = metric
+        .chars()
+        .map(|c| {
+            if c.is_ascii_alphanumeric() || c == '_' || c == ':' {
+                c
+            } else {
+                '_'
+            }
+        })
+        .collect();
+    if !name.starts_with(|c: char| c.is_ascii_alphabetic() || c == '_' || c == ':') {
+        name.insert(0, '_');
+    }
+    name
+}
+
+/// `{key="value",...}` from a Datadog tag set, or nothing for an empty
+/// set. Repeated keys are joined with ","; bare tags get the value "true".
+fn prometheus_labels(tag_set: &serde_json::Value) -> String {
+    let mut labels: Vec<(String, String)> = Vec::new();
+    for tag in tag_set
+        .as_array()
+        .into_iter()
+        .flatten()
+        .filter_map(|t| t.as_str())
+    {
+        let (key, value) = tag.split_once(':').unwrap_or((tag, "true"));
+        let mut key = prometheus_name(key).replace(':', "_");
+        if key.starts_with("__") {
+            // Double-underscore label names are reserved by Prometheus.
+            key.replace_range(..2, "_");
+        }
+        match labels.iter_mut().find(|(k, _)| *k == key) {
+            Some((_, existing)) => {
+                existing.push(',');
+                existing.push_str(value);
+            }
+            None => labels.push((key, value.to_string())),
+        }
+    }
+    if labels.is_empty() {
+        return String::new();
+    }
+    let pairs: Vec<String> = labels
+        .iter()
+        .map(|(k, v)| {
+            let v = v
+                .replace('\\', "\\\\")
+                .replace('"', "\\\"")
+                .replace('\n', "\\n");
+            format!("{k}=\"{v}\"")
+        })
+        .collect();
+    format!("{{{}}}", pairs.join(","))
+}
+
+fn prometheus_value(v: f64) -> String {
+    if v.is_nan() {
+        "NaN".to_string()
+    } else if v.is_infinite() {
+        if v > 0.0 { "+Inf" } else { "-Inf" }.to_string()
+    } else {
+        v.to_string()
+    }
+}
+
+type Summarizer = fn(&[&serde_json::Value]) -> serde_json::Map<String, serde_json::Value>;
+
+/// `--summary` handlers keyed on the resource type of the response items.
+/// Types without an entry get a plain count.
+const SUMMARIZERS: &[(&str, Summarizer)] = &[
+    ("incidents", summarize_incidents),
+    ("monitors", summarize_monitors),
+    ("log", summarize_logs),
+];
+
+/// Reduces a list response to a short summary: `{type, total, ...}` plus
+/// whatever stats the resource's summarizer adds.
+pub fn summarize(value: &serde_json::Value) -> serde_json::Value {
+    // Active metrics lists are `{from, metrics: [names]}` rather than rows.
+    if let Some(metrics) = value.get("metrics").and_then(|m| m.as_array()) {
+        return serde_json::json!({"type": "metrics", "total": metrics.len()});
+    }
+
+    let rows = extract_rows(value);
+    let kind = summary_kind(&rows);
+    let mut summary = serde_json::Map::new();
+    if let Some(kind) = kind {
+        summary.insert("type".into(), kind.into());
+    }
+    summary.insert("total".into(), rows.len().into());
+    if let Some((_, summarizer)) = SUMMARIZERS.iter().find(|(k, _)| Some(*k) == kind) {
+        summary.extend(summarizer(&rows));
+    }
+    serde_json::Value::Object(summary)
+}
+
+/// Resource type shared by the rows: the JSON:API `type` of the first row,
+/// or "monitors" for v1 monitor objects, which carry no resource type.
+fn summary_kind<'a>(rows: &[&'a serde_json::Value]) -> Option<&'a str> {
+    let first = rows.first()?;
+    if first.get("overall_state").is_some() {
+        return Some("monitors");
+    }
+    if first.get("attributes").is_some() {
+        return first.get("type").and_then(|t| t.as_str());
+    }
+    None
+}
+
+/// Counts rows by the string at `pointer`, with missing values as "unknown".
+fn count_by(rows: &[&serde_json::Value], pointer: &str) -> serde_json::Value {
+    let mut counts = std::collections::BTreeMap::<String, u64>::new();
+    for row in rows {
+        let key = row
+            .pointer(pointer)
+            .and_then(|v| v.as_str())
+            .unwrap_or("unknown");
+        *counts.entry(key.to_string()).or_default() += 1;
+    }
+    serde_json::json!(counts)
+}
+
+fn summarize_incidents(rows: &[&serde_json::Value]) -> serde_json::Map<String, serde_json::Value> {
+    let mut out = serde_json::Map::new();
+    out.insert("by_state".into(), count_by(rows, "/attributes/state"));
+    out.insert("by_severity".into(), count_by(rows, "/attributes/severity"));
+    out
+}
+
+fn summarize_monitors(rows: &[&serde_json::Value]) -> serde_json::Map<String, serde_json::Value> {
+    let mut out = serde_json::Map::new();
+    out.insert("by_status".into(), count_by(rows, "/overall_state"));
+    out
+}
+
+fn summarize_logs(rows: &[&serde_json::Value]) -> serde_json::Map<String, serde_json::Value> {
+    // RFC 3339 UTC timestamps order correctly as strings.
+    let timestamps: Vec<&str> = rows
+        .iter()
+        .filter_map(|r| r.pointer("/attributes/timestamp").and_then(|t| t.as_str()))
+        .collect();
+    let mut out = serde_json::Map::new();
+    if let (Some(oldest), Some(newest)) = (timestamps.iter().min(), timestamps.iter().max()) {
+        out.insert("oldest".into(), (*oldest).into());
+        out.insert("newest".into(), (*newest).into());
+    }
+    out
+}
+
+fn unix_now() -> i64 {
+    std::time::SystemTime::now()
+        .duration_since(std::time::UNIX_EPOCH)
+        .map(|d| d.as_secs() as i64)
+        .unwrap_or_default()
+}
+
+/// Rewrites time fields for display, judged by field name: `*_at` values
+/// (epoch seconds, epoch milliseconds, or RFC 3339) become relative times
+/// like "3h ago", and `*_duration` / `time_to_*` seconds become "2m15s".
+/// Other fields, and values that don't parse, are left alone.
+fn humanize_value(value: serde_json::Value, now: i64) -> serde_json::Value {
+    match value {
+        serde_json::Value::Object(map) => map
+            .into_iter()
+            .map(|(k, v)| {
+                let v = match humanize_field(&k, &v, now) {
+                    Some(text) => serde_json::Value::String(text),
+                    None => humanize_value(v, now),
+                };
+                (k, v)
+            })
+            .collect(),
+        serde_json::Value::Array(items) => {
+            items.into_iter().map(|v| humanize_value(v, now)).collect()
+        }
+        other => other,
+    }
+}
+
+fn humanize_field(key: &str, value: &serde_json::Value, now: i64) -> Option<String> {
+    if key.ends_with("_at") {
+        let ts = match value {
+            serde_json::Value::Number(n) => {
+                let n = n.as_f64()? as i64;
+                // Anything past year 5000 in seconds is really milliseconds.
+                if n > 100_000_000_000 {
+                    n / 1000
+                } else {
+                    n
+                }
+            }
+            serde_json::Value::String(s) => parse_rfc3339_secs(s)?,
+            _ => return None,
+        };
+        return Some(relative_time(ts, now));
+    }
+    if key.ends_with("_duration") || key.starts_with("time_to_") {
+        return Some(human_duration(value.as_f64()?.round() as i64));
+    }
+    None
+}
+
+/// "45s ago", "3h ago", "in 2d": the largest whole unit only.
+fn relative_time(ts: i64, now: i64) -> String {
+    let diff = now - ts;
+    let span = human_units(diff.abs(), 1);
+    if diff >= 0 {
+        format!("{span} ago")
+    } else {
+        format!("in {span}")
+    }
+}
+
+/// "2m15s", "1d3h": the two largest non-zero units.
+fn human_duration(secs: i64) -> String {
+    let text = human_units(secs.abs(), 2);
+    if secs < 0 {
+        format!("-{text}")
+    } else {
+        text
+    }
+}
+
+fn human_units(secs: i64, max_units: usize) -> String {
+    let units = [("d", 86_400), ("h", 3_600), ("m", 60), ("s", 1)];
+    let mut rest = secs;
+    let mut parts = Vec::new();
+    for (suffix, size) in units {
+        let n = rest / size;
+        rest %= size;
+        if n > 0 && parts.len() < max_units {
+            parts.push(format!("{n}{suffix}"));
+        } else if !parts.is_empty() {
+            // Stop at the first gap so "1d0h5m" doesn't become "1d5m".
+            break;
+        }
+    }
+    if parts.is_empty() {
+        "0s".to_string()
+    } else {
+        parts.concat()
+    }
+}
+
+/// Parses "2024-01-02T03:04:05Z", with optional fractional seconds and a
+/// `±HH:MM` offset, to Unix seconds. The formatter is also built for the
+/// browser, so this avoids depending on chrono.
+fn parse_rfc3339_secs(s: &str) -> Option<i64> {
+    let b = s.as_bytes();
+    if b.len() < 20 || b[4] != b'-' || b[7] != b'-' || !matches!(b[10], b'T' | b't' | b' ') {
+        return None;
+    }
+    let num = |r: std::ops::Range<usize>| s.get(r)?.parse::<i64>().ok();
+    let (year, month, day) = (num(0..4)?, num(5..7)?, num(8..10)?);
+    let (hour, min, sec) = (num(11..13)?, num(14..16)?, num(17..19)?);
+    if !(1..=12).contains(&month) || !(1..=31).contains(&day) {
+        return None;
+    }
+
+    let mut rest = &s[19..];
+    if let Some(frac) = rest.strip_prefix('.') {
+        let digits = frac.bytes().take_while(u8::is_ascii_digit).count();
+        rest = &frac[digits..];
+    }
+    let offset = match rest {
+        "Z" | "z" => 0,
+        _ => {
+            let sign = match rest.as_bytes().first()? {
+                b'+' => 1,
+                b'-' => -1,
+                _ => return None,
+            };
+            let (h, m) = rest.get(1..)?.split_once(':')?;
+            sign * (h.parse::<i64>().ok()? * 3600 + m.parse::<i64>().ok()? * 60)
+        }
+    };
+
+    // Days from the civil date (Howard Hinnant's algorithm).
+    let y = if month <= 2 { year - 1 } else { year };
+    let era = y.div_euclid(400);
+    let yoe = y - era * 400;
+    let mp = (month + 9) % 12;
+    let doy = (153 * mp + 2) / 5 + day - 1;
+    let doe = yoe * 365 + yoe / 4 - yoe / 100 + doy;
+    let days = era * 146_097 + doe - 719_468;
+    Some(days * 86_400 + hour * 3600 + min * 60 + sec - offset)
+}
+
+/// Extract displayable rows from a JSON value.
+/// Handles: arrays, objects with "data" field, single objects.
+fn extract_rows(value: &serde_json::Value) -> Vec<&serde_json::Value> {
+    match value {
+        serde_json::Value::Array(arr) => arr.iter().collect(),
+        serde_json::Value::Object(map) => {
+            // API responses often wrap data: { "data": [...], "meta": ... }
+            if let Some(data) = map.get("data") {
+                return extract_rows(data);
+            }
+            vec![value]
+        }
+        _ => vec![],
+    }
+}
+
+/// Compact label for a single array element, used when previewing arrays in table cells.
+/// For objects, tries id/name/title/type in order; falls back to format_cell for primitives.
+fn format_array_item(value: &serde_json::Value) -> String {
+    if let serde_json::Value::Object(map) = value {
+        for key in &["name", "title", "id", "type"] {
+            if let Some(serde_json::Value::String(s)) = map.get(*key) {
+                return if s.len() > 16 {
+                    format!("{}...", &s[..13])
+                } else {
+                    s.clone()
+                };
+            }
+        }
+        return format!("{{{} fields}}", map.len());
+    }
+    format_cell(Some(value))
+}
+
+fn format_cell(value: Option<&serde_json::Value>) -> String {
+    match value {
+        None | Some(serde_json::Value::Null) => String::new(),
+        Some(serde_json::Value::String(s)) => {
+            if s.len() > 50 {
+                format!("{}...", &s[..47])
+            } else {
+                s.clone()
+            }
+        }
+        Some(serde_json::Value::Number(n)) => format_number(n),
+        Some(serde_json::Value::Bool(b)) => b.to_string(),
+        Some(serde_json::Value::Array(arr)) => {
+            if arr.is_empty() {
+                return "[]".to_string();
+            }
+            let mut parts: Vec<String> = arr.iter().take(4).map(format_array_item).collect();
+            if arr.len() > 4 {
+                parts.push(format!("+{} more", arr.len() - 4));
+            }
+            let result = format!("[{}]", parts.join(", "));
+            if result.len() > 50 {
+                format!("{}...", &result[..47])
+            } else {
+                result
+            }
+        }
+        Some(serde_json::Value::Object(map)) => format!("{{{} fields}}", map.len()),
+    }
+}
+
+/// Number text for table cells. Floats print in plain decimal: a whole
+/// number (an id or count that passed through an f64) without a trailing
+/// `.0`, and large or tiny values without serde's exponent form (`1e21`).
+fn format_number(n: &serde_json::Number) -> String {
+    match n.as_f64() {
+        Some(f) if n.is_f64() => format!("{f}"),
+        _ => n.to_string(),
+    }
+}
+
+/// Cell text for table output: nulls as the empty placeholder, booleans as
+/// icons, arrays inlined or counted per the inline limit, strings wrapped
+/// when a wrap width is set, everything else as in `format_cell`.
+fn format_table_cell(
+    value: Option<&serde_json::Value>,
+    wrap_width: Option<usize>,
+    opts: &TableOptions,
+) -> String {
+    match (value, wrap_width) {
+        (None | Some(serde_json::Value::Null), _) => opts.empty_placeholder.clone(),
+        (Some(serde_json::Value::Bool(b)), _) => bool_icon(*b, opts.ascii).to_string(),
+        (Some(serde_json::Value::Array(arr)), _) => {
+            format_array_cell(arr, opts.inline_array_limit, wrap_width.is_none())
+        }
+        (_, Some(width)) => format_cell_wrapped(value, width),
+        (_, None) => format_cell(value),
+    }
+}
+
+/// Shows up to `limit` items inline, otherwise just the count. Inline text
+/// is cut at 50 characters like other cells unless `truncate` is false.
+fn format_array_cell(arr: &[serde_json::Value], limit: usize, truncate: bool) -> String {
+    if arr.is_empty() {
+        return "[]".to_string();
+    }
+    if arr.len() > limit {
+        return match arr.len() {
+            1 => "[1 item]".to_string(),
+            n => format!("[{n} items]"),
+        };
+    }
+    let parts: Vec<String> = arr.iter().map(format_array_item).collect();
+    let result = format!("[{}]", parts.join(", "));
+    if truncate && result.chars().count() > 50 {
+        format!("{}...", result.chars().take(47).collect::<String>())
+    } else {
+        result
+    }
+}
+
+fn bool_icon(b: bool, ascii: bool) -> &'static str {
+    match (b, ascii) {
+        (true, false) => "✓",
+        (false, false) => "✗",
+        (true, true) => "Y",
+        (false, true) => "N",
+    }
+}
+
+/// Narrowest column `--wrap` will lay out, however many columns there are.
+const MIN_WRAP_WIDTH: usize = 10;
+
+/// Width tables are fitted to: `--width` when given, otherwise the
+/// terminal's. None when stdout is not a terminal, so piped output keeps
+/// every column at full length.
+fn table_width(opts: &TableOptions) -> Option<usize> {
+    use std::io::IsTerminal;
+    if opts.width.is_some() {
+        return opts.width;
+    }
+    if !std::io::stdout().is_terminal() {
+        return None;
+    }
+    comfy_table::Table::new().width().map(usize::from)
+}
+
+/// How many `--wrap` columns fit in `term_width` at MIN_WRAP_WIDTH each;
+/// always at least one.
+fn columns_at_min_width(term_width: usize) -> usize {
+    (term_width.saturating_sub(1) / (MIN_WRAP_WIDTH + 3)).max(1)
+}
+
+/// Splits the terminal width evenly across columns, leaving room for the
+/// "| " and " |" borders around each cell.
+fn column_budget(term_width: usize, columns: usize) -> usize {
+    (term_width.saturating_sub(3 * columns + 1) / columns).max(MIN_WRAP_WIDTH)
+}
+
+/// Narrowest a column is squeezed to before lower-priority columns are
+/// dropped instead.
+const MIN_COLUMN_WIDTH: usize = 8;
+
+/// Fits a rendered table into `width` terminal columns. Columns are kept in
+/// priority order (see `table_columns`) while each still gets
+/// MIN_COLUMN_WIDTH, or its content width if that is narrower; the rest are
+/// dropped. Kept columns wider than their share are cut with "...".
+fn fit_to_width(headers: &mut Vec<String>, grid: &mut [Vec<String>], width: usize) {
+    let natural: Vec<usize> = headers
+        .iter()
+        .enumerate()
+        .map(|(i, h)| {
+            grid.iter()
+                .map(|row| text_width(&row[i]))
+                .chain([text_width(h)])
+                .max()
+                .unwrap_or(0)
+        })
+        .collect();
+    let widths = fit_column_widths(&natural, width);
+    headers.truncate(widths.len());
+    for (header, &w) in headers.iter_mut().zip(&widths) {
+        *header = clip_text(header, w);
+    }
+    for row in grid.iter_mut() {
+        row.truncate(widths.len());
+        for (cell, &w) in row.iter_mut().zip(&widths) {
+            *cell = clip_text(cell, w);
+        }
+    }
+}
+
+/// Content widths for the columns that fit in `width`, given the width each
+/// column's content needs. Borders take 3 characters per column plus one.
+/// The result is a prefix of `natural` when everything fits; otherwise the
+/// space left after the minimums is shared in proportion to how much more
+/// each column wants, and columns that don't fit even at their minimum are
+/// left off the end.
+fn fit_column_widths(natural: &[usize], width: usize) -> Vec<usize> {
+    let total = |widths: &[usize]| widths.iter().map(|w| w + 3).sum::<usize>() + 1;
+    if total(natural) <= width {
+        return natural.to_vec();
+    }
+    let mins: Vec<usize> = natural.iter().map(|&w| w.min(MIN_COLUMN_WIDTH)).collect();
+    let mut kept = 0;
+    while kept < mins.len() && (kept == 0 || total(&mins[..=kept]) <= width) {
+        kept += 1;
+    }
+    let (natural, mins) = (&natural[..kept], &mins[..kept]);
+    if total(natural) <= width {
+        return natural.to_vec();
+    }
+    let spare = width.saturating_sub(total(mins));
+    let wanted: usize = natural.iter().zip(mins).map(|(n, m)| n - m).sum();
+    natural
+        .iter()
+        .zip(mins)
+        .map(|(n, m)| m + spare * (n - m) / wanted)
+        .collect()
+}
+
+/// Display width of the longest line in `text`.
+fn text_width(text: &str) -> usize {
+    text.lines().map(|l| l.chars().count()).max().unwrap_or(0)
+}
+
+/// Cuts each line of `text` to at most `width` characters, ending cut lines
+/// with "..." when there is room for it.
+fn clip_text(text: &str, width: usize) -> String {
+    text.split('\n')
+        .map(|line| {
+            if line.chars().count() <= width {
+                line.to_string()
+            } else if width <= 3 {
+                line.chars().take(width).collect()
+            } else {
+                let head: String = line.chars().take(width - 3).collect();
+                format!("{head}...")
+            }
+        })
+        .collect::<Vec<_>>()
+        .join("\n")
+}
+
+/// Like `format_cell`, but wraps strings to `width` instead of truncating.
+/// Arrays and objects keep their compact summaries.
+fn format_cell_wrapped(value: Option<&serde_json::Value>, width: usize) -> String {
+    match value {
+        Some(serde_json::Value::String(s)) => wrap_text(s, width),
+        other => format_cell(other),
+    }
+}
+
+/// Word-wraps `s` into lines of at most `width` characters, hard-breaking
+/// words that are longer than a line. Existing newlines are kept.
+fn wrap_text(s: &str, width: usize) -> String {
+    let width = width.max(1);
+    let mut lines: Vec<String> = Vec::new();
+    for paragraph in s.split('\n') {
+        let mut line = String::new();
+        let mut line_len = 0;
+        for word in paragraph.split_whitespace() {
+            let mut chars: Vec<char> = word.chars().collect();
+            if line_len > 0 && line_len + 1 + chars.len() > width {
+                lines.push(std::mem::take(&mut line));
+                line_len = 0;
+            }
+            while chars.len() > width {
+                let rest = chars.split_off(width);
+                lines.push(chars.into_iter().collect());
+                chars = rest;
+            }
+            if line_len > 0 {
+                line.push(' ');
+                line_len += 1;
+            }
+            line_len += chars.len();
+            line.extend(chars);
+        }
+        lines.push(line);
+    }
+    lines.join("\n")
+}
+
+/// Format an API error with contextual guidance.
+#[allow(dead_code)]
+pub fn format_api_error(operation: &str, status: Option<u16>, body: Option<&str>) -> String {
+    let mut msg = format!("failed to {operation}");
+
+    if let Some(code) = status {
+        msg.push_str(&format!(" (HTTP {code})"));
+    }
+
+    if let Some(body) = body {
+        if !body.is_empty() {
+            msg.push_str(&format!("\nAPI response: {body}"));
+        }
+    }
+
+    if let Some(code) = status {
+        let hint = match code {
+            500.. => "API server error — try again later",
+            429 => "rate limited — wait and retry",
+            403 => "access denied — check permissions",
+            401 => "authentication failed — check credentials or run 'pup auth login'",
+            404 => "resource not found — verify the ID",
+            400 => "invalid request — check parameters",
+            _ => "",
+        };
+        if !hint.is_empty() {
+            msg.push_str(&format!("\nHint: {hint}"));
+        }
+    }
+
+    msg
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_format_cell_string() {
+        assert_eq!(format_cell(Some(&serde_json::json!("hello"))), "hello");
+    }
+
+    #[test]
+    fn test_format_cell_long_string() {
+        let long = "a".repeat(60);
+        let result = format_cell(Some(&serde_json::json!(long)));
+        assert_eq!(result.len(), 50);
+        assert!(result.ends_with("..."));
+    }
+
+    #[test]
+    fn test_wrap_text_at_fixed_width() {
+        let wrapped = wrap_text("the quick brown fox jumps over the lazy dog", 10);
+        assert_eq!(wrapped, "the quick\nbrown fox\njumps over\nthe lazy\ndog");
+        assert!(wrapped.lines().all(|l| l.chars().count() <= 10));
+    }
+
+    #[test]
+    fn test_wrap_text_breaks_long_words() {
+        assert_eq!(wrap_text("abcdefghij", 4), "abcd\nefgh\nij");
+        assert_eq!(wrap_text("id: 0123456789", 5), "id:\n01234\n56789");
+    }
+
+    #[test]
+    fn test_wrap_text_short_and_multiline() {
+        assert_eq!(wrap_text("short", 10), "short");
+        assert_eq!(wrap_text("one\ntwo", 10), "one\ntwo");
+    }
+
+    #[test]
+    fn test_format_cell_wrapped_keeps_full_string() {
+        let long = "x".repeat(60);
+        let cell = format_cell_wrapped(Some(&serde_json::json!(long)), 25);
+        assert_eq!(cell.replace('\n', ""), long);
+        assert_eq!(cell.lines().count(), 3);
+        // Non-strings keep their compact summaries
+        let obj = serde_json::json!({"a": 1});
+        assert_eq!(format_cell_wrapped(Some(&obj), 25), "{1 fields}");
+    }
+
+    #[test]
+    fn test_highlight_text_wraps_term_in_color() {
+        let out = highlight_text("Connection TIMEOUT after 30s", &["timeout".into()]);
+        assert_eq!(
+            out,
+            format!("Connection {ANSI_HIGHLIGHT}TIMEOUT{ANSI_RESET} after 30s")
+        );
+        assert_eq!(highlight_text("all good", &["timeout".into()]), "all good");
+    }
+
+    #[test]
+    fn test_highlight_text_prefers_longer_term() {
+        let out = highlight_text("timeouts", &["time".into(), "timeouts".into()]);
+        assert_eq!(out, format!("{ANSI_HIGHLIGHT}timeouts{ANSI_RESET}"));
+    }
+
+    #[test]
+    fn test_highlight_column_only_touches_target_column() {
+        let rendered = "\
++---------+----------------+
+| service | message        |
++=========+================+
+| timeout | request timeout|
++---------+----------------+";
+        let out = highlight_column(rendered, 1, &["timeout".into()]);
+        let lines: Vec<&str> = out.lines().collect();
+        assert_eq!(lines[1], "| service | message        |");
+        assert_eq!(
+            lines[3],
+            format!("| timeout | request {ANSI_HIGHLIGHT}timeout{ANSI_RESET}|")
+        );
+    }
+
+    #[test]
+    fn test_color_enabled_modes() {
+        assert!(color_enabled("always").unwrap());
+        assert!(!color_enabled("never").unwrap());
+        assert!(color_enabled("sometimes").is_err());
+    }
+
+    #[test]
+    fn test_render_csv_timeseries_long_format() {
+        let data = serde_json::json!({
+            "data": {
+                "type": "timeseries_response",
+                "attributes": {
+                    "series": [
+                        {"group_tags": ["host:a"], "query_index": 0},
+                        {"group_tags": ["host:b", "env:prod"], "query_index": 0}
+                    ],
+                    "times": [1700000000000i64, 1700000060000i64],
+                    "values": [[1.5, 2.0], [3, null]]
+                }
+            }
+        });
+        assert_eq!(
+            render_csv(&data),
+            "timestamp,series,value\n\
+             1700000000000,host:a,1.5\n\
+             1700000060000,host:a,2.0\n\
+             1700000000000,\"host:b,env:prod\",3\n\
+             1700000060000,\"host:b,env:prod\",\n"
+        );
+    }
+
+    #[test]
+    fn test_render_csv_v1_metrics_query() {
+        let data = serde_json::json!({
+            "res_type": "time_series",
+            "series": [
+                {"scope": "host:a", "tag_set": ["host:a"], "pointlist": [[1700000000000.0, 0.5]]},
+                {"scope": "*", "tag_set": [], "pointlist": [[1700000000000.0, 1.25]]}
+            ]
+        });
+        assert_eq!(
+            render_csv(&data),
+            "timestamp,series,value\n1700000000000,host:a,0.5\n1700000000000,*,1.25\n"
+        );
+    }
+
+    #[test]
+    fn test_timeseries_table_aligns_series_on_union_of_timestamps() {
+        // Two queries whose series report slightly different timestamps
+        let data = serde_json::json!({
+            "res_type": "time_series",
+            "series": [
+                {"scope": "host:a", "tag_set": ["host:a"], "pointlist": [
+                    [1700000000000.0, 0.5], [1700000060000.0, 1.0], [1700000120000.0, 1.5]
+                ]},
+                {"scope": "host:a", "tag_set": ["host:a"], "pointlist": [
+                    [1700000060000.0, 20.0], [1700000120000.0, null], [1700000180000.0, 40.0]
+                ]}
+            ]
+        });
+        let (headers, rows) = timeseries_table(&data).unwrap();
+        assert_eq!(headers, ["timestamp", "host:a", "host:a (2)"]);
+        assert_eq!(
+            rows,
+            [
+                ["1700000000000", "0.5", ""],
+                ["1700000060000", "1", "20"],
+                ["1700000120000", "1.5", ""],
+                ["1700000180000", "", "40"],
+            ]
+        );
+    }
+
+    #[test]
+    fn test_timeseries_table_v2_shared_axis() {
+        let data = serde_json::json!({
+            "data": {
+                "type": "timeseries_response",
+                "attributes": {
+                    "series": [{"query_index": 0}, {"query_index": 1}],
+                    "times": [1700000060000i64, 1700000000000i64],
+                    "values": [[2, 1], [null, 3.25]]
+                }
+            }
+        });
+        let (headers, rows) = timeseries_table(&data).unwrap();
+        assert_eq!(headers, ["timestamp", "query0", "query1"]);
+        assert_eq!(
+            rows,
+            [["1700000000000", "1", "3.25"], ["1700000060000", "2", ""]]
+        );
+        assert!(timeseries_table(&serde_json::json!({"data": []})).is_none());
+    }
+
+    #[test]
+    fn test_timeseries_table_aggregate_buckets() {
+        let data = serde_json::json!({
+            "data": {"buckets": [
+                {"by": {"status": "error"}, "computes": {"c0": [
+                    {"time": "2024-01-01T00:00:00.000Z", "value": 4},
+                    {"time": "2024-01-01T00:05:00.000Z", "value": 9}
+                ]}},
+                {"by": {"status": "warn"}, "computes": {"c0": [
+                    {"time": "2024-01-01T00:05:00.000Z", "value": 2.5}
+                ]}}
+            ]},
+            "meta": {"status": "done"}
+        });
+        let (headers, rows) = timeseries_table(&data).unwrap();
+        assert_eq!(headers, ["timestamp", "status:error", "status:warn"]);
+        assert_eq!(
+            rows,
+            [
+                ["2024-01-01T00:00:00.000Z", "4", ""],
+                ["2024-01-01T00:05:00.000Z", "9", "2.5"],
+            ]
+        );
+
+        let ungrouped = serde_json::json!({"data": {"buckets": [{"by": {}, "computes": {
+            "c0": [{"time": "2024-01-01T00:00:00.000Z", "value": 1}],
+            "c1": [{"time": "2024-01-01T00:00:00.000Z", "value": 250}]
+        }}]}});
+        let (headers, _) = timeseries_table(&ungrouped).unwrap();
+        assert_eq!(headers, ["timestamp", "c0", "c1"]);
+
+        let totals = serde_json::json!({"data": {"buckets": [
+            {"by": {"status": "info"}, "computes": {"c0": 42}}
+        ]}});
+        assert!(timeseries_table(&totals).is_none());
+        assert!(timeseries_table(&serde_json::json!({"data": {"buckets": []}})).is_none());
+    }
+
+    #[test]
+    fn test_aggregate_table_count_by_status() {
+        let data = serde_json::json!({
+            "data": {"buckets": [
+                {"by": {"status": "info"}, "computes": {"c0": 1200}},
+                {"by": {"status": "error"}, "computes": {"c0": 4521}},
+                {"by": {"status": "warn"}, "computes": {"c0": 37.5}}
+            ]},
+            "meta": {"status": "done"}
+        });
+        let (title, headers, rows) = aggregate_table(&data).unwrap();
+        assert_eq!(title, "c0 by status");
+        assert_eq!(headers, vec!["status", "c0"]);
+        assert_eq!(
+            rows,
+            vec![
+                vec!["error", "4521"],
+                vec!["info", "1200"],
+                vec!["warn", "37.5"],
+            ]
+        );
+    }
+
+    #[test]
+    fn test_aggregate_table_falls_back_for_other_shapes() {
+        let ungrouped =
+            serde_json::json!({"data": {"buckets": [{"by": {}, "computes": {"c0": 42}}]}});
+        assert!(aggregate_table(&ungrouped).is_none());
+        let two_facets = serde_json::json!({"data": {"buckets": [
+            {"by": {"status": "info", "service": "web"}, "computes": {"c0": 1}}
+        ]}});
+        assert!(aggregate_table(&two_facets).is_none());
+        let timeseries = serde_json::json!({"data": {"buckets": [
+            {"by": {"status": "info"}, "computes": {"c0": [{"time": "t", "value": 1}]}}
+        ]}});
+        assert!(aggregate_table(&timeseries).is_none());
+        assert!(aggregate_table(&serde_json::json!({"data": {"buckets": []}})).is_none());
+    }
+
+    #[test]
+    fn test_render_csv_generic_rows() {
+        let data = serde_json::json!({"data": [
+            {"id": 1, "name": "a, b", "tags": ["x"]},
+            {"id": 2, "note": "say \"hi\""}
+        ]});
+        assert_eq!(
+            render_csv(&data),
+            "id,name,tags,note\n1,\"a, b\",\"[\"\"x\"\"]\",\n2,,,\"say \"\"hi\"\"\"\n"
+        );
+    }
+
+    #[test]
+    fn test_summarize_incidents() {
+        let data = serde_json::json!({"data": [
+            {"type": "incidents", "id": "1", "attributes": {"state": "active", "severity": "SEV-1"}},
+            {"type": "incidents", "id": "2", "attributes": {"state": "active", "severity": "SEV-3"}},
+            {"type": "incidents", "id": "3", "attributes": {"state": "resolved", "severity": "SEV-1"}}
+        ]});
+        assert_eq!(
+            summarize(&data),
+            serde_json::json!({
+                "type": "incidents",
+                "total": 3,
+                "by_state": {"active": 2, "resolved": 1},
+                "by_severity": {"SEV-1": 2, "SEV-3": 1}
+            })
+        );
+    }
+
+    #[test]
+    fn test_summarize_monitors() {
+        let data = serde_json::json!([
+            {"id": 1, "type": "metric alert", "overall_state": "Alert"},
+            {"id": 2, "type": "log alert", "overall_state": "OK"},
+            {"id": 3, "type": "metric alert", "overall_state": "OK"},
+            {"id": 4, "type": "metric alert"}
+        ]);
+        assert_eq!(
+            summarize(&data),
+            serde_json::json!({
+                "type": "monitors",
+                "total": 4,
+                "by_status": {"Alert": 1, "OK": 2, "unknown": 1}
+            })
+        );
+    }
+
+    #[test]
+    fn test_summarize_logs_and_fallbacks() {
+        let logs = serde_json::json!({"data": [
+            {"type": "log", "attributes": {"timestamp": "2024-01-01T00:05:00Z"}},
+            {"type": "log", "attributes": {"timestamp": "2024-01-01T00:01:00Z"}}
+        ]});
+        assert_eq!(
+            summarize(&logs),
+            serde_json::json!({
+                "type": "log",
+                "total": 2,
+                "oldest": "2024-01-01T00:01:00Z",
+                "newest": "2024-01-01T00:05:00Z"
+            })
+        );
+        let metrics = serde_json::json!({"from": "1", "metrics": ["a", "b"]});
+        assert_eq!(
+            summarize(&metrics),
+            serde_json::json!({"type": "metrics", "total": 2})
+        );
+        let users = serde_json::json!({"data": [{"type": "users", "attributes": {}}]});
+        assert_eq!(
+            summarize(&users),
+            serde_json::json!({"type": "users", "total": 1})
+        );
+        assert_eq!(
+            summarize(&serde_json::json!(["x", "y"])),
+            serde_json::json!({"total": 2})
+        );
+    }
+
+    #[test]
+    fn test_sync_writer_keeps_blocks_whole() {
+        let writer = std::sync::Arc::new(SyncWriter::new(Vec::<u8>::new()));
+        let handles: Vec<_> = (0..8)
+            .map(|n| {
+                let writer = writer.clone();
+                std::thread::spawn(move || {
+                    let block: String = (0..50).map(|i| format!("org{n} line{i}\n")).collect();
+                    for _ in 0..10 {
+                        writer.write_block(&block).unwrap();
+                    }
+                })
+            })
+            .collect();
+        for h in handles {
+            h.join().unwrap();
+        }
+
+        let writer = std::sync::Arc::into_inner(writer).unwrap();
+        let out = String::from_utf8(writer.into_inner()).unwrap();
+        let lines: Vec<&str> = out.lines().collect();
+        assert_eq!(lines.len(), 8 * 10 * 50);
+        for block in lines.chunks(50) {
+            let org = block[0].split(' ').next().unwrap();
+            for (i, line) in block.iter().enumerate() {
+                assert_eq!(*line, format!("{org} line{i}"));
+            }
+        }
+    }
+
+    #[test]
+    fn test_format_table_cell_booleans() {
+        let icons = TableOptions::default();
+        let ascii = TableOptions {
+            ascii: true,
+            ..Default::default()
+        };
+        let t = serde_json::json!(true);
+        let f = serde_json::json!(false);
+        assert_eq!(format_table_cell(Some(&t), None, &icons), "✓");
+        assert_eq!(format_table_cell(Some(&f), Some(20), &icons), "✗");
+        assert_eq!(format_table_cell(Some(&t), None, &ascii), "Y");
+        assert_eq!(format_table_cell(Some(&f), None, &ascii), "N");
+        let s = serde_json::json!("true");
+        assert_eq!(format_table_cell(Some(&s), None, &icons), "true");
+    }
+
+    fn incident_with_included() -> serde_json::Value {
+        serde_json::json!({
+            "data": [{
+                "id": "inc-1",
+                "type": "incidents",
+                "attributes": {"title": "API down"},
+                "relationships": {
+                    "commander_user": {"data": {"type": "users", "id": "u-1"}},
+                    "teams": {"data": [
+                        {"type": "teams", "id": "t-1"},
+                        {"type": "teams", "id": "t-2"}
+                    ]},
+                    "created_by_user": {"data": {"type": "users", "id": "u-missing"}},
+                    "attachments": {"data": []}
+                }
+            }],
+            "included": [
+                {"type": "users", "id": "u-1", "attributes": {"name": "Jane Doe", "email": "jane@example.com"}},
+                {"type": "teams", "id": "t-1", "attributes": {"name": "Platform", "handle": "platform"}},
+                {"type": "teams", "id": "t-2", "attributes": {"handle": "sre"}}
+            ]
+        })
+    }
+
+    #[test]
+    fn test_resolve_relationships() {
+        let resolved = resolve_relationships(&incident_with_included());
+        let rels = &resolved["data"][0]["relationships"];
+        assert_eq!(rels["commander_user"], "Jane Doe");
+        assert_eq!(rels["teams"], serde_json::json!(["Platform", "sre"]));
+        // No included match: the reference is kept as-is.
+        assert_eq!(
+            rels["created_by_user"],
+            serde_json::json!({"data": {"type": "users", "id": "u-missing"}})
+        );
+        assert_eq!(rels["attachments"], serde_json::json!({"data": []}));
+
+        let row = flatten_row(&resolved["data"][0]);
+        assert_eq!(row["relationships.commander_user"], "Jane Doe");
+    }
+
+    #[test]
+    fn test_resolve_relationships_without_included() {
+        let mut value = incident_with_included();
+        value.as_object_mut().unwrap().remove("included");
+        assert_eq!(resolve_relationships(&value), value);
+
+        // A single resource under `data` is resolved too.
+        let single = serde_json::json!({
+            "data": {"id": "case-1", "relationships": {
+                "assignee": {"data": {"type": "users", "id": "u-1"}}
+            }},
+            "included": [{"type": "users", "id": "u-1", "attributes": {"name": "Jane Doe"}}]
+        });
+        let resolved = resolve_relationships(&single);
+        assert_eq!(resolved["data"]["relationships"]["assignee"], "Jane Doe");
+    }
+
+    #[test]
+    fn test_print_table_resolve_relationships() {
+        let opts = TableOptions {
+            resolve_relationships: true,
+            ..Default::default()
+        };
+        assert!(print_table_with(&incident_with_included(), &opts).is_ok());
+    }
+
+    #[test]
+    fn test_truncation_footer_when_truncated() {
+        let data = serde_json::json!({
+            "data": [{"id": "1"}, {"id": "2"}],
+            "meta": {"page": {"total_count": 245}}
+        });
+        assert_eq!(
+            truncation_footer(&data).unwrap(),
+            "Showing 2 of 245 (raise --limit or narrow the query to see more)"
+        );
+
+        let cursor = serde_json::json!({
+            "data": [{"id": "1"}],
+            "meta": {"page": {"after": "eyJhZnRlciI6IjEifQ"}}
+        });
+        assert!(truncation_footer(&cursor)
+            .unwrap()
+            .starts_with("Showing 1; more results are available"));
+    }
+
+    #[test]
+    fn test_truncation_footer_absent_when_complete() {
+        let complete = serde_json::json!({
+            "data": [{"id": "1"}, {"id": "2"}],
+            "meta": {"page": {"total_count": 2}}
+        });
+        assert!(truncation_footer(&complete).is_none());
+        let last_page = serde_json::json!({
+            "data": [{"id": "1"}],
+            "meta": {"pagination": {"next_offset": null}},
+            "links": {"next": null}
+        });
+        assert!(truncation_footer(&last_page).is_none());
+        assert!(truncation_footer(&serde_json::json!({"data": []})).is_none());
+        assert!(truncation_footer(&serde_json::json!([{"id": "1"}])).is_none());
+    }
+
+    #[test]
+    fn test_humanize_value() {
+        let now = 1_700_000_000;
+        let data = serde_json::json!({"data": [{
+            "id": 1,
+            "created_at": now - 3 * 3600,
+            "updated_at": (now - 90) * 1000,
+            "attributes": {
+                "resolved_at": "2023-11-14T22:13:20Z",
+                "time_to_resolve": 135,
+                "detection_duration": 90061.4,
+                "created": now - 60,
+                "title": "started_at is not a field name"
+            },
+            "deleted_at": null
+        }]});
+        let out = humanize_value(data, now);
+        let row = &out["data"][0];
+        assert_eq!(row["created_at"], "3h ago");
+        assert_eq!(row["updated_at"], "1m ago");
+        assert_eq!(row["attributes"]["resolved_at"], "0s ago");
+        assert_eq!(row["attributes"]["time_to_resolve"], "2m15s");
+        assert_eq!(row["attributes"]["detection_duration"], "1d1h");
+        // Other fields keep their raw values.
+        assert_eq!(row["id"], 1);
+        assert_eq!(row["attributes"]["created"], now - 60);
+        assert_eq!(row["deleted_at"], serde_json::Value::Null);
+    }
+
+    #[test]
+    fn test_parse_rfc3339_secs() {
+        assert_eq!(parse_rfc3339_secs("1970-01-01T00:00:00Z"), Some(0));
+        assert_eq!(
+            parse_rfc3339_secs("2023-11-14T22:13:20.123Z"),
+            Some(1_700_000_000)
+        );
+        assert_eq!(
+            parse_rfc3339_secs("2023-11-15T00:13:20+02:00"),
+            Some(1_700_000_000)
+        );
+        assert_eq!(
+            parse_rfc3339_secs("2024-02-29T00:00:00Z"),
+            Some(1_709_164_800)
+        );
+        assert_eq!(parse_rfc3339_secs("yesterday"), None);
+    }
+
+    #[test]
+    fn test_human_duration_and_relative_time() {
+        assert_eq!(human_duration(0), "0s");
+        assert_eq!(human_duration(135), "2m15s");
+        assert_eq!(human_duration(3600), "1h");
+        assert_eq!(human_duration(86_400 + 300), "1d");
+        assert_eq!(relative_time(100, 145), "45s ago");
+        assert_eq!(relative_time(100 + 2 * 86_400, 100), "in 2d");
+    }
+
+    #[test]
+    fn test_format_table_cell_inline_array_limit() {
+        let opts = |limit| TableOptions {
+            inline_array_limit: limit,
+            ..Default::default()
+        };
+        let three = serde_json::json!(["a", "b", "c"]);
+        let five = serde_json::json!([1, 2, 3, 4, 5]);
+        let ten = serde_json::json!([1, 2, 3, 4, 5, 6, 7, 8, 9, 10]);
+
+        // 0 always summarizes.
+        assert_eq!(format_table_cell(Some(&three), None, &opts(0)), "[3 items]");
+        assert_eq!(
+            format_table_cell(Some(&serde_json::json!(["x"])), None, &opts(0)),
+            "[1 item]"
+        );
+        assert_eq!(
+            format_table_cell(Some(&serde_json::json!([])), None, &opts(0)),
+            "[]"
+        );
+
+        // The default of 3 inlines up to three items.
+        assert_eq!(TableOptions::default().inline_array_limit, 3);
+        assert_eq!(format_table_cell(Some(&three), None, &opts(3)), "[a, b, c]");
+        assert_eq!(format_table_cell(Some(&five), None, &opts(3)), "[5 items]");
+
+        // A large limit inlines everything.
+        assert_eq!(
+            format_table_cell(Some(&ten), None, &opts(10)),
+            "[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]"
+        );
+        assert_eq!(
+            format_table_cell(Some(&five), Some(20), &opts(10)),
+            "[1, 2, 3, 4, 5]"
+        );
+    }
+
+    #[test]
+    fn test_empty_placeholder_in_table_cells() {
+        let opts = TableOptions {
+            empty_placeholder: "-".into(),
+            ..Default::default()
+        };
+        assert_eq!(format_table_cell(None, None, &opts), "-");
+        assert_eq!(
+            format_table_cell(Some(&serde_json::Value::Null), Some(20), &opts),
+            "-"
+        );
+        assert_eq!(
+            format_table_cell(Some(&serde_json::json!("")), None, &opts),
+            ""
+        );
+        assert_eq!(
+            format_table_cell(Some(&serde_json::json!("web")), None, &opts),
+            "web"
+        );
+        assert_eq!(
+            format_table_cell(Some(&serde_json::json!(0)), None, &opts),
+            "0"
+        );
+        assert_eq!(
+            format_table_cell(Some(&serde_json::json!(false)), None, &opts),
+            "✗"
+        );
+        // No placeholder by default.
+        assert_eq!(format_table_cell(None, None, &TableOptions::default()), "");
+    }
+
+    #[test]
+    fn test_empty_placeholder_in_csv() {
+        let data = serde_json::json!([
+            {"name": "a", "owner": null, "team": "sre"},
+            {"name": "b", "team": "web"}
+        ]);
+        assert_eq!(
+            render_csv_with(&data, "N/A"),
+            "name,owner,team\na,N/A,sre\nb,N/A,web\n"
+        );
+        assert_eq!(render_csv(&data), "name,owner,team\na,,sre\nb,,web\n");
+    }
+
+    #[test]
+    fn test_booleans_literal_outside_tables() {
+        let data = serde_json::json!([{"name": "a", "enabled": true, "muted": false}]);
+        assert_eq!(render_csv(&data), "name,enabled,muted\na,true,false\n");
+        let json = serde_json::to_string(&sort_json_value(data)).unwrap();
+        assert_eq!(json, r#"[{"enabled":true,"muted":false,"name":"a"}]"#);
+    }
+
+    #[test]
+    fn test_table_columns_deterministic() {
+        let data = serde_json::json!([
+            {"zeta": 1, "name": "a", "alpha": 2, "id": 1},
+            {"id": 2, "beta": 3, "alpha": 4, "status": "ok"}
+        ]);
+        let rows = extract_rows(&data);
+        let first = table_columns(&rows);
+        assert_eq!(first, ["id", "name", "status", "zeta", "alpha", "beta"]);
+        for _ in 0..20 {
+            assert_eq!(table_columns(&rows), first);
         }
+        assert_eq!(
+            collect_columns(&rows),
+            ["zeta", "name", "alpha", "id", "beta", "status"]
+        );
+        assert_eq!(
+            render_csv(&data),
+            render_csv(&data.clone()),
+            "CSV column order must not change between runs"
+        );
     }
 
-    let mut table = comfy_table::Table::new();
-    table.set_header(&final_headers);
+    #[test]
+    fn test_table_columns_heuristic() {
+        let mut attributes = serde_json::json!({
+            "title": "API latency",
+            "customer_impacted": false,
+            "fields": {"teams": {"type": "autocomplete", "value": ["sre"]}},
+            "notification_handles": [{"handle": "@sre"}],
+            "severity": "SEV-2",
+            "state": "active",
+            "created": "2024-01-01T00:00:00Z",
+            "modified": "2024-01-02T00:00:00Z"
+        });
+        for i in 0..20 {
+            attributes[format!("extra_{i:02}")] = i.into();
+        }
+        let data = serde_json::json!({"data": [{
+            "id": "inc-1",
+            "type": "incidents",
+            "attributes": attributes,
+            "relationships": {"commander_user": {"data": {"type": "users", "id": "u-1"}}}
+        }]});
+        let rows: Vec<serde_json::Value> =
+            extract_rows(&data).into_iter().map(flatten_row).collect();
+        let columns = table_columns(&rows.iter().collect::<Vec<_>>());
+
+        assert_eq!(columns.len(), MAX_TABLE_COLUMNS);
+        assert_eq!(
+            columns[..7],
+            [
+                "id",
+                "attributes.title",
+                "type",
+                "attributes.state",
+                "attributes.severity",
+                "attributes.created",
+                "attributes.modified",
+            ]
+        );
+        // Scalars fill the remaining slots ahead of nested values.
+        assert_eq!(columns[7], "attributes.customer_impacted");
+        assert_eq!(columns[8], "attributes.extra_00");
+        assert!(!columns.iter().any(|c| c.contains("notification_handles")));
+        assert!(!columns.iter().any(|c| c.starts_with("relationships.")));
+    }
 
-    for row in &rows {
-        let cells: Vec<String> = final_headers
-            .iter()
-            .map(|h| {
-                if let serde_json::Value::Object(map) = row {
-                    format_cell(map.get(h.as_str()))
-                } else {
-                    String::new()
-                }
-            })
-            .collect();
-        table.add_row(cells);
+    #[test]
+    fn test_table_columns_nested_fill_remaining_room() {
+        let data = serde_json::json!([{"name": "web", "tags": ["env:prod"], "host": "h1"}]);
+        assert_eq!(
+            table_columns(&extract_rows(&data)),
+            ["name", "host", "tags"]
+        );
     }
 
-    println!("{table}");
-    Ok(())
-}
+    #[test]
+    fn test_table_columns_capped() {
+        let row: serde_json::Map<String, serde_json::Value> =
+            (0..20).map(|i| (format!("c{i:02}"), i.into())).collect();
+        let data = serde_json::Value::Array(vec![serde_json::Value::Object(row)]);
+        let columns = table_columns(&extract_rows(&data));
+        assert_eq!(columns.len(), MAX_TABLE_COLUMNS);
+        assert_eq!(columns[0], "c00");
+        assert_eq!(columns[11], "c11");
+    }
 
-/// Extract displayable rows from a JSON value.
-/// Handles: arrays, objects with "data" field, single objects.
-fn extract_rows(value: &serde_json::Value) -> Vec<&serde_json::Value> {
-    match value {
-        serde_json::Value::Array(arr) => arr.iter().collect(),
-        serde_json::Value::Object(map) => {
-            // API responses often wrap data: { "data": [...], "meta": ... }
-            if let Some(data) = map.get("data") {
-                return extract_rows(data);
-            }
-            vec![value]
-        }
-        _ => vec![],
+    #[test]
+    fn test_column_budget() {
+        assert_eq!(column_budget(120, 4), 26);
+        assert_eq!(column_budget(40, 10), MIN_WRAP_WIDTH);
     }
-}
 
-/// Compact label for a single array element, used when previewing arrays in table cells.
-/// For objects, tries id/name/title/type in order; falls back to format_cell for primitives.
-fn format_array_item(value: &serde_json::Value) -> String {
-    if let serde_json::Value::Object(map) = value {
-        for key in &["name", "title", "id", "type"] {
-            if let Some(serde_json::Value::String(s)) = map.get(*key) {
-                return if s.len() > 16 {
-                    format!("{}...", &s[..13])
-                } else {
-                    s.clone()
-                };
-            }
-        }
-        return format!("{{{} fields}}", map.len());
+    #[test]
+    fn test_columns_at_min_width() {
+        assert_eq!(columns_at_min_width(80), 6);
+        assert_eq!(columns_at_min_width(5), 1);
     }
-    format_cell(Some(value))
-}
 
-fn format_cell(value: Option<&serde_json::Value>) -> String {
-    match value {
-        None | Some(serde_json::Value::Null) => String::new(),
-        Some(serde_json::Value::String(s)) => {
-            if s.len() > 50 {
-                format!("{}...", &s[..47])
-            } else {
-                s.clone()
-            }
-        }
-        Some(serde_json::Value::Number(n)) => n.to_string(),
-        Some(serde_json::Value::Bool(b)) => b.to_string(),
-        Some(serde_json::Value::Array(arr)) => {
-            if arr.is_empty() {
-                return "[]".to_string();
-            }
-            let mut parts: Vec<String> = arr.iter().take(4).map(format_array_item).collect();
-            if arr.len() > 4 {
-                parts.push(format!("+{} more", arr.len() - 4));
-            }
-            let result = format!("[{}]", parts.join(", "));
-            if result.len() > 50 {
-                format!("{}...", &result[..47])
-            } else {
-                result
-            }
-        }
-        Some(serde_json::Value::Object(map)) => format!("{{{} fields}}", map.len()),
+    #[test]
+    fn test_fit_column_widths_keeps_natural_when_it_fits() {
+        // 4 + 6 content, 3 * 2 + 1 border.
+        assert_eq!(fit_column_widths(&[4, 6], 17), vec![4, 6]);
     }
-}
 
-/// Format an API error with contextual guidance.
-#[allow(dead_code)]
-pub fn format_api_error(operation: &str, status: Option<u16>, body: Option<&str>) -> String {
-    let mut msg = format!("failed to {operation}");
+    #[test]
+    fn test_fit_column_widths_shrinks_proportionally() {
+        // 40 wide leaves 33 for content: 8 + 8 minimums, 17 spare split 30:10.
+        let widths = fit_column_widths(&[38, 18], 40);
+        assert_eq!(widths, vec![20, 12]);
+        assert!(widths.iter().map(|w| w + 3).sum::<usize>() < 40);
+    }
 
-    if let Some(code) = status {
-        msg.push_str(&format!(" (HTTP {code})"));
+    #[test]
+    fn test_fit_column_widths_drops_trailing_columns() {
+        // Only two columns fit at the minimum width; they share the rest.
+        assert_eq!(fit_column_widths(&[30, 30, 30], 25), vec![9, 9]);
+        // Narrow columns keep their width and leave more room for others.
+        assert_eq!(fit_column_widths(&[2, 30, 30, 30], 25), vec![2, 16]);
+        // The first column is always kept, however narrow the terminal.
+        assert_eq!(fit_column_widths(&[30, 30], 5), vec![8]);
     }
 
-    if let Some(body) = body {
-        if !body.is_empty() {
-            msg.push_str(&format!("\nAPI response: {body}"));
+    #[test]
+    fn test_fit_to_width_narrow_table() {
+        let mut headers: Vec<String> = ["id", "name", "status", "description", "owner"]
+            .iter()
+            .map(|h| h.to_string())
+            .collect();
+        let mut grid = vec![vec![
+            "abc-123".to_string(),
+            "checkout latency is above threshold".to_string(),
+            "ok".to_string(),
+            "p99 latency of the checkout service".to_string(),
+            "team-payments".to_string(),
+        ]];
+        fit_to_width(&mut headers, &mut grid, 40);
+        assert_eq!(headers, vec!["id", "name", "status"]);
+        assert_eq!(grid[0].len(), 3);
+        assert!(grid[0][1].ends_with("..."), "{}", grid[0][1]);
+
+        let mut table = comfy_table::Table::new();
+        table.set_header(&headers);
+        table.add_row(grid[0].clone());
+        for line in table.to_string().lines() {
+            assert!(line.chars().count() <= 40, "{line}");
         }
+        assert_eq!(
+            dropped_columns_note(5 - headers.len()),
+            "+2 more columns (use --output=json)"
+        );
     }
 
-    if let Some(code) = status {
-        let hint = match code {
-            500.. => "API server error — try again later",
-            429 => "rate limited — wait and retry",
-            403 => "access denied — check permissions",
-            401 => "authentication failed — check credentials or run 'pup auth login'",
-            404 => "resource not found — verify the ID",
-            400 => "invalid request — check parameters",
-            _ => "",
-        };
-        if !hint.is_empty() {
-            msg.push_str(&format!("\nHint: {hint}"));
-        }
+    #[test]
+    fn test_clip_text() {
+        assert_eq!(clip_text("short", 8), "short");
+        assert_eq!(clip_text("much too long", 8), "much ...");
+        assert_eq!(clip_text("abcdef", 2), "ab");
+        assert_eq!(clip_text("one line\nand another", 6), "one...\nand...");
     }
 
-    msg
-}
+    #[test]
+    fn test_key_value_sections_for_nested_resource() {
+        let monitor = serde_json::json!({
+            "id": 42,
+            "name": "CPU high",
+            "tags": ["env:prod"],
+            "options": {
+                "notify_no_data": false,
+                "thresholds": {"critical": 90, "warning": 80}
+            },
+            "creator": {"email": "jane@example.com"}
+        });
+        let sections = key_value_sections(monitor.as_object().unwrap());
+        let names: Vec<Option<&str>> = sections.iter().map(|(t, _)| t.as_deref()).collect();
+        assert_eq!(names, vec![None, Some("Options"), Some("Creator")]);
+        let top: Vec<&str> = sections[0].1.iter().map(|(k, _)| k.as_str()).collect();
+        assert_eq!(top, vec!["id", "name", "tags"]);
+        let options: Vec<&str> = sections[1].1.iter().map(|(k, _)| k.as_str()).collect();
+        assert_eq!(
+            options,
+            vec![
+                "notify_no_data",
+                "thresholds.critical",
+                "thresholds.warning"
+            ]
+        );
 
-#[cfg(test)]
-mod tests {
-    use super::*;
+        let rendered = render_key_values(monitor.as_object().unwrap(), &TableOptions::default());
+        assert!(rendered.contains("\nOptions\n"), "{rendered}");
+        assert!(rendered.contains("\nCreator\n"), "{rendered}");
+        assert!(rendered.contains("thresholds.critical"), "{rendered}");
+    }
 
     #[test]
-    fn test_format_cell_string() {
-        assert_eq!(format_cell(Some(&serde_json::json!("hello"))), "hello");
+    fn test_key_value_sections_hoist_jsonapi_attributes() {
+        let incident = serde_json::json!({"data": {
+            "id": "abc",
+            "type": "incidents",
+            "attributes": {
+                "title": "Checkout errors",
+                "customer_impact_scope": null,
+                "fields": {"severity": {"type": "dropdown", "value": "SEV-2"}}
+            }
+        }});
+        let resource = single_resource(&incident).unwrap();
+        let sections = key_value_sections(resource);
+        let top: Vec<&str> = sections[0].1.iter().map(|(k, _)| k.as_str()).collect();
+        assert_eq!(top, vec!["id", "type", "title", "customer_impact_scope"]);
+        assert_eq!(sections[1].0.as_deref(), Some("Fields"));
+        let fields: Vec<&str> = sections[1].1.iter().map(|(k, _)| k.as_str()).collect();
+        assert_eq!(fields, vec!["severity.type", "severity.value"]);
     }
 
     #[test]
-    fn test_format_cell_long_string() {
-        let long = "a".repeat(60);
-        let result = format_cell(Some(&serde_json::json!(long)));
-        assert_eq!(result.len(), 50);
-        assert!(result.ends_with("..."));
+    fn test_single_resource() {
+        assert!(single_resource(&serde_json::json!({"data": []})).is_none());
+        assert!(single_resource(&serde_json::json!([{"id": 1}])).is_none());
+        assert!(single_resource(&serde_json::json!({"id": 1})).is_some());
+        assert_eq!(section_title("customer_impact"), "Customer impact");
+    }
+
+    #[test]
+    fn test_print_table_with_width() {
+        let data = serde_json::json!([
+            {"id": "1", "name": "a fairly long monitor name", "status": "OK", "query": "avg(last_5m):avg:system.cpu.user{*} > 90"}
+        ]);
+        let opts = TableOptions {
+            width: Some(30),
+            ..Default::default()
+        };
+        assert!(print_table_with(&data, &opts).is_ok());
     }
 
     #[test]
@@ -711,11 +3447,131 @@ mod tests {
             output_format: OutputFormat::Json,
             auto_approve: false,
             agent_mode: false,
+            envelope: None,
+            table: Default::default(),
+            template: None,
+            summary: false,
+            profile: None,
         };
         let data = serde_json::json!({"hello": "world"});
         assert!(output(&cfg, &data).is_ok());
     }
 
+    fn sample_request_info() -> RequestInfo {
+        RequestInfo {
+            command: "logs search".into(),
+            args: vec![
+                "logs".into(),
+                "search".into(),
+                "--query=status:error".into(),
+            ],
+            from: Some("2024-01-01T00:00:00+00:00".into()),
+            to: Some("2024-01-01T01:00:00+00:00".into()),
+            timestamp: "2024-01-01T01:00:05+00:00".into(),
+            profile: None,
+        }
+    }
+
+    #[test]
+    fn test_envelope_value_contains_request_and_result() {
+        let data = serde_json::json!({"data": [{"id": "abc"}]});
+        let wrapped = envelope_value(&data, &sample_request_info()).unwrap();
+        assert_eq!(wrapped["request"]["command"], "logs search");
+        assert_eq!(wrapped["request"]["from"], "2024-01-01T00:00:00+00:00");
+        assert_eq!(wrapped["request"]["to"], "2024-01-01T01:00:00+00:00");
+        assert_eq!(wrapped["request"]["timestamp"], "2024-01-01T01:00:05+00:00");
+        assert_eq!(wrapped["request"]["args"][2], "--query=status:error");
+        assert_eq!(wrapped["result"], data);
+    }
+
+    #[test]
+    fn test_envelope_value_omits_missing_window() {
+        let mut info = sample_request_info();
+        info.from = None;
+        info.to = None;
+        let wrapped = envelope_value(&serde_json::json!([]), &info).unwrap();
+        let request = wrapped["request"].as_object().unwrap();
+        assert!(!request.contains_key("from"));
+        assert!(!request.contains_key("to"));
+    }
+
+    #[test]
+    fn test_org_block_success_and_error() {
+        let mut info = sample_request_info();
+        info.profile = Some("prod".into());
+
+        let one = org_block(&info, Ok(vec![serde_json::json!({"id": 1})])).unwrap();
+        assert_eq!(one["request"]["profile"], "prod");
+        assert_eq!(one["result"], serde_json::json!({"id": 1}));
+
+        let many = org_block(&info, Ok(vec![serde_json::json!(1), serde_json::json!(2)])).unwrap();
+        assert_eq!(many["result"], serde_json::json!([1, 2]));
+
+        let failed = org_block(&info, Err("boom".into())).unwrap();
+        assert_eq!(failed["error"], "boom");
+        assert!(failed.get("result").is_none());
+    }
+
+    #[test]
+    fn test_output_is_captured() {
+        let cfg = crate::config::Config {
+            api_key: None,
+            app_key: None,
+            access_token: None,
+            site: "datadoghq.com".into(),
+            output_format: OutputFormat::Json,
+            auto_approve: false,
+            agent_mode: false,
+            envelope: None,
+            table: Default::default(),
+            template: None,
+            summary: false,
+            profile: None,
+        };
+        let marker = serde_json::json!({"captured": "test_output_is_captured"});
+        begin_capture();
+        output(&cfg, &marker).unwrap();
+        // A nested capture (batch under --orgs) collects separately
+        begin_capture();
+        print_block("plain text\n").unwrap();
+        assert_eq!(end_capture(), vec![serde_json::json!("plain text")]);
+        let captured = end_capture();
+        assert_eq!(captured, vec![marker]);
+        assert!(!capturing());
+        assert!(end_capture().is_empty());
+    }
+
+    #[test]
+    fn test_output_with_envelope() {
+        let cfg = crate::config::Config {
+            api_key: None,
+            app_key: None,
+            access_token: None,
+            site: "datadoghq.com".into(),
+            output_format: OutputFormat::Json,
+            auto_approve: false,
+            agent_mode: false,
+            envelope: Some(sample_request_info()),
+            table: Default::default(),
+            template: None,
+            summary: false,
+            profile: None,
+        };
+        assert!(output(&cfg, &serde_json::json!({"hello": "world"})).is_ok());
+    }
+
+    #[test]
+    fn test_print_table_with_wrap() {
+        let data = serde_json::json!([
+            {"id": 1, "message": "a very long log message that would normally be cut off with an ellipsis"}
+        ]);
+        let opts = TableOptions {
+            wrap: true,
+            ..Default::default()
+        };
+        assert!(print_table_with(&data, &opts).is_ok());
+    }
+
     #[test]
     fn test_print_table_with_priority_fields() {
         let data = serde_json::json!([
@@ -733,4 +3589,224 @@ mod tests {
         let data = serde_json::json!([obj]);
         assert!(print_table(&data).is_ok());
     }
+
+    #[test]
+    fn test_reindent_json_matches_serde_layout() {
+        let raw = r#"{"data":[{"id":"a","attributes":{"tags":[],"meta":{},"ok":true,"n":null}}],"total":2}"#;
+        let value: serde_json::Value = serde_json::from_str(raw).unwrap();
+        assert_eq!(
+            reindent_json(raw),
+            serde_json::to_string_pretty(&value).unwrap()
+        );
+    }
+
+    #[test]
+    fn test_reindent_json_preserves_large_integers() {
+        let raw = r#"{ "trace_id" : 123456789012345678901234567890, "ratio": 1.50 }"#;
+        let lossy = serde_json::to_string(&RawJson::parse(raw.into()).unwrap().value).unwrap();
+        assert!(lossy.contains("e29"), "expected float round trip: {lossy}");
+
+        let out = reindent_json(raw);
+        assert_eq!(
+            out,
+            "{\n  \"trace_id\": 123456789012345678901234567890,\n  \"ratio\": 1.50\n}"
+        );
+    }
+
+    #[test]
+    fn test_reindent_json_leaves_strings_alone() {
+        let raw = r#"["a, {b}: [c]", "quote \" and \\ ", ""]"#;
+        let out = reindent_json(raw);
+        assert_eq!(
+            out,
+            "[\n  \"a, {b}: [c]\",\n  \"quote \\\" and \\\\ \",\n  \"\"\n]"
+        );
+    }
+
+    #[test]
+    fn test_raw_json_parse_empty_body() {
+        let raw = RawJson::parse(String::new()).unwrap();
+        assert_eq!(raw.value, serde_json::json!({}));
+        assert_eq!(raw.text, "{}");
+        assert!(RawJson::parse("not json".into()).is_err());
+    }
+
+    /// Checks that every opened element is closed in order, which is enough to
+    /// catch broken nesting in the JUnit writer.
+    fn assert_balanced_xml(xml: &str) {
+        let mut open: Vec<String> = Vec::new();
+        for tag in xml.split('<').skip(1).map(|t| &t[..t.find('>').unwrap()]) {
+            if tag.starts_with('?') || tag.ends_with('/') {
+                continue;
+            }
+            if let Some(name) = tag.strip_prefix('/') {
+                assert_eq!(open.pop().as_deref(), Some(name), "unbalanced </{name}>");
+            } else {
+                open.push(tag.split_whitespace().next().unwrap().to_string());
+            }
+        }
+        assert!(open.is_empty(), "unclosed elements: {open:?}");
+    }
+
+    #[test]
+    fn test_render_junit_synthetics_results() {
+        let data = serde_json::json!({
+            "last_timestamp_fetched": 1700000000000i64,
+            "results": [
+                {"result_id": "111", "probe_dc": "aws:us-east-1", "status": 0,
+                 "result": {"passed": true, "timings": {"total": 250.0}}},
+                {"result_id": "222", "probe_dc": "aws:eu-west-1", "status": 1,
+                 "result": {
+                     "passed": false,
+                     "timings": {"total": 1500.0},
+                     "failure": {"code": "INCORRECT_ASSERTION", "message": "status <> 200 & \"ok\""},
+                     "assertionResults": [
+                         {"valid": true, "type": "latency", "operator": "lessThan", "target": 1000},
+                         {"valid": false, "type": "statusCode", "operator": "is", "target": 200, "actual": 500}
+                     ]
+                 }},
+                {"result_id": "333", "status": 1, "result": {}}
+            ]
+        });
+        let xml = render_junit(&data).unwrap();
+        assert_balanced_xml(&xml);
+        assert!(xml.starts_with("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"));
+        assert!(xml.contains("<testsuites tests=\"3\" failures=\"2\" skipped=\"0\">"));
+        assert!(xml.contains(
+            "<testsuite name=\"synthetics\" tests=\"3\" failures=\"2\" skipped=\"0\" time=\"1.750\">"
+        ));
+        assert!(xml.contains(
+            "<testcase name=\"aws:us-east-1 (111)\" classname=\"synthetics\" time=\"0.250\"/>"
+        ));
+        assert!(xml.contains(
+            "<failure message=\"INCORRECT_ASSERTION: status &lt;&gt; 200 &amp; &quot;ok&quot;\"/>"
+        ));
+        assert!(xml.contains("<failure message=\"assertion failed: statusCode is 200, got 500\"/>"));
+        assert!(!xml.contains("latency"));
+        // No `passed` flag: fall back to the monitor status, with a generic message
+        assert!(xml.contains(
+            "<testcase name=\"333\" classname=\"synthetics\">\n      <failure message=\"test failed\"/>"
+        ));
+        assert_eq!(xml.matches("<testcase ").count(), 3);
+    }
+
+    #[test]
+    fn test_render_junit_ci_test_events() {
+        let event = |name: &str, suite: &str, status: &str| {
+            serde_json::json!({
+                "id": name, "type": "citest",
+                "attributes": {"attributes": {
+                    "test": {"name": name, "suite": suite, "status": status},
+                    "duration": 2_000_000_000i64
+                }}
+            })
+        };
+        let mut failed = event("test_checkout", "cart", "fail");
+        failed["attributes"]["attributes"]["error"] =
+            serde_json::json!({"message": "expected 2, got 3"});
+        let dotted = serde_json::json!({"attributes": {"attributes": {
+            "test.name": "test_login", "test.suite": "auth", "test.status": "pass"
+        }}});
+        let data = serde_json::json!({"data": [
+            event("test_add", "cart", "pass"),
+            failed,
+            event("test_slow", "cart", "skip"),
+            dotted
+        ]});
+
+        let xml = render_junit(&data).unwrap();
+        assert_balanced_xml(&xml);
+        assert!(xml.contains("<testsuites tests=\"4\" failures=\"1\" skipped=\"1\">"));
+        assert!(xml.contains(
+            "<testsuite name=\"cart\" tests=\"3\" failures=\"1\" skipped=\"1\" time=\"6.000\">"
+        ));
+        assert!(xml.contains("<failure message=\"expected 2, got 3\"/>"));
+        assert!(xml.contains(
+            "<testcase name=\"test_slow\" classname=\"cart\" time=\"2.000\">\n      <skipped/>"
+        ));
+        assert!(xml.contains(
+            "<testsuite name=\"auth\" tests=\"1\" failures=\"0\" skipped=\"0\" time=\"0.000\">"
+        ));
+        assert!(xml.contains("<testcase name=\"test_login\" classname=\"auth\"/>"));
+    }
+
+    #[test]
+    fn test_render_junit_rejects_other_data() {
+        let monitors = serde_json::json!([{"id": 1, "name": "CPU high"}]);
+        let err = render_junit(&monitors).unwrap_err().to_string();
+        assert!(err.contains("junit"), "{err}");
+        let not_tests = serde_json::json!({"data": [{"id": "1", "attributes": {"name": "x"}}]});
+        assert!(render_junit(&not_tests).is_err());
+        assert!(format_and_print(&monitors, &OutputFormat::Junit, false, None).is_err());
+    }
+
+    #[test]
+    fn test_render_prometheus_query_series() {
+        let resp = serde_json::json!({"series": [
+            {
+                "metric": "system.cpu.user",
+                "tag_set": ["host:web-1", "env:prod"],
+                "pointlist": [[1700000000000.0, 12.5], [1700000060000.0, null]]
+            },
+            {
+                "metric": "app.requests",
+                "tag_set": [],
+                "pointlist": [[1700000000000.0, 3.0]]
+            },
+            {
+                "metric": "system.cpu.user",
+                "tag_set": ["host:web-2", "role:a", "role:b", "canary", "note:say \"hi\""],
+                "pointlist": [[1700000000000.0, 7.25]]
+            }
+        ]});
+        assert_eq!(
+            render_prometheus(&resp).unwrap(),
+            "# TYPE system_cpu_user untyped\n\
+             system_cpu_user{host=\"web-1\",env=\"prod\"} 12.5 1700000000000\n\
+             system_cpu_user{host=\"web-2\",role=\"a,b\",canary=\"true\",note=\"say \\\"hi\\\"\"} 7.25 1700000000000\n\
+             # TYPE app_requests untyped\n\
+             app_requests 3 1700000000000\n"
+        );
+    }
+
+    #[test]
+    fn test_prometheus_names() {
+        assert_eq!(
+            prometheus_name("trace.http-request.hits"),
+            "trace_http_request_hits"
+        );
+        assert_eq!(prometheus_name("2xx.count"), "_2xx_count");
+        assert_eq!(
+            prometheus_labels(&serde_json::json!(["kube_namespace:default", "__meta:x"])),
+            "{kube_namespace=\"default\",_meta=\"x\"}"
+        );
+        assert_eq!(prometheus_value(f64::INFINITY), "+Inf");
+        assert_eq!(prometheus_value(f64::NAN), "NaN");
+    }
+
+    #[test]
+    fn test_render_prometheus_rejects_other_data() {
+        let monitors = serde_json::json!([{"id": 1, "name": "CPU high"}]);
+        let err = render_prometheus(&monitors).unwrap_err().to_string();
+        assert!(err.contains("prometheus"), "{err}");
+        assert!(format_and_print(&monitors, &OutputFormat::Prometheus, false, None).is_err());
+    }
+
+    #[test]
+    fn test_format_cell_numbers() {
+        let cell = |v: serde_json::Value| format_cell(Some(&v));
+        // Whole-number floats print as integers, however large
+        assert_eq!(cell(serde_json::json!(1234567.0)), "1234567");
+        assert_eq!(
+            cell(serde_json::json!(9.007199254740993e15)),
+            "9007199254740992"
+        );
+        assert_eq!(cell(serde_json::json!(1e21)), "1000000000000000000000");
+        // Genuine decimals keep their digits, without exponents
+        assert_eq!(cell(serde_json::json!(0.25)), "0.25");
+        assert_eq!(cell(serde_json::json!(1.5e-7)), "0.00000015");
+        // Integers are unchanged
+        assert_eq!(cell(serde_json::json!(u64::MAX)), "18446744073709551615");
+        assert_eq!(cell(serde_json::json!(-42)), "-42");
+    }
 }
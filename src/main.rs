@@ -5,6 +5,8 @@ mod client;
 mod commands;
 mod config;
 mod formatter;
+mod plan;
+mod template;
 mod useragent;
 mod util;
 mod version;
@@ -20,20 +22,70 @@ pub(crate) mod test_utils {
     pub static ENV_LOCK: Mutex<()> = Mutex::new(());
 }
 
-use clap::{CommandFactory, Parser, Subcommand};
+use clap::{CommandFactory, FromArgMatches, Parser, Subcommand};
 
 #[derive(Parser)]
 #[command(name = "pup", version = version::VERSION, about = "Datadog API CLI")]
 struct Cli {
-    /// Output format (json, table, yaml)
-    #[arg(short, long, global = true, default_value = "json")]
-    output: String,
+    /// Output format (json, table, yaml, csv, junit, prometheus) [default: table on a terminal, json otherwise]
+    #[arg(short, long, global = true)]
+    output: Option<String>,
     /// Auto-approve destructive operations
     #[arg(short = 'y', long = "yes", global = true)]
     yes: bool,
     /// Enable agent mode
     #[arg(long, global = true)]
     agent: bool,
+    /// Datadog site or region alias (us1, us3, us5, eu1, ap1, gov); overrides DD_SITE
+    #[arg(long, global = true)]
+    site: Option<String>,
+    /// Read configuration from this file instead of ~/.config/pup/config.yaml
+    #[arg(long, global = true, value_name = "PATH")]
+    config: Option<std::path::PathBuf>,
+    /// Wrap output in {request, result} with the command, args, and resolved time window
+    #[arg(long, global = true)]
+    envelope: bool,
+    /// Wrap long table cells across lines instead of truncating (terminal output or --width only)
+    #[arg(long, global = true)]
+    wrap: bool,
+    /// Fit tables into this many columns, dropping low-priority columns that don't fit [default: terminal width]
+    #[arg(long, global = true, value_name = "N",
+          value_parser = clap::builder::RangedU64ValueParser::<usize>::new().range(1..))]
+    width: Option<usize>,
+    /// Use plain ASCII in table output (Y/N instead of ✓/✗ for booleans)
+    #[arg(long, global = true)]
+    ascii: bool,
+    /// Largest array shown inline in table cells; longer arrays show as [N items] (0 always summarizes)
+    #[arg(long, global = true, default_value_t = formatter::DEFAULT_INLINE_ARRAY_LIMIT)]
+    inline_array_limit: usize,
+    /// In table output, show JSON:API relationships by the names of their included resources instead of ids
+    #[arg(long, global = true)]
+    resolve_relationships: bool,
+    /// In table and CSV output, show *_at fields as relative times ("3h ago") and durations as "2m15s"
+    #[arg(long, global = true)]
+    humanize: bool,
+    /// Text shown for null or missing values in table and CSV cells (e.g. "-" or "N/A")
+    #[arg(long, global = true, default_value = "", value_name = "TEXT")]
+    empty_placeholder: String,
+    /// Colorize output: auto, always, never
+    #[arg(long, global = true, default_value = "auto")]
+    color: String,
+    /// Most API requests in flight at once when a command fans out (bulk actions)
+    #[arg(long, global = true, default_value_t = client::DEFAULT_MAX_CONCURRENCY, value_name = "N",
+          value_parser = clap::builder::RangedU64ValueParser::<usize>::new().range(1..))]
+    max_concurrency: usize,
+    /// Run the command against each named config profile (comma-separated) and aggregate the results
+    #[arg(long, global = true, value_name = "PROFILES")]
+    orgs: Option<String>,
+    /// Render output through a Go-style template file (helpers: json, upper, lower, default, join)
+    #[arg(long, global = true, value_name = "PATH")]
+    template_file: Option<String>,
+    /// Print a short summary (counts by state/status, time span) instead of the full list
+    #[arg(long, global = true)]
+    summary: bool,
+    /// Print the API call plan (time window, endpoints, pagination, request count) and exit without calling the API
+    #[arg(long, global = true)]
+    explain: bool,
     #[command(subcommand)]
     command: Commands,
 }
@@ -197,6 +249,9 @@ enum Commands {
     ///   # Query entities with filtering
     ///   pup apm entities list --start $(date -d '1 hour ago' +%s) --end $(date +%s) --env prod
     ///
+    ///   # Entities as a tree, inferred datastores and queues under their service
+    ///   pup apm entities list --format tree
+    ///
     ///   # View service dependencies
     ///   pup apm dependencies list --env prod --start $(date -d '1 hour ago' +%s) --end $(date +%s)
     ///
@@ -319,6 +374,9 @@ enum Commands {
     ///   DD_SITE=us5.datadoghq.com pup auth login # US5
     ///   DD_SITE=ap1.datadoghq.com pup auth login # AP1
     ///
+    ///   Region aliases work anywhere a site is accepted:
+    ///   pup --site=eu1 auth login                # same as datadoghq.eu
+    ///
     /// TOKEN STORAGE:
     ///   Credentials are stored in:
     ///   • ~/.config/pup/tokens_<site>.json - OAuth2 tokens
@@ -339,6 +397,39 @@ enum Commands {
         #[command(subcommand)]
         action: AuthActions,
     },
+    /// Run several commands from a file
+    ///
+    /// Runs the steps listed in a YAML or JSON file in order, in one process,
+    /// and prints their outputs as one JSON array with an entry per step.
+    /// Each step gives a command path and its flags/arguments; global flags
+    /// (--output, --yes, ...) come from the batch invocation and are refused
+    /// in a step's args. Steps never prompt: destructive steps need --yes.
+    ///
+    /// By default the batch stops at the first failing step. With
+    /// --continue-on-error every step runs. Either way, the batch exits
+    /// non-zero if any step failed.
+    ///
+    /// FILE FORMAT:
+    ///   steps:
+    ///     - name: prod-monitors
+    ///       command: monitors list
+    ///       args: ["--tags", "env:prod"]
+    ///     - command: monitors get
+    ///       args: ["12345"]
+    ///
+    /// EXAMPLES:
+    ///   # Run a batch file
+    ///   pup batch --file ops.yaml
+    ///
+    ///   # Run every step even if some fail
+    ///   pup batch --file ops.yaml --continue-on-error
+    #[command(verbatim_doc_comment)]
+    Batch {
+        #[arg(long, help = "YAML or JSON file listing the steps to run")]
+        file: String,
+        #[arg(long, help = "Keep running the remaining steps after a step fails")]
+        continue_on_error: bool,
+    },
     /// Manage case management cases and projects
     ///
     /// Manage Datadog Case Management for tracking and resolving issues.
@@ -582,6 +673,20 @@ enum Commands {
     ///   # Delete a dashboard without confirmation (automation)
     ///   pup dashboards delete abc-def-123 --yes
     ///
+    ///   # Export a dashboard and recreate it elsewhere
+    ///   pup dashboards export abc-def-123 --file=checkout.yaml
+    ///   pup dashboards import --file=checkout.yaml
+    ///
+    ///   # Overwrite an existing dashboard from a definition file
+    ///   pup dashboards import --file=checkout.yaml --update=abc-def-123
+    ///
+    ///   # Share a dashboard with two people for 30 days
+    ///   pup dashboards shares create abc-def-123 --share-type=invite \
+    ///     --invitee=a@example.com --invitee=b@example.com --expires-in=30d
+    ///
+    ///   # Revoke a shared dashboard URL
+    ///   pup dashboards shares revoke <token>
+    ///
     /// TEMPLATE VARIABLES:
     ///   Dashboards can include template variables for dynamic filtering:
     ///   • $env: Environment filter
@@ -642,12 +747,19 @@ enum Commands {
     ///   # Get downtime details
     ///   pup downtime get abc-123-def
     ///
+    ///   # Silence production for two hours starting now
+    ///   pup downtime create --scope=env:prod --duration=2h
+    ///
+    ///   # Silence team monitors in staging every Saturday 22:00-00:00 UTC
+    ///   pup downtime create --scope=env:staging --monitor-tag=team:web \
+    ///     --start=2024-01-06T22:00:00Z --duration=2h --rrule="FREQ=WEEKLY;BYDAY=SA"
+    ///
     ///   # Cancel a downtime
     ///   pup downtime cancel abc-123-def
     ///
     /// AUTHENTICATION:
     ///   Requires either OAuth2 authentication or API keys.
-    #[command(verbatim_doc_comment)]
+    #[command(alias = "downtimes", verbatim_doc_comment)]
     Downtime {
         #[command(subcommand)]
         action: DowntimeActions,
@@ -688,6 +800,7 @@ enum Commands {
     ///   • List recent events
     ///   • Search events with queries
     ///   • Get event details
+    ///   • Post change and alert events
     ///
     /// EXAMPLES:
     ///   # List recent events
@@ -699,6 +812,10 @@ enum Commands {
     ///   # Get specific event
     ///   pup events get 1234567890
     ///
+    ///   # Mark a deploy from CI as a change event
+    ///   pup events post --title="Deployed checkout v42" --tags=env:prod,service:checkout \
+    ///     --changed-resource=checkout --author=ci --new-value=v42 --impacted-service=checkout
+    ///
     /// AUTHENTICATION:
     ///   Requires either OAuth2 authentication or API keys.
     #[command(verbatim_doc_comment)]
@@ -837,6 +954,7 @@ enum Commands {
     ///   • Get host details and metrics
     ///   • Search hosts by tags or status
     ///   • Monitor host health
+    ///   • Mute and unmute hosts during maintenance windows
     ///
     /// EXAMPLES:
     ///   # List all hosts
@@ -845,9 +963,18 @@ enum Commands {
     ///   # Search for hosts by tag
     ///   pup infrastructure hosts list --filter="env:production"
     ///
+    ///   # List up to 5000 hosts that reported in the last hour
+    ///   pup infrastructure hosts list --from=1h --count=5000
+    ///
     ///   # Get host details
     ///   pup infrastructure hosts get my-host
     ///
+    ///   # Mute a host for a two hour patch window
+    ///   pup infrastructure hosts mute my-host --end=2h --message="OS patching"
+    ///
+    ///   # Unmute it again
+    ///   pup infrastructure hosts unmute my-host
+    ///
     /// AUTHENTICATION:
     ///   Requires either OAuth2 authentication or API keys.
     #[command(verbatim_doc_comment)]
@@ -926,10 +1053,14 @@ enum Commands {
     ///   • Search logs with flexible queries (v1 API)
     ///   • Query and aggregate logs (v2 API)
     ///   • List logs with filtering (v2 API)
+    ///   • Tail logs and follow new ones as they arrive
+    ///   • Cluster log messages into patterns for triage
     ///   • Search across different storage tiers (indexes, online-archives, flex)
     ///   • Manage log archives (CRUD operations)
     ///   • Manage custom destinations for logs
     ///   • Create and manage log-based metrics
+    ///   • Manage log indexes (quotas, retention, exclusion filters, order)
+    ///   • Manage log pipelines and their processing order
     ///   • Configure restriction queries for access control
     ///
     /// STORAGE TIERS:
@@ -964,21 +1095,45 @@ enum Commands {
     ///   # Search Flex logs specifically
     ///   pup logs search --query="status:error" --from="1h" --storage="flex"
     ///
+    ///   # Search indexes, online archives and Flex at once, tagged by tier
+    ///   pup logs search --query="status:error" --from="1d" --storage="all-parallel"
+    ///
+    ///   # Pull a long range one day at a time
+    ///   pup logs search --query="service:api" --from="90d" --chunk="1d" --limit=1000
+    ///
     ///   # Query logs from a specific service
     ///   pup logs query --query="service:web-app" --from="4h" --to="now"
     ///
     ///   # Query online archives
     ///   pup logs query --query="service:web-app" --from="30d" --storage="online-archives"
     ///
+    ///   # Export every matching log to a CSV file
+    ///   pup logs export --query="service:api" --from="7d" --output-file=api.csv
+    ///
+    ///   # Top error message patterns over the last 4 hours
+    ///   pup logs analyze --query="status:error" --from="4h" --top=10
+    ///
+    ///   # Follow new error logs as they arrive (Ctrl-C to stop)
+    ///   pup logs tail --query="status:error" --follow
+    ///
+    ///   # List facets, the fields usable in --group-by
+    ///   pup logs facets list
+    ///
     ///   # Aggregate logs by status
     ///   pup logs aggregate --query="*" --compute="count" --group-by="status"
     ///
+    ///   # Latency percentiles over a measure
+    ///   pup logs aggregate --query="service:api" --percentiles=50,90,95,99 --field=@duration
+    ///
     ///   # List log archives
     ///   pup logs archives list
     ///
     ///   # Get specific archive details
     ///   pup logs archives get "my-archive-id"
     ///
+    ///   # Create an S3 archive (bucket and AWS integration in the file)
+    ///   pup logs archives create --file=archive.json --name=prod --query="env:prod" --destination-type=s3
+    ///
     ///   # List log-based metrics
     ///   pup logs metrics list
     ///
@@ -988,9 +1143,28 @@ enum Commands {
     ///   # List custom destinations
     ///   pup logs custom-destinations list
     ///
+    ///   # Create a custom destination from a body file, disabled at first
+    ///   pup logs custom-destinations create --file=destination.json --enabled=false
+    ///
+    ///   # Forward error logs to an HTTPS endpoint with a token header
+    ///   pup logs custom-destinations create --name=errors --query="status:error" \
+    ///     --endpoint=https://logs.example.com --header-name=Authorization --header-value="Bearer $TOKEN"
+    ///
     ///   # List restriction queries
     ///   pup logs restriction-queries list
     ///
+    ///   # Limit a role to staging logs
+    ///   pup logs restriction-queries create --query="env:staging"
+    ///   pup logs restriction-queries grant-role <query-id> --role-id=<role-id>
+    ///
+    ///   # Cap an index at 10M logs a day and drop 90% of health checks
+    ///   pup logs indexes update main --daily-limit=10000000 \
+    ///     --exclusion-name=health --exclusion-query="@http.url:/health" --exclusion-sample-rate=0.9
+    ///
+    ///   # Create a log pipeline, then run it before all others
+    ///   pup logs pipelines create --file=pipeline.json
+    ///   pup logs pipelines reorder "<pipeline-id>"
+    ///
     /// AUTHENTICATION:
     ///   Requires either OAuth2 authentication (pup auth login) or API keys
     ///   (DD_API_KEY and DD_APP_KEY environment variables).
@@ -1030,6 +1204,16 @@ enum Commands {
     ///   # Query metrics
     ///   pup metrics query --query="avg:system.cpu.user{*}" --from="1h" --to="now"
     ///   pup metrics query --query="sum:app.requests{env:prod} by {service}" --from="4h"
+    ///   pup metrics query --query="avg:system.cpu.user{*} by {host}" --output=prometheus
+    ///
+    ///   # What breaks if this metric is renamed or dropped
+    ///   pup metrics assets app.requests
+    ///
+    ///   # Find the most expensive custom metrics
+    ///   pup metrics volumes list --filter="app.*" --limit=20
+    ///
+    ///   # One number per group (e.g. for CI gates)
+    ///   pup metrics scalar --query="avg:trace.http.request.duration{env:prod} by {service}" --from="15m"
     ///
     ///   # List metrics
     ///   pup metrics list
@@ -1048,6 +1232,9 @@ enum Commands {
     ///   # Submit custom metrics
     ///   pup metrics submit --name="custom.metric" --value=123 --tags="env:prod,team:backend"
     ///   pup metrics submit --name="custom.gauge" --value=99.5 --type="gauge" --timestamp=now
+    ///   pup metrics submit --name="api.latency" --type=distribution --value=12 --value=48.5 --value=230
+    ///   pup metrics submit --file=series.json
+    ///   generate-series | pup metrics submit --file=-
     ///
     ///   # List metric tags
     ///   pup metrics tags list system.cpu.user
@@ -1097,6 +1284,7 @@ enum Commands {
     ///   • List all monitors with optional filtering by name or tags
     ///   • Get detailed information about a specific monitor
     ///   • Delete monitors (requires confirmation unless --yes flag is used)
+    ///   • Delete or mute every monitor matching a search query
     ///   • View monitor configuration, thresholds, and notification settings
     ///
     /// MONITOR TYPES:
@@ -1127,6 +1315,17 @@ enum Commands {
     ///   # Delete a monitor without confirmation (automation)
     ///   pup monitors delete 12345678 --yes
     ///
+    ///   # Delete or mute every monitor matching a search (more than 20 requires --yes)
+    ///   pup monitors delete --query="tag:deprecated"
+    ///   pup monitors mute --query="tag:noisy" --duration=2h
+    ///
+    ///   # Tag and resolve every matching monitor in one pass
+    ///   pup monitors bulk --query="tag:team-x" --set-tag=owner:team-x --resolve
+    ///
+    ///   # Keep monitors as code: export to a directory, edit, then import
+    ///   pup monitors export --query="team:web" --dir=./monitors
+    ///   pup monitors import --dir=./monitors
+    ///
     /// OUTPUT FORMAT:
     ///   All commands output JSON by default. Use --output flag for other formats.
     ///
@@ -1321,6 +1520,44 @@ enum Commands {
         #[command(subcommand)]
         action: ProductAnalyticsActions,
     },
+    /// Call any Datadog API path with the configured auth
+    ///
+    /// An escape hatch for endpoints pup has no command for yet. The request
+    /// uses the same site and credentials as every other command.
+    ///
+    /// EXAMPLES:
+    ///   # GET a path and format the JSON response
+    ///   pup raw GET /api/v1/validate
+    ///
+    ///   # POST a JSON body from a file
+    ///   pup raw POST /api/v2/users --file=user.json
+    ///
+    ///   # Copy the response body to stdout or a file unchanged
+    ///   pup raw GET /api/v2/logs/events --stream > events.json
+    ///   pup raw GET /api/v2/logs/events --output-file=events.json
+    ///
+    /// AUTHENTICATION:
+    ///   Requires either OAuth2 authentication (pup auth login) or API keys
+    ///   (DD_API_KEY and DD_APP_KEY environment variables).
+    #[command(verbatim_doc_comment)]
+    Raw {
+        /// HTTP method (GET, POST, PUT, PATCH, DELETE)
+        method: String,
+        /// API path, e.g. /api/v1/validate (query string allowed)
+        path: String,
+        #[arg(long, help = "JSON file with the request body")]
+        file: Option<String>,
+        #[arg(
+            long,
+            help = "Copy the response body to stdout without decoding or formatting"
+        )]
+        stream: bool,
+        #[arg(
+            long,
+            help = "Write the response body to this file unchanged (implies --stream)"
+        )]
+        output_file: Option<String>,
+    },
     /// Manage Real User Monitoring (RUM)
     ///
     /// Manage Datadog Real User Monitoring (RUM) for frontend application performance.
@@ -1457,6 +1694,32 @@ enum Commands {
         #[command(subcommand)]
         action: ServiceCatalogActions,
     },
+    /// Submit service checks
+    ///
+    /// Report custom health checks to Datadog through the service check intake.
+    ///
+    /// Service checks carry an ok, warning, critical, or unknown status for a
+    /// named check on a host, so scripts and cron jobs can report their health
+    /// and be monitored with service check monitors.
+    ///
+    /// CAPABILITIES:
+    ///   • Submit a check run with status, host, tags, and message
+    ///
+    /// EXAMPLES:
+    ///   # Report a successful backup
+    ///   pup service-checks submit --check backup.nightly --status ok --host db-1
+    ///
+    ///   # Report a failure with tags and a message
+    ///   pup service-checks submit --check backup.nightly --status critical \
+    ///     --host db-1 --tags env:prod,team:data --message "pg_dump exited 1"
+    ///
+    /// AUTHENTICATION:
+    ///   Requires an API key (DD_API_KEY). Bearer tokens are not accepted.
+    #[command(name = "service-checks", verbatim_doc_comment)]
+    ServiceChecks {
+        #[command(subcommand)]
+        action: ServiceCheckActions,
+    },
     /// Manage Service Level Objectives
     ///
     /// Manage Datadog Service Level Objectives (SLOs) for tracking service reliability.
@@ -1501,6 +1764,16 @@ enum Commands {
     ///   # Delete an SLO without confirmation (automation)
     ///   pup slos delete abc-123-def --yes
     ///
+    ///   # Weekly reliability review: SLI, budget left, and burn rate per SLO
+    ///   pup slos history --query=checkout --from=7d --output=table
+    ///
+    ///   # Exclude a maintenance window from an SLO's error budget
+    ///   pup slos corrections create abc-123-def --from 2h --to 1h --category scheduled_maintenance
+    ///
+    ///   # Exclude a weekly Saturday maintenance window (2h from the given start)
+    ///   pup slos corrections create abc-123-def --from 2024-06-01T02:00:00Z \
+    ///     --rrule "FREQ=WEEKLY;BYDAY=SA" --duration 2h --category scheduled_maintenance
+    ///
     /// ERROR BUDGET:
     ///   Error budget represents the allowed amount of unreliability before breaching
     ///   the SLO target. It's calculated as (1 - target) * time_window.
@@ -1576,7 +1849,9 @@ enum Commands {
     ///   • List synthetic tests
     ///   • Search synthetic tests by text query
     ///   • Get test details
+    ///   • Create, update, delete, pause, and resume tests
     ///   • Get test results
+    ///   • Trigger tests and wait for results (CI gating, JUnit reports)
     ///   • List test locations
     ///   • Manage global variables
     ///
@@ -1591,6 +1866,13 @@ enum Commands {
     ///   # Get test details
     ///   pup synthetics tests get test-id
     ///
+    ///   # Create or update a test from a definition file
+    ///   pup synthetics tests create --file=checkout-api.json
+    ///   pup synthetics tests update abc-def-ghi --file=checkout-api.json
+    ///
+    ///   # Run smoke tests in CI and fail the step if any blocking test fails
+    ///   pup synthetics run --tag=smoke --wait --junit-file=synthetics.xml
+    ///
     ///   # List available locations
     ///   pup synthetics locations list
     ///
@@ -1614,6 +1896,7 @@ enum Commands {
     ///   • Add tags to a host
     ///   • Update host tags
     ///   • Remove tags from a host
+    ///   • Add and remove tags across many hosts at once
     ///
     /// EXAMPLES:
     ///   # List all host tags
@@ -1625,6 +1908,9 @@ enum Commands {
     ///   # Add tags to a host
     ///   pup tags add my-host env:prod team:backend
     ///
+    ///   # Retag several hosts concurrently, with a result per host
+    ///   pup tags apply --hosts=web-1,web-2 --add=team:core --remove=legacy:true
+    ///
     /// AUTHENTICATION:
     ///   Requires either OAuth2 authentication or API keys.
     #[command(verbatim_doc_comment)]
@@ -1644,7 +1930,7 @@ enum Commands {
     ///
     /// COMPLEMENTS THE APM COMMAND:
     ///   - apm: Service-level aggregated data (services, operations, dependencies)
-    ///   - traces: Individual span-level data (search, aggregate)
+    ///   - traces: Individual span-level data (search, aggregate, get)
     ///
     /// EXAMPLES:
     ///   # Search for error spans in the last hour
@@ -1656,6 +1942,9 @@ enum Commands {
     ///   # P99 latency by resource
     ///   pup traces aggregate --query="service:api" --compute="percentile(@duration, 99)" --group-by="resource_name"
     ///
+    ///   # Show a whole trace as a call tree
+    ///   pup traces get 1234567890123456789 --output=tree
+    ///
     /// AUTHENTICATION:
     ///   Requires either OAuth2 authentication (apm_read scope) or API keys.
     #[command(verbatim_doc_comment)]
@@ -1739,7 +2028,12 @@ enum MonitorActions {
         limit: i32,
     },
     /// Get monitor details
-    Get { monitor_id: i64 },
+    Get {
+        monitor_id: i64,
+        /// Also list the dashboards and SLOs that reference this monitor
+        #[arg(long)]
+        related: bool,
+    },
     /// Create a monitor from JSON file
     Create {
         #[arg(long)]
@@ -1751,6 +2045,50 @@ enum MonitorActions {
         #[arg(long)]
         file: String,
     },
+    /// Check monitor definitions with the API without creating them
+    Validate {
+        #[arg(
+            long,
+            required = true,
+            help = "Monitor JSON file to check (repeatable); exits non-zero if any is invalid"
+        )]
+        file: Vec<String>,
+    },
+    /// Tag, resolve, or delete every monitor matching a search query
+    Bulk {
+        #[arg(long, help = "Monitor search query (e.g. \"tag:team-x\")")]
+        query: String,
+        #[arg(
+            long = "set-tag",
+            help = "Tag to set as key:value, replacing other values for the key (repeatable)"
+        )]
+        set_tag: Vec<String>,
+        #[arg(long, help = "Resolve every alerting group")]
+        resolve: bool,
+        #[arg(
+            long,
+            conflicts_with_all = ["set_tag", "resolve"],
+            help = "Delete the matched monitors"
+        )]
+        delete: bool,
+    },
+    /// Export matching monitors to a directory, one file per monitor
+    Export {
+        #[arg(long, help = "Monitor search query selecting the monitors to export")]
+        query: String,
+        #[arg(long, help = "Directory to write monitor definitions to")]
+        dir: String,
+        #[arg(long, default_value = "json", help = "File format: json, yaml")]
+        format: String,
+    },
+    /// Create or update monitors from a directory of definitions
+    Import {
+        #[arg(
+            long,
+            help = "Directory of monitor definitions; monitors are matched by their pup_managed tag"
+        )]
+        dir: String,
+    },
     /// Search monitors
     Search {
         #[arg(long, help = "Search query string")]
@@ -1762,8 +2100,60 @@ enum MonitorActions {
         #[arg(long, help = "Sort order")]
         sort: Option<String>,
     },
-    /// Delete a monitor
-    Delete { monitor_id: i64 },
+    /// Delete a monitor, or every monitor matching --query
+    Delete {
+        #[arg(required_unless_present = "query")]
+        monitor_id: Option<i64>,
+        #[arg(
+            long,
+            conflicts_with = "monitor_id",
+            help = "Delete every monitor matching this search query (e.g. tag:deprecated)"
+        )]
+        query: Option<String>,
+    },
+    /// Mute a monitor, or every monitor matching --query, with a downtime
+    Mute {
+        #[arg(required_unless_present = "query")]
+        monitor_id: Option<i64>,
+        #[arg(
+            long,
+            conflicts_with = "monitor_id",
+            help = "Mute every monitor matching this search query (e.g. tag:deprecated)"
+        )]
+        query: Option<String>,
+        #[arg(
+            long,
+            requires = "query",
+            help = "Apply to every monitor returned by --query (implied by --query)"
+        )]
+        all_matching: bool,
+        #[arg(long, help = "How long to mute (e.g. 2h, 1d); indefinite if omitted")]
+        duration: Option<String>,
+        #[arg(
+            long,
+            help = "Only mute these groups (e.g. env:prod); all groups if omitted"
+        )]
+        scope: Option<String>,
+    },
+    /// Unmute a monitor, or every monitor matching --query, by cancelling its downtimes
+    Unmute {
+        #[arg(required_unless_present = "query")]
+        monitor_id: Option<i64>,
+        #[arg(
+            long,
+            conflicts_with = "monitor_id",
+            help = "Unmute every monitor matching this search query"
+        )]
+        query: Option<String>,
+        #[arg(
+            long,
+            requires = "query",
+            help = "Apply to every monitor returned by --query (implied by --query)"
+        )]
+        all_matching: bool,
+        #[arg(long, help = "Only cancel downtimes over this scope (e.g. env:prod)")]
+        scope: Option<String>,
+    },
 }
 
 // ---- Logs ----
@@ -1791,8 +2181,30 @@ enum LogActions {
         sort: String,
         #[arg(long, help = "Comma-separated log indexes")]
         index: Option<String>,
-        #[arg(long, help = "Storage tier: indexes, online-archives, or flex")]
+        #[arg(
+            long,
+            help = "Storage tier: indexes, online-archives, flex, or all-parallel to search every tier at once"
+        )]
         storage: Option<String>,
+        #[arg(
+            long,
+            help = "Split the time range into sub-windows of this size (e.g. 1d) and merge results"
+        )]
+        chunk: Option<String>,
+        #[arg(
+            long,
+            help = "Highlight query terms in the message column (table output)"
+        )]
+        highlight: bool,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
+        #[arg(
+            long,
+            value_name = "PATH",
+            conflicts_with_all = ["chunk", "limit", "storage"],
+            help = "Page through every matching log and stream them to this file as NDJSON"
+        )]
+        export: Option<String>,
     },
     /// List logs (v2 API)
     List {
@@ -1810,8 +2222,13 @@ enum LogActions {
         limit: i32,
         #[arg(long, default_value = "-timestamp", help = "Sort order")]
         sort: String,
-        #[arg(long, help = "Storage tier: indexes, online-archives, or flex")]
+        #[arg(
+            long,
+            help = "Storage tier: indexes, online-archives, flex, or all-parallel to search every tier at once"
+        )]
         storage: Option<String>,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
     },
     /// Query logs (v2 API)
     Query {
@@ -1829,10 +2246,81 @@ enum LogActions {
         limit: i32,
         #[arg(long, default_value = "-timestamp", help = "Sort order")]
         sort: String,
-        #[arg(long, help = "Storage tier: indexes, online-archives, or flex")]
+        #[arg(
+            long,
+            help = "Storage tier: indexes, online-archives, flex, or all-parallel to search every tier at once"
+        )]
         storage: Option<String>,
         #[arg(long, help = "Timezone for timestamps")]
         timezone: Option<String>,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
+    },
+    /// Export every matching log to a file (NDJSON or CSV), page by page with progress
+    Export {
+        #[arg(long, help = "Search query (required)")]
+        query: String,
+        #[arg(
+            long,
+            default_value = "1h",
+            help = "Start time: 1h, 5min, 2hours, '5 minutes', RFC3339, Unix timestamp, or 'now'"
+        )]
+        from: String,
+        #[arg(long, default_value = "now", help = "End time")]
+        to: String,
+        #[arg(
+            long,
+            value_name = "PATH",
+            help = "File to write the logs to (overwritten)"
+        )]
+        output_file: String,
+        #[arg(
+            long,
+            help = "File format: ndjson or csv [default: csv for a .csv file, ndjson otherwise]"
+        )]
+        format: Option<String>,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
+    },
+    /// Print the newest logs and, with --follow, keep printing new logs as they arrive (Ctrl-C to stop)
+    Tail {
+        #[arg(long, default_value = "*", help = "Search query")]
+        query: String,
+        #[arg(
+            long,
+            default_value = "15m",
+            help = "How far back to look for the initial logs (e.g. 15m, 1h)"
+        )]
+        from: String,
+        #[arg(long, default_value_t = 10, help = "Number of initial logs to print (1-1000)",
+              value_parser = clap::value_parser!(i32).range(1..=1000))]
+        limit: i32,
+        #[arg(short, long, help = "Keep polling and print new logs as they arrive")]
+        follow: bool,
+        #[arg(
+            long,
+            default_value = "5s",
+            help = "Poll interval with --follow (e.g. 5s, 1m)"
+        )]
+        interval: String,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
+    },
+    /// Group sampled log messages into patterns and count them
+    Analyze {
+        #[arg(long, default_value = "*", help = "Search query")]
+        query: String,
+        #[arg(long, default_value = "1h", help = "Start time")]
+        from: String,
+        #[arg(long, default_value = "now", help = "End time")]
+        to: String,
+        #[arg(long, default_value_t = 1000, help = "Maximum logs to sample, newest first (1-10000)",
+              value_parser = clap::value_parser!(i32).range(1..=10000))]
+        limit: i32,
+        #[arg(long, default_value_t = 20, help = "Number of patterns to report")]
+        top: usize,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
     },
     /// Aggregate logs (v2 API)
     Aggregate {
@@ -1846,11 +2334,38 @@ enum LogActions {
         from: String,
         #[arg(long, default_value = "now", help = "End time")]
         to: String,
-        #[arg(long, default_value = "count", help = "Metric to compute")]
-        compute: String,
-        #[arg(long, help = "Field to group by")]
-        group_by: Option<String>,
-        #[arg(long, default_value_t = 10, help = "Maximum groups")]
+        #[arg(
+            long,
+            help = "Metric to compute: count, avg(@duration), percentile(@duration, 99), etc. Repeatable; defaults to count"
+        )]
+        compute: Vec<String>,
+        #[arg(
+            long,
+            requires = "field",
+            help = "Comma-separated percentiles of --field to compute (50, 75, 90, 95, 98, 99)"
+        )]
+        percentiles: Option<String>,
+        #[arg(
+            long,
+            requires = "percentiles",
+            help = "Measure the percentiles are computed over (e.g. @duration)"
+        )]
+        field: Option<String>,
+        #[arg(
+            long,
+            help = "Bucket size for a timeseries of each compute (e.g. 5m, 1h); totals when omitted"
+        )]
+        interval: Option<String>,
+        #[arg(
+            long,
+            help = "Facet to group by (e.g. service, @http.status_code). Repeatable to nest groups"
+        )]
+        group_by: Vec<String>,
+        #[arg(
+            long,
+            default_value_t = 10,
+            help = "Maximum groups per --group-by facet"
+        )]
         limit: i32,
         #[arg(long, help = "Storage tier: indexes, online-archives, or flex")]
         storage: Option<String>,
@@ -1860,6 +2375,12 @@ enum LogActions {
         #[command(subcommand)]
         action: LogArchiveActions,
     },
+    /// Archive rehydrations (historical views); Datadog has no public API for them yet,
+    /// so these fail with a pointer to the Datadog UI
+    Rehydrations {
+        #[command(subcommand)]
+        action: LogRehydrationActions,
+    },
     /// Manage custom log destinations
     #[command(name = "custom-destinations")]
     CustomDestinations {
@@ -1871,6 +2392,21 @@ enum LogActions {
         #[command(subcommand)]
         action: LogMetricActions,
     },
+    /// List log facets
+    Facets {
+        #[command(subcommand)]
+        action: LogFacetActions,
+    },
+    /// Manage log indexes, their quotas, exclusion filters, and order
+    Indexes {
+        #[command(subcommand)]
+        action: LogIndexActions,
+    },
+    /// Manage log pipelines and their processing order
+    Pipelines {
+        #[command(subcommand)]
+        action: LogPipelineActions,
+    },
     /// Manage log restriction queries
     #[command(name = "restriction-queries")]
     RestrictionQueries {
@@ -1885,33 +2421,384 @@ enum LogRestrictionQueryActions {
     List,
     /// Get restriction query details
     Get { query_id: String },
+    /// Create a restriction query
+    Create {
+        #[arg(long, help = "Log query the restriction allows (e.g. env:staging)")]
+        query: String,
+    },
+    /// Update a restriction query
+    Update {
+        query_id: String,
+        #[arg(long, help = "New log query the restriction allows")]
+        query: String,
+    },
+    /// Delete a restriction query
+    Delete { query_id: String },
+    /// Grant a restriction query to a role
+    GrantRole {
+        query_id: String,
+        #[arg(long, help = "Role ID to grant the restriction query to")]
+        role_id: String,
+    },
+    /// Revoke a restriction query from a role
+    RevokeRole {
+        query_id: String,
+        #[arg(long, help = "Role ID to revoke the restriction query from")]
+        role_id: String,
+    },
 }
 
 #[derive(Subcommand)]
-enum LogArchiveActions {
-    /// List all log archives
-    List,
-    /// Get log archive details
-    Get { archive_id: String },
-    /// Delete a log archive
-    Delete { archive_id: String },
-}
-
-#[derive(Subcommand)]
-enum LogCustomDestinationActions {
-    /// List custom log destinations
+enum LogRehydrationActions {
+    /// List rehydration jobs
     List,
-    /// Get custom destination details
-    Get { destination_id: String },
+    /// Get a rehydration job
+    Get { rehydration_id: String },
+    /// Rehydrate logs from an archive
+    Create {
+        #[arg(long, help = "Archive to rehydrate from")]
+        archive_id: String,
+        #[arg(
+            long,
+            default_value = "*",
+            help = "Log query selecting the logs to rehydrate"
+        )]
+        query: String,
+        #[arg(long, help = "Start of the rehydrated window")]
+        from: String,
+        #[arg(long, default_value = "now", help = "End of the rehydrated window")]
+        to: String,
+        #[arg(long, help = "Poll until the rehydration is complete")]
+        wait: bool,
+    },
+    /// Cancel a rehydration job
+    Cancel { rehydration_id: String },
 }
 
 #[derive(Subcommand)]
-enum LogMetricActions {
-    /// List log-based metrics
+enum LogArchiveActions {
+    /// List all log archives
     List,
-    /// Get log-based metric details
-    Get { metric_id: String },
-    /// Delete a log-based metric
+    /// Get log archive details
+    Get { archive_id: String },
+    /// Create a log archive
+    Create {
+        #[arg(
+            long,
+            help = "JSON file with the request body (destination bucket and integration)"
+        )]
+        file: Option<String>,
+        #[arg(long, help = "Archive name")]
+        name: Option<String>,
+        #[arg(long, help = "Log query selecting the logs to archive")]
+        query: Option<String>,
+        #[arg(long = "destination-type", help = "Storage type: s3, gcs, or azure")]
+        dest_type: Option<String>,
+        #[arg(
+            long,
+            help = "Comma-separated tags added to logs rehydrated from the archive"
+        )]
+        rehydration_tags: Option<String>,
+        #[arg(
+            long,
+            help = "Whether to store log tags in the archive (true or false)"
+        )]
+        include_tags: Option<bool>,
+    },
+    /// Update a log archive; without --file, flags change only what they name
+    Update {
+        archive_id: String,
+        #[arg(
+            long,
+            help = "JSON file with the full request body, replacing the archive"
+        )]
+        file: Option<String>,
+        #[arg(long, help = "Archive name")]
+        name: Option<String>,
+        #[arg(long, help = "Log query selecting the logs to archive")]
+        query: Option<String>,
+        #[arg(long = "destination-type", help = "Storage type: s3, gcs, or azure")]
+        dest_type: Option<String>,
+        #[arg(
+            long,
+            help = "Comma-separated tags added to logs rehydrated from the archive"
+        )]
+        rehydration_tags: Option<String>,
+        #[arg(
+            long,
+            help = "Whether to store log tags in the archive (true or false)"
+        )]
+        include_tags: Option<bool>,
+    },
+    /// Delete a log archive
+    Delete { archive_id: String },
+    /// Show the archive order, or move the given archives to the front of it
+    Order {
+        #[arg(help = "Archive IDs; unlisted archives keep their order after these")]
+        archive_ids: Vec<String>,
+    },
+}
+
+#[derive(Subcommand)]
+enum LogCustomDestinationActions {
+    /// List custom log destinations
+    List,
+    /// Get custom destination details
+    Get { destination_id: String },
+    /// Create a custom destination
+    Create {
+        #[arg(
+            long,
+            help = "JSON file with the request body (forwarder endpoint and auth)"
+        )]
+        file: Option<String>,
+        #[arg(long, help = "Destination name")]
+        name: Option<String>,
+        #[arg(long, help = "Log query selecting the logs to forward")]
+        query: Option<String>,
+        #[arg(long = "type", help = "Forwarder type: http, splunk, or elasticsearch")]
+        dest_type: Option<String>,
+        #[arg(long, help = "Whether forwarding is enabled (true or false)")]
+        enabled: Option<bool>,
+        #[arg(
+            long,
+            conflicts_with = "dest_type",
+            help = "HTTPS endpoint of an HTTP destination (builds the forwarder from flags)"
+        )]
+        endpoint: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "password"], conflicts_with = "header_name", help = "Basic auth username for --endpoint")]
+        username: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "username"], help = "Basic auth password for --endpoint")]
+        password: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "header_value"], help = "Auth header name for --endpoint (e.g. Authorization)")]
+        header_name: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "header_name"], help = "Auth header value for --endpoint")]
+        header_value: Option<String>,
+    },
+    /// Update a custom destination
+    Update {
+        destination_id: String,
+        #[arg(long, help = "JSON file with the request body")]
+        file: Option<String>,
+        #[arg(long, help = "Destination name")]
+        name: Option<String>,
+        #[arg(long, help = "Log query selecting the logs to forward")]
+        query: Option<String>,
+        #[arg(long = "type", help = "Forwarder type: http, splunk, or elasticsearch")]
+        dest_type: Option<String>,
+        #[arg(long, help = "Whether forwarding is enabled (true or false)")]
+        enabled: Option<bool>,
+        #[arg(
+            long,
+            conflicts_with = "dest_type",
+            help = "HTTPS endpoint of an HTTP destination (builds the forwarder from flags)"
+        )]
+        endpoint: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "password"], conflicts_with = "header_name", help = "Basic auth username for --endpoint")]
+        username: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "username"], help = "Basic auth password for --endpoint")]
+        password: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "header_value"], help = "Auth header name for --endpoint (e.g. Authorization)")]
+        header_name: Option<String>,
+        #[arg(long, requires_all = ["endpoint", "header_name"], help = "Auth header value for --endpoint")]
+        header_value: Option<String>,
+    },
+    /// Delete a custom destination
+    Delete { destination_id: String },
+}
+
+#[derive(Subcommand)]
+enum LogFacetActions {
+    /// List standard and custom log facets (fields usable in --group-by)
+    List,
+}
+
+#[derive(Subcommand)]
+enum LogIndexActions {
+    /// List log indexes
+    List,
+    /// Get log index details
+    Get { name: String },
+    /// Create a log index
+    Create {
+        name: String,
+        #[arg(long, help = "JSON file with the index definition")]
+        file: Option<String>,
+        #[arg(long, help = "Query selecting the logs that go to this index")]
+        filter: Option<String>,
+        #[arg(long, help = "Daily quota: most logs indexed per day")]
+        daily_limit: Option<i64>,
+        #[arg(long, conflicts_with = "daily_limit", help = "Remove the daily quota")]
+        no_daily_limit: bool,
+        #[arg(
+            long,
+            help = "Days logs are retained (allowed values depend on your contract)"
+        )]
+        retention_days: Option<i64>,
+        #[arg(
+            long,
+            requires = "exclusion_query",
+            help = "Add an exclusion filter with this name, replacing one with the same name"
+        )]
+        exclusion_name: Option<String>,
+        #[arg(
+            long,
+            requires = "exclusion_name",
+            help = "Query of the exclusion filter"
+        )]
+        exclusion_query: Option<String>,
+        #[arg(
+            long,
+            default_value_t = 1.0,
+            help = "Fraction of matching logs the exclusion filter drops (0.0-1.0)"
+        )]
+        exclusion_sample_rate: f64,
+        #[arg(long, help = "Add the exclusion filter disabled")]
+        exclusion_disabled: bool,
+    },
+    /// Update a log index; flags change only what they name
+    Update {
+        name: String,
+        #[arg(
+            long,
+            help = "JSON file with the full index definition, replacing the current one"
+        )]
+        file: Option<String>,
+        #[arg(long, help = "Query selecting the logs that go to this index")]
+        filter: Option<String>,
+        #[arg(long, help = "Daily quota: most logs indexed per day")]
+        daily_limit: Option<i64>,
+        #[arg(long, conflicts_with = "daily_limit", help = "Remove the daily quota")]
+        no_daily_limit: bool,
+        #[arg(
+            long,
+            help = "Days logs are retained (allowed values depend on your contract)"
+        )]
+        retention_days: Option<i64>,
+        #[arg(
+            long,
+            requires = "exclusion_query",
+            help = "Add an exclusion filter with this name, replacing one with the same name"
+        )]
+        exclusion_name: Option<String>,
+        #[arg(
+            long,
+            requires = "exclusion_name",
+            help = "Query of the exclusion filter"
+        )]
+        exclusion_query: Option<String>,
+        #[arg(
+            long,
+            default_value_t = 1.0,
+            help = "Fraction of matching logs the exclusion filter drops (0.0-1.0)"
+        )]
+        exclusion_sample_rate: f64,
+        #[arg(long, help = "Add the exclusion filter disabled")]
+        exclusion_disabled: bool,
+        #[arg(
+            long,
+            value_name = "NAME",
+            help = "Remove the exclusion filter with this name (repeatable)"
+        )]
+        remove_exclusion: Vec<String>,
+    },
+    /// Delete a log index
+    Delete { name: String },
+    /// Show the index order, or move the named indexes to the front of it
+    Order {
+        #[arg(help = "Index names; unlisted indexes keep their order after these")]
+        names: Vec<String>,
+    },
+}
+
+#[derive(Subcommand)]
+enum LogPipelineActions {
+    /// List log pipelines
+    List,
+    /// Get log pipeline details, including its processors
+    Get { pipeline_id: String },
+    /// Create a log pipeline
+    Create {
+        #[arg(
+            long,
+            help = "JSON file with the pipeline definition (name, filter, processors)"
+        )]
+        file: String,
+    },
+    /// Replace a log pipeline with a new definition
+    Update {
+        pipeline_id: String,
+        #[arg(long, help = "JSON file with the full pipeline definition")]
+        file: String,
+    },
+    /// Delete a log pipeline
+    Delete { pipeline_id: String },
+    /// Move pipelines to the front of the processing order, in the order given
+    Reorder {
+        #[arg(
+            required = true,
+            help = "Pipeline IDs; unlisted pipelines keep their order after these"
+        )]
+        pipeline_ids: Vec<String>,
+    },
+}
+
+#[derive(Subcommand)]
+enum LogMetricActions {
+    /// List log-based metrics
+    List,
+    /// Get log-based metric details
+    Get {
+        metric_id: String,
+        /// Also count the logs the metric's query currently matches
+        #[arg(long)]
+        with_volume: bool,
+        /// Lookback window for --with-volume (e.g., 1h, 24h, 7d)
+        #[arg(long, default_value = "1h", requires = "with_volume")]
+        window: String,
+    },
+    /// Create a log-based metric
+    Create {
+        #[arg(long, help = "Metric name (e.g. app.errors)")]
+        name: String,
+        #[arg(long, help = "JSON file with the request body")]
+        file: Option<String>,
+        #[arg(long, help = "Log query selecting the logs the metric counts")]
+        query: Option<String>,
+        #[arg(
+            long,
+            help = "Comma-separated attribute paths to tag the metric by, each optionally PATH=TAG_NAME"
+        )]
+        group_by: Option<String>,
+        #[arg(
+            long,
+            help = "Compute percentiles for a distribution metric (true or false)"
+        )]
+        include_percentiles: Option<bool>,
+        #[arg(long, help = "What to compute: count or distribution [default: count]")]
+        compute: Option<String>,
+        #[arg(long, help = "Measure a distribution aggregates (e.g. @duration)")]
+        path: Option<String>,
+    },
+    /// Update a log-based metric's query, group-by, or percentiles
+    Update {
+        metric_id: String,
+        #[arg(long, help = "JSON file with the request body")]
+        file: Option<String>,
+        #[arg(long, help = "Log query selecting the logs the metric counts")]
+        query: Option<String>,
+        #[arg(
+            long,
+            help = "Comma-separated attribute paths to tag the metric by, each optionally PATH=TAG_NAME"
+        )]
+        group_by: Option<String>,
+        #[arg(
+            long,
+            help = "Compute percentiles for a distribution metric (true or false)"
+        )]
+        include_percentiles: Option<bool>,
+    },
+    /// Delete a log-based metric
     Delete { metric_id: String },
 }
 
@@ -1924,12 +2811,30 @@ enum IncidentActions {
         limit: i64,
     },
     /// Get incident details
-    Get { incident_id: String },
+    Get {
+        incident_id: String,
+        #[arg(
+            long,
+            help = "Render as postmortem markdown (summary, impact, timeline, action items)"
+        )]
+        postmortem: bool,
+        #[arg(
+            long,
+            requires = "postmortem",
+            help = "Redact email and IP addresses from the postmortem"
+        )]
+        redact: bool,
+    },
     /// Manage incident attachments
     Attachments {
         #[command(subcommand)]
         action: IncidentAttachmentActions,
     },
+    /// Manage incident todos (action items)
+    Todos {
+        #[command(subcommand)]
+        action: IncidentTodoActions,
+    },
     /// Manage global incident settings
     Settings {
         #[command(subcommand)]
@@ -1959,6 +2864,29 @@ enum IncidentAttachmentActions {
     },
 }
 
+#[derive(Subcommand)]
+enum IncidentTodoActions {
+    /// List an incident's todos
+    List { incident_id: String },
+    /// Add a todo to an incident
+    Add {
+        incident_id: String,
+        #[arg(long, help = "What needs to be done (required)")]
+        content: String,
+        #[arg(
+            long = "assignee",
+            value_name = "HANDLE",
+            help = "Assignee handle, e.g. @jane@example.com (repeatable)"
+        )]
+        assignees: Vec<String>,
+    },
+    /// Mark an incident todo completed
+    Complete {
+        incident_id: String,
+        todo_id: String,
+    },
+}
+
 #[derive(Subcommand)]
 enum IncidentSettingsActions {
     /// Get global incident settings
@@ -1974,17 +2902,20 @@ enum IncidentSettingsActions {
 enum IncidentHandleActions {
     /// List global incident handles
     List,
+    /// Get a single incident handle
+    Get { handle_id: String },
     /// Create global incident handle
     Create {
         #[arg(long, help = "JSON file with handle data (required)")]
         file: String,
     },
-    /// Update global incident handle
+    /// Update an incident handle
     Update {
+        handle_id: String,
         #[arg(long, help = "JSON file with handle data (required)")]
         file: String,
     },
-    /// Delete global incident handle
+    /// Delete an incident handle
     Delete { handle_id: String },
 }
 
@@ -2013,7 +2944,11 @@ enum IncidentPostmortemActions {
 #[derive(Subcommand)]
 enum DashboardActions {
     /// List all dashboards
-    List,
+    List {
+        /// List recently deleted dashboards (candidates for `dashboards restore`)
+        #[arg(long)]
+        deleted: bool,
+    },
     /// Get dashboard details
     Get { id: String },
     /// Create a dashboard from JSON file
@@ -2029,6 +2964,80 @@ enum DashboardActions {
     },
     /// Delete a dashboard
     Delete { id: String },
+    /// Restore one or more deleted dashboards
+    Restore {
+        /// Dashboard IDs to restore (see `dashboards list --deleted`)
+        #[arg(required = true)]
+        ids: Vec<String>,
+    },
+    /// Export a dashboard definition to a file
+    Export {
+        id: String,
+        #[arg(long, help = "File to write the definition to (.json, .yaml or .yml)")]
+        file: String,
+    },
+    /// Create a dashboard from an exported definition, or overwrite one
+    Import {
+        #[arg(
+            long,
+            help = "Definition file written by `dashboards export` (JSON or YAML)"
+        )]
+        file: String,
+        #[arg(
+            long,
+            help = "Overwrite this existing dashboard instead of creating a new one"
+        )]
+        update: Option<String>,
+    },
+    /// Manage shared dashboards (public, invite-only, and embedded URLs)
+    Shares {
+        #[command(subcommand)]
+        action: DashboardShareActions,
+    },
+}
+
+#[derive(Subcommand)]
+enum DashboardShareActions {
+    /// List shared dashboards
+    List {
+        #[arg(long, help = "Only list shares of this dashboard")]
+        dashboard: Option<String>,
+    },
+    /// Get a shared dashboard by its token
+    Get { token: String },
+    /// Share a dashboard
+    Create {
+        dashboard_id: String,
+        #[arg(
+            long,
+            default_value = "open",
+            help = "Who can view: open (anyone with the URL), invite (invited emails, after signing in), embed (iframe on allowed domains)"
+        )]
+        share_type: String,
+        #[arg(
+            long,
+            help = "Revoke the share automatically after this long, e.g. 30d"
+        )]
+        expires_in: Option<String>,
+        #[arg(
+            long = "invitee",
+            value_name = "EMAIL",
+            help = "Email allowed to view an invite share (repeatable)"
+        )]
+        invitees: Vec<String>,
+        #[arg(
+            long = "embed-domain",
+            value_name = "DOMAIN",
+            help = "Domain allowed to embed an embed share (repeatable)"
+        )]
+        embed_domains: Vec<String>,
+        #[arg(long, help = "Default time frame shown to viewers, e.g. 1h, 1d, 1w")]
+        live_span: Option<String>,
+        #[arg(long, help = "Let viewers change the time frame")]
+        selectable_time: bool,
+    },
+    /// Revoke a shared dashboard URL
+    Revoke { token: String },
 }
 
 // ---- Metrics ----
@@ -2043,8 +3052,13 @@ enum MetricActions {
         filter: Option<String>,
         #[arg(long, help = "Filter metrics by tags (e.g., env:prod,service:api)")]
         tag_filter: Option<String>,
-        #[arg(long, default_value = "1h")]
-        from: String,
+        #[arg(
+            long,
+            alias = "from",
+            default_value = "1h",
+            help = "Lookback window for active metrics (e.g., 1h, 1d, unix timestamp); daily metrics need 1d or more"
+        )]
+        active_since: String,
     },
     /// Search metrics (v1 API)
     Search {
@@ -2079,6 +3093,33 @@ enum MetricActions {
             help = "End time (e.g., now, unix timestamp)"
         )]
         to: String,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
+    },
+    /// Query one reduced value per group (v2 scalar API)
+    Scalar {
+        #[arg(long, help = "Metric query string (required)")]
+        query: String,
+        #[arg(
+            long,
+            default_value = "1h",
+            help = "Start time (e.g., 1h, 30m, 7d, now, unix timestamp)"
+        )]
+        from: String,
+        #[arg(
+            long,
+            default_value = "now",
+            help = "End time (e.g., now, unix timestamp)"
+        )]
+        to: String,
+        #[arg(
+            long,
+            default_value = "avg",
+            help = "Reducer over the window: avg, min, max, sum, last, mean, area, l2norm, percentile"
+        )]
+        aggregator: String,
+        #[arg(long, help = "Check query syntax locally before sending the request")]
+        query_validate: bool,
     },
     /// Submit custom metrics to Datadog
     Submit {
@@ -2088,14 +3129,19 @@ enum MetricActions {
             required_unless_present = "file"
         )]
         name: Option<String>,
-        #[arg(long, default_value_t = 0.0, help = "Metric value (required)")]
-        value: f64,
+        #[arg(
+            long,
+            required_unless_present = "file",
+            allow_negative_numbers = true,
+            help = "Metric value (repeat for each sample with --type=distribution)"
+        )]
+        value: Vec<f64>,
         #[arg(long, help = "Tags (comma-separated)")]
         tags: Option<String>,
         #[arg(
             long,
             default_value = "gauge",
-            help = "Metric type (gauge, count, rate)"
+            help = "Metric type (gauge, count, rate, distribution)"
         )]
         r#type: String,
         #[arg(long, help = "Host name")]
@@ -2106,7 +3152,17 @@ enum MetricActions {
             help = "Interval in seconds for rate/count metrics"
         )]
         interval: i64,
-        #[arg(long, help = "JSON file with metrics data", conflicts_with = "name")]
+        #[arg(
+            long,
+            default_value = "now",
+            help = "Point timestamp (now, relative like 5m, or RFC3339)"
+        )]
+        timestamp: String,
+        #[arg(
+            long,
+            help = "JSON file with series ({\"series\": [...]} or a bare array), or - for stdin; sent gzipped in chunks under the intake limit",
+            conflicts_with = "name"
+        )]
         file: Option<String>,
     },
     /// Manage metric metadata
@@ -2119,6 +3175,122 @@ enum MetricActions {
         #[command(subcommand)]
         action: MetricTagActions,
     },
+    /// List dashboards, monitors, notebooks, and SLOs that use a metric
+    Assets { metric_name: String },
+    /// Show ingested and indexed volumes for a metric, or list them with `volumes list`
+    #[command(args_conflicts_with_subcommands = true, arg_required_else_help = true)]
+    Volumes {
+        metric_name: Option<String>,
+        #[command(subcommand)]
+        action: Option<MetricVolumeActions>,
+    },
+    /// Estimate custom metric cardinality for a tag configuration before applying it
+    Estimate {
+        metric_name: String,
+        #[arg(long, help = "Comma-separated tags that would stay queryable")]
+        tags: Option<String>,
+        #[arg(
+            long,
+            help = "Comma-separated aggregations, as for tag-config (count, rate, and gauge metrics)"
+        )]
+        aggregations: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "aggregations",
+            help = "Include percentile aggregations (distributions only)"
+        )]
+        include_percentiles: bool,
+        #[arg(
+            long,
+            value_parser = clap::value_parser!(i32).range(0..),
+            help = "Hours before now to start the estimate window"
+        )]
+        hours_ago: Option<i32>,
+        #[arg(
+            long,
+            value_parser = clap::value_parser!(i32).range(1..),
+            help = "Length of the estimate window in hours"
+        )]
+        timespan_hours: Option<i32>,
+    },
+    /// Manage tag configurations (which tags stay queryable) for custom metrics
+    TagConfig {
+        #[command(subcommand)]
+        action: MetricTagConfigActions,
+    },
+}
+
+#[derive(Subcommand)]
+enum MetricVolumeActions {
+    /// List volumes of matching metrics, largest first
+    List {
+        #[arg(
+            long,
+            help = "Metric name pattern (* matches any characters, e.g. 'app.*'; '*' for all)"
+        )]
+        filter: String,
+        #[arg(long, default_value_t = 50, help = "Maximum metrics to show")]
+        limit: usize,
+    },
+}
+
+#[derive(Subcommand)]
+enum MetricTagConfigActions {
+    /// Get a metric's tag configuration
+    Get { metric_name: String },
+    /// Create a tag configuration
+    Create {
+        #[arg(required_unless_present = "filter")]
+        metric_name: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "metric_name",
+            help = "Apply to every matching metric instead of one (* matches any characters, e.g. 'app.http.*')"
+        )]
+        filter: Option<String>,
+        #[arg(long, help = "Comma-separated tags to keep queryable")]
+        tags: String,
+        #[arg(
+            long,
+            help = "Comma-separated aggregations: time:space (e.g. sum:max) or a space aggregation (avg, max, min, sum)"
+        )]
+        aggregations: Option<String>,
+        #[arg(
+            long,
+            help = "gauge, count, rate, or distribution (defaults to the type in the metric's metadata)"
+        )]
+        metric_type: Option<String>,
+        #[arg(long, help = "Enable percentile aggregations (distributions only)")]
+        include_percentiles: Option<bool>,
+    },
+    /// Update a tag configuration
+    Update {
+        #[arg(required_unless_present = "filter")]
+        metric_name: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "metric_name",
+            help = "Apply to every matching metric instead of one (* matches any characters, e.g. 'app.http.*')"
+        )]
+        filter: Option<String>,
+        #[arg(long, help = "Comma-separated tags to keep queryable")]
+        tags: Option<String>,
+        #[arg(long, help = "Comma-separated aggregations (see create)")]
+        aggregations: Option<String>,
+        #[arg(long, help = "Enable percentile aggregations (distributions only)")]
+        include_percentiles: Option<bool>,
+    },
+    /// Delete a tag configuration, making all tags queryable again
+    Delete {
+        #[arg(required_unless_present = "filter")]
+        metric_name: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "metric_name",
+            help = "Apply to every matching metric instead of one (* matches any characters, e.g. 'app.http.*')"
+        )]
+        filter: Option<String>,
+    },
 }
 
 #[derive(Subcommand)]
@@ -2161,11 +3333,68 @@ enum SloActions {
     /// List all SLOs
     List,
     /// Get SLO details
-    Get { id: String },
-    /// Create an SLO from JSON file
+    Get {
+        id: String,
+        #[arg(
+            long,
+            help = "Show per-group SLI and error budget for --from/--to, worst first"
+        )]
+        with_sli_breakdown: bool,
+        #[arg(
+            long,
+            default_value = "7d",
+            requires = "with_sli_breakdown",
+            help = "Breakdown start time (7d, 30d, Unix timestamp, or RFC3339)"
+        )]
+        from: String,
+        #[arg(
+            long,
+            default_value = "now",
+            requires = "with_sli_breakdown",
+            help = "Breakdown end time (now, Unix timestamp, or RFC3339)"
+        )]
+        to: String,
+    },
+    /// Create an SLO from a JSON file, or a monitor SLO from an existing monitor
     Create {
-        #[arg(long)]
-        file: String,
+        #[arg(long, required_unless_present = "from_monitor")]
+        file: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "file",
+            requires = "target",
+            help = "Build a monitor-based SLO tracking this monitor ID"
+        )]
+        from_monitor: Option<i64>,
+        #[arg(
+            long,
+            requires = "from_monitor",
+            conflicts_with = "file",
+            help = "Target percentage, between 0 and 100 (e.g. 99.9)"
+        )]
+        target: Option<f64>,
+        #[arg(
+            long,
+            default_value = "30d",
+            requires = "from_monitor",
+            conflicts_with = "file",
+            help = "SLO timeframe: 7d, 30d or 90d"
+        )]
+        timeframe: String,
+        #[arg(
+            long,
+            requires = "from_monitor",
+            conflicts_with = "file",
+            help = "SLO name (defaults to the monitor name)"
+        )]
+        name: Option<String>,
+        #[arg(
+            long,
+            requires = "from_monitor",
+            conflicts_with = "file",
+            help = "SLO description"
+        )]
+        description: Option<String>,
     },
     /// Update an SLO from JSON file
     Update {
@@ -2182,7 +3411,103 @@ enum SloActions {
         from: String,
         #[arg(long, help = "End time (now, Unix timestamp, or RFC3339)")]
         to: String,
+        #[arg(
+            long,
+            help = "Show per-group SLI and error budget for the window, worst first"
+        )]
+        with_sli_breakdown: bool,
+    },
+    /// Report SLI, error budget remaining, and burn rate over a window
+    History {
+        #[arg(required_unless_present = "query", conflicts_with = "query")]
+        id: Option<String>,
+        #[arg(long, help = "Report every SLO whose name matches this query")]
+        query: Option<String>,
+        #[arg(
+            long,
+            default_value = "7d",
+            help = "Start time (7d, 30d, Unix timestamp, or RFC3339)"
+        )]
+        from: String,
+        #[arg(
+            long,
+            default_value = "now",
+            help = "End time (now, Unix timestamp, or RFC3339)"
+        )]
+        to: String,
+    },
+    /// Manage SLO corrections (excluded time windows)
+    Corrections {
+        #[command(subcommand)]
+        action: SloCorrectionActions,
+    },
+}
+
+#[derive(Subcommand)]
+enum SloCorrectionActions {
+    /// List corrections for an SLO
+    List { slo_id: String },
+    /// Create a correction excluding a time window from an SLO
+    Create {
+        slo_id: String,
+        #[arg(long, help = "Start time (1h, 30d, Unix timestamp, or RFC3339)")]
+        from: String,
+        #[arg(
+            long,
+            required_unless_present = "rrule",
+            conflicts_with = "rrule",
+            help = "End time (now, Unix timestamp, or RFC3339)"
+        )]
+        to: Option<String>,
+        #[arg(
+            long,
+            help = "Category: scheduled_maintenance, outside_business_hours, deployment, other"
+        )]
+        category: String,
+        #[arg(long, help = "Description of the correction")]
+        description: Option<String>,
+        #[arg(
+            long,
+            requires = "duration",
+            help = "Repeat the correction from --from, e.g. \"FREQ=WEEKLY;BYDAY=SA\""
+        )]
+        rrule: Option<String>,
+        #[arg(
+            long,
+            requires = "rrule",
+            help = "Length of each recurring correction, e.g. 2h"
+        )]
+        duration: Option<String>,
+    },
+    /// Update a correction's window, category, description, or recurrence
+    Update {
+        correction_id: String,
+        #[arg(long, help = "New start time (1h, 30d, Unix timestamp, or RFC3339)")]
+        from: Option<String>,
+        #[arg(long, help = "New end time (now, Unix timestamp, or RFC3339)")]
+        to: Option<String>,
+        #[arg(
+            long,
+            help = "Category: scheduled_maintenance, outside_business_hours, deployment, other"
+        )]
+        category: Option<String>,
+        #[arg(long, help = "Description of the correction")]
+        description: Option<String>,
+        #[arg(
+            long,
+            requires = "duration",
+            help = "Recurrence rule, e.g. \"FREQ=WEEKLY;BYDAY=SA\""
+        )]
+        rrule: Option<String>,
+        #[arg(
+            long,
+            requires = "rrule",
+            help = "Length of each recurring correction, e.g. 2h"
+        )]
+        duration: Option<String>,
     },
+    /// Delete a correction
+    Delete { correction_id: String },
 }
 
 // ---- Synthetics ----
@@ -2193,6 +3518,45 @@ enum SyntheticsActions {
         #[command(subcommand)]
         action: SyntheticsTestActions,
     },
+    /// Show the latest results of a synthetic test
+    ///
+    /// Use --output=junit to write the results as a JUnit XML report for CI.
+    Results { public_id: String },
+    /// Trigger synthetic tests, optionally waiting for their results
+    ///
+    /// With --wait, polls until every result is in and exits non-zero if a
+    /// blocking test failed, so a pipeline step can gate on it.
+    Run {
+        #[arg(
+            long = "test-id",
+            value_name = "PUBLIC_ID",
+            required_unless_present = "tags",
+            help = "Public ID of a test to run (repeatable)"
+        )]
+        test_ids: Vec<String>,
+        #[arg(
+            long = "tag",
+            value_name = "TAG",
+            help = "Run every test that has all --tag values, e.g. team:web (repeatable)"
+        )]
+        tags: Vec<String>,
+        #[arg(long, help = "Wait for results and fail if a blocking test fails")]
+        wait: bool,
+        #[arg(
+            long,
+            default_value = "30m",
+            requires = "wait",
+            help = "Give up waiting after this long"
+        )]
+        timeout: String,
+        #[arg(
+            long,
+            value_name = "PATH",
+            requires = "wait",
+            help = "Write the results as a JUnit XML report to this file"
+        )]
+        junit_file: Option<String>,
+    },
     /// Manage test locations
     Locations {
         #[command(subcommand)]
@@ -2227,6 +3591,65 @@ enum SyntheticsTestActions {
         #[arg(long, default_value_t = 0)]
         start: i64,
     },
+    /// Create an API or browser test from a JSON definition file
+    Create {
+        #[arg(
+            long,
+            help = "JSON test definition; its \"type\" (api or browser) picks the endpoint"
+        )]
+        file: String,
+    },
+    /// Update a test from a JSON definition file
+    Update {
+        public_id: String,
+        #[arg(long, help = "JSON test definition of the same type as the test")]
+        file: String,
+    },
+    /// Delete one or more tests
+    Delete {
+        #[arg(required = true)]
+        public_ids: Vec<String>,
+    },
+    /// Pause a synthetic test, or with --all every test carrying the given tags
+    Pause {
+        #[arg(required_unless_present = "all")]
+        public_id: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "public_id",
+            requires = "tags",
+            help = "Pause every test that has all --tag values (asks for confirmation)"
+        )]
+        all: bool,
+        #[arg(
+            long = "tag",
+            value_name = "TAG",
+            requires = "all",
+            conflicts_with = "public_id",
+            help = "Tag to match with --all, e.g. team:web (repeatable)"
+        )]
+        tags: Vec<String>,
+    },
+    /// Resume a paused synthetic test, or with --all every test carrying the given tags
+    Resume {
+        #[arg(required_unless_present = "all")]
+        public_id: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "public_id",
+            requires = "tags",
+            help = "Resume every test that has all --tag values (asks for confirmation)"
+        )]
+        all: bool,
+        #[arg(
+            long = "tag",
+            value_name = "TAG",
+            requires = "all",
+            conflicts_with = "public_id",
+            help = "Tag to match with --all, e.g. team:web (repeatable)"
+        )]
+        tags: Vec<String>,
+    },
 }
 
 #[derive(Subcommand)]
@@ -2286,7 +3709,7 @@ enum EventActions {
         #[arg(long, help = "Filter by tags")]
         tags: Option<String>,
     },
-    /// Search events
+    /// Search events (v2 event platform), paging through results up to --limit
     Search {
         #[arg(long, help = "Search query")]
         query: String,
@@ -2294,11 +3717,75 @@ enum EventActions {
         from: String,
         #[arg(long, default_value = "now", help = "End time")]
         to: String,
-        #[arg(long, default_value_t = 100, help = "Maximum results")]
+        #[arg(
+            long,
+            default_value_t = 100,
+            help = "Maximum results; larger limits follow the page cursor"
+        )]
         limit: i32,
     },
     /// Get event details
     Get { event_id: i64 },
+    /// Post an event through the v2 events intake (e.g. deploy or config-change markers)
+    Post {
+        #[arg(long, help = "Event title")]
+        title: String,
+        #[arg(long, help = "Event message")]
+        text: Option<String>,
+        #[arg(
+            long = "tags",
+            value_delimiter = ',',
+            help = "Comma-separated tags, e.g. env:prod,team:web"
+        )]
+        tags: Vec<String>,
+        #[arg(
+            long,
+            default_value = "change",
+            help = "Event category: change or alert"
+        )]
+        category: String,
+        #[arg(
+            long,
+            help = "Alert events: error, warning, info, or success [default: info]"
+        )]
+        alert_type: Option<String>,
+        #[arg(
+            long,
+            help = "Event source, recorded as a source:<name> tag (e.g. github, jenkins)"
+        )]
+        source_type: Option<String>,
+        #[arg(long, help = "Key grouping related events together")]
+        aggregation_key: Option<String>,
+        #[arg(long, help = "Host the event relates to")]
+        host: Option<String>,
+        #[arg(
+            long,
+            help = "Change events: what changed, as NAME or TYPE:NAME (e.g. feature_flag:checkout-v2; type defaults to configuration)"
+        )]
+        changed_resource: Option<String>,
+        #[arg(long, help = "Change events: who made the change")]
+        author: Option<String>,
+        #[arg(
+            long,
+            help = "Change events: previous value (a JSON object or plain text)"
+        )]
+        prev_value: Option<String>,
+        #[arg(long, help = "Change events: new value (a JSON object or plain text)")]
+        new_value: Option<String>,
+        #[arg(
+            long = "impacted-service",
+            value_name = "SERVICE",
+            help = "Change events: service affected by the change (repeatable)"
+        )]
+        impacted_services: Vec<String>,
+    },
+    /// Poll events search and print new events as they arrive (Ctrl-C to stop)
+    Stream {
+        #[arg(long, help = "Search query (e.g., tags:deploy)")]
+        query: String,
+        #[arg(long, default_value = "5s", help = "Poll interval (e.g., 5s, 1m)")]
+        interval: String,
+    },
 }
 
 // ---- Downtime ----
@@ -2308,10 +3795,42 @@ enum DowntimeActions {
     List,
     /// Get downtime details
     Get { id: String },
-    /// Create a downtime from JSON file
+    /// Create a downtime from a JSON file or from schedule flags
     Create {
-        #[arg(long)]
-        file: String,
+        #[arg(long, conflicts_with_all = ["scope", "monitor_id", "monitor_tag", "start", "duration", "rrule", "message"])]
+        file: Option<String>,
+        #[arg(
+            long,
+            required_unless_present = "file",
+            help = "Scope to silence (e.g. \"env:prod\", or \"*\" for everything)"
+        )]
+        scope: Option<String>,
+        #[arg(
+            long,
+            conflicts_with = "monitor_tag",
+            help = "Silence only this monitor"
+        )]
+        monitor_id: Option<i64>,
+        #[arg(
+            long = "monitor-tag",
+            help = "Silence monitors with this tag (repeatable; default all monitors)"
+        )]
+        monitor_tag: Vec<String>,
+        #[arg(long, help = "Start time (RFC3339, default now)")]
+        start: Option<String>,
+        #[arg(
+            long,
+            help = "How long the downtime (or each recurrence) lasts, e.g. 2h; until cancelled if omitted"
+        )]
+        duration: Option<String>,
+        #[arg(
+            long,
+            requires = "duration",
+            help = "Recurrence rule in UTC, e.g. \"FREQ=WEEKLY;BYDAY=SA\""
+        )]
+        rrule: Option<String>,
+        #[arg(long, help = "Message included with notifications")]
+        message: Option<String>,
     },
     /// Cancel a downtime
     Cancel { id: String },
@@ -2330,6 +3849,24 @@ enum TagActions {
     Update { hostname: String, tags: Vec<String> },
     /// Delete all tags from a host
     Delete { hostname: String },
+    /// Add and remove user tags on several hosts concurrently
+    Apply {
+        #[arg(
+            long,
+            value_delimiter = ',',
+            required = true,
+            help = "Comma-separated host names"
+        )]
+        hosts: Vec<String>,
+        #[arg(long, value_delimiter = ',', help = "Comma-separated tags to add")]
+        add: Vec<String>,
+        #[arg(
+            long,
+            value_delimiter = ',',
+            help = "Comma-separated tags to remove (a bare key removes every value)"
+        )]
+        remove: Vec<String>,
+    },
 }
 
 // ---- Users ----
@@ -2370,11 +3907,33 @@ enum InfraHostActions {
         filter: Option<String>,
         #[arg(long, default_value = "status", help = "Sort field")]
         sort: String,
-        #[arg(long, default_value_t = 100, help = "Maximum hosts")]
+        #[arg(
+            long,
+            default_value_t = 100,
+            help = "Maximum hosts (fetched in pages of 1000)"
+        )]
         count: i64,
+        #[arg(
+            long,
+            help = "Only hosts that reported since this time (e.g. 1h, RFC3339)"
+        )]
+        from: Option<String>,
     },
     /// Get host details
     Get { hostname: String },
+    /// Mute a host's monitor notifications
+    Mute {
+        hostname: String,
+        #[arg(
+            long,
+            help = "When the mute ends: a duration from now (e.g. 2h) or RFC3339 time; indefinite if omitted"
+        )]
+        end: Option<String>,
+        #[arg(long, help = "Reason for muting the host")]
+        message: Option<String>,
+    },
+    /// Unmute a host
+    Unmute { hostname: String },
 }
 
 // ---- Audit Logs ----
@@ -2601,6 +4160,11 @@ enum CaseActions {
     Search {
         #[arg(long, help = "Search query")]
         query: Option<String>,
+        #[arg(
+            long,
+            help = "Only cases assigned to this user: 'me', '@handle', or a user id"
+        )]
+        assignee: Option<String>,
         #[arg(long, default_value_t = 10, help = "Results per page")]
         page_size: i64,
         #[arg(long, default_value_t = 0, help = "Page number")]
@@ -2769,6 +4333,24 @@ enum ServiceCatalogActions {
     Get { service_name: String },
 }
 
+// ---- Service Checks ----
+#[derive(Subcommand)]
+enum ServiceCheckActions {
+    /// Submit a check run
+    Submit {
+        #[arg(long, help = "Check name (required)")]
+        check: String,
+        #[arg(long, help = "Check status (ok, warning, critical, unknown)")]
+        status: String,
+        #[arg(long, help = "Host name the check ran on (required)")]
+        host: String,
+        #[arg(long, help = "Tags (comma-separated)")]
+        tags: Option<String>,
+        #[arg(long, help = "Message describing the status")]
+        message: Option<String>,
+    },
+}
+
 // ---- API Keys ----
 #[derive(Subcommand)]
 enum ApiKeyActions {
@@ -2846,6 +4428,18 @@ enum UsageActions {
         #[arg(long, help = "End time (now, YYYY-MM-DD, or RFC3339)")]
         to: Option<String>,
     },
+    /// List custom metrics by hourly volume, largest first
+    #[command(name = "top-metrics")]
+    TopMetrics {
+        #[arg(
+            long,
+            default_value = "7d",
+            help = "Start of the window, at most 31 days ago (1d, 7d, YYYY-MM-DD, or RFC3339)"
+        )]
+        from: String,
+        #[arg(long, default_value_t = 20, help = "Number of metrics to show")]
+        limit: usize,
+    },
 }
 
 // ---- Notebooks ----
@@ -2931,6 +4525,8 @@ enum RumAppActions {
     Get {
         #[arg(help = "Application ID (required)")]
         app_id: String,
+        #[arg(long, help = "Print the SDK initialization snippet for the app")]
+        snippet: bool,
     },
     /// Create a new RUM application
     Create {
@@ -2969,6 +4565,9 @@ enum RumSessionActions {
         to: String,
         #[arg(long, default_value_t = 100)]
         limit: i32,
+        /// Only count the matching events, without fetching them
+        #[arg(long)]
+        count_only: bool,
     },
     /// List RUM sessions
     List {
@@ -2986,7 +4585,15 @@ enum RumMetricActions {
     /// List all RUM custom metrics
     List,
     /// Get RUM custom metric details
-    Get { metric_id: String },
+    Get {
+        metric_id: String,
+        /// Also count the RUM events the metric currently matches
+        #[arg(long)]
+        with_volume: bool,
+        /// Lookback window for --with-volume (e.g., 1h, 24h, 7d)
+        #[arg(long, default_value = "1h", requires = "with_volume")]
+        window: String,
+    },
     /// Create a RUM custom metric
     Create {
         #[arg(long)]
@@ -3634,6 +5241,11 @@ enum JiraActions {
 enum JiraAccountActions {
     /// List Jira accounts
     List,
+    /// Create a Jira account from JSON file
+    Create {
+        #[arg(long, help = "JSON file with request body (required)")]
+        file: String,
+    },
     /// Delete a Jira account
     Delete { account_id: String },
 }
@@ -3905,6 +5517,12 @@ enum ApmEntityActions {
         primary_tag: Option<String>,
         #[arg(long, help = "Entity types (comma-separated)")]
         types: Option<String>,
+        #[arg(
+            long,
+            default_value = "flat",
+            help = "flat (the raw entities) or tree (grouped by type, inferred dependencies under their service)"
+        )]
+        format: String,
     },
 }
 
@@ -4031,6 +5649,9 @@ enum TracesActions {
     ///   pup traces search --query="@http.status_code:>=500"
     ///   pup traces search --query="service:api @duration:>1000000000" --from="4h"
     ///   pup traces search --query="env:prod" --sort="timestamp" --limit=20
+    ///   pup traces search --query="service:api" --limit=5000 | jq '.data[].attributes.trace_id'
+    ///
+    /// More than 1000 spans are fetched page by page through the search cursor.
     #[command(verbatim_doc_comment)]
     Search {
         #[arg(long, default_value = "*", help = "Span search query")]
@@ -4046,7 +5667,7 @@ enum TracesActions {
         #[arg(
             long,
             default_value_t = 50,
-            help = "Maximum number of spans to return (1-1000)"
+            help = "Maximum number of spans to return (paged 1000 at a time)"
         )]
         limit: i32,
         #[arg(
@@ -4061,8 +5682,9 @@ enum TracesActions {
     /// Compute aggregated statistics over spans matching a query.
     ///
     /// Returns computed metrics (count, avg, sum, percentiles, etc.) optionally
-    /// grouped by a facet. Unlike search, this returns statistical buckets, not
-    /// individual spans.
+    /// grouped by one or more facets. Unlike search, this returns statistical
+    /// buckets, not individual spans. With --interval, each compute becomes a
+    /// timeseries instead of a single total.
     ///
     /// COMPUTE FORMATS:
     ///   count                        Count of matching spans
@@ -4077,6 +5699,7 @@ enum TracesActions {
     ///   pup traces aggregate --query="@http.status_code:>=500" --compute="count"
     ///   pup traces aggregate --query="env:prod" --compute="avg(@duration)" --group-by="service"
     ///   pup traces aggregate --query="service:api" --compute="percentile(@duration, 99)" --group-by="resource_name"
+    ///   pup traces aggregate --query="service:api" --compute="median(@duration)" --compute="pc99(@duration)" --group-by="version" --interval=5m
     #[command(verbatim_doc_comment)]
     Aggregate {
         #[arg(long, default_value = "*", help = "Span search query")]
@@ -4091,14 +5714,58 @@ enum TracesActions {
         to: String,
         #[arg(
             long,
-            help = "Aggregation: count, avg(@duration), percentile(@duration, 99), etc."
+            help = "Aggregation: count, avg(@duration), percentile(@duration, 99), etc. Repeatable; defaults to count"
         )]
-        compute: String,
+        compute: Vec<String>,
         #[arg(
             long,
-            help = "Facet to group by (e.g., service, resource_name, @http.status_code)"
+            help = "Bucket size for a timeseries of each compute (e.g. 5m, 1h); totals when omitted"
         )]
-        group_by: Option<String>,
+        interval: Option<String>,
+        #[arg(
+            long,
+            help = "Facet to group by (e.g., service, resource_name, @http.status_code). Repeatable to nest groups"
+        )]
+        group_by: Vec<String>,
+        #[arg(long, default_value_t = 10, help = "Maximum groups per facet")]
+        limit: i32,
+    },
+    /// Fetch every span of a trace
+    ///
+    /// Retrieve all spans sharing a trace ID, in start order.
+    ///
+    /// With --format=tree, spans are arranged by parent/child relationship and
+    /// printed as an indented call tree with each span's service, resource,
+    /// and duration, like the trace flame graph in the UI.
+    ///
+    /// EXAMPLES:
+    ///   pup traces get 1234567890123456789
+    ///   pup traces get 1234567890123456789 --output=tree
+    ///   pup traces get 1234567890123456789 --from=2d --limit=20000
+    #[command(verbatim_doc_comment)]
+    Get {
+        trace_id: String,
+        #[arg(
+            long,
+            default_value = "15d",
+            help = "Start of the window searched for the trace's spans"
+        )]
+        from: String,
+        #[arg(long, default_value = "now", help = "End time")]
+        to: String,
+        #[arg(
+            long,
+            default_value_t = 10000,
+            help = "Maximum number of spans to fetch"
+        )]
+        limit: i32,
+        #[arg(
+            long,
+            default_value = "flat",
+            help = "flat (the spans in start order) or tree (parent/child call tree with durations); \
+                    -o tree also selects the tree, as --output only knows data formats otherwise"
+        )]
+        format: String,
     },
 }
 
@@ -4283,7 +5950,11 @@ enum StaticAnalysisCoverageActions {
 #[derive(Subcommand)]
 enum AuthActions {
     /// Login via OAuth2
-    Login,
+    Login {
+        /// Use the device authorization flow: print a code to enter in a browser on another machine
+        #[arg(long)]
+        device: bool,
+    },
     /// Logout and clear tokens
     Logout,
     /// Check authentication status
@@ -4346,8 +6017,8 @@ fn build_agent_schema_scoped(
             {
                 "name": "--output",
                 "type": "string",
-                "default": "json",
-                "description": "Output format (json, table, yaml)"
+                "default": "table on a terminal, json when piped",
+                "description": "Output format (json, table, yaml, csv, junit, prometheus)"
             },
             {
                 "name": "--yes",
@@ -4460,8 +6131,8 @@ fn build_agent_schema(cmd: &clap::Command) -> serde_json::Value {
             {
                 "name": "--output",
                 "type": "string",
-                "default": "json",
-                "description": "Output format (json, table, yaml)"
+                "default": "table on a terminal, json when piped",
+                "description": "Output format (json, table, yaml, csv, junit, prometheus)"
             },
             {
                 "name": "--yes",
@@ -4697,6 +6368,33 @@ fn build_command_schema(cmd: &clap::Command, parent_path: &str) -> serde_json::V
 
 // ---- Main ----
 
+/// Build `--envelope` request metadata from parsed matches.
+/// Walks to the leaf subcommand for the command path and resolves its
+/// --from/--to values (when present) to RFC3339 timestamps.
+fn build_request_info(matches: &clap::ArgMatches, args: &[String]) -> formatter::RequestInfo {
+    let mut path = Vec::new();
+    let mut leaf = matches;
+    while let Some((name, sub)) = leaf.subcommand() {
+        path.push(name.to_string());
+        leaf = sub;
+    }
+
+    let resolve = |id: &str| -> Option<String> {
+        let raw = leaf.try_get_one::<String>(id).ok().flatten()?;
+        let ms = util::parse_time_to_unix_millis(raw).ok()?;
+        chrono::DateTime::from_timestamp_millis(ms).map(|dt| dt.to_rfc3339())
+    };
+
+    formatter::RequestInfo {
+        command: path.join(" "),
+        args: args.to_vec(),
+        from: resolve("from"),
+        to: resolve("to"),
+        timestamp: chrono::Utc::now().to_rfc3339(),
+        profile: None,
+    }
+}
+
 #[cfg(not(target_arch = "wasm32"))]
 #[tokio::main]
 async fn main() -> anyhow::Result<()> {
@@ -4735,11 +6433,24 @@ async fn main_inner() -> anyhow::Result<()> {
         return Ok(());
     }
 
-    let cli = Cli::parse();
-    let mut cfg = config::Config::from_env()?;
+    let matches = Cli::command().get_matches();
+    let mut cli = Cli::from_arg_matches(&matches).unwrap_or_else(|e| e.exit());
+    // `-o tree` picks the call-tree view of `traces get`. Tree isn't a data
+    // format every command can produce, so it is carried by that command's
+    // --format rather than added to OutputFormat.
+    if cli.output.as_deref() == Some("tree") {
+        if let Commands::Traces {
+            action: TracesActions::Get { format, .. },
+        } = &mut cli.command
+        {
+            *format = "tree".to_string();
+        }
+    }
+    config::set_config_path(cli.config.clone());
+    let mut cfg = config::Config::from_env(cli.site.as_deref())?;
 
     // Apply flag overrides
-    if let Ok(fmt) = cli.output.parse() {
+    if let Some(Ok(fmt)) = cli.output.as_deref().map(str::parse) {
         cfg.output_format = fmt;
     }
     if cli.yes {
@@ -4749,8 +6460,238 @@ async fn main_inner() -> anyhow::Result<()> {
     if cfg.agent_mode {
         cfg.auto_approve = true;
     }
+    if cli.envelope {
+        cfg.envelope = Some(build_request_info(&matches, &args[1..]));
+    }
+    cfg.table.wrap = cli.wrap;
+    cfg.table.width = cli.width;
+    cfg.table.ascii = cli.ascii;
+    cfg.table.inline_array_limit = cli.inline_array_limit;
+    cfg.table.resolve_relationships = cli.resolve_relationships;
+    cfg.table.humanize = cli.humanize;
+    cfg.table.empty_placeholder = cli.empty_placeholder.clone();
+    cfg.table.color = formatter::color_enabled(&cli.color)?;
+    cfg.summary = cli.summary;
+    #[cfg(not(target_arch = "wasm32"))]
+    client::set_max_concurrency(cli.max_concurrency);
+    if let Some(path) = cli.template_file.as_deref() {
+        cfg.template = Some(template::load_file(path)?);
+    }
+
+    if cli.explain {
+        let plan = explain_plan(&cfg, &cli, &matches, &args[1..])?;
+        return formatter::print_block(&plan.render());
+    }
+    if let Some(orgs) = cli.orgs.as_deref() {
+        return run_across_orgs(&cfg, &matches, &args[1..], orgs).await;
+    }
+    run_command(cfg, cli.command).await
+}
+
+/// Build the `--explain` plan for the parsed command. Commands without a
+/// plan are an error rather than an empty plan that looks like success.
+fn explain_plan(
+    cfg: &config::Config,
+    cli: &Cli,
+    matches: &clap::ArgMatches,
+    args: &[String],
+) -> anyhow::Result<plan::Plan> {
+    let info = build_request_info(matches, args);
+    let mut plan = match &cli.command {
+        Commands::Logs {
+            action:
+                LogActions::Search {
+                    query,
+                    from,
+                    to,
+                    limit,
+                    chunk,
+                    export,
+                    storage,
+                    ..
+                },
+        } => commands::logs::search_plan(
+            query,
+            from,
+            to,
+            *limit,
+            chunk.as_deref(),
+            export.as_deref(),
+            storage.as_deref(),
+        )?,
+        Commands::Logs {
+            action:
+                LogActions::List {
+                    query,
+                    from,
+                    to,
+                    limit,
+                    storage,
+                    ..
+                }
+                | LogActions::Query {
+                    query,
+                    from,
+                    to,
+                    limit,
+                    storage,
+                    ..
+                },
+        } => commands::logs::search_plan(query, from, to, *limit, None, None, storage.as_deref())?,
+        Commands::Logs {
+            action:
+                LogActions::Export {
+                    query,
+                    from,
+                    to,
+                    output_file,
+                    ..
+                },
+        } => commands::logs::search_plan(query, from, to, 0, None, Some(output_file), None)?,
+        _ => anyhow::bail!(
+            "--explain has no request plan for `pup {}`; it covers logs search, list, query and export",
+            info.command
+        ),
+    };
+    plan.command = info.command;
+    plan.site = cfg.site.clone();
+    plan.auth = if cfg.has_bearer_token() {
+        "OAuth bearer token"
+    } else if cfg.has_api_keys() {
+        "API + application keys"
+    } else {
+        "none configured"
+    }
+    .to_string();
+    plan.output = cfg.output_format.to_string();
+    if let Some(orgs) = cli.orgs.as_deref() {
+        plan.orgs = orgs
+            .split(',')
+            .map(str::trim)
+            .filter(|o| !o.is_empty())
+            .map(String::from)
+            .collect();
+    }
+    Ok(plan)
+}
+
+/// Run the steps of a `pup batch` file and print one entry per step. The
+/// batch fails if any step failed, after printing what ran.
+async fn run_batch(
+    cfg: &config::Config,
+    file: &str,
+    continue_on_error: bool,
+) -> anyhow::Result<()> {
+    let steps = commands::batch::load_steps(file)?;
+    let blocks = collect_batch_results(cfg, &steps, continue_on_error).await?;
+    if formatter::capturing() {
+        // Under --orgs the step list becomes this profile's result
+        formatter::output(cfg, &blocks)?;
+    } else {
+        formatter::format_and_print(&blocks, &cfg.output_format, cfg.agent_mode, None)?;
+    }
+    let failed = blocks.iter().filter(|b| b.get("error").is_some()).count();
+    if failed > 0 {
+        anyhow::bail!("{failed} of {} batch step(s) failed", steps.len());
+    }
+    Ok(())
+}
+
+async fn collect_batch_results(
+    cfg: &config::Config,
+    steps: &[commands::batch::BatchStep],
+    continue_on_error: bool,
+) -> anyhow::Result<Vec<serde_json::Value>> {
+    let mut blocks = Vec::new();
+    for (i, step) in steps.iter().enumerate() {
+        let outcome = match Cli::command().try_get_matches_from(step.argv()) {
+            Err(e) => Err(e.to_string().trim().to_string()),
+            Ok(matches) => match step_global_flag(&matches) {
+                Some(flag) => Err(format!(
+                    "--{flag} is a global flag and applies to the whole batch; \
+                     pass it to `pup batch` instead"
+                )),
+                None => {
+                    let command = Cli::from_arg_matches(&matches)?.command;
+                    let mut step_cfg = cfg.clone();
+                    step_cfg.envelope = None;
+                    formatter::begin_capture();
+                    let result = Box::pin(run_command(step_cfg, command)).await;
+                    let captured = formatter::end_capture();
+                    result.map(|()| captured).map_err(|e| format!("{e:#}"))
+                }
+            },
+        };
+        let failed = outcome.is_err();
+        blocks.push(commands::batch::step_block(i, step, outcome));
+        if failed && !continue_on_error {
+            break;
+        }
+    }
+    Ok(blocks)
+}
+
+/// The first global flag given on a batch step's own command line. Steps
+/// share the settings passed to `pup batch`, so a per-step global flag is
+/// refused rather than silently ignored.
+fn step_global_flag(matches: &clap::ArgMatches) -> Option<String> {
+    Cli::command()
+        .get_arguments()
+        .filter(|arg| arg.is_global_set())
+        .find(|arg| {
+            matches.value_source(arg.get_id().as_str())
+                == Some(clap::parser::ValueSource::CommandLine)
+        })
+        .map(|arg| arg.get_long().unwrap_or(arg.get_id().as_str()).to_string())
+}
+
+/// Run the command once per `--orgs` profile and print the results as one list
+/// of envelopes tagged with the profile name. A failure in one org is recorded
+/// in its block and does not stop the others.
+async fn run_across_orgs(
+    cfg: &config::Config,
+    matches: &clap::ArgMatches,
+    args: &[String],
+    orgs: &str,
+) -> anyhow::Result<()> {
+    let profiles = config::load_profiles()?;
+    let blocks = collect_org_results(cfg, matches, args, orgs, &profiles).await?;
+    formatter::format_and_print(&blocks, &cfg.output_format, cfg.agent_mode, None)
+}
+
+async fn collect_org_results(
+    cfg: &config::Config,
+    matches: &clap::ArgMatches,
+    args: &[String],
+    orgs: &str,
+    profiles: &std::collections::HashMap<String, config::Profile>,
+) -> anyhow::Result<Vec<serde_json::Value>> {
+    let mut blocks = Vec::new();
+    for name in orgs.split(',').map(str::trim).filter(|n| !n.is_empty()) {
+        let mut info = build_request_info(matches, args);
+        info.profile = Some(name.to_string());
+
+        let outcome = match profiles.get(name) {
+            None => Err(format!("profile {name:?} not found in config file")),
+            Some(profile) => {
+                let mut org_cfg = cfg.with_profile(name, profile);
+                org_cfg.envelope = None;
+                // Re-parse so each run gets its own owned copy of the command
+                let command = Cli::from_arg_matches(matches)?.command;
+                formatter::begin_capture();
+                let result = run_command(org_cfg, command).await;
+                let captured = formatter::end_capture();
+                result.map(|()| captured).map_err(|e| format!("{e:#}"))
+            }
+        };
+        blocks.push(formatter::org_block(&info, outcome)?);
+    }
+    Ok(blocks)
+}
 
-    match cli.command {
+/// Dispatch a parsed command against a fully resolved config.
+async fn run_command(mut cfg: config::Config, command: Commands) -> anyhow::Result<()> {
+    match command {
         // --- Monitors ---
         Commands::Monitors { action } => {
             cfg.validate_auth()?;
@@ -4758,7 +6699,13 @@ async fn main_inner() -> anyhow::Result<()> {
                 MonitorActions::List { name, tags, limit } => {
                     commands::monitors::list(&cfg, name, tags, limit).await?;
                 }
-                MonitorActions::Get { monitor_id } => {
+                MonitorActions::Get {
+                    monitor_id,
+                    related: true,
+                } => {
+                    commands::monitors::get_with_related(&cfg, monitor_id).await?;
+                }
+                MonitorActions::Get { monitor_id, .. } => {
                     commands::monitors::get(&cfg, monitor_id).await?;
                 }
                 MonitorActions::Create { file } => {
@@ -4770,9 +6717,51 @@ async fn main_inner() -> anyhow::Result<()> {
                 MonitorActions::Search { query, .. } => {
                     commands::monitors::search(&cfg, query).await?;
                 }
-                MonitorActions::Delete { monitor_id } => {
-                    commands::monitors::delete(&cfg, monitor_id).await?;
+                MonitorActions::Validate { file } => {
+                    commands::monitors::validate(&cfg, &file).await?;
+                }
+                MonitorActions::Bulk {
+                    query,
+                    set_tag,
+                    resolve,
+                    delete,
+                } => {
+                    commands::monitors::bulk(&cfg, &query, set_tag, resolve, delete).await?;
+                }
+                MonitorActions::Export { query, dir, format } => {
+                    commands::monitors::export(&cfg, &query, &dir, &format).await?;
+                }
+                MonitorActions::Import { dir } => {
+                    commands::monitors::import(&cfg, &dir).await?;
                 }
+                MonitorActions::Delete { monitor_id, query } => match (monitor_id, query) {
+                    (Some(id), _) => commands::monitors::delete(&cfg, id).await?,
+                    (None, Some(q)) => commands::monitors::delete_matching(&cfg, &q).await?,
+                    (None, None) => anyhow::bail!("a monitor id or --query is required"),
+                },
+                MonitorActions::Mute {
+                    monitor_id,
+                    query,
+                    duration,
+                    scope,
+                    ..
+                } => match (monitor_id, query) {
+                    (Some(id), _) => commands::monitors::mute(&cfg, id, duration, scope).await?,
+                    (None, Some(q)) => {
+                        commands::monitors::mute_matching(&cfg, &q, duration, scope).await?
+                    }
+                    (None, None) => anyhow::bail!("a monitor id or --query is required"),
+                },
+                MonitorActions::Unmute {
+                    monitor_id,
+                    query,
+                    scope,
+                    ..
+                } => match (monitor_id, query) {
+                    (Some(id), _) => commands::monitors::unmute(&cfg, id, scope).await?,
+                    (None, Some(q)) => commands::monitors::unmute_matching(&cfg, &q, scope).await?,
+                    (None, None) => anyhow::bail!("a monitor id or --query is required"),
+                },
             }
         }
         // --- Logs ---
@@ -4785,51 +6774,182 @@ async fn main_inner() -> anyhow::Result<()> {
                     to,
                     limit,
                     sort: _,
-                    index: _,
-                    storage: _,
+                    index: _,
+                    storage,
+                    chunk,
+                    highlight,
+                    query_validate,
+                    export,
+                } => {
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
+                    if let Some(path) = export {
+                        commands::logs::export(
+                            &cfg,
+                            query,
+                            from,
+                            to,
+                            &path,
+                            commands::logs::ExportFormat::Ndjson,
+                        )
+                        .await?;
+                        return Ok(());
+                    }
+                    if highlight {
+                        cfg.table.highlight = commands::logs::highlight_terms(&query);
+                    }
+                    commands::logs::search(&cfg, query, from, to, limit, chunk, storage).await?;
+                }
+                LogActions::List {
+                    query,
+                    from,
+                    to,
+                    limit,
+                    sort: _,
+                    storage,
+                    query_validate,
                 } => {
-                    commands::logs::search(&cfg, query, from, to, limit).await?;
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
+                    commands::logs::list(&cfg, query, from, to, limit, storage).await?;
                 }
-                LogActions::List {
+                LogActions::Query {
                     query,
                     from,
                     to,
                     limit,
                     sort: _,
-                    storage: _,
+                    storage,
+                    timezone: _,
+                    query_validate,
                 } => {
-                    commands::logs::list(&cfg, query, from, to, limit).await?;
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
+                    commands::logs::query(&cfg, query, from, to, limit, storage).await?;
                 }
-                LogActions::Query {
+                LogActions::Export {
+                    query,
+                    from,
+                    to,
+                    output_file,
+                    format,
+                    query_validate,
+                } => {
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
+                    let format =
+                        commands::logs::ExportFormat::resolve(format.as_deref(), &output_file)?;
+                    commands::logs::export(&cfg, query, from, to, &output_file, format).await?;
+                }
+                LogActions::Tail {
+                    query,
+                    from,
+                    limit,
+                    follow,
+                    interval,
+                    query_validate,
+                } => {
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
+                    commands::logs::tail(&cfg, query, from, limit, follow, &interval).await?;
+                }
+                LogActions::Analyze {
                     query,
                     from,
                     to,
                     limit,
-                    sort: _,
-                    storage: _,
-                    timezone: _,
+                    top,
+                    query_validate,
                 } => {
-                    commands::logs::query(&cfg, query, from, to, limit).await?;
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
+                    commands::logs::analyze(&cfg, query, from, to, limit, top).await?;
                 }
                 LogActions::Aggregate {
                     query,
                     from,
                     to,
-                    compute: _,
-                    group_by: _,
-                    limit: _,
+                    compute,
+                    percentiles,
+                    field,
+                    interval,
+                    group_by,
+                    limit,
                     storage: _,
                 } => {
-                    commands::logs::aggregate(&cfg, query.unwrap_or_default(), from, to).await?;
+                    commands::logs::aggregate(
+                        &cfg,
+                        query.unwrap_or_default(),
+                        from,
+                        to,
+                        compute,
+                        percentiles,
+                        field,
+                        interval,
+                        group_by,
+                        limit,
+                    )
+                    .await?;
                 }
                 LogActions::Archives { action } => match action {
                     LogArchiveActions::List => commands::logs::archives_list(&cfg).await?,
                     LogArchiveActions::Get { archive_id } => {
                         commands::logs::archives_get(&cfg, &archive_id).await?;
                     }
+                    LogArchiveActions::Create {
+                        file,
+                        name,
+                        query,
+                        dest_type,
+                        rehydration_tags,
+                        include_tags,
+                    } => {
+                        let base = file.as_deref().map(util::read_json_file).transpose()?;
+                        let body = commands::logs::archive_body(
+                            base,
+                            name.as_deref(),
+                            query.as_deref(),
+                            dest_type.as_deref(),
+                            rehydration_tags.as_deref(),
+                            include_tags,
+                        )?;
+                        commands::logs::archives_create(&cfg, body).await?;
+                    }
+                    LogArchiveActions::Update {
+                        archive_id,
+                        file,
+                        name,
+                        query,
+                        dest_type,
+                        rehydration_tags,
+                        include_tags,
+                    } => {
+                        let base = match file.as_deref() {
+                            Some(file) => util::read_json_file(file)?,
+                            None => commands::logs::archives_current(&cfg, &archive_id).await?,
+                        };
+                        let body = commands::logs::archive_body(
+                            Some(base),
+                            name.as_deref(),
+                            query.as_deref(),
+                            dest_type.as_deref(),
+                            rehydration_tags.as_deref(),
+                            include_tags,
+                        )?;
+                        commands::logs::archives_update(&cfg, &archive_id, body).await?;
+                    }
                     LogArchiveActions::Delete { archive_id } => {
                         commands::logs::archives_delete(&cfg, &archive_id).await?;
                     }
+                    LogArchiveActions::Order { archive_ids } => {
+                        commands::logs::archives_order(&cfg, &archive_ids).await?;
+                    }
                 },
                 LogActions::CustomDestinations { action } => match action {
                     LogCustomDestinationActions::List => {
@@ -4838,16 +6958,245 @@ async fn main_inner() -> anyhow::Result<()> {
                     LogCustomDestinationActions::Get { destination_id } => {
                         commands::logs::custom_destinations_get(&cfg, &destination_id).await?;
                     }
+                    LogCustomDestinationActions::Create {
+                        file,
+                        name,
+                        query,
+                        dest_type,
+                        enabled,
+                        endpoint,
+                        username,
+                        password,
+                        header_name,
+                        header_value,
+                    } => {
+                        let mut base = file.as_deref().map(util::read_json_file).transpose()?;
+                        if let Some(endpoint) = endpoint {
+                            let http = commands::logs::HttpForwarder {
+                                endpoint,
+                                username,
+                                password,
+                                header_name,
+                                header_value,
+                            };
+                            base = Some(commands::logs::with_http_forwarder(base, &http)?);
+                        }
+                        let body = commands::logs::custom_destination_body(
+                            base,
+                            None,
+                            name.as_deref(),
+                            query.as_deref(),
+                            dest_type.as_deref(),
+                            enabled,
+                        )?;
+                        commands::logs::custom_destinations_create(&cfg, body).await?;
+                    }
+                    LogCustomDestinationActions::Update {
+                        destination_id,
+                        file,
+                        name,
+                        query,
+                        dest_type,
+                        enabled,
+                        endpoint,
+                        username,
+                        password,
+                        header_name,
+                        header_value,
+                    } => {
+                        let mut base = file.as_deref().map(util::read_json_file).transpose()?;
+                        if let Some(endpoint) = endpoint {
+                            let http = commands::logs::HttpForwarder {
+                                endpoint,
+                                username,
+                                password,
+                                header_name,
+                                header_value,
+                            };
+                            base = Some(commands::logs::with_http_forwarder(base, &http)?);
+                        }
+                        let body = commands::logs::custom_destination_body(
+                            base,
+                            Some(&destination_id),
+                            name.as_deref(),
+                            query.as_deref(),
+                            dest_type.as_deref(),
+                            enabled,
+                        )?;
+                        commands::logs::custom_destinations_update(&cfg, &destination_id, body)
+                            .await?;
+                    }
+                    LogCustomDestinationActions::Delete { destination_id } => {
+                        if !util::confirm(
+                            &cfg,
+                            &format!("Delete custom destination {destination_id}?"),
+                        )? {
+                            return Ok(());
+                        }
+                        commands::logs::custom_destinations_delete(&cfg, &destination_id).await?;
+                    }
                 },
                 LogActions::Metrics { action } => match action {
                     LogMetricActions::List => commands::logs::metrics_list(&cfg).await?,
-                    LogMetricActions::Get { metric_id } => {
-                        commands::logs::metrics_get(&cfg, &metric_id).await?;
+                    LogMetricActions::Get {
+                        metric_id,
+                        with_volume,
+                        window,
+                    } => {
+                        let volume_window = with_volume.then_some(window.as_str());
+                        commands::logs::metrics_get(&cfg, &metric_id, volume_window).await?;
+                    }
+                    LogMetricActions::Create {
+                        name,
+                        file,
+                        query,
+                        group_by,
+                        include_percentiles,
+                        compute,
+                        path,
+                    } => {
+                        let base = file.as_deref().map(util::read_json_file).transpose()?;
+                        let flags = commands::logs::LogMetricFlags {
+                            query,
+                            compute,
+                            path,
+                            group_by,
+                            include_percentiles,
+                        };
+                        let body = commands::logs::log_metric_body(base, Some(&name), &flags)?;
+                        commands::logs::metrics_create(&cfg, body).await?;
+                    }
+                    LogMetricActions::Update {
+                        metric_id,
+                        file,
+                        query,
+                        group_by,
+                        include_percentiles,
+                    } => {
+                        let base = file.as_deref().map(util::read_json_file).transpose()?;
+                        let flags = commands::logs::LogMetricFlags {
+                            query,
+                            group_by,
+                            include_percentiles,
+                            ..Default::default()
+                        };
+                        let body = commands::logs::log_metric_body(base, None, &flags)?;
+                        commands::logs::metrics_update(&cfg, &metric_id, body).await?;
                     }
                     LogMetricActions::Delete { metric_id } => {
                         commands::logs::metrics_delete(&cfg, &metric_id).await?;
                     }
                 },
+                LogActions::Facets { action } => match action {
+                    LogFacetActions::List => commands::logs::facets_list(&cfg).await?,
+                },
+                LogActions::Indexes { action } => match action {
+                    LogIndexActions::List => commands::logs::indexes_list(&cfg).await?,
+                    LogIndexActions::Get { name } => {
+                        commands::logs::indexes_get(&cfg, &name).await?;
+                    }
+                    LogIndexActions::Create {
+                        name,
+                        file,
+                        filter,
+                        daily_limit,
+                        no_daily_limit,
+                        retention_days,
+                        exclusion_name,
+                        exclusion_query,
+                        exclusion_sample_rate,
+                        exclusion_disabled,
+                    } => {
+                        let changes = commands::logs::IndexChanges {
+                            filter,
+                            daily_limit,
+                            no_daily_limit,
+                            retention_days,
+                            exclusion: exclusion_name.zip(exclusion_query).map(|(name, query)| {
+                                commands::logs::ExclusionFilter {
+                                    name,
+                                    query,
+                                    sample_rate: exclusion_sample_rate,
+                                    enabled: !exclusion_disabled,
+                                }
+                            }),
+                            remove_exclusions: Vec::new(),
+                        };
+                        let base = file.as_deref().map(util::read_json_file).transpose()?;
+                        let body = commands::logs::index_body(base, Some(&name), &changes)?;
+                        commands::logs::indexes_create(&cfg, body).await?;
+                    }
+                    LogIndexActions::Update {
+                        name,
+                        file,
+                        filter,
+                        daily_limit,
+                        no_daily_limit,
+                        retention_days,
+                        exclusion_name,
+                        exclusion_query,
+                        exclusion_sample_rate,
+                        exclusion_disabled,
+                        remove_exclusion,
+                    } => {
+                        let changes = commands::logs::IndexChanges {
+                            filter,
+                            daily_limit,
+                            no_daily_limit,
+                            retention_days,
+                            exclusion: exclusion_name.zip(exclusion_query).map(|(name, query)| {
+                                commands::logs::ExclusionFilter {
+                                    name,
+                                    query,
+                                    sample_rate: exclusion_sample_rate,
+                                    enabled: !exclusion_disabled,
+                                }
+                            }),
+                            remove_exclusions: remove_exclusion,
+                        };
+                        let base = file.as_deref().map(util::read_json_file).transpose()?;
+                        commands::logs::indexes_update(&cfg, &name, base, &changes).await?;
+                    }
+                    LogIndexActions::Delete { name } => {
+                        if !util::confirm(&cfg, &format!("Delete log index {name}?"))? {
+                            return Ok(());
+                        }
+                        commands::logs::indexes_delete(&cfg, &name).await?;
+                    }
+                    LogIndexActions::Order { names } => {
+                        commands::logs::indexes_order(&cfg, &names).await?;
+                    }
+                },
+                LogActions::Pipelines { action } => match action {
+                    LogPipelineActions::List => commands::logs::pipelines_list(&cfg).await?,
+                    LogPipelineActions::Get { pipeline_id } => {
+                        commands::logs::pipelines_get(&cfg, &pipeline_id).await?;
+                    }
+                    LogPipelineActions::Create { file } => {
+                        commands::logs::pipelines_create(&cfg, &file).await?;
+                    }
+                    LogPipelineActions::Update { pipeline_id, file } => {
+                        commands::logs::pipelines_update(&cfg, &pipeline_id, &file).await?;
+                    }
+                    LogPipelineActions::Delete { pipeline_id } => {
+                        if !util::confirm(&cfg, &format!("Delete log pipeline {pipeline_id}?"))? {
+                            return Ok(());
+                        }
+                        commands::logs::pipelines_delete(&cfg, &pipeline_id).await?;
+                    }
+                    LogPipelineActions::Reorder { pipeline_ids } => {
+                        commands::logs::pipelines_reorder(&cfg, &pipeline_ids).await?;
+                    }
+                },
+                LogActions::Rehydrations { action } => {
+                    let command = match action {
+                        LogRehydrationActions::List => "list",
+                        LogRehydrationActions::Get { .. } => "get",
+                        LogRehydrationActions::Create { .. } => "create",
+                        LogRehydrationActions::Cancel { .. } => "cancel",
+                    };
+                    commands::logs::rehydrations_unavailable(&cfg, command)?;
+                }
                 LogActions::RestrictionQueries { action } => match action {
                     LogRestrictionQueryActions::List => {
                         commands::logs::restriction_queries_list(&cfg).await?;
@@ -4855,6 +7204,26 @@ async fn main_inner() -> anyhow::Result<()> {
                     LogRestrictionQueryActions::Get { query_id } => {
                         commands::logs::restriction_queries_get(&cfg, &query_id).await?;
                     }
+                    LogRestrictionQueryActions::Create { query } => {
+                        commands::logs::restriction_queries_create(&cfg, &query).await?;
+                    }
+                    LogRestrictionQueryActions::Update { query_id, query } => {
+                        commands::logs::restriction_queries_update(&cfg, &query_id, &query).await?;
+                    }
+                    LogRestrictionQueryActions::Delete { query_id } => {
+                        if !util::confirm(&cfg, &format!("Delete restriction query {query_id}?"))? {
+                            return Ok(());
+                        }
+                        commands::logs::restriction_queries_delete(&cfg, &query_id).await?;
+                    }
+                    LogRestrictionQueryActions::GrantRole { query_id, role_id } => {
+                        commands::logs::restriction_queries_grant_role(&cfg, &query_id, &role_id)
+                            .await?;
+                    }
+                    LogRestrictionQueryActions::RevokeRole { query_id, role_id } => {
+                        commands::logs::restriction_queries_revoke_role(&cfg, &query_id, &role_id)
+                            .await?;
+                    }
                 },
             }
         }
@@ -4865,8 +7234,16 @@ async fn main_inner() -> anyhow::Result<()> {
                 IncidentActions::List { limit } => {
                     commands::incidents::list(&cfg, limit).await?;
                 }
-                IncidentActions::Get { incident_id } => {
-                    commands::incidents::get(&cfg, &incident_id).await?;
+                IncidentActions::Get {
+                    incident_id,
+                    postmortem,
+                    redact,
+                } => {
+                    if postmortem {
+                        commands::incidents::postmortem(&cfg, &incident_id, redact).await?;
+                    } else {
+                        commands::incidents::get(&cfg, &incident_id).await?;
+                    }
                 }
                 IncidentActions::Attachments { action } => match action {
                     IncidentAttachmentActions::List { incident_id } => {
@@ -4880,6 +7257,25 @@ async fn main_inner() -> anyhow::Result<()> {
                             .await?;
                     }
                 },
+                IncidentActions::Todos { action } => match action {
+                    IncidentTodoActions::List { incident_id } => {
+                        commands::incidents::todos_list(&cfg, &incident_id).await?;
+                    }
+                    IncidentTodoActions::Add {
+                        incident_id,
+                        content,
+                        assignees,
+                    } => {
+                        commands::incidents::todos_add(&cfg, &incident_id, &content, &assignees)
+                            .await?;
+                    }
+                    IncidentTodoActions::Complete {
+                        incident_id,
+                        todo_id,
+                    } => {
+                        commands::incidents::todos_complete(&cfg, &incident_id, &todo_id).await?;
+                    }
+                },
                 IncidentActions::Settings { action } => match action {
                     IncidentSettingsActions::Get => {
                         commands::incidents::settings_get(&cfg).await?;
@@ -4892,11 +7288,14 @@ async fn main_inner() -> anyhow::Result<()> {
                     IncidentHandleActions::List => {
                         commands::incidents::handles_list(&cfg).await?;
                     }
+                    IncidentHandleActions::Get { handle_id } => {
+                        commands::incidents::handles_get(&cfg, &handle_id).await?;
+                    }
                     IncidentHandleActions::Create { file } => {
                         commands::incidents::handles_create(&cfg, &file).await?;
                     }
-                    IncidentHandleActions::Update { file } => {
-                        commands::incidents::handles_update(&cfg, &file).await?;
+                    IncidentHandleActions::Update { handle_id, file } => {
+                        commands::incidents::handles_update(&cfg, &handle_id, &file).await?;
                     }
                     IncidentHandleActions::Delete { handle_id } => {
                         commands::incidents::handles_delete(&cfg, &handle_id).await?;
@@ -4927,7 +7326,9 @@ async fn main_inner() -> anyhow::Result<()> {
         Commands::Dashboards { action } => {
             cfg.validate_auth()?;
             match action {
-                DashboardActions::List => commands::dashboards::list(&cfg).await?,
+                DashboardActions::List { deleted } => {
+                    commands::dashboards::list(&cfg, deleted).await?;
+                }
                 DashboardActions::Get { id } => commands::dashboards::get(&cfg, &id).await?,
                 DashboardActions::Create { file } => {
                     commands::dashboards::create(&cfg, &file).await?;
@@ -4936,26 +7337,110 @@ async fn main_inner() -> anyhow::Result<()> {
                     commands::dashboards::update(&cfg, &id, &file).await?;
                 }
                 DashboardActions::Delete { id } => commands::dashboards::delete(&cfg, &id).await?,
+                DashboardActions::Restore { ids } => {
+                    commands::dashboards::restore(&cfg, &ids).await?;
+                }
+                DashboardActions::Export { id, file } => {
+                    commands::dashboards::export(&cfg, &id, &file).await?;
+                }
+                DashboardActions::Import { file, update } => {
+                    commands::dashboards::import(&cfg, &file, update.as_deref()).await?;
+                }
+                DashboardActions::Shares { action } => match action {
+                    DashboardShareActions::List { dashboard } => {
+                        commands::dashboards::shares_list(&cfg, dashboard.as_deref()).await?;
+                    }
+                    DashboardShareActions::Get { token } => {
+                        commands::dashboards::shares_get(&cfg, &token).await?;
+                    }
+                    DashboardShareActions::Create {
+                        dashboard_id,
+                        share_type,
+                        expires_in,
+                        invitees,
+                        embed_domains,
+                        live_span,
+                        selectable_time,
+                    } => {
+                        let opts = commands::dashboards::ShareOptions {
+                            share_type,
+                            expires_in,
+                            invitees,
+                            embeddable_domains: embed_domains,
+                            live_span,
+                            selectable_time,
+                        };
+                        commands::dashboards::shares_create(&cfg, &dashboard_id, &opts).await?;
+                    }
+                    DashboardShareActions::Revoke { token } => {
+                        if !util::confirm(&cfg, &format!("Revoke shared dashboard {token}?"))? {
+                            return Ok(());
+                        }
+                        commands::dashboards::shares_revoke(&cfg, &token).await?;
+                    }
+                },
             }
         }
         // --- Metrics ---
         Commands::Metrics { action } => {
             cfg.validate_auth()?;
             match action {
-                MetricActions::List { filter, from, .. } => {
-                    commands::metrics::list(&cfg, filter, from).await?;
+                MetricActions::List {
+                    filter,
+                    active_since,
+                    ..
+                } => {
+                    commands::metrics::list(&cfg, filter, active_since).await?;
                 }
                 MetricActions::Search { query, from, to } => {
                     commands::metrics::search(&cfg, query, from, to).await?;
                 }
-                MetricActions::Query { query, from, to } => {
+                MetricActions::Query {
+                    query,
+                    from,
+                    to,
+                    query_validate,
+                } => {
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
                     commands::metrics::query(&cfg, query, from, to).await?;
                 }
-                MetricActions::Submit { file, .. } => {
+                MetricActions::Scalar {
+                    query,
+                    from,
+                    to,
+                    aggregator,
+                    query_validate,
+                } => {
+                    if query_validate {
+                        util::validate_query(&query)?;
+                    }
+                    commands::metrics::scalar(&cfg, query, from, to, aggregator).await?;
+                }
+                MetricActions::Submit {
+                    name,
+                    value,
+                    tags,
+                    r#type,
+                    host,
+                    interval,
+                    timestamp,
+                    file,
+                } => {
                     if let Some(f) = file {
                         commands::metrics::submit(&cfg, &f).await?;
                     } else {
-                        anyhow::bail!("flag-based submit not yet implemented; use --file");
+                        let point = commands::metrics::SubmitPoint {
+                            name: name.expect("clap requires --name without --file"),
+                            values: value,
+                            metric_type: r#type,
+                            tags,
+                            host,
+                            interval,
+                            timestamp,
+                        };
+                        commands::metrics::submit_point(&cfg, point).await?;
                     }
                 }
                 MetricActions::Metadata { action } => match action {
@@ -4979,6 +7464,129 @@ async fn main_inner() -> anyhow::Result<()> {
                         commands::metrics::tags_list(&cfg, &metric_name).await?;
                     }
                 },
+                MetricActions::Assets { metric_name } => {
+                    commands::metrics::assets(&cfg, &metric_name).await?;
+                }
+                MetricActions::Volumes {
+                    action: Some(MetricVolumeActions::List { filter, limit }),
+                    ..
+                } => {
+                    commands::metrics::volumes_list(&cfg, &filter, limit).await?;
+                }
+                MetricActions::Volumes {
+                    metric_name: Some(metric_name),
+                    ..
+                } => {
+                    commands::metrics::volumes_get(&cfg, &metric_name).await?;
+                }
+                MetricActions::Volumes { .. } => {
+                    anyhow::bail!("pass a metric name, or use `metrics volumes list --filter`");
+                }
+                MetricActions::Estimate {
+                    metric_name,
+                    tags,
+                    aggregations,
+                    include_percentiles,
+                    hours_ago,
+                    timespan_hours,
+                } => {
+                    let opts = commands::metrics::EstimateOptions {
+                        tags,
+                        aggregations,
+                        include_percentiles,
+                        hours_ago,
+                        timespan_hours,
+                    };
+                    commands::metrics::estimate(&cfg, &metric_name, opts).await?;
+                }
+                MetricActions::TagConfig { action } => match action {
+                    MetricTagConfigActions::Get { metric_name } => {
+                        commands::metrics::tag_config_get(&cfg, &metric_name).await?;
+                    }
+                    MetricTagConfigActions::Create {
+                        metric_name,
+                        filter,
+                        tags,
+                        aggregations,
+                        metric_type,
+                        include_percentiles,
+                    } => {
+                        let opts = commands::metrics::TagConfigOptions {
+                            tags: Some(tags),
+                            aggregations,
+                            metric_type,
+                            include_percentiles,
+                        };
+                        match (metric_name, filter) {
+                            (_, Some(pattern)) => {
+                                commands::metrics::tag_config_matching(
+                                    &cfg,
+                                    &pattern,
+                                    commands::metrics::TagConfigAction::Create,
+                                    opts,
+                                )
+                                .await?;
+                            }
+                            (Some(metric_name), None) => {
+                                commands::metrics::tag_config_create(&cfg, &metric_name, opts)
+                                    .await?;
+                            }
+                            (None, None) => unreachable!("clap requires a metric or --filter"),
+                        }
+                    }
+                    MetricTagConfigActions::Update {
+                        metric_name,
+                        filter,
+                        tags,
+                        aggregations,
+                        include_percentiles,
+                    } => {
+                        let opts = commands::metrics::TagConfigOptions {
+                            tags,
+                            aggregations,
+                            metric_type: None,
+                            include_percentiles,
+                        };
+                        match (metric_name, filter) {
+                            (_, Some(pattern)) => {
+                                commands::metrics::tag_config_matching(
+                                    &cfg,
+                                    &pattern,
+                                    commands::metrics::TagConfigAction::Update,
+                                    opts,
+                                )
+                                .await?;
+                            }
+                            (Some(metric_name), None) => {
+                                commands::metrics::tag_config_update(&cfg, &metric_name, opts)
+                                    .await?;
+                            }
+                            (None, None) => unreachable!("clap requires a metric or --filter"),
+                        }
+                    }
+                    MetricTagConfigActions::Delete {
+                        metric_name: None,
+                        filter: Some(pattern),
+                    } => {
+                        commands::metrics::tag_config_matching(
+                            &cfg,
+                            &pattern,
+                            commands::metrics::TagConfigAction::Delete,
+                            commands::metrics::TagConfigOptions::default(),
+                        )
+                        .await?;
+                    }
+                    MetricTagConfigActions::Delete { metric_name, .. } => {
+                        let metric_name = metric_name.expect("clap requires a metric or --filter");
+                        if !util::confirm(
+                            &cfg,
+                            &format!("Delete the tag configuration for {metric_name}?"),
+                        )? {
+                            return Ok(());
+                        }
+                        commands::metrics::tag_config_delete(&cfg, &metric_name).await?;
+                    }
+                },
             }
         }
         // --- SLOs ---
@@ -4986,17 +7594,124 @@ async fn main_inner() -> anyhow::Result<()> {
             cfg.validate_auth()?;
             match action {
                 SloActions::List => commands::slos::list(&cfg).await?,
-                SloActions::Get { id } => commands::slos::get(&cfg, &id).await?,
-                SloActions::Create { file } => commands::slos::create(&cfg, &file).await?,
+                SloActions::Get {
+                    id,
+                    with_sli_breakdown,
+                    from,
+                    to,
+                } => {
+                    let breakdown = if with_sli_breakdown {
+                        let from_ts = util::parse_time_to_unix_millis(&from)? / 1000;
+                        let to_ts = util::parse_time_to_unix_millis(&to)? / 1000;
+                        Some((from_ts, to_ts))
+                    } else {
+                        None
+                    };
+                    commands::slos::get(&cfg, &id, breakdown).await?
+                }
+                SloActions::Create {
+                    file,
+                    from_monitor,
+                    target,
+                    timeframe,
+                    name,
+                    description,
+                } => match (from_monitor, file) {
+                    (Some(monitor_id), _) => {
+                        let opts = commands::slos::FromMonitor {
+                            monitor_id,
+                            target: target.unwrap_or_default(),
+                            timeframe,
+                            name,
+                            description,
+                        };
+                        commands::slos::create_from_monitor(&cfg, opts).await?
+                    }
+                    (None, Some(file)) => commands::slos::create(&cfg, &file).await?,
+                    (None, None) => unreachable!("clap requires --file or --from-monitor"),
+                },
                 SloActions::Update { id, file } => {
                     commands::slos::update(&cfg, &id, &file).await?;
                 }
                 SloActions::Delete { id } => commands::slos::delete(&cfg, &id).await?,
-                SloActions::Status { id, from, to } => {
+                SloActions::Status {
+                    id,
+                    from,
+                    to,
+                    with_sli_breakdown,
+                } => {
+                    let from_ts = util::parse_time_to_unix_millis(&from)? / 1000;
+                    let to_ts = util::parse_time_to_unix_millis(&to)? / 1000;
+                    commands::slos::status(&cfg, &id, from_ts, to_ts, with_sli_breakdown).await?;
+                }
+                SloActions::History {
+                    id,
+                    query,
+                    from,
+                    to,
+                } => {
                     let from_ts = util::parse_time_to_unix_millis(&from)? / 1000;
                     let to_ts = util::parse_time_to_unix_millis(&to)? / 1000;
-                    commands::slos::status(&cfg, &id, from_ts, to_ts).await?;
+                    commands::slos::history(&cfg, id.as_deref(), query.as_deref(), from_ts, to_ts)
+                        .await?;
                 }
+                SloActions::Corrections { action } => match action {
+                    SloCorrectionActions::List { slo_id } => {
+                        commands::slos::corrections_list(&cfg, &slo_id).await?;
+                    }
+                    SloCorrectionActions::Create {
+                        slo_id,
+                        from,
+                        to,
+                        category,
+                        description,
+                        rrule,
+                        duration,
+                    } => {
+                        let start = util::parse_time_to_unix_millis(&from)? / 1000;
+                        let end = to
+                            .map(|to| util::parse_time_to_unix_millis(&to).map(|ms| ms / 1000))
+                            .transpose()?;
+                        let recurrence = commands::slos::parse_recurrence(rrule, duration)?;
+                        commands::slos::corrections_create(
+                            &cfg,
+                            &slo_id,
+                            start,
+                            end,
+                            &category,
+                            description.as_deref(),
+                            recurrence.as_ref(),
+                        )
+                        .await?;
+                    }
+                    SloCorrectionActions::Update {
+                        correction_id,
+                        from,
+                        to,
+                        category,
+                        description,
+                        rrule,
+                        duration,
+                    } => {
+                        let to_secs =
+                            |t: String| util::parse_time_to_unix_millis(&t).map(|ms| ms / 1000);
+                        let update = commands::slos::CorrectionUpdate {
+                            start: from.map(to_secs).transpose()?,
+                            end: to.map(to_secs).transpose()?,
+                            category,
+                            description,
+                            recurrence: commands::slos::parse_recurrence(rrule, duration)?,
+                        };
+                        commands::slos::corrections_update(&cfg, &correction_id, &update).await?;
+                    }
+                    SloCorrectionActions::Delete { correction_id } => {
+                        if !util::confirm(&cfg, &format!("Delete SLO correction {correction_id}?"))?
+                        {
+                            return Ok(());
+                        }
+                        commands::slos::corrections_delete(&cfg, &correction_id).await?;
+                    }
+                },
             }
         }
         // --- Synthetics ---
@@ -5013,7 +7728,63 @@ async fn main_inner() -> anyhow::Result<()> {
                     SyntheticsTestActions::Search { text, count, start } => {
                         commands::synthetics::tests_search(&cfg, text, count, start).await?;
                     }
+                    SyntheticsTestActions::Create { file } => {
+                        commands::synthetics::tests_create(&cfg, &file).await?;
+                    }
+                    SyntheticsTestActions::Update { public_id, file } => {
+                        commands::synthetics::tests_update(&cfg, &public_id, &file).await?;
+                    }
+                    SyntheticsTestActions::Delete { public_ids } => {
+                        let prompt = format!("Delete synthetic test(s) {}?", public_ids.join(", "));
+                        if !util::confirm(&cfg, &prompt)? {
+                            return Ok(());
+                        }
+                        commands::synthetics::tests_delete(&cfg, &public_ids).await?;
+                    }
+                    SyntheticsTestActions::Pause {
+                        public_id, tags, ..
+                    } => {
+                        let status = commands::synthetics::TestStatus::Paused;
+                        match public_id {
+                            Some(id) => commands::synthetics::set_status(&cfg, &id, status).await?,
+                            None => {
+                                commands::synthetics::set_status_matching(&cfg, &tags, status)
+                                    .await?
+                            }
+                        }
+                    }
+                    SyntheticsTestActions::Resume {
+                        public_id, tags, ..
+                    } => {
+                        let status = commands::synthetics::TestStatus::Live;
+                        match public_id {
+                            Some(id) => commands::synthetics::set_status(&cfg, &id, status).await?,
+                            None => {
+                                commands::synthetics::set_status_matching(&cfg, &tags, status)
+                                    .await?
+                            }
+                        }
+                    }
                 },
+                SyntheticsActions::Results { public_id } => {
+                    commands::synthetics::results(&cfg, &public_id).await?;
+                }
+                SyntheticsActions::Run {
+                    test_ids,
+                    tags,
+                    wait,
+                    timeout,
+                    junit_file,
+                } => {
+                    let opts = commands::synthetics::RunOptions {
+                        test_ids,
+                        tags,
+                        wait,
+                        timeout,
+                        junit_file,
+                    };
+                    commands::synthetics::run(&cfg, &opts).await?;
+                }
                 SyntheticsActions::Locations { action } => match action {
                     SyntheticsLocationActions::List => {
                         commands::synthetics::locations_list(&cfg).await?;
@@ -5058,6 +7829,41 @@ async fn main_inner() -> anyhow::Result<()> {
                 EventActions::Get { event_id } => {
                     commands::events::get(&cfg, event_id).await?;
                 }
+                EventActions::Post {
+                    title,
+                    text,
+                    tags,
+                    category,
+                    alert_type,
+                    source_type,
+                    aggregation_key,
+                    host,
+                    changed_resource,
+                    author,
+                    prev_value,
+                    new_value,
+                    impacted_services,
+                } => {
+                    let event = commands::events::EventPost {
+                        title,
+                        text,
+                        tags,
+                        category,
+                        alert_type,
+                        source_type,
+                        aggregation_key,
+                        host,
+                        changed_resource,
+                        author,
+                        prev_value,
+                        new_value,
+                        impacted_services,
+                    };
+                    commands::events::post(&cfg, &event).await?;
+                }
+                EventActions::Stream { query, interval } => {
+                    commands::events::stream(&cfg, query, &interval).await?;
+                }
             }
         }
         // --- Downtime ---
@@ -5066,9 +7872,31 @@ async fn main_inner() -> anyhow::Result<()> {
             match action {
                 DowntimeActions::List => commands::downtime::list(&cfg).await?,
                 DowntimeActions::Get { id } => commands::downtime::get(&cfg, &id).await?,
-                DowntimeActions::Create { file } => {
-                    commands::downtime::create(&cfg, &file).await?;
-                }
+                DowntimeActions::Create {
+                    file,
+                    scope,
+                    monitor_id,
+                    monitor_tag,
+                    start,
+                    duration,
+                    rrule,
+                    message,
+                } => match (file, scope) {
+                    (Some(file), _) => commands::downtime::create_from_file(&cfg, &file).await?,
+                    (None, Some(scope)) => {
+                        let schedule = commands::downtime::DowntimeSchedule {
+                            scope,
+                            monitor_id,
+                            monitor_tags: monitor_tag,
+                            start,
+                            duration,
+                            rrule,
+                            message,
+                        };
+                        commands::downtime::create_scheduled(&cfg, &schedule).await?;
+                    }
+                    (None, None) => anyhow::bail!("--file or --scope is required"),
+                },
                 DowntimeActions::Cancel { id } => commands::downtime::cancel(&cfg, &id).await?,
             }
         }
@@ -5087,6 +7915,9 @@ async fn main_inner() -> anyhow::Result<()> {
                 TagActions::Delete { hostname } => {
                     commands::tags::delete(&cfg, &hostname).await?;
                 }
+                TagActions::Apply { hosts, add, remove } => {
+                    commands::tags::apply(&cfg, &hosts, &add, &remove).await?;
+                }
             }
         }
         // --- Users ---
@@ -5109,12 +7940,30 @@ async fn main_inner() -> anyhow::Result<()> {
                         filter,
                         sort,
                         count,
+                        from,
                     } => {
-                        commands::infrastructure::hosts_list(&cfg, filter, sort, count).await?;
+                        commands::infrastructure::hosts_list(&cfg, filter, sort, count, from)
+                            .await?;
                     }
                     InfraHostActions::Get { hostname } => {
                         commands::infrastructure::hosts_get(&cfg, &hostname).await?;
                     }
+                    InfraHostActions::Mute {
+                        hostname,
+                        end,
+                        message,
+                    } => {
+                        commands::infrastructure::hosts_mute(
+                            &cfg,
+                            &hostname,
+                            end.as_deref(),
+                            message,
+                        )
+                        .await?;
+                    }
+                    InfraHostActions::Unmute { hostname } => {
+                        commands::infrastructure::hosts_unmute(&cfg, &hostname).await?;
+                    }
                 },
             }
         }
@@ -5236,9 +8085,12 @@ async fn main_inner() -> anyhow::Result<()> {
             cfg.validate_auth()?;
             match action {
                 CaseActions::Search {
-                    query, page_size, ..
+                    query,
+                    assignee,
+                    page_size,
+                    ..
                 } => {
-                    commands::cases::search(&cfg, query, page_size).await?;
+                    commands::cases::search(&cfg, query, assignee, page_size).await?;
                 }
                 CaseActions::Get { case_id } => commands::cases::get(&cfg, &case_id).await?,
                 CaseActions::Create {
@@ -5366,6 +8218,25 @@ async fn main_inner() -> anyhow::Result<()> {
                 }
             }
         }
+        // --- Service Checks ---
+        Commands::ServiceChecks { action } => match action {
+            ServiceCheckActions::Submit {
+                check,
+                status,
+                host,
+                tags,
+                message,
+            } => {
+                let run = commands::service_checks::CheckRun {
+                    check,
+                    status,
+                    host,
+                    tags,
+                    message,
+                };
+                commands::service_checks::submit(&cfg, &run).await?;
+            }
+        },
         // --- API Keys ---
         Commands::ApiKeys { action } => {
             cfg.validate_auth()?;
@@ -5393,16 +8264,11 @@ async fn main_inner() -> anyhow::Result<()> {
                     commands::app_keys::register(&cfg, &key_id).await?
                 }
                 AppKeyActions::Unregister { key_id } => {
-                    if !cfg.auto_approve {
-                        eprint!(
-                            "Unregister app key {key_id} from Action Connections? Type 'yes' to confirm: "
-                        );
-                        let mut input = String::new();
-                        std::io::stdin().read_line(&mut input)?;
-                        if input.trim() != "yes" {
-                            println!("Operation cancelled.");
-                            return Ok(());
-                        }
+                    if !util::confirm(
+                        &cfg,
+                        &format!("Unregister app key {key_id} from Action Connections?"),
+                    )? {
+                        return Ok(());
                     }
                     commands::app_keys::unregister(&cfg, &key_id).await?
                 }
@@ -5418,6 +8284,9 @@ async fn main_inner() -> anyhow::Result<()> {
                 UsageActions::Hourly { from, to } => {
                     commands::usage::hourly(&cfg, from, to).await?;
                 }
+                UsageActions::TopMetrics { from, limit } => {
+                    commands::usage::top_metrics(&cfg, from, limit).await?;
+                }
             }
         }
         // --- Notebooks ---
@@ -5439,13 +8308,28 @@ async fn main_inner() -> anyhow::Result<()> {
                 }
             }
         }
+        // --- Raw ---
+        Commands::Raw {
+            method,
+            path,
+            file,
+            stream,
+            output_file,
+        } => {
+            cfg.validate_auth()?;
+            let path = commands::raw::normalize_path(&path)?;
+            let body = file.as_deref().map(util::read_json_file).transpose()?;
+            commands::raw::run(&cfg, &method, &path, body, stream, output_file.as_deref()).await?;
+        }
         // --- RUM ---
         Commands::Rum { action } => {
             cfg.validate_auth()?;
             match action {
                 RumActions::Apps { action } => match action {
                     RumAppActions::List => commands::rum::apps_list(&cfg).await?,
-                    RumAppActions::Get { app_id } => commands::rum::apps_get(&cfg, &app_id).await?,
+                    RumAppActions::Get { app_id, snippet } => {
+                        commands::rum::apps_get(&cfg, &app_id, snippet).await?;
+                    }
                     RumAppActions::Create { name, app_type } => {
                         commands::rum::apps_create(&cfg, &name, app_type).await?;
                     }
@@ -5466,8 +8350,10 @@ async fn main_inner() -> anyhow::Result<()> {
                         from,
                         to,
                         limit,
+                        count_only,
                     } => {
-                        commands::rum::sessions_search(&cfg, query, from, to, limit).await?;
+                        commands::rum::sessions_search(&cfg, query, from, to, limit, count_only)
+                            .await?;
                     }
                     RumSessionActions::List { from, to, limit } => {
                         commands::rum::sessions_list(&cfg, from, to, limit).await?;
@@ -5475,8 +8361,13 @@ async fn main_inner() -> anyhow::Result<()> {
                 },
                 RumActions::Metrics { action } => match action {
                     RumMetricActions::List => commands::rum::metrics_list(&cfg).await?,
-                    RumMetricActions::Get { metric_id } => {
-                        commands::rum::metrics_get(&cfg, &metric_id).await?;
+                    RumMetricActions::Get {
+                        metric_id,
+                        with_volume,
+                        window,
+                    } => {
+                        let volume_window = with_volume.then_some(window.as_str());
+                        commands::rum::metrics_get(&cfg, &metric_id, volume_window).await?;
                     }
                     RumMetricActions::Create { file } => {
                         commands::rum::metrics_create(&cfg, &file).await?;
@@ -5866,6 +8757,9 @@ async fn main_inner() -> anyhow::Result<()> {
                         JiraAccountActions::List => {
                             commands::integrations::jira_accounts_list(&cfg).await?
                         }
+                        JiraAccountActions::Create { file } => {
+                            commands::integrations::jira_accounts_create(&cfg, &file).await?;
+                        }
                         JiraAccountActions::Delete { account_id } => {
                             commands::integrations::jira_accounts_delete(&cfg, &account_id).await?;
                         }
@@ -6021,8 +8915,10 @@ async fn main_inner() -> anyhow::Result<()> {
                     }
                 },
                 ApmActions::Entities { action } => match action {
-                    ApmEntityActions::List { from, to, .. } => {
-                        commands::apm::entities_list(&cfg, from, to).await?;
+                    ApmEntityActions::List {
+                        from, to, format, ..
+                    } => {
+                        commands::apm::entities_list(&cfg, from, to, format).await?;
                     }
                 },
                 ApmActions::Dependencies { action } => match action {
@@ -6112,9 +9008,23 @@ async fn main_inner() -> anyhow::Result<()> {
                     from,
                     to,
                     compute,
+                    interval,
                     group_by,
+                    limit,
+                } => {
+                    commands::traces::aggregate(
+                        &cfg, query, from, to, compute, interval, group_by, limit,
+                    )
+                    .await?;
+                }
+                TracesActions::Get {
+                    trace_id,
+                    from,
+                    to,
+                    limit,
+                    format,
                 } => {
-                    commands::traces::aggregate(&cfg, query, from, to, compute, group_by).await?;
+                    commands::traces::get(&cfg, &trace_id, from, to, limit, format).await?;
                 }
             }
         }
@@ -6123,6 +9033,10 @@ async fn main_inner() -> anyhow::Result<()> {
             AgentActions::Schema { compact } => commands::agent::schema(compact)?,
             AgentActions::Guide => commands::agent::guide()?,
         },
+        Commands::Batch {
+            file,
+            continue_on_error,
+        } => run_batch(&cfg, &file, continue_on_error).await?,
         // --- Alias ---
         Commands::Alias { action } => match action {
             AliasActions::List => commands::alias::list()?,
@@ -6182,7 +9096,8 @@ async fn main_inner() -> anyhow::Result<()> {
         }
         // --- Auth ---
         Commands::Auth { action } => match action {
-            AuthActions::Login => commands::auth::login(&cfg).await?,
+            AuthActions::Login { device: false } => commands::auth::login(&cfg).await?,
+            AuthActions::Login { device: true } => commands::auth::login_device(&cfg).await?,
             AuthActions::Logout => commands::auth::logout(&cfg).await?,
             AuthActions::Status => commands::auth::status(&cfg)?,
             AuthActions::Token => commands::auth::token(&cfg)?,
@@ -6192,7 +9107,7 @@ async fn main_inner() -> anyhow::Result<()> {
         Commands::Completions { shell } => {
             clap_complete::generate(shell, &mut Cli::command(), "pup", &mut std::io::stdout());
         }
-        Commands::Version => println!("{}", version::build_info()),
+        Commands::Version => formatter::print_block(&format!("{}\n", version::build_info()))?,
         Commands::Test => commands::test::run(&cfg)?,
     }
 
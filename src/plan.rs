@@ -0,0 +1,160 @@
+//! Request plans for `--explain`: what a command would send, printed instead
+//! of sending it.
+//!
+//! Handlers that split work across several requests (chunked or paginated
+//! searches) build a detailed `Plan`; `--explain` on any other command is an
+//! error. The root fills in site, auth, output format and
+//! `--orgs` before rendering. Credentials are never included.
+
+/// How many API requests a command is expected to make.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum RequestCount {
+    Exactly(usize),
+    AtMost(usize),
+    /// Depends on the data, e.g. one per page of results.
+    Unbounded(String),
+}
+
+#[derive(Debug, Default)]
+pub struct Plan {
+    /// Command path, e.g. "logs search".
+    pub command: String,
+    /// Resolved time window (RFC3339), if the command takes one.
+    pub from: Option<String>,
+    pub to: Option<String>,
+    /// Requests as "METHOD /path".
+    pub endpoints: Vec<String>,
+    pub pagination: Option<String>,
+    pub requests: Option<RequestCount>,
+    pub site: String,
+    /// Kind of credentials the requests would carry, never their values.
+    pub auth: String,
+    pub output: String,
+    /// Profiles from `--orgs`; the plan runs once per profile.
+    pub orgs: Vec<String>,
+    pub notes: Vec<String>,
+}
+
+impl Plan {
+    pub fn new(endpoint: &str) -> Self {
+        Self {
+            endpoints: vec![endpoint.to_string()],
+            ..Default::default()
+        }
+    }
+
+    /// Records the window `[from_ms, to_ms]` in RFC3339.
+    pub fn set_window(&mut self, from_ms: i64, to_ms: i64) {
+        let rfc3339 = |ms: i64| {
+            chrono::DateTime::from_timestamp_millis(ms)
+                .map(|dt| dt.to_rfc3339())
+                .unwrap_or_else(|| ms.to_string())
+        };
+        self.from = Some(rfc3339(from_ms));
+        self.to = Some(rfc3339(to_ms));
+    }
+
+    fn requests_line(&self) -> Option<String> {
+        let count = match self.requests.as_ref()? {
+            RequestCount::Exactly(n) => n.to_string(),
+            RequestCount::AtMost(n) => format!("up to {n}"),
+            RequestCount::Unbounded(how) => how.clone(),
+        };
+        Some(match self.orgs.len() {
+            0 | 1 => count,
+            orgs => format!("{count} per org, {orgs} orgs"),
+        })
+    }
+
+    /// Human-readable plan, one labelled line per known detail.
+    pub fn render(&self) -> String {
+        let mut lines = vec![format!(
+            "Plan for `pup {}` (no requests sent):",
+            self.command
+        )];
+        let mut field = |label: &str, value: &str| {
+            lines.push(format!("  {:<12}{value}", format!("{label}:")));
+        };
+        if let (Some(from), Some(to)) = (&self.from, &self.to) {
+            field("Window", &format!("{from} to {to}"));
+        }
+        match self.endpoints.as_slice() {
+            [] => field("Endpoint", "not planned in detail for this command"),
+            [one] => field("Endpoint", one),
+            many => field("Endpoints", &many.join(", ")),
+        }
+        if let Some(pagination) = &self.pagination {
+            field("Pagination", pagination);
+        }
+        if let Some(requests) = self.requests_line() {
+            field("Requests", &requests);
+        }
+        field("Site", &self.site);
+        field("Auth", &self.auth);
+        if !self.orgs.is_empty() {
+            field("Orgs", &self.orgs.join(", "));
+        }
+        field("Output", &self.output);
+        for note in &self.notes {
+            field("Note", note);
+        }
+        lines.join("\n") + "\n"
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn plan() -> Plan {
+        Plan {
+            command: "logs search".into(),
+            pagination: Some("3 windows of 1d, newest first".into()),
+            requests: Some(RequestCount::AtMost(3)),
+            site: "datadoghq.com".into(),
+            auth: "API + application keys".into(),
+            output: "json".into(),
+            ..Plan::new("POST /api/v2/logs/events/search")
+        }
+    }
+
+    #[test]
+    fn test_render() {
+        let mut p = plan();
+        p.set_window(1_704_067_200_000, 1_704_326_400_000);
+        let out = p.render();
+        assert!(out.starts_with("Plan for `pup logs search` (no requests sent):\n"));
+        assert!(
+            out.contains("  Window:     2024-01-01T00:00:00+00:00 to 2024-01-04T00:00:00+00:00\n"),
+            "{out}"
+        );
+        assert!(out.contains("  Endpoint:   POST /api/v2/logs/events/search\n"));
+        assert!(out.contains("  Requests:   up to 3\n"));
+        assert!(out.contains("  Auth:       API + application keys\n"));
+        assert!(!out.contains("Orgs"));
+    }
+
+    #[test]
+    fn test_requests_per_org() {
+        let mut p = plan();
+        p.orgs = vec!["prod".into(), "staging".into()];
+        assert_eq!(
+            p.requests_line().as_deref(),
+            Some("up to 3 per org, 2 orgs")
+        );
+        p.requests = Some(RequestCount::Unbounded("one per page".into()));
+        p.orgs.clear();
+        assert_eq!(p.requests_line().as_deref(), Some("one per page"));
+    }
+
+    #[test]
+    fn test_render_generic() {
+        let p = Plan {
+            command: "monitors list".into(),
+            ..Default::default()
+        };
+        assert!(p
+            .render()
+            .contains("Endpoint:   not planned in detail for this command"));
+    }
+}
@@ -0,0 +1,498 @@
+//! Minimal Go-style text templates for `--template-file`.
+//!
+//! Supports the subset of Go's text/template that report templates need:
+//!   - Field access: `{{ .data.id }}`, `{{ . }}`, `{{ $ }}` (the root value)
+//!   - Actions: `{{ range }}`, `{{ if }}`, `{{ with }}`, each with optional `{{ else }}`
+//!   - Pipelines: `{{ .name | upper }}`, `{{ .owner | default "none" }}`
+//!   - Trim markers `{{-` / `-}}` and comments `{{/* ... */}}`
+//!
+//! Helper functions: `json`, `upper`, `lower`, `default`, `join`.
+
+use anyhow::{bail, Result};
+use serde_json::Value;
+
+/// A parsed template, ready to render against JSON data.
+#[derive(Clone, Debug)]
+pub struct Template {
+    nodes: Vec<Node>,
+}
+
+#[derive(Clone, Debug)]
+enum Node {
+    Text(String),
+    Output(Pipeline),
+    Range(Pipeline, Vec<Node>, Vec<Node>),
+    If(Pipeline, Vec<Node>, Vec<Node>),
+    With(Pipeline, Vec<Node>, Vec<Node>),
+}
+
+type Pipeline = Vec<Command>;
+type Command = Vec<Arg>;
+
+#[derive(Clone, Debug)]
+enum Arg {
+    Dot(Vec<String>),
+    Root(Vec<String>),
+    Literal(Value),
+    Func(String),
+}
+
+/// Parses template source into a [`Template`].
+pub fn parse(src: &str) -> Result<Template> {
+    let tokens = tokenize(src)?;
+    let mut pos = 0;
+    let (nodes, end) = parse_nodes(&tokens, &mut pos)?;
+    if let Some(tag) = end {
+        bail!("unexpected {{{{{tag}}}}} in template");
+    }
+    Ok(Template { nodes })
+}
+
+/// Reads and parses a template file.
+pub fn load_file(path: &str) -> Result<Template> {
+    let src = std::fs::read_to_string(path)
+        .map_err(|e| anyhow::anyhow!("failed to read template file {path}: {e}"))?;
+    parse(&src).map_err(|e| anyhow::anyhow!("invalid template {path}: {e}"))
+}
+
+impl Template {
+    /// Renders the template with `data` as both `.` and `$`.
+    pub fn render(&self, data: &Value) -> Result<String> {
+        let mut out = String::new();
+        render_nodes(&self.nodes, data, data, &mut out)?;
+        Ok(out)
+    }
+}
+
+// ---- Lexing ----
+
+enum Token {
+    Text(String),
+    Action(String),
+}
+
+fn tokenize(src: &str) -> Result<Vec<Token>> {
+    let mut tokens = Vec::new();
+    let mut rest = src;
+    let mut trim_next = false;
+    while let Some(start) = rest.find("{{") {
+        let mut text = &rest[..start];
+        if trim_next {
+            text = text.trim_start();
+        }
+        let after = &rest[start + 2..];
+        let Some(end) = after.find("}}") else {
+            bail!("unclosed action");
+        };
+        let mut action = &after[..end];
+        if let Some(a) = action.strip_prefix('-') {
+            text = text.trim_end();
+            action = a;
+        }
+        trim_next = false;
+        if let Some(a) = action.strip_suffix('-') {
+            trim_next = true;
+            action = a;
+        }
+        if !text.is_empty() {
+            tokens.push(Token::Text(text.to_string()));
+        }
+        let action = action.trim();
+        if !(action.starts_with("/*") && action.ends_with("*/")) {
+            tokens.push(Token::Action(action.to_string()));
+        }
+        rest = &after[end + 2..];
+    }
+    let text = if trim_next { rest.trim_start() } else { rest };
+    if !text.is_empty() {
+        tokens.push(Token::Text(text.to_string()));
+    }
+    Ok(tokens)
+}
+
+// ---- Parsing ----
+
+/// Parses nodes until `{{ else }}`, `{{ end }}`, or end of input; returns the
+/// terminating keyword, if any.
+fn parse_nodes(tokens: &[Token], pos: &mut usize) -> Result<(Vec<Node>, Option<&'static str>)> {
+    let mut nodes = Vec::new();
+    while *pos < tokens.len() {
+        let token = &tokens[*pos];
+        *pos += 1;
+        let action = match token {
+            Token::Text(t) => {
+                nodes.push(Node::Text(t.clone()));
+                continue;
+            }
+            Token::Action(a) => a.as_str(),
+        };
+        let (keyword, rest) = action
+            .split_once(char::is_whitespace)
+            .unwrap_or((action, ""));
+        match keyword {
+            "end" => return Ok((nodes, Some("end"))),
+            "else" => return Ok((nodes, Some("else"))),
+            "range" | "if" | "with" => {
+                let pipe = parse_pipeline(rest)?;
+                let (body, end) = parse_nodes(tokens, pos)?;
+                let alt = match end {
+                    Some("else") => match parse_nodes(tokens, pos)? {
+                        (alt, Some("end")) => alt,
+                        _ => bail!("missing {{{{end}}}} for {{{{{keyword}}}}}"),
+                    },
+                    Some("end") => Vec::new(),
+                    _ => bail!("missing {{{{end}}}} for {{{{{keyword}}}}}"),
+                };
+                nodes.push(match keyword {
+                    "range" => Node::Range(pipe, body, alt),
+                    "if" => Node::If(pipe, body, alt),
+                    _ => Node::With(pipe, body, alt),
+                });
+            }
+            _ => nodes.push(Node::Output(parse_pipeline(action)?)),
+        }
+    }
+    Ok((nodes, None))
+}
+
+fn parse_pipeline(src: &str) -> Result<Pipeline> {
+    let words = split_words(src)?;
+    if words.is_empty() {
+        bail!("missing value for command");
+    }
+    let mut pipeline = Vec::new();
+    for cmd in words.split(|w| w == "|") {
+        if cmd.is_empty() {
+            bail!("empty command in pipeline");
+        }
+        pipeline.push(
+            cmd.iter()
+                .map(|w| parse_arg(w))
+                .collect::<Result<Vec<_>>>()?,
+        );
+    }
+    Ok(pipeline)
+}
+
+fn split_words(src: &str) -> Result<Vec<String>> {
+    let mut words = Vec::new();
+    let mut chars = src.chars().peekable();
+    while let Some(&c) = chars.peek() {
+        if c.is_whitespace() {
+            chars.next();
+        } else if c == '"' {
+            let mut word = String::from('"');
+            chars.next();
+            let mut closed = false;
+            while let Some(c) = chars.next() {
+                word.push(c);
+                if c == '\\' {
+                    if let Some(escaped) = chars.next() {
+                        word.push(escaped);
+                    }
+                } else if c == '"' {
+                    closed = true;
+                    break;
+                }
+            }
+            if !closed {
+                bail!("unterminated quoted string");
+            }
+            words.push(word);
+        } else if c == '|' {
+            chars.next();
+            words.push("|".to_string());
+        } else {
+            let mut word = String::new();
+            while let Some(&c) = chars.peek() {
+                if c.is_whitespace() || c == '|' {
+                    break;
+                }
+                word.push(c);
+                chars.next();
+            }
+            words.push(word);
+        }
+    }
+    Ok(words)
+}
+
+fn parse_arg(word: &str) -> Result<Arg> {
+    let fields = |path: &str| -> Vec<String> {
+        path.split('.')
+            .filter(|s| !s.is_empty())
+            .map(String::from)
+            .collect()
+    };
+    if let Some(path) = word.strip_prefix('$') {
+        return Ok(Arg::Root(fields(path)));
+    }
+    if word.starts_with('.') {
+        return Ok(Arg::Dot(fields(word)));
+    }
+    if word.starts_with('"') {
+        let s: String = serde_json::from_str(word)
+            .map_err(|e| anyhow::anyhow!("invalid string {word}: {e}"))?;
+        return Ok(Arg::Literal(Value::String(s)));
+    }
+    match word {
+        "true" => return Ok(Arg::Literal(Value::Bool(true))),
+        "false" => return Ok(Arg::Literal(Value::Bool(false))),
+        "nil" => return Ok(Arg::Literal(Value::Null)),
+        _ => {}
+    }
+    if let Ok(n) = serde_json::from_str::<serde_json::Number>(word) {
+        return Ok(Arg::Literal(Value::Number(n)));
+    }
+    if FUNCS.contains(&word) {
+        return Ok(Arg::Func(word.to_string()));
+    }
+    bail!("function {word:?} not defined")
+}
+
+// ---- Rendering ----
+
+const FUNCS: &[&str] = &["json", "upper", "lower", "default", "join"];
+
+fn render_nodes(nodes: &[Node], dot: &Value, root: &Value, out: &mut String) -> Result<()> {
+    for node in nodes {
+        match node {
+            Node::Text(t) => out.push_str(t),
+            Node::Output(pipe) => out.push_str(&to_text(&eval_pipeline(pipe, dot, root)?)),
+            Node::If(pipe, body, alt) => {
+                let branch = if truthy(&eval_pipeline(pipe, dot, root)?) {
+                    body
+                } else {
+                    alt
+                };
+                render_nodes(branch, dot, root, out)?;
+            }
+            Node::With(pipe, body, alt) => {
+                let value = eval_pipeline(pipe, dot, root)?;
+                if truthy(&value) {
+                    render_nodes(body, &value, root, out)?;
+                } else {
+                    render_nodes(alt, dot, root, out)?;
+                }
+            }
+            Node::Range(pipe, body, alt) => {
+                let value = eval_pipeline(pipe, dot, root)?;
+                let items: Vec<&Value> = match &value {
+                    Value::Array(a) => a.iter().collect(),
+                    Value::Object(m) => m.values().collect(),
+                    Value::Null => Vec::new(),
+                    other => bail!("range can't iterate over {other}"),
+                };
+                if items.is_empty() {
+                    render_nodes(alt, dot, root, out)?;
+                }
+                for item in items {
+                    render_nodes(body, item, root, out)?;
+                }
+            }
+        }
+    }
+    Ok(())
+}
+
+fn eval_pipeline(pipe: &Pipeline, dot: &Value, root: &Value) -> Result<Value> {
+    let mut piped: Option<Value> = None;
+    for cmd in pipe {
+        piped = Some(eval_command(cmd, dot, root, piped)?);
+    }
+    Ok(piped.unwrap_or(Value::Null))
+}
+
+fn eval_command(cmd: &Command, dot: &Value, root: &Value, piped: Option<Value>) -> Result<Value> {
+    let operand = |arg: &Arg| -> Result<Value> {
+        match arg {
+            Arg::Dot(path) => Ok(lookup(dot, path)),
+            Arg::Root(path) => Ok(lookup(root, path)),
+            Arg::Literal(v) => Ok(v.clone()),
+            Arg::Func(name) => bail!("function {name:?} used as a value"),
+        }
+    };
+    let Arg::Func(name) = &cmd[0] else {
+        if cmd.len() > 1 || piped.is_some() {
+            bail!("can't give arguments to a non-function");
+        }
+        return operand(&cmd[0]);
+    };
+    let mut args = cmd[1..].iter().map(operand).collect::<Result<Vec<_>>>()?;
+    args.extend(piped);
+    call(name, &args)
+}
+
+fn call(name: &str, args: &[Value]) -> Result<Value> {
+    let arity = |n: usize| -> Result<()> {
+        if args.len() != n {
+            bail!(
+                "wrong number of args for {name}: want {n} got {}",
+                args.len()
+            );
+        }
+        Ok(())
+    };
+    match name {
+        "json" => {
+            arity(1)?;
+            Ok(Value::String(serde_json::to_string(&args[0])?))
+        }
+        "upper" => {
+            arity(1)?;
+            Ok(Value::String(to_text(&args[0]).to_uppercase()))
+        }
+        "lower" => {
+            arity(1)?;
+            Ok(Value::String(to_text(&args[0]).to_lowercase()))
+        }
+        "default" => {
+            arity(2)?;
+            Ok(if truthy(&args[1]) {
+                args[1].clone()
+            } else {
+                args[0].clone()
+            })
+        }
+        "join" => {
+            arity(2)?;
+            let sep = to_text(&args[0]);
+            let items = match &args[1] {
+                Value::Array(a) => a.iter().map(to_text).collect::<Vec<_>>(),
+                Value::Null => Vec::new(),
+                other => vec![to_text(other)],
+            };
+            Ok(Value::String(items.join(&sep)))
+        }
+        _ => bail!("function {name:?} not defined"),
+    }
+}
+
+fn lookup(value: &Value, path: &[String]) -> Value {
+    let mut current = value;
+    for key in path {
+        current = match current {
+            Value::Object(m) => match m.get(key) {
+                Some(v) => v,
+                None => return Value::Null,
+            },
+            Value::Array(a) => match key.parse::<usize>().ok().and_then(|i| a.get(i)) {
+                Some(v) => v,
+                None => return Value::Null,
+            },
+            _ => return Value::Null,
+        };
+    }
+    current.clone()
+}
+
+/// Go template truthiness: false, 0, nil, and empty strings/collections are false.
+fn truthy(value: &Value) -> bool {
+    match value {
+        Value::Null => false,
+        Value::Bool(b) => *b,
+        Value::Number(n) => n.as_f64().is_some_and(|f| f != 0.0),
+        Value::String(s) => !s.is_empty(),
+        Value::Array(a) => !a.is_empty(),
+        Value::Object(m) => !m.is_empty(),
+    }
+}
+
+fn to_text(value: &Value) -> String {
+    match value {
+        Value::Null => String::new(),
+        Value::String(s) => s.clone(),
+        other => other.to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use serde_json::json;
+
+    fn render(src: &str, data: Value) -> String {
+        parse(src).unwrap().render(&data).unwrap()
+    }
+
+    #[test]
+    fn test_render_fields_and_pipelines() {
+        let data = json!({"name": "web", "tags": ["env:prod", "team:a"], "owner": null});
+        assert_eq!(render("{{ .name | upper }}", data.clone()), "WEB");
+        assert_eq!(
+            render("{{ .owner | default \"none\" }}", data.clone()),
+            "none"
+        );
+        assert_eq!(render("{{ default \"none\" .name }}", data.clone()), "web");
+        assert_eq!(
+            render("{{ .tags | json }}", data.clone()),
+            r#"["env:prod","team:a"]"#
+        );
+        assert_eq!(
+            render("{{ join \", \" .tags }}", data.clone()),
+            "env:prod, team:a"
+        );
+        assert_eq!(render("{{ .tags.1 }}", data.clone()), "team:a");
+        assert_eq!(render("{{ .missing.deep }}", data), "");
+    }
+
+    #[test]
+    fn test_render_control_flow() {
+        let data = json!({"items": [], "meta": {"count": 0}, "title": "T"});
+        assert_eq!(
+            render("{{ range .items }}x{{ else }}empty{{ end }}", data.clone()),
+            "empty"
+        );
+        assert_eq!(
+            render(
+                "{{ if .meta.count }}some{{ else }}none{{ end }}",
+                data.clone()
+            ),
+            "none"
+        );
+        assert_eq!(
+            render(
+                "{{ with .meta }}{{ .count }} in {{ $.title }}{{ end }}",
+                data
+            ),
+            "0 in T"
+        );
+    }
+
+    #[test]
+    fn test_render_multiline_template_file() {
+        let path = std::env::temp_dir().join("pup_test_report.tmpl");
+        std::fs::write(
+            &path,
+            "# Monitors\n\
+             {{- range .data }}\n\
+             - {{ .name | upper }} ({{ .overall_state | default \"Unknown\" }})\n\
+             {{- /* tags are optional */ -}}\n\
+             {{ with .tags }} tags={{ json . }}{{ end }}\n\
+             {{- end }}\n",
+        )
+        .unwrap();
+        let tmpl = load_file(path.to_str().unwrap()).unwrap();
+        let _ = std::fs::remove_file(&path);
+
+        let data = json!({"data": [
+            {"name": "cpu high", "overall_state": "Alert", "tags": ["env:prod"]},
+            {"name": "disk full", "tags": []}
+        ]});
+        assert_eq!(
+            tmpl.render(&data).unwrap(),
+            "# Monitors\n\
+             - CPU HIGH (Alert) tags=[\"env:prod\"]\n\
+             - DISK FULL (Unknown)\n"
+        );
+    }
+
+    #[test]
+    fn test_parse_errors() {
+        assert!(parse("{{ range .data }}x").is_err());
+        assert!(parse("{{ end }}").is_err());
+        assert!(parse("{{ .a | nope }}").is_err());
+        assert!(parse("{{ .a").is_err());
+        assert!(load_file("/nonexistent/pup.tmpl").is_err());
+    }
+}
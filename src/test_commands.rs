@@ -31,6 +31,11 @@ fn test_config(mock_url: &str) -> Config {
         output_format: OutputFormat::Json,
         auto_approve: false,
         agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
     }
 }
 
@@ -106,6 +111,81 @@ async fn test_monitors_get() {
     cleanup_env();
 }
 
+#[tokio::test]
+async fn test_monitors_get_related() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+
+    let mut json_mock = |method: &str, path: &str, body: &str| {
+        server
+            .mock(method, path)
+            .with_status(200)
+            .with_header("content-type", "application/json")
+            .with_body(body.to_string())
+            .expect(1)
+    };
+    let monitor = json_mock(
+        "GET",
+        "/api/v1/monitor/4242",
+        r#"{"id": 4242, "name": "Checkout errors", "type": "metric alert", "query": "avg(last_5m):sum:checkout.errors{*} > 5", "options": {}}"#,
+    )
+    .create_async()
+    .await;
+    let slos = json_mock(
+        "GET",
+        "/api/v1/slo",
+        r#"{"data": [
+            {"id": "slo-1", "name": "Checkout availability", "type": "monitor", "monitor_ids": [4242]},
+            {"id": "slo-2", "name": "Search latency", "type": "monitor", "monitor_ids": [7]}
+        ]}"#,
+    )
+    .match_query(mockito::Matcher::UrlEncoded("limit".into(), "1000".into()))
+    .create_async()
+    .await;
+    let list = json_mock(
+        "GET",
+        "/api/v1/dashboard",
+        r#"{"dashboards": [{"id": "abc-123", "title": "Checkout"}, {"id": "def-456", "title": "Infra"}]}"#,
+    )
+    .create_async()
+    .await;
+    let checkout = json_mock(
+        "GET",
+        "/api/v1/dashboard/abc-123",
+        r#"{"id": "abc-123", "title": "Checkout", "url": "/dashboard/abc-123",
+            "widgets": [{"definition": {"type": "alert_graph", "alert_id": "4242", "viz_type": "timeseries"}}]}"#,
+    )
+    .create_async()
+    .await;
+    let infra = json_mock(
+        "GET",
+        "/api/v1/dashboard/def-456",
+        r#"{"id": "def-456", "title": "Infra", "url": "/dashboard/def-456",
+            "widgets": [{"definition": {"type": "alert_value", "alert_id": "999"}}]}"#,
+    )
+    .create_async()
+    .await;
+
+    crate::formatter::begin_capture();
+    let result = crate::commands::monitors::get_with_related(&cfg, 4242).await;
+    let captured = crate::formatter::end_capture();
+    assert!(
+        result.is_ok(),
+        "monitors get --related failed: {:?}",
+        result.err()
+    );
+    for mock in [monitor, slos, list, checkout, infra] {
+        mock.assert_async().await;
+    }
+    let related = &captured[0]["related"];
+    assert_eq!(related["dashboards"][0]["id"], "abc-123");
+    assert_eq!(related["dashboards"].as_array().unwrap().len(), 1);
+    assert_eq!(related["slos"][0]["id"], "slo-1");
+    assert_eq!(related["slos"].as_array().unwrap().len(), 1);
+    cleanup_env();
+}
+
 #[tokio::test]
 async fn test_monitors_search() {
     let _lock = lock_env();
@@ -132,532 +212,893 @@ async fn test_monitors_delete() {
     cleanup_env();
 }
 
-// -------------------------------------------------------------------------
-// Dashboards
-// -------------------------------------------------------------------------
-
-#[tokio::test]
-async fn test_dashboards_list() {
-    let _lock = lock_env();
-    let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "GET", r#"{"dashboards": []}"#).await;
+/// Mocks a single page of monitor search results with the given ids.
+async fn mock_monitor_search(server: &mut mockito::Server, ids: &[i64]) -> mockito::Mock {
+    let monitors: Vec<serde_json::Value> = ids
+        .iter()
+        .map(|id| serde_json::json!({"id": id, "name": format!("monitor {id}")}))
+        .collect();
+    let body = serde_json::json!({
+        "monitors": monitors,
+        "metadata": {"page": 0, "page_count": 1, "per_page": 100, "total_count": ids.len()}
+    });
+    server
+        .mock("GET", "/api/v1/monitor/search")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(body.to_string())
+        .create_async()
+        .await
+}
 
-    let result = crate::commands::dashboards::list(&cfg).await;
-    assert!(result.is_ok(), "dashboards list failed: {:?}", result.err());
-    cleanup_env();
+async fn mock_monitor_delete(
+    server: &mut mockito::Server,
+    id: i64,
+    status: usize,
+) -> mockito::Mock {
+    server
+        .mock("DELETE", format!("/api/v1/monitor/{id}").as_str())
+        .match_query(mockito::Matcher::Any)
+        .with_status(status)
+        .with_header("content-type", "application/json")
+        .with_body(format!(r#"{{"deleted_monitor_id": {id}}}"#))
+        .expect(1)
+        .create_async()
+        .await
 }
 
 #[tokio::test]
-async fn test_dashboards_get() {
+async fn test_monitors_delete_matching() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "GET",
-        r#"{"id": "abc-123", "title": "Test Dashboard", "layout_type": "ordered", "widgets": []}"#,
-    )
-    .await;
-
-    let result = crate::commands::dashboards::get(&cfg, "abc-123").await;
-    assert!(result.is_ok(), "dashboards get failed: {:?}", result.err());
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+    let _search = mock_monitor_search(&mut server, &[1, 2, 3]).await;
+    let deletes = vec![
+        mock_monitor_delete(&mut server, 1, 200).await,
+        mock_monitor_delete(&mut server, 2, 200).await,
+        mock_monitor_delete(&mut server, 3, 200).await,
+    ];
+
+    let result = crate::commands::monitors::delete_matching(&cfg, "tag:deprecated").await;
+    assert!(
+        result.is_ok(),
+        "monitors delete --query failed: {:?}",
+        result.err()
+    );
+    for m in deletes {
+        m.assert_async().await;
+    }
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_dashboards_delete() {
+async fn test_monitors_delete_matching_reports_failures() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "DELETE",
-        r#"{"deleted_dashboard_id": "abc-123"}"#,
-    )
-    .await;
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+    let _search = mock_monitor_search(&mut server, &[1, 2]).await;
+    let ok = mock_monitor_delete(&mut server, 1, 200).await;
+    let missing = mock_monitor_delete(&mut server, 2, 404).await;
 
-    let result = crate::commands::dashboards::delete(&cfg, "abc-123").await;
+    let err = crate::commands::monitors::delete_matching(&cfg, "tag:deprecated")
+        .await
+        .unwrap_err();
     assert!(
-        result.is_ok(),
-        "dashboards delete failed: {:?}",
-        result.err()
+        err.to_string().contains("failed to delete 1 of 2 monitors"),
+        "{err}"
     );
+    ok.assert_async().await;
+    missing.assert_async().await;
     cleanup_env();
 }
 
-// -------------------------------------------------------------------------
-// SLOs
-// -------------------------------------------------------------------------
-
 #[tokio::test]
-async fn test_slos_list() {
+async fn test_monitors_delete_matching_large_set_requires_yes() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "GET", r#"{"data": [], "errors": []}"#).await;
+    let ids: Vec<i64> = (1..=crate::util::BULK_CONFIRM_THRESHOLD as i64 + 1).collect();
+    let _search = mock_monitor_search(&mut server, &ids).await;
+    let delete = server
+        .mock("DELETE", mockito::Matcher::Any)
+        .match_query(mockito::Matcher::Any)
+        .expect(0)
+        .create_async()
+        .await;
 
-    let result = crate::commands::slos::list(&cfg).await;
-    assert!(result.is_ok(), "slos list failed: {:?}", result.err());
+    let err = crate::commands::monitors::delete_matching(&cfg, "tag:deprecated")
+        .await
+        .unwrap_err();
+    assert!(err.to_string().contains("re-run with --yes"), "{err}");
+    delete.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_slos_get() {
+async fn test_monitors_mute_matching() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "GET",
-        r#"{"data": {"id": "abc123", "name": "Test SLO", "type": "metric", "thresholds": [{"timeframe": "7d", "target": 99.9}]}, "errors": []}"#,
-    )
-    .await;
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+    let _search = mock_monitor_search(&mut server, &[7, 8]).await;
+    let downtimes = server
+        .mock("POST", "/api/v2/downtime")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"type": "downtime", "attributes": {"scope": "*"}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"id": "dt-1", "type": "downtime"}}"#)
+        .expect(2)
+        .create_async()
+        .await;
 
-    let result = crate::commands::slos::get(&cfg, "abc123").await;
-    assert!(result.is_ok(), "slos get failed: {:?}", result.err());
+    let result =
+        crate::commands::monitors::mute_matching(&cfg, "tag:noisy", Some("2h".into()), None).await;
+    assert!(result.is_ok(), "monitors mute failed: {:?}", result.err());
+    downtimes.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_slos_delete() {
+async fn test_monitors_bulk_sets_tags_and_resolves() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "DELETE", r#"{"data": []}"#).await;
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+    let _search = mock_monitor_search(&mut server, &[7, 8]).await;
+    let _get7 = server
+        .mock("GET", "/api/v1/monitor/7")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"id": 7, "tags": ["team-x", "owner:old"]}"#)
+        .create_async()
+        .await;
+    let _get8 = server
+        .mock("GET", "/api/v1/monitor/8")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"id": 8, "tags": ["owner:team-x"]}"#)
+        .create_async()
+        .await;
+    let retag = server
+        .mock("PUT", "/api/v1/monitor/7")
+        .match_body(mockito::Matcher::Json(
+            serde_json::json!({"tags": ["team-x", "owner:team-x"]}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"id": 7}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let already_tagged = server
+        .mock("PUT", "/api/v1/monitor/8")
+        .expect(0)
+        .create_async()
+        .await;
+    let resolve = server
+        .mock("POST", "/api/v1/monitor/bulk_resolve")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body("{}")
+        .expect(2)
+        .create_async()
+        .await;
 
-    let result = crate::commands::slos::delete(&cfg, "abc123").await;
-    assert!(result.is_ok(), "slos delete failed: {:?}", result.err());
+    let result = crate::commands::monitors::bulk(
+        &cfg,
+        "tag:team-x",
+        vec!["owner:team-x".into()],
+        true,
+        false,
+    )
+    .await;
+    assert!(result.is_ok(), "monitors bulk failed: {:?}", result.err());
+    retag.assert_async().await;
+    already_tagged.assert_async().await;
+    resolve.assert_async().await;
+
+    let err = crate::commands::monitors::bulk(&cfg, "tag:team-x", vec![], false, false)
+        .await
+        .unwrap_err();
+    assert!(err.to_string().contains("nothing to do"), "{err}");
     cleanup_env();
 }
 
-// -------------------------------------------------------------------------
-// Tags
-// -------------------------------------------------------------------------
-
 #[tokio::test]
-async fn test_tags_list() {
+async fn test_monitors_validate_reports_invalid_definitions() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "GET", r#"{"tags": {}}"#).await;
+    let good = std::env::temp_dir().join("pup_test_monitor_good.json");
+    let bad = std::env::temp_dir().join("pup_test_monitor_bad.json");
+    std::fs::write(
+        &good,
+        r#"{"type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{*} > 90", "name": "CPU"}"#,
+    )
+    .unwrap();
+    std::fs::write(
+        &bad,
+        r#"{"type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{*} >", "name": "Broken"}"#,
+    )
+    .unwrap();
+    let valid = server
+        .mock("POST", "/api/v1/monitor/validate")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"name": "CPU"}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body("{}")
+        .expect(1)
+        .create_async()
+        .await;
+    let invalid = server
+        .mock("POST", "/api/v1/monitor/validate")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"name": "Broken"}),
+        ))
+        .with_status(400)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"errors": ["The value provided for parameter 'query' is invalid"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
 
-    let result = crate::commands::tags::list(&cfg).await;
-    assert!(result.is_ok(), "tags list failed: {:?}", result.err());
+    let files = vec![
+        good.to_str().unwrap().to_string(),
+        bad.to_str().unwrap().to_string(),
+    ];
+    let err = crate::commands::monitors::validate(&cfg, &files)
+        .await
+        .unwrap_err();
+    assert!(
+        err.to_string()
+            .contains("1 of 2 monitor definitions are invalid"),
+        "{err}"
+    );
+    valid.assert_async().await;
+    invalid.assert_async().await;
+    std::fs::remove_file(&good).ok();
+    std::fs::remove_file(&bad).ok();
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_tags_get() {
+async fn test_monitors_import_creates_updates_and_skips_unchanged() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "GET",
-        r#"{"host": "myhost", "tags": ["env:prod", "service:web"]}"#,
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+    let dir = std::env::temp_dir().join("pup_test_monitors_import");
+    std::fs::remove_dir_all(&dir).ok();
+    std::fs::create_dir_all(&dir).unwrap();
+    std::fs::write(
+        dir.join("cpu.json"),
+        r#"{"name": "CPU", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{*} > 90"}"#,
+    )
+    .unwrap();
+    std::fs::write(
+        dir.join("disk.json"),
+        r#"{"name": "Disk", "type": "metric alert", "query": "avg(last_5m):avg:system.disk.in_use{*} > 0.95"}"#,
+    )
+    .unwrap();
+    std::fs::write(
+        dir.join("new.json"),
+        r#"{"name": "New", "type": "metric alert", "query": "avg(last_5m):avg:system.load.1{*} > 4"}"#,
     )
+    .unwrap();
+
+    let mut managed = |key: &str, body: &str| {
+        server
+            .mock("GET", "/api/v1/monitor")
+            .match_query(mockito::Matcher::UrlEncoded(
+                "monitor_tags".into(),
+                format!("pup_managed:{key}"),
+            ))
+            .with_status(200)
+            .with_header("content-type", "application/json")
+            .with_body(body.to_string())
+    };
+    let _cpu = managed(
+        "cpu",
+        r#"[{"id": 5, "name": "CPU", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{*} > 90", "tags": ["pup_managed:cpu"], "overall_state": "OK"}]"#,
+    )
+    .create_async()
     .await;
+    let _disk = managed(
+        "disk",
+        r#"[{"id": 6, "name": "Disk", "type": "metric alert", "query": "avg(last_5m):avg:system.disk.in_use{*} > 0.9", "tags": ["pup_managed:disk"]}]"#,
+    )
+    .create_async()
+    .await;
+    let _new = managed("new", "[]").create_async().await;
+    let untouched = server
+        .mock("PUT", "/api/v1/monitor/5")
+        .expect(0)
+        .create_async()
+        .await;
+    let update = server
+        .mock("PUT", "/api/v1/monitor/6")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "query": "avg(last_5m):avg:system.disk.in_use{*} > 0.95",
+            "tags": ["pup_managed:disk"]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"id": 6}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let create = server
+        .mock("POST", "/api/v1/monitor")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "name": "New",
+            "tags": ["pup_managed:new"]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"id": 7}"#)
+        .expect(1)
+        .create_async()
+        .await;
 
-    let result = crate::commands::tags::get(&cfg, "myhost").await;
-    assert!(result.is_ok(), "tags get failed: {:?}", result.err());
+    let result = crate::commands::monitors::import(&cfg, dir.to_str().unwrap()).await;
+    assert!(result.is_ok(), "monitors import failed: {:?}", result.err());
+    untouched.assert_async().await;
+    update.assert_async().await;
+    create.assert_async().await;
+    std::fs::remove_dir_all(&dir).ok();
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_tags_add() {
+async fn test_monitors_mute_with_scope() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "POST",
-        r#"{"host": "myhost", "tags": ["env:prod"]}"#,
-    )
-    .await;
+    let downtime = server
+        .mock("POST", "/api/v2/downtime")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"attributes": {
+                "scope": "env:prod",
+                "monitor_identifier": {"monitor_id": 7}
+            }}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"id": "dt-1", "type": "downtime"}}"#)
+        .expect(1)
+        .create_async()
+        .await;
 
-    let result = crate::commands::tags::add(&cfg, "myhost", vec!["env:prod".into()]).await;
-    assert!(result.is_ok(), "tags add failed: {:?}", result.err());
+    let result =
+        crate::commands::monitors::mute(&cfg, 7, Some("2h".into()), Some("env:prod".into())).await;
+    assert!(result.is_ok(), "monitors mute failed: {:?}", result.err());
+    downtime.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_tags_update() {
+async fn test_monitors_unmute_cancels_monitor_downtimes() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "PUT",
-        r#"{"host": "myhost", "tags": ["env:staging"]}"#,
-    )
-    .await;
+    let _list = server
+        .mock("GET", "/api/v2/downtime")
+        .match_query(mockito::Matcher::UrlEncoded(
+            "current_only".into(),
+            "true".into(),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                {"id": "dt-7", "type": "downtime", "attributes": {"scope": "*", "monitor_identifier": {"monitor_id": 7}}},
+                {"id": "dt-8", "type": "downtime", "attributes": {"scope": "*", "monitor_identifier": {"monitor_id": 8}}},
+                {"id": "dt-tags", "type": "downtime", "attributes": {"scope": "*", "monitor_identifier": {"monitor_tags": ["team:web"]}}}
+            ]}"#,
+        )
+        .create_async()
+        .await;
+    let cancel = server
+        .mock("DELETE", "/api/v2/downtime/dt-7")
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+    let others = server
+        .mock(
+            "DELETE",
+            mockito::Matcher::Regex("/api/v2/downtime/dt-(8|tags)".into()),
+        )
+        .expect(0)
+        .create_async()
+        .await;
 
-    let result = crate::commands::tags::update(&cfg, "myhost", vec!["env:staging".into()]).await;
-    assert!(result.is_ok(), "tags update failed: {:?}", result.err());
+    let result = crate::commands::monitors::unmute(&cfg, 7, None).await;
+    assert!(result.is_ok(), "monitors unmute failed: {:?}", result.err());
+    cancel.assert_async().await;
+    others.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_tags_delete() {
+async fn test_monitors_unmute_matching_reports_unmuted_monitors() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-
-    // Delete returns 204 No Content
-    let _mock = server
-        .mock("DELETE", mockito::Matcher::Any)
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+    let _search = mock_monitor_search(&mut server, &[7, 8]).await;
+    let _list = server
+        .mock("GET", "/api/v2/downtime")
         .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                {"id": "dt-7", "type": "downtime", "attributes": {"scope": "*", "monitor_identifier": {"monitor_id": 7}}}
+            ]}"#,
+        )
+        .create_async()
+        .await;
+    let cancel = server
+        .mock("DELETE", "/api/v2/downtime/dt-7")
         .with_status(204)
+        .expect(1)
         .create_async()
         .await;
 
-    let result = crate::commands::tags::delete(&cfg, "myhost").await;
-    assert!(result.is_ok(), "tags delete failed: {:?}", result.err());
+    crate::formatter::begin_capture();
+    let result = crate::commands::monitors::unmute_matching(&cfg, "tag:noisy", None).await;
+    let captured = crate::formatter::end_capture();
+    assert!(result.is_ok(), "monitors unmute failed: {:?}", result.err());
+    cancel.assert_async().await;
+    let rows = captured[0].as_array().unwrap();
+    assert_eq!(rows[0]["status"], "unmuted");
+    assert_eq!(rows[1]["status"], "not muted");
     cleanup_env();
 }
 
 // -------------------------------------------------------------------------
-// Events
+// Dashboards
 // -------------------------------------------------------------------------
 
 #[tokio::test]
-async fn test_events_list() {
+async fn test_dashboards_list() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "GET", r#"{"events": []}"#).await;
+    let _mock = mock_any(&mut server, "GET", r#"{"dashboards": []}"#).await;
 
-    let now = chrono::Utc::now().timestamp();
-    let result = crate::commands::events::list(&cfg, now - 3600, now, None).await;
-    assert!(result.is_ok(), "events list failed: {:?}", result.err());
+    let result = crate::commands::dashboards::list(&cfg, false).await;
+    assert!(result.is_ok(), "dashboards list failed: {:?}", result.err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_events_get() {
+async fn test_dashboards_get() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
     let _mock = mock_any(
         &mut server,
         "GET",
-        r#"{"event": {"id": 12345, "title": "Test Event", "text": "Something happened"}}"#,
+        r#"{"id": "abc-123", "title": "Test Dashboard", "layout_type": "ordered", "widgets": []}"#,
     )
     .await;
 
-    let result = crate::commands::events::get(&cfg, 12345).await;
-    assert!(result.is_ok(), "events get failed: {:?}", result.err());
+    let result = crate::commands::dashboards::get(&cfg, "abc-123").await;
+    assert!(result.is_ok(), "dashboards get failed: {:?}", result.err());
     cleanup_env();
 }
 
-// -------------------------------------------------------------------------
-// Logs (requires API keys)
-// -------------------------------------------------------------------------
-
 #[tokio::test]
-async fn test_logs_search() {
+async fn test_dashboards_delete() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "POST", r#"{"data": [], "meta": {"page": {}}}"#).await;
-
-    let result =
-        crate::commands::logs::search(&cfg, "status:error".into(), "1h".into(), "now".into(), 10)
-            .await;
-    assert!(result.is_ok(), "logs search failed: {:?}", result.err());
-    cleanup_env();
-}
-
-#[tokio::test]
-async fn test_logs_search_requires_api_keys() {
-    let _lock = lock_env();
-    let server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: None,
-        app_key: None,
-        access_token: Some("token".into()),
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
+    let _mock = mock_any(
+        &mut server,
+        "DELETE",
+        r#"{"deleted_dashboard_id": "abc-123"}"#,
+    )
+    .await;
 
-    let result =
-        crate::commands::logs::search(&cfg, "status:error".into(), "1h".into(), "now".into(), 10)
-            .await;
-    assert!(result.is_err(), "logs search should require API keys");
+    let result = crate::commands::dashboards::delete(&cfg, "abc-123").await;
     assert!(
-        result
-            .unwrap_err()
-            .to_string()
-            .contains("API+APP key authentication"),
-        "error should mention API key auth"
+        result.is_ok(),
+        "dashboards delete failed: {:?}",
+        result.err()
     );
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_logs_aggregate() {
-    let _lock = lock_env();
-    let mut server = mockito::Server::new_async().await;
-    let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "POST", r#"{"data": {"buckets": []}}"#).await;
-
-    let result =
-        crate::commands::logs::aggregate(&cfg, "*".into(), "1h".into(), "now".into()).await;
-    assert!(result.is_ok(), "logs aggregate failed: {:?}", result.err());
-    cleanup_env();
-}
-
-#[tokio::test]
-async fn test_logs_archives_list() {
+async fn test_dashboards_list_deleted() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "GET", r#"{"data": []}"#).await;
+    let mock = server
+        .mock("GET", "/api/v1/dashboard")
+        .match_query(mockito::Matcher::UrlEncoded(
+            "filter[deleted]".into(),
+            "true".into(),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"dashboards": [{"id": "abc-123", "title": "Gone"}]}"#)
+        .expect(1)
+        .create_async()
+        .await;
 
-    let result = crate::commands::logs::archives_list(&cfg).await;
+    let result = crate::commands::dashboards::list(&cfg, true).await;
     assert!(
         result.is_ok(),
-        "logs archives list failed: {:?}",
+        "dashboards list --deleted failed: {:?}",
         result.err()
     );
+    mock.assert_async().await;
     cleanup_env();
 }
 
+async fn mock_dashboard_get(server: &mut mockito::Server, id: &str) -> mockito::Mock {
+    server
+        .mock("GET", format!("/api/v1/dashboard/{id}").as_str())
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(format!(
+            r#"{{"id": "{id}", "title": "Restored", "layout_type": "ordered", "widgets": []}}"#
+        ))
+        .expect(1)
+        .create_async()
+        .await
+}
+
 #[tokio::test]
-async fn test_logs_custom_destinations_list() {
+async fn test_dashboards_restore() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "GET", r#"{"data": []}"#).await;
+    let restore = server
+        .mock("PATCH", "/api/v1/dashboard")
+        .match_body(mockito::Matcher::Json(serde_json::json!({"data": [
+            {"id": "abc-123", "type": "dashboard"},
+            {"id": "def-456", "type": "dashboard"}
+        ]})))
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+    let get_first = mock_dashboard_get(&mut server, "abc-123").await;
+    let get_second = mock_dashboard_get(&mut server, "def-456").await;
 
-    let result = crate::commands::logs::custom_destinations_list(&cfg).await;
+    let ids = vec!["abc-123".to_string(), "def-456".to_string()];
+    let result = crate::commands::dashboards::restore(&cfg, &ids).await;
     assert!(
         result.is_ok(),
-        "logs custom destinations list failed: {:?}",
+        "dashboards restore failed: {:?}",
         result.err()
     );
+    restore.assert_async().await;
+    get_first.assert_async().await;
+    get_second.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_logs_metrics_list() {
+async fn test_dashboards_export_import_round_trip() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "GET", r#"{"data": []}"#).await;
+    let file = std::env::temp_dir().join("pup_test_dashboard_export.yaml");
+    let get = server
+        .mock("GET", "/api/v1/dashboard/abc-123")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"id": "abc-123", "title": "Checkout", "layout_type": "free", "author_handle": "a@example.com",
+                "template_variables": [{"name": "env", "prefix": "env", "default": "prod"}],
+                "widgets": [{"id": 1, "definition": {"type": "note", "content": "hi"},
+                             "layout": {"x": 2, "y": 3, "width": 4, "height": 5}}]}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let update = server
+        .mock("PUT", "/api/v1/dashboard/def-456")
+        .match_body(mockito::Matcher::Json(serde_json::json!({
+            "title": "Checkout",
+            "layout_type": "free",
+            "template_variables": [{"name": "env", "prefix": "env", "default": "prod"}],
+            "widgets": [{"id": 1, "definition": {"type": "note", "content": "hi"},
+                         "layout": {"x": 2, "y": 3, "width": 4, "height": 5}}]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"id": "def-456", "title": "Checkout"}"#)
+        .expect(1)
+        .create_async()
+        .await;
 
-    let result = crate::commands::logs::metrics_list(&cfg).await;
+    let path = file.to_str().unwrap();
+    let result = crate::commands::dashboards::export(&cfg, "abc-123", path).await;
     assert!(
         result.is_ok(),
-        "logs metrics list failed: {:?}",
+        "dashboards export failed: {:?}",
+        result.err()
+    );
+    let result = crate::commands::dashboards::import(&cfg, path, Some("def-456")).await;
+    assert!(
+        result.is_ok(),
+        "dashboards import failed: {:?}",
         result.err()
     );
+    get.assert_async().await;
+    update.assert_async().await;
+    std::fs::remove_file(&file).ok();
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_logs_restriction_queries_list() {
+async fn test_dashboards_shares_create() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-
-    // restriction_queries_list uses raw HTTP (not DD client), so mock specific path
-    let _mock = server
-        .mock("GET", "/api/v2/logs/config/restriction_queries")
-        .match_query(mockito::Matcher::Any)
+    let get = server
+        .mock("GET", "/api/v1/dashboard/abc-123")
         .with_status(200)
         .with_header("content-type", "application/json")
-        .with_body(r#"{"data": []}"#)
+        .with_body(
+            r#"{"id": "abc-123", "title": "Checkout", "layout_type": "ordered", "widgets": []}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let create = server
+        .mock("POST", "/api/v1/dashboard/public")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "dashboard_id": "abc-123",
+            "dashboard_type": "custom_timeboard",
+            "share_type": "embed",
+            "embeddable_domains": ["https://status.example.com"]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"token": "tok-1", "public_url": "https://p.datadoghq.com/sb/tok-1"}"#)
+        .expect(1)
         .create_async()
         .await;
 
-    let result = crate::commands::logs::restriction_queries_list(&cfg).await;
+    let opts = crate::commands::dashboards::ShareOptions {
+        share_type: "embed".into(),
+        embeddable_domains: vec!["https://status.example.com".into()],
+        ..Default::default()
+    };
+    let result = crate::commands::dashboards::shares_create(&cfg, "abc-123", &opts).await;
     assert!(
         result.is_ok(),
-        "logs restriction queries list failed: {:?}",
+        "dashboards shares create failed: {:?}",
         result.err()
     );
+    get.assert_async().await;
+    create.assert_async().await;
     cleanup_env();
 }
 
 // -------------------------------------------------------------------------
-// Metrics
+// SLOs
 // -------------------------------------------------------------------------
 
 #[tokio::test]
-async fn test_metrics_list() {
+async fn test_slos_list() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "GET",
-        r#"{"metrics": [], "from": "2024-01-01T00:00:00Z"}"#,
-    )
-    .await;
+    let _mock = mock_any(&mut server, "GET", r#"{"data": [], "errors": []}"#).await;
 
-    let result = crate::commands::metrics::list(&cfg, None, "1h".into()).await;
-    assert!(result.is_ok(), "metrics list failed: {:?}", result.err());
+    let result = crate::commands::slos::list(&cfg).await;
+    assert!(result.is_ok(), "slos list failed: {:?}", result.err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_metrics_query() {
+async fn test_slos_get() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
     let _mock = mock_any(
         &mut server,
         "GET",
-        r#"{"status": "ok", "res_type": "time_series", "series": [], "from_date": 0, "to_date": 0, "query": "avg:system.cpu.user{*}"}"#,
+        r#"{"data": {"id": "abc123", "name": "Test SLO", "type": "metric", "thresholds": [{"timeframe": "7d", "target": 99.9}]}, "errors": []}"#,
     )
     .await;
 
-    let result = crate::commands::metrics::query(
-        &cfg,
-        "avg:system.cpu.user{*}".into(),
-        "1h".into(),
-        "now".into(),
-    )
-    .await;
-    assert!(result.is_ok(), "metrics query failed: {:?}", result.err());
+    let result = crate::commands::slos::get(&cfg, "abc123", None).await;
+    assert!(result.is_ok(), "slos get failed: {:?}", result.err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_metrics_metadata_get() {
+async fn test_slos_status_with_sli_breakdown() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(
-        &mut server,
-        "GET",
-        r#"{"type": "gauge", "description": "CPU usage", "unit": "percent"}"#,
-    )
-    .await;
+    let mock = server
+        .mock("GET", "/api/v1/slo/abc123/history")
+        .match_query(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::UrlEncoded("from_ts".into(), "1700000000".into()),
+            mockito::Matcher::UrlEncoded("to_ts".into(), "1700086400".into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"from_ts": 1700000000, "to_ts": 1700086400, "type": "monitor",
+                "groups": [
+                    {"group": "env:staging", "sli_value": 99.99, "error_budget_remaining": {"7d": 90.0}},
+                    {"group": "env:prod", "sli_value": 99.1, "error_budget_remaining": {"7d": -5.0}}
+                ]}, "errors": []}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
 
-    let result = crate::commands::metrics::metadata_get(&cfg, "system.cpu.user").await;
+    let result = crate::commands::slos::status(&cfg, "abc123", 1700000000, 1700086400, true).await;
     assert!(
         result.is_ok(),
-        "metrics metadata get failed: {:?}",
+        "slos status breakdown failed: {:?}",
         result.err()
     );
+    mock.assert_async().await;
     cleanup_env();
 }
 
-// -------------------------------------------------------------------------
-// Events search (requires API keys)
-// -------------------------------------------------------------------------
-
 #[tokio::test]
-async fn test_events_search() {
+async fn test_slos_history_by_query() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
     let cfg = test_config(&server.url());
-    let _mock = mock_any(&mut server, "POST", r#"{"data": [], "meta": {"page": {}}}"#).await;
+    let list = server
+        .mock("GET", "/api/v1/slo")
+        .match_query(mockito::Matcher::UrlEncoded(
+            "query".into(),
+            "checkout".into(),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                {"id": "slo-a", "name": "Checkout API", "type": "monitor", "thresholds": [{"timeframe": "7d", "target": 99.9}]},
+                {"id": "slo-b", "name": "Checkout UI", "type": "monitor", "thresholds": [{"timeframe": "7d", "target": 99.0}]}
+            ], "errors": []}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let mut history = |id: &str| {
+        server
+            .mock("GET", format!("/api/v1/slo/{id}/history").as_str())
+            .match_query(mockito::Matcher::Any)
+            .with_status(200)
+            .with_header("content-type", "application/json")
+            .with_body(
+                r#"{"data": {"from_ts": 1700000000, "to_ts": 1700604800, "type": "monitor",
+                    "overall": {"sli_value": 99.95, "error_budget_remaining": {"7d": 50.0}}}, "errors": []}"#,
+            )
+            .expect(1)
+    };
+    let first = history("slo-a").create_async().await;
+    let second = history("slo-b").create_async().await;
 
     let result =
-        crate::commands::events::search(&cfg, "source:nginx".into(), "1h".into(), "now".into(), 10)
-            .await;
-    assert!(result.is_ok(), "events search failed: {:?}", result.err());
+        crate::commands::slos::history(&cfg, None, Some("checkout"), 1700000000, 1700604800).await;
+    assert!(result.is_ok(), "slos history failed: {:?}", result.err());
+    list.assert_async().await;
+    first.assert_async().await;
+    second.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_events_search_requires_api_keys() {
+async fn test_slos_delete() {
     let _lock = lock_env();
-    let server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: None,
-        app_key: None,
-        access_token: Some("token".into()),
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "DELETE", r#"{"data": []}"#).await;
 
-    let result =
-        crate::commands::events::search(&cfg, "source:nginx".into(), "1h".into(), "now".into(), 10)
-            .await;
-    assert!(result.is_err(), "events search should require API keys");
+    let result = crate::commands::slos::delete(&cfg, "abc123").await;
+    assert!(result.is_ok(), "slos delete failed: {:?}", result.err());
     cleanup_env();
 }
 
-// =========================================================================
-// Raw HTTP api module tests — these use the api.rs module directly
-// (not the DD client library), so we can mock specific paths precisely.
-// =========================================================================
-
 #[tokio::test]
-async fn test_api_get() {
+async fn test_slos_create_from_monitor() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
-
-    let mock = server
-        .mock("GET", "/api/v1/test")
+    let cfg = test_config(&server.url());
+    let monitor = server
+        .mock("GET", "/api/v1/monitor/123")
+        .match_query(mockito::Matcher::Any)
         .with_status(200)
         .with_header("content-type", "application/json")
-        .with_body(r#"{"status": "ok"}"#)
+        .with_body(
+            r#"{"id": 123, "name": "Checkout errors", "type": "metric alert", "query": "avg(last_5m):avg:checkout.errors{*} > 5", "message": "", "tags": ["team:payments"], "options": {}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let create = server
+        .mock("POST", "/api/v1/slo")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "type": "monitor",
+            "name": "Checkout errors",
+            "monitor_ids": [123],
+            "thresholds": [{"timeframe": "30d", "target": 99.9}]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": [{"id": "slo-1", "name": "Checkout errors", "type": "monitor", "thresholds": []}]}"#)
+        .expect(1)
         .create_async()
         .await;
 
-    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
-    assert!(result.is_ok(), "api get failed: {:?}", result.err());
-    let val = result.unwrap();
-    assert_eq!(val["status"], "ok");
-    mock.assert_async().await;
+    let opts = crate::commands::slos::FromMonitor {
+        monitor_id: 123,
+        target: 99.9,
+        timeframe: "30d".into(),
+        name: None,
+        description: None,
+    };
+    let result = crate::commands::slos::create_from_monitor(&cfg, opts).await;
+    assert!(
+        result.is_ok(),
+        "create from monitor failed: {:?}",
+        result.err()
+    );
+    monitor.assert_async().await;
+    create.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_get_with_query() {
+async fn test_slos_create_from_monitor_invalid_target() {
     let _lock = lock_env();
-    let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+    let server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
 
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
+    let opts = crate::commands::slos::FromMonitor {
+        monitor_id: 123,
+        target: 120.0,
+        timeframe: "30d".into(),
+        name: None,
+        description: None,
     };
+    let result = crate::commands::slos::create_from_monitor(&cfg, opts).await;
+    assert!(result.is_err());
+    cleanup_env();
+}
 
+#[tokio::test]
+async fn test_slos_corrections_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
     let mock = server
-        .mock("GET", "/api/v1/search")
-        .match_query(mockito::Matcher::Any)
+        .mock("GET", "/api/v1/slo/abc123/corrections")
         .with_status(200)
         .with_header("content-type", "application/json")
-        .with_body(r#"{"results": []}"#)
+        .with_body(r#"{"data": [{"id": "corr-1", "type": "correction"}]}"#)
         .create_async()
         .await;
 
-    let query = vec![("q", "test".to_string())];
-    let result = crate::api::get(&cfg, "/api/v1/search", &query).await;
+    let result = crate::commands::slos::corrections_list(&cfg, "abc123").await;
     assert!(
         result.is_ok(),
-        "api get with query failed: {:?}",
+        "corrections list failed: {:?}",
         result.err()
     );
     mock.assert_async().await;
@@ -665,1102 +1106,5125 @@ async fn test_api_get_with_query() {
 }
 
 #[tokio::test]
-async fn test_api_post() {
+async fn test_slos_corrections_create() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
-
+    let cfg = test_config(&server.url());
     let mock = server
-        .mock("POST", "/api/v2/test")
+        .mock("POST", "/api/v1/slo/correction")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {
+                "type": "correction",
+                "attributes": {
+                    "slo_id": "abc123",
+                    "start": 1700000000,
+                    "end": 1700003600,
+                    "category": "Scheduled Maintenance"
+                }
+            }
+        })))
         .with_status(200)
         .with_header("content-type", "application/json")
-        .with_body(r#"{"created": true}"#)
+        .with_body(r#"{"data": {"id": "corr-1", "type": "correction"}}"#)
         .create_async()
         .await;
 
-    let body = serde_json::json!({"name": "test"});
-    let result = crate::api::post(&cfg, "/api/v2/test", &body).await;
-    assert!(result.is_ok(), "api post failed: {:?}", result.err());
+    let result = crate::commands::slos::corrections_create(
+        &cfg,
+        "abc123",
+        1700000000,
+        Some(1700003600),
+        "scheduled_maintenance",
+        None,
+        None,
+    )
+    .await;
+    assert!(
+        result.is_ok(),
+        "corrections create failed: {:?}",
+        result.err()
+    );
     mock.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_put() {
+async fn test_slos_corrections_create_invalid_category() {
     let _lock = lock_env();
-    let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
-
-    let mock = server
-        .mock("PUT", "/api/v1/test/123")
-        .with_status(200)
-        .with_header("content-type", "application/json")
-        .with_body(r#"{"updated": true}"#)
-        .create_async()
-        .await;
+    let server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
 
-    let body = serde_json::json!({"name": "updated"});
-    let result = crate::api::put(&cfg, "/api/v1/test/123", &body).await;
-    assert!(result.is_ok(), "api put failed: {:?}", result.err());
-    mock.assert_async().await;
+    let result = crate::commands::slos::corrections_create(
+        &cfg,
+        "abc123",
+        100,
+        Some(200),
+        "holiday",
+        None,
+        None,
+    )
+    .await;
+    assert!(result.is_err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_patch() {
+async fn test_slos_corrections_update_recurring() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
-
+    let cfg = test_config(&server.url());
     let mock = server
-        .mock("PATCH", "/api/v1/test/123")
+        .mock("PATCH", "/api/v1/slo/correction/corr-1")
+        .match_body(mockito::Matcher::Json(serde_json::json!({
+            "data": {
+                "type": "correction",
+                "attributes": {
+                    "rrule": "FREQ=WEEKLY;BYDAY=SU",
+                    "duration": 7200
+                }
+            }
+        })))
         .with_status(200)
         .with_header("content-type", "application/json")
-        .with_body(r#"{"patched": true}"#)
+        .with_body(r#"{"data": {"id": "corr-1", "type": "correction"}}"#)
+        .expect(1)
         .create_async()
         .await;
 
-    let body = serde_json::json!({"name": "patched"});
-    let result = crate::api::patch(&cfg, "/api/v1/test/123", &body).await;
-    assert!(result.is_ok(), "api patch failed: {:?}", result.err());
+    let update = crate::commands::slos::CorrectionUpdate {
+        recurrence: Some(crate::commands::slos::CorrectionRecurrence {
+            rrule: "FREQ=WEEKLY;BYDAY=SU".into(),
+            duration: 7200,
+        }),
+        ..Default::default()
+    };
+    let result = crate::commands::slos::corrections_update(&cfg, "corr-1", &update).await;
+    assert!(
+        result.is_ok(),
+        "corrections update failed: {:?}",
+        result.err()
+    );
     mock.assert_async().await;
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_delete() {
+async fn test_slos_corrections_delete() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
-
+    let cfg = test_config(&server.url());
     let mock = server
-        .mock("DELETE", "/api/v1/test/123")
-        .with_status(200)
-        .with_header("content-type", "application/json")
-        .with_body(r#"{"deleted": true}"#)
+        .mock("DELETE", "/api/v1/slo/correction/corr-1")
+        .with_status(204)
         .create_async()
         .await;
 
-    let result = crate::api::delete(&cfg, "/api/v1/test/123").await;
-    assert!(result.is_ok(), "api delete failed: {:?}", result.err());
+    let result = crate::commands::slos::corrections_delete(&cfg, "corr-1").await;
+    assert!(
+        result.is_ok(),
+        "corrections delete failed: {:?}",
+        result.err()
+    );
     mock.assert_async().await;
     cleanup_env();
 }
 
+// -------------------------------------------------------------------------
+// Tags
+// -------------------------------------------------------------------------
+
 #[tokio::test]
-async fn test_api_error_response() {
+async fn test_tags_list() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
-
-    let mock = server
-        .mock("GET", "/api/v1/test/missing")
-        .with_status(404)
-        .with_header("content-type", "application/json")
-        .with_body(r#"{"errors": ["not found"]}"#)
-        .create_async()
-        .await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "GET", r#"{"tags": {}}"#).await;
 
-    let result = crate::api::get(&cfg, "/api/v1/test/missing", &[]).await;
-    assert!(result.is_err(), "should return error for 404");
-    assert!(result.unwrap_err().to_string().contains("404"));
-    mock.assert_async().await;
+    let result = crate::commands::tags::list(&cfg).await;
+    assert!(result.is_ok(), "tags list failed: {:?}", result.err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_bearer_auth() {
+async fn test_tags_get() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "GET",
+        r#"{"host": "myhost", "tags": ["env:prod", "service:web"]}"#,
+    )
+    .await;
 
-    let cfg = Config {
-        api_key: None,
-        app_key: None,
-        access_token: Some("test-bearer-token".into()),
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
+    let result = crate::commands::tags::get(&cfg, "myhost").await;
+    assert!(result.is_ok(), "tags get failed: {:?}", result.err());
+    cleanup_env();
+}
 
-    let mock = server
-        .mock("GET", "/api/v1/test")
-        .match_header("Authorization", "Bearer test-bearer-token")
-        .with_status(200)
-        .with_header("content-type", "application/json")
-        .with_body(r#"{"auth": "bearer"}"#)
-        .create_async()
-        .await;
+#[tokio::test]
+async fn test_tags_add() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "POST",
+        r#"{"host": "myhost", "tags": ["env:prod"]}"#,
+    )
+    .await;
 
-    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
-    assert!(result.is_ok(), "bearer auth failed: {:?}", result.err());
-    mock.assert_async().await;
+    let result = crate::commands::tags::add(&cfg, "myhost", vec!["env:prod".into()]).await;
+    assert!(result.is_ok(), "tags add failed: {:?}", result.err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_no_auth() {
+async fn test_tags_update() {
     let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "PUT",
+        r#"{"host": "myhost", "tags": ["env:staging"]}"#,
+    )
+    .await;
 
-    let cfg = Config {
-        api_key: None,
-        app_key: None,
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
-
-    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
-    assert!(result.is_err(), "should fail without auth");
-    assert!(
-        result.unwrap_err().to_string().contains("authentication"),
-        "error should mention authentication"
-    );
+    let result = crate::commands::tags::update(&cfg, "myhost", vec!["env:staging".into()]).await;
+    assert!(result.is_ok(), "tags update failed: {:?}", result.err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_empty_response() {
+async fn test_tags_delete() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
-
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
+    let cfg = test_config(&server.url());
 
-    let mock = server
-        .mock("DELETE", "/api/v1/test/empty")
+    // Delete returns 204 No Content
+    let _mock = server
+        .mock("DELETE", mockito::Matcher::Any)
+        .match_query(mockito::Matcher::Any)
         .with_status(204)
-        .with_body("")
         .create_async()
         .await;
 
-    let result = crate::api::delete(&cfg, "/api/v1/test/empty").await;
-    assert!(result.is_ok(), "empty response failed: {:?}", result.err());
-    let val = result.unwrap();
-    assert_eq!(val, serde_json::json!({}));
-    mock.assert_async().await;
+    let result = crate::commands::tags::delete(&cfg, "myhost").await;
+    assert!(result.is_ok(), "tags delete failed: {:?}", result.err());
     cleanup_env();
 }
 
 #[tokio::test]
-async fn test_api_server_error() {
+async fn test_tags_apply_reports_per_host_failures() {
     let _lock = lock_env();
     let mut server = mockito::Server::new_async().await;
-    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+    let cfg = test_config(&server.url());
+    let users = || mockito::Matcher::UrlEncoded("source".into(), "users".into());
+    let _get = server
+        .mock("GET", "/api/v1/tags/hosts/web-1")
+        .match_query(users())
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"host": "web-1", "tags": ["env:prod", "legacy:true"]}"#)
+        .create_async()
+        .await;
+    let put = server
+        .mock("PUT", "/api/v1/tags/hosts/web-1")
+        .match_query(users())
+        .match_body(mockito::Matcher::Json(
+            serde_json::json!({"tags": ["env:prod", "team:core"]}),
+        ))
+        .with_status(201)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"host": "web-1", "tags": ["env:prod", "team:core"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let _missing = server
+        .mock("GET", "/api/v1/tags/hosts/web-2")
+        .match_query(users())
+        .with_status(404)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"errors": ["Not found"]}"#)
+        .create_async()
+        .await;
 
-    let cfg = Config {
-        api_key: Some("test-key".into()),
-        app_key: Some("test-app".into()),
-        access_token: None,
-        site: "datadoghq.com".into(),
-        output_format: OutputFormat::Json,
-        auto_approve: false,
-        agent_mode: false,
-    };
+    let hosts = vec!["web-1".to_string(), "web-2".to_string()];
+    let result = crate::commands::tags::apply(
+        &cfg,
+        &hosts,
+        &["team:core".to_string()],
+        &["legacy".to_string()],
+    )
+    .await;
+    let err = result.expect_err("one host failed").to_string();
+    assert!(err.contains("1 of 2 hosts"), "{err}");
+    put.assert_async().await;
+    cleanup_env();
+}
+
+// -------------------------------------------------------------------------
+// Events
+// -------------------------------------------------------------------------
+
+#[tokio::test]
+async fn test_events_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "GET", r#"{"events": []}"#).await;
+
+    let now = chrono::Utc::now().timestamp();
+    let result = crate::commands::events::list(&cfg, now - 3600, now, None).await;
+    assert!(result.is_ok(), "events list failed: {:?}", result.err());
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_events_get() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "GET",
+        r#"{"event": {"id": 12345, "title": "Test Event", "text": "Something happened"}}"#,
+    )
+    .await;
+
+    let result = crate::commands::events::get(&cfg, 12345).await;
+    assert!(result.is_ok(), "events get failed: {:?}", result.err());
+    cleanup_env();
+}
+
+// -------------------------------------------------------------------------
+// Logs (requires API keys)
+// -------------------------------------------------------------------------
+
+#[tokio::test]
+async fn test_logs_search() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "POST", r#"{"data": [], "meta": {"page": {}}}"#).await;
+
+    let result = crate::commands::logs::search(
+        &cfg,
+        "status:error".into(),
+        "1h".into(),
+        "now".into(),
+        10,
+        None,
+        None,
+    )
+    .await;
+    assert!(result.is_ok(), "logs search failed: {:?}", result.err());
+    cleanup_env();
+}
 
+#[tokio::test]
+async fn test_logs_search_chunked() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    // Three 1h chunks, each returning two logs; the limit of 5 stops the merge early
+    // on the third chunk, which is asked for only the one remaining log.
     let mock = server
-        .mock("GET", "/api/v1/test")
-        .with_status(500)
-        .with_body(r#"{"errors": ["internal server error"]}"#)
+        .mock("POST", mockito::Matcher::Any)
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [{"id": "a", "type": "log"}, {"id": "b", "type": "log"}], "meta": {"page": {}}}"#,
+        )
+        .expect(3)
         .create_async()
         .await;
 
-    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
+    let result = crate::commands::logs::search(
+        &cfg,
+        "status:error".into(),
+        "2024-01-01T00:00:00Z".into(),
+        "2024-01-01T03:00:00Z".into(),
+        5,
+        Some("1h".into()),
+        None,
+    )
+    .await;
+    assert!(
+        result.is_ok(),
+        "chunked logs search failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_search_chunk_pages_each_window() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mut search = |body: serde_json::Value, response: &str| {
+        server
+            .mock("POST", "/api/v2/logs/events/search")
+            .match_body(mockito::Matcher::PartialJson(body))
+            .with_status(200)
+            .with_header("content-type", "application/json")
+            .with_body(response.to_string())
+            .expect(1)
+    };
+    // Newest window [01:00, 02:00] spans two pages; the older window ends
+    // a millisecond before 01:00 so the boundary log isn't fetched twice
+    let newer_page_2 = search(
+        serde_json::json!({"page": {"cursor": "next"}}),
+        r#"{"data": [{"id": "b", "type": "log"}], "meta": {"page": {}}}"#,
+    )
+    .create_async()
+    .await;
+    let newer = search(
+        serde_json::json!({"filter": {"from": "1704070800000", "to": "1704074400000"}}),
+        r#"{"data": [{"id": "a", "type": "log"}], "meta": {"page": {"after": "next"}}}"#,
+    )
+    .create_async()
+    .await;
+    let older = search(
+        serde_json::json!({"filter": {"from": "1704067200000", "to": "1704070799999"}}),
+        r#"{"data": [{"id": "c", "type": "log"}], "meta": {"page": {}}}"#,
+    )
+    .create_async()
+    .await;
+
+    crate::formatter::begin_capture();
+    let result = crate::commands::logs::search(
+        &cfg,
+        "status:error".into(),
+        "2024-01-01T00:00:00Z".into(),
+        "2024-01-01T02:00:00Z".into(),
+        10,
+        Some("1h".into()),
+        None,
+    )
+    .await;
+    let captured = crate::formatter::end_capture();
+    assert!(result.is_ok(), "chunked search failed: {:?}", result.err());
+    let ids: Vec<&str> = captured[0]["data"]
+        .as_array()
+        .unwrap()
+        .iter()
+        .filter_map(|log| log["id"].as_str())
+        .collect();
+    assert_eq!(ids, ["a", "b", "c"]);
+    for mock in [newer_page_2, newer, older] {
+        mock.assert_async().await;
+    }
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_search_all_tiers_parallel() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mut mocks = Vec::new();
+    for (tier, status, body) in [
+        (
+            "indexes",
+            200,
+            r#"{"data": [{"id": "a", "type": "log", "attributes": {"timestamp": "2024-01-01T00:02:00Z"}}]}"#,
+        ),
+        (
+            "online-archives",
+            200,
+            r#"{"data": [{"id": "b", "type": "log", "attributes": {"timestamp": "2024-01-01T00:01:00Z"}}]}"#,
+        ),
+        // A tier the org can't search is skipped rather than failing the search.
+        ("flex", 403, r#"{"errors": ["Forbidden"]}"#),
+    ] {
+        let mock = server
+            .mock("POST", "/api/v2/logs/events/search")
+            .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+                "filter": {"storage_tier": tier}
+            })))
+            .with_status(status)
+            .with_header("content-type", "application/json")
+            .with_body(body)
+            .expect(1)
+            .create_async()
+            .await;
+        mocks.push(mock);
+    }
+
+    let result = crate::commands::logs::search(
+        &cfg,
+        "status:error".into(),
+        "1h".into(),
+        "now".into(),
+        10,
+        None,
+        Some("all-parallel".into()),
+    )
+    .await;
+    assert!(
+        result.is_ok(),
+        "parallel logs search failed: {:?}",
+        result.err()
+    );
+    for mock in mocks {
+        mock.assert_async().await;
+    }
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_export_streams_every_page() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let first = server
+        .mock("POST", "/api/v2/logs/events/search")
+        .match_body(mockito::Matcher::Regex(r#""page":\{"limit":1000\}"#.into()))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [{"id": "a", "type": "log"}, {"id": "b", "type": "log"}],
+                "meta": {"page": {"after": "page-2"}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let second = server
+        .mock("POST", "/api/v2/logs/events/search")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"page": {"cursor": "page-2"}}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": [{"id": "c", "type": "log"}], "meta": {"page": {}}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let path = std::env::temp_dir().join("pup_test_logs_export.ndjson");
+    let result = crate::commands::logs::export(
+        &cfg,
+        "status:error".into(),
+        "30d".into(),
+        "now".into(),
+        path.to_str().unwrap(),
+        crate::commands::logs::ExportFormat::Ndjson,
+    )
+    .await;
+    assert!(result.is_ok(), "logs export failed: {:?}", result.err());
+    first.assert_async().await;
+    second.assert_async().await;
+
+    let written = std::fs::read_to_string(&path).unwrap();
+    let ids: Vec<String> = written
+        .lines()
+        .map(|line| serde_json::from_str::<serde_json::Value>(line).unwrap()["id"].to_string())
+        .collect();
+    assert_eq!(ids, [r#""a""#, r#""b""#, r#""c""#]);
+    let _ = std::fs::remove_file(&path);
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_export_csv() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "POST",
+        r#"{"data": [{"id": "a", "type": "log", "attributes": {
+                "timestamp": "2024-01-01T00:00:00Z", "status": "error",
+                "service": "api", "message": "boom, again", "tags": ["env:prod"]}}],
+            "meta": {"page": {}}}"#,
+    )
+    .await;
+
+    let mut out = Vec::new();
+    let total = crate::commands::logs::export_to(
+        &cfg,
+        "status:error",
+        "1h",
+        "now",
+        crate::commands::logs::ExportFormat::Csv,
+        &mut out,
+    )
+    .await
+    .unwrap();
+    assert_eq!(total, 1);
+    let csv = String::from_utf8(out).unwrap();
+    let lines: Vec<&str> = csv.lines().collect();
+    assert_eq!(lines[0], "timestamp,status,service,host,message,tags,id");
+    assert!(lines[1].starts_with("2024-01-01T00:00:00"), "{csv}");
+    assert!(
+        lines[1].ends_with(r#",error,api,,"boom, again",env:prod,a"#),
+        "{csv}"
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_export_checks_auth_before_creating_file() {
+    let _lock = lock_env();
+    let server = mockito::Server::new_async().await;
+    let mut cfg = test_config(&server.url());
+    cfg.api_key = None;
+    let path = std::env::temp_dir().join("pup_test_export_no_auth.ndjson");
+    let _ = std::fs::remove_file(&path);
+
+    let result = crate::commands::logs::export(
+        &cfg,
+        "status:error".into(),
+        "1h".into(),
+        "now".into(),
+        path.to_str().unwrap(),
+        crate::commands::logs::ExportFormat::Ndjson,
+    )
+    .await;
     assert!(result.is_err());
-    assert!(result.unwrap_err().to_string().contains("500"));
+    assert!(
+        !path.exists(),
+        "a failed export must not leave a file behind"
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_tail_poll_pages_and_skips_seen() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let first = server
+        .mock("POST", "/api/v2/logs/events/search")
+        .match_body(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::PartialJson(serde_json::json!({"sort": "timestamp"})),
+            mockito::Matcher::Regex(r#""page":\{"limit":1000\}"#.into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                  {"id": "a", "type": "log", "attributes": {"timestamp": "2024-01-01T00:00:01Z"}},
+                  {"id": "b", "type": "log", "attributes": {"timestamp": "2024-01-01T00:00:02Z"}}],
+                "meta": {"page": {"after": "page-2"}}}"#,
+        )
+        .expect(2)
+        .create_async()
+        .await;
+    let second = server
+        .mock("POST", "/api/v2/logs/events/search")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"page": {"cursor": "page-2"}}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                  {"id": "c", "type": "log", "attributes": {"timestamp": "2024-01-01T00:00:02Z"}}],
+                "meta": {"page": {}}}"#,
+        )
+        .expect(2)
+        .create_async()
+        .await;
+
+    let start = chrono::DateTime::parse_from_rfc3339("2024-01-01T00:00:00Z")
+        .unwrap()
+        .timestamp_millis();
+    let mut cursor = crate::commands::events::StreamCursor::starting_at(start);
+    let ids = |logs: Vec<serde_json::Value>| -> Vec<String> {
+        logs.iter()
+            .map(|l| l["id"].as_str().unwrap().to_string())
+            .collect()
+    };
+
+    let logs = crate::commands::logs::tail_poll(&cfg, "service:api", &mut cursor)
+        .await
+        .unwrap();
+    assert_eq!(ids(logs), ["a", "b", "c"]);
+    // The next poll returns the same logs again; all were already printed.
+    let logs = crate::commands::logs::tail_poll(&cfg, "service:api", &mut cursor)
+        .await
+        .unwrap();
+    assert!(logs.is_empty());
+    first.assert_async().await;
+    second.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_tail_without_follow() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/logs/events/search")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"sort": "-timestamp", "page": {"limit": 20}}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": [], "meta": {"page": {}}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::tail(&cfg, "*".into(), "15m".into(), 20, false, "5s").await;
+    assert!(result.is_ok(), "logs tail failed: {:?}", result.err());
     mock.assert_async().await;
     cleanup_env();
 }
 
-// =========================================================================
-// Bulk command module tests — exercise list/get operations for all remaining
-// command modules to maximize coverage. The mock_any helper catches all
-// requests. We use `let _ =` instead of asserting success because some DD
-// client types may not deserialize our minimal responses — the important
-// thing is that the command code paths are exercised.
-// =========================================================================
-
-/// Mock all HTTP methods with the same response body.
-async fn mock_all(s: &mut mockito::Server, body: &str) {
-    for method in &["GET", "POST", "PUT", "PATCH", "DELETE"] {
-        s.mock(method, mockito::Matcher::Any)
-            .match_query(mockito::Matcher::Any)
-            .with_status(200)
-            .with_header("content-type", "application/json")
-            .with_body(body)
-            .create_async()
-            .await;
-    }
+#[tokio::test]
+async fn test_logs_search_chunk_too_small() {
+    let _lock = lock_env();
+    let server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+
+    let result = crate::commands::logs::search(
+        &cfg,
+        "*".into(),
+        "90d".into(),
+        "now".into(),
+        10,
+        Some("1m".into()),
+        None,
+    )
+    .await;
+    assert!(result.is_err(), "90d in 1m chunks should be rejected");
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_search_requires_api_keys() {
+    let _lock = lock_env();
+    let server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: None,
+        app_key: None,
+        access_token: Some("token".into()),
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let result = crate::commands::logs::search(
+        &cfg,
+        "status:error".into(),
+        "1h".into(),
+        "now".into(),
+        10,
+        None,
+        None,
+    )
+    .await;
+    assert!(result.is_err(), "logs search should require API keys");
+    assert!(
+        result
+            .unwrap_err()
+            .to_string()
+            .contains("API+APP key authentication"),
+        "error should mention API key auth"
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_metrics_create_from_flags() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/logs/config/metrics")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"id": "api.errors", "type": "logs_metrics", "attributes": {
+                "compute": {"aggregation_type": "count"},
+                "filter": {"query": "service:api status:error"},
+                "group_by": [{"path": "@http.status_code", "tag_name": "status"}]
+            }}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"id": "api.errors", "type": "logs_metrics", "attributes": {
+                "compute": {"aggregation_type": "count"},
+                "filter": {"query": "service:api status:error"}}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let flags = crate::commands::logs::LogMetricFlags {
+        query: Some("service:api status:error".into()),
+        group_by: Some("@http.status_code=status".into()),
+        ..Default::default()
+    };
+    let body = crate::commands::logs::log_metric_body(None, Some("api.errors"), &flags).unwrap();
+    let result = crate::commands::logs::metrics_create(&cfg, body).await;
+    assert!(
+        result.is_ok(),
+        "logs metrics create failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_archives_update_merges_flags() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let archive = r#"{"data": {"type": "archives", "id": "arch-1", "attributes": {
+        "name": "prod", "query": "env:prod", "state": "WORKING", "include_tags": false,
+        "destination": {"type": "s3", "bucket": "logs", "path": "/prod",
+            "integration": {"account_id": "123", "role_name": "archiver"}}}}}"#;
+    let get = server
+        .mock("GET", "/api/v2/logs/config/archives/arch-1")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(archive)
+        .expect(1)
+        .create_async()
+        .await;
+    let put = server
+        .mock("PUT", "/api/v2/logs/config/archives/arch-1")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"type": "archives", "attributes": {
+                "name": "prod",
+                "query": "env:prod service:api",
+                "rehydration_tags": ["team:core"],
+                "destination": {"type": "s3", "bucket": "logs"}
+            }}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(archive)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let current = crate::commands::logs::archives_current(&cfg, "arch-1")
+        .await
+        .unwrap();
+    let body = crate::commands::logs::archive_body(
+        Some(current),
+        None,
+        Some("env:prod service:api"),
+        None,
+        Some("team:core"),
+        None,
+    )
+    .unwrap();
+    let result = crate::commands::logs::archives_update(&cfg, "arch-1", body).await;
+    assert!(result.is_ok(), "archives update failed: {:?}", result.err());
+    get.assert_async().await;
+    put.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_archives_order() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let get = server
+        .mock("GET", "/api/v2/logs/config/archive-order")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"type": "archive_order", "attributes": {"archive_ids": ["a", "b", "c"]}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let put = server
+        .mock("PUT", "/api/v2/logs/config/archive-order")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"type": "archive_order", "attributes": {"archive_ids": ["b", "a", "c"]}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"type": "archive_order", "attributes": {"archive_ids": ["b", "a", "c"]}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::archives_order(&cfg, &["b".into()]).await;
+    assert!(result.is_ok(), "archives order failed: {:?}", result.err());
+    get.assert_async().await;
+    put.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_indexes_update_merges_flags() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let index = r#"{"name": "main", "filter": {"query": "*"}, "daily_limit": 500,
+        "num_retention_days": 15, "is_rate_limited": false,
+        "exclusion_filters": [{"name": "debug", "is_enabled": true,
+            "filter": {"query": "status:debug", "sample_rate": 1.0}}]}"#;
+    let get = server
+        .mock("GET", "/api/v1/logs/config/indexes/main")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(index)
+        .expect(1)
+        .create_async()
+        .await;
+    let put = server
+        .mock("PUT", "/api/v1/logs/config/indexes/main")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "filter": {"query": "*"},
+            "daily_limit": 1000,
+            "num_retention_days": 15,
+            "exclusion_filters": [
+                {"name": "debug", "is_enabled": true,
+                 "filter": {"query": "status:debug", "sample_rate": 1.0}},
+                {"name": "health", "is_enabled": true,
+                 "filter": {"query": "@http.url:/health", "sample_rate": 0.5}}
+            ]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(index)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let changes = crate::commands::logs::IndexChanges {
+        daily_limit: Some(1000),
+        exclusion: Some(crate::commands::logs::ExclusionFilter {
+            name: "health".into(),
+            query: "@http.url:/health".into(),
+            sample_rate: 0.5,
+            enabled: true,
+        }),
+        ..Default::default()
+    };
+    let result = crate::commands::logs::indexes_update(&cfg, "main", None, &changes).await;
+    assert!(result.is_ok(), "indexes update failed: {:?}", result.err());
+    get.assert_async().await;
+    put.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_indexes_order() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let get = server
+        .mock("GET", "/api/v1/logs/config/index-order")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"index_names": ["main", "audit", "debug"]}"#)
+        .expect(2)
+        .create_async()
+        .await;
+    let put = server
+        .mock("PUT", "/api/v1/logs/config/index-order")
+        .match_body(mockito::Matcher::Json(
+            serde_json::json!({"index_names": ["debug", "main", "audit"]}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"index_names": ["debug", "main", "audit"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    // Without names the order is only shown.
+    let result = crate::commands::logs::indexes_order(&cfg, &[]).await;
+    assert!(result.is_ok(), "indexes order failed: {:?}", result.err());
+    let result = crate::commands::logs::indexes_order(&cfg, &["debug".into()]).await;
+    assert!(result.is_ok(), "indexes order failed: {:?}", result.err());
+    get.assert_async().await;
+    put.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_pipelines_reorder() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let get = server
+        .mock("GET", "/api/v1/logs/config/pipeline-order")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"pipeline_ids": ["a", "b", "c"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let put = server
+        .mock("PUT", "/api/v1/logs/config/pipeline-order")
+        .match_body(mockito::Matcher::Json(
+            serde_json::json!({"pipeline_ids": ["c", "a", "b"]}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"pipeline_ids": ["c", "a", "b"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::pipelines_reorder(&cfg, &["c".into()]).await;
+    assert!(
+        result.is_ok(),
+        "pipelines reorder failed: {:?}",
+        result.err()
+    );
+    get.assert_async().await;
+    put.assert_async().await;
+
+    let result = crate::commands::logs::pipelines_reorder(&cfg, &["missing".into()]).await;
+    assert!(result.is_err(), "unknown pipeline ids should be rejected");
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_pipelines_create_from_file() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v1/logs/config/pipelines")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"name": "nginx", "filter": {"query": "source:nginx"}}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"id": "p-1", "name": "nginx", "filter": {"query": "source:nginx"}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let path = std::env::temp_dir().join("pup_test_logs_pipeline.json");
+    std::fs::write(
+        &path,
+        r#"{"name": "nginx", "is_enabled": true, "filter": {"query": "source:nginx"}}"#,
+    )
+    .unwrap();
+    let result = crate::commands::logs::pipelines_create(&cfg, path.to_str().unwrap()).await;
+    assert!(
+        result.is_ok(),
+        "pipelines create failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    let _ = std::fs::remove_file(&path);
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_facets_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("GET", "/api/v1/logs/facets")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"facets": {"logs": [{"path": "service", "type": "string"}]}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::facets_list(&cfg).await;
+    assert!(
+        result.is_ok(),
+        "logs facets list failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_analyze() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/logs/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "filter": {"query": "status:error"},
+            "page": {"limit": 50}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                {"id": "a", "type": "log", "attributes": {"service": "api", "message": "Timeout after 30s"}},
+                {"id": "b", "type": "log", "attributes": {"service": "api", "message": "Timeout after 45s"}}
+            ], "meta": {"page": {}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::analyze(
+        &cfg,
+        "status:error".into(),
+        "1h".into(),
+        "now".into(),
+        50,
+        10,
+    )
+    .await;
+    assert!(result.is_ok(), "logs analyze failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_aggregate() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "POST", r#"{"data": {"buckets": []}}"#).await;
+
+    let result = crate::commands::logs::aggregate(
+        &cfg,
+        "*".into(),
+        "1h".into(),
+        "now".into(),
+        vec![],
+        None,
+        None,
+        None,
+        vec![],
+        10,
+    )
+    .await;
+    assert!(result.is_ok(), "logs aggregate failed: {:?}", result.err());
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_aggregate_percentiles() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/logs/analytics/aggregate")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "compute": [
+                {"aggregation": "median", "metric": "@duration"},
+                {"aggregation": "pc90", "metric": "@duration"},
+                {"aggregation": "pc95", "metric": "@duration"},
+                {"aggregation": "pc99", "metric": "@duration"}
+            ]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"buckets": []}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::aggregate(
+        &cfg,
+        "service:api".into(),
+        "1h".into(),
+        "now".into(),
+        vec![],
+        Some("50,90,95,99".into()),
+        Some("@duration".into()),
+        None,
+        vec![],
+        10,
+    )
+    .await;
+    assert!(result.is_ok(), "logs aggregate failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_aggregate_multiple_computes_and_group_bys() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/logs/analytics/aggregate")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "compute": [
+                {"aggregation": "count"},
+                {"aggregation": "pc95", "metric": "@duration"}
+            ],
+            "group_by": [
+                {"facet": "service", "limit": 5},
+                {"facet": "status", "limit": 5}
+            ]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"buckets": []}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::aggregate(
+        &cfg,
+        "*".into(),
+        "1h".into(),
+        "now".into(),
+        vec!["count".into(), "pc95(@duration)".into()],
+        None,
+        None,
+        None,
+        vec!["service".into(), "status".into()],
+        5,
+    )
+    .await;
+    assert!(result.is_ok(), "logs aggregate failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_aggregate_timeseries() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/logs/analytics/aggregate")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "compute": [{"aggregation": "count", "type": "timeseries", "interval": "5m"}]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"buckets": [{"by": {}, "computes": {"c0": [
+                {"time": "2024-01-01T00:00:00.000Z", "value": 12},
+                {"time": "2024-01-01T00:05:00.000Z", "value": 7}
+            ]}}]}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::aggregate(
+        &cfg,
+        "status:error".into(),
+        "1h".into(),
+        "now".into(),
+        vec![],
+        None,
+        None,
+        Some("5m".into()),
+        vec![],
+        10,
+    )
+    .await;
+    assert!(result.is_ok(), "logs aggregate failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_archives_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "GET", r#"{"data": []}"#).await;
+
+    let result = crate::commands::logs::archives_list(&cfg).await;
+    assert!(
+        result.is_ok(),
+        "logs archives list failed: {:?}",
+        result.err()
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_rehydrations_point_at_ui() {
+    let _lock = lock_env();
+    let server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+
+    let err = crate::commands::logs::rehydrations_unavailable(&cfg, "create")
+        .unwrap_err()
+        .to_string();
+    assert!(
+        err.contains("`logs rehydrations create` is not available"),
+        "{err}"
+    );
+    assert!(
+        err.contains("https://app.datadoghq.com/logs/pipelines/historical-views"),
+        "{err}"
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_custom_destinations_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "GET", r#"{"data": []}"#).await;
+
+    let result = crate::commands::logs::custom_destinations_list(&cfg).await;
+    assert!(
+        result.is_ok(),
+        "logs custom destinations list failed: {:?}",
+        result.err()
+    );
+    cleanup_env();
+}
+
+const CUSTOM_DESTINATION_RESPONSE: &str = r#"{"data": {
+    "id": "abc-123",
+    "type": "custom_destination",
+    "attributes": {
+        "name": "forwarder",
+        "query": "service:api",
+        "enabled": true,
+        "forward_tags": true,
+        "forwarder_destination": {
+            "type": "http",
+            "endpoint": "https://example.com/logs",
+            "auth": {"type": "basic", "username": "u", "password": "p"}
+        }
+    }
+}}"#;
+
+#[tokio::test]
+async fn test_logs_custom_destinations_create() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/logs/config/custom_destinations")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"type": "custom_destination", "attributes": {"name": "forwarder"}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(CUSTOM_DESTINATION_RESPONSE)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let file = serde_json::json!({"data": {"attributes": {
+        "query": "service:api",
+        "forwarder_destination": {
+            "type": "http",
+            "endpoint": "https://example.com/logs",
+            "auth": {"type": "basic", "username": "u", "password": "p"}
+        }
+    }}});
+    let body = crate::commands::logs::custom_destination_body(
+        Some(file),
+        None,
+        Some("forwarder"),
+        None,
+        None,
+        None,
+    )
+    .unwrap();
+    let result = crate::commands::logs::custom_destinations_create(&cfg, body).await;
+    assert!(
+        result.is_ok(),
+        "custom destinations create failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_custom_destinations_update() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("PATCH", "/api/v2/logs/config/custom_destinations/abc-123")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"id": "abc-123", "attributes": {"enabled": false}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(CUSTOM_DESTINATION_RESPONSE)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let body = crate::commands::logs::custom_destination_body(
+        None,
+        Some("abc-123"),
+        None,
+        None,
+        None,
+        Some(false),
+    )
+    .unwrap();
+    let result = crate::commands::logs::custom_destinations_update(&cfg, "abc-123", body).await;
+    assert!(
+        result.is_ok(),
+        "custom destinations update failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_custom_destinations_delete() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("DELETE", "/api/v2/logs/config/custom_destinations/abc-123")
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::custom_destinations_delete(&cfg, "abc-123").await;
+    assert!(
+        result.is_ok(),
+        "custom destinations delete failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_metrics_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "GET", r#"{"data": []}"#).await;
+
+    let result = crate::commands::logs::metrics_list(&cfg).await;
+    assert!(
+        result.is_ok(),
+        "logs metrics list failed: {:?}",
+        result.err()
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_metrics_get_with_volume() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _metric = server
+        .mock("GET", "/api/v2/logs/config/metrics/errors.by.service")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"id": "errors.by.service", "type": "logs_metrics", "attributes": {
+                "compute": {"aggregation_type": "count"},
+                "filter": {"query": "service:web status:error"}
+            }}}"#,
+        )
+        .create_async()
+        .await;
+    let aggregate = server
+        .mock("POST", "/api/v2/logs/analytics/aggregate")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "filter": {"query": "service:web status:error"}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"buckets": [{"by": {}, "computes": {"c0": 42}}]}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::metrics_get(&cfg, "errors.by.service", Some("24h")).await;
+    assert!(
+        result.is_ok(),
+        "logs metrics get failed: {:?}",
+        result.err()
+    );
+    aggregate.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_restriction_queries_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+
+    // restriction_queries_list uses raw HTTP (not DD client), so mock specific path
+    let _mock = server
+        .mock("GET", "/api/v2/logs/config/restriction_queries")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": []}"#)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::restriction_queries_list(&cfg).await;
+    assert!(
+        result.is_ok(),
+        "logs restriction queries list failed: {:?}",
+        result.err()
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_restriction_queries_create_and_update() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let body = r#"{"data": {"id": "rq-1", "type": "logs_restriction_queries"}}"#;
+    let create = server
+        .mock("POST", "/api/v2/logs/config/restriction_queries")
+        .match_body(mockito::Matcher::Json(serde_json::json!({"data": {
+            "type": "logs_restriction_queries",
+            "attributes": {"restriction_query": "env:staging"}
+        }})))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(body)
+        .expect(1)
+        .create_async()
+        .await;
+    let update = server
+        .mock("PATCH", "/api/v2/logs/config/restriction_queries/rq-1")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({"data": {
+            "attributes": {"restriction_query": "env:prod"}
+        }})))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(body)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::logs::restriction_queries_create(&cfg, "env:staging").await;
+    assert!(result.is_ok(), "create failed: {:?}", result.err());
+    let result = crate::commands::logs::restriction_queries_update(&cfg, "rq-1", "env:prod").await;
+    assert!(result.is_ok(), "update failed: {:?}", result.err());
+    create.assert_async().await;
+    update.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_logs_restriction_queries_grant_and_revoke_role() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let role = serde_json::json!({"data": {"type": "roles", "id": "role-1"}});
+    let grant = server
+        .mock("POST", "/api/v2/logs/config/restriction_queries/rq-1/roles")
+        .match_body(mockito::Matcher::Json(role.clone()))
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+    let revoke = server
+        .mock(
+            "DELETE",
+            "/api/v2/logs/config/restriction_queries/rq-1/roles",
+        )
+        .match_body(mockito::Matcher::Json(role))
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result =
+        crate::commands::logs::restriction_queries_grant_role(&cfg, "rq-1", "role-1").await;
+    assert!(result.is_ok(), "grant failed: {:?}", result.err());
+    let result =
+        crate::commands::logs::restriction_queries_revoke_role(&cfg, "rq-1", "role-1").await;
+    assert!(result.is_ok(), "revoke failed: {:?}", result.err());
+    grant.assert_async().await;
+    revoke.assert_async().await;
+    cleanup_env();
+}
+
+// -------------------------------------------------------------------------
+// Metrics
+// -------------------------------------------------------------------------
+
+#[tokio::test]
+async fn test_metrics_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "GET",
+        r#"{"metrics": [], "from": "2024-01-01T00:00:00Z"}"#,
+    )
+    .await;
+
+    let result = crate::commands::metrics::list(&cfg, None, "1h".into()).await;
+    assert!(result.is_ok(), "metrics list failed: {:?}", result.err());
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_list_active_since() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let from = chrono::Utc::now().timestamp() - 2 * 86400;
+    let mock = server
+        .mock("GET", "/api/v1/metrics")
+        .match_query(mockito::Matcher::UrlEncoded(
+            "from".into(),
+            from.to_string(),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"metrics": ["custom.daily.metric"], "from": "0"}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::metrics::list(&cfg, None, from.to_string()).await;
+    assert!(result.is_ok(), "metrics list failed: {:?}", result.err());
+    mock.assert_async().await;
+
+    let result = crate::commands::metrics::list(&cfg, None, "365d".into()).await;
+    assert!(result.is_err(), "lookback beyond the API limit should fail");
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_query() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "GET",
+        r#"{"status": "ok", "res_type": "time_series", "series": [], "from_date": 0, "to_date": 0, "query": "avg:system.cpu.user{*}"}"#,
+    )
+    .await;
+
+    let result = crate::commands::metrics::query(
+        &cfg,
+        "avg:system.cpu.user{*}".into(),
+        "1h".into(),
+        "now".into(),
+    )
+    .await;
+    assert!(result.is_ok(), "metrics query failed: {:?}", result.err());
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_metadata_get() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(
+        &mut server,
+        "GET",
+        r#"{"type": "gauge", "description": "CPU usage", "unit": "percent"}"#,
+    )
+    .await;
+
+    let result = crate::commands::metrics::metadata_get(&cfg, "system.cpu.user").await;
+    assert!(
+        result.is_ok(),
+        "metrics metadata get failed: {:?}",
+        result.err()
+    );
+    cleanup_env();
+}
+
+const TAG_CONFIG_RESPONSE: &str = r#"{"data": {"id": "app.requests", "type": "manage_tags", "attributes": {"tags": ["env", "service"], "metric_type": "count", "aggregations": [{"time": "avg", "space": "avg"}, {"time": "sum", "space": "sum"}]}}}"#;
+
+#[tokio::test]
+async fn test_metrics_scalar() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/query/scalar")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"attributes": {"queries": [{"aggregator": "max"}]}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"type": "scalar_response", "attributes": {"columns": [
+                {"name": "service", "type": "group", "values": [["web"]]},
+                {"name": "a", "type": "number", "values": [0.42]}
+            ]}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::metrics::scalar(
+        &cfg,
+        "max:app.error_rate{*} by {service}".into(),
+        "15m".into(),
+        "now".into(),
+        "max".into(),
+    )
+    .await;
+    assert!(result.is_ok(), "metrics scalar failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_submit_file_gzips_single_request() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let path = std::env::temp_dir().join("pup_test_metrics_submit.json");
+    let series: Vec<serde_json::Value> = (0..200)
+        .map(|i| {
+            serde_json::json!({
+                "metric": "app.jobs.completed",
+                "type": 1,
+                "points": [{"timestamp": 1700000000 + i, "value": i}],
+                "tags": ["env:test"]
+            })
+        })
+        .collect();
+    std::fs::write(&path, serde_json::to_string(&series).unwrap()).unwrap();
+    let mock = server
+        .mock("POST", "/api/v2/series")
+        .match_header("content-encoding", "gzip")
+        .with_status(202)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"errors": []}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::metrics::submit(&cfg, path.to_str().unwrap()).await;
+    assert!(result.is_ok(), "metrics submit failed: {:?}", result.err());
+    mock.assert_async().await;
+    std::fs::remove_file(&path).ok();
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_submit_distribution_points() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v1/distribution_points")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "series": [{"metric": "api.latency", "points": [[1700000000, [12.0, 48.5]]]}]
+        })))
+        .with_status(202)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"status": "ok"}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let point = crate::commands::metrics::SubmitPoint {
+        name: "api.latency".into(),
+        values: vec![12.0, 48.5],
+        metric_type: "distribution".into(),
+        tags: Some("env:test".into()),
+        host: None,
+        interval: 0,
+        timestamp: "2023-11-14T22:13:20Z".into(),
+    };
+    let result = crate::commands::metrics::submit_point(&cfg, point).await;
+    assert!(
+        result.is_ok(),
+        "distribution submit failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_estimate() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("GET", "/api/v2/metrics/app.requests/estimate")
+        .match_query(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::UrlEncoded("filter[groups]".into(), "env,service".into()),
+            mockito::Matcher::UrlEncoded("filter[num_aggregations]".into(), "2".into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"id": "app.requests", "type": "metric_cardinality_estimate",
+                "attributes": {"estimate_type": "count_or_gauge", "estimated_output_series": 120}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let opts = crate::commands::metrics::EstimateOptions {
+        tags: Some("env,service".into()),
+        aggregations: Some("avg,sum".into()),
+        ..Default::default()
+    };
+    let result = crate::commands::metrics::estimate(&cfg, "app.requests", opts).await;
+    assert!(
+        result.is_ok(),
+        "metrics estimate failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_volumes_list() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _list = server
+        .mock("GET", "/api/v2/metrics")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                {"id": "app.requests", "type": "metrics"},
+                {"id": "app.errors", "type": "metrics"},
+                {"id": "system.cpu.user", "type": "metrics"}
+            ]}"#,
+        )
+        .create_async()
+        .await;
+    let requests = server
+        .mock("GET", "/api/v2/metrics/app.requests/volumes")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"id": "app.requests", "type": "metric_volumes",
+                "attributes": {"ingested_volume": 900, "indexed_volume": 40}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let errors = server
+        .mock("GET", "/api/v2/metrics/app.errors/volumes")
+        .with_status(404)
+        .with_body(r#"{"errors": ["Not found"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let system = server
+        .mock("GET", "/api/v2/metrics/system.cpu.user/volumes")
+        .expect(0)
+        .create_async()
+        .await;
+
+    let result = crate::commands::metrics::volumes_list(&cfg, "app.*", 10).await;
+    assert!(result.is_ok(), "volumes list failed: {:?}", result.err());
+    requests.assert_async().await;
+    errors.assert_async().await;
+    system.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_tag_config_get() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("GET", "/api/v2/metrics/app.requests/tags")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(TAG_CONFIG_RESPONSE)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::metrics::tag_config_get(&cfg, "app.requests").await;
+    assert!(result.is_ok(), "tag config get failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_tag_config_create_uses_metadata_type() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let metadata = server
+        .mock("GET", "/api/v1/metrics/app.requests")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"type": "count", "unit": "request"}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let create = server
+        .mock("POST", "/api/v2/metrics/app.requests/tags")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {
+                "id": "app.requests",
+                "type": "manage_tags",
+                "attributes": {
+                    "tags": ["env", "service"],
+                    "metric_type": "count",
+                    "aggregations": [
+                        {"time": "avg", "space": "avg"},
+                        {"time": "sum", "space": "sum"}
+                    ]
+                }
+            }
+        })))
+        .with_status(201)
+        .with_header("content-type", "application/json")
+        .with_body(TAG_CONFIG_RESPONSE)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let opts = crate::commands::metrics::TagConfigOptions {
+        tags: Some("env,service".into()),
+        aggregations: Some("avg,sum".into()),
+        ..Default::default()
+    };
+    let result = crate::commands::metrics::tag_config_create(&cfg, "app.requests", opts).await;
+    assert!(
+        result.is_ok(),
+        "tag config create failed: {:?}",
+        result.err()
+    );
+    metadata.assert_async().await;
+    create.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_tag_config_update() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("PATCH", "/api/v2/metrics/app.requests/tags")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"id": "app.requests", "type": "manage_tags", "attributes": {"tags": ["env"]}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(TAG_CONFIG_RESPONSE)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let opts = crate::commands::metrics::TagConfigOptions {
+        tags: Some("env".into()),
+        ..Default::default()
+    };
+    let result = crate::commands::metrics::tag_config_update(&cfg, "app.requests", opts).await;
+    assert!(
+        result.is_ok(),
+        "tag config update failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+
+    let nothing = crate::commands::metrics::TagConfigOptions::default();
+    assert!(
+        crate::commands::metrics::tag_config_update(&cfg, "app.requests", nothing)
+            .await
+            .is_err()
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_tag_config_delete() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("DELETE", "/api/v2/metrics/app.requests/tags")
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::metrics::tag_config_delete(&cfg, "app.requests").await;
+    assert!(
+        result.is_ok(),
+        "tag config delete failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_tag_config_delete_matching() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+    // The metric list spans two pages linked by a cursor
+    let _second_page = server
+        .mock("GET", "/api/v2/metrics")
+        .match_query(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::UrlEncoded("filter[configured]".into(), "true".into()),
+            mockito::Matcher::UrlEncoded("page[cursor]".into(), "p2".into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                {"id": "app.http.errors", "type": "manage_tags"},
+                {"id": "app.grpc.requests", "type": "manage_tags"}
+            ], "meta": {"pagination": {"next_cursor": null}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let _list = server
+        .mock("GET", "/api/v2/metrics")
+        .match_query(mockito::Matcher::UrlEncoded(
+            "filter[configured]".into(),
+            "true".into(),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": [
+                {"id": "app.http.requests", "type": "manage_tags"}
+            ], "meta": {"pagination": {"next_cursor": "p2"}}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let requests = server
+        .mock("DELETE", "/api/v2/metrics/app.http.requests/tags")
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+    let errors = server
+        .mock("DELETE", "/api/v2/metrics/app.http.errors/tags")
+        .with_status(404)
+        .with_body(r#"{"errors": ["Not found"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let untouched = server
+        .mock("DELETE", "/api/v2/metrics/app.grpc.requests/tags")
+        .expect(0)
+        .create_async()
+        .await;
+
+    let err = crate::commands::metrics::tag_config_matching(
+        &cfg,
+        "app.http.*",
+        crate::commands::metrics::TagConfigAction::Delete,
+        crate::commands::metrics::TagConfigOptions::default(),
+    )
+    .await
+    .unwrap_err();
+    assert!(
+        err.to_string()
+            .contains("failed to delete tag configurations of 1 of 2 metrics"),
+        "{err}"
+    );
+    requests.assert_async().await;
+    errors.assert_async().await;
+    untouched.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_metrics_tag_config_update_matching_requires_fields() {
+    let _lock = lock_env();
+    let server = mockito::Server::new_async().await;
+    let mut cfg = test_config(&server.url());
+    cfg.auto_approve = true;
+
+    let err = crate::commands::metrics::tag_config_matching(
+        &cfg,
+        "app.*",
+        crate::commands::metrics::TagConfigAction::Update,
+        crate::commands::metrics::TagConfigOptions::default(),
+    )
+    .await
+    .unwrap_err();
+    assert!(err.to_string().contains("nothing to update"), "{err}");
+    cleanup_env();
+}
+
+// -------------------------------------------------------------------------
+// Events search (requires API keys)
+// -------------------------------------------------------------------------
+
+#[tokio::test]
+async fn test_events_search() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = mock_any(&mut server, "POST", r#"{"data": [], "meta": {"page": {}}}"#).await;
+
+    let result =
+        crate::commands::events::search(&cfg, "source:nginx".into(), "1h".into(), "now".into(), 10)
+            .await;
+    assert!(result.is_ok(), "events search failed: {:?}", result.err());
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_events_post_change_event() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/events")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"type": "event", "attributes": {
+                "title": "Deployed checkout v42",
+                "category": "change",
+                "attributes": {"changed_resource": {"name": "checkout", "type": "configuration"}}
+            }}
+        })))
+        .with_status(202)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"type": "event", "attributes": {}}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let event = crate::commands::events::EventPost {
+        title: "Deployed checkout v42".into(),
+        category: "change".into(),
+        changed_resource: Some("checkout".into()),
+        ..Default::default()
+    };
+    let result = crate::commands::events::post(&cfg, &event).await;
+    assert!(result.is_ok(), "events post failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_events_search_follows_cursor() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let event = |id: &str| serde_json::json!({"id": id, "type": "event", "attributes": {}});
+    let first = server
+        .mock("POST", "/api/v2/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "page": {"limit": 3}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({
+                "data": [event("e1"), event("e2")],
+                "meta": {"page": {"after": "next-page"}}
+            })
+            .to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let second = server
+        .mock("POST", "/api/v2/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "page": {"limit": 1, "cursor": "next-page"}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({
+                "data": [event("e3")],
+                "meta": {"page": {"after": "more"}}
+            })
+            .to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result =
+        crate::commands::events::search(&cfg, "tags:deploy".into(), "1h".into(), "now".into(), 3)
+            .await;
+    assert!(result.is_ok(), "events search failed: {:?}", result.err());
+    first.assert_async().await;
+    second.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_events_stream_polls() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let event = |id: &str, ts: &str| serde_json::json!({"id": id, "type": "event", "attributes": {"timestamp": ts, "message": id}});
+    let first = server
+        .mock("POST", "/api/v2/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "filter": {"query": "tags:deploy"}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(serde_json::json!({"data": [event("e1", "2024-01-01T00:00:10Z")]}).to_string())
+        .expect(1)
+        .create_async()
+        .await;
+    // The second poll spans two pages; the newest page links to the next
+    let second = server
+        .mock("POST", "/api/v2/events/search")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({
+                "data": [event("e3", "2024-01-01T00:00:30Z")],
+                "meta": {"page": {"after": "page-2"}}
+            })
+            .to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let second_page = server
+        .mock("POST", "/api/v2/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "page": {"cursor": "page-2"}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({"data": [
+                event("e2", "2024-01-01T00:00:20Z"),
+                event("e1", "2024-01-01T00:00:10Z")
+            ]})
+            .to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let start = chrono::DateTime::parse_from_rfc3339("2024-01-01T00:00:00Z")
+        .unwrap()
+        .timestamp_millis();
+    let mut cursor = crate::commands::events::StreamCursor::starting_at(start);
+    let polled = crate::commands::events::stream_poll(&cfg, "tags:deploy", &mut cursor)
+        .await
+        .unwrap();
+    assert_eq!(polled.len(), 1);
+    assert_eq!(polled[0]["id"], "e1");
+
+    let polled = crate::commands::events::stream_poll(&cfg, "tags:deploy", &mut cursor)
+        .await
+        .unwrap();
+    let ids: Vec<&str> = polled.iter().filter_map(|e| e["id"].as_str()).collect();
+    assert_eq!(
+        ids,
+        ["e2", "e3"],
+        "only the new events are returned, oldest first"
+    );
+    first.assert_async().await;
+    second.assert_async().await;
+    second_page.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_events_search_requires_api_keys() {
+    let _lock = lock_env();
+    let server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: None,
+        app_key: None,
+        access_token: Some("token".into()),
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let result =
+        crate::commands::events::search(&cfg, "source:nginx".into(), "1h".into(), "now".into(), 10)
+            .await;
+    assert!(result.is_err(), "events search should require API keys");
+    cleanup_env();
+}
+
+// =========================================================================
+// Raw HTTP api module tests — these use the api.rs module directly
+// (not the DD client library), so we can mock specific paths precisely.
+// =========================================================================
+
+#[tokio::test]
+async fn test_api_get() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("GET", "/api/v1/test")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"status": "ok"}"#)
+        .create_async()
+        .await;
+
+    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
+    assert!(result.is_ok(), "api get failed: {:?}", result.err());
+    let val = result.unwrap();
+    assert_eq!(val["status"], "ok");
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_get_with_query() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("GET", "/api/v1/search")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"results": []}"#)
+        .create_async()
+        .await;
+
+    let query = vec![("q", "test".to_string())];
+    let result = crate::api::get(&cfg, "/api/v1/search", &query).await;
+    assert!(
+        result.is_ok(),
+        "api get with query failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_post() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("POST", "/api/v2/test")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"created": true}"#)
+        .create_async()
+        .await;
+
+    let body = serde_json::json!({"name": "test"});
+    let result = crate::api::post(&cfg, "/api/v2/test", &body).await;
+    assert!(result.is_ok(), "api post failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_put() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("PUT", "/api/v1/test/123")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"updated": true}"#)
+        .create_async()
+        .await;
+
+    let body = serde_json::json!({"name": "updated"});
+    let result = crate::api::put(&cfg, "/api/v1/test/123", &body).await;
+    assert!(result.is_ok(), "api put failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_patch() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("PATCH", "/api/v1/test/123")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"patched": true}"#)
+        .create_async()
+        .await;
+
+    let body = serde_json::json!({"name": "patched"});
+    let result = crate::api::patch(&cfg, "/api/v1/test/123", &body).await;
+    assert!(result.is_ok(), "api patch failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_delete() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("DELETE", "/api/v1/test/123")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"deleted": true}"#)
+        .create_async()
+        .await;
+
+    let result = crate::api::delete(&cfg, "/api/v1/test/123").await;
+    assert!(result.is_ok(), "api delete failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_error_response() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("GET", "/api/v1/test/missing")
+        .with_status(404)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"errors": ["not found"]}"#)
+        .create_async()
+        .await;
+
+    let result = crate::api::get(&cfg, "/api/v1/test/missing", &[]).await;
+    assert!(result.is_err(), "should return error for 404");
+    assert!(result.unwrap_err().to_string().contains("404"));
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_bearer_auth() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: None,
+        app_key: None,
+        access_token: Some("test-bearer-token".into()),
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("GET", "/api/v1/test")
+        .match_header("Authorization", "Bearer test-bearer-token")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"auth": "bearer"}"#)
+        .create_async()
+        .await;
+
+    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
+    assert!(result.is_ok(), "bearer auth failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_no_auth() {
+    let _lock = lock_env();
+
+    let cfg = Config {
+        api_key: None,
+        app_key: None,
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
+    assert!(result.is_err(), "should fail without auth");
+    assert!(
+        result.unwrap_err().to_string().contains("authentication"),
+        "error should mention authentication"
+    );
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_empty_response() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("DELETE", "/api/v1/test/empty")
+        .with_status(204)
+        .with_body("")
+        .create_async()
+        .await;
+
+    let result = crate::api::delete(&cfg, "/api/v1/test/empty").await;
+    assert!(result.is_ok(), "empty response failed: {:?}", result.err());
+    let val = result.unwrap();
+    assert_eq!(val, serde_json::json!({}));
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_api_server_error() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    std::env::set_var("PUP_MOCK_SERVER", &server.url());
+
+    let cfg = Config {
+        api_key: Some("test-key".into()),
+        app_key: Some("test-app".into()),
+        access_token: None,
+        site: "datadoghq.com".into(),
+        output_format: OutputFormat::Json,
+        auto_approve: false,
+        agent_mode: false,
+        envelope: None,
+        table: Default::default(),
+        template: None,
+        summary: false,
+        profile: None,
+    };
+
+    let mock = server
+        .mock("GET", "/api/v1/test")
+        .with_status(500)
+        .with_body(r#"{"errors": ["internal server error"]}"#)
+        .create_async()
+        .await;
+
+    let result = crate::api::get(&cfg, "/api/v1/test", &[]).await;
+    assert!(result.is_err());
+    assert!(result.unwrap_err().to_string().contains("500"));
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+// =========================================================================
+// Bulk command module tests — exercise list/get operations for all remaining
+// command modules to maximize coverage. The mock_any helper catches all
+// requests. We use `let _ =` instead of asserting success because some DD
+// client types may not deserialize our minimal responses — the important
+// thing is that the command code paths are exercised.
+// =========================================================================
+
+/// Mock all HTTP methods with the same response body.
+async fn mock_all(s: &mut mockito::Server, body: &str) {
+    for method in &["GET", "POST", "PUT", "PATCH", "DELETE"] {
+        s.mock(method, mockito::Matcher::Any)
+            .match_query(mockito::Matcher::Any)
+            .with_status(200)
+            .with_header("content-type", "application/json")
+            .with_body(body)
+            .create_async()
+            .await;
+    }
+}
+
+// --- RUM ---
+#[tokio::test]
+async fn test_rum_apps_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::rum::apps_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_apps_get() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": {"id": "abc", "type": "rum_browser"}}"#).await;
+    let _ = crate::commands::rum::apps_get(&cfg, "abc", false).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_apps_get_snippet() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(
+        &mut s,
+        r#"{"data": {"id": "abc", "type": "rum_application", "attributes": {"application_id": "abc", "client_token": "pub123", "name": "web", "type": "browser", "created_at": 0, "created_by_handle": "", "org_id": 1, "updated_at": 0, "updated_by_handle": ""}}}"#,
+    )
+    .await;
+    let result = crate::commands::rum::apps_get(&cfg, "abc", true).await;
+    assert!(
+        result.is_ok(),
+        "rum apps get --snippet failed: {:?}",
+        result.err()
+    );
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_apps_delete() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{}"#).await;
+    let _ = crate::commands::rum::apps_delete(&cfg, "abc").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_metrics_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::rum::metrics_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_metrics_get() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::rum::metrics_get(&cfg, "m1", None).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_metrics_get_with_volume() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _metric = server
+        .mock("GET", "/api/v2/rum/metrics/checkout.errors")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"id": "checkout.errors", "type": "rum_metrics", "attributes": {
+                "event_type": "error",
+                "compute": {"aggregation_type": "count"},
+                "filter": {"query": "@application.name:checkout"}
+            }}}"#,
+        )
+        .create_async()
+        .await;
+    let aggregate = server
+        .mock("POST", "/api/v2/rum/analytics/aggregate")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "filter": {"query": "@type:error (@application.name:checkout)"}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"buckets": [{"by": {}, "computes": {"c0": 17}}]}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::rum::metrics_get(&cfg, "checkout.errors", Some("24h")).await;
+    assert!(result.is_ok(), "rum metrics get failed: {:?}", result.err());
+    aggregate.assert_async().await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_sessions_search_count_only() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let search = server
+        .mock("POST", "/api/v2/rum/events/search")
+        .expect(0)
+        .create_async()
+        .await;
+    let aggregate = server
+        .mock("POST", "/api/v2/rum/analytics/aggregate")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "filter": {"query": "@type:session @session.has_replay:true"},
+            "compute": [{"aggregation": "count"}]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"buckets": [{"by": {}, "computes": {"c0": 250}}]}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::rum::sessions_search(
+        &cfg,
+        Some("@type:session @session.has_replay:true".into()),
+        "1h".into(),
+        "now".into(),
+        100,
+        true,
+    )
+    .await;
+    assert!(
+        result.is_ok(),
+        "rum sessions count failed: {:?}",
+        result.err()
+    );
+    aggregate.assert_async().await;
+    search.assert_async().await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_metrics_delete() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{}"#).await;
+    let _ = crate::commands::rum::metrics_delete(&cfg, "m1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_retention_filters_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::rum::retention_filters_list(&cfg, "app1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_events_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::rum::events_list(&cfg, "1h".into(), "now".into(), 10).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_rum_playlists_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::rum::playlists_list(&cfg).await;
+    cleanup_env();
+}
+
+// --- Status Pages ---
+#[tokio::test]
+async fn test_status_pages_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::status_pages::pages_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_status_pages_get() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::status_pages::pages_get(&cfg, "p1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_status_pages_delete() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{}"#).await;
+    let _ = crate::commands::status_pages::pages_delete(&cfg, "p1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_status_pages_components_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::status_pages::components_list(&cfg, "p1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_status_pages_degradations_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::status_pages::degradations_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_status_pages_third_party_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::status_pages::third_party_list(&cfg, None, false).await;
+    cleanup_env();
+}
+
+// --- Cases ---
+#[tokio::test]
+async fn test_cases_search() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::cases::search(&cfg, None, None, 10).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_cases_search_assignee_me() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    let me = s
+        .mock("GET", "/api/v2/current_user")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"type": "users", "id": "7f1c5e9a-0000-4000-8000-000000000001", "attributes": {"handle": "oncall@example.com"}}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let cases = s
+        .mock("GET", "/api/v2/cases")
+        .match_query(mockito::Matcher::UrlEncoded(
+            "filter".into(),
+            "status:open assignee:7f1c5e9a-0000-4000-8000-000000000001".into(),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": []}"#)
+        .expect(2)
+        .create_async()
+        .await;
+
+    // The second search reuses the id resolved by the first
+    for _ in 0..2 {
+        let result =
+            crate::commands::cases::search(&cfg, Some("status:open".into()), Some("me".into()), 10)
+                .await;
+        assert!(result.is_ok(), "cases search failed: {:?}", result.err());
+    }
+    me.assert_async().await;
+    cases.assert_async().await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_cases_get() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::cases::get(&cfg, "case1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_cases_projects_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::cases::projects_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_cases_projects_get() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::cases::projects_get(&cfg, "proj1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_cases_projects_delete() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{}"#).await;
+    let _ = crate::commands::cases::projects_delete(&cfg, "proj1").await;
+    cleanup_env();
+}
+
+// --- Integrations ---
+#[tokio::test]
+async fn test_integrations_jira_accounts_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::integrations::jira_accounts_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_integrations_jira_accounts_create() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    let mock = s
+        .mock("POST", "/api/v2/integration/jira/accounts")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"id": "acct-1", "type": "jira-account"}}"#)
+        .create_async()
+        .await;
+    let file = std::env::temp_dir().join("pup_test_jira_account.json");
+    std::fs::write(
+        &file,
+        r#"{"data": {"type": "jira-account", "attributes": {"instance_url": "https://example.atlassian.net"}}}"#,
+    )
+    .unwrap();
+    let result =
+        crate::commands::integrations::jira_accounts_create(&cfg, file.to_str().unwrap()).await;
+    assert!(
+        result.is_ok(),
+        "jira accounts create failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    let _ = std::fs::remove_file(&file);
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_integrations_jira_accounts_create_invalid_body() {
+    let _lock = lock_env();
+    let s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    let file = std::env::temp_dir().join("pup_test_jira_account_invalid.json");
+    std::fs::write(
+        &file,
+        r#"{"instance_url": "https://example.atlassian.net"}"#,
+    )
+    .unwrap();
+    let result =
+        crate::commands::integrations::jira_accounts_create(&cfg, file.to_str().unwrap()).await;
+    assert!(
+        result.is_err(),
+        "body without data.attributes should be rejected"
+    );
+    let _ = std::fs::remove_file(&file);
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_integrations_jira_templates_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::integrations::jira_templates_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_integrations_servicenow_instances_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::integrations::servicenow_instances_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_integrations_servicenow_templates_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::integrations::servicenow_templates_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_integrations_slack_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::integrations::slack_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_integrations_webhooks_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::integrations::webhooks_list(&cfg).await;
+    cleanup_env();
+}
+
+// --- CI/CD ---
+#[tokio::test]
+async fn test_cicd_pipelines_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::cicd::pipelines_list(&cfg, None, "1h".into(), "now".into(), 10).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_cicd_tests_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::cicd::tests_list(&cfg, None, "1h".into(), "now".into(), 10).await;
+    cleanup_env();
+}
+
+// --- Fleet ---
+#[tokio::test]
+async fn test_fleet_agents_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::fleet::agents_list(&cfg, None).await;
+    cleanup_env();
 }
-
-// --- RUM ---
 #[tokio::test]
-async fn test_rum_apps_list() {
+async fn test_fleet_agents_get() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::fleet::agents_get(&cfg, "a1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_fleet_agents_versions() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::rum::apps_list(&cfg).await;
+    let _ = crate::commands::fleet::agents_versions(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_rum_apps_get() {
+async fn test_fleet_deployments_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {"id": "abc", "type": "rum_browser"}}"#).await;
-    let _ = crate::commands::rum::apps_get(&cfg, "abc").await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::fleet::deployments_list(&cfg, None).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_rum_apps_delete() {
+async fn test_fleet_schedules_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::rum::apps_delete(&cfg, "abc").await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::fleet::schedules_list(&cfg).await;
     cleanup_env();
 }
+
+// --- Incidents ---
 #[tokio::test]
-async fn test_rum_metrics_list() {
+async fn test_incidents_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::rum::metrics_list(&cfg).await;
+    let _ = crate::commands::incidents::list(&cfg, 10).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_rum_metrics_get() {
+async fn test_incidents_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::rum::metrics_get(&cfg, "m1").await;
+    let _ = crate::commands::incidents::get(&cfg, "inc1").await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_rum_metrics_delete() {
+async fn test_incidents_settings_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::rum::metrics_delete(&cfg, "m1").await;
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::incidents::settings_get(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_rum_retention_filters_list() {
+async fn test_incidents_handles_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::rum::retention_filters_list(&cfg, "app1").await;
+    let _ = crate::commands::incidents::handles_list(&cfg).await;
     cleanup_env();
 }
+const INCIDENT_HANDLES: &str = r#"{"data": [
+    {"id": "h-123", "type": "incidents_handles", "attributes": {"name": "@sre-oncall"}},
+    {"id": "h-456", "type": "incidents_handles", "attributes": {"name": "@dba"}}
+]}"#;
+async fn mock_incident_handles(s: &mut mockito::Server) -> mockito::Mock {
+    s.mock("GET", "/api/v2/incidents/config/handles")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(INCIDENT_HANDLES)
+        .create_async()
+        .await
+}
 #[tokio::test]
-async fn test_rum_events_list() {
+async fn test_incidents_handles_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::rum::events_list(&cfg, "1h".into(), "now".into(), 10).await;
+    let _list = mock_incident_handles(&mut s).await;
+
+    crate::formatter::begin_capture();
+    let result = crate::commands::incidents::handles_get(&cfg, "h-456").await;
+    let captured = crate::formatter::end_capture();
+    assert!(result.is_ok(), "handles get failed: {:?}", result.err());
+    assert_eq!(captured[0]["data"]["attributes"]["name"], "@dba");
+
+    let result = crate::commands::incidents::handles_get(&cfg, "h-789").await;
+    assert!(result.unwrap_err().to_string().contains("not found"));
     cleanup_env();
 }
 #[tokio::test]
-async fn test_rum_playlists_list() {
+async fn test_incidents_handles_delete_checks_id() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let mut cfg = test_config(&s.url());
+    cfg.auto_approve = true;
+    let _list = mock_incident_handles(&mut s).await;
+    let delete = s
+        .mock("DELETE", "/api/v2/incidents/config/handles/h-123")
+        .with_status(204)
+        .expect(1)
+        .create_async()
+        .await;
+    // The id-less collection delete would remove every handle
+    let collection = s
+        .mock("DELETE", "/api/v2/incidents/config/handles")
+        .match_query(mockito::Matcher::Any)
+        .expect(0)
+        .create_async()
+        .await;
+    let result = crate::commands::incidents::handles_delete(&cfg, "h-123").await;
+    assert!(result.is_ok(), "handles delete failed: {:?}", result.err());
+
+    // Unknown and malformed ids fail before anything is deleted
+    let result = crate::commands::incidents::handles_delete(&cfg, "h-789").await;
+    assert!(result.is_err());
+    let result = crate::commands::incidents::handles_delete(&cfg, "h-123/../x").await;
+    assert!(result.is_err());
+    delete.assert_async().await;
+    collection.assert_async().await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_incidents_todos_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    let mock = s
+        .mock("GET", "/api/v2/incidents/inc-1/relationships/todos")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": [{"id": "t-1", "type": "incident_todos", "attributes": {"content": "Roll back", "assignees": [], "completed": null}}]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let result = crate::commands::incidents::todos_list(&cfg, "inc-1").await;
+    assert!(result.is_ok(), "todos list failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_incidents_todos_add() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    let mock = s
+        .mock("POST", "/api/v2/incidents/inc-1/relationships/todos")
+        .match_body(mockito::Matcher::Json(serde_json::json!({"data": {
+            "type": "incident_todos",
+            "attributes": {"content": "Roll back", "assignees": ["@jane@example.com"]}
+        }})))
+        .with_status(201)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"id": "t-1", "type": "incident_todos", "attributes": {"content": "Roll back", "assignees": ["@jane@example.com"], "completed": null}}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let result = crate::commands::incidents::todos_add(
+        &cfg,
+        "inc-1",
+        "Roll back",
+        &["@jane@example.com".into()],
+    )
+    .await;
+    assert!(result.is_ok(), "todos add failed: {:?}", result.err());
+    mock.assert_async().await;
+
+    // Bad assignees are rejected before any request is sent.
+    let result =
+        crate::commands::incidents::todos_add(&cfg, "inc-1", "Roll back", &["jane".into()]).await;
+    assert!(result.is_err());
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_incidents_todos_complete() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    let get = s
+        .mock("GET", "/api/v2/incidents/inc-1/relationships/todos/t-1")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"id": "t-1", "type": "incident_todos", "attributes": {"content": "Roll back", "assignees": ["@jane@example.com"], "completed": null}}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let patch = s
+        .mock("PATCH", "/api/v2/incidents/inc-1/relationships/todos/t-1")
+        .match_body(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::PartialJson(serde_json::json!({"data": {
+                "type": "incident_todos",
+                "attributes": {"content": "Roll back", "assignees": ["@jane@example.com"]}
+            }})),
+            mockito::Matcher::Regex(r#""completed":"\d{4}-"#.into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"id": "t-1", "type": "incident_todos", "attributes": {"content": "Roll back", "completed": "2024-03-01T12:00:00+00:00"}}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let result = crate::commands::incidents::todos_complete(&cfg, "inc-1", "t-1").await;
+    assert!(result.is_ok(), "todos complete failed: {:?}", result.err());
+    get.assert_async().await;
+    patch.assert_async().await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_incidents_postmortem_templates_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::rum::playlists_list(&cfg).await;
+    let _ = crate::commands::incidents::postmortem_templates_list(&cfg).await;
     cleanup_env();
 }
 
-// --- Status Pages ---
+// --- On-Call ---
 #[tokio::test]
-async fn test_status_pages_list() {
+async fn test_on_call_teams_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::status_pages::pages_list(&cfg).await;
+    let _ = crate::commands::on_call::teams_list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_status_pages_get() {
+async fn test_on_call_teams_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::status_pages::pages_get(&cfg, "p1").await;
+    let _ = crate::commands::on_call::teams_get(&cfg, "t1").await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_status_pages_delete() {
+async fn test_on_call_teams_delete() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::status_pages::pages_delete(&cfg, "p1").await;
+    let _ = crate::commands::on_call::teams_delete(&cfg, "t1").await;
     cleanup_env();
 }
+
+// --- Security ---
 #[tokio::test]
-async fn test_status_pages_components_list() {
+async fn test_security_rules_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::status_pages::components_list(&cfg, "p1").await;
+    let _ = crate::commands::security::rules_list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_status_pages_degradations_list() {
+async fn test_security_rules_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::status_pages::degradations_list(&cfg).await;
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::security::rules_get(&cfg, "r1").await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_status_pages_third_party_list() {
+async fn test_security_content_packs_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::status_pages::third_party_list(&cfg, None, false).await;
+    let _ = crate::commands::security::content_packs_list(&cfg).await;
+    cleanup_env();
+}
+
+// --- Synthetics ---
+#[tokio::test]
+async fn test_synthetics_tests_list() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{"tests": []}"#).await;
+    let _ = crate::commands::synthetics::tests_list(&cfg).await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_synthetics_tests_get() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    mock_all(&mut s, r#"{}"#).await;
+    let _ = crate::commands::synthetics::tests_get(&cfg, "pub1").await;
+    cleanup_env();
+}
+#[tokio::test]
+async fn test_synthetics_tests_create_browser_from_file() {
+    let _lock = lock_env();
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let file = std::env::temp_dir().join("pup_test_synthetics_browser.json");
+    std::fs::write(
+        &file,
+        r#"{"type": "browser", "name": "Checkout", "locations": ["aws:eu-west-1"], "config": {"request": {"method": "GET", "url": "https://example.com"}, "assertions": []}, "options": {"tick_every": 900}, "message": ""}"#,
+    )
+    .unwrap();
+    let mock = server
+        .mock("POST", "/api/v1/synthetics/tests/browser")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"type": "browser", "name": "Checkout"}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"public_id": "brw-123", "type": "browser", "name": "Checkout"}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::synthetics::tests_create(&cfg, file.to_str().unwrap()).await;
+    assert!(
+        result.is_ok(),
+        "synthetics tests create failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
+    std::fs::remove_file(&file).ok();
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_synthetics_tests_pause_single() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let cfg = test_config(&s.url());
+    let mock = s
+        .mock("PUT", "/api/v1/synthetics/tests/abc-123/status")
+        .match_body(mockito::Matcher::Json(
+            serde_json::json!({"new_status": "paused"}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body("true")
+        .expect(1)
+        .create_async()
+        .await;
+    let result = crate::commands::synthetics::set_status(
+        &cfg,
+        "abc-123",
+        crate::commands::synthetics::TestStatus::Paused,
+    )
+    .await;
+    assert!(result.is_ok(), "tests pause failed: {:?}", result.err());
+    mock.assert_async().await;
+    cleanup_env();
+}
+
+#[tokio::test]
+async fn test_synthetics_tests_resume_matching_tags() {
+    let _lock = lock_env();
+    let mut s = mockito::Server::new_async().await;
+    let mut cfg = test_config(&s.url());
+    cfg.auto_approve = true;
+    let _list = s
+        .mock("GET", "/api/v1/synthetics/tests")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({"tests": [
+                {"public_id": "abc-123", "name": "Checkout", "type": "api", "status": "paused", "tags": ["team:web"]},
+                {"public_id": "def-456", "name": "Login", "type": "browser", "status": "paused", "tags": ["team:web", "env:prod"]},
+                {"public_id": "ghi-789", "name": "API", "type": "api", "status": "paused", "tags": ["team:api"]}
+            ]})
+            .to_string(),
+        )
+        .create_async()
+        .await;
+    let resumed = s
+        .mock(
+            "PUT",
+            mockito::Matcher::Regex(r"^/api/v1/synthetics/tests/(abc-123|def-456)/status$".into()),
+        )
+        .match_body(mockito::Matcher::Json(
+            serde_json::json!({"new_status": "live"}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body("true")
+        .expect(2)
+        .create_async()
+        .await;
+    let other = s
+        .mock("PUT", "/api/v1/synthetics/tests/ghi-789/status")
+        .expect(0)
+        .create_async()
+        .await;
+
+    let result = crate::commands::synthetics::set_status_matching(
+        &cfg,
+        &["team:web".into()],
+        crate::commands::synthetics::TestStatus::Live,
+    )
+    .await;
+    assert!(
+        result.is_ok(),
+        "tests resume --all failed: {:?}",
+        result.err()
+    );
+    resumed.assert_async().await;
+    other.assert_async().await;
     cleanup_env();
 }
 
-// --- Cases ---
 #[tokio::test]
-async fn test_cases_search() {
+async fn test_synthetics_tests_pause_matching_reports_failures() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cases::search(&cfg, None, 10).await;
+    let mut cfg = test_config(&s.url());
+    cfg.auto_approve = true;
+    let _list = s
+        .mock("GET", "/api/v1/synthetics/tests")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({"tests": [
+                {"public_id": "abc-123", "name": "Checkout", "type": "api", "status": "live", "tags": ["team:web"]},
+                {"public_id": "def-456", "name": "Login", "type": "api", "status": "live", "tags": ["team:web"]}
+            ]})
+            .to_string(),
+        )
+        .create_async()
+        .await;
+    let _ok = s
+        .mock("PUT", "/api/v1/synthetics/tests/abc-123/status")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body("true")
+        .create_async()
+        .await;
+    let _missing = s
+        .mock("PUT", "/api/v1/synthetics/tests/def-456/status")
+        .with_status(404)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"errors": ["Synthetics test not found"]}"#)
+        .create_async()
+        .await;
+
+    let err = crate::commands::synthetics::set_status_matching(
+        &cfg,
+        &["team:web".into()],
+        crate::commands::synthetics::TestStatus::Paused,
+    )
+    .await
+    .unwrap_err();
+    assert!(
+        err.to_string()
+            .contains("failed to pause 1 of 2 synthetic tests"),
+        "{err}"
+    );
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_cases_get() {
+async fn test_synthetics_run_wait_writes_junit_and_fails_on_blocking_failure() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::cases::get(&cfg, "case1").await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let junit = std::env::temp_dir().join("pup_test_synthetics_run.xml");
+    let trigger = server
+        .mock("POST", "/api/v1/synthetics/tests/trigger/ci")
+        .match_body(mockito::Matcher::Json(serde_json::json!({
+            "tests": [{"public_id": "abc-123"}, {"public_id": "def-456"}]
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"batch_id": "batch-1", "results": []}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let batch = server
+        .mock("GET", "/api/v1/synthetics/ci/batch/batch-1")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"data": {"status": "failed", "results": [
+                {"result_id": "1", "test_public_id": "abc-123", "test_name": "Checkout",
+                 "location": "aws:eu-west-1", "status": "passed", "duration": 2.0, "execution_rule": "blocking"},
+                {"result_id": "2", "test_public_id": "def-456", "test_name": "Login",
+                 "location": "aws:eu-west-1", "status": "failed", "duration": 3.0, "execution_rule": "blocking"}
+            ]}}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let opts = crate::commands::synthetics::RunOptions {
+        test_ids: vec!["abc-123".into(), "def-456".into()],
+        wait: true,
+        timeout: "1m".into(),
+        junit_file: Some(junit.to_str().unwrap().into()),
+        ..Default::default()
+    };
+    let err = crate::commands::synthetics::run(&cfg, &opts)
+        .await
+        .unwrap_err();
+    assert!(
+        err.to_string()
+            .contains("1 of 2 synthetic test results failed"),
+        "{err}"
+    );
+    let report = std::fs::read_to_string(&junit).unwrap();
+    assert!(
+        report.contains(r#"<testsuite name="Login" tests="1" failures="1""#),
+        "{report}"
+    );
+    trigger.assert_async().await;
+    batch.assert_async().await;
+    std::fs::remove_file(&junit).ok();
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_cases_projects_list() {
+async fn test_synthetics_locations_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cases::projects_list(&cfg).await;
+    mock_all(&mut s, r#"{"locations": []}"#).await;
+    let _ = crate::commands::synthetics::locations_list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_cases_projects_get() {
+async fn test_synthetics_results_picks_endpoint_by_test_type() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::cases::projects_get(&cfg, "proj1").await;
+    let mut cfg = test_config(&s.url());
+    cfg.output_format = crate::config::OutputFormat::Junit;
+    let _test = s
+        .mock("GET", "/api/v1/synthetics/tests/brw-123")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"public_id": "brw-123", "name": "Checkout", "type": "browser"}"#)
+        .create_async()
+        .await;
+    let browser = s
+        .mock("GET", "/api/v1/synthetics/tests/browser/brw-123/results")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"results": [
+                {"result_id": "1", "probe_dc": "aws:us-east-1", "status": 0, "result": {"passed": true}},
+                {"result_id": "2", "probe_dc": "aws:eu-west-1", "status": 1, "result": {"passed": false}}
+            ]}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let api = s
+        .mock("GET", "/api/v1/synthetics/tests/brw-123/results")
+        .expect(0)
+        .create_async()
+        .await;
+
+    let result = crate::commands::synthetics::results(&cfg, "brw-123").await;
+    assert!(
+        result.is_ok(),
+        "synthetics results failed: {:?}",
+        result.err()
+    );
+    browser.assert_async().await;
+    api.assert_async().await;
     cleanup_env();
 }
+
+// --- App Keys ---
 #[tokio::test]
-async fn test_cases_projects_delete() {
+async fn test_app_keys_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::cases::projects_delete(&cfg, "proj1").await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::app_keys::list(&cfg, 10, 0).await;
     cleanup_env();
 }
-
-// --- Integrations ---
 #[tokio::test]
-async fn test_integrations_jira_accounts_list() {
+async fn test_app_keys_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::integrations::jira_accounts_list(&cfg).await;
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::app_keys::get(&cfg, "k1").await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_integrations_jira_templates_list() {
+async fn test_app_keys_unregister() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::integrations::jira_templates_list(&cfg).await;
+    mock_all(&mut s, r#"{}"#).await;
+    let _ = crate::commands::app_keys::unregister(&cfg, "k1").await;
     cleanup_env();
 }
+
+// --- API Keys ---
 #[tokio::test]
-async fn test_integrations_servicenow_instances_list() {
+async fn test_api_keys_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::integrations::servicenow_instances_list(&cfg).await;
+    let _ = crate::commands::api_keys::list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_integrations_servicenow_templates_list() {
+async fn test_api_keys_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::integrations::servicenow_templates_list(&cfg).await;
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::api_keys::get(&cfg, "k1").await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_integrations_slack_list() {
+async fn test_api_keys_delete() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::integrations::slack_list(&cfg).await;
+    mock_all(&mut s, r#"{}"#).await;
+    let _ = crate::commands::api_keys::delete(&cfg, "k1").await;
     cleanup_env();
 }
+
+// --- Audit Logs ---
 #[tokio::test]
-async fn test_integrations_webhooks_list() {
+async fn test_audit_logs_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::integrations::webhooks_list(&cfg).await;
+    let _ = crate::commands::audit_logs::list(&cfg, "1h".into(), "now".into(), 10).await;
     cleanup_env();
 }
 
-// --- CI/CD ---
+// --- Users ---
 #[tokio::test]
-async fn test_cicd_pipelines_list() {
+async fn test_users_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cicd::pipelines_list(&cfg, None, "1h".into(), "now".into(), 10).await;
+    let _ = crate::commands::users::list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_cicd_tests_list() {
+async fn test_users_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cicd::tests_list(&cfg, None, "1h".into(), "now".into(), 10).await;
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::users::get(&cfg, "u1").await;
     cleanup_env();
 }
-
-// --- Fleet ---
 #[tokio::test]
-async fn test_fleet_agents_list() {
+async fn test_users_roles_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::fleet::agents_list(&cfg, None).await;
+    let _ = crate::commands::users::roles_list(&cfg).await;
     cleanup_env();
 }
+
+// --- Usage ---
 #[tokio::test]
-async fn test_fleet_agents_get() {
+async fn test_usage_summary() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::fleet::agents_get(&cfg, "a1").await;
+    mock_all(&mut s, r#"{"usage": []}"#).await;
+    let _ = crate::commands::usage::summary(&cfg, "2024-01".into(), None).await;
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_fleet_agents_versions() {
+async fn test_usage_top_metrics_queries_each_day() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::fleet::agents_versions(&cfg).await;
+    // Each day is read in full, not cut to --limit, before ranking
+    let mock = s
+        .mock("GET", "/api/v1/usage/top_avg_metrics")
+        .match_query(mockito::Matcher::UrlEncoded("limit".into(), "5000".into()))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"usage": [
+                {"metric_name": "app.requests", "metric_category": "custom", "avg_metric_hour": 1200, "max_metric_hour": 1800},
+                {"metric_name": "app.latency", "metric_category": "custom", "avg_metric_hour": 300, "max_metric_hour": 320}
+            ], "metadata": {}}"#,
+        )
+        .expect(2)
+        .create_async()
+        .await;
+
+    let result = crate::commands::usage::top_metrics(&cfg, "2d".into(), 5).await;
+    assert!(
+        result.is_ok(),
+        "usage top-metrics failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
     cleanup_env();
 }
+
+// --- Infrastructure ---
 #[tokio::test]
-async fn test_fleet_deployments_list() {
+async fn test_infrastructure_hosts_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::fleet::deployments_list(&cfg, None).await;
+    mock_all(&mut s, r#"{"host_list": [], "total_returned": 0}"#).await;
+    let _ = crate::commands::infrastructure::hosts_list(&cfg, None, "name".into(), 10, None).await;
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_fleet_schedules_list() {
+async fn test_infrastructure_hosts_list_pages_to_count() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::fleet::schedules_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let hosts = |n: usize, offset: usize| {
+        (0..n)
+            .map(|i| serde_json::json!({"name": format!("host-{}", offset + i)}))
+            .collect::<Vec<_>>()
+    };
+    let first = server
+        .mock("GET", "/api/v1/hosts")
+        .match_query(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::UrlEncoded("start".into(), "0".into()),
+            mockito::Matcher::UrlEncoded("count".into(), "1000".into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({"host_list": hosts(1000, 0), "total_matching": 1500}).to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let second = server
+        .mock("GET", "/api/v1/hosts")
+        .match_query(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::UrlEncoded("start".into(), "1000".into()),
+            mockito::Matcher::UrlEncoded("count".into(), "200".into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({"host_list": hosts(200, 1000), "total_matching": 1500}).to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result =
+        crate::commands::infrastructure::hosts_list(&cfg, None, "name".into(), 1200, None).await;
+    assert!(result.is_ok(), "hosts list failed: {:?}", result.err());
+    first.assert_async().await;
+    second.assert_async().await;
     cleanup_env();
 }
 
-// --- Incidents ---
 #[tokio::test]
-async fn test_incidents_list() {
+async fn test_infrastructure_hosts_mute() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::incidents::list(&cfg, 10).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v1/host/web-1/mute")
+        .match_body(mockito::Matcher::PartialJson(
+            serde_json::json!({"message": "OS patching"}),
+        ))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"action": "Muted", "hostname": "web-1", "message": "OS patching"}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::infrastructure::hosts_mute(
+        &cfg,
+        "web-1",
+        Some("2h"),
+        Some("OS patching".into()),
+    )
+    .await;
+    assert!(result.is_ok(), "hosts mute failed: {:?}", result.err());
+    mock.assert_async().await;
     cleanup_env();
 }
+
+// --- Traces ---
 #[tokio::test]
-async fn test_incidents_get() {
+async fn test_traces_search_follows_cursor() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::incidents::get(&cfg, "inc1").await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let span = |id: &str| serde_json::json!({"id": id, "type": "spans", "attributes": {}});
+    let first = server
+        .mock("POST", "/api/v2/spans/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"attributes": {"page": {"limit": 3}}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({
+                "data": [span("s1"), span("s2")],
+                "meta": {"page": {"after": "next-page"}}
+            })
+            .to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+    let second = server
+        .mock("POST", "/api/v2/spans/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"attributes": {"page": {"limit": 1, "cursor": "next-page"}}}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(serde_json::json!({"data": [span("s3")], "meta": {"page": {}}}).to_string())
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::traces::search(
+        &cfg,
+        "service:api".into(),
+        "1h".into(),
+        "now".into(),
+        3,
+        "-timestamp".into(),
+    )
+    .await;
+    assert!(result.is_ok(), "traces search failed: {:?}", result.err());
+    first.assert_async().await;
+    second.assert_async().await;
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_incidents_settings_get() {
+async fn test_traces_aggregate_timeseries_by_facets() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::incidents::settings_get(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/spans/analytics/aggregate")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"attributes": {
+                "compute": [
+                    {"aggregation": "median", "metric": "@duration", "type": "timeseries", "interval": "5m"},
+                    {"aggregation": "pc99", "metric": "@duration", "type": "timeseries", "interval": "5m"}
+                ],
+                "group_by": [
+                    {"facet": "resource_name", "limit": 5},
+                    {"facet": "version", "limit": 5}
+                ]
+            }}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": [], "meta": {"status": "done"}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::traces::aggregate(
+        &cfg,
+        "service:api".into(),
+        "1h".into(),
+        "now".into(),
+        vec![
+            "median(@duration)".into(),
+            "percentile(@duration, 99)".into(),
+        ],
+        Some("5m".into()),
+        vec!["resource_name".into(), "version".into()],
+        5,
+    )
+    .await;
+    assert!(
+        result.is_ok(),
+        "traces aggregate failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_incidents_handles_list() {
+async fn test_traces_get_queries_trace_id() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::incidents::handles_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v2/spans/events/search")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"attributes": {
+                "filter": {"query": "trace_id:4242"},
+                "sort": "timestamp"
+            }}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({"data": [{"id": "1", "type": "spans", "attributes": {
+                "span_id": "1", "parent_id": "0", "service": "web",
+                "start_timestamp": "2024-01-01T00:00:00Z"
+            }}], "meta": {"page": {}}})
+            .to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result =
+        crate::commands::traces::get(&cfg, "4242", "1d".into(), "now".into(), 100, "tree".into())
+            .await;
+    assert!(result.is_ok(), "traces get failed: {:?}", result.err());
+    mock.assert_async().await;
     cleanup_env();
 }
+
+// --- Notebooks ---
 #[tokio::test]
-async fn test_incidents_postmortem_templates_list() {
+async fn test_notebooks_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::incidents::postmortem_templates_list(&cfg).await;
+    let _ = crate::commands::notebooks::list(&cfg).await;
     cleanup_env();
 }
 
-// --- On-Call ---
+// --- Downtime ---
 #[tokio::test]
-async fn test_on_call_teams_list() {
+async fn test_downtime_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::on_call::teams_list(&cfg).await;
+    let _ = crate::commands::downtime::list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_on_call_teams_get() {
+async fn test_downtime_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::on_call::teams_get(&cfg, "t1").await;
+    let _ = crate::commands::downtime::get(&cfg, "d1").await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_on_call_teams_delete() {
+async fn test_downtime_create_recurring() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::on_call::teams_delete(&cfg, "t1").await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let create = server
+        .mock("POST", "/api/v2/downtime")
+        .match_body(mockito::Matcher::PartialJson(serde_json::json!({
+            "data": {"type": "downtime", "attributes": {
+                "scope": "env:staging",
+                "monitor_identifier": {"monitor_tags": ["team:web"]},
+                "schedule": {
+                    "recurrences": [{"rrule": "FREQ=WEEKLY;BYDAY=SA", "duration": "2h"}],
+                    "timezone": "UTC"
+                }
+            }}
+        })))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": {"id": "dt-1", "type": "downtime"}}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let schedule = crate::commands::downtime::DowntimeSchedule {
+        scope: "env:staging".into(),
+        monitor_tags: vec!["team:web".into()],
+        start: Some("2024-01-06T22:00:00Z".into()),
+        duration: Some("2h".into()),
+        rrule: Some("FREQ=WEEKLY;BYDAY=SA".into()),
+        ..Default::default()
+    };
+    let result = crate::commands::downtime::create_scheduled(&cfg, &schedule).await;
+    assert!(result.is_ok(), "downtime create failed: {:?}", result.err());
+    create.assert_async().await;
     cleanup_env();
 }
 
-// --- Security ---
+// --- Cost ---
 #[tokio::test]
-async fn test_security_rules_list() {
+async fn test_cost_projected() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::security::rules_list(&cfg).await;
+    let _ = crate::commands::cost::projected(&cfg).await;
     cleanup_env();
 }
+
+// --- Error Tracking ---
 #[tokio::test]
-async fn test_security_rules_get() {
+async fn test_error_tracking_issues_search() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::security::rules_get(&cfg, "r1").await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::error_tracking::issues_search(&cfg, None, 10).await;
     cleanup_env();
 }
+
+// --- Cloud ---
 #[tokio::test]
-async fn test_security_content_packs_list() {
+async fn test_cloud_aws_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::security::content_packs_list(&cfg).await;
+    let _ = crate::commands::cloud::aws_list(&cfg).await;
     cleanup_env();
 }
-
-// --- Synthetics ---
 #[tokio::test]
-async fn test_synthetics_tests_list() {
+async fn test_cloud_gcp_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"tests": []}"#).await;
-    let _ = crate::commands::synthetics::tests_list(&cfg).await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::cloud::gcp_list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_synthetics_tests_get() {
+async fn test_cloud_azure_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::synthetics::tests_get(&cfg, "pub1").await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::cloud::azure_list(&cfg).await;
     cleanup_env();
 }
+
+// --- Organizations ---
 #[tokio::test]
-async fn test_synthetics_locations_list() {
+async fn test_organizations_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"locations": []}"#).await;
-    let _ = crate::commands::synthetics::locations_list(&cfg).await;
+    mock_all(&mut s, r#"{"orgs": []}"#).await;
+    let _ = crate::commands::organizations::list(&cfg).await;
     cleanup_env();
 }
 
-// --- App Keys ---
+// --- Service Catalog ---
 #[tokio::test]
-async fn test_app_keys_list() {
+async fn test_service_catalog_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::app_keys::list(&cfg, 10, 0).await;
+    let _ = crate::commands::service_catalog::list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_app_keys_get() {
+async fn test_service_catalog_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::app_keys::get(&cfg, "k1").await;
-    cleanup_env();
-}
-#[tokio::test]
-async fn test_app_keys_unregister() {
-    let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::app_keys::unregister(&cfg, "k1").await;
+    let _ = crate::commands::service_catalog::get(&cfg, "svc1").await;
     cleanup_env();
 }
 
-// --- API Keys ---
-#[tokio::test]
-async fn test_api_keys_list() {
-    let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::api_keys::list(&cfg).await;
-    cleanup_env();
-}
+// --- Service Checks ---
 #[tokio::test]
-async fn test_api_keys_get() {
+async fn test_service_checks_submit() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::api_keys::get(&cfg, "k1").await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("POST", "/api/v1/check_run")
+        .match_body(mockito::Matcher::Json(serde_json::json!([{
+            "check": "backup.nightly",
+            "host_name": "db-1",
+            "status": 1,
+            "tags": ["env:prod"],
+            "message": "slow run"
+        }])))
+        .with_status(202)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"status": "ok"}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let run = crate::commands::service_checks::CheckRun {
+        check: "backup.nightly".into(),
+        status: "warning".into(),
+        host: "db-1".into(),
+        tags: Some("env:prod".into()),
+        message: Some("slow run".into()),
+    };
+    let result = crate::commands::service_checks::submit(&cfg, &run).await;
+    assert!(
+        result.is_ok(),
+        "service check submit failed: {:?}",
+        result.err()
+    );
+    mock.assert_async().await;
     cleanup_env();
 }
+
+// --- Misc ---
 #[tokio::test]
-async fn test_api_keys_delete() {
+async fn test_misc_ip_ranges() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::api_keys::delete(&cfg, "k1").await;
+    let _ = crate::commands::misc::ip_ranges(&cfg).await;
     cleanup_env();
 }
 
-// --- Audit Logs ---
+// --- Data Governance ---
 #[tokio::test]
-async fn test_audit_logs_list() {
+async fn test_data_governance_scanner_rules_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::audit_logs::list(&cfg, "1h".into(), "now".into(), 10).await;
+    let _ = crate::commands::data_governance::scanner_rules_list(&cfg).await;
     cleanup_env();
 }
 
-// --- Users ---
+// --- Investigations ---
 #[tokio::test]
-async fn test_users_list() {
+async fn test_investigations_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::users::list(&cfg).await;
+    let _ = crate::commands::investigations::list(&cfg, 10, 0).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_users_get() {
+async fn test_investigations_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::users::get(&cfg, "u1").await;
+    let _ = crate::commands::investigations::get(&cfg, "inv1").await;
     cleanup_env();
 }
+
+// --- Network ---
 #[tokio::test]
-async fn test_users_roles_list() {
+async fn test_network_flows_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::users::roles_list(&cfg).await;
+    let _ = crate::commands::network::flows_list(&cfg).await;
     cleanup_env();
 }
-
-// --- Usage ---
 #[tokio::test]
-async fn test_usage_summary() {
+async fn test_network_devices_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"usage": []}"#).await;
-    let _ = crate::commands::usage::summary(&cfg, "2024-01".into(), None).await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::network::devices_list(&cfg).await;
     cleanup_env();
 }
 
-// --- Infrastructure ---
+// --- Code Coverage ---
 #[tokio::test]
-async fn test_infrastructure_hosts_list() {
+async fn test_code_coverage_branch_summary() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"host_list": [], "total_returned": 0}"#).await;
-    let _ = crate::commands::infrastructure::hosts_list(&cfg, None, "name".into(), 10).await;
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ =
+        crate::commands::code_coverage::branch_summary(&cfg, "repo".into(), "main".into()).await;
     cleanup_env();
 }
 
-// --- Notebooks ---
+// --- HAMR ---
 #[tokio::test]
-async fn test_notebooks_list() {
+async fn test_hamr_connections_get() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::notebooks::list(&cfg).await;
+    mock_all(&mut s, r#"{"data": {}}"#).await;
+    let _ = crate::commands::hamr::connections_get(&cfg).await;
     cleanup_env();
 }
 
-// --- Downtime ---
+// --- Static Analysis ---
 #[tokio::test]
-async fn test_downtime_list() {
+async fn test_static_analysis_ast_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::downtime::list(&cfg).await;
+    let _ = crate::commands::static_analysis::ast_list(&cfg).await;
     cleanup_env();
 }
 #[tokio::test]
-async fn test_downtime_get() {
+async fn test_static_analysis_sca_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::downtime::get(&cfg, "d1").await;
+    mock_all(&mut s, r#"{"data": []}"#).await;
+    let _ = crate::commands::static_analysis::sca_list(&cfg).await;
     cleanup_env();
 }
-
-// --- Cost ---
 #[tokio::test]
-async fn test_cost_projected() {
+async fn test_static_analysis_custom_rulesets_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cost::projected(&cfg).await;
+    let _ = crate::commands::static_analysis::custom_rulesets_list(&cfg).await;
     cleanup_env();
 }
-
-// --- Error Tracking ---
 #[tokio::test]
-async fn test_error_tracking_issues_search() {
+async fn test_static_analysis_coverage_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::error_tracking::issues_search(&cfg, None, 10).await;
+    let _ = crate::commands::static_analysis::coverage_list(&cfg).await;
     cleanup_env();
 }
 
-// --- Cloud ---
+// --- APM ---
 #[tokio::test]
-async fn test_cloud_aws_list() {
+async fn test_apm_services_list() {
     let _lock = lock_env();
     let mut s = mockito::Server::new_async().await;
     let cfg = test_config(&s.url());
     mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cloud::aws_list(&cfg).await;
+    let _ =
+        crate::commands::apm::services_list(&cfg, "prod".into(), "1h".into(), "now".into()).await;
     cleanup_env();
 }
+
+// --- Envelope ---
+#[test]
+fn test_build_request_info_resolves_window() {
+    use clap::CommandFactory;
+    let argv = [
+        "pup",
+        "logs",
+        "search",
+        "--query=status:error",
+        "--from=2024-01-01T00:00:00Z",
+        "--to=2024-01-01T01:00:00Z",
+        "--envelope",
+    ];
+    let matches = crate::Cli::command().try_get_matches_from(argv).unwrap();
+    let args: Vec<String> = argv[1..].iter().map(|s| s.to_string()).collect();
+    let info = crate::build_request_info(&matches, &args);
+    assert_eq!(info.command, "logs search");
+    assert_eq!(info.args, args);
+    assert_eq!(info.from.as_deref(), Some("2024-01-01T00:00:00+00:00"));
+    assert_eq!(info.to.as_deref(), Some("2024-01-01T01:00:00+00:00"));
+    assert!(!info.timestamp.is_empty());
+}
+
+#[test]
+fn test_build_request_info_without_window() {
+    use clap::CommandFactory;
+    let argv = ["pup", "monitors", "get", "12345"];
+    let matches = crate::Cli::command().try_get_matches_from(argv).unwrap();
+    let info = crate::build_request_info(&matches, &[]);
+    assert_eq!(info.command, "monitors get");
+    assert!(info.from.is_none());
+    assert!(info.to.is_none());
+}
+
+// --- Multi-org (--orgs) ---
 #[tokio::test]
-async fn test_cloud_gcp_list() {
+async fn test_collect_org_results_isolates_failures() {
+    use clap::CommandFactory;
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cloud::gcp_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let body = r#"{"id": 12345, "name": "Test Monitor", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{*} > 90", "message": "CPU high", "tags": [], "options": {}}"#;
+    let _mock = mock_any(&mut server, "GET", body).await;
+
+    let mut profiles = std::collections::HashMap::new();
+    profiles.insert(
+        "alpha".to_string(),
+        crate::config::Profile {
+            api_key: Some("alpha-api".into()),
+            app_key: Some("alpha-app".into()),
+            ..Default::default()
+        },
+    );
+    // No credentials: the command fails auth validation for this org only
+    profiles.insert("beta".to_string(), crate::config::Profile::default());
+
+    let argv = [
+        "pup",
+        "monitors",
+        "get",
+        "12345",
+        "--orgs",
+        "alpha,beta,gamma",
+    ];
+    let matches = crate::Cli::command().try_get_matches_from(argv).unwrap();
+    let args: Vec<String> = argv[1..].iter().map(|s| s.to_string()).collect();
+    let blocks = crate::collect_org_results(&cfg, &matches, &args, "alpha,beta,gamma", &profiles)
+        .await
+        .unwrap();
+
+    assert_eq!(blocks.len(), 3);
+    assert_eq!(blocks[0]["request"]["profile"], "alpha");
+    assert_eq!(blocks[0]["request"]["command"], "monitors get");
+    assert_eq!(blocks[0]["result"]["id"], 12345);
+    assert_eq!(blocks[1]["request"]["profile"], "beta");
+    assert!(blocks[1]["error"]
+        .as_str()
+        .unwrap()
+        .contains("authentication required"));
+    assert_eq!(blocks[2]["request"]["profile"], "gamma");
+    assert!(blocks[2]["error"].as_str().unwrap().contains("not found"));
     cleanup_env();
 }
-#[tokio::test]
-async fn test_cloud_azure_list() {
-    let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::cloud::azure_list(&cfg).await;
-    cleanup_env();
+
+// --- Batch ---
+fn batch_steps(json: &str) -> Vec<crate::commands::batch::BatchStep> {
+    let path = std::env::temp_dir().join("pup_test_batch.json");
+    std::fs::write(&path, json).unwrap();
+    let steps = crate::commands::batch::load_steps(path.to_str().unwrap()).unwrap();
+    let _ = std::fs::remove_file(&path);
+    steps
+}
+
+async fn mock_monitor_routes(server: &mut mockito::Server) -> (mockito::Mock, mockito::Mock) {
+    let monitor = r#"{"id": 12345, "name": "Test Monitor", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{*} > 90", "message": "CPU high", "tags": [], "options": {}}"#;
+    let list = server
+        .mock("GET", "/api/v1/monitor")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(format!("[{monitor}]"))
+        .create_async()
+        .await;
+    let get = server
+        .mock("GET", "/api/v1/monitor/12345")
+        .match_query(mockito::Matcher::Any)
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(monitor)
+        .create_async()
+        .await;
+    (list, get)
 }
 
-// --- Organizations ---
 #[tokio::test]
-async fn test_organizations_list() {
+async fn test_batch_runs_steps_in_order() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"orgs": []}"#).await;
-    let _ = crate::commands::organizations::list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mocks = mock_monitor_routes(&mut server).await;
+
+    let steps = batch_steps(
+        r#"{"steps": [
+            {"name": "all", "command": "monitors list", "args": ["--limit", "5"]},
+            {"command": "monitors get", "args": ["12345"]}
+        ]}"#,
+    );
+    let blocks = crate::collect_batch_results(&cfg, &steps, false)
+        .await
+        .unwrap();
+
+    assert_eq!(blocks.len(), 2);
+    assert_eq!(blocks[0]["step"], 1);
+    assert_eq!(blocks[0]["name"], "all");
+    assert_eq!(blocks[0]["command"], "monitors list");
+    assert_eq!(blocks[0]["result"][0]["id"], 12345);
+    assert_eq!(blocks[1]["step"], 2);
+    assert_eq!(blocks[1]["command"], "monitors get");
+    assert_eq!(blocks[1]["result"]["name"], "Test Monitor");
     cleanup_env();
 }
 
-// --- Service Catalog ---
 #[tokio::test]
-async fn test_service_catalog_list() {
+async fn test_batch_stops_on_error_unless_continuing() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::service_catalog::list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mocks = mock_monitor_routes(&mut server).await;
+
+    let json = r#"{"steps": [
+        {"command": "monitors get", "args": ["not-a-number"]},
+        {"command": "monitors get", "args": ["12345"]}
+    ]}"#;
+
+    let blocks = crate::collect_batch_results(&cfg, &batch_steps(json), false)
+        .await
+        .unwrap();
+    assert_eq!(blocks.len(), 1, "batch must stop at the failing step");
+    assert!(blocks[0]["error"]
+        .as_str()
+        .unwrap()
+        .contains("not-a-number"));
+
+    let blocks = crate::collect_batch_results(&cfg, &batch_steps(json), true)
+        .await
+        .unwrap();
+    assert_eq!(blocks.len(), 2);
+    assert!(blocks[0].get("error").is_some());
+    assert_eq!(blocks[1]["result"]["id"], 12345);
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_service_catalog_get() {
+async fn test_batch_rejects_global_flags_in_steps() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::service_catalog::get(&cfg, "svc1").await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mocks = mock_monitor_routes(&mut server).await;
+
+    let json = r#"{"steps": [
+        {"command": "monitors get", "args": ["12345", "--output", "table"]},
+        {"command": "monitors get", "args": ["12345", "--yes"]}
+    ]}"#;
+    let blocks = crate::collect_batch_results(&cfg, &batch_steps(json), true)
+        .await
+        .unwrap();
+    assert!(blocks[0]["error"].as_str().unwrap().contains("--output"));
+    assert!(blocks[1]["error"].as_str().unwrap().contains("--yes"));
     cleanup_env();
 }
 
-// --- Misc ---
 #[tokio::test]
-async fn test_misc_ip_ranges() {
+async fn test_batch_step_confirmation_requires_yes() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{}"#).await;
-    let _ = crate::commands::misc::ip_ranges(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let mut cfg = test_config(&server.url());
+    let delete = server
+        .mock("DELETE", "/api/v1/logs/config/indexes/main")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body("{}")
+        .expect(1)
+        .create_async()
+        .await;
+
+    let json = r#"{"steps": [{"command": "logs indexes delete", "args": ["main"]}]}"#;
+    let blocks = crate::collect_batch_results(&cfg, &batch_steps(json), false)
+        .await
+        .unwrap();
+    assert!(
+        blocks[0]["error"].as_str().unwrap().contains("--yes"),
+        "{}",
+        blocks[0]
+    );
+
+    cfg.auto_approve = true;
+    let blocks = crate::collect_batch_results(&cfg, &batch_steps(json), false)
+        .await
+        .unwrap();
+    assert!(blocks[0].get("error").is_none(), "{}", blocks[0]);
+    delete.assert_async().await;
     cleanup_env();
 }
 
-// --- Data Governance ---
 #[tokio::test]
-async fn test_data_governance_scanner_rules_list() {
+async fn test_batch_runs_under_orgs() {
+    use clap::CommandFactory;
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::data_governance::scanner_rules_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mocks = mock_monitor_routes(&mut server).await;
+
+    let path = std::env::temp_dir().join("pup_test_batch_orgs.json");
+    std::fs::write(
+        &path,
+        r#"{"steps": [{"command": "monitors get", "args": ["12345"]}]}"#,
+    )
+    .unwrap();
+    let mut profiles = std::collections::HashMap::new();
+    profiles.insert(
+        "alpha".to_string(),
+        crate::config::Profile {
+            api_key: Some("alpha-api".into()),
+            app_key: Some("alpha-app".into()),
+            ..Default::default()
+        },
+    );
+
+    let argv = [
+        "pup",
+        "batch",
+        "--file",
+        path.to_str().unwrap(),
+        "--orgs",
+        "alpha",
+    ];
+    let matches = crate::Cli::command().try_get_matches_from(argv).unwrap();
+    let args: Vec<String> = argv[1..].iter().map(|s| s.to_string()).collect();
+    let blocks = crate::collect_org_results(&cfg, &matches, &args, "alpha", &profiles)
+        .await
+        .unwrap();
+    let _ = std::fs::remove_file(&path);
+
+    assert_eq!(blocks.len(), 1);
+    assert_eq!(blocks[0]["request"]["profile"], "alpha");
+    assert_eq!(blocks[0]["result"][0]["step"], 1);
+    assert_eq!(blocks[0]["result"][0]["result"]["id"], 12345);
     cleanup_env();
 }
 
-// --- Investigations ---
+// --- Raw ---
 #[tokio::test]
-async fn test_investigations_list() {
+async fn test_raw_get_formats_json() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::investigations::list(&cfg, 10, 0).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let mock = server
+        .mock("GET", "/api/v1/validate")
+        .match_header("DD-API-KEY", "test-api-key")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"valid": true}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result =
+        crate::commands::raw::run(&cfg, "get", "/api/v1/validate", None, false, None).await;
+    assert!(result.is_ok(), "raw get failed: {:?}", result.err());
+    mock.assert_async().await;
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_investigations_get() {
+async fn test_raw_non_json_bodies_are_verbatim() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::investigations::get(&cfg, "inv1").await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let text = "status: ok\n  not: [json\n";
+    let csv = "host,cpu\n\"web-1, eu\",0.5\n";
+    let _text = server
+        .mock("GET", "/api/v1/text")
+        .with_status(200)
+        .with_header("content-type", "text/plain; charset=utf-8")
+        .with_body(text)
+        .create_async()
+        .await;
+    let _csv = server
+        .mock("GET", "/api/v1/export.csv")
+        .with_status(200)
+        .with_header("content-type", "text/csv")
+        .with_body(csv)
+        .create_async()
+        .await;
+
+    for (path, expected) in [("/api/v1/text", text), ("/api/v1/export.csv", csv)] {
+        match crate::commands::raw::fetch(&cfg, "GET", path, None).await {
+            Ok(crate::commands::raw::RawBody::Text(body)) => assert_eq!(body, expected),
+            Ok(crate::commands::raw::RawBody::Json(_)) => panic!("{path} was decoded as JSON"),
+            Err(e) => panic!("raw get {path} failed: {e}"),
+        }
+    }
+    let result = crate::commands::raw::run(&cfg, "GET", "/api/v1/text", None, false, None).await;
+    assert!(result.is_ok(), "raw text get failed: {:?}", result.err());
     cleanup_env();
 }
 
-// --- Network ---
 #[tokio::test]
-async fn test_network_flows_list() {
+async fn test_raw_get_json_keeps_large_integers() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::network::flows_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = server
+        .mock("GET", "/api/v2/apm/services")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data": [{"id": 98765432109876543210987654321}]}"#)
+        .create_async()
+        .await;
+
+    let raw = crate::client::raw_get_json(&cfg, "/api/v2/apm/services")
+        .await
+        .unwrap();
+    let out = crate::formatter::reindent_json(&raw.text);
+    assert!(
+        out.contains("\"id\": 98765432109876543210987654321"),
+        "{out}"
+    );
+    assert!(raw.value["data"][0]["id"].is_number());
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_network_devices_list() {
+async fn test_raw_stream_copies_body_bytes() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::network::devices_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    // Large and not JSON: streaming must neither buffer-decode nor alter it
+    let body: Vec<u8> = (0..2_000_000u32).map(|i| (i % 251) as u8).collect();
+    let _mock = server
+        .mock("GET", "/api/v2/export")
+        .with_status(200)
+        .with_header("content-type", "application/octet-stream")
+        .with_body(body.clone())
+        .create_async()
+        .await;
+
+    let mut sink = Vec::new();
+    let written = crate::commands::raw::stream_to(&cfg, "GET", "/api/v2/export", None, &mut sink)
+        .await
+        .unwrap();
+    assert_eq!(written, body.len() as u64);
+    assert!(sink == body, "streamed bytes differ from the response body");
     cleanup_env();
 }
 
-// --- Code Coverage ---
 #[tokio::test]
-async fn test_code_coverage_branch_summary() {
+async fn test_raw_stream_checks_status_before_writing() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ =
-        crate::commands::code_coverage::branch_summary(&cfg, "repo".into(), "main".into()).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = server
+        .mock("GET", "/api/v2/export")
+        .with_status(403)
+        .with_body(r#"{"errors": ["Forbidden"]}"#)
+        .create_async()
+        .await;
+
+    let mut sink = Vec::new();
+    let err = crate::commands::raw::stream_to(&cfg, "GET", "/api/v2/export", None, &mut sink)
+        .await
+        .unwrap_err();
+    assert!(err.to_string().contains("HTTP 403"), "{err}");
+    assert!(sink.is_empty());
     cleanup_env();
 }
 
-// --- HAMR ---
 #[tokio::test]
-async fn test_hamr_connections_get() {
+async fn test_raw_output_file() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": {}}"#).await;
-    let _ = crate::commands::hamr::connections_get(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let _mock = server
+        .mock("POST", "/api/v2/export")
+        .match_body(mockito::Matcher::Json(serde_json::json!({"q": "x"})))
+        .with_status(200)
+        .with_body("a,b\n1,2\n")
+        .create_async()
+        .await;
+
+    let path = std::env::temp_dir().join("pup_test_raw_output.csv");
+    let result = crate::commands::raw::run(
+        &cfg,
+        "POST",
+        "/api/v2/export",
+        Some(serde_json::json!({"q": "x"})),
+        false,
+        path.to_str(),
+    )
+    .await;
+    assert!(
+        result.is_ok(),
+        "raw --output-file failed: {:?}",
+        result.err()
+    );
+    assert_eq!(std::fs::read_to_string(&path).unwrap(), "a,b\n1,2\n");
+    let _ = std::fs::remove_file(&path);
     cleanup_env();
 }
 
-// --- Static Analysis ---
+// -------------------------------------------------------------------------
+// OAuth token refresh on 401
+// -------------------------------------------------------------------------
+
+/// In-memory token store so refresh tests never touch the real keychain.
+#[derive(Default)]
+struct MemoryStorage {
+    tokens: Mutex<Option<crate::auth::types::TokenSet>>,
+    creds: Option<crate::auth::types::ClientCredentials>,
+}
+
+impl crate::auth::storage::Storage for MemoryStorage {
+    fn backend_type(&self) -> crate::auth::storage::BackendType {
+        crate::auth::storage::BackendType::File
+    }
+    fn storage_location(&self) -> String {
+        "memory".into()
+    }
+    fn save_tokens(
+        &self,
+        _site: &str,
+        tokens: &crate::auth::types::TokenSet,
+    ) -> anyhow::Result<()> {
+        *self.tokens.lock().unwrap() = Some(tokens.clone());
+        Ok(())
+    }
+    fn load_tokens(&self, _site: &str) -> anyhow::Result<Option<crate::auth::types::TokenSet>> {
+        Ok(self.tokens.lock().unwrap().clone())
+    }
+    fn delete_tokens(&self, _site: &str) -> anyhow::Result<()> {
+        *self.tokens.lock().unwrap() = None;
+        Ok(())
+    }
+    fn save_client_credentials(
+        &self,
+        _site: &str,
+        _creds: &crate::auth::types::ClientCredentials,
+    ) -> anyhow::Result<()> {
+        Ok(())
+    }
+    fn load_client_credentials(
+        &self,
+        _site: &str,
+    ) -> anyhow::Result<Option<crate::auth::types::ClientCredentials>> {
+        Ok(self.creds.clone())
+    }
+    fn delete_client_credentials(&self, _site: &str) -> anyhow::Result<()> {
+        Ok(())
+    }
+}
+
+/// Installs a store holding `access_token` plus a refresh token and returns a
+/// bearer-only config using that access token.
+fn oauth_config(mock_url: &str, access_token: &str) -> Config {
+    crate::auth::storage::replace_storage(Some(Box::new(MemoryStorage {
+        tokens: Mutex::new(Some(crate::auth::types::TokenSet {
+            access_token: access_token.into(),
+            refresh_token: "refresh-1".into(),
+            token_type: "Bearer".into(),
+            expires_in: 3600,
+            issued_at: 0,
+            scope: String::new(),
+            client_id: "client-1".into(),
+        })),
+        creds: Some(crate::auth::types::ClientCredentials {
+            client_id: "client-1".into(),
+            client_name: "pup".into(),
+            redirect_uris: vec![],
+            registered_at: 0,
+            site: "datadoghq.com".into(),
+        }),
+    })));
+    Config {
+        api_key: None,
+        app_key: None,
+        access_token: Some(access_token.into()),
+        ..test_config(mock_url)
+    }
+}
+
+fn stored_access_token() -> Option<String> {
+    let store = crate::auth::storage::get_storage().unwrap();
+    let guard = store.lock().unwrap();
+    let tokens = guard
+        .as_ref()
+        .unwrap()
+        .load_tokens("datadoghq.com")
+        .unwrap();
+    tokens.map(|t| t.access_token)
+}
+
+async fn mock_token_refresh(server: &mut mockito::Server, new_token: &str) -> mockito::Mock {
+    server
+        .mock("POST", "/oauth2/v1/token")
+        .match_body(mockito::Matcher::AllOf(vec![
+            mockito::Matcher::UrlEncoded("grant_type".into(), "refresh_token".into()),
+            mockito::Matcher::UrlEncoded("refresh_token".into(), "refresh-1".into()),
+        ]))
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            serde_json::json!({
+                "access_token": new_token,
+                "token_type": "Bearer",
+                "expires_in": 3600,
+                "refresh_token": "refresh-2"
+            })
+            .to_string(),
+        )
+        .expect(1)
+        .create_async()
+        .await
+}
+
 #[tokio::test]
-async fn test_static_analysis_ast_list() {
+async fn test_raw_get_refreshes_token_on_401() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::static_analysis::ast_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = oauth_config(&server.url(), "expired-raw");
+    let rejected = server
+        .mock("GET", "/api/v2/thing")
+        .match_header("authorization", "Bearer expired-raw")
+        .with_status(401)
+        .with_body(r#"{"errors":["Unauthorized"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let refresh = mock_token_refresh(&mut server, "fresh-raw").await;
+    let accepted = server
+        .mock("GET", "/api/v2/thing")
+        .match_header("authorization", "Bearer fresh-raw")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(r#"{"data":[]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::client::raw_get(&cfg, "/api/v2/thing").await;
+    assert_eq!(result.unwrap(), serde_json::json!({"data": []}));
+    rejected.assert_async().await;
+    refresh.assert_async().await;
+    accepted.assert_async().await;
+    assert_eq!(stored_access_token().as_deref(), Some("fresh-raw"));
+    assert_eq!(crate::client::current_token("expired-raw"), "fresh-raw");
+    crate::auth::storage::replace_storage(None);
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_static_analysis_sca_list() {
+async fn test_typed_client_refreshes_token_on_401() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::static_analysis::sca_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = oauth_config(&server.url(), "expired-typed");
+    let rejected = server
+        .mock("GET", "/api/v1/monitor")
+        .match_query(mockito::Matcher::Any)
+        .match_header("authorization", "Bearer expired-typed")
+        .with_status(401)
+        .with_body(r#"{"errors":["Unauthorized"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let refresh = mock_token_refresh(&mut server, "fresh-typed").await;
+    let accepted = server
+        .mock("GET", "/api/v1/monitor")
+        .match_query(mockito::Matcher::Any)
+        .match_header("authorization", "Bearer fresh-typed")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body("[]")
+        .expect(1)
+        .create_async()
+        .await;
+
+    let result = crate::commands::monitors::list(&cfg, None, None, 10).await;
+    assert!(result.is_ok(), "monitors list failed: {:?}", result.err());
+    rejected.assert_async().await;
+    refresh.assert_async().await;
+    accepted.assert_async().await;
+    assert_eq!(stored_access_token().as_deref(), Some("fresh-typed"));
+    crate::auth::storage::replace_storage(None);
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_static_analysis_custom_rulesets_list() {
+async fn test_failed_refresh_surfaces_original_401() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::static_analysis::custom_rulesets_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = oauth_config(&server.url(), "expired-revoked");
+    let rejected = server
+        .mock("GET", "/api/v2/thing")
+        .with_status(401)
+        .with_body(r#"{"errors":["Unauthorized"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let refresh = server
+        .mock("POST", "/oauth2/v1/token")
+        .with_status(400)
+        .with_body(r#"{"error":"invalid_grant"}"#)
+        .expect(1)
+        .create_async()
+        .await;
+
+    let err = crate::client::raw_get(&cfg, "/api/v2/thing")
+        .await
+        .unwrap_err();
+    assert!(err.to_string().contains("HTTP 401"), "{err}");
+    rejected.assert_async().await;
+    refresh.assert_async().await;
+    assert_eq!(stored_access_token().as_deref(), Some("expired-revoked"));
+    crate::auth::storage::replace_storage(None);
     cleanup_env();
 }
+
 #[tokio::test]
-async fn test_static_analysis_coverage_list() {
+async fn test_env_access_token_is_not_refreshed() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ = crate::commands::static_analysis::coverage_list(&cfg).await;
+    let mut server = mockito::Server::new_async().await;
+    let mut cfg = oauth_config(&server.url(), "stored-token");
+    // A DD_ACCESS_TOKEN that differs from the stored login token.
+    cfg.access_token = Some("env-token".into());
+    let rejected = server
+        .mock("GET", "/api/v2/thing")
+        .with_status(401)
+        .with_body(r#"{"errors":["Unauthorized"]}"#)
+        .expect(1)
+        .create_async()
+        .await;
+    let refresh = server
+        .mock("POST", "/oauth2/v1/token")
+        .expect(0)
+        .create_async()
+        .await;
+
+    let err = crate::client::raw_get(&cfg, "/api/v2/thing")
+        .await
+        .unwrap_err();
+    assert!(err.to_string().contains("HTTP 401"), "{err}");
+    rejected.assert_async().await;
+    refresh.assert_async().await;
+    crate::auth::storage::replace_storage(None);
     cleanup_env();
 }
 
-// --- APM ---
 #[tokio::test]
-async fn test_apm_services_list() {
+async fn test_device_client_follows_mock_server() {
     let _lock = lock_env();
-    let mut s = mockito::Server::new_async().await;
-    let cfg = test_config(&s.url());
-    mock_all(&mut s, r#"{"data": []}"#).await;
-    let _ =
-        crate::commands::apm::services_list(&cfg, "prod".into(), "1h".into(), "now".into()).await;
+    let mut server = mockito::Server::new_async().await;
+    let cfg = test_config(&server.url());
+    let authorize = server
+        .mock("POST", "/oauth2/v1/device/authorize")
+        .with_status(200)
+        .with_header("content-type", "application/json")
+        .with_body(
+            r#"{"device_code": "dev-1", "user_code": "WXYZ-1234",
+                "verification_uri": "https://app.datadoghq.com/device", "expires_in": 600}"#,
+        )
+        .expect(1)
+        .create_async()
+        .await;
+
+    let client = crate::auth::device::DeviceClient::new(&cfg.site).unwrap();
+    let auth = client
+        .authorize("client-1", &["metrics_read"])
+        .await
+        .unwrap();
+    assert_eq!(auth.user_code, "WXYZ-1234");
+    authorize.assert_async().await;
     cleanup_env();
 }
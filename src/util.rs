@@ -37,22 +37,7 @@ pub fn parse_time_to_unix_millis(input: &str) -> Result<i64> {
     // Relative time — strip leading minus
     let stripped = input.trim_start_matches('-').trim();
 
-    let re = Regex::new(
-        r"(?i)^(\d+)\s*(s|sec|secs|second|seconds|m|min|mins|minute|minutes|h|hr|hrs|hour|hours|d|day|days|w|week|weeks)$",
-    )
-    .unwrap();
-
-    if let Some(caps) = re.captures(stripped) {
-        let num: i64 = caps[1].parse()?;
-        let unit = caps[2].to_lowercase();
-        let seconds = match unit.as_str() {
-            "s" | "sec" | "secs" | "second" | "seconds" => num,
-            "m" | "min" | "mins" | "minute" | "minutes" => num * 60,
-            "h" | "hr" | "hrs" | "hour" | "hours" => num * 3600,
-            "d" | "day" | "days" => num * 86400,
-            "w" | "week" | "weeks" => num * 7 * 86400,
-            _ => bail!("unknown time unit: {}", unit),
-        };
+    if let Some(seconds) = duration_seconds(stripped)? {
         // Second-aligned: Unix seconds * 1000 (matches Go behavior)
         return Ok((Utc::now().timestamp() - seconds) * 1000);
     }
@@ -63,6 +48,69 @@ pub fn parse_time_to_unix_millis(input: &str) -> Result<i64> {
     )
 }
 
+/// Parses a duration such as "1h", "30m", or "2 days" into seconds.
+/// Returns `Ok(None)` if the input is not a duration.
+fn duration_seconds(input: &str) -> Result<Option<i64>> {
+    let re = Regex::new(
+        r"(?i)^(\d+)\s*(s|sec|secs|second|seconds|m|min|mins|minute|minutes|h|hr|hrs|hour|hours|d|day|days|w|week|weeks)$",
+    )
+    .unwrap();
+
+    let Some(caps) = re.captures(input) else {
+        return Ok(None);
+    };
+    let num: i64 = caps[1].parse()?;
+    let unit = caps[2].to_lowercase();
+    let seconds = match unit.as_str() {
+        "s" | "sec" | "secs" | "second" | "seconds" => num,
+        "m" | "min" | "mins" | "minute" | "minutes" => num * 60,
+        "h" | "hr" | "hrs" | "hour" | "hours" => num * 3600,
+        "d" | "day" | "days" => num * 86400,
+        "w" | "week" | "weeks" => num * 7 * 86400,
+        _ => bail!("unknown time unit: {}", unit),
+    };
+    Ok(Some(seconds))
+}
+
+/// Parses a duration ("15m", "1h", "7d", ...) into milliseconds.
+pub fn parse_duration_millis(input: &str) -> Result<i64> {
+    match duration_seconds(input.trim())? {
+        Some(seconds) => Ok(seconds * 1000),
+        None => bail!("unable to parse duration: {input:?}\nExpected: 30s, 15m, 1h, 7d, or 1w"),
+    }
+}
+
+/// Smallest sub-window accepted by `split_time_range`.
+pub const MIN_TIME_CHUNK_MILLIS: i64 = 60_000;
+
+/// Upper bound on sub-windows, so a tiny chunk can't fan out into thousands of requests.
+pub const MAX_TIME_CHUNKS: usize = 500;
+
+/// Splits `[from_ms, to_ms]` into consecutive sub-windows of at most `chunk_ms`,
+/// oldest first. The last window is truncated to end exactly at `to_ms`.
+pub fn split_time_range(from_ms: i64, to_ms: i64, chunk_ms: i64) -> Result<Vec<(i64, i64)>> {
+    if chunk_ms < MIN_TIME_CHUNK_MILLIS {
+        bail!("chunk size must be at least 1m");
+    }
+    if from_ms >= to_ms {
+        bail!("start time must be before end time");
+    }
+    let count = (to_ms - from_ms + chunk_ms - 1) / chunk_ms;
+    if count as usize > MAX_TIME_CHUNKS {
+        bail!(
+            "chunk size would split the range into {count} requests (max {MAX_TIME_CHUNKS}); use a larger chunk"
+        );
+    }
+    let mut windows = Vec::with_capacity(count as usize);
+    let mut start = from_ms;
+    while start < to_ms {
+        let end = (start + chunk_ms).min(to_ms);
+        windows.push((start, end));
+        start = end;
+    }
+    Ok(windows)
+}
+
 /// Convenience: parse to Unix seconds.
 pub fn parse_time_to_unix(input: &str) -> Result<i64> {
     Ok(parse_time_to_unix_millis(input)? / 1000)
@@ -72,6 +120,74 @@ fn now_millis() -> i64 {
     Utc::now().timestamp() * 1000
 }
 
+/// Total of the `c0` count across the buckets of a logs or RUM aggregate
+/// response.
+pub fn aggregate_count(resp: &serde_json::Value) -> i64 {
+    resp.pointer("/data/buckets")
+        .and_then(|b| b.as_array())
+        .map(|buckets| {
+            buckets
+                .iter()
+                .filter_map(|b| b.pointer("/computes/c0").and_then(|c| c.as_f64()))
+                .sum::<f64>() as i64
+        })
+        .unwrap_or(0)
+}
+
+/// Adds `volume: {query, window, count}` next to a metric's `data`, for the
+/// `metrics get --with-volume` commands.
+pub fn attach_volume(metric: &mut serde_json::Value, query: &str, window: &str, count: i64) {
+    if let Some(obj) = metric.as_object_mut() {
+        obj.insert(
+            "volume".into(),
+            serde_json::json!({"query": query, "window": window, "count": count}),
+        );
+    }
+}
+
+/// Bulk actions matching more resources than this require `--yes`.
+pub const BULK_CONFIRM_THRESHOLD: usize = 20;
+
+/// Asks before applying `verb` to `count` matched `noun` (plural, e.g.
+/// "monitors"). Large sets are refused outright unless `--yes` was given.
+pub fn confirm_bulk(
+    cfg: &crate::config::Config,
+    verb: &str,
+    noun: &str,
+    count: usize,
+) -> Result<bool> {
+    if cfg.auto_approve {
+        return Ok(true);
+    }
+    if count > BULK_CONFIRM_THRESHOLD {
+        bail!(
+            "matched {count} {noun} (more than {BULK_CONFIRM_THRESHOLD}); \
+             re-run with --yes to {verb} them all"
+        );
+    }
+    confirm(cfg, &format!("{verb} {count} {noun}?"))
+}
+
+/// Asks the user to type `yes` before going ahead with `prompt`, unless
+/// `--yes` was given. Runs under `--orgs` or `pup batch` capture their output
+/// and cannot be answered interactively, so they are refused instead.
+pub fn confirm(cfg: &crate::config::Config, prompt: &str) -> Result<bool> {
+    if cfg.auto_approve {
+        return Ok(true);
+    }
+    if crate::formatter::capturing() {
+        bail!("{prompt} Confirmation is not possible here; re-run with --yes");
+    }
+    crate::formatter::print_prompt(&format!("{prompt} Type 'yes' to confirm: "));
+    let mut input = String::new();
+    std::io::stdin().read_line(&mut input)?;
+    if input.trim() != "yes" {
+        crate::formatter::print_block("Operation cancelled.\n")?;
+        return Ok(false);
+    }
+    Ok(true)
+}
+
 /// Read a JSON file and deserialize into the specified type.
 /// Used by create/update commands that accept `--file` input.
 pub fn read_json_file<T: serde::de::DeserializeOwned>(path: &str) -> Result<T> {
@@ -81,11 +197,134 @@ pub fn read_json_file<T: serde::de::DeserializeOwned>(path: &str) -> Result<T> {
         .map_err(|e| anyhow::anyhow!("failed to parse JSON from {path:?}: {e}"))
 }
 
+/// Like [`read_json_file`], but `-` reads the document from stdin.
+pub fn read_json_input<T: serde::de::DeserializeOwned>(path: &str) -> Result<T> {
+    if path != "-" {
+        return read_json_file(path);
+    }
+    let mut contents = String::new();
+    std::io::Read::read_to_string(&mut std::io::stdin(), &mut contents)
+        .map_err(|e| anyhow::anyhow!("failed to read stdin: {e}"))?;
+    serde_json::from_str(&contents)
+        .map_err(|e| anyhow::anyhow!("failed to parse JSON from stdin: {e}"))
+}
+
 /// Parses a UUID string, returning a descriptive error if invalid.
 pub fn parse_uuid(id: &str, label: &str) -> anyhow::Result<uuid::Uuid> {
     uuid::Uuid::parse_str(id).map_err(|e| anyhow::anyhow!("invalid {label} UUID '{id}': {e}"))
 }
 
+/// Masks email addresses and IPv4 addresses in free text, for sharing
+/// incident write-ups outside the team.
+pub fn redact_pii(text: &str) -> String {
+    let email = Regex::new(r"[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}").unwrap();
+    let ipv4 = Regex::new(r"\b(?:\d{1,3}\.){3}\d{1,3}\b").unwrap();
+    let text = email.replace_all(text, "[REDACTED_EMAIL]");
+    ipv4.replace_all(&text, "[REDACTED_IP]").into_owned()
+}
+
+/// Checks log/metric query syntax locally so typos fail before a request is
+/// sent: unterminated quotes, unbalanced `()`/`{}`/`[]`, and boolean
+/// operators (AND, OR, NOT) missing an operand. Positions are 1-based.
+pub fn validate_query(query: &str) -> Result<()> {
+    if query.trim().is_empty() {
+        bail!("invalid query: query is empty");
+    }
+
+    let mut problems = Vec::new();
+    let mut open: Vec<(char, usize)> = Vec::new();
+    // Operator waiting for a right-hand operand, with its position.
+    let mut pending: Option<(String, usize)> = None;
+    // Whether an operand has been seen since the last '(' or start of query.
+    let mut has_operand = false;
+    let chars: Vec<char> = query.chars().collect();
+    let mut i = 0;
+
+    while i < chars.len() {
+        let c = chars[i];
+        let pos = i + 1;
+        match c {
+            c if c.is_whitespace() => i += 1,
+            '"' => {
+                let mut j = i + 1;
+                while j < chars.len() && chars[j] != '"' {
+                    j += if chars[j] == '\\' { 2 } else { 1 };
+                }
+                if j >= chars.len() {
+                    problems.push(format!("unterminated quote at position {pos}"));
+                }
+                pending = None;
+                has_operand = true;
+                i = j + 1;
+            }
+            '(' | '{' | '[' => {
+                open.push((c, pos));
+                has_operand = false;
+                i += 1;
+            }
+            ')' | '}' | ']' => {
+                let expected = match c {
+                    ')' => '(',
+                    '}' => '{',
+                    _ => '[',
+                };
+                match open.pop() {
+                    Some((o, _)) if o == expected => {}
+                    Some((o, at)) => problems.push(format!(
+                        "'{c}' at position {pos} does not match '{o}' at position {at}"
+                    )),
+                    None => problems.push(format!("unexpected '{c}' at position {pos}")),
+                }
+                if let Some((op, at)) = pending.take() {
+                    problems.push(format!(
+                        "operator {op} at position {at} has no right operand"
+                    ));
+                }
+                has_operand = true;
+                i += 1;
+            }
+            _ => {
+                let start = i;
+                while i < chars.len() && !chars[i].is_whitespace() && !"(){}[]\"".contains(chars[i])
+                {
+                    i += if chars[i] == '\\' { 2 } else { 1 };
+                }
+                let word: String = chars[start..i.min(chars.len())].iter().collect();
+                match word.as_str() {
+                    "AND" | "OR" => {
+                        if !has_operand || pending.is_some() {
+                            problems.push(format!(
+                                "operator {word} at position {pos} has no left operand"
+                            ));
+                        }
+                        pending = Some((word, pos));
+                    }
+                    "NOT" => {
+                        pending = Some((word, pos));
+                    }
+                    _ => {
+                        pending = None;
+                        has_operand = true;
+                    }
+                }
+            }
+        }
+    }
+
+    if let Some((op, at)) = pending {
+        problems.push(format!(
+            "operator {op} at position {at} has no right operand"
+        ));
+    }
+    for (o, at) in open {
+        problems.push(format!("unclosed '{o}' at position {at}"));
+    }
+    if !problems.is_empty() {
+        bail!("invalid query: {}", problems.join("; "));
+    }
+    Ok(())
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -149,6 +388,38 @@ mod tests {
         assert!(parse_time_to_unix_millis("").is_err());
     }
 
+    #[test]
+    fn test_parse_duration_millis() {
+        assert_eq!(parse_duration_millis("15m").unwrap(), 900_000);
+        assert_eq!(parse_duration_millis("1 day").unwrap(), 86_400_000);
+        assert!(parse_duration_millis("now").is_err());
+        assert!(parse_duration_millis("2024-01-01T00:00:00Z").is_err());
+    }
+
+    #[test]
+    fn test_split_time_range_three_chunks() {
+        let hour = 3_600_000;
+        let windows = split_time_range(0, 3 * hour - 1000, hour).unwrap();
+        assert_eq!(
+            windows,
+            vec![(0, hour), (hour, 2 * hour), (2 * hour, 3 * hour - 1000)]
+        );
+    }
+
+    #[test]
+    fn test_split_time_range_single_chunk() {
+        let windows = split_time_range(0, 60_000, 3_600_000).unwrap();
+        assert_eq!(windows, vec![(0, 60_000)]);
+    }
+
+    #[test]
+    fn test_split_time_range_guards() {
+        assert!(split_time_range(0, 3_600_000, 1000).is_err());
+        assert!(split_time_range(3_600_000, 0, 60_000).is_err());
+        // 90 days in 1m chunks is far too many requests
+        assert!(split_time_range(0, 90 * 86_400_000, 60_000).is_err());
+    }
+
     #[test]
     fn test_parse_time_to_unix_returns_seconds() {
         let secs = parse_time_to_unix("1700000000000").unwrap();
@@ -230,4 +501,78 @@ mod tests {
         assert_eq!(result.unwrap()["name"], "test");
         std::fs::remove_file(path).ok();
     }
+
+    #[test]
+    fn test_read_json_input_file() {
+        let path = "/tmp/__pup_test_input__.json";
+        std::fs::write(path, r#"[1, 2]"#).unwrap();
+        let result: serde_json::Value = read_json_input(path).unwrap();
+        assert_eq!(result, serde_json::json!([1, 2]));
+        std::fs::remove_file(path).ok();
+    }
+
+    #[test]
+    fn test_redact_pii() {
+        assert_eq!(
+            redact_pii("Paged jane.doe+oncall@example.co.uk from 10.0.12.7; version 1.2.3 ok"),
+            "Paged [REDACTED_EMAIL] from [REDACTED_IP]; version 1.2.3 ok"
+        );
+        assert_eq!(redact_pii("nothing here"), "nothing here");
+    }
+
+    #[test]
+    fn test_validate_query_valid() {
+        for q in [
+            "*",
+            "service:web status:error",
+            "service:web AND (status:error OR status:warn)",
+            "NOT env:staging",
+            "-env:staging @http.status_code:[400 TO 499]",
+            "\"connection refused\" AND host:db-1",
+            "\"say \\\"hi\\\"\"",
+            "avg:system.cpu.user{env:prod} by {host}",
+            "sum:app.requests{env:prod,service:api}.as_count()",
+        ] {
+            assert!(validate_query(q).is_ok(), "{q}: {:?}", validate_query(q));
+        }
+    }
+
+    #[test]
+    fn test_validate_query_unbalanced() {
+        let err = |q: &str| validate_query(q).unwrap_err().to_string();
+        assert!(err("\"unterminated AND x").contains("unterminated quote at position 1"));
+        assert!(err("(status:error OR x").contains("unclosed '(' at position 1"));
+        assert!(err("status:error)").contains("unexpected ')' at position 13"));
+        assert!(err("avg:cpu{host:a)").contains("')' at position 15 does not match '{'"));
+        assert!(err("").contains("query is empty"));
+    }
+
+    #[test]
+    fn test_validate_query_stray_operators() {
+        let err = |q: &str| validate_query(q).unwrap_err().to_string();
+        assert!(err("AND service:web").contains("operator AND at position 1 has no left operand"));
+        assert!(err("service:web OR").contains("operator OR at position 13 has no right operand"));
+        assert!(err("a AND OR b").contains("operator OR at position 7 has no left operand"));
+        assert!(err("(a AND) b").contains("operator AND at position 4 has no right operand"));
+        assert!(err("a NOT").contains("operator NOT at position 3 has no right operand"));
+    }
+
+    #[test]
+    fn test_aggregate_count_and_attach_volume() {
+        let resp = serde_json::json!({"data": {"buckets": [
+            {"by": {}, "computes": {"c0": 1200}},
+            {"by": {}, "computes": {"c0": 34}}
+        ]}});
+        assert_eq!(aggregate_count(&resp), 1234);
+        assert_eq!(
+            aggregate_count(&serde_json::json!({"data": {"buckets": []}})),
+            0
+        );
+
+        let mut out = serde_json::json!({"data": {"id": "m1"}});
+        attach_volume(&mut out, "service:web status:error", "1h", 1234);
+        assert_eq!(out["volume"]["count"], 1234);
+        assert_eq!(out["volume"]["window"], "1h");
+        assert_eq!(out["data"]["id"], "m1");
+    }
 }